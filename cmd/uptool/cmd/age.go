@@ -0,0 +1,76 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// allReleaseTimestamps collects release times for the dependencies of every
+// manifest whose integration reports them, for --fetch-info.
+func allReleaseTimestamps(ctx context.Context, manifests []*engine.Manifest) map[string]time.Time {
+	return fetchReleaseTimestamps(ctx, manifests, func(*engine.Manifest) bool { return true })
+}
+
+// setUpdateAges records on each update when its current and target versions
+// were released, and how many days old the current one is at now. Updates
+// with neither release time known are left without an age.
+func setUpdateAges(plans []*engine.UpdatePlan, timestamps map[string]time.Time, now time.Time) {
+	for _, plan := range plans {
+		for i := range plan.Updates {
+			u := &plan.Updates[i]
+			current, currentOK := releasedAt(timestamps, u.Dependency.Name, u.Dependency.CurrentVersion)
+			target, targetOK := releasedAt(timestamps, u.Dependency.Name, u.TargetVersion)
+			if !currentOK && !targetOK {
+				continue
+			}
+
+			age := &engine.UpdateAge{TargetReleasedAt: target}
+			if currentOK {
+				age.CurrentReleasedAt = current
+				age.CurrentAgeDays = max(0, int(now.Sub(current).Hours()/24))
+			}
+			u.Age = age
+		}
+	}
+}
+
+// releasedAt looks up the release time of name at version. Range operators
+// are stripped from the version, and it is tried with and without a "v"
+// prefix since registries differ on whether they report one.
+func releasedAt(timestamps map[string]time.Time, name, version string) (time.Time, bool) {
+	version = strings.TrimLeft(strings.TrimSpace(version), "^~=<> ")
+	if version == "" {
+		return time.Time{}, false
+	}
+
+	bare := strings.TrimPrefix(version, "v")
+	for _, v := range []string{version, bare, "v" + bare} {
+		if t, ok := timestamps[name+"@"+v]; ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
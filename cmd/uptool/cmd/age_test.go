@@ -0,0 +1,95 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestSetUpdateAges(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	timestamps := map[string]time.Time{
+		"express@4.18.0":            time.Date(2022, 4, 25, 0, 0, 0, 0, time.UTC),
+		"express@4.19.2":            time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+		"golang.org/x/text@v0.14.0": time.Date(2024, 5, 31, 18, 0, 0, 0, time.UTC),
+		"golang.org/x/text@v0.15.0": time.Date(2024, 5, 31, 20, 0, 0, 0, time.UTC),
+		"left-pad@1.3.0":            time.Date(2018, 4, 9, 0, 0, 0, 0, time.UTC),
+	}
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "express", CurrentVersion: "^4.18.0"}, TargetVersion: "4.19.2"},
+				{Dependency: engine.Dependency{Name: "left-pad", CurrentVersion: "1.1.0"}, TargetVersion: "1.3.0"},
+				{Dependency: engine.Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21"},
+			},
+		},
+		{
+			Manifest: &engine.Manifest{Path: "go.mod", Type: "gomod"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "golang.org/x/text", CurrentVersion: "0.14.0"}, TargetVersion: "v0.15.0"},
+			},
+		},
+	}
+
+	setUpdateAges(plans, timestamps, now)
+
+	express := plans[0].Updates[0].Age
+	if express == nil {
+		t.Fatal("express has no age")
+	}
+	if !express.CurrentReleasedAt.Equal(timestamps["express@4.18.0"]) || !express.TargetReleasedAt.Equal(timestamps["express@4.19.2"]) {
+		t.Errorf("express age = %+v, want release times of 4.18.0 and 4.19.2", express)
+	}
+	if express.CurrentAgeDays != 768 {
+		t.Errorf("express CurrentAgeDays = %d, want 768", express.CurrentAgeDays)
+	}
+
+	// Only the target release time is known
+	leftPad := plans[0].Updates[1].Age
+	if leftPad == nil || !leftPad.CurrentReleasedAt.IsZero() || leftPad.CurrentAgeDays != 0 ||
+		!leftPad.TargetReleasedAt.Equal(timestamps["left-pad@1.3.0"]) {
+		t.Errorf("left-pad age = %+v, want only the target release time", leftPad)
+	}
+
+	if plans[0].Updates[2].Age != nil {
+		t.Errorf("lodash age = %+v, want none without release times", plans[0].Updates[2].Age)
+	}
+
+	// The "v" prefix differs between the manifest and the registry; under a day old is 0 days
+	text := plans[1].Updates[0].Age
+	if text == nil || text.CurrentAgeDays != 0 || !text.TargetReleasedAt.Equal(timestamps["golang.org/x/text@v0.15.0"]) {
+		t.Errorf("golang.org/x/text age = %+v, want both release times", text)
+	}
+
+	data, err := json.Marshal(plans[0].Updates[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"age":{"target_released_at":"2018-04-09T00:00:00Z"}`) {
+		t.Errorf("JSON = %s, want age with only target_released_at", data)
+	}
+}
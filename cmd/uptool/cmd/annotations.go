@@ -0,0 +1,94 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// annotationEscaper and annotationPropertyEscaper encode the characters
+// GitHub Actions workflow commands reserve in messages and properties.
+var (
+	annotationEscaper         = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	annotationPropertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+// annotationCommand maps an update's impact to a workflow command. Major
+// updates fail the check visually; everything else is a warning.
+func annotationCommand(impact string) string {
+	if impact == "major" {
+		return "error"
+	}
+	return "warning"
+}
+
+// outputGitHubAnnotations writes one GitHub Actions workflow command per
+// planned update, located at the dependency's manifest line when known,
+// followed by an error per plan error and a notice summarizing the counts.
+func outputGitHubAnnotations(w io.Writer, result *engine.PlanResult, repoRoot string) error {
+	counts := make(map[string]int)
+	total, manifests := 0, 0
+
+	for _, plan := range result.Plans {
+		if len(plan.Updates) == 0 {
+			continue
+		}
+		manifests++
+
+		file := annotationPropertyEscaper.Replace(sarifURI(plan.Manifest.Path, repoRoot))
+		for i := range plan.Updates {
+			update := &plan.Updates[i]
+			dep := update.Dependency
+
+			location := "file=" + file
+			if line := declarationLine(&dep, plan.Manifest.Path, repoRoot); line > 0 {
+				location += fmt.Sprintf(",line=%d", line)
+			}
+
+			message := fmt.Sprintf("%s %s -> %s", dep.Name, dep.CurrentVersion, update.TargetVersion)
+			if _, err := fmt.Fprintf(w, "::%s %s::%s\n",
+				annotationCommand(update.Impact), location, annotationEscaper.Replace(message)); err != nil {
+				return err
+			}
+
+			counts[update.Impact]++
+			total++
+		}
+	}
+
+	for _, e := range result.Errors {
+		if _, err := fmt.Fprintf(w, "::error::%s\n", annotationEscaper.Replace(e)); err != nil {
+			return err
+		}
+	}
+
+	summary := fmt.Sprintf("%d updates available in %d manifests (%d major, %d minor, %d patch)",
+		total, manifests, counts["major"], counts["minor"], counts["patch"])
+	if len(result.Errors) > 0 {
+		summary += fmt.Sprintf(", %d errors", len(result.Errors))
+	}
+	_, err := fmt.Fprintf(w, "::notice title=uptool::%s\n", annotationEscaper.Replace(summary))
+	return err
+}
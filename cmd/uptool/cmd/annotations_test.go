@@ -0,0 +1,119 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations/npm"
+)
+
+func TestOutputGitHubAnnotations(t *testing.T) {
+	repoRoot := t.TempDir()
+	manifest := `{
+  "name": "app",
+  "dependencies": {
+    "express": "5.1.0",
+    "react": "^17.0.0"
+  },
+  "devDependencies": {
+    "typescript": "~5.4.2"
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(repoRoot, "package.json"), []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := npm.New().Detect(context.Background(), repoRoot)
+	if err != nil || len(manifests) != 1 {
+		t.Fatalf("Detect() = %v, %v", manifests, err)
+	}
+	deps := make(map[string]engine.Dependency)
+	for _, dep := range manifests[0].Dependencies {
+		deps[dep.Name] = dep
+	}
+
+	result := &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{{
+			Manifest: manifests[0],
+			Updates: []engine.Update{
+				{Dependency: deps["express"], TargetVersion: "5.2.1", Impact: "minor"},
+				{Dependency: deps["react"], TargetVersion: "18.2.0", Impact: "major"},
+				{Dependency: deps["typescript"], TargetVersion: "5.4.5", Impact: "patch"},
+			},
+		}},
+		Errors: []string{"cargo: fetch failed: 50% of\nrequests"},
+	}
+
+	var buf bytes.Buffer
+	if err := outputGitHubAnnotations(&buf, result, repoRoot); err != nil {
+		t.Fatalf("outputGitHubAnnotations() error = %v", err)
+	}
+
+	want := "::warning file=package.json,line=4::express 5.1.0 -> 5.2.1\n" +
+		"::error file=package.json,line=5::react ^17.0.0 -> 18.2.0\n" +
+		"::warning file=package.json,line=8::typescript ~5.4.2 -> 5.4.5\n" +
+		"::error::cargo: fetch failed: 50%25 of%0Arequests\n" +
+		"::notice title=uptool::3 updates available in 1 manifests (1 major, 1 minor, 1 patch), 1 errors\n"
+	if got := buf.String(); got != want {
+		t.Errorf("outputGitHubAnnotations() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOutputGitHubAnnotations_NoUpdates(t *testing.T) {
+	var buf bytes.Buffer
+	if err := outputGitHubAnnotations(&buf, &engine.PlanResult{}, t.TempDir()); err != nil {
+		t.Fatalf("outputGitHubAnnotations() error = %v", err)
+	}
+
+	want := "::notice title=uptool::0 updates available in 0 manifests (0 major, 0 minor, 0 patch)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("outputGitHubAnnotations() = %q, want %q", got, want)
+	}
+}
+
+func TestOutputGitHubAnnotations_EscapesProperties(t *testing.T) {
+	result := &engine.PlanResult{Plans: []*engine.UpdatePlan{{
+		Manifest: &engine.Manifest{Path: "weird,dir/package.json", Type: "npm"},
+		Updates: []engine.Update{{
+			Dependency:    engine.Dependency{Name: "left-pad", CurrentVersion: "1.0.0", Line: 3},
+			TargetVersion: "1.3.0",
+			Impact:        "minor",
+		}},
+	}}}
+
+	var buf bytes.Buffer
+	if err := outputGitHubAnnotations(&buf, result, t.TempDir()); err != nil {
+		t.Fatalf("outputGitHubAnnotations() error = %v", err)
+	}
+
+	want := "::warning file=weird%2Cdir/package.json,line=3::left-pad 1.0.0 -> 1.3.0\n"
+	if got := buf.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("outputGitHubAnnotations() = %q, want prefix %q", got, want)
+	}
+}
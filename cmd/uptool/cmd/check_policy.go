@@ -21,7 +21,6 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
 
@@ -59,7 +58,7 @@ func init() {
 }
 
 func runCheckPolicy(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := commandContext(cmd)
 
 	// Load configuration
 	cfg, err := loadPolicyConfig()
@@ -0,0 +1,128 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Color modes accepted by the --color flag.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// ANSI escape sequences used for terminal output.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// colorEnabled controls whether output helpers emit ANSI escape sequences.
+// It is resolved once from the --color flag in the root PersistentPreRunE.
+var colorEnabled bool
+
+// validateColorMode returns an error if mode is not a supported --color value.
+func validateColorMode(mode string) error {
+	switch mode {
+	case colorAuto, colorAlways, colorNever:
+		return nil
+	default:
+		return fmt.Errorf("invalid --color value %q (must be auto, always, or never)", mode)
+	}
+}
+
+// resolveColor decides whether color output should be enabled.
+//
+// "always" and "never" are honored unconditionally. In "auto" mode color is
+// enabled only when stdout is a terminal and NO_COLOR is not set
+// (see https://no-color.org).
+func resolveColor(mode string, isTTY bool, noColor string) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	default:
+		return isTTY && noColor == ""
+	}
+}
+
+// stdoutIsTerminal reports whether stdout is attached to a character device.
+func stdoutIsTerminal() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in the given ANSI code when color output is enabled.
+func colorize(code, s string) string {
+	if !colorEnabled || s == "" {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// colorizeImpact colors an impact label by severity.
+func colorizeImpact(impact string) string {
+	switch strings.TrimSpace(impact) {
+	case "major":
+		return colorize(ansiRed, impact)
+	case "minor":
+		return colorize(ansiYellow, impact)
+	case "patch":
+		return colorize(ansiGreen, impact)
+	default:
+		return impact
+	}
+}
+
+// colorizeDiff colors a unified diff: additions green, removals red and
+// hunk headers cyan. File headers are rendered bold.
+func colorizeDiff(diff string) string {
+	if !colorEnabled {
+		return diff
+	}
+
+	lines := strings.Split(diff, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = colorize(ansiBold, line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = colorize(ansiCyan, line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = colorize(ansiGreen, line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = colorize(ansiRed, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// captureStdout runs fn and returns everything it wrote to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+
+	orig := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = orig }()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("close pipe: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read pipe: %v", err)
+	}
+	return string(out)
+}
+
+// captureStderr runs fn and returns everything it wrote to os.Stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+
+	orig := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = orig }()
+
+	fn()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("close pipe: %v", err)
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("read pipe: %v", err)
+	}
+	return string(out)
+}
+
+func TestValidateColorMode(t *testing.T) {
+	for _, mode := range []string{"auto", "always", "never"} {
+		if err := validateColorMode(mode); err != nil {
+			t.Errorf("validateColorMode(%q) error = %v, want nil", mode, err)
+		}
+	}
+	if err := validateColorMode("sometimes"); err == nil {
+		t.Error("validateColorMode(\"sometimes\") error = nil, want error")
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		noColor string
+		isTTY   bool
+		want    bool
+	}{
+		{name: "auto on tty", mode: "auto", isTTY: true, want: true},
+		{name: "auto without tty", mode: "auto", isTTY: false, want: false},
+		{name: "auto with NO_COLOR", mode: "auto", isTTY: true, noColor: "1", want: false},
+		{name: "always without tty", mode: "always", isTTY: false, want: true},
+		{name: "always with NO_COLOR", mode: "always", isTTY: false, noColor: "1", want: true},
+		{name: "never on tty", mode: "never", isTTY: true, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveColor(tt.mode, tt.isTTY, tt.noColor); got != tt.want {
+				t.Errorf("resolveColor(%q, %v, %q) = %v, want %v", tt.mode, tt.isTTY, tt.noColor, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColorOutput(t *testing.T) {
+	result := &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{
+			{
+				Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+				Updates: []engine.Update{
+					{
+						Dependency:    engine.Dependency{Name: "react", CurrentVersion: "17.0.0"},
+						TargetVersion: "18.0.0",
+						Impact:        string(engine.ImpactMajor),
+					},
+				},
+			},
+		},
+		Errors: []string{"registry unavailable"},
+	}
+	diff := "--- a/package.json\n+++ b/package.json\n@@ -1,1 +1,1 @@\n-\"react\": \"17.0.0\"\n+\"react\": \"18.0.0\"\n"
+
+	orig := colorEnabled
+	defer func() { colorEnabled = orig }()
+
+	t.Run("never strips ANSI", func(t *testing.T) {
+		colorEnabled = resolveColor(colorNever, true, "")
+
+		out := captureStdout(t, func() {
+			if err := outputPlanTable(result); err != nil {
+				t.Errorf("outputPlanTable() error = %v", err)
+			}
+		})
+		if strings.Contains(out, "\x1b[") {
+			t.Errorf("outputPlanTable() contains ANSI escapes with --color=never:\n%q", out)
+		}
+		if got := colorizeDiff(diff); got != diff {
+			t.Errorf("colorizeDiff() = %q, want unchanged %q", got, diff)
+		}
+	})
+
+	t.Run("always forces ANSI without tty", func(t *testing.T) {
+		colorEnabled = resolveColor(colorAlways, false, "")
+
+		out := captureStdout(t, func() {
+			if err := outputPlanTable(result); err != nil {
+				t.Errorf("outputPlanTable() error = %v", err)
+			}
+		})
+		if !strings.Contains(out, ansiRed+"major") {
+			t.Errorf("outputPlanTable() missing colored impact with --color=always:\n%q", out)
+		}
+		got := colorizeDiff(diff)
+		if !strings.Contains(got, ansiGreen+"+\"react\": \"18.0.0\""+ansiReset) {
+			t.Errorf("colorizeDiff() missing green addition: %q", got)
+		}
+		if !strings.Contains(got, ansiRed+"-\"react\": \"17.0.0\""+ansiReset) {
+			t.Errorf("colorizeDiff() missing red removal: %q", got)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestCompleteIntegrations(t *testing.T) {
+	got, directive := completeIntegrations(nil, nil, "")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want NoFileComp", directive)
+	}
+	for _, want := range []string{"npm", "helm", "terraform", failingIntegrationName} {
+		if !slices.Contains(got, want) {
+			t.Errorf("completeIntegrations() = %v, missing %q", got, want)
+		}
+	}
+
+	t.Run("completes last element of a list", func(t *testing.T) {
+		got, _ := completeIntegrations(nil, nil, "npm,he")
+		if !reflect.DeepEqual(got, []string{"npm,helm"}) {
+			t.Errorf("completeIntegrations(\"npm,he\") = %v, want [npm,helm]", got)
+		}
+	})
+
+	t.Run("skips integrations already listed", func(t *testing.T) {
+		got, _ := completeIntegrations(nil, nil, "npm,")
+		if slices.Contains(got, "npm,npm") {
+			t.Errorf("completeIntegrations(\"npm,\") offered npm again: %v", got)
+		}
+		if !slices.Contains(got, "npm,helm") {
+			t.Errorf("completeIntegrations(\"npm,\") = %v, missing npm,helm", got)
+		}
+	})
+}
+
+func TestCompleteDependencies(t *testing.T) {
+	dir := t.TempDir()
+	pkg := `{"name": "app", "dependencies": {"lodash": "^4.17.20", "express": "^4.18.0"}}`
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(pkg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	cmd := &cobra.Command{}
+	cmd.Flags().String("only", "npm", "")
+	cmd.Flags().String("exclude", "", "")
+
+	got, directive := completeDependencies(cmd, nil, "")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("directive = %v, want NoFileComp", directive)
+	}
+	if !reflect.DeepEqual(got, []string{"express", "lodash"}) {
+		t.Errorf("completeDependencies() = %v, want [express lodash]", got)
+	}
+
+	got, _ = completeDependencies(cmd, nil, "express,l")
+	if !reflect.DeepEqual(got, []string{"express,lodash"}) {
+		t.Errorf("completeDependencies(\"express,l\") = %v, want [express,lodash]", got)
+	}
+}
+
+func TestFlagCompletionsRegistered(t *testing.T) {
+	for _, tc := range []struct {
+		cmd  *cobra.Command
+		flag string
+	}{
+		{scanCmd, "only"},
+		{scanCmd, "exclude"},
+		{planCmd, "only"},
+		{planCmd, "exclude"},
+		{planCmd, "only-dependency"},
+		{updateCmd, "only"},
+		{updateCmd, "exclude"},
+		{updateCmd, "only-dependency"},
+	} {
+		if _, ok := tc.cmd.GetFlagCompletionFunc(tc.flag); !ok {
+			t.Errorf("%s --%s has no completion function", tc.cmd.Name(), tc.flag)
+		}
+	}
+}
+
+func TestFilterPlansByDependency(t *testing.T) {
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "express"}},
+				{Dependency: engine.Dependency{Name: "@types/node"}},
+				{Dependency: engine.Dependency{Name: "lodash"}},
+			},
+		},
+	}
+
+	got := filterPlansByDependency(plans, []string{"express", "@types/*"})
+	names := make([]string, 0, len(got[0].Updates))
+	for _, u := range got[0].Updates {
+		names = append(names, u.Dependency.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"express", "@types/node"}) {
+		t.Errorf("filterPlansByDependency() kept %v, want [express @types/node]", names)
+	}
+	if len(plans[0].Updates) != 3 {
+		t.Error("filterPlansByDependency() modified the input plan")
+	}
+	if len(got[0].Filtered) != 1 || got[0].Filtered[0].Name != "lodash" {
+		t.Errorf("filterPlansByDependency() filtered %v, want [lodash]", got[0].Filtered)
+	}
+
+	if got := filterPlansByDependency(plans, nil); len(got[0].Updates) != 3 {
+		t.Errorf("filterPlansByDependency() without patterns kept %d updates, want 3", len(got[0].Updates))
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// releaseDatasources maps integrations to the datasource that reports release
+// times for their dependencies by name. Cooldowns can only be enforced where
+// release times are known.
+var releaseDatasources = map[string]string{
+	"npm":   "npm",
+	"gomod": "go",
+	"cargo": "crates",
+}
+
+// releaseTimestamps collects the publish time of every version of the dependencies
+// of manifests whose integration policy configures a cooldown, keyed by "name@version".
+// It returns nil when no cooldown applies, so planning makes no extra registry calls.
+func releaseTimestamps(ctx context.Context, eng *engine.Engine, manifests []*engine.Manifest) map[string]time.Time {
+	return fetchReleaseTimestamps(ctx, manifests, func(m *engine.Manifest) bool {
+		return eng.GetUpdateFilter(m.Type).HasCooldown()
+	})
+}
+
+// fetchReleaseTimestamps collects the publish time of every version of the
+// dependencies of the manifests include selects, keyed by "name@version".
+// It returns nil when no release time is known.
+func fetchReleaseTimestamps(ctx context.Context, manifests []*engine.Manifest, include func(*engine.Manifest) bool) map[string]time.Time {
+	var timestamps map[string]time.Time
+	fetched := make(map[string]bool)
+
+	for _, m := range manifests {
+		dsName, ok := releaseDatasources[m.Type]
+		if !ok || !include(m) {
+			continue
+		}
+
+		ds, err := datasource.Get(dsName)
+		if err != nil {
+			continue
+		}
+
+		for _, dep := range m.Dependencies {
+			key := dsName + "\x00" + dep.Name
+			if fetched[key] {
+				continue
+			}
+			fetched[key] = true
+
+			info, err := ds.GetPackageInfo(ctx, dep.Name)
+			if err != nil || info == nil {
+				continue
+			}
+
+			for _, v := range info.Versions {
+				published, err := time.Parse(time.RFC3339, v.PublishedAt)
+				if err != nil {
+					continue
+				}
+				if timestamps == nil {
+					timestamps = make(map[string]time.Time)
+				}
+				timestamps[dep.Name+"@"+v.Version] = published
+			}
+		}
+	}
+
+	return timestamps
+}
+
+// outputCooldownTable lists updates held by a cooldown policy with the days
+// remaining before each is proposed.
+func outputCooldownTable(result *engine.PlanResult) {
+	total := 0
+	for _, plan := range result.Plans {
+		total += len(plan.Held)
+	}
+
+	if total == 0 {
+		fmt.Println("\nNo updates held by cooldown.")
+		return
+	}
+
+	fmt.Printf("\n%s\n", colorize(ansiBold, "Held by cooldown:"))
+	fmt.Printf("%-40s %-15s %-15s %-10s %s\n", "Package", "Current", "Target", "Impact", "Remaining")
+	fmt.Println(strings.Repeat("-", 95))
+
+	for _, plan := range result.Plans {
+		for i := range plan.Held {
+			held := &plan.Held[i]
+			pkg := held.Update.Dependency.Name
+			if len(pkg) > 40 {
+				pkg = pkg[:37] + "..."
+			}
+
+			fmt.Printf("%-40s %-15s %-15s %s %s\n",
+				pkg,
+				held.Update.Dependency.CurrentVersion,
+				held.Update.TargetVersion,
+				colorizeImpact(fmt.Sprintf("%-10s", held.Update.Impact)),
+				formatDaysRemaining(held))
+		}
+	}
+}
+
+// formatDaysRemaining describes how long an update remains held, e.g.
+// "3 days remaining (7-day cooldown, released 2024-05-01)".
+func formatDaysRemaining(held *engine.HeldUpdate) string {
+	unit := "days"
+	if held.DaysRemaining == 1 {
+		unit = "day"
+	}
+	return fmt.Sprintf("%d %s remaining (%d-day cooldown, released %s)",
+		held.DaysRemaining, unit, held.CooldownDays, held.ReleasedAt.Format("2006-01-02"))
+}
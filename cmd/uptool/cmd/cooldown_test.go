@@ -0,0 +1,79 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestOutputCooldownTable(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	result := inventoryPlanResult()
+	express := result.Plans[0].Manifest.Dependencies[0]
+	result.Plans[0].Held = []engine.HeldUpdate{
+		{
+			Update:        engine.Update{Dependency: express, TargetVersion: "5.0.0", Impact: "major"},
+			ReleasedAt:    time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			CooldownDays:  7,
+			DaysRemaining: 3,
+		},
+	}
+
+	out := captureStdout(t, func() { outputCooldownTable(result) })
+
+	var expressLine string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "express ") {
+			expressLine = line
+		}
+	}
+	if expressLine == "" {
+		t.Fatalf("held update missing from output:\n%s", out)
+	}
+	if !strings.Contains(expressLine, "5.0.0") || !strings.Contains(expressLine, "3 days remaining (7-day cooldown, released 2024-05-01)") {
+		t.Errorf("express line = %q, want target and days remaining", expressLine)
+	}
+
+	out = captureStdout(t, func() { outputCooldownTable(inventoryPlanResult()) })
+	if !strings.Contains(out, "No updates held by cooldown.") {
+		t.Errorf("output = %q, want no held updates message", out)
+	}
+}
+
+func TestReleaseTimestamps_NoCooldown(t *testing.T) {
+	eng := engine.NewEngine(nil)
+	manifests := []*engine.Manifest{
+		{Path: "package.json", Type: "npm", Dependencies: []engine.Dependency{{Name: "express"}}},
+	}
+
+	// Without a cooldown policy no registry calls are made
+	if got := releaseTimestamps(context.Background(), eng, manifests); got != nil {
+		t.Errorf("releaseTimestamps() = %v, want nil", got)
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+var (
+	diffPlanFile       string
+	diffOnly           string
+	diffExclude        string
+	diffOnlyDependency string
+	diffOnlyGroup      string
+	diffTrackedOnly    bool
+	diffLookupTimeout  time.Duration
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Preview manifest changes without writing them",
+	Long: `Preview the changes 'uptool update' would make as unified diffs.
+
+Each integration rewrites its manifests in memory and the result is
+compared against the file on disk; nothing is written. By default the
+repository is scanned and planned first. With --plan, the updates of a
+plan saved by 'uptool plan --out' are previewed instead.
+
+Lockfiles are not previewed. Integrations that update manifests through
+an external tool (pre-commit) or across several files (terraform) are
+reported as not supporting previews.`,
+	Example: `  # Preview all available updates
+  uptool diff
+
+  # Preview a saved plan
+  uptool plan --out plan.json
+  uptool diff --plan plan.json
+
+  # Preview npm updates only
+  uptool diff --only npm`,
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffPlanFile, "plan", "", "preview a plan saved with 'uptool plan --out' instead of planning")
+	diffCmd.Flags().StringVar(&diffOnly, "only", "", "comma-separated integrations to include")
+	diffCmd.Flags().StringVar(&diffExclude, "exclude", "", "comma-separated integrations to exclude")
+	diffCmd.Flags().StringVar(&diffOnlyDependency, "only-dependency", "", "comma-separated dependency names or globs to include")
+	diffCmd.Flags().StringVar(&diffOnlyGroup, "only-group", "", "preview only updates in this dependency group (groups in uptool.yaml)")
+	diffCmd.Flags().BoolVar(&diffTrackedOnly, "tracked-only", false, "skip manifests git does not track (all are scanned outside a git repository)")
+	diffCmd.Flags().DurationVar(&diffLookupTimeout, "lookup-timeout", engine.DefaultLookupTimeout, "per-dependency registry lookup timeout; slower lookups are reported as unchecked")
+
+	if err := diffCmd.RegisterFlagCompletionFunc("only", completeIntegrations); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+	if err := diffCmd.RegisterFlagCompletionFunc("exclude", completeIntegrations); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+	if err := diffCmd.RegisterFlagCompletionFunc("plan", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"json"}, cobra.ShellCompDirectiveFilterFileExt
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	eng := setupEngine()
+	eng.SetTrackedOnly(diffTrackedOnly)
+	ctx := commandContext(cmd)
+
+	var runErrors [][]string
+	var plans []*engine.UpdatePlan
+	if diffPlanFile != "" {
+		planResult, err := loadPlanFile(diffPlanFile)
+		if err != nil {
+			return err
+		}
+		plans = planResult.Plans
+	} else {
+		repoRoot, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("get working directory: %w", err)
+		}
+
+		onlyList, excludeList := parseFilters(diffOnly, diffExclude)
+		scanResult, err := eng.Scan(ctx, repoRoot, onlyList, excludeList)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+
+		planResult, err := eng.PlanWithOptions(ctx, scanResult.Manifests, &engine.PlanOptions{
+			ReleaseTimestamps: releaseTimestamps(ctx, eng, scanResult.Manifests),
+			LookupTimeout:     diffLookupTimeout,
+			OnlyGroup:         diffOnlyGroup,
+		})
+		if err != nil {
+			return fmt.Errorf("plan failed: %w", err)
+		}
+		plans = planResult.Plans
+		runErrors = append(runErrors, scanResult.Errors, planResult.Errors)
+	}
+
+	depList, _ := parseFilters(diffOnlyDependency, "")
+	plans = filterPlansByDependency(plans, depList)
+
+	previewResult, err := eng.Preview(ctx, plans)
+	if err != nil {
+		return fmt.Errorf("preview failed: %w", err)
+	}
+
+	applyErrors := writeDiffs(os.Stdout, previewResult)
+	runErrors = append(runErrors, previewResult.Errors, applyErrors)
+	return checkRunErrors(runErrors...)
+}
+
+// loadPlanFile reads a plan written by 'uptool plan --out'.
+func loadPlanFile(path string) (*engine.PlanResult, error) {
+	data, err := secureio.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read plan file: %w", err)
+	}
+
+	var result engine.PlanResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parse plan file %s: %w", path, err)
+	}
+	for i, plan := range result.Plans {
+		if plan == nil || plan.Manifest == nil {
+			return nil, fmt.Errorf("parse plan file %s: plan %d has no manifest", path, i)
+		}
+	}
+	return &result, nil
+}
+
+// writeDiffs prints the diff of every manifest the preview changed, colored
+// when color is enabled, followed by updates that could not be applied. It
+// returns those failures for --fail-on-error.
+func writeDiffs(w io.Writer, result *engine.UpdateResult) []string {
+	var failures []string
+	changed := 0
+	for _, r := range result.Results {
+		for _, e := range r.Errors {
+			failures = append(failures, fmt.Sprintf("%s: %s", r.Manifest.Path, e))
+		}
+		if r.ManifestDiff == "" {
+			continue
+		}
+		changed++
+		fmt.Fprintln(w, colorizeDiff(r.ManifestDiff))
+	}
+
+	if changed == 0 {
+		fmt.Fprintln(w, "No changes.")
+	}
+
+	printErrors(w, append(append([]string{}, result.Errors...), failures...))
+	return failures
+}
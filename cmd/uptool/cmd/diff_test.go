@@ -0,0 +1,130 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations/npm"
+)
+
+func TestDiff_DoesNotModifyFiles(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	dir := t.TempDir()
+	manifestPath := filepath.Join(dir, "package.json")
+	if err := os.WriteFile(manifestPath, []byte(stdinPackageJSON), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	eng := engine.NewEngine(nil)
+	eng.Register(&offlineNPM{
+		Integration: npm.New(),
+		latest:      map[string]string{"express": "4.18.2", "lodash": "4.17.21"},
+	})
+
+	scanResult, err := eng.Scan(ctx, dir, nil, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	planResult, err := eng.Plan(ctx, scanResult.Manifests)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	for _, p := range planResult.Plans {
+		p.Manifest.Path = filepath.Join(dir, p.Manifest.Path)
+	}
+
+	// Round-trip the plan through a file as 'uptool plan --out' writes it
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	data, err := json.Marshal(planResult)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(planFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := loadPlanFile(planFile)
+	if err != nil {
+		t.Fatalf("loadPlanFile() error = %v", err)
+	}
+
+	result, err := eng.Preview(ctx, loaded.Plans)
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+
+	var out bytes.Buffer
+	if failures := writeDiffs(&out, result); len(failures) != 0 {
+		t.Errorf("writeDiffs() failures = %v", failures)
+	}
+	for _, want := range []string{`-    "express": "4.17.0",`, `+    "express": "4.18.2",`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("diff output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	got, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != stdinPackageJSON {
+		t.Errorf("diff modified package.json:\n%s", got)
+	}
+}
+
+func TestWriteDiffs_NoChanges(t *testing.T) {
+	var out bytes.Buffer
+	writeDiffs(&out, &engine.UpdateResult{Errors: []string{"Chart.yaml: helm does not support previews"}})
+
+	if !strings.Contains(out.String(), "No changes.") || !strings.Contains(out.String(), "does not support previews") {
+		t.Errorf("writeDiffs() output = %q", out.String())
+	}
+}
+
+func TestLoadPlanFile_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"garbage.json":     "not json",
+		"no-manifest.json": `{"plans": [{"updates": []}]}`,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := loadPlanFile(path); err == nil {
+			t.Errorf("loadPlanFile(%s) should return error", name)
+		}
+	}
+	if _, err := loadPlanFile(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("loadPlanFile() of a missing file should return error")
+	}
+}
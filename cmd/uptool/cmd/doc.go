@@ -27,6 +27,9 @@
 //   - scan: Discover all manifests in a repository
 //   - plan: Generate an update plan showing available dependency updates
 //   - update: Apply updates to manifest files
+//   - history: Show updates applied by previous update runs
+//   - import: Convert another tool's configuration (dependabot.yml) to uptool.yaml
+//   - export: Convert uptool.yaml to another tool's configuration (dependabot.yml)
 //   - list: List all supported integrations and their status
 //   - completion: Generate shell completion scripts
 //
@@ -34,6 +37,10 @@
 //
 //   - -v, --verbose: Enable verbose debug output
 //   - -q, --quiet: Suppress informational output (errors only)
+//   - --color: Colorize output: auto (default, TTY only), always, never; NO_COLOR disables auto
+//   - --fail-on-error: Exit non-zero if scan, plan, or update records any error
+//   - --quiet-errors: Hide non-fatal errors from output (--fail-on-error still applies)
+//   - --stats-network: Print HTTP request, network time, and cache hit/miss counts at exit
 //
 // Example usage:
 //
@@ -0,0 +1,113 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/policy"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// dueStateFile is the name of the --due-only state file in uptool's cache
+// directory. It records when each integration was last updated.
+const dueStateFile = "last-run.json"
+
+// dueStatePath returns the path of the --due-only state file.
+func dueStatePath() (string, error) {
+	dir, err := registry.DefaultCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(dir), dueStateFile), nil
+}
+
+// loadDueState reads the --due-only state; a missing file is an empty state.
+func loadDueState() (*policy.CadenceState, string, error) {
+	path, err := dueStatePath()
+	if err != nil {
+		return nil, "", err
+	}
+	state, err := policy.LoadCadenceState(path)
+	if err != nil {
+		return nil, "", err
+	}
+	return state, path, nil
+}
+
+// filterDueManifests drops the manifests of integrations that are not due at
+// now according to their cadence or schedule, returning the kept manifests
+// and the skipped integrations in name order.
+func filterDueManifests(eng *engine.Engine, state *policy.CadenceState, manifests []*engine.Manifest, now time.Time) ([]*engine.Manifest, []string) {
+	due := make(map[string]bool)
+	kept := make([]*engine.Manifest, 0, len(manifests))
+	var skipped []string
+
+	for _, m := range manifests {
+		isDue, seen := due[m.Type]
+		if !seen {
+			var err error
+			isDue, err = state.IsDue(m.Type, eng.GetPolicy(m.Type), now)
+			if err != nil {
+				// An unusable schedule should not silently stop updates
+				fmt.Fprintf(os.Stderr, "Warning: %s: %v; treating it as due\n", m.Type, err)
+				isDue = true
+			}
+			due[m.Type] = isDue
+			if !isDue {
+				skipped = append(skipped, m.Type)
+			}
+		}
+		if isDue {
+			kept = append(kept, m)
+		}
+	}
+
+	sort.Strings(skipped)
+	return kept, skipped
+}
+
+// reportSkippedIntegrations notes on stderr the integrations --due-only skipped.
+func reportSkippedIntegrations(skipped []string) {
+	if quietFlag {
+		return
+	}
+	for _, name := range skipped {
+		fmt.Fprintf(os.Stderr, "Skipping %s: not due yet\n", name)
+	}
+}
+
+// recordDueRuns marks the integrations of manifests as run at now and saves
+// the state.
+func recordDueRuns(state *policy.CadenceState, path string, manifests []*engine.Manifest, now time.Time) error {
+	for _, m := range manifests {
+		state.MarkCheckedAt(m.Type, now)
+	}
+	if err := policy.SaveCadenceState(path, state); err != nil {
+		return fmt.Errorf("save --due-only state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/policy"
+)
+
+func TestFilterDueManifests(t *testing.T) {
+	now := time.Now()
+	eng := engine.NewEngine(nil)
+	eng.SetPolicies(map[string]engine.IntegrationPolicy{
+		"npm": {Enabled: true, Update: "major", Cadence: "weekly"},
+	})
+
+	state := &policy.CadenceState{}
+	state.MarkCheckedAt("npm", now.Add(-48*time.Hour))
+
+	manifests := []*engine.Manifest{
+		{Path: "package.json", Type: "npm"},
+		{Path: "web/package.json", Type: "npm"},
+		{Path: ".pre-commit-config.yaml", Type: "precommit"},
+	}
+
+	kept, skipped := filterDueManifests(eng, state, manifests, now)
+
+	if len(skipped) != 1 || skipped[0] != "npm" {
+		t.Errorf("skipped = %v, want [npm]", skipped)
+	}
+	if len(kept) != 1 || kept[0].Type != "precommit" {
+		t.Errorf("kept = %v, want only the precommit manifest", kept)
+	}
+
+	// A week later npm is due again
+	kept, skipped = filterDueManifests(eng, state, manifests, now.Add(6*24*time.Hour))
+	if len(skipped) != 0 || len(kept) != 3 {
+		t.Errorf("after a week: kept %d, skipped %v; want 3 kept, none skipped", len(kept), skipped)
+	}
+}
+
+func TestRecordDueRuns(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	state, path, err := loadDueState()
+	if err != nil {
+		t.Fatalf("loadDueState() error = %v", err)
+	}
+	if filepath.Base(path) != dueStateFile {
+		t.Errorf("state path = %s, want a %s file", path, dueStateFile)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	manifests := []*engine.Manifest{{Path: "package.json", Type: "npm"}}
+	if err := recordDueRuns(state, path, manifests, now); err != nil {
+		t.Fatalf("recordDueRuns() error = %v", err)
+	}
+
+	reloaded, _, err := loadDueState()
+	if err != nil {
+		t.Fatalf("loadDueState() error = %v", err)
+	}
+	if got := reloaded.LastChecked["npm"]; !got.Equal(now) {
+		t.Errorf("npm last run = %v, want %v", got, now)
+	}
+	if _, ok := reloaded.LastChecked["precommit"]; ok {
+		t.Error("precommit recorded although it was not processed")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/dependabot"
+	"github.com/santosr2/uptool/internal/policy"
+)
+
+var (
+	exportOutputFlag string
+	exportDryRunFlag bool
+	exportForceFlag  bool
+
+	exportCmd = &cobra.Command{
+		Use:   "export",
+		Short: "Export configuration for another dependency update tool",
+		Long: `Export uptool.yaml to the configuration format of another dependency update tool.
+
+Run "uptool export <tool> --help" for the settings each exporter translates.`,
+	}
+
+	exportDependabotCmd = &cobra.Command{
+		Use:   "dependabot [path]",
+		Short: "Convert uptool.yaml to dependabot.yml",
+		Long: `Convert an uptool.yaml configuration to a version 2 dependabot.yml.
+
+Each enabled integration with a matching Dependabot package-ecosystem becomes an
+entry under "updates", with its schedule (or cadence), groups, allow and ignore
+rules, cooldown, open_pull_requests_limit, labels, assignees, reviewers, and
+commit_message carried over. Directories are derived from the integration's
+match.files patterns.
+
+Dependabot accepts at most 10 open pull requests per entry, so larger limits are
+lowered. An "update" level of minor or patch becomes an ignore rule on higher
+update types. Settings with no Dependabot equivalent (for example
+allow_prerelease or pin) are listed in the summary and left out of the output.
+
+The generated file is validated before it is written. When no path is given,
+the file named by --config, or uptool.yaml, is read.
+
+Example:
+  # Write .github/dependabot.yml from uptool.yaml
+  uptool export dependabot
+
+  # Preview the result for a specific file
+  uptool export dependabot configs/uptool.yaml --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runExportDependabot,
+	}
+)
+
+func init() {
+	exportDependabotCmd.Flags().StringVarP(&exportOutputFlag, "output", "o", ".github/dependabot.yml", "output path for dependabot.yml")
+	exportDependabotCmd.Flags().BoolVar(&exportDryRunFlag, "dry-run", false, "print the generated configuration without writing it")
+	exportDependabotCmd.Flags().BoolVarP(&exportForceFlag, "force", "f", false, "overwrite an existing output file")
+
+	exportCmd.AddCommand(exportDependabotCmd)
+	rootCmd.AddCommand(exportCmd)
+}
+
+func runExportDependabot(cmd *cobra.Command, args []string) error {
+	sourcePath := GetConfigPath()
+	if len(args) > 0 {
+		sourcePath = args[0]
+	}
+	if sourcePath == "" {
+		sourcePath = "uptool.yaml"
+	}
+	return exportDependabotConfig(sourcePath, exportOutputFlag, exportDryRunFlag, exportForceFlag)
+}
+
+// exportDependabotConfig converts the uptool.yaml at sourcePath and writes
+// the resulting dependabot.yml to outputPath. With dryRun the generated
+// configuration is printed instead.
+func exportDependabotConfig(sourcePath, outputPath string, dryRun, force bool) error {
+	absPath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	if _, err := os.Stat(absPath); os.IsNotExist(err) {
+		return fmt.Errorf("source file not found: %s", sourcePath)
+	}
+
+	fmt.Printf("Reading uptool configuration from: %s\n", sourcePath)
+
+	cfg, err := policy.LoadConfig(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to load uptool config: %w", err)
+	}
+
+	depConfig, report := dependabot.ExportFromUptool(cfg)
+	printExportReport(report)
+
+	if len(depConfig.Updates) == 0 {
+		return fmt.Errorf("no integrations could be exported to dependabot.yml")
+	}
+	if err := depConfig.Validate(); err != nil {
+		return fmt.Errorf("generated dependabot config is invalid: %w", err)
+	}
+
+	yamlData, err := depConfig.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to generate dependabot config: %w", err)
+	}
+
+	header := `# Dependabot configuration
+# Exported from: ` + sourcePath + `
+
+`
+	output := header + string(yamlData)
+
+	if dryRun {
+		fmt.Println("\n--- Generated dependabot.yml (dry-run) ---")
+		fmt.Println(output)
+		return nil
+	}
+
+	if !force {
+		if _, err := os.Stat(outputPath); err == nil {
+			return fmt.Errorf("output file %s already exists; use --force to overwrite", outputPath)
+		}
+	}
+
+	outDir := filepath.Dir(outputPath)
+	if outDir != "" && outDir != "." {
+		if err := os.MkdirAll(outDir, 0o750); err != nil { // #nosec G301 -- directory needs to be accessible
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(outputPath, []byte(output), 0o600); err != nil { // #nosec G306 -- config file needs secure permissions
+		return fmt.Errorf("failed to write dependabot config: %w", err)
+	}
+
+	fmt.Printf("\nExport complete! Written to: %s\n", outputPath)
+
+	if len(report.Untranslated) > 0 {
+		fmt.Println("\nPlease review the generated configuration and adjust as needed.")
+	}
+
+	return nil
+}
+
+func printExportReport(report *dependabot.ExportReport) {
+	fmt.Println("\n=== Export Report ===")
+
+	if len(report.EcosystemsExported) > 0 {
+		fmt.Println("\nExported ecosystems:")
+		for _, eco := range report.EcosystemsExported {
+			fmt.Printf("  - %s\n", eco)
+		}
+	}
+
+	if len(report.Skipped) > 0 {
+		fmt.Println("\nSkipped integrations:")
+		for _, skipped := range report.Skipped {
+			fmt.Printf("  - %s\n", skipped)
+		}
+	}
+
+	if len(report.Untranslated) > 0 {
+		fmt.Println("\nNot translated (no Dependabot equivalent):")
+		for _, setting := range report.Untranslated {
+			fmt.Printf("  - %s\n", setting)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/dependabot"
+)
+
+func TestExportDependabotConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	content := `version: 1
+integrations:
+  - id: npm
+    enabled: true
+    policy:
+      update: major
+      cadence: daily
+      open_pull_requests_limit: 3
+      groups:
+        frontend:
+          patterns: ["react*"]
+  - id: precommit
+    enabled: true
+    policy:
+      update: major
+`
+	if err := os.WriteFile("uptool.yaml", []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	output := filepath.Join(".github", "dependabot.yml")
+	captureStdout(t, func() {
+		if err := exportDependabotConfig("uptool.yaml", output, false, false); err != nil {
+			t.Fatalf("exportDependabotConfig() error = %v", err)
+		}
+	})
+
+	cfg, err := dependabot.LoadConfig(filepath.Join(dir, output))
+	if err != nil {
+		t.Fatalf("dependabot.LoadConfig() error = %v", err)
+	}
+	if len(cfg.Updates) != 1 {
+		t.Fatalf("len(Updates) = %d, want 1", len(cfg.Updates))
+	}
+	update := cfg.Updates[0]
+	if update.PackageEcosystem != "npm" || update.Schedule.Interval != "daily" {
+		t.Errorf("update = %s %s, want npm daily", update.PackageEcosystem, update.Schedule.Interval)
+	}
+	if update.OpenPullRequestsLimit != 3 {
+		t.Errorf("OpenPullRequestsLimit = %d, want 3", update.OpenPullRequestsLimit)
+	}
+	if _, ok := update.Groups["frontend"]; !ok {
+		t.Errorf("Groups = %+v, want frontend", update.Groups)
+	}
+
+	captureStdout(t, func() {
+		if err := exportDependabotConfig("uptool.yaml", output, false, false); err == nil {
+			t.Error("exportDependabotConfig() should refuse to overwrite without force")
+		}
+	})
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// ErrFailOn is returned by plan when --fail-on is set and an update at or
+// above that impact is available.
+var ErrFailOn = errors.New("updates at or above the --fail-on level are available")
+
+// validateFailOn checks --fail-on and --since before any registry calls.
+func validateFailOn(level, since string, stdin bool) error {
+	if level != "" {
+		if _, ok := impactRank[strings.ToLower(level)]; !ok {
+			return fmt.Errorf("unsupported --fail-on level: %s (valid: %s)", level, strings.Join(sortedFailOnLevels(), ", "))
+		}
+	}
+	if since == "" {
+		return nil
+	}
+	if level == "" {
+		return fmt.Errorf("--since only applies to the --fail-on gate")
+	}
+	if stdin {
+		return fmt.Errorf("--since cannot be combined with --stdin-type")
+	}
+	return nil
+}
+
+// checkFailOn fails with ErrFailOn when any update's impact is at or above
+// level. Updates of dependencies in added are exempt: they were chosen in the
+// change under review, so a newer release is not a reason to block it. The
+// failing updates are printed to stderr.
+func checkFailOn(plans []*engine.UpdatePlan, level string, added map[string]map[string]bool) error {
+	if level == "" {
+		return nil
+	}
+	threshold := impactRank[strings.ToLower(level)]
+
+	var failing []string
+	for _, plan := range plans {
+		for i := range plan.Updates {
+			update := &plan.Updates[i]
+			if impactRank[strings.ToLower(update.Impact)] < threshold {
+				continue
+			}
+			if added[plan.Manifest.Path][update.Dependency.Name] {
+				continue
+			}
+			failing = append(failing, fmt.Sprintf("%s: %s %s -> %s (%s)",
+				plan.Manifest.Path, update.Dependency.Name, update.Dependency.CurrentVersion, update.TargetVersion, update.Impact))
+		}
+	}
+	if len(failing) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%d update(s) at or above %s (--fail-on):\n", len(failing), level)
+	for _, f := range failing {
+		fmt.Fprintf(os.Stderr, "  - %s\n", f)
+	}
+	return fmt.Errorf("%w: %d", ErrFailOn, len(failing))
+}
+
+// addedDependencies returns, per manifest path, the dependencies that the
+// manifest declares now but did not declare at the git revision since. Each
+// manifest's content at since is parsed by its own integration, so a manifest
+// that did not exist yet has all of its dependencies added.
+func addedDependencies(ctx context.Context, eng *engine.Engine, repoRoot, since string, plans []*engine.UpdatePlan) (map[string]map[string]bool, error) {
+	if strings.HasPrefix(since, "-") {
+		return nil, fmt.Errorf("invalid --since revision: %s", since)
+	}
+	verify := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", since+"^{commit}") // #nosec G204 - revision is validated above and passed as an argument, not to a shell
+	verify.Dir = repoRoot
+	if err := verify.Run(); err != nil {
+		return nil, fmt.Errorf("--since: %s is not a git revision of %s", since, repoRoot)
+	}
+
+	added := make(map[string]map[string]bool)
+	for _, plan := range plans {
+		if len(plan.Updates) == 0 {
+			continue
+		}
+		previous, err := dependenciesAt(ctx, eng, repoRoot, since, plan.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		for _, dep := range plan.Manifest.Dependencies {
+			if previous[dep.Name] {
+				continue
+			}
+			if added[plan.Manifest.Path] == nil {
+				added[plan.Manifest.Path] = make(map[string]bool)
+			}
+			added[plan.Manifest.Path][dep.Name] = true
+		}
+	}
+	return added, nil
+}
+
+// dependenciesAt returns the names of the dependencies manifest declared at
+// revision, or none when the file did not exist then.
+func dependenciesAt(ctx context.Context, eng *engine.Engine, repoRoot, revision string, manifest *engine.Manifest) (map[string]bool, error) {
+	rel := manifest.Path
+	if filepath.IsAbs(rel) {
+		var err error
+		if rel, err = filepath.Rel(repoRoot, rel); err != nil || strings.HasPrefix(rel, "..") {
+			return nil, nil
+		}
+	}
+	rel = filepath.ToSlash(filepath.Clean(rel))
+
+	show := exec.CommandContext(ctx, "git", "show", revision+":"+rel) // #nosec G204 - arguments are built by uptool, not a shell
+	show.Dir = repoRoot
+	content, err := show.Output()
+	if err != nil {
+		// The revision was verified, so the manifest is new since then
+		return nil, nil
+	}
+
+	integ, ok := eng.GetIntegration(manifest.Type)
+	if !ok {
+		return nil, fmt.Errorf("integration %q is not enabled", manifest.Type)
+	}
+
+	dir, err := os.MkdirTemp("", "uptool-since-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }() //nolint:errcheck // cleanup best effort
+
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		return nil, fmt.Errorf("write %s at %s: %w", rel, revision, err)
+	}
+
+	manifests, err := integ.Detect(ctx, dir)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s at %s: %w", rel, revision, err)
+	}
+
+	names := make(map[string]bool)
+	for _, m := range manifests {
+		for _, dep := range m.Dependencies {
+			names[dep.Name] = true
+		}
+	}
+	return names, nil
+}
+
+// sortedFailOnLevels lists the values accepted by --fail-on.
+func sortedFailOnLevels() []string {
+	levels := make([]string, 0, len(impactRank))
+	for level := range impactRank {
+		levels = append(levels, level)
+	}
+	sort.Slice(levels, func(i, j int) bool { return impactRank[levels[i]] < impactRank[levels[j]] })
+	return levels
+}
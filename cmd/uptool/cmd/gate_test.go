@@ -0,0 +1,182 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations/npm"
+)
+
+// sinceRepo commits package.json with express and app/package.json, then adds
+// lodash to package.json and a new web/package.json in the working tree.
+func sinceRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	write := func(path, content string) {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	write("package.json", `{"dependencies": {"express": "3.0.0"}}`)
+	git("init", "-q")
+	git("add", "package.json")
+	git("commit", "-q", "-m", "initial")
+
+	write("package.json", `{"dependencies": {"express": "3.0.0", "lodash": "3.10.1"}}`)
+	write("web/package.json", `{"dependencies": {"react": "17.0.0"}}`)
+	return dir
+}
+
+func TestFailOn_SinceGrace(t *testing.T) {
+	dir := sinceRepo(t)
+	ctx := context.Background()
+
+	eng := engine.NewEngine(nil)
+	eng.Register(npm.New())
+	scanResult, err := eng.Scan(ctx, dir, nil, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	latest := map[string]string{"express": "5.1.0", "lodash": "4.17.21", "react": "19.0.0"}
+	plans := make([]*engine.UpdatePlan, 0, len(scanResult.Manifests))
+	for _, m := range scanResult.Manifests {
+		plan := &engine.UpdatePlan{Manifest: m}
+		for _, dep := range m.Dependencies {
+			plan.Updates = append(plan.Updates, engine.Update{Dependency: dep, TargetVersion: latest[dep.Name], Impact: "major"})
+		}
+		plans = append(plans, plan)
+	}
+
+	added, err := addedDependencies(ctx, eng, dir, "HEAD", plans)
+	if err != nil {
+		t.Fatalf("addedDependencies() error = %v", err)
+	}
+
+	var newDeps []string
+	for _, plan := range plans {
+		for _, u := range plan.Updates {
+			if added[plan.Manifest.Path][u.Dependency.Name] {
+				newDeps = append(newDeps, u.Dependency.Name)
+			}
+		}
+	}
+	if len(newDeps) != 2 || newDeps[0] == "express" || newDeps[1] == "express" {
+		t.Errorf("added dependencies = %v, want lodash and react", newDeps)
+	}
+
+	// The pre-existing, stale express still trips the gate
+	err = checkFailOn(plans, "major", added)
+	if !errors.Is(err, ErrFailOn) || err.Error() != ErrFailOn.Error()+": 1" {
+		t.Errorf("checkFailOn() error = %v, want ErrFailOn for express only", err)
+	}
+
+	// Without it, the newly added dependencies do not
+	for _, plan := range plans {
+		kept := plan.Updates[:0]
+		for _, u := range plan.Updates {
+			if u.Dependency.Name != "express" {
+				kept = append(kept, u)
+			}
+		}
+		plan.Updates = kept
+	}
+	if err := checkFailOn(plans, "major", added); err != nil {
+		t.Errorf("checkFailOn() error = %v, want newly added dependencies exempt", err)
+	}
+	if err := checkFailOn(plans, "major", nil); !errors.Is(err, ErrFailOn) {
+		t.Errorf("checkFailOn() without --since error = %v, want ErrFailOn", err)
+	}
+
+	if _, err := addedDependencies(ctx, eng, dir, "no-such-branch", plans); err == nil {
+		t.Error("addedDependencies() with unknown revision should return error")
+	}
+}
+
+func TestCheckFailOn_Levels(t *testing.T) {
+	plans := []*engine.UpdatePlan{{
+		Manifest: &engine.Manifest{Path: "package.json"},
+		Updates:  []engine.Update{{Dependency: engine.Dependency{Name: "express"}, Impact: "minor"}},
+	}}
+
+	if err := checkFailOn(plans, "", nil); err != nil {
+		t.Errorf("checkFailOn() without --fail-on error = %v", err)
+	}
+	if err := checkFailOn(plans, "major", nil); err != nil {
+		t.Errorf("checkFailOn(major) error = %v for a minor update", err)
+	}
+	if err := checkFailOn(plans, "minor", nil); !errors.Is(err, ErrFailOn) {
+		t.Errorf("checkFailOn(minor) error = %v, want ErrFailOn", err)
+	}
+	if err := checkFailOn(plans, "patch", nil); !errors.Is(err, ErrFailOn) {
+		t.Errorf("checkFailOn(patch) error = %v, want ErrFailOn", err)
+	}
+}
+
+func TestValidateFailOn(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		since   string
+		stdin   bool
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "level", level: "major"},
+		{name: "level and since", level: "minor", since: "origin/main"},
+		{name: "unknown level", level: "huge", wantErr: true},
+		{name: "since without level", since: "origin/main", wantErr: true},
+		{name: "since with stdin", level: "major", since: "HEAD", stdin: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFailOn(tt.level, tt.since, tt.stdin)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFailOn() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
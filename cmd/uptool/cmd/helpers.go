@@ -21,9 +21,15 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -44,6 +50,14 @@ func setupEngine() *engine.Engine {
 	}))
 
 	eng := engine.NewEngine(logger)
+	if concurrency > 0 {
+		if err := eng.SetConcurrency(concurrency); err != nil {
+			logger.Warn("ignoring concurrency", "error", err)
+		}
+	}
+	if deterministic {
+		eng.SetDeterministic(true)
+	}
 
 	// Load configuration if available
 	var cfg *policy.Config
@@ -75,6 +89,15 @@ func setupEngine() *engine.Engine {
 		logger.Warn("config file not found", "path", configPath)
 	}
 
+	// Private registries must be configured before any lookup is made
+	registriesPath := registriesFromDependabot
+	if registriesPath == "" && cfg != nil {
+		registriesPath = cfg.RegistriesFromDependabot
+	}
+	if registriesPath != "" {
+		configureDependabotRegistries(registriesPath, logger)
+	}
+
 	// Get all registered integrations from the global registry
 	allIntegrations := integrations.GetAll()
 
@@ -104,7 +127,7 @@ func setupEngine() *engine.Engine {
 
 		// Set integration policies for policy-aware version selection
 		// This implements the precedence: CLI flags > uptool.yaml > constraints
-		policies := buildPolicies(cfg)
+		policies := applyIntegrationConfig(buildPolicies(cfg), integrationConfig)
 		if len(policies) > 0 {
 			eng.SetPolicies(policies)
 			logger.Debug("set integration policies", "count", len(policies))
@@ -121,6 +144,10 @@ func setupEngine() *engine.Engine {
 		for _, integration := range allIntegrations {
 			eng.Register(integration)
 		}
+
+		if len(integrationConfig) > 0 {
+			eng.SetPolicies(applyIntegrationConfig(nil, integrationConfig))
+		}
 	}
 
 	return eng
@@ -152,7 +179,8 @@ func buildPolicies(cfg *policy.Config) map[string]engine.IntegrationPolicy {
 		}
 
 		// Only add policy if it has settings
-		if p.Update != "" || p.AllowPrerelease {
+		if p.Update != "" || p.AllowPrerelease || len(p.PrereleaseChannels) > 0 || p.PinDigest ||
+			p.AllowDeprecated || p.Cadence != "" || p.Schedule != nil || len(p.Custom) > 0 {
 			policies[ic.ID] = p
 		}
 	}
@@ -211,9 +239,189 @@ func parseFilters(only, exclude string) ([]string, []string) {
 	return onlyList, excludeList
 }
 
+// prereleaseCLIFlags builds CLI flag overrides from a comma-separated
+// --prerelease-channel value. Returns nil when no channels are given.
+func prereleaseCLIFlags(channels string) *engine.CLIFlags {
+	list, _ := parseFilters(channels, "")
+	if len(list) == 0 {
+		return nil
+	}
+	return &engine.CLIFlags{PrereleaseChannels: list}
+}
+
 // completeIntegrations provides shell completion for integration names
 func completeIntegrations(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	// Get list of available integrations
 	registered := integrations.List()
-	return registered, cobra.ShellCompDirectiveNoFileComp
+	sort.Strings(registered)
+	return completeCommaList(registered, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeDependencies provides shell completion for dependency names.
+// It runs a scan of the working directory (no registry lookups), honoring the
+// command's --only and --exclude flags when present.
+func completeDependencies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	repoRoot, err := os.Getwd()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var only, exclude string
+	if cmd != nil {
+		only, _ = cmd.Flags().GetString("only")       //nolint:errcheck // flag may not exist
+		exclude, _ = cmd.Flags().GetString("exclude") //nolint:errcheck // flag may not exist
+	}
+	onlyList, excludeList := parseFilters(only, exclude)
+
+	result, err := setupEngine().Scan(context.Background(), repoRoot, onlyList, excludeList)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, m := range result.Manifests {
+		for _, dep := range m.Dependencies {
+			if !seen[dep.Name] {
+				seen[dep.Name] = true
+				names = append(names, dep.Name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	return completeCommaList(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCommaList completes the last element of a comma-separated flag value.
+// Candidates already present earlier in the list are not offered again.
+func completeCommaList(candidates []string, toComplete string) []string {
+	prefix, current := "", toComplete
+	if idx := strings.LastIndex(toComplete, ","); idx >= 0 {
+		prefix, current = toComplete[:idx+1], toComplete[idx+1:]
+	}
+
+	used := make(map[string]bool)
+	for _, item := range strings.Split(prefix, ",") {
+		used[strings.TrimSpace(item)] = true
+	}
+
+	result := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if used[c] || !strings.HasPrefix(c, current) {
+			continue
+		}
+		result = append(result, prefix+c)
+	}
+	return result
+}
+
+// filterPlansByDependency keeps only updates for dependencies matching one of the
+// patterns. Patterns are dependency names or globs (e.g., "@types/*").
+func filterPlansByDependency(plans []*engine.UpdatePlan, patterns []string) []*engine.UpdatePlan {
+	if len(patterns) == 0 {
+		return plans
+	}
+
+	filtered := make([]*engine.UpdatePlan, 0, len(plans))
+	for _, plan := range plans {
+		p := *plan
+		p.Updates = make([]engine.Update, 0, len(plan.Updates))
+		for i := range plan.Updates {
+			if matchesAnyDependency(plan.Updates[i].Dependency.Name, patterns) {
+				p.Updates = append(p.Updates, plan.Updates[i])
+			}
+		}
+		p.RecordFiltered(plan.Updates)
+		filtered = append(filtered, &p)
+	}
+	return filtered
+}
+
+// matchesAnyDependency reports whether name equals or glob-matches any pattern.
+func matchesAnyDependency(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if pattern == name {
+			return true
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrRunErrors is returned by scan, plan, and update when --fail-on-error is set
+// and the run recorded integration errors.
+var ErrRunErrors = errors.New("errors were reported")
+
+// printErrors writes the errors section of table output to w. Nothing is
+// written when there are no errors or --quiet-errors is set.
+func printErrors(w io.Writer, errs []string) {
+	if len(errs) == 0 || quietErrors {
+		return
+	}
+
+	fmt.Fprintf(w, "\n%s\n", colorize(ansiRed, "Errors:"))
+	for _, e := range errs {
+		fmt.Fprintf(w, "  - %s\n", e)
+	}
+}
+
+// checkRunErrors fails the command with ErrRunErrors when --fail-on-error is set and
+// any of the error lists is non-empty. The errors are printed to stderr so they stand
+// out in CI logs regardless of the output format, unless --quiet-errors is set.
+func checkRunErrors(errLists ...[]string) error {
+	if !failOnError {
+		return nil
+	}
+
+	var all []string
+	for _, errs := range errLists {
+		all = append(all, errs...)
+	}
+	if len(all) == 0 {
+		return nil
+	}
+
+	if !quietErrors {
+		fmt.Fprintf(os.Stderr, "\n%d error(s) reported (--fail-on-error):\n", len(all))
+		for _, e := range all {
+			fmt.Fprintf(os.Stderr, "  - %s\n", e)
+		}
+	}
+
+	return fmt.Errorf("%w: %d", ErrRunErrors, len(all))
+}
+
+// loadResumeState loads the --resume state file, resolved against repoRoot.
+// It returns nil when resuming is not requested.
+func loadResumeState(repoRoot, statePath string) (*engine.ResumeState, error) {
+	if statePath == "" {
+		return nil, nil
+	}
+	if !filepath.IsAbs(statePath) {
+		statePath = filepath.Join(repoRoot, statePath)
+	}
+
+	state, err := engine.LoadResumeState(statePath)
+	if err != nil {
+		return nil, err
+	}
+	if n := state.Len(); n > 0 && !quietFlag {
+		fmt.Fprintf(os.Stderr, "Resuming: %d manifest(s) already planned\n", n)
+	}
+	return state, nil
+}
+
+// finishResume removes the --resume state once every manifest planned
+// cleanly; otherwise it is kept so the next run picks up where this one
+// stopped.
+func finishResume(state *engine.ResumeState, planErrors []string) {
+	if state == nil || len(planErrors) > 0 {
+		return
+	}
+	if err := state.Clear(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
 }
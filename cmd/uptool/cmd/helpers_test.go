@@ -21,12 +21,45 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
 	"github.com/santosr2/uptool/internal/policy"
 )
 
+const failingIntegrationName = "test-failing"
+
+func init() {
+	integrations.Register(failingIntegrationName, func() engine.Integration {
+		return failingIntegration{}
+	})
+}
+
+// failingIntegration records an error on every Detect call.
+type failingIntegration struct{}
+
+func (failingIntegration) Name() string { return failingIntegrationName }
+
+func (failingIntegration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	return nil, errors.New("registry unreachable")
+}
+
+func (failingIntegration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	return &engine.UpdatePlan{Manifest: manifest}, nil
+}
+
+func (failingIntegration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	return &engine.ApplyResult{Manifest: plan.Manifest}, nil
+}
+
+func (failingIntegration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	return nil
+}
+
 func TestBuildPolicies(t *testing.T) {
 	tests := []struct {
 		config        *policy.Config
@@ -186,6 +219,25 @@ func TestBuildPolicies(t *testing.T) {
 				"npm": false, // No settings, so not included
 			},
 		},
+		{
+			name: "cadence only - included for --due-only",
+			config: &policy.Config{
+				Version: 1,
+				Integrations: []policy.IntegrationConfig{
+					{
+						ID:      "npm",
+						Enabled: true,
+						Policy: engine.IntegrationPolicy{
+							Enabled: true,
+							Cadence: "weekly",
+						},
+					},
+				},
+			},
+			wantPolicies: map[string]bool{
+				"npm": true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -214,3 +266,145 @@ func TestBuildPolicies(t *testing.T) {
 		})
 	}
 }
+
+func TestFailOnError(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	origFail, origOnly, origFormat := failOnError, scanOnly, scanFormat
+	origPlanOnly, origUpdateOnly := planOnly, updateOnly
+	defer func() {
+		failOnError, scanOnly, scanFormat = origFail, origOnly, origFormat
+		planOnly, updateOnly = origPlanOnly, origUpdateOnly
+	}()
+	scanOnly, planOnly, updateOnly = failingIntegrationName, failingIntegrationName, failingIntegrationName
+	scanFormat = "json"
+
+	commands := map[string]func() error{
+		"scan":   func() error { return runScan(nil, nil) },
+		"plan":   func() error { return runPlan(nil, nil) },
+		"update": func() error { return runUpdate(nil, nil) },
+	}
+
+	for name, run := range commands {
+		t.Run(name+" fails with flag", func(t *testing.T) {
+			failOnError = true
+			var err error
+			captureStdout(t, func() { err = run() })
+			if !errors.Is(err, ErrRunErrors) {
+				t.Errorf("%s error = %v, want ErrRunErrors (exit code 1)", name, err)
+			}
+		})
+
+		t.Run(name+" succeeds without flag", func(t *testing.T) {
+			failOnError = false
+			var err error
+			captureStdout(t, func() { err = run() })
+			if err != nil {
+				t.Errorf("%s error = %v, want nil", name, err)
+			}
+		})
+	}
+}
+
+func TestCheckRunErrors(t *testing.T) {
+	orig := failOnError
+	defer func() { failOnError = orig }()
+
+	failOnError = true
+	if err := checkRunErrors(nil, []string{}); err != nil {
+		t.Errorf("checkRunErrors() with no errors = %v, want nil", err)
+	}
+	if err := checkRunErrors(nil, []string{"npm: boom"}); !errors.Is(err, ErrRunErrors) {
+		t.Errorf("checkRunErrors() = %v, want ErrRunErrors", err)
+	}
+
+	failOnError = false
+	if err := checkRunErrors([]string{"npm: boom"}); err != nil {
+		t.Errorf("checkRunErrors() without flag = %v, want nil", err)
+	}
+}
+
+func TestQuietErrors(t *testing.T) {
+	origFail, origQuiet, origColor := failOnError, quietErrors, colorEnabled
+	defer func() { failOnError, quietErrors, colorEnabled = origFail, origQuiet, origColor }()
+	colorEnabled = false
+
+	result := inventoryPlanResult()
+	result.Errors = []string{"rubygems: registry unavailable"}
+
+	t.Run("errors shown by default", func(t *testing.T) {
+		quietErrors = false
+		out := captureStdout(t, func() {
+			if err := outputPlanTable(result); err != nil {
+				t.Fatalf("outputPlanTable() error = %v", err)
+			}
+		})
+		if !strings.Contains(out, "Errors:") || !strings.Contains(out, "registry unavailable") {
+			t.Errorf("output missing errors section:\n%s", out)
+		}
+	})
+
+	t.Run("errors hidden but still fail the run", func(t *testing.T) {
+		quietErrors, failOnError = true, true
+		out := captureStdout(t, func() {
+			if err := outputPlanTable(result); err != nil {
+				t.Fatalf("outputPlanTable() error = %v", err)
+			}
+		})
+		if strings.Contains(out, "Errors:") || strings.Contains(out, "registry unavailable") {
+			t.Errorf("--quiet-errors output shows errors:\n%s", out)
+		}
+		if !strings.Contains(out, "express") {
+			t.Errorf("--quiet-errors output missing the plan:\n%s", out)
+		}
+
+		var err error
+		stderr := captureStderr(t, func() {
+			err = checkRunErrors(result.Errors)
+		})
+		if !errors.Is(err, ErrRunErrors) {
+			t.Errorf("checkRunErrors() = %v, want ErrRunErrors", err)
+		}
+		if stderr != "" {
+			t.Errorf("--quiet-errors stderr = %q, want nothing", stderr)
+		}
+	})
+
+	t.Run("errors hidden without fail-on-error exit zero", func(t *testing.T) {
+		quietErrors, failOnError = true, false
+		if err := checkRunErrors(result.Errors); err != nil {
+			t.Errorf("checkRunErrors() = %v, want nil", err)
+		}
+	})
+}
+
+func TestResolveConcurrency(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		flag    int
+		want    int
+		flagSet bool
+		wantErr bool
+	}{
+		{name: "default", want: 0},
+		{name: "flag", flagSet: true, flag: 8, want: 8},
+		{name: "flag overrides env", flagSet: true, flag: 2, env: "12", want: 2},
+		{name: "env", env: "12", want: 12},
+		{name: "flag below 1", flagSet: true, flag: 0, wantErr: true},
+		{name: "env below 1", env: "0", wantErr: true},
+		{name: "env not a number", env: "many", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveConcurrency(tt.flagSet, tt.flag, tt.env)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveConcurrency() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("resolveConcurrency() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
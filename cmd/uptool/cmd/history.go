@@ -0,0 +1,241 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/history"
+)
+
+var (
+	historySince  string
+	historyUntil  string
+	historyFormat string
+	historyFile   string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show dependency updates applied by uptool",
+	Long: `Show the dependency updates applied by 'uptool update'.
+
+'uptool update --record-history' appends the applied updates to a log,
+.uptool/history.jsonl by default. Nothing is recorded without that flag.
+This command reads the log and prints the updates within a date range,
+with the old and new versions and the commit message for each. Use --file
+when the log was recorded elsewhere.
+
+Dates use the YYYY-MM-DD format (local time) or RFC 3339. The --until
+date is inclusive: a plain date covers the whole day.`,
+	Example: `  # Show all recorded updates
+  uptool history
+
+  # Show updates applied in the first quarter
+  uptool history --since 2026-01-01 --until 2026-03-31
+
+  # Export the audit as JSON
+  uptool history --since 2026-01-01 --format json`,
+	RunE: runHistory,
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only show updates applied on or after this date")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "only show updates applied on or before this date")
+	historyCmd.Flags().StringVarP(&historyFormat, "format", "f", "table", "output format: table, json")
+	historyCmd.Flags().StringVar(&historyFile, "file", history.DefaultPath, "history log written by 'uptool update --record-history'; relative paths are resolved from the working directory")
+
+	_ = historyCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) { //nolint:errcheck // best effort completion
+		return []string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	since, err := parseHistoryDate(historySince, false)
+	if err != nil {
+		return fmt.Errorf("invalid --since: %w", err)
+	}
+	until, err := parseHistoryDate(historyUntil, true)
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+	if !since.IsZero() && !until.IsZero() && until.Before(since) {
+		return fmt.Errorf("--until must not be before --since")
+	}
+
+	repoRoot, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	entries, err := history.Read(resolveHistoryPath(repoRoot, historyFile))
+	if err != nil {
+		return err
+	}
+	entries = history.Filter(entries, since, until)
+
+	switch historyFormat {
+	case "json":
+		return outputJSON(entries)
+	case "table":
+		return outputHistoryTable(entries)
+	default:
+		return fmt.Errorf("unknown format: %s", historyFormat)
+	}
+}
+
+// resolveHistoryPath resolves a history log path given on the command line
+// against the repository root.
+func resolveHistoryPath(repoRoot, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(repoRoot, path)
+}
+
+// parseHistoryDate parses a YYYY-MM-DD or RFC 3339 date. An empty string yields
+// the zero time. With endOfDay set, a plain date is moved to the last instant of that day.
+func parseHistoryDate(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected YYYY-MM-DD or RFC 3339, got %q", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// outputHistoryTable prints history entries in a human-readable table.
+func outputHistoryTable(entries []history.Entry) error {
+	if len(entries) == 0 {
+		fmt.Println("No updates recorded in this range.")
+		return nil
+	}
+
+	fmt.Printf("%-20s %-30s %-35s %-15s %-15s\n", "Applied", "Manifest", "Package", "From", "To")
+	fmt.Println(strings.Repeat("-", 118))
+
+	for i := range entries {
+		e := &entries[i]
+
+		manifest := e.Manifest
+		if len(manifest) > 30 {
+			manifest = "..." + manifest[len(manifest)-27:]
+		}
+		pkg := e.Name
+		if len(pkg) > 35 {
+			pkg = pkg[:32] + "..."
+		}
+
+		fmt.Printf("%-20s %-30s %-35s %-15s %-15s\n",
+			e.Timestamp.Local().Format("2006-01-02 15:04"), manifest, pkg, e.FromVersion, e.ToVersion)
+		if e.CommitMessage != "" {
+			// Only the subject line; bodies repeat the update details
+			subject, _, _ := strings.Cut(e.CommitMessage, "\n")
+			fmt.Printf("  %s\n", subject)
+		}
+	}
+
+	fmt.Printf("\nTotal: %d updates\n", len(entries))
+	return nil
+}
+
+// appliedHistoryEntries builds history entries for the updates that were applied.
+func appliedHistoryEntries(eng *engine.Engine, plans []*engine.UpdatePlan, updateResult *engine.UpdateResult) []history.Entry {
+	// --deterministic pins result timestamps; history still records when updates happened
+	at := updateResult.Timestamp
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var entries []history.Entry
+	for _, applied := range appliedPlans(plans, updateResult) {
+		message := eng.GetUpdateFilter(applied.Manifest.Type).FormatCommitMessage(applied.Updates, applied.Manifest.Path)
+		entries = append(entries, history.EntriesFromPlan(applied, message, at)...)
+	}
+
+	return entries
+}
+
+// appliedPlans returns copies of plans reduced to the updates that were applied.
+// Results without applied updates are ignored. A result that reports failures
+// contributes only the updates its integration names in AppliedUpdates; when
+// the integration does not report them, the manifest is left out rather than
+// guessed at.
+func appliedPlans(plans []*engine.UpdatePlan, updateResult *engine.UpdateResult) []*engine.UpdatePlan {
+	plansByPath := make(map[string]*engine.UpdatePlan, len(plans))
+	for _, plan := range plans {
+		plansByPath[plan.Manifest.Path] = plan
+	}
+
+	var applied []*engine.UpdatePlan
+	for _, result := range updateResult.Results {
+		if result.Applied == 0 {
+			continue
+		}
+
+		plan, ok := plansByPath[result.Manifest.Path]
+		if !ok {
+			continue
+		}
+
+		done := *plan
+		switch {
+		case result.AppliedUpdates != nil:
+			names := make(map[string]bool, len(result.AppliedUpdates))
+			for _, name := range result.AppliedUpdates {
+				names[name] = true
+			}
+			done.Updates = make([]engine.Update, 0, len(plan.Updates))
+			for i := range plan.Updates {
+				if names[plan.Updates[i].Dependency.Name] {
+					done.Updates = append(done.Updates, plan.Updates[i])
+				}
+			}
+		case result.Failed > 0:
+			continue
+		}
+		if len(done.Updates) == 0 {
+			continue
+		}
+		applied = append(applied, &done)
+	}
+
+	return applied
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestParseHistoryDate(t *testing.T) {
+	t.Run("empty is open", func(t *testing.T) {
+		got, err := parseHistoryDate("", false)
+		if err != nil || !got.IsZero() {
+			t.Errorf("parseHistoryDate(\"\") = %v, %v; want zero time", got, err)
+		}
+	})
+
+	t.Run("plain date end of day", func(t *testing.T) {
+		got, err := parseHistoryDate("2026-03-31", true)
+		if err != nil {
+			t.Fatalf("parseHistoryDate() error = %v", err)
+		}
+		want := time.Date(2026, 3, 31, 23, 59, 59, 999999999, time.Local)
+		if !got.Equal(want) {
+			t.Errorf("parseHistoryDate() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("rfc3339", func(t *testing.T) {
+		got, err := parseHistoryDate("2026-03-31T12:00:00Z", true)
+		if err != nil {
+			t.Fatalf("parseHistoryDate() error = %v", err)
+		}
+		if !got.Equal(time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("parseHistoryDate() = %v", got)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := parseHistoryDate("31/03/2026", false); err == nil {
+			t.Error("parseHistoryDate() expected error")
+		}
+	})
+}
+
+func TestAppliedHistoryEntries(t *testing.T) {
+	express := engine.Update{
+		Dependency:    engine.Dependency{Name: "express", CurrentVersion: "4.17.0"},
+		TargetVersion: "4.18.2",
+	}
+	lodash := engine.Update{
+		Dependency:    engine.Dependency{Name: "lodash", CurrentVersion: "4.17.20"},
+		TargetVersion: "4.17.21",
+	}
+
+	npm := &engine.Manifest{Path: "package.json", Type: "npm"}
+	tf := &engine.Manifest{Path: "main.tf", Type: "terraform"}
+	plans := []*engine.UpdatePlan{
+		{Manifest: npm, Updates: []engine.Update{express, lodash}},
+		{Manifest: tf, Updates: []engine.Update{{Dependency: engine.Dependency{Name: "aws"}, TargetVersion: "5.0.0"}}},
+	}
+
+	result := &engine.UpdateResult{
+		Timestamp: time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC),
+		Results: []*engine.ApplyResult{
+			{Manifest: npm, Applied: 1, Failed: 1, AppliedUpdates: []string{"express"}, Errors: []string{"invalid version for lodash: 4.17.21"}},
+			{Manifest: tf, Applied: 0},
+		},
+	}
+
+	entries := appliedHistoryEntries(engine.NewEngine(nil), plans, result)
+	if len(entries) != 1 {
+		t.Fatalf("appliedHistoryEntries() returned %d entries, want 1", len(entries))
+	}
+	if entries[0].Name != "express" || entries[0].ToVersion != "4.18.2" {
+		t.Errorf("entry = %+v, want express 4.18.2", entries[0])
+	}
+	if entries[0].CommitMessage != "chore(deps): update express from 4.17.0 to 4.18.2" {
+		t.Errorf("CommitMessage = %q", entries[0].CommitMessage)
+	}
+}
+
+func TestAppliedPlans(t *testing.T) {
+	react := engine.Update{Dependency: engine.Dependency{Name: "react"}, TargetVersion: "18.2.0"}
+	reactDOM := engine.Update{Dependency: engine.Dependency{Name: "react-dom"}, TargetVersion: "18.2.0"}
+
+	tests := []struct {
+		name   string
+		result *engine.ApplyResult
+		want   []string
+	}{
+		{
+			name:   "all applied",
+			result: &engine.ApplyResult{Applied: 2},
+			want:   []string{"react", "react-dom"},
+		},
+		{
+			name:   "partly applied",
+			result: &engine.ApplyResult{Applied: 1, Failed: 1, AppliedUpdates: []string{"react-dom"}, Errors: []string{"react: version 18.2.0 not found"}},
+			want:   []string{"react-dom"},
+		},
+		{
+			name:   "failures without outcomes",
+			result: &engine.ApplyResult{Applied: 1, Failed: 1, Errors: []string{"react-dom: failed"}},
+		},
+		{
+			name:   "skipped on conflict",
+			result: &engine.ApplyResult{Failed: 2, Errors: []string{"skipped: package.json changed since scan"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest := &engine.Manifest{Path: "package.json", Type: "npm"}
+			plans := []*engine.UpdatePlan{{Manifest: manifest, Updates: []engine.Update{react, reactDOM}}}
+			tt.result.Manifest = manifest
+
+			var got []string
+			for _, plan := range appliedPlans(plans, &engine.UpdateResult{Results: []*engine.ApplyResult{tt.result}}) {
+				for i := range plan.Updates {
+					got = append(got, plan.Updates[i].Dependency.Name)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("appliedPlans() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
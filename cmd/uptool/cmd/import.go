@@ -0,0 +1,83 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	importOutputFlag string
+	importDryRunFlag bool
+	importForceFlag  bool
+
+	importCmd = &cobra.Command{
+		Use:   "import",
+		Short: "Import configuration from another dependency update tool",
+		Long: `Import configuration from another dependency update tool into uptool.yaml.
+
+Run "uptool import <tool> --help" for the settings each importer translates.`,
+	}
+
+	importDependabotCmd = &cobra.Command{
+		Use:   "dependabot [path]",
+		Short: "Convert dependabot.yml to uptool.yaml",
+		Long: `Convert a dependabot.yml configuration to uptool.yaml.
+
+Each entry under "updates" becomes an integration whose ecosystem is mapped to
+the matching uptool integration, with its schedule, groups, allow and ignore
+rules, cooldown, open-pull-requests-limit, labels, assignees, reviewers, and
+commit-message carried over to the integration policy.
+
+Settings that have no uptool equivalent (for example target-branch,
+rebase-strategy, or milestone) are listed in the summary printed after the
+conversion and left out of the generated file.
+
+When no path is given, .github/dependabot.yml, .dependabot/dependabot.yml, and
+dependabot.yml are tried in that order (with .yml or .yaml extensions).
+
+Example:
+  # Convert the repository's dependabot.yml
+  uptool import dependabot
+
+  # Convert a specific file and preview the result
+  uptool import dependabot .github/dependabot.yml --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runImportDependabot,
+	}
+)
+
+func init() {
+	importDependabotCmd.Flags().StringVarP(&importOutputFlag, "output", "o", "uptool.yaml", "output path for uptool.yaml")
+	importDependabotCmd.Flags().BoolVar(&importDryRunFlag, "dry-run", false, "print the generated configuration without writing it")
+	importDependabotCmd.Flags().BoolVarP(&importForceFlag, "force", "f", false, "overwrite an existing output file")
+
+	importCmd.AddCommand(importDependabotCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImportDependabot(cmd *cobra.Command, args []string) error {
+	var sourcePath string
+	if len(args) > 0 {
+		sourcePath = args[0]
+	}
+	return migrateDependabotConfig(sourcePath, importOutputFlag, importDryRunFlag, importForceFlag)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/policy"
+)
+
+func TestMigrateDependabotConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	source := filepath.Join(".dependabot", "dependabot.yml")
+	if err := os.MkdirAll(filepath.Dir(source), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := `version: 2
+updates:
+  - package-ecosystem: "npm"
+    directory: "/"
+    schedule:
+      interval: "daily"
+    target-branch: "develop"
+    labels: ["deps"]
+`
+	if err := os.WriteFile(source, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := migrateDependabotConfig("", "uptool.yaml", false, false); err != nil {
+		t.Fatalf("migrateDependabotConfig() error = %v", err)
+	}
+
+	cfg, err := policy.LoadConfig(filepath.Join(dir, "uptool.yaml"))
+	if err != nil {
+		t.Fatalf("policy.LoadConfig() error = %v", err)
+	}
+	pol, ok := cfg.ToPolicyMap()["npm"]
+	if !ok {
+		t.Fatalf("integrations = %+v, want npm", cfg.Integrations)
+	}
+	if pol.Schedule == nil || pol.Schedule.Interval != "daily" {
+		t.Errorf("Schedule = %+v, want daily", pol.Schedule)
+	}
+	if len(pol.Labels) != 1 || pol.Labels[0] != "deps" {
+		t.Errorf("Labels = %v, want [deps]", pol.Labels)
+	}
+
+	if err := migrateDependabotConfig(source, "uptool.yaml", false, false); err == nil {
+		t.Error("migrateDependabotConfig() should refuse to overwrite without force")
+	}
+}
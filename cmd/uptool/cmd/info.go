@@ -0,0 +1,188 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// maxInfoCommits bounds the commits --fetch-info lists for each update.
+const maxInfoCommits = 20
+
+// releaseInfoSource fetches release notes and the commits between two tags
+// of a GitHub repository.
+type releaseInfoSource interface {
+	GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*registry.Release, error)
+	CompareCommits(ctx context.Context, owner, repo, base, head string, limit int) ([]registry.Commit, error)
+}
+
+// newReleaseInfoSource returns a GitHub client, authenticated with
+// GITHUB_TOKEN when it is set to raise the API rate limit.
+func newReleaseInfoSource() releaseInfoSource {
+	return registry.NewGitHubClient(os.Getenv("GITHUB_TOKEN"), registry.DefaultGitHubMaxRetries, registry.DefaultGitHubMaxWait)
+}
+
+// infoDatasources maps integrations to the datasource whose package metadata
+// names the source repository of their dependencies.
+var infoDatasources = map[string]string{
+	"npm":   "npm",
+	"cargo": "crates",
+}
+
+// dependencyRepository returns the source repository URL of a dependency, or
+// "" when it is unknown. GitHub Actions and github.com Go modules name their
+// repository directly; npm and Cargo packages declare it in their metadata.
+func dependencyRepository(ctx context.Context, integration, name string) string {
+	switch integration {
+	case "actions":
+		parts := strings.SplitN(name, "/", 3)
+		if len(parts) < 2 {
+			return ""
+		}
+		return "https://github.com/" + parts[0] + "/" + parts[1]
+	case "gomod":
+		if strings.HasPrefix(name, "github.com/") {
+			return "https://" + name
+		}
+		return ""
+	}
+
+	dsName, ok := infoDatasources[integration]
+	if !ok {
+		return ""
+	}
+	ds, err := datasource.Get(dsName)
+	if err != nil {
+		return ""
+	}
+	info, err := ds.GetPackageInfo(ctx, name)
+	if err != nil || info == nil {
+		return ""
+	}
+	return info.Repository
+}
+
+// setUpdateInfo records on each update whose source repository is on GitHub
+// the release notes of its target version and the commits since its current
+// version. Releases and tags that cannot be found are left out; an update
+// whose repository is unknown gets no info.
+func setUpdateInfo(ctx context.Context, source releaseInfoSource, plans []*engine.UpdatePlan, repositoryOf func(ctx context.Context, integration, name string) string) {
+	repos := make(map[string]string)
+	for _, plan := range plans {
+		for i := range plan.Updates {
+			u := &plan.Updates[i]
+
+			key := plan.Manifest.Type + "\x00" + u.Dependency.Name
+			url, ok := repos[key]
+			if !ok {
+				url = repositoryOf(ctx, plan.Manifest.Type, u.Dependency.Name)
+				repos[key] = url
+			}
+			if !strings.HasPrefix(url, "https://github.com/") {
+				continue
+			}
+			owner, repo, err := registry.ParseGitHubURL(url)
+			if err != nil {
+				continue
+			}
+
+			u.Info = fetchUpdateInfo(ctx, source, owner, repo, u.Dependency.CurrentVersion, u.TargetVersion)
+		}
+	}
+}
+
+// setCompatibilityScores records on each update an estimate of how safely it
+// applies, from its impact, its release notes and how long its target
+// version has been out. It runs after setUpdateAges and setUpdateInfo so
+// both inputs are known; updates without info get one holding just the score.
+func setCompatibilityScores(plans []*engine.UpdatePlan, now time.Time) {
+	for _, plan := range plans {
+		for i := range plan.Updates {
+			u := &plan.Updates[i]
+
+			var releaseAge time.Duration
+			if u.Age != nil && !u.Age.TargetReleasedAt.IsZero() {
+				releaseAge = now.Sub(u.Age.TargetReleasedAt)
+			}
+			if u.Info == nil {
+				u.Info = &engine.UpdateInfo{}
+			}
+			u.Info.CompatibilityScore = engine.ComputeCompatibilityScore(*u, releaseAge, u.Info.ReleaseNotes)
+		}
+	}
+}
+
+// fetchUpdateInfo looks up the release of target and the commits from
+// current to target. Tags are tried with and without a "v" prefix, since
+// projects differ on whether they use one.
+func fetchUpdateInfo(ctx context.Context, source releaseInfoSource, owner, repo, current, target string) *engine.UpdateInfo {
+	info := &engine.UpdateInfo{SourceURL: "https://github.com/" + owner + "/" + repo}
+
+	base, head := tagCandidates(current), tagCandidates(target)
+	for j, tag := range head {
+		release, err := source.GetReleaseByTag(ctx, owner, repo, tag)
+		if err != nil {
+			continue
+		}
+		info.ReleaseNotes = release.Body
+		info.ReleaseURL = release.HTMLURL
+		// Compare with the tag style the release uses first
+		head[0], head[j] = head[j], head[0]
+		base[0], base[j] = base[j], base[0]
+		break
+	}
+
+	for j := range head {
+		commits, err := source.CompareCommits(ctx, owner, repo, base[j], head[j], maxInfoCommits)
+		if err != nil {
+			continue
+		}
+		for _, c := range commits {
+			commit := engine.CommitInfo{
+				SHA:     c.SHA,
+				Message: strings.TrimSpace(strings.SplitN(c.Commit.Message, "\n", 2)[0]),
+				Author:  c.Commit.Author.Name,
+				URL:     c.HTMLURL,
+			}
+			if c.Author != nil && c.Author.Login != "" {
+				commit.Author = c.Author.Login
+			}
+			info.Commits = append(info.Commits, commit)
+		}
+		break
+	}
+
+	return info
+}
+
+// tagCandidates returns the tags a version may be published under: with a
+// "v" prefix, then without. Range operators are stripped.
+func tagCandidates(version string) []string {
+	bare := strings.TrimPrefix(strings.TrimLeft(strings.TrimSpace(version), "^~=<> "), "v")
+	return []string{"v" + bare, bare}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// mockReleaseInfo serves releases keyed by "owner/repo@tag" and commit
+// ranges keyed by "owner/repo@base...head"; anything else is not found.
+type mockReleaseInfo struct {
+	releases map[string]*registry.Release
+	compares map[string][]registry.Commit
+	limits   []int
+}
+
+func (m *mockReleaseInfo) GetReleaseByTag(_ context.Context, owner, repo, tag string) (*registry.Release, error) {
+	if release, ok := m.releases[owner+"/"+repo+"@"+tag]; ok {
+		return release, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func (m *mockReleaseInfo) CompareCommits(_ context.Context, owner, repo, base, head string, limit int) ([]registry.Commit, error) {
+	m.limits = append(m.limits, limit)
+	if commits, ok := m.compares[owner+"/"+repo+"@"+base+"..."+head]; ok {
+		return commits, nil
+	}
+	return nil, registry.ErrNotFound
+}
+
+func commit(sha, message, login string) registry.Commit {
+	c := registry.Commit{SHA: sha, HTMLURL: "https://github.com/o/r/commit/" + sha}
+	c.Commit.Message = message
+	c.Commit.Author.Name = "Committer"
+	if login != "" {
+		c.Author = &struct {
+			Login string `json:"login"`
+		}{Login: login}
+	}
+	return c
+}
+
+func TestSetUpdateInfo(t *testing.T) {
+	source := &mockReleaseInfo{
+		releases: map[string]*registry.Release{
+			"expressjs/express@4.19.0": {Body: "## Fixes\n* Routing", HTMLURL: "https://github.com/expressjs/express/releases/tag/4.19.0"},
+		},
+		compares: map[string][]registry.Commit{
+			"expressjs/express@4.18.0...4.19.0": {
+				commit("a1", "Fix routing\n\nLonger description", "jane"),
+				commit("b2", "Release 4.19.0", ""),
+			},
+			"actions/checkout@v4.1.0...v4.2.0": {commit("c3", "Add sparse checkout", "octocat")},
+		},
+	}
+
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "express", CurrentVersion: "^4.18.0"}, TargetVersion: "4.19.0"},
+				{Dependency: engine.Dependency{Name: "left-pad", CurrentVersion: "1.0.0"}, TargetVersion: "1.3.0"},
+			},
+		},
+		{
+			Manifest: &engine.Manifest{Path: ".github/workflows/ci.yml", Type: "actions"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "actions/checkout", CurrentVersion: "v4.1.0"}, TargetVersion: "v4.2.0"},
+			},
+		},
+	}
+
+	repositories := map[string]string{
+		"npm\x00express":              "https://github.com/expressjs/express",
+		"npm\x00left-pad":             "https://gitlab.com/someone/left-pad",
+		"actions\x00actions/checkout": "https://github.com/actions/checkout",
+	}
+	lookups := 0
+	repositoryOf := func(_ context.Context, integration, name string) string {
+		lookups++
+		return repositories[integration+"\x00"+name]
+	}
+
+	setUpdateInfo(context.Background(), source, plans, repositoryOf)
+
+	want := &engine.UpdateInfo{
+		ReleaseNotes: "## Fixes\n* Routing",
+		SourceURL:    "https://github.com/expressjs/express",
+		ReleaseURL:   "https://github.com/expressjs/express/releases/tag/4.19.0",
+		Commits: []engine.CommitInfo{
+			{SHA: "a1", Message: "Fix routing", Author: "jane", URL: "https://github.com/o/r/commit/a1"},
+			{SHA: "b2", Message: "Release 4.19.0", Author: "Committer", URL: "https://github.com/o/r/commit/b2"},
+		},
+	}
+	if got := plans[0].Updates[0].Info; !reflect.DeepEqual(got, want) {
+		t.Errorf("express Info = %+v, want %+v", got, want)
+	}
+
+	if got := plans[0].Updates[1].Info; got != nil {
+		t.Errorf("left-pad Info = %+v, want nil for a repository outside GitHub", got)
+	}
+
+	checkout := plans[1].Updates[0].Info
+	if checkout == nil || checkout.ReleaseNotes != "" || len(checkout.Commits) != 1 || checkout.Commits[0].Message != "Add sparse checkout" {
+		t.Errorf("checkout Info = %+v, want commits without release notes", checkout)
+	}
+	if checkout != nil && checkout.SourceURL != "https://github.com/actions/checkout" {
+		t.Errorf("checkout SourceURL = %q", checkout.SourceURL)
+	}
+
+	for _, limit := range source.limits {
+		if limit != maxInfoCommits {
+			t.Errorf("CompareCommits() limit = %d, want %d", limit, maxInfoCommits)
+		}
+	}
+	if lookups != 3 {
+		t.Errorf("repository lookups = %d, want 3", lookups)
+	}
+}
+
+func TestDependencyRepository(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		integration string
+		name        string
+		want        string
+	}{
+		{"actions", "actions/checkout", "https://github.com/actions/checkout"},
+		{"actions", "github/codeql-action/init", "https://github.com/github/codeql-action"},
+		{"gomod", "github.com/spf13/cobra", "https://github.com/spf13/cobra"},
+		{"gomod", "golang.org/x/mod", ""},
+		{"helm", "nginx", ""},
+	}
+
+	for _, tt := range tests {
+		if got := dependencyRepository(ctx, tt.integration, tt.name); got != tt.want {
+			t.Errorf("dependencyRepository(%q, %q) = %q, want %q", tt.integration, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSetCompatibilityScores(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+			Updates: []engine.Update{
+				{
+					Dependency: engine.Dependency{Name: "express"},
+					Impact:     "major",
+					Age:        &engine.UpdateAge{TargetReleasedAt: now.Add(-2 * time.Hour)},
+					Info:       &engine.UpdateInfo{ReleaseNotes: "BREAKING CHANGE: drops Node 16", SourceURL: "https://github.com/expressjs/express"},
+				},
+				{
+					Dependency: engine.Dependency{Name: "left-pad"},
+					Impact:     "patch",
+					Age:        &engine.UpdateAge{TargetReleasedAt: now.AddDate(0, -6, 0)},
+				},
+			},
+		},
+	}
+
+	setCompatibilityScores(plans, now)
+
+	express := plans[0].Updates[0].Info
+	if want := engine.ComputeCompatibilityScore(plans[0].Updates[0], 2*time.Hour, express.ReleaseNotes); express.CompatibilityScore != want {
+		t.Errorf("express CompatibilityScore = %d, want %d", express.CompatibilityScore, want)
+	}
+	if express.SourceURL != "https://github.com/expressjs/express" {
+		t.Errorf("express SourceURL = %q, want existing info kept", express.SourceURL)
+	}
+
+	leftPad := plans[0].Updates[1].Info
+	if leftPad == nil {
+		t.Fatal("left-pad Info = nil, want info holding the score")
+	}
+	if leftPad.CompatibilityScore != 100 {
+		t.Errorf("left-pad CompatibilityScore = %d, want 100 for a proven patch", leftPad.CompatibilityScore)
+	}
+	if express.CompatibilityScore >= leftPad.CompatibilityScore {
+		t.Errorf("fresh breaking major scored %d, not below proven patch %d", express.CompatibilityScore, leftPad.CompatibilityScore)
+	}
+}
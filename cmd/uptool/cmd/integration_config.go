@@ -0,0 +1,89 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// integrationConfig holds the parsed --integration-config values, keyed by
+// integration then by setting name.
+var integrationConfig map[string]map[string]interface{}
+
+// parseIntegrationConfig parses INTEGRATION.KEY=VALUE pairs. Values are
+// decoded as YAML, so "true" and "3" arrive as a bool and an int, as they
+// would from uptool.yaml; a value that is not valid YAML, or is empty, stays a
+// string. A later pair overrides an earlier one.
+func parseIntegrationConfig(pairs []string) (map[string]map[string]interface{}, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	result := make(map[string]map[string]interface{})
+	for _, pair := range pairs {
+		name, raw, ok := strings.Cut(pair, "=")
+		integration, key, dotted := strings.Cut(name, ".")
+		if !ok || !dotted || integration == "" || key == "" {
+			return nil, fmt.Errorf("invalid --integration-config %q: expected INTEGRATION.KEY=VALUE", pair)
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(raw), &value); err != nil || value == nil {
+			value = raw
+		}
+
+		if result[integration] == nil {
+			result[integration] = make(map[string]interface{})
+		}
+		result[integration][key] = value
+	}
+	return result, nil
+}
+
+// applyIntegrationConfig merges custom settings into the Custom map of each
+// integration's policy, overriding uptool.yaml. An integration without a
+// policy gets one holding only the custom settings. policies is not modified.
+func applyIntegrationConfig(policies map[string]engine.IntegrationPolicy, custom map[string]map[string]interface{}) map[string]engine.IntegrationPolicy {
+	if len(custom) == 0 {
+		return policies
+	}
+
+	result := make(map[string]engine.IntegrationPolicy, len(policies)+len(custom))
+	maps.Copy(result, policies)
+	for integration, settings := range custom {
+		p, ok := result[integration]
+		if !ok {
+			p = engine.IntegrationPolicy{Enabled: true}
+		}
+		merged := make(map[string]interface{}, len(p.Custom)+len(settings))
+		maps.Copy(merged, p.Custom)
+		maps.Copy(merged, settings)
+		p.Custom = merged
+		result[integration] = p
+	}
+	return result
+}
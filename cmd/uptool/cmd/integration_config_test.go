@@ -0,0 +1,137 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// customIntegration stands in for a plugin that reads its settings from the
+// policy's Custom map.
+type customIntegration struct {
+	got map[string]interface{}
+}
+
+func (*customIntegration) Name() string { return "custom-plugin" }
+
+func (*customIntegration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	return []*engine.Manifest{{Path: "custom.txt", Type: "custom-plugin"}}, nil
+}
+
+func (c *customIntegration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	if planCtx.Policy != nil {
+		c.got = planCtx.Policy.Custom
+	}
+	return &engine.UpdatePlan{Manifest: manifest}, nil
+}
+
+func (*customIntegration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	return &engine.ApplyResult{Manifest: plan.Manifest}, nil
+}
+
+func (*customIntegration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	return nil
+}
+
+func TestParseIntegrationConfig(t *testing.T) {
+	got, err := parseIntegrationConfig([]string{"npm.foo=bar", "npm.retries=3", "npm.strict=true", "helm.url=https://x.example/a=b", "npm.foo=baz", "npm.empty="})
+	if err != nil {
+		t.Fatalf("parseIntegrationConfig() error = %v", err)
+	}
+
+	want := map[string]interface{}{"foo": "baz", "retries": 3, "strict": true, "empty": ""}
+	for key, value := range want {
+		if got["npm"][key] != value {
+			t.Errorf("npm.%s = %#v, want %#v", key, got["npm"][key], value)
+		}
+	}
+	if got["helm"]["url"] != "https://x.example/a=b" {
+		t.Errorf("helm.url = %#v, want the text after the first =", got["helm"]["url"])
+	}
+
+	for _, bad := range []string{"npm", "npm=bar", "npm.=bar", ".foo=bar", "npm.foo"} {
+		if _, err := parseIntegrationConfig([]string{bad}); err == nil {
+			t.Errorf("parseIntegrationConfig(%q) expected error", bad)
+		}
+	}
+}
+
+func TestIntegrationConfig_ReachesPlugin(t *testing.T) {
+	custom, err := parseIntegrationConfig([]string{"custom-plugin.endpoint=https://internal.example", "custom-plugin.retries=2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := map[string]engine.IntegrationPolicy{
+		"custom-plugin": {Enabled: true, Update: "minor", Custom: map[string]interface{}{"endpoint": "from-yaml", "team": "infra"}},
+	}
+
+	plugin := &customIntegration{}
+	eng := engine.NewEngine(nil)
+	eng.Register(plugin)
+	eng.SetPolicies(applyIntegrationConfig(existing, custom))
+
+	scanResult, err := eng.Scan(context.Background(), t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := eng.Plan(context.Background(), scanResult.Manifests); err != nil {
+		t.Fatal(err)
+	}
+
+	if endpoint, ok := plugin.got["endpoint"].(string); !ok || endpoint != "https://internal.example" {
+		t.Errorf("endpoint = %#v, want the --integration-config value", plugin.got["endpoint"])
+	}
+	if retries, ok := plugin.got["retries"].(int); !ok || retries != 2 {
+		t.Errorf("retries = %#v, want 2", plugin.got["retries"])
+	}
+	if plugin.got["team"] != "infra" {
+		t.Errorf("team = %#v, want the uptool.yaml value kept", plugin.got["team"])
+	}
+	if existing["custom-plugin"].Custom["endpoint"] != "from-yaml" {
+		t.Error("applyIntegrationConfig modified the uptool.yaml policies")
+	}
+}
+
+func TestSetupEngine_IntegrationConfig(t *testing.T) {
+	t.Chdir(t.TempDir())
+	config := "version: 1\nintegrations:\n  - id: npm\n    enabled: true\n    policy:\n      enabled: true\n      update: minor\n      registry_scope: acme\n"
+	if err := os.WriteFile("uptool.yaml", []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := integrationConfig
+	defer func() { integrationConfig = orig }()
+	integrationConfig = map[string]map[string]interface{}{"helm": {"mirror": "https://charts.example"}}
+
+	eng := setupEngine()
+
+	if p := eng.GetPolicy("npm"); p == nil || p.Custom["registry_scope"] != "acme" {
+		t.Errorf("npm policy = %+v, want custom registry_scope from uptool.yaml", p)
+	}
+	if p := eng.GetPolicy("helm"); p == nil || p.Custom["mirror"] != "https://charts.example" {
+		t.Errorf("helm policy = %+v, want custom mirror from --integration-config", p)
+	}
+}
@@ -22,24 +22,41 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/integrations"
 )
 
 var (
 	listCategory     string
 	listExperimental bool
+	listJSON         bool
 )
 
+// integrationEntry describes one integration in the list --json catalog.
+type integrationEntry struct {
+	Name         string `json:"name"`
+	DisplayName  string `json:"display_name,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Category     string `json:"category,omitempty"`
+	Disabled     bool   `json:"disabled"`
+	Experimental bool   `json:"experimental"`
+	Plugin       bool   `json:"plugin"`
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List available integrations",
 	Long: `List all available integrations and their status.
 
-Integrations can be filtered by category or include experimental ones.`,
+Integrations can be filtered by category or include experimental ones.
+With --verbose, the manifest files each integration handles are listed too.
+With --json, the catalog is printed as a JSON array sorted by name, including
+integrations loaded from plugins.`,
 	Example: `  # List all integrations
   uptool list
 
@@ -47,7 +64,13 @@ Integrations can be filtered by category or include experimental ones.`,
   uptool list --category package-manager
 
   # Include experimental integrations
-  uptool list --experimental`,
+  uptool list --experimental
+
+  # Show the files each integration handles
+  uptool list -v
+
+  # Print the catalog as JSON
+  uptool list --json --experimental`,
 	RunE: runList,
 }
 
@@ -56,6 +79,7 @@ func init() {
 
 	listCmd.Flags().StringVarP(&listCategory, "category", "c", "", "filter by category")
 	listCmd.Flags().BoolVar(&listExperimental, "experimental", false, "include experimental integrations")
+	listCmd.Flags().BoolVar(&listJSON, "json", false, "print the integration catalog as JSON")
 }
 
 func runList(cmd *cobra.Command, args []string) error {
@@ -65,6 +89,10 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("load metadata: %w", err)
 	}
 
+	if listJSON {
+		return outputJSON(integrationCatalog(meta))
+	}
+
 	// Get all registered integrations
 	registered := integrations.List()
 	registeredMap := make(map[string]bool)
@@ -133,6 +161,12 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 
 		fmt.Printf("%-15s %-20s %-50s %s\n", id, name, desc, status)
+
+		if verboseFlag {
+			if files := supportedFiles(id); len(files) > 0 {
+				fmt.Printf("%-15s files: %s\n", "", strings.Join(files, ", "))
+			}
+		}
 	}
 
 	fmt.Printf("\nTotal: %d integrations\n", len(displayIntegrations))
@@ -156,3 +190,46 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// integrationCatalog describes every registered integration, including those
+// loaded from plugins that have no metadata, filtered by --category and
+// --experimental and sorted by name.
+func integrationCatalog(meta *integrations.RegistryMetadata) []integrationEntry {
+	registered := integrations.GetLazy()
+	names := make([]string, 0, len(registered))
+	for name := range registered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]integrationEntry, 0, len(names))
+	for _, name := range names {
+		info := meta.Integrations[name]
+		if info.Experimental && !listExperimental {
+			continue
+		}
+		if listCategory != "" && info.Category != listCategory {
+			continue
+		}
+
+		entries = append(entries, integrationEntry{
+			Name:         name,
+			DisplayName:  info.DisplayName,
+			Description:  info.Description,
+			Category:     info.Category,
+			Disabled:     info.Disabled,
+			Experimental: info.Experimental,
+			Plugin:       integrations.IsPlugin(name),
+		})
+	}
+	return entries
+}
+
+// supportedFiles returns the manifest file patterns of a registered integration.
+func supportedFiles(id string) []string {
+	integ, err := integrations.Get(id)
+	if err != nil {
+		return nil
+	}
+	return engine.SupportedFiles(integ)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+)
+
+func TestListJSON(t *testing.T) {
+	origJSON, origExperimental, origCategory := listJSON, listExperimental, listCategory
+	defer func() { listJSON, listExperimental, listCategory = origJSON, origExperimental, origCategory }()
+	listJSON = true
+	listExperimental = true
+	listCategory = ""
+
+	var err error
+	out := captureStdout(t, func() { err = runList(nil, nil) })
+	if err != nil {
+		t.Fatalf("runList() error = %v", err)
+	}
+
+	var entries []integrationEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("output is not a JSON catalog: %v\n%s", err, out)
+	}
+
+	if !sort.SliceIsSorted(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name }) {
+		t.Error("entries are not sorted by name")
+	}
+
+	var npm *integrationEntry
+	for i := range entries {
+		if entries[i].Name == "npm" {
+			npm = &entries[i]
+		}
+	}
+	if npm == nil {
+		t.Fatalf("npm missing from catalog:\n%s", out)
+	}
+	if npm.Category != "package-manager" {
+		t.Errorf("npm category = %q, want package-manager", npm.Category)
+	}
+	if npm.DisplayName == "" || npm.Plugin {
+		t.Errorf("npm entry = %+v, want a built-in integration with a display name", *npm)
+	}
+}
@@ -80,25 +80,36 @@ func init() {
 	rootCmd.AddCommand(migrateCmd)
 }
 
+// dependabotConfigCandidates are the paths checked, in order, when no
+// dependabot.yml is given explicitly.
+var dependabotConfigCandidates = []string{
+	".github/dependabot.yml",
+	".github/dependabot.yaml",
+	".dependabot/dependabot.yml",
+	".dependabot/dependabot.yaml",
+	"dependabot.yml",
+	"dependabot.yaml",
+}
+
 func runMigrate(cmd *cobra.Command, args []string) error {
+	return migrateDependabotConfig(migrateSourceFlag, migrateOutputFlag, migrateDryRunFlag, migrateForceFlag)
+}
+
+// migrateDependabotConfig converts the dependabot.yml at sourcePath, or the
+// first auto-detected one when sourcePath is empty, and writes the result to
+// outputPath. With dryRun the generated configuration is printed instead.
+func migrateDependabotConfig(sourcePath, outputPath string, dryRun, force bool) error {
 	// Find source file
-	sourcePath := migrateSourceFlag
 	if sourcePath == "" {
 		// Auto-detect dependabot.yml location
-		candidates := []string{
-			".github/dependabot.yml",
-			".github/dependabot.yaml",
-			"dependabot.yml",
-			"dependabot.yaml",
-		}
-		for _, candidate := range candidates {
+		for _, candidate := range dependabotConfigCandidates {
 			if _, err := os.Stat(candidate); err == nil {
 				sourcePath = candidate
 				break
 			}
 		}
 		if sourcePath == "" {
-			return fmt.Errorf("no dependabot.yml found; specify with --source flag")
+			return fmt.Errorf("no dependabot.yml found; specify its path explicitly")
 		}
 	}
 
@@ -135,21 +146,21 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 `
 	output := header + string(yamlData)
 
-	if migrateDryRunFlag {
+	if dryRun {
 		fmt.Println("\n--- Generated uptool.yaml (dry-run) ---")
 		fmt.Println(output)
 		return nil
 	}
 
 	// Check if output exists
-	if !migrateForceFlag {
-		if _, err := os.Stat(migrateOutputFlag); err == nil {
-			return fmt.Errorf("output file %s already exists; use --force to overwrite", migrateOutputFlag)
+	if !force {
+		if _, err := os.Stat(outputPath); err == nil {
+			return fmt.Errorf("output file %s already exists; use --force to overwrite", outputPath)
 		}
 	}
 
 	// Ensure output directory exists
-	outDir := filepath.Dir(migrateOutputFlag)
+	outDir := filepath.Dir(outputPath)
 	if outDir != "" && outDir != "." {
 		if err := os.MkdirAll(outDir, 0o750); err != nil { // #nosec G301 -- directory needs to be accessible
 			return fmt.Errorf("failed to create output directory: %w", err)
@@ -157,11 +168,11 @@ func runMigrate(cmd *cobra.Command, args []string) error {
 	}
 
 	// Write output file
-	if err := os.WriteFile(migrateOutputFlag, []byte(output), 0o600); err != nil { // #nosec G306 -- config file needs secure permissions
+	if err := os.WriteFile(outputPath, []byte(output), 0o600); err != nil { // #nosec G306 -- config file needs secure permissions
 		return fmt.Errorf("failed to write uptool config: %w", err)
 	}
 
-	fmt.Printf("\nMigration complete! Written to: %s\n", migrateOutputFlag)
+	fmt.Printf("\nMigration complete! Written to: %s\n", outputPath)
 
 	if len(report.UnsupportedFeatures) > 0 || len(report.Warnings) > 0 {
 		fmt.Println("\nPlease review the generated configuration and adjust as needed.")
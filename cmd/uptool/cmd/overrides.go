@@ -0,0 +1,149 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations/npm"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// manifestOverrides are the overrides suggested for one npm manifest.
+type manifestOverrides struct {
+	Manifest    *engine.Manifest
+	Suggestions []npm.OverrideSuggestion
+}
+
+// suggestManifestOverrides looks up security advisories for the transitive
+// packages locked in each npm manifest's package-lock.json and suggests
+// overrides that force vulnerable ones to their first patched version.
+// Manifests without a lockfile are skipped since their installed tree is unknown.
+func suggestManifestOverrides(ctx context.Context, source advisorySource, repoRoot string, manifests []*engine.Manifest) ([]manifestOverrides, []string) {
+	var (
+		results []manifestOverrides
+		errs    []string
+	)
+	for _, m := range manifests {
+		lockPath, ok := m.Metadata["lockfile"].(string)
+		if m.Type != "npm" || !ok {
+			continue
+		}
+
+		var pkg npm.PackageJSON
+		if err := json.Unmarshal(m.Content, &pkg); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: parse package.json: %v", m.Path, err))
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(repoRoot, lockPath)) // #nosec G304 - lockfile found by scan
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", m.Path, err))
+			continue
+		}
+		locked, err := npm.LockedPackages(content)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", lockPath, err))
+			continue
+		}
+
+		direct := make(map[string]bool)
+		for _, dep := range m.Dependencies {
+			direct[dep.Name] = true
+		}
+
+		var vulns []npm.Vulnerability
+		for name, versions := range locked {
+			if direct[name] {
+				continue
+			}
+			advisories, err := source.GetSecurityVulnerabilities(ctx, ghsaEcosystems["npm"], name)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", m.Path, err))
+				continue
+			}
+			vulns = append(vulns, lockedVulnerabilities(name, versions, advisories)...)
+		}
+
+		if suggestions := npm.SuggestOverrides(&pkg, vulns); len(suggestions) > 0 {
+			results = append(results, manifestOverrides{Manifest: m, Suggestions: suggestions})
+		}
+	}
+	return results, errs
+}
+
+// lockedVulnerabilities returns the advisories with a patched version that
+// affect any of the installed versions of a package.
+func lockedVulnerabilities(name string, versions []string, advisories []registry.SecurityVulnerability) []npm.Vulnerability {
+	var vulns []npm.Vulnerability
+	for _, raw := range versions {
+		installed, err := semver.NewVersion(raw)
+		if err != nil {
+			continue
+		}
+		for _, a := range advisories {
+			affected, err := semver.NewConstraint(a.VulnerableVersionRange)
+			if err != nil || a.FirstPatchedVersion == "" || !affected.Check(installed) {
+				continue
+			}
+			vulns = append(vulns, npm.Vulnerability{
+				ID:           a.GHSAID,
+				Package:      name,
+				Version:      raw,
+				FixedVersion: a.FirstPatchedVersion,
+			})
+		}
+	}
+	return vulns
+}
+
+// applyManifestOverrides prints the suggested overrides and, unless dryRun is
+// set, writes them into each package.json.
+func applyManifestOverrides(repoRoot string, overrides []manifestOverrides, dryRun bool) []string {
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	var errs []string
+	fmt.Println("\n=== Overrides ===")
+	for _, o := range overrides {
+		fmt.Printf("\n%s:\n", o.Manifest.Path)
+		for _, s := range o.Suggestions {
+			fmt.Printf("  %s -> %s (%s)\n", s.Package, s.Version, s.Vulnerability)
+		}
+		if dryRun {
+			continue
+		}
+		if err := npm.ApplyOverrides(filepath.Join(repoRoot, o.Manifest.Path), o.Suggestions); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", o.Manifest.Path, err))
+		}
+	}
+	if !dryRun && len(errs) < len(overrides) {
+		fmt.Println("\nRun npm install to apply the overrides to package-lock.json.")
+	}
+	return errs
+}
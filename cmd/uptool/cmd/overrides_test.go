@@ -0,0 +1,106 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations/npm"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func TestSuggestManifestOverrides(t *testing.T) {
+	repoRoot := t.TempDir()
+	pkgJSON := `{
+  "name": "app",
+  "dependencies": {"express": "^4.18.0"}
+}
+`
+	lock := `{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app"},
+    "node_modules/express": {"version": "4.18.0"},
+    "node_modules/qs": {"version": "6.10.3"},
+    "node_modules/debug": {"version": "2.6.9"}
+  }
+}`
+	for name, content := range map[string]string{"package.json": pkgJSON, "package-lock.json": lock} {
+		if err := os.WriteFile(filepath.Join(repoRoot, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source := &mockAdvisories{vulns: map[string][]registry.SecurityVulnerability{
+		"NPM/express": {{GHSAID: "GHSA-direct", VulnerableVersionRange: "< 4.19.2", FirstPatchedVersion: "4.19.2"}},
+		"NPM/qs":      {{GHSAID: "GHSA-hrpp-h998-j3pp", VulnerableVersionRange: ">= 6.10.0, < 6.10.4", FirstPatchedVersion: "6.10.4"}},
+		"NPM/debug":   {{GHSAID: "GHSA-old", VulnerableVersionRange: "< 2.6.9", FirstPatchedVersion: "2.6.9"}},
+	}}
+	manifests := []*engine.Manifest{
+		{
+			Path:         "package.json",
+			Type:         "npm",
+			Content:      []byte(pkgJSON),
+			Dependencies: []engine.Dependency{{Name: "express", CurrentVersion: "^4.18.0"}},
+			Metadata:     map[string]interface{}{"lockfile": "package-lock.json"},
+		},
+		{Path: "sub/package.json", Type: "npm", Content: []byte(`{}`)}, // no lockfile
+	}
+
+	got, errs := suggestManifestOverrides(t.Context(), source, repoRoot, manifests)
+	if len(errs) != 0 {
+		t.Fatalf("suggestManifestOverrides() errors = %v", errs)
+	}
+	want := []npm.OverrideSuggestion{{Package: "qs", Version: "6.10.4", Vulnerability: "GHSA-hrpp-h998-j3pp"}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Suggestions, want) {
+		t.Fatalf("suggestManifestOverrides() = %+v, want %+v", got, want)
+	}
+	if source.calls != 2 {
+		t.Errorf("advisory lookups = %d, want 2 (transitive packages only)", source.calls)
+	}
+
+	if errs := applyManifestOverrides(repoRoot, got, true); len(errs) != 0 {
+		t.Fatalf("applyManifestOverrides(dryRun) errors = %v", errs)
+	}
+	data, err := os.ReadFile(filepath.Join(repoRoot, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != pkgJSON {
+		t.Errorf("dry run modified package.json:\n%s", data)
+	}
+
+	if errs := applyManifestOverrides(repoRoot, got, false); len(errs) != 0 {
+		t.Fatalf("applyManifestOverrides() errors = %v", errs)
+	}
+	data, err = os.ReadFile(filepath.Join(repoRoot, "package.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"overrides": {`+"\n"+`    "qs": "6.10.4"`) {
+		t.Errorf("package.json missing qs override:\n%s", data)
+	}
+}
@@ -21,24 +21,64 @@
 package cmd
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/santosr2/uptool/internal/dashboard"
 	"github.com/santosr2/uptool/internal/engine"
 )
 
 var (
 	planFormat           string
+	planFailOn           string
+	planSince            string
+	planOnlyGroup        string
+	planResume           string
+	planOutput           string
 	planOut              string
+	planDashboard        string
 	planOnly             string
 	planExclude          string
+	planExcludePath      string
+	planOnlyDependency   string
+	planPrerelease       string
+	planTemplateFile     string
+	planSort             string
+	planLookupTimeout    time.Duration
+	planStdin            string
+	planManifest         string
 	planShowPolicySource bool
+	planShowCooldown     bool
 	planShowUpToDate     bool
+	planIncludeUpToDate  bool
+	planCollapse         bool
+	planOnlySecurity     bool
+	planCheckYanked      bool
+	planFetchInfo        bool
+	planTrackedOnly      bool
+	planDueOnly          bool
+)
+
+// Output modes accepted by --output.
+const (
+	planOutputText = "text"
+	planOutputJSON = "json"
+)
+
+// Dependency statuses reported by --include-up-to-date.
+const (
+	statusCurrent         = "current"
+	statusUpdateAvailable = "update available"
+	statusNoConstraint    = "no constraint"
+	statusFiltered        = "filtered"
+	statusError           = "error"
 )
 
 var planCmd = &cobra.Command{
@@ -48,45 +88,150 @@ var planCmd = &cobra.Command{
 
 This command scans for manifests and queries registries to determine which
 dependencies have newer versions available. The plan shows what would be
-updated without making any changes.`,
+updated without making any changes.
+
+JSON output reports the reason for the exit code as "exit_reason": fail_on and
+errors_present (only with --fail-on-error) exit 1; updates_available,
+cooldown_only, and up_to_date exit 0.`,
 	Example: `  # Generate plan with table output
   uptool plan
 
-  # Generate plan as JSON
-  uptool plan --format json
+  # Generate plan as JSON for automation (errors included in the document)
+  uptool plan --output json
 
   # Save plan to file
   uptool plan --out plan.json
 
+  # Keep a dependency dashboard up to date
+  uptool plan --dashboard DASHBOARD.md
+
   # Plan only npm dependencies
-  uptool plan --only npm`,
+  uptool plan --only npm
+
+  # Plan an unsaved package.json piped on stdin
+  cat package.json | uptool plan --stdin-type npm
+
+  # Plan one manifest without scanning the rest of the repository
+  uptool plan --manifest services/api/package.json
+
+  # Skip generated or vendored manifests that git does not track
+  uptool plan --tracked-only
+
+  # Skip manifests under examples/ on top of .gitignore
+  uptool plan --exclude-path examples/
+
+  # Plan only specific dependencies
+  uptool plan --only-dependency express,@types/*
+
+  # Continue a plan that was interrupted, skipping finished manifests
+  uptool plan --resume
+
+  # Plan only the updates of one dependency group
+  uptool plan --only-group security-deps
+
+  # List every dependency with its status
+  uptool plan --include-up-to-date
+
+  # List an update shared by many manifests of a monorepo only once
+  uptool plan --collapse-duplicates
+
+  # List the riskiest updates first
+  uptool plan --sort worst-first
+
+  # Show updates held back by a cooldown policy
+  uptool plan --show-cooldown
+
+  # Include release ages, release notes, and commits between versions
+  uptool plan --fetch-info --format json
+
+  # Annotate outdated dependencies in a pull request check
+  uptool plan --format github-actions
+
+  # Render the plan with a custom template
+  uptool plan --format template --template-file report.tmpl`,
 	RunE: runPlan,
 }
 
 func init() {
 	rootCmd.AddCommand(planCmd)
 
-	planCmd.Flags().StringVarP(&planFormat, "format", "f", "table", "output format: table, json")
+	planCmd.Flags().StringVarP(&planFormat, "format", "f", "table", "output format: table, json, template, github-actions")
+	planCmd.Flags().StringVar(&planOutput, "output", planOutputText, "output mode: text, json (machine-readable plan with errors in the document)")
+	planCmd.Flags().StringVar(&planTemplateFile, "template-file", "", "Go text/template file rendered against the plan (with --format template)")
 	planCmd.Flags().StringVarP(&planOut, "out", "o", "", "write plan to file")
+	planCmd.Flags().StringVar(&planDashboard, "dashboard", "", "write or update a markdown dependency dashboard at this path")
+	planCmd.Flags().StringVar(&planSort, "sort", "", "order updates in the output: worst-first, name, path, impact")
+	planCmd.Flags().DurationVar(&planLookupTimeout, "lookup-timeout", engine.DefaultLookupTimeout, "per-dependency registry lookup timeout; slower lookups are reported as unchecked")
 	planCmd.Flags().StringVar(&planOnly, "only", "", "comma-separated integrations to include")
 	planCmd.Flags().StringVar(&planExclude, "exclude", "", "comma-separated integrations to exclude")
+	planCmd.Flags().StringVar(&planExcludePath, "exclude-path", "", "comma-separated gitignore-style path patterns to skip, in addition to .gitignore")
+	planCmd.Flags().BoolVar(&planTrackedOnly, "tracked-only", false, "skip manifests git does not track (all are scanned outside a git repository)")
+	planCmd.Flags().StringVar(&planStdin, "stdin-type", "", "plan a single manifest of this integration read from stdin instead of scanning")
+	planCmd.Flags().StringVar(&planManifest, "manifest", "", "plan only this manifest file, with the integrations that handle it")
+	planCmd.MarkFlagsMutuallyExclusive("manifest", "stdin-type")
+	planCmd.Flags().StringVar(&planOnlyDependency, "only-dependency", "", "comma-separated dependency names or globs to include")
+	planCmd.Flags().StringVar(&planResume, "resume", "", "record plan progress and skip manifests finished by an interrupted run (default path: "+engine.DefaultResumeStatePath+")")
+	planCmd.Flags().Lookup("resume").NoOptDefVal = engine.DefaultResumeStatePath
+	planCmd.Flags().BoolVar(&planOnlySecurity, "only-security", false, "plan only updates that fix a GitHub security advisory (needs GITHUB_TOKEN)")
+	planCmd.Flags().BoolVar(&planDueOnly, "due-only", false, "skip integrations whose policy cadence or schedule is not yet due (reads the state 'update --due-only' records)")
+	planCmd.Flags().BoolVar(&planCheckYanked, "check-yanked", false, "flag dependencies whose current version was yanked or unpublished (cargo, npm)")
+	planCmd.Flags().BoolVar(&planFetchInfo, "fetch-info", false, "look up release times and GitHub release notes: record each update's age (cargo, go, npm) and info with release notes, commits (actions, cargo, go, npm) and a compatibility score")
+	planCmd.Flags().StringVar(&planOnlyGroup, "only-group", "", "plan only updates in this dependency group (groups in uptool.yaml)")
+	planCmd.Flags().StringVar(&planPrerelease, "prerelease-channel", "", "comma-separated prerelease channels to consider (e.g. rc), overriding prerelease_channels")
+	planCmd.Flags().StringVar(&planFailOn, "fail-on", "", "exit non-zero if an update at or above this impact is available: patch, minor, major")
+	planCmd.Flags().StringVar(&planSince, "since", "", "with --fail-on, exempt dependencies added since this git revision (e.g. origin/main)")
 	planCmd.Flags().BoolVar(&planShowPolicySource, "show-policy-source", false, "show where the policy originated (uptool.yaml, cli-flag, constraint, default)")
+	planCmd.Flags().BoolVar(&planShowCooldown, "show-cooldown", false, "list updates held by a cooldown policy with the days remaining")
 	planCmd.Flags().BoolVar(&planShowUpToDate, "show-up-to-date", false, "show packages that are already up-to-date")
+	planCmd.Flags().BoolVar(&planIncludeUpToDate, "include-up-to-date", false, "list every dependency with its status (current, update available, filtered or error)")
+	planCmd.Flags().BoolVar(&planCollapse, "collapse-duplicates", false, "list each identical update (dependency, current and target version) once with the manifests it appears in")
 
 	// Add shell completion for flags
 	if err := planCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp
+		return []string{"table", "json", "template", "github-actions"}, cobra.ShellCompDirectiveNoFileComp
 	}); err != nil {
 		// This is a non-critical error during CLI initialization
 		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
 	}
 
+	if err := planCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{planOutputText, planOutputJSON}, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+
+	if err := planCmd.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return planSortModes, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+	if err := planCmd.RegisterFlagCompletionFunc("fail-on", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sortedFailOnLevels(), cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
 	if err := planCmd.RegisterFlagCompletionFunc("only", completeIntegrations); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
 	}
 	if err := planCmd.RegisterFlagCompletionFunc("exclude", completeIntegrations); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
 	}
+	if err := planCmd.RegisterFlagCompletionFunc("stdin-type", completeStdinTypes); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+	if err := planCmd.RegisterFlagCompletionFunc("only-dependency", completeDependencies); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+	if err := planCmd.RegisterFlagCompletionFunc("template-file", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"tmpl", "tpl"}, cobra.ShellCompDirectiveFilterFileExt
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
+	if err := planCmd.RegisterFlagCompletionFunc("dashboard", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"md"}, cobra.ShellCompDirectiveFilterFileExt
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
 	if err := planCmd.RegisterFlagCompletionFunc("out", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return nil, cobra.ShellCompDirectiveDefault // File completion
 	}); err != nil {
@@ -95,27 +240,166 @@ func init() {
 }
 
 func runPlan(cmd *cobra.Command, args []string) error {
+	if err := validatePlanSort(planSort); err != nil {
+		return err
+	}
+	if err := validateFailOn(planFailOn, planSince, planStdin != ""); err != nil {
+		return err
+	}
+
+	format, err := resolvePlanFormat(planOutput, planFormat)
+	if err != nil {
+		return err
+	}
+	jsonOutput := format == "json"
+	if err := validateCollapseDuplicates(planCollapse, planIncludeUpToDate, format); err != nil {
+		return err
+	}
+
+	// JSON consumers read errors from the document, so keep engine logs off
+	// stderr unless they were asked for
+	if jsonOutput && !verboseFlag {
+		defer func(level slog.Level) { logLevel = level }(logLevel)
+		logLevel = slog.LevelError + 1
+	}
+
+	// Parse the template up front so mistakes surface before any registry calls
+	var tmpl *template.Template
+	if format == "template" {
+		if tmpl, err = loadPlanTemplate(planTemplateFile); err != nil {
+			return err
+		}
+	}
+
 	eng := setupEngine()
-	ctx := context.Background()
+	eng.SetTrackedOnly(planTrackedOnly)
+	_, excludePaths := parseFilters("", planExcludePath)
+	eng.SetExcludePaths(excludePaths)
+	if flags := prereleaseCLIFlags(planPrerelease); flags != nil {
+		eng.SetCLIFlags(flags)
+	}
+	ctx := commandContext(cmd)
 
 	repoRoot, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("get working directory: %w", err)
 	}
 
+	var advisories advisorySource
+	if planOnlySecurity {
+		if advisories, err = newAdvisorySource(); err != nil {
+			return err
+		}
+	}
+
 	onlyList, excludeList := parseFilters(planOnly, planExclude)
 
-	// First scan
-	scanResult, err := eng.Scan(ctx, repoRoot, onlyList, excludeList)
+	// First scan, or read the manifest from stdin
+	var scanResult *engine.ScanResult
+	if planStdin != "" {
+		var cleanup func()
+		scanResult, cleanup, err = scanStdinManifest(ctx, eng, planStdin, os.Stdin)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	} else if planManifest != "" {
+		scanResult, err = eng.ScanManifest(ctx, repoRoot, planManifest)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+	} else {
+		scanResult, err = eng.Scan(ctx, repoRoot, onlyList, excludeList)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+	}
+
+	if planDueOnly {
+		state, _, err := loadDueState()
+		if err != nil {
+			return err
+		}
+		var skipped []string
+		scanResult.Manifests, skipped = filterDueManifests(eng, state, scanResult.Manifests, time.Now())
+		reportSkippedIntegrations(skipped)
+	}
+
+	resume, err := loadResumeState(repoRoot, planResume)
 	if err != nil {
-		return fmt.Errorf("scan failed: %w", err)
+		return err
+	}
+
+	// --fetch-info needs release times for every dependency, not only those under a cooldown
+	timestamps := releaseTimestamps(ctx, eng, scanResult.Manifests)
+	if planFetchInfo {
+		timestamps = allReleaseTimestamps(ctx, scanResult.Manifests)
 	}
 
 	// Then plan
-	planResult, err := eng.Plan(ctx, scanResult.Manifests)
+	planResult, err := eng.PlanWithOptions(ctx, scanResult.Manifests, &engine.PlanOptions{
+		ReleaseTimestamps: timestamps,
+		LookupTimeout:     planLookupTimeout,
+		OnlyGroup:         planOnlyGroup,
+		Resume:            resume,
+	})
 	if err != nil {
 		return fmt.Errorf("plan failed: %w", err)
 	}
+	finishResume(resume, planResult.Errors)
+	if planStdin != "" {
+		labelStdin(scanResult.Manifests, planResult.Plans)
+	}
+	depList, _ := parseFilters(planOnlyDependency, "")
+	planResult.Plans = filterPlansByDependency(planResult.Plans, depList)
+	if advisories != nil {
+		var advisoryErrors []string
+		planResult.Plans, advisoryErrors = filterSecurityUpdates(ctx, advisories, planResult.Plans)
+		planResult.Errors = append(planResult.Errors, advisoryErrors...)
+	}
+	if planCheckYanked {
+		planResult.Errors = append(planResult.Errors, flagYankedVersions(ctx, yankSources(), planResult.Plans)...)
+	}
+	if planFetchInfo {
+		setUpdateAges(planResult.Plans, timestamps, time.Now())
+		setUpdateInfo(ctx, newReleaseInfoSource(), planResult.Plans, dependencyRepository)
+		setCompatibilityScores(planResult.Plans, time.Now())
+	}
+	sortPlans(planResult.Plans, planSort)
+
+	// Evaluate the --fail-on gate now; it only sets the exit code once the plan is printed
+	var added map[string]map[string]bool
+	if planSince != "" {
+		if added, err = addedDependencies(ctx, eng, repoRoot, planSince, planResult.Plans); err != nil {
+			return err
+		}
+	}
+
+	// Write the dashboard before JSON output folds scan errors into the plan
+	if planDashboard != "" {
+		report := &engine.PlanResult{
+			Plans:  planResult.Plans,
+			Errors: append(append([]string{}, scanResult.Errors...), planResult.Errors...),
+		}
+		limit := func(integration string) int {
+			return eng.GetUpdateFilter(integration).GetOpenPullRequestsLimit()
+		}
+		if err := dashboard.Write(planDashboard, report, dashboard.Options{Limit: limit, CollapseDuplicates: planCollapse}); err != nil {
+			return err
+		}
+		if jsonOutput {
+			fmt.Fprintf(os.Stderr, "Dashboard written to %s\n", planDashboard)
+		} else {
+			fmt.Printf("Dashboard written to %s\n", planDashboard)
+		}
+	}
+
+	if jsonOutput {
+		planResult.Errors = append(append([]string{}, scanResult.Errors...), planResult.Errors...)
+		if planResult.Plans == nil {
+			planResult.Plans = []*engine.UpdatePlan{}
+		}
+	}
 
 	// Write to file if requested
 	if planOut != "" {
@@ -126,16 +410,91 @@ func runPlan(cmd *cobra.Command, args []string) error {
 		if err := os.WriteFile(planOut, data, 0o600); err != nil {
 			return fmt.Errorf("write plan file: %w", err)
 		}
-		fmt.Printf("Plan written to %s\n", planOut)
+		if jsonOutput {
+			fmt.Fprintf(os.Stderr, "Plan written to %s\n", planOut)
+		} else {
+			fmt.Printf("Plan written to %s\n", planOut)
+		}
 	}
 
-	switch planFormat {
+	// JSON output reports why the command exits as it does, so decide the exit
+	// status first. Errors are already in the document; --fail-on-error only
+	// sets the exit code
+	var exitErr error
+	if jsonOutput {
+		if failOnError && len(planResult.Errors) > 0 {
+			exitErr = fmt.Errorf("%w: %d", ErrRunErrors, len(planResult.Errors))
+		} else {
+			exitErr = checkFailOn(planResult.Plans, planFailOn, added)
+		}
+	}
+	reason := planExitReason(planResult.Plans, exitErr)
+
+	switch format {
 	case "json":
-		return outputJSON(planResult)
+		switch {
+		case planIncludeUpToDate:
+			inventory := buildInventory(planResult)
+			inventory.ExitReason = reason
+			err = outputJSON(inventory)
+		case planCollapse:
+			collapsed := buildCollapsedPlan(planResult)
+			collapsed.ExitReason = reason
+			err = outputJSON(collapsed)
+		default:
+			err = outputJSON(planDocument{PlanResult: planResult, ExitReason: reason})
+		}
 	case "table":
-		return outputPlanTable(planResult)
+		switch {
+		case planIncludeUpToDate:
+			err = outputInventoryTable(planResult)
+		case planCollapse:
+			err = outputCollapsedTable(planResult)
+		default:
+			err = outputPlanTable(planResult)
+		}
+		if err == nil && planShowCooldown {
+			outputCooldownTable(planResult)
+		}
+	case "template":
+		err = renderPlanTemplate(os.Stdout, tmpl, planResult)
+	case "github-actions":
+		report := &engine.PlanResult{
+			Plans:  planResult.Plans,
+			Errors: append(append([]string{}, scanResult.Errors...), planResult.Errors...),
+		}
+		err = outputGitHubAnnotations(os.Stdout, report, repoRoot)
 	default:
-		return fmt.Errorf("unsupported format: %s", planFormat)
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return exitErr
+	}
+
+	exitErr = checkRunErrors(scanResult.Errors, planResult.Errors)
+	if exitErr == nil {
+		exitErr = checkFailOn(planResult.Plans, planFailOn, added)
+	}
+	return exitErr
+}
+
+// resolvePlanFormat combines --output and --format into the format to render.
+// --output json is shorthand for --format json; --output text defers to --format.
+func resolvePlanFormat(output, format string) (string, error) {
+	switch output {
+	case planOutputText, "":
+		return format, nil
+	case planOutputJSON:
+		if format != "table" && format != "json" {
+			return "", fmt.Errorf("--output json cannot be combined with --format %s", format)
+		}
+		return "json", nil
+	default:
+		return "", fmt.Errorf("unsupported output mode: %s (valid: %s, %s)", output, planOutputText, planOutputJSON)
 	}
 }
 
@@ -157,7 +516,7 @@ func outputPlanTable(result *engine.PlanResult) error {
 			continue
 		}
 
-		fmt.Printf("\n%s (%s):\n", plan.Manifest.Path, plan.Manifest.Type)
+		fmt.Printf("\n%s (%s):\n", colorize(ansiBold, plan.Manifest.Path), manifestLabel(plan.Manifest))
 
 		if !hasUpdates {
 			// Show up-to-date message
@@ -189,18 +548,18 @@ func outputPlanTable(result *engine.PlanResult) error {
 			}
 
 			if planShowPolicySource {
-				fmt.Printf("%-35s %-15s %-15s %-10s %-15s\n",
+				fmt.Printf("%-35s %-15s %-15s %s %-15s\n",
 					pkg,
 					update.Dependency.CurrentVersion,
 					update.TargetVersion,
-					update.Impact,
+					colorizeImpact(fmt.Sprintf("%-10s", update.Impact)),
 					update.PolicySource)
 			} else {
-				fmt.Printf("%-40s %-15s %-15s %-10s\n",
+				fmt.Printf("%-40s %-15s %-15s %s\n",
 					pkg,
 					update.Dependency.CurrentVersion,
 					update.TargetVersion,
-					update.Impact)
+					colorizeImpact(fmt.Sprintf("%-10s", update.Impact)))
 			}
 		}
 
@@ -215,12 +574,216 @@ func outputPlanTable(result *engine.PlanResult) error {
 		fmt.Printf("\nTotal: %d updates across %d manifests\n", totalUpdates, manifestsWithUpdates)
 	}
 
-	if len(result.Errors) > 0 {
-		fmt.Printf("\nErrors:\n")
-		for _, e := range result.Errors {
-			fmt.Printf("  - %s\n", e)
+	printUnchecked(result.Plans)
+	printYanked(result.Plans)
+
+	printErrors(os.Stdout, result.Errors)
+
+	return nil
+}
+
+// manifestLabel returns the description printed next to a manifest path: its
+// type, and the workspace it is a member of (npm package.json, go.work).
+func manifestLabel(m *engine.Manifest) string {
+	if ws, ok := m.Metadata["workspace"].(string); ok && ws != "" && ws != m.Path {
+		return m.Type + ", workspace " + ws
+	}
+	return m.Type
+}
+
+// printUnchecked lists dependencies whose registry lookup timed out. They are
+// not errors: the rest of each manifest was planned normally.
+func printUnchecked(plans []*engine.UpdatePlan) {
+	var lines []string
+	for _, plan := range plans {
+		for _, dep := range plan.Unchecked {
+			lines = append(lines, fmt.Sprintf("  - %s: %s (%s)", plan.Manifest.Path, dep.Name, dep.Reason))
 		}
 	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", colorize(ansiYellow, "Unchecked (lookup timed out):"))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
+
+// inventoryEntry describes a single dependency and whether an update is available.
+type inventoryEntry struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Status  string `json:"status"`
+	Impact  string `json:"impact,omitempty"`
+}
+
+// manifestInventory lists every dependency of a manifest with its status.
+type manifestInventory struct {
+	Path         string           `json:"path"`
+	Type         string           `json:"type"`
+	Dependencies []inventoryEntry `json:"dependencies"`
+}
+
+// planInventory is the JSON shape of plan --include-up-to-date.
+type planInventory struct {
+	Manifests  []manifestInventory `json:"manifests"`
+	Errors     []string            `json:"errors,omitempty"`
+	ExitReason string              `json:"exit_reason,omitempty"`
+}
+
+// buildInventory merges each manifest's dependencies with its planned updates.
+// Dependencies whose lookup did not finish are reported as errors, and those
+// whose update a policy, cooldown or plan filter removed as filtered. Other
+// dependencies without an update are reported as current, with the latest
+// allowed version equal to the current one, or as having no constraint when
+// declared without any version or requirement.
+func buildInventory(result *engine.PlanResult) *planInventory {
+	inventory := &planInventory{
+		Manifests: make([]manifestInventory, 0, len(result.Plans)),
+		Errors:    result.Errors,
+	}
+
+	for _, plan := range result.Plans {
+		updates := make(map[string]*engine.Update, len(plan.Updates))
+		for i := range plan.Updates {
+			update := &plan.Updates[i]
+			updates[update.Dependency.Name+"\x00"+update.Dependency.Type] = update
+		}
+		filtered := make(map[string]bool, len(plan.Filtered)+len(plan.Held))
+		for _, dep := range plan.Filtered {
+			filtered[dep.Name+"\x00"+dep.Type] = true
+		}
+		for i := range plan.Held {
+			dep := plan.Held[i].Update.Dependency
+			filtered[dep.Name+"\x00"+dep.Type] = true
+		}
+		// Unchecked dependencies are only known by name
+		unchecked := make(map[string]bool, len(plan.Unchecked))
+		for _, dep := range plan.Unchecked {
+			unchecked[dep.Name] = true
+		}
+
+		mi := manifestInventory{
+			Path:         plan.Manifest.Path,
+			Type:         plan.Manifest.Type,
+			Dependencies: make([]inventoryEntry, 0, len(plan.Manifest.Dependencies)),
+		}
+
+		seen := make(map[string]bool, len(plan.Manifest.Dependencies))
+		for _, dep := range plan.Manifest.Dependencies {
+			key := dep.Name + "\x00" + dep.Type
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			entry := inventoryEntry{
+				Name:    dep.Name,
+				Type:    dep.Type,
+				Current: dep.CurrentVersion,
+				Latest:  dep.CurrentVersion,
+				Status:  statusCurrent,
+			}
+			switch {
+			case unchecked[dep.Name]:
+				entry.Status = statusError
+			case filtered[key]:
+				entry.Status = statusFiltered
+			case dep.CurrentVersion == "" && dep.Constraint == "":
+				entry.Status = statusNoConstraint
+			}
+			if update, ok := updates[key]; ok {
+				entry.Latest = update.TargetVersion
+				entry.Status = statusUpdateAvailable
+				entry.Impact = update.Impact
+			}
+			mi.Dependencies = append(mi.Dependencies, entry)
+		}
+
+		// Updates for dependencies the manifest does not list (e.g., added by reconciliation)
+		for i := range plan.Updates {
+			update := &plan.Updates[i]
+			key := update.Dependency.Name + "\x00" + update.Dependency.Type
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			mi.Dependencies = append(mi.Dependencies, inventoryEntry{
+				Name:    update.Dependency.Name,
+				Type:    update.Dependency.Type,
+				Current: update.Dependency.CurrentVersion,
+				Latest:  update.TargetVersion,
+				Status:  statusUpdateAvailable,
+				Impact:  update.Impact,
+			})
+		}
+
+		inventory.Manifests = append(inventory.Manifests, mi)
+	}
+
+	return inventory
+}
+
+// outputInventoryTable prints every dependency with its current and latest version.
+func outputInventoryTable(result *engine.PlanResult) error {
+	inventory := buildInventory(result)
+
+	total, current, outdated, filtered, failed := 0, 0, 0, 0, 0
+
+	for _, mi := range inventory.Manifests {
+		if len(mi.Dependencies) == 0 {
+			continue
+		}
+
+		fmt.Printf("\n%s (%s):\n", colorize(ansiBold, mi.Path), mi.Type)
+		fmt.Printf("%-40s %-15s %-15s %s\n", "Package", "Current", "Latest", "Status")
+		fmt.Println(strings.Repeat("-", 90))
+
+		for _, entry := range mi.Dependencies {
+			pkg := entry.Name
+			if len(pkg) > 40 {
+				pkg = pkg[:37] + "..."
+			}
+
+			status := colorize(ansiGreen, "✓ "+entry.Status)
+			switch entry.Status {
+			case statusNoConstraint:
+				status = "- " + entry.Status
+				current++
+			case statusUpdateAvailable:
+				status = colorize(ansiYellow, "↑ "+entry.Status)
+				if entry.Impact != "" {
+					status += " (" + colorizeImpact(entry.Impact) + ")"
+				}
+				outdated++
+			case statusFiltered:
+				status = "- " + entry.Status
+				filtered++
+			case statusError:
+				status = colorize(ansiRed, "✗ "+entry.Status)
+				failed++
+			default:
+				current++
+			}
+
+			fmt.Printf("%-40s %-15s %-15s %s\n", pkg, entry.Current, entry.Latest, status)
+			total++
+		}
+	}
+
+	summary := fmt.Sprintf("%d current, %d with updates available", current, outdated)
+	if filtered > 0 {
+		summary += fmt.Sprintf(", %d filtered", filtered)
+	}
+	if failed > 0 {
+		summary += fmt.Sprintf(", %d with errors", failed)
+	}
+	fmt.Printf("\nTotal: %d dependencies (%s)\n", total, summary)
+
+	printErrors(os.Stdout, inventory.Errors)
 
 	return nil
 }
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// collapsedPlan is the JSON document printed by --collapse-duplicates.
+type collapsedPlan struct {
+	Updates    []engine.CollapsedUpdate `json:"updates"`
+	Errors     []string                 `json:"errors,omitempty"`
+	ExitReason string                   `json:"exit_reason,omitempty"`
+}
+
+// validateCollapseDuplicates checks that --collapse-duplicates is combined
+// with an output it can shorten.
+func validateCollapseDuplicates(collapse, includeUpToDate bool, format string) error {
+	if !collapse {
+		return nil
+	}
+	if includeUpToDate {
+		return fmt.Errorf("--collapse-duplicates cannot be combined with --include-up-to-date")
+	}
+	if format != "table" && format != "json" {
+		return fmt.Errorf("--collapse-duplicates cannot be combined with --format %s", format)
+	}
+	return nil
+}
+
+// buildCollapsedPlan returns the --collapse-duplicates JSON document.
+func buildCollapsedPlan(result *engine.PlanResult) *collapsedPlan {
+	updates := engine.CollapseDuplicates(result.Plans)
+	if updates == nil {
+		updates = []engine.CollapsedUpdate{}
+	}
+	return &collapsedPlan{Updates: updates, Errors: result.Errors}
+}
+
+// outputCollapsedTable prints each unique update once, followed by the
+// manifests it appears in.
+func outputCollapsedTable(result *engine.PlanResult) error {
+	collapsed := engine.CollapseDuplicates(result.Plans)
+	if len(collapsed) == 0 {
+		fmt.Println("No updates available.")
+		printErrors(os.Stdout, result.Errors)
+		return nil
+	}
+
+	fmt.Printf("\n%-40s %-15s %-15s %-10s %s\n", "Package", "Current", "Target", "Impact", "Manifests")
+	fmt.Println(strings.Repeat("-", 90))
+
+	total := 0
+	for i := range collapsed {
+		c := &collapsed[i]
+		pkg := c.Name
+		if len(pkg) > 40 {
+			pkg = pkg[:37] + "..."
+		}
+
+		fmt.Printf("%-40s %-15s %-15s %s %d\n",
+			pkg, c.CurrentVersion, c.TargetVersion, colorizeImpact(fmt.Sprintf("%-10s", c.Impact)), len(c.Manifests))
+		for _, path := range c.Manifests {
+			fmt.Printf("  %s (%s)\n", path, c.Integration)
+		}
+		total += len(c.Manifests)
+	}
+
+	fmt.Printf("\nTotal: %d unique updates (%d across all manifests)\n", len(collapsed), total)
+
+	printUnchecked(result.Plans)
+	printYanked(result.Plans)
+
+	printErrors(os.Stdout, result.Errors)
+
+	return nil
+}
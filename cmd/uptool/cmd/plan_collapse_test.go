@@ -0,0 +1,121 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// monorepoPlanResult has the same lodash update in three manifests.
+func monorepoPlanResult() *engine.PlanResult {
+	lodash := engine.Update{Dependency: engine.Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21", Impact: "patch"}
+	return &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{
+			{Manifest: &engine.Manifest{Path: "apps/web/package.json", Type: "npm"}, Updates: []engine.Update{lodash}},
+			{Manifest: &engine.Manifest{Path: "apps/api/package.json", Type: "npm"}, Updates: []engine.Update{lodash}},
+			{Manifest: &engine.Manifest{Path: "libs/ui/package.json", Type: "npm"}, Updates: []engine.Update{lodash}},
+		},
+	}
+}
+
+func TestPlanOutput_CollapseDuplicates(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	t.Run("table", func(t *testing.T) {
+		out := captureStdout(t, func() {
+			if err := outputCollapsedTable(monorepoPlanResult()); err != nil {
+				t.Fatalf("outputCollapsedTable() error = %v", err)
+			}
+		})
+
+		if n := strings.Count(out, "lodash"); n != 1 {
+			t.Errorf("lodash listed %d times, want 1:\n%s", n, out)
+		}
+		for _, path := range []string{"apps/web/package.json", "apps/api/package.json", "libs/ui/package.json"} {
+			if !strings.Contains(out, "  "+path+" (npm)") {
+				t.Errorf("output missing manifest %s:\n%s", path, out)
+			}
+		}
+		if !strings.Contains(out, "Total: 1 unique updates (3 across all manifests)") {
+			t.Errorf("output missing summary:\n%s", out)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		data, err := json.Marshal(buildCollapsedPlan(monorepoPlanResult()))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var doc collapsedPlan
+		if err := json.Unmarshal(data, &doc); err != nil {
+			t.Fatal(err)
+		}
+		if len(doc.Updates) != 1 {
+			t.Fatalf("updates = %+v, want one", doc.Updates)
+		}
+		if got := doc.Updates[0].Manifests; len(got) != 3 {
+			t.Errorf("manifests = %v, want three paths", got)
+		}
+	})
+
+	t.Run("no updates", func(t *testing.T) {
+		data, err := json.Marshal(buildCollapsedPlan(&engine.PlanResult{}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != `{"updates":[]}` {
+			t.Errorf("buildCollapsedPlan() = %s, want an empty update list", data)
+		}
+	})
+}
+
+func TestValidateCollapseDuplicates(t *testing.T) {
+	tests := []struct {
+		name            string
+		collapse        bool
+		includeUpToDate bool
+		format          string
+		wantErr         bool
+	}{
+		{"unset", false, true, "template", false},
+		{"table", true, false, "table", false},
+		{"json", true, false, "json", false},
+		{"with include-up-to-date", true, true, "table", true},
+		{"with template", true, false, "template", true},
+		{"with github-actions", true, false, "github-actions", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateCollapseDuplicates(tt.collapse, tt.includeUpToDate, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateCollapseDuplicates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// Exit reasons reported in the "exit_reason" field of plan's JSON output. The
+// reason follows from the exit code: exitReasonFailOn and exitReasonErrors
+// exit 1, the rest exit 0.
+const (
+	exitReasonFailOn           = "fail_on"
+	exitReasonErrors           = "errors_present"
+	exitReasonUpdatesAvailable = "updates_available"
+	exitReasonCooldownOnly     = "cooldown_only"
+	exitReasonUpToDate         = "up_to_date"
+)
+
+// planDocument is the JSON shape of plan --format json.
+type planDocument struct {
+	*engine.PlanResult
+	ExitReason string `json:"exit_reason,omitempty"`
+}
+
+// planExitReason classifies the outcome of a plan from the error the command
+// exits with, if any. Errors that do not fail the run (no --fail-on-error) do
+// not change the reason, so a zero exit code never reports errors_present.
+func planExitReason(plans []*engine.UpdatePlan, exitErr error) string {
+	switch {
+	case errors.Is(exitErr, ErrFailOn):
+		return exitReasonFailOn
+	case errors.Is(exitErr, ErrRunErrors):
+		return exitReasonErrors
+	}
+
+	held := false
+	for _, plan := range plans {
+		if len(plan.Updates) > 0 {
+			return exitReasonUpdatesAvailable
+		}
+		held = held || len(plan.Held) > 0
+	}
+	if held {
+		return exitReasonCooldownOnly
+	}
+	return exitReasonUpToDate
+}
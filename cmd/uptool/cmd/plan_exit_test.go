@@ -0,0 +1,110 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestPlanExitReason(t *testing.T) {
+	update := engine.Update{Dependency: engine.Dependency{Name: "lodash"}, TargetVersion: "4.17.21", Impact: "patch"}
+	withUpdate := &engine.UpdatePlan{Updates: []engine.Update{update}}
+	withHeld := &engine.UpdatePlan{Held: []engine.HeldUpdate{{Update: update, CooldownDays: 7}}}
+	empty := &engine.UpdatePlan{}
+
+	tests := []struct {
+		name    string
+		plans   []*engine.UpdatePlan
+		exitErr error
+		want    string
+	}{
+		{"no plans", nil, nil, exitReasonUpToDate},
+		{"no updates", []*engine.UpdatePlan{empty}, nil, exitReasonUpToDate},
+		{"updates", []*engine.UpdatePlan{empty, withUpdate}, nil, exitReasonUpdatesAvailable},
+		{"held only", []*engine.UpdatePlan{withHeld, empty}, nil, exitReasonCooldownOnly},
+		{"updates and held", []*engine.UpdatePlan{withHeld, withUpdate}, nil, exitReasonUpdatesAvailable},
+		{"fail-on-error", []*engine.UpdatePlan{withUpdate}, ErrRunErrors, exitReasonErrors},
+		{"fail-on", []*engine.UpdatePlan{withUpdate}, ErrFailOn, exitReasonFailOn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := planExitReason(tt.plans, tt.exitErr); got != tt.want {
+				t.Errorf("planExitReason() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanOutput_JSONExitReason(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	origOnly, origOutput, origFail, origFailOn := planOnly, planOutput, failOnError, planFailOn
+	defer func() { planOnly, planOutput, failOnError, planFailOn = origOnly, origOutput, origFail, origFailOn }()
+	planOutput = planOutputJSON
+
+	tests := []struct {
+		name        string
+		only        string
+		failOn      string
+		failOnError bool
+		want        string
+		wantErr     error
+	}{
+		{"updates available", staticIntegrationName, "", false, exitReasonUpdatesAvailable, nil},
+		{"errors ignored", staticIntegrationName + "," + failingIntegrationName, "", false, exitReasonUpdatesAvailable, nil},
+		{"errors present", staticIntegrationName + "," + failingIntegrationName, "", true, exitReasonErrors, ErrRunErrors},
+		{"fail-on", staticIntegrationName, "minor", false, exitReasonFailOn, ErrFailOn},
+		{"fail-on not reached", staticIntegrationName, "major", false, exitReasonUpdatesAvailable, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planOnly, planFailOn, failOnError = tt.only, tt.failOn, tt.failOnError
+
+			var err error
+			out := captureStdout(t, func() {
+				captureStderr(t, func() { err = runPlan(nil, nil) })
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("runPlan() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var doc struct {
+				Plans      []*engine.UpdatePlan `json:"plans"`
+				ExitReason string               `json:"exit_reason"`
+			}
+			if err := json.Unmarshal([]byte(out), &doc); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+			if doc.ExitReason != tt.want {
+				t.Errorf("exit_reason = %q, want %q", doc.ExitReason, tt.want)
+			}
+			if len(doc.Plans) != 1 {
+				t.Errorf("plans = %d, want the plan fields at the top level", len(doc.Plans))
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// Orderings accepted by plan --sort.
+const (
+	sortWorstFirst = "worst-first"
+	sortName       = "name"
+	sortPath       = "path"
+	sortImpact     = "impact"
+)
+
+var planSortModes = []string{sortWorstFirst, sortName, sortPath, sortImpact}
+
+// impactRank orders impacts from least to most disruptive.
+var impactRank = map[string]int{
+	string(engine.ImpactPatch): 1,
+	string(engine.ImpactMinor): 2,
+	string(engine.ImpactMajor): 3,
+}
+
+// riskScore is the composite score used by --sort worst-first. Security fixes
+// outrank breaking changes, which outrank the semver impact of the update.
+func riskScore(update *engine.Update) int {
+	score := impactRank[strings.ToLower(update.Impact)]
+	if update.Breaking {
+		score += 4
+	}
+	if update.Security {
+		score += 8
+	}
+	return score
+}
+
+// validatePlanSort checks a --sort value. The empty string keeps the engine's order.
+func validatePlanSort(mode string) error {
+	if mode == "" {
+		return nil
+	}
+	for _, m := range planSortModes {
+		if mode == m {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported sort: %s (expected one of %s)", mode, strings.Join(planSortModes, ", "))
+}
+
+// sortPlans orders plans and their updates for display. It only changes the
+// order in which updates are listed, never which updates are planned.
+//
+//   - worst-first: updates by descending risk score, manifests by their riskiest update
+//   - impact: updates by descending impact, manifests by their largest impact
+//   - name: updates by dependency name, manifests by path
+//   - path: manifests by path, keeping the order of their updates
+func sortPlans(plans []*engine.UpdatePlan, mode string) {
+	var score func(update *engine.Update) int
+	switch mode {
+	case sortWorstFirst:
+		score = riskScore
+	case sortImpact:
+		score = func(update *engine.Update) int { return impactRank[strings.ToLower(update.Impact)] }
+	case sortName, sortPath:
+	default:
+		return
+	}
+
+	for _, plan := range plans {
+		updates := plan.Updates
+		switch {
+		case score != nil:
+			sort.SliceStable(updates, func(i, j int) bool {
+				si, sj := score(&updates[i]), score(&updates[j])
+				if si != sj {
+					return si > sj
+				}
+				return updates[i].Dependency.Name < updates[j].Dependency.Name
+			})
+		case mode == sortName:
+			sort.SliceStable(updates, func(i, j int) bool {
+				return updates[i].Dependency.Name < updates[j].Dependency.Name
+			})
+		}
+	}
+
+	// Updates are sorted, so a plan's first update is its highest scoring one
+	top := func(plan *engine.UpdatePlan) int {
+		if score == nil || len(plan.Updates) == 0 {
+			return 0
+		}
+		return score(&plan.Updates[0])
+	}
+
+	sort.SliceStable(plans, func(i, j int) bool {
+		if ti, tj := top(plans[i]), top(plans[j]); ti != tj {
+			return ti > tj
+		}
+		return plans[i].Manifest.Path < plans[j].Manifest.Path
+	})
+}
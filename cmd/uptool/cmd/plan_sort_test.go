@@ -0,0 +1,124 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func sortPlanFixture() []*engine.UpdatePlan {
+	update := func(name, impact string) engine.Update {
+		return engine.Update{Dependency: engine.Dependency{Name: name}, Impact: impact}
+	}
+
+	security := update("qs", "patch")
+	security.Security = true
+	breaking := update("react", "major")
+	breaking.Breaking = true
+
+	return []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "web/package.json"},
+			Updates:  []engine.Update{update("axios", "patch"), update("express", "major"), update("lodash", "minor")},
+		},
+		{
+			Manifest: &engine.Manifest{Path: "app/package.json"},
+			Updates:  []engine.Update{update("chalk", "patch"), breaking, security},
+		},
+	}
+}
+
+func updateNames(plans []*engine.UpdatePlan) string {
+	var parts []string
+	for _, plan := range plans {
+		var names []string
+		for _, update := range plan.Updates {
+			names = append(names, update.Dependency.Name)
+		}
+		parts = append(parts, plan.Manifest.Path+": "+strings.Join(names, ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func TestSortPlans(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string
+	}{
+		{
+			mode: sortWorstFirst,
+			want: "app/package.json: qs,react,chalk; web/package.json: express,lodash,axios",
+		},
+		{
+			mode: sortImpact,
+			want: "app/package.json: react,chalk,qs; web/package.json: express,lodash,axios",
+		},
+		{
+			mode: sortName,
+			want: "app/package.json: chalk,qs,react; web/package.json: axios,express,lodash",
+		},
+		{
+			mode: sortPath,
+			want: "app/package.json: chalk,react,qs; web/package.json: axios,express,lodash",
+		},
+		{
+			mode: "",
+			want: "web/package.json: axios,express,lodash; app/package.json: chalk,react,qs",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			plans := sortPlanFixture()
+			sortPlans(plans, tt.mode)
+			if got := updateNames(plans); got != tt.want {
+				t.Errorf("sortPlans(%q) = %s, want %s", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRiskScore_WorstFirst(t *testing.T) {
+	patch := &engine.Update{Impact: "patch"}
+	major := &engine.Update{Impact: "major"}
+	securityPatch := &engine.Update{Impact: "patch", Security: true}
+
+	if riskScore(major) <= riskScore(patch) {
+		t.Errorf("major score %d should exceed patch score %d", riskScore(major), riskScore(patch))
+	}
+	if riskScore(securityPatch) <= riskScore(major) {
+		t.Errorf("security patch score %d should exceed major score %d", riskScore(securityPatch), riskScore(major))
+	}
+}
+
+func TestValidatePlanSort(t *testing.T) {
+	for _, mode := range append([]string{""}, planSortModes...) {
+		if err := validatePlanSort(mode); err != nil {
+			t.Errorf("validatePlanSort(%q) error = %v", mode, err)
+		}
+	}
+	if err := validatePlanSort("newest"); err == nil {
+		t.Error("validatePlanSort(newest) should return error")
+	}
+}
@@ -0,0 +1,419 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+)
+
+const staticIntegrationName = "test-static"
+
+func init() {
+	integrations.Register(staticIntegrationName, func() engine.Integration {
+		return staticIntegration{}
+	})
+}
+
+// staticIntegration detects one manifest and plans a fixed update for it.
+type staticIntegration struct{}
+
+func (staticIntegration) Name() string { return staticIntegrationName }
+
+func (staticIntegration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	return []*engine.Manifest{{Path: "static.json", Type: staticIntegrationName}}, nil
+}
+
+func (staticIntegration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Strategy: "static",
+		Updates: []engine.Update{{
+			Dependency:    engine.Dependency{Name: "left-pad", CurrentVersion: "1.0.0", Type: "direct"},
+			TargetVersion: "1.3.0",
+			Impact:        "minor",
+			PolicySource:  engine.PolicySourceDefault,
+			Group:         "utils",
+		}},
+	}, nil
+}
+
+func (staticIntegration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	return &engine.ApplyResult{Manifest: plan.Manifest}, nil
+}
+
+func (staticIntegration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	return nil
+}
+
+func inventoryPlanResult() *engine.PlanResult {
+	express := engine.Dependency{Name: "express", CurrentVersion: "4.17.0", Constraint: "^4.17.0", Type: "direct"}
+	lodash := engine.Dependency{Name: "lodash", CurrentVersion: "4.17.21", Constraint: "^4.17.21", Type: "direct"}
+
+	return &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{
+			{
+				Manifest: &engine.Manifest{
+					Path:         "package.json",
+					Type:         "npm",
+					Dependencies: []engine.Dependency{express, lodash},
+				},
+				Updates: []engine.Update{
+					{Dependency: express, TargetVersion: "4.18.2", Impact: "minor"},
+				},
+			},
+		},
+	}
+}
+
+func TestBuildInventory(t *testing.T) {
+	inventory := buildInventory(inventoryPlanResult())
+
+	if len(inventory.Manifests) != 1 {
+		t.Fatalf("len(Manifests) = %d, want 1", len(inventory.Manifests))
+	}
+
+	deps := inventory.Manifests[0].Dependencies
+	if len(deps) != 2 {
+		t.Fatalf("len(Dependencies) = %d, want 2", len(deps))
+	}
+
+	if deps[0].Name != "express" || deps[0].Status != statusUpdateAvailable || deps[0].Latest != "4.18.2" {
+		t.Errorf("express entry = %+v, want update available to 4.18.2", deps[0])
+	}
+	if deps[1].Name != "lodash" || deps[1].Status != statusCurrent || deps[1].Latest != "4.17.21" {
+		t.Errorf("lodash entry = %+v, want current at 4.17.21", deps[1])
+	}
+}
+
+func TestBuildInventory_NoConstraint(t *testing.T) {
+	rails := engine.Dependency{Name: "rails", CurrentVersion: "7.1", Constraint: "~> 7.1", Type: "direct"}
+	puma := engine.Dependency{Name: "puma", Type: "direct"}
+
+	inventory := buildInventory(&engine.PlanResult{
+		Plans: []*engine.UpdatePlan{{
+			Manifest: &engine.Manifest{
+				Path:         "Gemfile",
+				Type:         "bundler",
+				Dependencies: []engine.Dependency{rails, puma},
+			},
+		}},
+	})
+
+	deps := inventory.Manifests[0].Dependencies
+	if len(deps) != 2 {
+		t.Fatalf("len(Dependencies) = %d, want 2", len(deps))
+	}
+	if deps[0].Status != statusCurrent {
+		t.Errorf("rails status = %q, want %q", deps[0].Status, statusCurrent)
+	}
+	if deps[1].Status != statusNoConstraint {
+		t.Errorf("puma status = %q, want %q", deps[1].Status, statusNoConstraint)
+	}
+}
+
+func TestBuildInventory_FilteredAndErrors(t *testing.T) {
+	result := inventoryPlanResult()
+	react := engine.Dependency{Name: "react", CurrentVersion: "18.2.0", Constraint: "^18.2.0", Type: "direct"}
+	plan := result.Plans[0]
+	plan.Manifest.Dependencies = append(plan.Manifest.Dependencies, react)
+	plan.Filtered = []engine.Dependency{react}
+	plan.Unchecked = []engine.UncheckedDependency{{Name: "lodash", Datasource: "npm", Reason: "lookup timed out"}}
+
+	deps := buildInventory(result).Manifests[0].Dependencies
+	statuses := make(map[string]string, len(deps))
+	for _, dep := range deps {
+		statuses[dep.Name] = dep.Status
+	}
+	want := map[string]string{"express": statusUpdateAvailable, "lodash": statusError, "react": statusFiltered}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+}
+
+func TestPlanOutput_IncludeUpToDate(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	t.Run("flag set lists current dependencies", func(t *testing.T) {
+		out := captureStdout(t, func() {
+			if err := outputInventoryTable(inventoryPlanResult()); err != nil {
+				t.Fatalf("outputInventoryTable() error = %v", err)
+			}
+		})
+
+		var lodashLine string
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, "lodash ") {
+				lodashLine = line
+			}
+		}
+		if lodashLine == "" {
+			t.Fatalf("up-to-date dependency missing from output:\n%s", out)
+		}
+		if !strings.Contains(lodashLine, "4.17.21") || !strings.Contains(lodashLine, statusCurrent) {
+			t.Errorf("lodash line = %q, want current version and %q marker", lodashLine, statusCurrent)
+		}
+		if !strings.Contains(out, statusUpdateAvailable) {
+			t.Errorf("output missing %q status:\n%s", statusUpdateAvailable, out)
+		}
+		if !strings.Contains(out, "Total: 2 dependencies (1 current, 1 with updates available)") {
+			t.Errorf("output missing inventory summary:\n%s", out)
+		}
+	})
+
+	t.Run("flag unset hides current dependencies", func(t *testing.T) {
+		out := captureStdout(t, func() {
+			if err := outputPlanTable(inventoryPlanResult()); err != nil {
+				t.Fatalf("outputPlanTable() error = %v", err)
+			}
+		})
+
+		if strings.Contains(out, "lodash") {
+			t.Errorf("up-to-date dependency should be hidden:\n%s", out)
+		}
+		if !strings.Contains(out, "express") {
+			t.Errorf("output missing update for express:\n%s", out)
+		}
+	})
+}
+
+func TestPlanOutput_ShowUpToDate(t *testing.T) {
+	orig, origShow := colorEnabled, planShowUpToDate
+	colorEnabled, planShowUpToDate = false, true
+	defer func() { colorEnabled, planShowUpToDate = orig, origShow }()
+
+	result := inventoryPlanResult()
+	result.Plans = append(result.Plans, &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "web/package.json", Type: "npm"},
+	})
+
+	out := captureStdout(t, func() {
+		if err := outputPlanTable(result); err != nil {
+			t.Fatalf("outputPlanTable() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(out, "web/package.json (npm):\n✓ All dependencies are up-to-date") {
+		t.Errorf("output missing up-to-date manifest:\n%s", out)
+	}
+	if !strings.Contains(out, "Total: 1 updates across 1 manifests (1 up-to-date)") {
+		t.Errorf("output missing up-to-date summary:\n%s", out)
+	}
+}
+
+func TestPlanOutput_Unchecked(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	result := inventoryPlanResult()
+	result.Plans[0].Unchecked = []engine.UncheckedDependency{
+		{Name: "lodash", Datasource: "npm", Reason: "lookup timed out"},
+	}
+
+	out := captureStdout(t, func() {
+		if err := outputPlanTable(result); err != nil {
+			t.Fatalf("outputPlanTable() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(out, "Unchecked (lookup timed out):") {
+		t.Fatalf("output missing unchecked section:\n%s", out)
+	}
+	if !strings.Contains(out, "  - package.json: lodash (lookup timed out)") {
+		t.Errorf("output missing unchecked dependency:\n%s", out)
+	}
+	if strings.Contains(out, "Errors:") {
+		t.Errorf("unchecked dependencies should not be reported as errors:\n%s", out)
+	}
+	if !strings.Contains(out, "express") {
+		t.Errorf("output missing update for express:\n%s", out)
+	}
+}
+
+func TestPlanOutput_Workspace(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	result := inventoryPlanResult()
+	result.Plans[0].Manifest.Path = "packages/web/package.json"
+	result.Plans[0].Manifest.Metadata = map[string]interface{}{"workspace": "package.json"}
+
+	out := captureStdout(t, func() {
+		if err := outputPlanTable(result); err != nil {
+			t.Fatalf("outputPlanTable() error = %v", err)
+		}
+	})
+
+	if !strings.Contains(out, "packages/web/package.json (npm, workspace package.json):") {
+		t.Errorf("output missing workspace of member manifest:\n%s", out)
+	}
+}
+
+func TestPlanOutput_JSON(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	origOnly, origOutput, origFail := planOnly, planOutput, failOnError
+	defer func() { planOnly, planOutput, failOnError = origOnly, origOutput, origFail }()
+	planOnly = staticIntegrationName + "," + failingIntegrationName
+	planOutput = planOutputJSON
+	failOnError = false
+
+	var err error
+	out := captureStdout(t, func() { err = runPlan(nil, nil) })
+	if err != nil {
+		t.Fatalf("runPlan() error = %v, want nil when updates exist", err)
+	}
+
+	// The document round-trips into engine.PlanResult
+	var result engine.PlanResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("output is not a PlanResult: %v\n%s", err, out)
+	}
+
+	if len(result.Plans) != 1 || len(result.Plans[0].Updates) != 1 {
+		t.Fatalf("Plans = %+v, want one plan with one update", result.Plans)
+	}
+	update := result.Plans[0].Updates[0]
+	if update.Dependency.Name != "left-pad" || update.TargetVersion != "1.3.0" {
+		t.Errorf("update = %+v, want left-pad 1.3.0", update)
+	}
+	if update.Impact != "minor" || update.PolicySource != engine.PolicySourceDefault || update.Group != "utils" {
+		t.Errorf("update impact/policy/group = %q/%q/%q, want minor/%s/utils",
+			update.Impact, update.PolicySource, update.Group, engine.PolicySourceDefault)
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "registry unreachable") {
+		t.Errorf("Errors = %v, want the scan error", result.Errors)
+	}
+
+	t.Run("fail-on-error sets the exit code", func(t *testing.T) {
+		failOnError = true
+		var err error
+		captureStdout(t, func() { err = runPlan(nil, nil) })
+		if !errors.Is(err, ErrRunErrors) {
+			t.Errorf("runPlan() error = %v, want ErrRunErrors", err)
+		}
+	})
+}
+
+func TestPlanOutput_Deterministic(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	origOnly, origOutput, origFail, origDeterministic := planOnly, planOutput, failOnError, deterministic
+	defer func() {
+		planOnly, planOutput, failOnError, deterministic = origOnly, origOutput, origFail, origDeterministic
+	}()
+	planOnly = staticIntegrationName + "," + failingIntegrationName
+	planOutput = planOutputJSON
+	failOnError = false
+	deterministic = true
+
+	run := func() string {
+		var err error
+		out := captureStdout(t, func() { err = runPlan(nil, nil) })
+		if err != nil {
+			t.Fatalf("runPlan() error = %v", err)
+		}
+		return out
+	}
+
+	first, second := run(), run()
+	if first != second {
+		t.Errorf("output differs between runs:\n--- first\n%s\n--- second\n%s", first, second)
+	}
+	if !strings.Contains(first, `"timestamp": "0001-01-01T00:00:00Z"`) {
+		t.Errorf("timestamp not pinned:\n%s", first)
+	}
+}
+
+func TestPlanDashboard(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	origOnly, origDashboard, origFail := planOnly, planDashboard, failOnError
+	defer func() { planOnly, planDashboard, failOnError = origOnly, origDashboard, origFail }()
+	planOnly = staticIntegrationName + "," + failingIntegrationName
+	planDashboard = "DASHBOARD.md"
+	failOnError = false
+
+	var err error
+	out := captureStdout(t, func() { err = runPlan(nil, nil) })
+	if err != nil {
+		t.Fatalf("runPlan() error = %v", err)
+	}
+	if !strings.Contains(out, "Dashboard written to DASHBOARD.md") {
+		t.Errorf("output missing dashboard message:\n%s", out)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "DASHBOARD.md"))
+	if err != nil {
+		t.Fatalf("dashboard not written: %v", err)
+	}
+	got := string(data)
+	for _, want := range []string{
+		"# Dependency Dashboard",
+		"- [ ] <!-- update:static.json:left-pad --> **left-pad**",
+		"## Errors",
+		"registry unreachable",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("dashboard missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestResolvePlanFormat(t *testing.T) {
+	tests := []struct {
+		output, format string
+		want           string
+		wantErr        bool
+	}{
+		{output: planOutputText, format: "table", want: "table"},
+		{output: planOutputText, format: "template", want: "template"},
+		{output: planOutputJSON, format: "table", want: "json"},
+		{output: planOutputJSON, format: "json", want: "json"},
+		{output: planOutputJSON, format: "template", wantErr: true},
+		{output: "yaml", format: "table", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := resolvePlanFormat(tt.output, tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolvePlanFormat(%q, %q) error = %v, wantErr %v", tt.output, tt.format, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolvePlanFormat(%q, %q) = %q, want %q", tt.output, tt.format, got, tt.want)
+		}
+	}
+}
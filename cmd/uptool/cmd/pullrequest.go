@@ -0,0 +1,73 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/pullrequest"
+)
+
+// newPublisher creates the publisher used by update --create-pr.
+// Tests replace it to capture pull requests instead of pushing them.
+var newPublisher = func() (pullrequest.Publisher, error) {
+	return pullrequest.NewGitHub()
+}
+
+// pullRequests builds the pull requests for the applied updates: a single one
+// when batch is set, otherwise one per update group or manifest.
+func pullRequests(plans []*engine.UpdatePlan, updateResult *engine.UpdateResult, batch bool, branch, title string) []pullrequest.Request {
+	applied := appliedPlans(plans, updateResult)
+	if len(applied) == 0 {
+		return nil
+	}
+	if batch {
+		return []pullrequest.Request{pullrequest.Batch(applied, branch, title)}
+	}
+	return pullrequest.PerGroup(applied, branch, title)
+}
+
+// publishPullRequests opens the pull requests for the applied updates and
+// prints their URLs.
+func publishPullRequests(ctx context.Context, plans []*engine.UpdatePlan, updateResult *engine.UpdateResult, batch bool, branch, title string) error {
+	requests := pullRequests(plans, updateResult, batch, branch, title)
+	if len(requests) == 0 {
+		fmt.Println("\nNo applied updates; no pull request created.")
+		return nil
+	}
+
+	publisher, err := newPublisher()
+	if err != nil {
+		return fmt.Errorf("create pull request: %w", err)
+	}
+
+	fmt.Println()
+	for _, req := range requests {
+		url, err := publisher.Publish(ctx, req)
+		if err != nil {
+			return fmt.Errorf("create pull request %s: %w", req.Branch, err)
+		}
+		fmt.Printf("Created pull request: %s\n", url)
+	}
+	return nil
+}
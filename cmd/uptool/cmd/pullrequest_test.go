@@ -0,0 +1,127 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/pullrequest"
+)
+
+// recordingPublisher captures published pull requests.
+type recordingPublisher struct {
+	requests []pullrequest.Request
+}
+
+func (r *recordingPublisher) Publish(ctx context.Context, req pullrequest.Request) (string, error) {
+	r.requests = append(r.requests, req)
+	return "https://github.com/owner/repo/pull/1", nil
+}
+
+func TestPublishPullRequests_Batch(t *testing.T) {
+	recorder := &recordingPublisher{}
+	original := newPublisher
+	newPublisher = func() (pullrequest.Publisher, error) { return recorder, nil }
+	t.Cleanup(func() { newPublisher = original })
+
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "express", CurrentVersion: "4.18.0"}, TargetVersion: "4.19.2", Impact: "minor"},
+			},
+		},
+		{
+			Manifest: &engine.Manifest{Path: "charts/app/Chart.yaml", Type: "helm"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "postgresql", CurrentVersion: "12.0.0"}, TargetVersion: "13.1.0", Impact: "major"},
+				{Dependency: engine.Dependency{Name: "redis", CurrentVersion: "17.0.0"}, TargetVersion: "17.0.1", Impact: "patch"},
+			},
+		},
+		{
+			Manifest: &engine.Manifest{Path: "Dockerfile", Type: "docker"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "node", CurrentVersion: "20"}, TargetVersion: "22", Impact: "major"},
+			},
+		},
+	}
+	updateResult := &engine.UpdateResult{Results: []*engine.ApplyResult{
+		{Manifest: plans[0].Manifest, Applied: 1},
+		{Manifest: plans[1].Manifest, Applied: 2},
+		{Manifest: plans[2].Manifest, Applied: 0, Failed: 1, Errors: []string{"node: write failed"}},
+	}}
+
+	out := captureStdout(t, func() {
+		if err := publishPullRequests(context.Background(), plans, updateResult, true, "deps", "chore: update dependencies"); err != nil {
+			t.Errorf("publishPullRequests() error = %v", err)
+		}
+	})
+
+	if len(recorder.requests) != 1 {
+		t.Fatalf("published %d pull requests, want 1", len(recorder.requests))
+	}
+	req := recorder.requests[0]
+	if req.Branch != "deps" || len(req.Files) != 2 {
+		t.Errorf("pull request branch = %q, files = %v", req.Branch, req.Files)
+	}
+
+	chart := strings.Index(req.Body, "### charts/app/Chart.yaml")
+	pkg := strings.Index(req.Body, "### package.json")
+	if chart < 0 || pkg < 0 || chart > pkg {
+		t.Fatalf("body missing manifest sections in path order:\n%s", req.Body)
+	}
+	for _, want := range []string{"**postgresql**", "**redis**"} {
+		if i := strings.Index(req.Body, want); i < chart || i > pkg {
+			t.Errorf("%s not in the Chart.yaml section:\n%s", want, req.Body)
+		}
+	}
+	if i := strings.Index(req.Body, "**express**"); i < pkg {
+		t.Errorf("express not in the package.json section:\n%s", req.Body)
+	}
+	if strings.Contains(req.Body, "Dockerfile") {
+		t.Errorf("body includes a manifest with no applied updates:\n%s", req.Body)
+	}
+
+	if !strings.Contains(out, "Created pull request: https://github.com/owner/repo/pull/1") {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestPullRequests_PerGroup(t *testing.T) {
+	plans := []*engine.UpdatePlan{
+		{Manifest: &engine.Manifest{Path: "a/package.json"}, Updates: []engine.Update{{Dependency: engine.Dependency{Name: "x"}}}},
+		{Manifest: &engine.Manifest{Path: "b/package.json"}, Updates: []engine.Update{{Dependency: engine.Dependency{Name: "y"}}}},
+	}
+	updateResult := &engine.UpdateResult{Results: []*engine.ApplyResult{
+		{Manifest: plans[0].Manifest, Applied: 1},
+		{Manifest: plans[1].Manifest, Applied: 1},
+	}}
+
+	if got := len(pullRequests(plans, updateResult, false, "deps", "update")); got != 2 {
+		t.Errorf("pullRequests() without batch = %d requests, want 2", got)
+	}
+	if got := len(pullRequests(plans, updateResult, true, "deps", "update")); got != 1 {
+		t.Errorf("pullRequests() with batch = %d requests, want 1", got)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/dependabot"
+)
+
+// defaultDependabotPath is read by --registries-from-dependabot without a value.
+const defaultDependabotPath = ".github/dependabot.yml"
+
+// configureDependabotRegistries imports the private registries defined in a
+// dependabot.yml into the datasources, so lookups authenticate the same way
+// Dependabot does. Problems are logged rather than failing the command.
+func configureDependabotRegistries(path string, logger *slog.Logger) {
+	cfg, err := dependabot.LoadConfig(path)
+	if err != nil {
+		logger.Warn("failed to load dependabot registries", "path", path, "error", err)
+		return
+	}
+
+	creds := dependabotRegistryCredentials(cfg)
+	for _, registryType := range datasource.ConfigureRegistries(creds) {
+		logger.Warn("dependabot registry type not supported, skipped", "type", registryType)
+	}
+	logger.Debug("imported dependabot registries", "path", path, "count", len(creds))
+}
+
+// dependabotRegistryCredentials converts dependabot registry definitions, in
+// name order, with secret references resolved from the environment.
+func dependabotRegistryCredentials(cfg *dependabot.Config) []datasource.RegistryCredentials {
+	names := make([]string, 0, len(cfg.Registries))
+	for name := range cfg.Registries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	creds := make([]datasource.RegistryCredentials, 0, len(names))
+	for _, name := range names {
+		reg := cfg.Registries[name].ExpandEnv()
+		creds = append(creds, datasource.RegistryCredentials{
+			Type:         reg.Type,
+			URL:          reg.URL,
+			Username:     reg.Username,
+			Password:     reg.Password,
+			Token:        reg.Token,
+			ReplacesBase: reg.ReplacesBase,
+		})
+	}
+	return creds
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/dependabot"
+)
+
+func TestDependabotRegistryCredentials(t *testing.T) {
+	t.Setenv("NPM_TOKEN", "npm-secret")
+	t.Setenv("DOCKER_PASS", "docker-secret")
+
+	path := filepath.Join(t.TempDir(), "dependabot.yml")
+	content := `version: 2
+registries:
+  npm-private:
+    type: npm-registry
+    url: https://npm.example.com
+    token: ${{secrets.NPM_TOKEN}}
+    replaces-base: true
+  docker-private:
+    type: docker-registry
+    url: registry.example.com
+    username: bot
+    password: ${{ secrets.DOCKER_PASS }}
+updates:
+  - package-ecosystem: npm
+    directory: "/"
+    schedule:
+      interval: weekly
+    registries:
+      - npm-private
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := dependabot.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	got := dependabotRegistryCredentials(cfg)
+	want := []datasource.RegistryCredentials{
+		{Type: "docker-registry", URL: "registry.example.com", Username: "bot", Password: "docker-secret"},
+		{Type: "npm-registry", URL: "https://npm.example.com", Token: "npm-secret", ReplacesBase: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dependabotRegistryCredentials() = %+v, want %+v", got, want)
+	}
+}
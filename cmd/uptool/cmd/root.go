@@ -21,18 +21,44 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
 	"github.com/santosr2/uptool/internal/version"
 )
 
 var (
-	quietFlag   bool
-	verboseFlag bool
-	configFlag  string
-	logLevel    = slog.LevelWarn
+	quietFlag     bool
+	quietErrors   bool
+	verboseFlag   bool
+	configFlag    string
+	colorFlag     string
+	failOnError   bool
+	statsNetwork  bool
+	concurrency   int
+	deterministic bool
+	runTimeout    = defaultRunTimeout
+
+	// runCtx carries the --timeout deadline of the running command.
+	runCtx    context.Context
+	cancelRun context.CancelFunc
+
+	registriesFromDependabot string
+	integrationConfigFlags   []string
+	noCache                  bool
+	cacheTTL                 = registry.DefaultCacheTTL
+
+	logLevel = slog.LevelWarn
 
 	rootCmd = &cobra.Command{
 		Use:   "uptool",
@@ -42,13 +68,44 @@ It scans repositories for dependency manifest files (package.json, Chart.yaml,
 .pre-commit-config.yaml, etc.), checks for available updates, and rewrites
 manifests with new versions while preserving formatting.`,
 		Version: version.Get(),
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Set log level based on flags
 			if quietFlag {
 				logLevel = slog.LevelError
 			} else if verboseFlag {
 				logLevel = slog.LevelDebug
 			}
+
+			// Resolve color output once for all commands
+			if err := validateColorMode(colorFlag); err != nil {
+				return err
+			}
+			colorEnabled = resolveColor(colorFlag, stdoutIsTerminal(), os.Getenv("NO_COLOR"))
+
+			n, err := resolveConcurrency(cmd.Flags().Changed("concurrency"), concurrency, os.Getenv(concurrencyEnv))
+			if err != nil {
+				return err
+			}
+			concurrency = n
+			if deterministic {
+				if cmd.Flags().Changed("concurrency") && concurrency != 1 {
+					return fmt.Errorf("--deterministic requires --concurrency=1")
+				}
+				concurrency = 1
+			}
+
+			if integrationConfig, err = parseIntegrationConfig(integrationConfigFlags); err != nil {
+				return err
+			}
+
+			configureHTTPCache()
+
+			if runTimeout > 0 {
+				runCtx, cancelRun = context.WithTimeoutCause(cmd.Context(), runTimeout,
+					fmt.Errorf("%w after %s", ErrTimeout, runTimeout))
+				cmd.SetContext(runCtx)
+			}
+			return nil
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -58,13 +115,102 @@ manifests with new versions while preserving formatting.`,
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().BoolVarP(&quietFlag, "quiet", "q", false, "suppress informational output (errors only)")
+	rootCmd.PersistentFlags().BoolVar(&quietErrors, "quiet-errors", false, "hide non-fatal errors from scan, plan, update, and diff output (--fail-on-error still sets the exit code)")
 	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "enable verbose debug output")
 	rootCmd.PersistentFlags().StringVar(&configFlag, "config", "", "path to config file (default: uptool.yaml)")
+	rootCmd.PersistentFlags().StringVar(&colorFlag, "color", colorAuto, "colorize output: auto, always, never (NO_COLOR disables auto)")
+	rootCmd.PersistentFlags().BoolVar(&failOnError, "fail-on-error", false, "exit non-zero if scan, plan, or update records any error")
+	rootCmd.PersistentFlags().BoolVar(&statsNetwork, "stats-network", false, "print HTTP request and cache statistics to stderr at the end of the run")
+	rootCmd.PersistentFlags().StringVar(&registriesFromDependabot, "registries-from-dependabot", "", "use the private registries defined in a dependabot.yml (default path: "+defaultDependabotPath+")")
+	rootCmd.PersistentFlags().Lookup("registries-from-dependabot").NoOptDefVal = defaultDependabotPath
+	rootCmd.PersistentFlags().StringArrayVar(&integrationConfigFlags, "integration-config", nil, "set a custom policy setting for an integration as INTEGRATION.KEY=VALUE, read by plugins from the policy's Custom map (repeatable)")
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 0, fmt.Sprintf("how many integrations run at once (default: number of CPUs, at most %d; env %s)", engine.MaxDefaultConcurrency, concurrencyEnv))
+	rootCmd.PersistentFlags().BoolVar(&deterministic, "deterministic", false, "produce byte-stable output: run one integration at a time, sort results, and pin timestamps")
+	rootCmd.PersistentFlags().DurationVar(&runTimeout, "timeout", defaultRunTimeout, "abort scan, plan, and update after this long, reporting partial results (0 disables)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "do not read or write the on-disk registry response cache")
+	rootCmd.PersistentFlags().DurationVar(&cacheTTL, "cache-ttl", registry.DefaultCacheTTL, "reuse cached registry responses for this long when the registry sets no max-age")
+
+	if err := rootCmd.RegisterFlagCompletionFunc("color", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{colorAuto, colorAlways, colorNever}, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
 }
 
+// defaultRunTimeout bounds a command so a hung registry cannot block it forever.
+const defaultRunTimeout = 5 * time.Minute
+
+// ErrTimeout is returned when a command runs past --timeout. Work finished
+// before the deadline is still reported.
+var ErrTimeout = errors.New("timed out")
+
 // Execute runs the root command
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if runCtx != nil {
+		if err == nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = context.Cause(runCtx)
+		}
+		cancelRun()
+	}
+
+	// Printed even when the command fails, since failures are often network related
+	if statsNetwork {
+		printNetworkStats(os.Stderr, registry.NetworkStats(), datasource.GetCacheStats())
+	}
+
+	return err
+}
+
+// commandContext returns the context of a running command, which carries the
+// --timeout deadline, or a background context when cmd is nil (as in tests).
+func commandContext(cmd *cobra.Command) context.Context {
+	if cmd == nil || cmd.Context() == nil {
+		return context.Background()
+	}
+	return cmd.Context()
+}
+
+// concurrencyEnv sets the concurrency when --concurrency is not given.
+const concurrencyEnv = "UPTOOL_CONCURRENCY"
+
+// resolveConcurrency returns the concurrency limit from --concurrency when
+// set, otherwise from UPTOOL_CONCURRENCY. Zero selects the engine default.
+func resolveConcurrency(flagSet bool, flag int, env string) (int, error) {
+	if flagSet {
+		if flag < 1 {
+			return 0, fmt.Errorf("invalid --concurrency %d: must be at least 1", flag)
+		}
+		return flag, nil
+	}
+	if env == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(env)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid %s %q: must be an integer of at least 1", concurrencyEnv, env)
+	}
+	return n, nil
+}
+
+// configureHTTPCache enables the on-disk registry response cache unless
+// --no-cache is set. A cache directory that cannot be located only disables
+// caching.
+func configureHTTPCache() {
+	if noCache || cacheTTL <= 0 {
+		registry.SetHTTPCache(nil, 0)
+		return
+	}
+
+	dir, err := registry.DefaultCacheDir()
+	if err == nil {
+		var cache *registry.DiskCache
+		if cache, err = registry.NewDiskCache(dir); err == nil {
+			registry.SetHTTPCache(cache, cacheTTL)
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Warning: registry response cache disabled: %v\n", err)
 }
 
 // GetLogLevel returns the current log level based on flags
@@ -0,0 +1,268 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+// SARIF 2.1.0 identifiers written to every log.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifToolURI = "https://github.com/santosr2/uptool"
+)
+
+// sarifLog is the subset of the SARIF 2.1.0 object model uptool emits.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifInvocation struct {
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+}
+
+type sarifNotification struct {
+	Message sarifMessage `json:"message"`
+	Level   string       `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	RuleIndex           int               `json:"ruleIndex"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	Region           *sarifRegion          `json:"region,omitempty"`
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevel maps an update's impact to a SARIF result level.
+func sarifLevel(impact string) string {
+	switch impact {
+	case "major":
+		return "error"
+	case "minor":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifRuleID returns the stable rule id for outdated dependencies of an integration.
+func sarifRuleID(integration string) string {
+	return "uptool/" + integration + "/outdated"
+}
+
+// buildSARIF converts every planned update into a SARIF result located at the
+// dependency's line in its manifest. Plan errors become tool notifications.
+func buildSARIF(result *engine.PlanResult, repoRoot string) *sarifLog {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "uptool",
+			Version:        version.Get(),
+			InformationURI: sarifToolURI,
+			Rules:          []sarifRule{},
+		}},
+		Invocations: []sarifInvocation{{ExecutionSuccessful: true}},
+		Results:     []sarifResult{},
+	}
+
+	for _, e := range result.Errors {
+		run.Invocations[0].ToolExecutionNotifications = append(run.Invocations[0].ToolExecutionNotifications,
+			sarifNotification{Level: "error", Message: sarifMessage{Text: e}})
+	}
+
+	// Rules are listed in integration name order so ruleIndex is deterministic
+	var integrations []string
+	seen := make(map[string]bool)
+	for _, plan := range result.Plans {
+		if len(plan.Updates) > 0 && !seen[plan.Manifest.Type] {
+			seen[plan.Manifest.Type] = true
+			integrations = append(integrations, plan.Manifest.Type)
+		}
+	}
+	sort.Strings(integrations)
+
+	ruleIndex := make(map[string]int, len(integrations))
+	for i, name := range integrations {
+		ruleIndex[name] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   sarifRuleID(name),
+			Name:                 "OutdatedDependency",
+			ShortDescription:     sarifMessage{Text: fmt.Sprintf("Outdated %s dependency", name)},
+			DefaultConfiguration: sarifConfiguration{Level: "warning"},
+		})
+	}
+
+	for _, plan := range result.Plans {
+		uri := sarifURI(plan.Manifest.Path, repoRoot)
+		for i := range plan.Updates {
+			update := &plan.Updates[i]
+			dep := update.Dependency
+
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: uri, URIBaseID: "%SRCROOT%"},
+			}}
+			if line := declarationLine(&dep, plan.Manifest.Path, repoRoot); line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+			}
+
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    sarifRuleID(plan.Manifest.Type),
+				RuleIndex: ruleIndex[plan.Manifest.Type],
+				Level:     sarifLevel(update.Impact),
+				Message: sarifMessage{Text: fmt.Sprintf("%s %s can be updated to %s (%s)",
+					dep.Name, dep.CurrentVersion, update.TargetVersion, update.Impact)},
+				Locations: []sarifLocation{location},
+				// Stable across runs so code scanning tracks one alert per dependency
+				PartialFingerprints: map[string]string{
+					"uptoolDependency/v1": uri + ":" + dep.Name,
+				},
+			})
+		}
+	}
+
+	return &sarifLog{Schema: sarifSchema, Version: sarifVersion, Runs: []sarifRun{run}}
+}
+
+// outputSARIF writes the plan as an indented SARIF log.
+func outputSARIF(w io.Writer, result *engine.PlanResult, repoRoot string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(buildSARIF(result, repoRoot))
+}
+
+// sarifURI returns a manifest path relative to repoRoot with forward slashes.
+func sarifURI(path, repoRoot string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(repoRoot, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// manifestFile resolves a manifest path against repoRoot.
+func manifestFile(path, repoRoot string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(repoRoot, path)
+}
+
+// declarationLine returns the line an integration recorded for dep during
+// Detect, falling back to searching the manifest text for it.
+func declarationLine(dep *engine.Dependency, manifestPath, repoRoot string) int {
+	if dep.Line > 0 {
+		return dep.Line
+	}
+	return dependencyLine(manifestFile(manifestPath, repoRoot), dep.Name, dep.CurrentVersion)
+}
+
+// dependencyLine returns the 1-based line of a manifest that declares name,
+// preferring a line that also holds the current version. It returns 0 when the
+// manifest cannot be read or does not mention the dependency.
+func dependencyLine(path, name, current string) int {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return 0
+	}
+	defer func() { _ = f.Close() }() //nolint:errcheck // read-only file
+
+	nameLine := 0
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if !strings.Contains(text, name) {
+			continue
+		}
+		if current == "" || strings.Contains(text, current) {
+			return line
+		}
+		if nameLine == 0 {
+			nameLine = line
+		}
+	}
+	return nameLine
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// validateSARIF checks a log against the parts of the SARIF 2.1.0 schema that
+// GitHub code scanning requires: the version, a tool driver with rules, and
+// results whose levels, rule ids, and locations are well-formed.
+func validateSARIF(t *testing.T, data []byte) {
+	t.Helper()
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("SARIF is not valid JSON: %v", err)
+	}
+	if doc["version"] != "2.1.0" {
+		t.Errorf("version = %v, want 2.1.0", doc["version"])
+	}
+	if _, ok := doc["$schema"].(string); !ok {
+		t.Error("$schema missing")
+	}
+
+	runs, ok := doc["runs"].([]interface{})
+	if !ok || len(runs) == 0 {
+		t.Fatalf("runs = %v, want a non-empty array", doc["runs"])
+	}
+
+	levels := map[string]bool{"none": true, "note": true, "warning": true, "error": true}
+	for _, r := range runs {
+		run := r.(map[string]interface{})
+		driver, ok := run["tool"].(map[string]interface{})["driver"].(map[string]interface{})
+		if !ok {
+			t.Fatal("run.tool.driver missing")
+		}
+		if name, _ := driver["name"].(string); name == "" {
+			t.Error("run.tool.driver.name missing")
+		}
+
+		rules := make(map[string]int)
+		ruleList, _ := driver["rules"].([]interface{})
+		for i, rule := range ruleList {
+			id, _ := rule.(map[string]interface{})["id"].(string)
+			if id == "" {
+				t.Errorf("rules[%d].id missing", i)
+			}
+			rules[id] = i
+		}
+
+		results, ok := run["results"].([]interface{})
+		if !ok {
+			t.Fatal("run.results must be an array")
+		}
+		for i, res := range results {
+			result := res.(map[string]interface{})
+
+			ruleID, _ := result["ruleId"].(string)
+			index, known := rules[ruleID]
+			if !known {
+				t.Errorf("results[%d].ruleId %q not declared in driver.rules", i, ruleID)
+			}
+			if got, _ := result["ruleIndex"].(float64); int(got) != index {
+				t.Errorf("results[%d].ruleIndex = %v, want %d", i, result["ruleIndex"], index)
+			}
+			if level, _ := result["level"].(string); !levels[level] {
+				t.Errorf("results[%d].level = %q, not a SARIF level", i, level)
+			}
+			if text, _ := result["message"].(map[string]interface{})["text"].(string); text == "" {
+				t.Errorf("results[%d].message.text missing", i)
+			}
+
+			locations, _ := result["locations"].([]interface{})
+			for _, loc := range locations {
+				physical := loc.(map[string]interface{})["physicalLocation"].(map[string]interface{})
+				if uri, _ := physical["artifactLocation"].(map[string]interface{})["uri"].(string); uri == "" {
+					t.Errorf("results[%d] artifactLocation.uri missing", i)
+				}
+				if region, ok := physical["region"].(map[string]interface{}); ok {
+					if line, _ := region["startLine"].(float64); line < 1 {
+						t.Errorf("results[%d] region.startLine = %v, want >= 1", i, region["startLine"])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBuildSARIF(t *testing.T) {
+	repoRoot := t.TempDir()
+	manifest := `{
+  "name": "app",
+  "dependencies": {
+    "express": "^4.17.0",
+    "lodash": "^4.17.20",
+    "react": "^17.0.0"
+  }
+}
+`
+	if err := os.WriteFile(filepath.Join(repoRoot, "package.json"), []byte(manifest), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dep := func(name, current string) engine.Dependency {
+		return engine.Dependency{Name: name, CurrentVersion: current, Type: "direct"}
+	}
+	result := &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{
+			{
+				Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+				Updates: []engine.Update{
+					{Dependency: dep("react", "17.0.0"), TargetVersion: "18.2.0", Impact: "major"},
+					{Dependency: dep("express", "4.17.0"), TargetVersion: "4.18.2", Impact: "minor"},
+					{Dependency: dep("lodash", "4.17.20"), TargetVersion: "4.17.21", Impact: "patch"},
+				},
+			},
+			{
+				Manifest: &engine.Manifest{Path: filepath.Join(repoRoot, "charts", "Chart.yaml"), Type: "helm"},
+				Updates: []engine.Update{
+					{Dependency: dep("postgresql", "12.0.0"), TargetVersion: "12.1.0", Impact: "minor"},
+				},
+			},
+		},
+		Errors: []string{"terraform: registry unreachable"},
+	}
+
+	var buf bytes.Buffer
+	if err := outputSARIF(&buf, result, repoRoot); err != nil {
+		t.Fatalf("outputSARIF() error = %v", err)
+	}
+	validateSARIF(t, buf.Bytes())
+
+	log := buildSARIF(result, repoRoot)
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "uptool/helm/outdated" ||
+		run.Tool.Driver.Rules[1].ID != "uptool/npm/outdated" {
+		t.Errorf("rules = %+v, want uptool/helm/outdated and uptool/npm/outdated", run.Tool.Driver.Rules)
+	}
+
+	want := []struct {
+		ruleID string
+		level  string
+		uri    string
+		line   int
+	}{
+		{ruleID: "uptool/npm/outdated", level: "error", uri: "package.json", line: 6},
+		{ruleID: "uptool/npm/outdated", level: "warning", uri: "package.json", line: 4},
+		{ruleID: "uptool/npm/outdated", level: "note", uri: "package.json", line: 5},
+		{ruleID: "uptool/helm/outdated", level: "warning", uri: "charts/Chart.yaml"},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("len(results) = %d, want %d", len(run.Results), len(want))
+	}
+	for i, w := range want {
+		got := run.Results[i]
+		loc := got.Locations[0].PhysicalLocation
+		if got.RuleID != w.ruleID || got.Level != w.level || loc.ArtifactLocation.URI != w.uri {
+			t.Errorf("results[%d] = %s/%s at %s, want %s/%s at %s",
+				i, got.RuleID, got.Level, loc.ArtifactLocation.URI, w.ruleID, w.level, w.uri)
+		}
+		switch {
+		case w.line == 0 && loc.Region != nil:
+			t.Errorf("results[%d] region = %+v, want none for an unreadable manifest", i, loc.Region)
+		case w.line > 0 && (loc.Region == nil || loc.Region.StartLine != w.line):
+			t.Errorf("results[%d] region = %+v, want line %d", i, loc.Region, w.line)
+		}
+	}
+
+	notes := run.Invocations[0].ToolExecutionNotifications
+	if len(notes) != 1 || notes[0].Message.Text != "terraform: registry unreachable" {
+		t.Errorf("notifications = %+v, want the plan error", notes)
+	}
+}
+
+func TestSARIFLevel(t *testing.T) {
+	tests := map[string]string{"major": "error", "minor": "warning", "patch": "note", "": "note"}
+	for impact, want := range tests {
+		if got := sarifLevel(impact); got != want {
+			t.Errorf("sarifLevel(%q) = %q, want %q", impact, got, want)
+		}
+	}
+}
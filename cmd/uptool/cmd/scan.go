@@ -33,9 +33,21 @@ import (
 )
 
 var (
-	scanFormat  string
-	scanOnly    string
-	scanExclude string
+	scanFormat      string
+	scanOnly        string
+	scanExclude     string
+	scanExcludePath string
+	scanStdin       string
+	scanManifest    string
+	scanOutput      string
+
+	scanTrackedOnly bool
+)
+
+// Output modes accepted by scan --output.
+const (
+	scanOutputText  = "text"
+	scanOutputSARIF = "sarif"
 )
 
 var scanCmd = &cobra.Command{
@@ -51,6 +63,7 @@ This command walks the repository tree and detects manifest files like:
   - .tool-versions (asdf)
   - .tflint.hcl (tflint)
   - main.tf, *.tf (Terraform)
+  - .gitlab-ci.yml (GitLab CI)
 
 Results can be output in table or JSON format.`,
 	Example: `  # Scan all manifests
@@ -63,7 +76,19 @@ Results can be output in table or JSON format.`,
   uptool scan --only npm,helm
 
   # Scan everything except terraform
-  uptool scan --exclude terraform`,
+  uptool scan --exclude terraform
+
+  # Skip manifests under examples/ and any fixtures directory
+  uptool scan --exclude-path examples/,fixtures/
+
+  # Report outdated dependencies as SARIF for GitHub code scanning
+  uptool scan --output sarif > uptool.sarif
+
+  # Parse a package.json piped on stdin
+  cat package.json | uptool scan --stdin-type npm
+
+  # Scan a single manifest
+  uptool scan --manifest charts/app/Chart.yaml`,
 	RunE: runScan,
 }
 
@@ -71,8 +96,14 @@ func init() {
 	rootCmd.AddCommand(scanCmd)
 
 	scanCmd.Flags().StringVarP(&scanFormat, "format", "f", "table", "output format: table, json")
+	scanCmd.Flags().StringVar(&scanOutput, "output", scanOutputText, "output mode: text, sarif (available updates as SARIF 2.1.0 results)")
 	scanCmd.Flags().StringVar(&scanOnly, "only", "", "comma-separated integrations to include")
 	scanCmd.Flags().StringVar(&scanExclude, "exclude", "", "comma-separated integrations to exclude")
+	scanCmd.Flags().StringVar(&scanExcludePath, "exclude-path", "", "comma-separated gitignore-style path patterns to skip, in addition to .gitignore")
+	scanCmd.Flags().BoolVar(&scanTrackedOnly, "tracked-only", false, "skip manifests git does not track (all are scanned outside a git repository)")
+	scanCmd.Flags().StringVar(&scanStdin, "stdin-type", "", "read a single manifest of this integration from stdin instead of scanning")
+	scanCmd.Flags().StringVar(&scanManifest, "manifest", "", "scan only this manifest file, with the integrations that handle it")
+	scanCmd.MarkFlagsMutuallyExclusive("manifest", "stdin-type")
 
 	// Add shell completion for flags
 	if err := scanCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -82,17 +113,34 @@ func init() {
 		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
 	}
 
+	if err := scanCmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{scanOutputText, scanOutputSARIF}, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
 	if err := scanCmd.RegisterFlagCompletionFunc("only", completeIntegrations); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
 	}
 	if err := scanCmd.RegisterFlagCompletionFunc("exclude", completeIntegrations); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
 	}
+	if err := scanCmd.RegisterFlagCompletionFunc("stdin-type", completeStdinTypes); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to register shell completion: %v\n", err)
+	}
 }
 
 func runScan(cmd *cobra.Command, args []string) error {
+	switch scanOutput {
+	case scanOutputText, scanOutputSARIF, "":
+	default:
+		return fmt.Errorf("unsupported output mode: %s (valid: %s, %s)", scanOutput, scanOutputText, scanOutputSARIF)
+	}
+
 	eng := setupEngine()
-	ctx := context.Background()
+	eng.SetTrackedOnly(scanTrackedOnly)
+	_, excludePaths := parseFilters("", scanExcludePath)
+	eng.SetExcludePaths(excludePaths)
+	ctx := commandContext(cmd)
 
 	repoRoot, err := os.Getwd()
 	if err != nil {
@@ -101,19 +149,67 @@ func runScan(cmd *cobra.Command, args []string) error {
 
 	onlyList, excludeList := parseFilters(scanOnly, scanExclude)
 
-	result, err := eng.Scan(ctx, repoRoot, onlyList, excludeList)
-	if err != nil {
-		return fmt.Errorf("scan failed: %w", err)
+	var result *engine.ScanResult
+	if scanStdin != "" {
+		var cleanup func()
+		result, cleanup, err = scanStdinManifest(ctx, eng, scanStdin, os.Stdin)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+	} else if scanManifest != "" {
+		result, err = eng.ScanManifest(ctx, repoRoot, scanManifest)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+	} else {
+		result, err = eng.Scan(ctx, repoRoot, onlyList, excludeList)
+		if err != nil {
+			return fmt.Errorf("scan failed: %w", err)
+		}
+	}
+
+	if scanOutput == scanOutputSARIF {
+		return outputScanSARIF(ctx, eng, result, repoRoot)
+	}
+	if scanStdin != "" {
+		labelStdin(result.Manifests, nil)
 	}
 
 	switch scanFormat {
 	case "json":
-		return outputJSON(result)
+		err = outputJSON(result)
 	case "table":
-		return outputScanTable(result)
+		err = outputScanTable(result)
 	default:
 		return fmt.Errorf("unsupported format: %s", scanFormat)
 	}
+	if err != nil {
+		return err
+	}
+
+	return checkRunErrors(result.Errors)
+}
+
+// outputScanSARIF plans the scanned manifests and writes each available update
+// as a SARIF result, so code scanning can flag outdated dependencies.
+func outputScanSARIF(ctx context.Context, eng *engine.Engine, result *engine.ScanResult, repoRoot string) error {
+	planResult, err := eng.PlanWithOptions(ctx, result.Manifests, &engine.PlanOptions{
+		ReleaseTimestamps: releaseTimestamps(ctx, eng, result.Manifests),
+	})
+	if err != nil {
+		return fmt.Errorf("plan failed: %w", err)
+	}
+	if scanStdin != "" {
+		labelStdin(result.Manifests, planResult.Plans)
+	}
+	planResult.Errors = append(append([]string{}, result.Errors...), planResult.Errors...)
+
+	if err := outputSARIF(os.Stdout, planResult, repoRoot); err != nil {
+		return err
+	}
+
+	return checkRunErrors(planResult.Errors)
 }
 
 func outputScanTable(result *engine.ScanResult) error {
@@ -135,12 +231,7 @@ func outputScanTable(result *engine.ScanResult) error {
 
 	fmt.Printf("\nTotal: %d manifests\n", len(result.Manifests))
 
-	if len(result.Errors) > 0 {
-		fmt.Printf("\nErrors:\n")
-		for _, e := range result.Errors {
-			fmt.Printf("  - %s\n", e)
-		}
-	}
+	printErrors(os.Stdout, result.Errors)
 
 	return nil
 }
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/jsonschema"
+	"github.com/santosr2/uptool/internal/policy"
+)
+
+// schemaDocuments are the documents 'uptool schema' can describe.
+var schemaDocuments = map[string]func() *jsonschema.Schema{
+	"plan": func() *jsonschema.Schema {
+		return jsonschema.Generate(engine.PlanResult{}, jsonschema.JSON, "uptool plan")
+	},
+	"config": func() *jsonschema.Schema {
+		return jsonschema.Generate(policy.Config{}, jsonschema.YAML, "uptool configuration")
+	},
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema {plan|config}",
+	Short: "Print the JSON Schema of the plan output or the configuration",
+	Long: `Print a JSON Schema describing one of uptool's documents:
+
+  plan    the JSON written by 'uptool plan --output json' and 'plan --out'
+  config  the uptool.yaml configuration file
+
+The schema is generated from the Go types uptool itself reads and writes,
+so it always matches the running version. Use it to generate typed clients
+or to validate documents in other tooling.`,
+	Example: `  # Save the plan schema for code generation
+  uptool schema plan > plan.schema.json
+
+  # Print the configuration schema
+  uptool schema config`,
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: []string{"plan", "config"},
+	RunE:      runSchema,
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	generate, ok := schemaDocuments[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown schema %q: expected plan or config", args[0])
+	}
+	return outputJSON(generate())
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/jsonschema"
+)
+
+// schemaProperty follows a path such as "plans[].updates[].impact" through
+// the schema, resolving $ref, nullable anyOf, and array items along the way.
+func schemaProperty(t *testing.T, root *jsonschema.Schema, path string) *jsonschema.Schema {
+	t.Helper()
+
+	resolve := func(s *jsonschema.Schema) *jsonschema.Schema {
+		if len(s.AnyOf) > 0 {
+			s = s.AnyOf[0]
+		}
+		if s.Ref != "" {
+			def, ok := root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+			if !ok {
+				t.Fatalf("unresolved reference %s", s.Ref)
+			}
+			return def
+		}
+		return s
+	}
+
+	current := root
+	for _, part := range strings.Split(path, ".") {
+		name, isArray := strings.CutSuffix(part, "[]")
+		prop, ok := resolve(current).Properties[name]
+		if !ok {
+			t.Fatalf("schema has no property %q on the way to %s", name, path)
+		}
+		if isArray {
+			if prop.Type != "array" || prop.Items == nil {
+				t.Fatalf("property %q is not an array", name)
+			}
+			prop = prop.Items
+		}
+		current = prop
+	}
+	return resolve(current)
+}
+
+func TestSchemaDocuments_Plan(t *testing.T) {
+	schema := schemaDocuments["plan"]()
+
+	if schema.Schema != jsonschema.Draft || schema.Title != "uptool plan" {
+		t.Errorf("schema header = %q %q", schema.Schema, schema.Title)
+	}
+
+	for path, wantType := range map[string]string{
+		"plans[].updates[].impact":                     "string",
+		"plans[].updates[].policy_source":              "string",
+		"plans[].updates[].target_version":             "string",
+		"plans[].updates[].dependency.current_version": "string",
+		"plans[].updates[].breaking":                   "boolean",
+		"plans[].manifest.path":                        "string",
+		"plans[].held[].released_at":                   "string",
+		"timestamp":                                    "string",
+	} {
+		if got := schemaProperty(t, schema, path); got.Type != wantType {
+			t.Errorf("%s type = %q, want %q", path, got.Type, wantType)
+		}
+	}
+
+	update := schemaProperty(t, schema, "plans[].updates[]")
+	required := strings.Join(update.Required, ",")
+	if !strings.Contains(required, "impact") || strings.Contains(required, "policy_source") {
+		t.Errorf("update required = %v, want impact but not the omitempty policy_source", update.Required)
+	}
+
+	if _, err := json.Marshal(schema); err != nil {
+		t.Errorf("marshal schema: %v", err)
+	}
+}
+
+func TestSchemaDocuments_Config(t *testing.T) {
+	schema := schemaDocuments["config"]()
+
+	for _, path := range []string{
+		"version",
+		"integrations[].id",
+		"integrations[].policy.update",
+		"integrations[].policy.schedule.interval",
+		"org_policy",
+	} {
+		schemaProperty(t, schema, path)
+	}
+
+	// Unknown policy keys are kept by the inline custom map
+	policy := schemaProperty(t, schema, "integrations[].policy")
+	if policy.AdditionalProperties == nil {
+		t.Error("integration policy does not accept custom keys")
+	}
+	if len(policy.Required) != 0 {
+		t.Errorf("config properties are optional, got required %v", policy.Required)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// ghsaEcosystems maps integrations to the GitHub Advisory Database ecosystem
+// of their packages. Updates of other integrations have no advisories and are
+// dropped by --only-security.
+var ghsaEcosystems = map[string]string{
+	"actions": "ACTIONS",
+	"cargo":   "RUST",
+	"gomod":   "GO",
+	"npm":     "NPM",
+	"python":  "PIP",
+}
+
+// advisorySource looks up the vulnerable version ranges of a package.
+type advisorySource interface {
+	GetSecurityVulnerabilities(ctx context.Context, ecosystem, pkg string) ([]registry.SecurityVulnerability, error)
+}
+
+// newAdvisorySource returns a GitHub Advisory Database client authenticated
+// with GITHUB_TOKEN, which its GraphQL API requires.
+func newAdvisorySource() (advisorySource, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("--only-security and --apply-overrides need GITHUB_TOKEN to query the GitHub Advisory Database")
+	}
+	return registry.NewGitHubClient(token, registry.DefaultGitHubMaxRetries, registry.DefaultGitHubMaxWait), nil
+}
+
+// filterSecurityUpdates keeps only updates whose current version is affected
+// by a security advisory that the target version is no longer affected by,
+// and records those advisories on the update. Lookup failures are returned
+// as errors and drop the package's updates.
+func filterSecurityUpdates(ctx context.Context, source advisorySource, plans []*engine.UpdatePlan) ([]*engine.UpdatePlan, []string) {
+	type lookup struct {
+		vulns []registry.SecurityVulnerability
+		err   error
+	}
+	cache := make(map[string]lookup)
+	var errs []string
+
+	filtered := make([]*engine.UpdatePlan, 0, len(plans))
+	for _, plan := range plans {
+		p := *plan
+		p.Updates = make([]engine.Update, 0, len(plan.Updates))
+
+		ecosystem, ok := ghsaEcosystems[plan.Manifest.Type]
+		if !ok {
+			p.RecordFiltered(plan.Updates)
+			filtered = append(filtered, &p)
+			continue
+		}
+
+		for i := range plan.Updates {
+			update := plan.Updates[i]
+			name := update.Dependency.Name
+
+			key := ecosystem + "/" + name
+			result, seen := cache[key]
+			if !seen {
+				result.vulns, result.err = source.GetSecurityVulnerabilities(ctx, ecosystem, name)
+				cache[key] = result
+				if result.err != nil {
+					errs = append(errs, fmt.Sprintf("%s: %v", plan.Manifest.Path, result.err))
+				}
+			}
+			if result.err != nil {
+				continue
+			}
+
+			advisories := fixedAdvisories(result.vulns, update.Dependency.CurrentVersion, update.TargetVersion)
+			if len(advisories) == 0 {
+				continue
+			}
+			update.Security = true
+			update.SecurityAdvisories = advisories
+			p.Updates = append(p.Updates, update)
+		}
+		p.RecordFiltered(plan.Updates)
+		filtered = append(filtered, &p)
+	}
+	return filtered, errs
+}
+
+// fixedAdvisories returns the advisories whose vulnerable range contains
+// current but not target. Unparseable versions or ranges match nothing.
+func fixedAdvisories(vulns []registry.SecurityVulnerability, current, target string) []engine.Advisory {
+	currentVersion, err := advisoryVersion(current)
+	if err != nil {
+		return nil
+	}
+	targetVersion, err := advisoryVersion(target)
+	if err != nil {
+		return nil
+	}
+
+	var advisories []engine.Advisory
+	seen := make(map[string]bool)
+	for _, v := range vulns {
+		affected, err := semver.NewConstraint(v.VulnerableVersionRange)
+		if err != nil {
+			continue
+		}
+		if !affected.Check(currentVersion) || affected.Check(targetVersion) || seen[v.GHSAID] {
+			continue
+		}
+		seen[v.GHSAID] = true
+		advisories = append(advisories, engine.Advisory{ID: v.GHSAID, Severity: v.Severity, Summary: v.Summary})
+	}
+	return advisories
+}
+
+// advisoryVersion parses the version a dependency is at. Range constraints
+// such as "^4.17.20" are reduced to their lower bound.
+func advisoryVersion(raw string) (*semver.Version, error) {
+	fields := strings.Fields(strings.TrimLeft(raw, "^~=>< "))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty version")
+	}
+	return semver.NewVersion(strings.TrimSuffix(fields[0], ","))
+}
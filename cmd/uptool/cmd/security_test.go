@@ -0,0 +1,117 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+type mockAdvisories struct {
+	vulns map[string][]registry.SecurityVulnerability
+	calls int
+}
+
+func (m *mockAdvisories) GetSecurityVulnerabilities(_ context.Context, ecosystem, pkg string) ([]registry.SecurityVulnerability, error) {
+	m.calls++
+	if pkg == "broken" {
+		return nil, errors.New("graphql: rate limited")
+	}
+	return m.vulns[ecosystem+"/"+pkg], nil
+}
+
+func TestFilterSecurityUpdates(t *testing.T) {
+	source := &mockAdvisories{vulns: map[string][]registry.SecurityVulnerability{
+		"NPM/lodash": {
+			{GHSAID: "GHSA-p6mc-m468-83gw", Severity: "HIGH", Summary: "Prototype pollution", VulnerableVersionRange: ">= 4.0.0, < 4.17.20"},
+			{GHSAID: "GHSA-35jh-r3h4-6jhm", Severity: "CRITICAL", Summary: "Command injection", VulnerableVersionRange: "< 4.17.21"},
+			{GHSAID: "GHSA-unfixed", Severity: "LOW", Summary: "Not fixed by the target", VulnerableVersionRange: "< 5.0.0"},
+		},
+		"NPM/express": {
+			{GHSAID: "GHSA-old", Severity: "MODERATE", Summary: "Fixed long ago", VulnerableVersionRange: "< 3.0.0"},
+		},
+	}}
+
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "lodash", CurrentVersion: "^4.17.15"}, TargetVersion: "4.17.21"},
+				{Dependency: engine.Dependency{Name: "express", CurrentVersion: "^4.18.0"}, TargetVersion: "4.21.0"},
+				{Dependency: engine.Dependency{Name: "left-pad", CurrentVersion: "1.0.0"}, TargetVersion: "1.3.0"},
+				{Dependency: engine.Dependency{Name: "broken", CurrentVersion: "1.0.0"}, TargetVersion: "2.0.0"},
+			},
+		},
+		{
+			Manifest: &engine.Manifest{Path: ".pre-commit-config.yaml", Type: "precommit"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "lodash", CurrentVersion: "4.17.15"}, TargetVersion: "4.17.21"},
+			},
+		},
+	}
+
+	got, errs := filterSecurityUpdates(context.Background(), source, plans)
+
+	if len(got) != 2 || len(got[0].Updates) != 1 {
+		t.Fatalf("filterSecurityUpdates() kept %+v, want only the lodash update", got)
+	}
+	update := got[0].Updates[0]
+	if update.Dependency.Name != "lodash" || !update.Security {
+		t.Errorf("kept update = %+v, want lodash marked as a security update", update)
+	}
+	want := []engine.Advisory{
+		{ID: "GHSA-p6mc-m468-83gw", Severity: "HIGH", Summary: "Prototype pollution"},
+		{ID: "GHSA-35jh-r3h4-6jhm", Severity: "CRITICAL", Summary: "Command injection"},
+	}
+	if !reflect.DeepEqual(update.SecurityAdvisories, want) {
+		t.Errorf("SecurityAdvisories = %+v, want %+v", update.SecurityAdvisories, want)
+	}
+
+	if len(got[1].Updates) != 0 {
+		t.Errorf("filterSecurityUpdates() kept %d precommit updates, want 0 (no advisory ecosystem)", len(got[1].Updates))
+	}
+	if len(errs) != 1 {
+		t.Errorf("filterSecurityUpdates() errors = %v, want one for the failed lookup", errs)
+	}
+	if source.calls != 4 {
+		t.Errorf("advisory lookups = %d, want 4", source.calls)
+	}
+	if len(plans[0].Updates) != 4 || plans[0].Updates[0].Security {
+		t.Error("filterSecurityUpdates() modified the input plan")
+	}
+}
+
+func TestNewAdvisorySource_RequiresToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := newAdvisorySource(); err == nil {
+		t.Error("newAdvisorySource() without GITHUB_TOKEN should return error")
+	}
+
+	t.Setenv("GITHUB_TOKEN", "test-token")
+	if _, err := newAdvisorySource(); err != nil {
+		t.Errorf("newAdvisorySource() error = %v", err)
+	}
+}
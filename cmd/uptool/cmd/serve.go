@@ -0,0 +1,260 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// maxServeRequestBytes caps the size of a request body, uploaded manifest
+// content included.
+const maxServeRequestBytes = 10 << 20
+
+// serveShutdownTimeout bounds how long in-flight requests may run after the
+// server is asked to stop.
+const serveShutdownTimeout = 10 * time.Second
+
+// serveLookupTTL bounds how long the server reuses a registry lookup across
+// requests, so new releases show up without a restart. Longer-lived reuse is
+// left to the HTTP cache, which revalidates with the registry.
+const serveLookupTTL = time.Minute
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve scan and plan results over HTTP",
+	Long: `Start an HTTP server exposing scan and plan as a JSON API, for IDE
+extensions and bots that would otherwise shell out to uptool.
+
+Endpoints:
+  POST /scan   returns the scan result, as scan --format json does
+  POST /plan   returns the plan result, as plan --format json does
+
+The request body is a JSON object naming either a repository path on the
+server's filesystem or the content of a single manifest:
+
+  {"path": "/src/app", "only": ["npm"], "exclude": []}
+  {"type": "npm", "content": "{\"dependencies\": {...}}"}
+
+Each request gets its own engine, configured from uptool.yaml and the global
+flags as the server was started, so requests may run concurrently. Registry
+lookups are shared between requests for a minute. Errors are
+returned as {"error": "..."} with a 4xx or 5xx status.
+
+The server binds to localhost by default; it can read any path the process
+can, so do not expose it on untrusted networks.`,
+	Example: `  # Serve on the default address
+  uptool serve
+
+  # Plan a repository
+  curl -s -X POST localhost:8080/plan -d '{"path": "/src/app"}'
+
+  # Scan an unsaved package.json
+  curl -s -X POST localhost:8080/scan -d '{"type": "npm", "content": "{}"}'`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "address to listen on")
+}
+
+// serveRequest is the body accepted by the scan and plan endpoints. Path and
+// Content are mutually exclusive; Type is required with Content.
+type serveRequest struct {
+	Path    string   `json:"path,omitempty"`
+	Type    string   `json:"type,omitempty"`
+	Content string   `json:"content,omitempty"`
+	Only    []string `json:"only,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// serveError is the body returned when a request fails.
+type serveError struct {
+	Error string `json:"error"`
+}
+
+// errBadServeRequest marks errors caused by the request rather than the scan.
+var errBadServeRequest = errors.New("bad request")
+
+func runServe(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(commandContext(cmd), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	datasource.SetCacheTTL(serveLookupTTL)
+
+	server := &http.Server{
+		Addr:              serveAddr,
+		Handler:           newServeHandler(setupEngine),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "Serving on http://%s\n", serveAddr)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	return nil
+}
+
+// newServeHandler returns the API handler. newEngine is called once per
+// request, so no engine state is shared between concurrent requests.
+func newServeHandler(newEngine func() *engine.Engine) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /scan", func(w http.ResponseWriter, r *http.Request) {
+		serveScanOrPlan(w, r, newEngine, false)
+	})
+	mux.HandleFunc("POST /plan", func(w http.ResponseWriter, r *http.Request) {
+		serveScanOrPlan(w, r, newEngine, true)
+	})
+	return mux
+}
+
+func serveScanOrPlan(w http.ResponseWriter, r *http.Request, newEngine func() *engine.Engine, plan bool) {
+	req, err := decodeServeRequest(w, r)
+	if err != nil {
+		writeServeJSON(w, http.StatusBadRequest, serveError{Error: err.Error()})
+		return
+	}
+
+	ctx := r.Context()
+	eng := newEngine()
+
+	scanResult, cleanup, err := serveScan(ctx, eng, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errBadServeRequest) {
+			status = http.StatusBadRequest
+		}
+		writeServeJSON(w, status, serveError{Error: err.Error()})
+		return
+	}
+	defer cleanup()
+
+	if !plan {
+		if req.Content != "" {
+			labelStdin(scanResult.Manifests, nil)
+		}
+		writeServeJSON(w, http.StatusOK, scanResult)
+		return
+	}
+
+	planResult, err := eng.PlanWithOptions(ctx, scanResult.Manifests, &engine.PlanOptions{
+		ReleaseTimestamps: releaseTimestamps(ctx, eng, scanResult.Manifests),
+	})
+	if err != nil {
+		writeServeJSON(w, http.StatusInternalServerError, serveError{Error: fmt.Sprintf("plan failed: %v", err)})
+		return
+	}
+	if req.Content != "" {
+		labelStdin(scanResult.Manifests, planResult.Plans)
+	}
+	planResult.Errors = append(append([]string{}, scanResult.Errors...), planResult.Errors...)
+
+	writeServeJSON(w, http.StatusOK, planResult)
+}
+
+// decodeServeRequest parses and validates a request body.
+func decodeServeRequest(w http.ResponseWriter, r *http.Request) (*serveRequest, error) {
+	var req serveRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxServeRequestBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return nil, fmt.Errorf("decode request: %w", err)
+	}
+
+	switch {
+	case req.Path != "" && req.Content != "":
+		return nil, fmt.Errorf("path and content are mutually exclusive")
+	case req.Content != "" && req.Type == "":
+		return nil, fmt.Errorf("type is required with content")
+	case req.Path == "" && req.Content == "":
+		return nil, fmt.Errorf("one of path or content is required")
+	}
+	return &req, nil
+}
+
+// serveScan scans the repository or uploaded manifest named by req. The
+// returned cleanup removes any temporary copy of uploaded content and must be
+// called once the result is no longer needed.
+func serveScan(ctx context.Context, eng *engine.Engine, req *serveRequest) (*engine.ScanResult, func(), error) {
+	if req.Content != "" {
+		result, cleanup, err := scanStdinManifest(ctx, eng, req.Type, strings.NewReader(req.Content))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %w", errBadServeRequest, err)
+		}
+		return result, cleanup, nil
+	}
+
+	repoRoot, err := filepath.Abs(req.Path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: resolve path: %w", errBadServeRequest, err)
+	}
+	if info, err := os.Stat(repoRoot); err != nil || !info.IsDir() {
+		return nil, nil, fmt.Errorf("%w: not a directory: %s", errBadServeRequest, req.Path)
+	}
+
+	result, err := eng.Scan(ctx, repoRoot, req.Only, req.Exclude)
+	if err != nil {
+		return nil, nil, fmt.Errorf("scan failed: %w", err)
+	}
+	return result, func() {}, nil
+}
+
+func writeServeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v) //nolint:errcheck // client may have gone away
+}
@@ -0,0 +1,203 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func newServeTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	server := httptest.NewServer(newServeHandler(setupEngine))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func postServe(t *testing.T, server *httptest.Server, endpoint, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(server.URL+endpoint, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST %s error = %v", endpoint, err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() }) //nolint:errcheck // test cleanup
+	return resp
+}
+
+func TestServe_Plan(t *testing.T) {
+	server := newServeTestServer(t)
+	dir := t.TempDir()
+
+	resp := postServe(t, server, "/plan", `{"path": "`+dir+`", "only": ["`+staticIntegrationName+`"]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+
+	var result engine.PlanResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode plan: %v", err)
+	}
+	if len(result.Plans) != 1 || len(result.Plans[0].Updates) != 1 {
+		t.Fatalf("plans = %+v, want one plan with one update", result.Plans)
+	}
+	update := result.Plans[0].Updates[0]
+	if update.Dependency.Name != "left-pad" || update.Dependency.CurrentVersion != "1.0.0" || update.TargetVersion != "1.3.0" {
+		t.Errorf("update = %+v, want left-pad 1.0.0 -> 1.3.0", update)
+	}
+}
+
+func TestServe_Scan(t *testing.T) {
+	server := newServeTestServer(t)
+	dir := t.TempDir()
+
+	resp := postServe(t, server, "/scan", `{"path": "`+dir+`", "only": ["`+staticIntegrationName+`"]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var result engine.ScanResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode scan: %v", err)
+	}
+	if len(result.Manifests) != 1 || result.Manifests[0].Type != staticIntegrationName {
+		t.Errorf("manifests = %+v, want one %s manifest", result.Manifests, staticIntegrationName)
+	}
+}
+
+func TestServe_ScanContent(t *testing.T) {
+	server := newServeTestServer(t)
+
+	body := `{"type": "npm", "content": "{\"dependencies\": {\"lodash\": \"^4.17.0\"}}"}`
+	resp := postServe(t, server, "/scan", body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	var result engine.ScanResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode scan: %v", err)
+	}
+	if len(result.Manifests) != 1 {
+		t.Fatalf("manifests = %+v, want one", result.Manifests)
+	}
+	m := result.Manifests[0]
+	if m.Path != stdinPath {
+		t.Errorf("Path = %q, want %q", m.Path, stdinPath)
+	}
+	if len(m.Dependencies) != 1 || m.Dependencies[0].Name != "lodash" {
+		t.Errorf("dependencies = %+v, want lodash", m.Dependencies)
+	}
+}
+
+func TestServe_BadRequests(t *testing.T) {
+	server := newServeTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "invalid json", body: `{`, want: "decode request"},
+		{name: "unknown field", body: `{"repo": "."}`, want: "unknown field"},
+		{name: "empty", body: `{}`, want: "one of path or content is required"},
+		{name: "path and content", body: `{"path": ".", "type": "npm", "content": "{}"}`, want: "mutually exclusive"},
+		{name: "content without type", body: `{"content": "{}"}`, want: "type is required"},
+		{name: "unsupported type", body: `{"type": "bazel", "content": "x"}`, want: "unsupported"},
+		{name: "missing path", body: `{"path": "does-not-exist"}`, want: "not a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := postServe(t, server, "/plan", tt.body)
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+			var got serveError
+			if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+				t.Fatalf("decode error body: %v", err)
+			}
+			if !strings.Contains(got.Error, tt.want) {
+				t.Errorf("error = %q, want it to contain %q", got.Error, tt.want)
+			}
+		})
+	}
+}
+
+func TestServe_MethodNotAllowed(t *testing.T) {
+	server := newServeTestServer(t)
+
+	resp, err := http.Get(server.URL + "/plan")
+	if err != nil {
+		t.Fatalf("GET /plan error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // test cleanup
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestServe_ConcurrentRequests(t *testing.T) {
+	server := newServeTestServer(t)
+	dir := t.TempDir()
+	body := `{"path": "` + dir + `", "only": ["` + staticIntegrationName + `"]}`
+
+	var wg sync.WaitGroup
+	errs := make(chan string, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.Post(server.URL+"/plan", "application/json", strings.NewReader(body))
+			if err != nil {
+				errs <- err.Error()
+				return
+			}
+			defer func() { _ = resp.Body.Close() }() //nolint:errcheck // test cleanup
+
+			var result engine.PlanResult
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				errs <- err.Error()
+				return
+			}
+			if resp.StatusCode != http.StatusOK || len(result.Plans) != 1 {
+				errs <- "unexpected plan response"
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
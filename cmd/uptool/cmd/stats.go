@@ -0,0 +1,71 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// printNetworkStats writes the per-client HTTP request counts and per-datasource
+// cache hit/miss counts gathered during the run.
+func printNetworkStats(w io.Writer, network map[string]registry.ClientStats, cache map[string]datasource.CacheStats) {
+	fmt.Fprintln(w, "\nNetwork summary:")
+
+	if len(network) == 0 {
+		fmt.Fprintln(w, "  No HTTP requests made.")
+	} else {
+		fmt.Fprintf(w, "  %-20s %10s %8s %12s\n", "Client", "Requests", "Errors", "Time")
+
+		var totalRequests int
+		var totalTime time.Duration
+		for _, name := range sortedKeys(network) {
+			s := network[name]
+			fmt.Fprintf(w, "  %-20s %10d %8d %12s\n", name, s.Requests, s.Errors, s.Duration.Round(time.Millisecond))
+			totalRequests += s.Requests
+			totalTime += s.Duration
+		}
+		fmt.Fprintf(w, "  Total: %d requests, %s network time\n", totalRequests, totalTime.Round(time.Millisecond))
+	}
+
+	if len(cache) > 0 {
+		fmt.Fprintf(w, "\n  %-20s %10s %8s\n", "Cache", "Hits", "Misses")
+		for _, name := range sortedKeys(cache) {
+			s := cache[name]
+			fmt.Fprintf(w, "  %-20s %10d %8d\n", name, s.Hits, s.Misses)
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func TestPrintNetworkStats(t *testing.T) {
+	var buf bytes.Buffer
+	printNetworkStats(&buf,
+		map[string]registry.ClientStats{
+			"npm":    {Requests: 3, Errors: 1, Duration: 1500 * time.Millisecond},
+			"github": {Requests: 2, Duration: 500 * time.Millisecond},
+		},
+		map[string]datasource.CacheStats{
+			"npm": {Hits: 7, Misses: 3},
+		},
+	)
+
+	out := buf.String()
+	for _, want := range []string{
+		"Total: 5 requests, 2s network time",
+		"npm                           3        1         1.5s",
+		"npm                           7        3",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "github") > strings.Index(out, "npm") {
+		t.Errorf("clients should be sorted by name:\n%s", out)
+	}
+
+	buf.Reset()
+	printNetworkStats(&buf, nil, nil)
+	if !strings.Contains(buf.String(), "No HTTP requests made.") {
+		t.Errorf("empty stats output = %q", buf.String())
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// stdinPath labels a manifest read from standard input in output.
+const stdinPath = "<stdin>"
+
+// stdinFileNames maps an integration to a file name its Detect recognizes, so
+// content piped with --stdin-type is parsed by the integration itself.
+var stdinFileNames = map[string]string{
+	"actions":      filepath.Join(".github", "workflows", "stdin.yml"),
+	"ansible":      "requirements.yml",
+	"asdf":         ".tool-versions",
+	"bundler":      "Gemfile",
+	"cargo":        "Cargo.toml",
+	"cocoapods":    "Podfile",
+	"composer":     "composer.json",
+	"devcontainer": filepath.Join(".devcontainer", "devcontainer.json"),
+	"docker":       "Dockerfile",
+	"gitlabci":     ".gitlab-ci.yml",
+	"gomod":        "go.mod",
+	"gradle":       filepath.Join("gradle", "libs.versions.toml"),
+	"helm":         "Chart.yaml",
+	"maven":        "pom.xml",
+	"mise":         "mise.toml",
+	"mix":          "mix.exs",
+	"nix":          "flake.lock",
+	"npm":          "package.json",
+	"nuget":        "stdin.csproj",
+	"precommit":    ".pre-commit-config.yaml",
+	"swift":        "Package.swift",
+	"terraform":    "main.tf",
+	"tflint":       ".tflint.hcl",
+}
+
+// stdinTypes returns the integrations accepted by --stdin-type.
+func stdinTypes() []string {
+	types := make([]string, 0, len(stdinFileNames))
+	for name := range stdinFileNames {
+		types = append(types, name)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// completeStdinTypes provides shell completion for --stdin-type.
+func completeStdinTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return stdinTypes(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// scanStdinManifest detects a single manifest of the given integration from r, for
+// content that is not on disk (e.g., an unsaved editor buffer). The content is
+// written to a temporary directory under the integration's file name; the
+// returned manifests point into it until cleanup is called, so Plan can read
+// them. Call labelStdin before printing results.
+func scanStdinManifest(ctx context.Context, eng *engine.Engine, integrationName string, r io.Reader) (*engine.ScanResult, func(), error) {
+	fileName, ok := stdinFileNames[integrationName]
+	if !ok {
+		return nil, nil, fmt.Errorf("unsupported --stdin-type %q (must be one of: %s)", integrationName, strings.Join(stdinTypes(), ", "))
+	}
+
+	integ, ok := eng.GetIntegration(integrationName)
+	if !ok {
+		return nil, nil, fmt.Errorf("integration %q is not enabled", integrationName)
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read stdin: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "uptool-stdin-*")
+	if err != nil {
+		return nil, nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(dir) } //nolint:errcheck // cleanup best effort
+
+	path := filepath.Join(dir, fileName)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	if err := os.WriteFile(path, content, 0o600); err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("write stdin manifest: %w", err)
+	}
+
+	manifests, err := integ.Detect(ctx, dir)
+	if err != nil {
+		cleanup()
+		return nil, nil, fmt.Errorf("parse stdin as %s: %w", integrationName, err)
+	}
+	if len(manifests) == 0 {
+		cleanup()
+		return nil, nil, fmt.Errorf("no %s manifest found on stdin", integrationName)
+	}
+
+	for _, m := range manifests {
+		if !filepath.IsAbs(m.Path) {
+			m.Path = filepath.Join(dir, m.Path)
+		}
+	}
+
+	return &engine.ScanResult{
+		Manifests: manifests,
+		RepoRoot:  stdinPath,
+	}, cleanup, nil
+}
+
+// labelStdin replaces the temporary paths of manifests read from stdin, and of
+// the plans made for them, with stdinPath.
+func labelStdin(manifests []*engine.Manifest, plans []*engine.UpdatePlan) {
+	for _, m := range manifests {
+		m.Path = stdinPath
+	}
+	for _, p := range plans {
+		p.Manifest.Path = stdinPath
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations/npm"
+)
+
+const stdinPackageJSON = `{
+  "name": "unsaved",
+  "dependencies": {
+    "express": "4.17.0",
+    "lodash": "4.17.21"
+  }
+}
+`
+
+// offlineNPM parses package.json with the real npm integration but plans
+// against a fixed set of latest versions instead of the npm registry.
+type offlineNPM struct {
+	*npm.Integration
+	latest map[string]string
+}
+
+func (o *offlineNPM) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	plan := &engine.UpdatePlan{Manifest: manifest, Strategy: "json_rewrite"}
+	for _, dep := range manifest.Dependencies {
+		if latest, ok := o.latest[dep.Name]; ok && latest != dep.CurrentVersion {
+			plan.Updates = append(plan.Updates, engine.Update{Dependency: dep, TargetVersion: latest, Impact: "minor"})
+		}
+	}
+	return plan, nil
+}
+
+func TestScanStdinManifest(t *testing.T) {
+	orig := colorEnabled
+	colorEnabled = false
+	defer func() { colorEnabled = orig }()
+
+	ctx := context.Background()
+	eng := engine.NewEngine(nil)
+	eng.Register(&offlineNPM{
+		Integration: npm.New(),
+		latest:      map[string]string{"express": "4.18.2", "lodash": "4.17.21"},
+	})
+
+	scanResult, cleanup, err := scanStdinManifest(ctx, eng, "npm", strings.NewReader(stdinPackageJSON))
+	if err != nil {
+		t.Fatalf("scanStdinManifest() error = %v", err)
+	}
+	defer cleanup()
+
+	if len(scanResult.Manifests) != 1 {
+		t.Fatalf("scanStdinManifest() returned %d manifests, want 1", len(scanResult.Manifests))
+	}
+	if got := string(scanResult.Manifests[0].Content); got != stdinPackageJSON {
+		t.Errorf("manifest content = %q, want the piped body", got)
+	}
+
+	planResult, err := eng.PlanWithOptions(ctx, scanResult.Manifests, &engine.PlanOptions{})
+	if err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+	labelStdin(scanResult.Manifests, planResult.Plans)
+
+	if len(planResult.Plans) != 1 {
+		t.Fatalf("PlanWithOptions() returned %d plans, want 1", len(planResult.Plans))
+	}
+	plan := planResult.Plans[0]
+	if len(plan.Updates) != 1 || plan.Updates[0].Dependency.Name != "express" || plan.Updates[0].Dependency.CurrentVersion != "4.17.0" {
+		t.Fatalf("plan updates = %+v, want express from the piped 4.17.0", plan.Updates)
+	}
+
+	out := captureStdout(t, func() {
+		if err := outputPlanTable(planResult); err != nil {
+			t.Fatalf("outputPlanTable() error = %v", err)
+		}
+	})
+	if !strings.Contains(out, stdinPath) {
+		t.Errorf("plan output should label the manifest %s:\n%s", stdinPath, out)
+	}
+	if !strings.Contains(out, "4.18.2") {
+		t.Errorf("plan output missing express update:\n%s", out)
+	}
+}
+
+func TestScanStdinManifest_Errors(t *testing.T) {
+	ctx := context.Background()
+	eng := engine.NewEngine(nil)
+	eng.Register(npm.New())
+
+	tests := []struct {
+		name      string
+		stdinType string
+		body      string
+		wantErr   string
+	}{
+		{name: "unsupported type", stdinType: "bazel", body: "module(name = \"app\")\n", wantErr: "unsupported --stdin-type"},
+		{name: "integration not enabled", stdinType: "helm", body: "apiVersion: v2\n", wantErr: "not enabled"},
+		{name: "unparsable content", stdinType: "npm", body: "{", wantErr: "parse stdin as npm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := scanStdinManifest(ctx, eng, tt.stdinType, strings.NewReader(tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("scanStdinManifest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// templateUpdate is a planned update together with the manifest it belongs to,
+// as exposed to --format=template by the updates and groupBy* helpers.
+type templateUpdate struct {
+	Manifest *engine.Manifest
+	engine.Update
+}
+
+// planTemplateFuncs are the helper functions available to plan templates.
+var planTemplateFuncs = template.FuncMap{
+	"impactEmoji":   impactEmoji,
+	"updates":       templateUpdates,
+	"groupByImpact": groupUpdatesByImpact,
+	"groupByType":   groupUpdatesByType,
+	"join":          strings.Join,
+	"lower":         strings.ToLower,
+	"upper":         strings.ToUpper,
+}
+
+// loadPlanTemplate parses a user-supplied text/template for --format=template.
+func loadPlanTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return nil, fmt.Errorf("--format template requires --template-file")
+	}
+
+	content, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("read template: %w", err)
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Funcs(planTemplateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// renderPlanTemplate executes tmpl against the plan result.
+func renderPlanTemplate(w io.Writer, tmpl *template.Template, result *engine.PlanResult) error {
+	if err := tmpl.Execute(w, result); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return nil
+}
+
+// impactEmoji returns an emoji for an update impact level.
+func impactEmoji(impact string) string {
+	switch impact {
+	case string(engine.ImpactMajor):
+		return "🔴"
+	case string(engine.ImpactMinor):
+		return "🟡"
+	case string(engine.ImpactPatch):
+		return "🟢"
+	default:
+		return "⚪"
+	}
+}
+
+// templateUpdates flattens all planned updates, in plan order.
+func templateUpdates(result *engine.PlanResult) []templateUpdate {
+	var updates []templateUpdate
+	for _, plan := range result.Plans {
+		for i := range plan.Updates {
+			updates = append(updates, templateUpdate{Manifest: plan.Manifest, Update: plan.Updates[i]})
+		}
+	}
+	return updates
+}
+
+// groupUpdatesByImpact groups planned updates by impact level.
+// Templates ranging over the result visit groups in sorted key order.
+func groupUpdatesByImpact(result *engine.PlanResult) map[string][]templateUpdate {
+	groups := make(map[string][]templateUpdate)
+	for _, u := range templateUpdates(result) {
+		groups[u.Impact] = append(groups[u.Impact], u)
+	}
+	return groups
+}
+
+// groupUpdatesByType groups planned updates by integration (manifest type).
+func groupUpdatesByType(result *engine.PlanResult) map[string][]templateUpdate {
+	groups := make(map[string][]templateUpdate)
+	for _, u := range templateUpdates(result) {
+		groups[u.Manifest.Type] = append(groups[u.Manifest.Type], u)
+	}
+	return groups
+}
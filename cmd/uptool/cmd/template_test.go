@@ -0,0 +1,110 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRenderPlanTemplate(t *testing.T) {
+	path := writeTemplate(t, `{{ range updates . }}{{ impactEmoji .Impact }} {{ .Manifest.Path }} {{ .Dependency.Name }} {{ .Dependency.CurrentVersion }} -> {{ .TargetVersion }}
+{{ end }}`)
+
+	tmpl, err := loadPlanTemplate(path)
+	if err != nil {
+		t.Fatalf("loadPlanTemplate() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderPlanTemplate(&buf, tmpl, inventoryPlanResult()); err != nil {
+		t.Fatalf("renderPlanTemplate() error = %v", err)
+	}
+
+	want := "🟡 package.json express 4.17.0 -> 4.18.2\n"
+	if buf.String() != want {
+		t.Errorf("renderPlanTemplate() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestRenderPlanTemplate_Grouping(t *testing.T) {
+	path := writeTemplate(t, `{{ range $impact, $updates := groupByImpact . }}{{ upper $impact }}={{ len $updates }};{{ end }}`)
+
+	tmpl, err := loadPlanTemplate(path)
+	if err != nil {
+		t.Fatalf("loadPlanTemplate() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := renderPlanTemplate(&buf, tmpl, inventoryPlanResult()); err != nil {
+		t.Fatalf("renderPlanTemplate() error = %v", err)
+	}
+	if buf.String() != "MINOR=1;" {
+		t.Errorf("renderPlanTemplate() = %q, want %q", buf.String(), "MINOR=1;")
+	}
+}
+
+func TestLoadPlanTemplate_Errors(t *testing.T) {
+	if _, err := loadPlanTemplate(""); err == nil || !strings.Contains(err.Error(), "--template-file") {
+		t.Errorf("loadPlanTemplate(\"\") error = %v, want missing --template-file", err)
+	}
+	if _, err := loadPlanTemplate(writeTemplate(t, "{{ .Plans ")); err == nil {
+		t.Error("loadPlanTemplate() should fail on invalid template syntax")
+	}
+}
+
+func TestExamplePlanTemplates(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("..", "..", "..", "examples", "templates", "*.tmpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("no example templates found")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			tmpl, err := loadPlanTemplate(path)
+			if err != nil {
+				t.Fatalf("loadPlanTemplate() error = %v", err)
+			}
+			var buf bytes.Buffer
+			if err := renderPlanTemplate(&buf, tmpl, inventoryPlanResult()); err != nil {
+				t.Fatalf("renderPlanTemplate() error = %v", err)
+			}
+			if !strings.Contains(buf.String(), "express") {
+				t.Errorf("rendered output missing express update:\n%s", buf.String())
+			}
+		})
+	}
+}
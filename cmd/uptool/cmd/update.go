@@ -21,18 +21,41 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/history"
+	"github.com/santosr2/uptool/internal/pullrequest"
 )
 
 var (
-	updateDryRun  bool
-	updateDiff    bool
-	updateOnly    string
-	updateExclude string
+	updateDryRun         bool
+	updateDiff           bool
+	updateOnly           string
+	updateExclude        string
+	updateOnlyDependency string
+	updateOnlyGroup      string
+	updateResume         string
+	updateRecordHistory  string
+	updateOnConflict     string
+	updatePrerelease     string
+	updateLockfileOnly   bool
+	updateNoLockfile     bool
+	updateFixLockfile    bool
+	updateCreatePR       bool
+	updateBatch          bool
+	updateFetchInfo      bool
+	updateOnlySecurity   bool
+	updateApplyOverrides bool
+	updateTrackedOnly    bool
+	updatePRTitle        string
+	updatePRBranch       string
+	updateLookupTimeout  time.Duration
+	updateDueOnly        bool
 )
 
 var updateCmd = &cobra.Command{
@@ -42,7 +65,15 @@ var updateCmd = &cobra.Command{
 
 This command scans for manifests, generates an update plan, and applies
 the updates by rewriting manifest files with new dependency versions.
-Formatting and structure are preserved.`,
+Formatting and structure are preserved.
+
+Manifests and the lockfiles they write are compared with their content
+at scan time. By default (--on-conflict=skip) a manifest edited after the
+scan, or a lockfile that changed, is left untouched and its updates are
+reported as failed; use --on-conflict=overwrite or merge to apply them.
+
+With --record-history, applied updates are appended to a history log
+(.uptool/history.jsonl by default); see 'uptool history'.`,
 	Example: `  # Update all dependencies
   uptool update
 
@@ -56,7 +87,31 @@ Formatting and structure are preserved.`,
   uptool update --only npm
 
   # Update everything except terraform
-  uptool update --exclude terraform`,
+  uptool update --exclude terraform
+
+  # Apply only the updates of one dependency group
+  uptool update --only-group security-deps
+
+  # Merge updates with files edited since the scan
+  uptool update --on-conflict merge
+
+  # Refresh lockfiles without touching manifests
+  uptool update --lockfile-only
+
+  # Let nix re-lock flake inputs instead of bumping revs directly
+  uptool update --only nix --fix-lockfile
+
+  # Advance git submodules and stage their new commits
+  uptool update --only gitsubmodule --fix-lockfile
+
+  # Regenerate Chart.lock with helm after bumping chart dependencies
+  uptool update --only helm --fix-lockfile
+
+  # Force patched versions of vulnerable transitive npm packages
+  uptool update --only npm --apply-overrides
+
+  # Open a single pull request with every update
+  uptool update --create-pr --batch`,
 	RunE: runUpdate,
 }
 
@@ -65,23 +120,90 @@ func init() {
 
 	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "show changes without applying")
 	updateCmd.Flags().BoolVar(&updateDiff, "diff", false, "show diffs of changes")
+	updateCmd.Flags().DurationVar(&updateLookupTimeout, "lookup-timeout", engine.DefaultLookupTimeout, "per-dependency registry lookup timeout; slower lookups are reported as unchecked")
 	updateCmd.Flags().StringVar(&updateOnly, "only", "", "comma-separated integrations to include")
 	updateCmd.Flags().StringVar(&updateExclude, "exclude", "", "comma-separated integrations to exclude")
+	updateCmd.Flags().BoolVar(&updateTrackedOnly, "tracked-only", false, "skip manifests git does not track (all are scanned outside a git repository)")
+	updateCmd.Flags().StringVar(&updateOnlyDependency, "only-dependency", "", "comma-separated dependency names or globs to include")
+	updateCmd.Flags().StringVar(&updateResume, "resume", "", "record plan progress and skip manifests finished by an interrupted run (default path: "+engine.DefaultResumeStatePath+")")
+	updateCmd.Flags().Lookup("resume").NoOptDefVal = engine.DefaultResumeStatePath
+	updateCmd.Flags().StringVar(&updateRecordHistory, "record-history", "", "append applied updates to a history log read by 'uptool history' (default path: "+history.DefaultPath+")")
+	updateCmd.Flags().Lookup("record-history").NoOptDefVal = history.DefaultPath
+	updateCmd.Flags().BoolVar(&updateDueOnly, "due-only", false, "skip integrations whose policy cadence or schedule is not yet due, recording the run time of those processed")
+	updateCmd.Flags().BoolVar(&updateOnlySecurity, "only-security", false, "apply only updates that fix a GitHub security advisory (needs GITHUB_TOKEN)")
+	updateCmd.Flags().BoolVar(&updateApplyOverrides, "apply-overrides", false, "add npm overrides pinning vulnerable transitive packages in package-lock.json to their patched versions (needs GITHUB_TOKEN)")
+	updateCmd.Flags().StringVar(&updateOnlyGroup, "only-group", "", "apply only updates in this dependency group (groups in uptool.yaml)")
+	updateCmd.Flags().StringVar(&updatePrerelease, "prerelease-channel", "", "comma-separated prerelease channels to consider (e.g. rc), overriding prerelease_channels")
+	updateCmd.Flags().StringVar(&updateOnConflict, "on-conflict", string(engine.ConflictSkip), "when a manifest or the lockfile it writes changed since scan or has conflict markers: skip (default; leaves both files untouched), overwrite, merge")
+	updateCmd.Flags().BoolVar(&updateLockfileOnly, "lockfile-only", false, "update lockfiles but not manifests, overriding versioning_strategy")
+	updateCmd.Flags().BoolVar(&updateNoLockfile, "no-lockfile", false, "update manifests but leave lockfiles untouched, overriding versioning_strategy")
+	updateCmd.MarkFlagsMutuallyExclusive("lockfile-only", "no-lockfile")
+	updateCmd.Flags().BoolVar(&updateFixLockfile, "fix-lockfile", false, "regenerate lockfiles with the native tool where supported (e.g. nix flake lock, submodule gitlinks, helm Chart.lock)")
+	updateCmd.Flags().BoolVar(&updateCreatePR, "create-pr", false, "open pull requests for applied updates, one per group or manifest (needs GITHUB_TOKEN and GITHUB_REPOSITORY)")
+	updateCmd.Flags().BoolVar(&updateBatch, "batch", false, "with --create-pr, combine every applied update into a single pull request")
+	updateCmd.Flags().StringVar(&updatePRTitle, "pr-title", pullrequest.DefaultTitle, "pull request title for --create-pr")
+	updateCmd.Flags().StringVar(&updatePRBranch, "pr-branch", pullrequest.DefaultBranch, "pull request branch for --create-pr")
+	updateCmd.Flags().BoolVar(&updateFetchInfo, "fetch-info", false, "look up release times and GitHub release notes; adds an Age column to --create-pr descriptions (cargo, go, npm)")
 
 	// Add shell completion for flags
-	_ = updateCmd.RegisterFlagCompletionFunc("only", completeIntegrations)    //nolint:errcheck // best effort completion
-	_ = updateCmd.RegisterFlagCompletionFunc("exclude", completeIntegrations) //nolint:errcheck // best effort completion
+	_ = updateCmd.RegisterFlagCompletionFunc("only", completeIntegrations)            //nolint:errcheck // best effort completion
+	_ = updateCmd.RegisterFlagCompletionFunc("exclude", completeIntegrations)         //nolint:errcheck // best effort completion
+	_ = updateCmd.RegisterFlagCompletionFunc("on-conflict", completeConflictPolicies) //nolint:errcheck // best effort completion
+	_ = updateCmd.RegisterFlagCompletionFunc("only-dependency", completeDependencies) //nolint:errcheck // best effort completion
+}
+
+// completeConflictPolicies provides shell completion for --on-conflict.
+func completeConflictPolicies(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{
+		string(engine.ConflictSkip),
+		string(engine.ConflictOverwrite),
+		string(engine.ConflictMerge),
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// lockfileMode maps the --lockfile-only and --no-lockfile flags to a lockfile mode.
+func lockfileMode(lockfileOnly, noLockfile bool) engine.LockfileMode {
+	switch {
+	case lockfileOnly:
+		return engine.LockfileOnly
+	case noLockfile:
+		return engine.LockfileSkip
+	default:
+		return engine.LockfileDefault
+	}
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
+	conflictPolicy, err := engine.ParseConflictPolicy(updateOnConflict)
+	if err != nil {
+		return err
+	}
+	if updateBatch && !updateCreatePR {
+		return fmt.Errorf("--batch requires --create-pr")
+	}
+
 	eng := setupEngine()
-	ctx := context.Background()
+	eng.SetConflictPolicy(conflictPolicy)
+	eng.SetLockfileMode(lockfileMode(updateLockfileOnly, updateNoLockfile))
+	eng.SetFixLockfile(updateFixLockfile)
+	eng.SetTrackedOnly(updateTrackedOnly)
+	if flags := prereleaseCLIFlags(updatePrerelease); flags != nil {
+		eng.SetCLIFlags(flags)
+	}
+	ctx := commandContext(cmd)
 
 	repoRoot, err := os.Getwd()
 	if err != nil {
 		return fmt.Errorf("get working directory: %w", err)
 	}
 
+	var advisories advisorySource
+	if updateOnlySecurity || updateApplyOverrides {
+		if advisories, err = newAdvisorySource(); err != nil {
+			return err
+		}
+	}
+
 	onlyList, excludeList := parseFilters(updateOnly, updateExclude)
 
 	// Scan
@@ -90,20 +212,80 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("scan failed: %w", err)
 	}
 
+	// --due-only skips integrations whose cadence or schedule has not come round
+	var recordRun func()
+	if updateDueOnly {
+		state, statePath, err := loadDueState()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		var skipped []string
+		scanResult.Manifests, skipped = filterDueManifests(eng, state, scanResult.Manifests, now)
+		reportSkippedIntegrations(skipped)
+		if !updateDryRun {
+			manifests := scanResult.Manifests
+			recordRun = func() {
+				if err := recordDueRuns(state, statePath, manifests, now); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				}
+			}
+		}
+	}
+
 	if len(scanResult.Manifests) == 0 {
 		fmt.Println("No manifests found.")
-		return nil
+		return checkRunErrors(scanResult.Errors)
+	}
+
+	var overrides []manifestOverrides
+	if updateApplyOverrides {
+		var overrideErrors []string
+		overrides, overrideErrors = suggestManifestOverrides(ctx, advisories, repoRoot, scanResult.Manifests)
+		scanResult.Errors = append(scanResult.Errors, overrideErrors...)
+	}
+
+	resume, err := loadResumeState(repoRoot, updateResume)
+	if err != nil {
+		return err
+	}
+
+	timestamps := releaseTimestamps(ctx, eng, scanResult.Manifests)
+	if updateFetchInfo {
+		timestamps = allReleaseTimestamps(ctx, scanResult.Manifests)
 	}
 
 	// Plan
-	planResult, err := eng.Plan(ctx, scanResult.Manifests)
+	planResult, err := eng.PlanWithOptions(ctx, scanResult.Manifests, &engine.PlanOptions{
+		ReleaseTimestamps: timestamps,
+		LookupTimeout:     updateLookupTimeout,
+		OnlyGroup:         updateOnlyGroup,
+		Resume:            resume,
+	})
 	if err != nil {
 		return fmt.Errorf("plan failed: %w", err)
 	}
+	finishResume(resume, planResult.Errors)
+	depList, _ := parseFilters(updateOnlyDependency, "")
+	planResult.Plans = filterPlansByDependency(planResult.Plans, depList)
+	if updateOnlySecurity {
+		var advisoryErrors []string
+		planResult.Plans, advisoryErrors = filterSecurityUpdates(ctx, advisories, planResult.Plans)
+		planResult.Errors = append(planResult.Errors, advisoryErrors...)
+	}
+	if updateFetchInfo {
+		setUpdateAges(planResult.Plans, timestamps, time.Now())
+		setUpdateInfo(ctx, newReleaseInfoSource(), planResult.Plans, dependencyRepository)
+		setCompatibilityScores(planResult.Plans, time.Now())
+	}
 
 	if len(planResult.Plans) == 0 {
+		if recordRun != nil {
+			recordRun()
+		}
 		fmt.Println("No updates available.")
-		return nil
+		overrideErrors := applyManifestOverrides(repoRoot, overrides, updateDryRun)
+		return checkRunErrors(scanResult.Errors, planResult.Errors, overrideErrors)
 	}
 
 	// Show plan
@@ -114,8 +296,9 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	}
 
 	if updateDryRun {
+		applyManifestOverrides(repoRoot, overrides, true)
 		fmt.Println("\nDry-run mode: no changes applied.")
-		return nil
+		return checkRunErrors(scanResult.Errors, planResult.Errors)
 	}
 
 	// Apply
@@ -124,20 +307,50 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("update failed: %w", err)
 	}
+	if recordRun != nil {
+		recordRun()
+	}
+
+	// Record applied updates; a failure here must not hide the results below
+	if updateRecordHistory != "" {
+		historyPath := resolveHistoryPath(repoRoot, updateRecordHistory)
+		if err := history.Append(historyPath, appliedHistoryEntries(eng, planResult.Plans, updateResult)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record update history: %v\n", err)
+		}
+	}
 
 	// Show results
+	applyErrors := make([]string, 0)
 	fmt.Println("\n=== Update Results ===")
 	for _, result := range updateResult.Results {
 		fmt.Printf("\n%s:\n", result.Manifest.Path)
 		fmt.Printf("  Applied: %d\n", result.Applied)
 		if result.Failed > 0 {
-			fmt.Printf("  Failed: %d\n", result.Failed)
+			fmt.Printf("  Failed: %s\n", colorize(ansiRed, fmt.Sprint(result.Failed)))
+		}
+		for _, e := range result.Errors {
+			if !quietErrors {
+				fmt.Printf("    - %s\n", e)
+			}
+			applyErrors = append(applyErrors, fmt.Sprintf("%s: %s", result.Manifest.Path, e))
 		}
 
 		if updateDiff && result.ManifestDiff != "" {
-			fmt.Printf("\nDiff:\n%s\n", result.ManifestDiff)
+			fmt.Printf("\nDiff:\n%s\n", colorizeDiff(result.ManifestDiff))
+		}
+		if updateDiff && result.LockfileDiff != "" {
+			fmt.Printf("\nLockfile diff:\n%s\n", colorizeDiff(result.LockfileDiff))
+		}
+	}
+
+	// Overrides go in after the updates so they edit the rewritten package.json
+	overrideErrors := applyManifestOverrides(repoRoot, overrides, false)
+
+	if updateCreatePR {
+		if err := publishPullRequests(ctx, planResult.Plans, updateResult, updateBatch, updatePRBranch, updatePRTitle); err != nil {
+			return err
 		}
 	}
 
-	return nil
+	return checkRunErrors(scanResult.Errors, planResult.Errors, updateResult.Errors, applyErrors, overrideErrors)
 }
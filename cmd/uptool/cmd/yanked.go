@@ -0,0 +1,154 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// yankDatasources maps integrations to the datasource that reports which
+// versions of their dependencies were withdrawn: crates.io yanks and npm
+// unpublishes.
+var yankDatasources = map[string]string{
+	"cargo": "crates",
+	"npm":   "npm",
+}
+
+// yankSources returns the registered datasources of yankDatasources, keyed
+// by integration.
+func yankSources() map[string]datasource.Datasource {
+	sources := make(map[string]datasource.Datasource, len(yankDatasources))
+	for integration, name := range yankDatasources {
+		if ds, err := datasource.Get(name); err == nil {
+			sources[integration] = ds
+		}
+	}
+	return sources
+}
+
+// flagYankedVersions records on each plan the dependencies whose current
+// version was withdrawn from their registry, with the nearest safe version.
+// Range constraints such as "^1.2.3" are checked at their lower bound.
+// Lookup failures are returned as errors.
+func flagYankedVersions(ctx context.Context, sources map[string]datasource.Datasource, plans []*engine.UpdatePlan) []string {
+	type lookup struct {
+		info *datasource.PackageInfo
+		err  error
+	}
+	cache := make(map[string]lookup)
+	var errs []string
+
+	for _, plan := range plans {
+		ds, ok := sources[plan.Manifest.Type]
+		if !ok {
+			continue
+		}
+
+		for _, dep := range plan.Manifest.Dependencies {
+			current, err := advisoryVersion(dep.CurrentVersion)
+			if err != nil {
+				continue
+			}
+
+			key := ds.Name() + "\x00" + dep.Name
+			result, seen := cache[key]
+			if !seen {
+				result.info, result.err = ds.GetPackageInfo(ctx, dep.Name)
+				cache[key] = result
+				if result.err != nil {
+					errs = append(errs, fmt.Sprintf("%s: check yanked %s: %v", plan.Manifest.Path, dep.Name, result.err))
+				}
+			}
+			if result.err != nil || result.info == nil {
+				continue
+			}
+
+			if safe, yanked := yankedVersion(result.info.Versions, current); yanked {
+				plan.Yanked = append(plan.Yanked, engine.YankedDependency{Dependency: dep, SafeVersion: safe})
+			}
+		}
+	}
+	return errs
+}
+
+// yankedVersion reports whether current is a withdrawn version and returns
+// the nearest safe version: the lowest stable release above current that was
+// not withdrawn, else the highest one below it.
+func yankedVersion(versions []datasource.VersionInfo, current *semver.Version) (string, bool) {
+	yanked := false
+	var safe []*semver.Version
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		if parsed.Equal(current) {
+			yanked = yanked || v.Yanked
+			continue
+		}
+		if !v.Yanked && parsed.Prerelease() == "" {
+			safe = append(safe, parsed)
+		}
+	}
+	if !yanked {
+		return "", false
+	}
+
+	sort.Slice(safe, func(i, j int) bool { return safe[i].LessThan(safe[j]) })
+	for _, v := range safe {
+		if v.GreaterThan(current) {
+			return v.Original(), true
+		}
+	}
+	if len(safe) > 0 {
+		return safe[len(safe)-1].Original(), true
+	}
+	return "", true
+}
+
+// printYanked lists dependencies whose current version was withdrawn.
+func printYanked(plans []*engine.UpdatePlan) {
+	var lines []string
+	for _, plan := range plans {
+		for _, y := range plan.Yanked {
+			safe := "no safe version available"
+			if y.SafeVersion != "" {
+				safe = "nearest safe version " + y.SafeVersion
+			}
+			lines = append(lines, fmt.Sprintf("  - %s: %s %s (%s)", plan.Manifest.Path, y.Dependency.Name, y.Dependency.CurrentVersion, safe))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	fmt.Printf("\n%s\n", colorize(ansiRed, "Yanked current versions:"))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// yankDatasource serves package info with the given versions withdrawn.
+type yankDatasource struct {
+	versions map[string][]datasource.VersionInfo
+}
+
+func (d *yankDatasource) Name() string { return "test-yank" }
+
+func (d *yankDatasource) GetLatestVersion(context.Context, string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (d *yankDatasource) GetVersions(context.Context, string) ([]string, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (d *yankDatasource) GetPackageInfo(_ context.Context, pkg string) (*datasource.PackageInfo, error) {
+	if pkg == "broken" {
+		return nil, errors.New("registry unreachable")
+	}
+	return &datasource.PackageInfo{Name: pkg, Versions: d.versions[pkg]}, nil
+}
+
+func TestFlagYankedVersions(t *testing.T) {
+	ds := &yankDatasource{versions: map[string][]datasource.VersionInfo{
+		"left-pad": {
+			{Version: "1.0.0"},
+			{Version: "1.0.1", Yanked: true},
+			{Version: "1.0.2", Yanked: true},
+			{Version: "1.1.0-rc.1"},
+			{Version: "1.1.0"},
+		},
+		"express": {{Version: "4.18.0"}, {Version: "4.21.0"}},
+		"is-odd":  {{Version: "2.0.0"}, {Version: "3.0.0", Yanked: true}},
+	}}
+
+	express := engine.Dependency{Name: "express", CurrentVersion: "^4.18.0"}
+	leftPad := engine.Dependency{Name: "left-pad", CurrentVersion: "1.0.1"}
+	isOdd := engine.Dependency{Name: "is-odd", CurrentVersion: "3.0.0"}
+	plans := []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{
+				Path:         "package.json",
+				Type:         "npm",
+				Dependencies: []engine.Dependency{express, leftPad, isOdd, {Name: "broken", CurrentVersion: "1.0.0"}},
+			},
+			Updates: []engine.Update{{Dependency: express, TargetVersion: "4.21.0", Impact: "minor"}},
+		},
+		{
+			Manifest: &engine.Manifest{
+				Path:         ".pre-commit-config.yaml",
+				Type:         "precommit",
+				Dependencies: []engine.Dependency{leftPad},
+			},
+		},
+	}
+
+	errs := flagYankedVersions(context.Background(), map[string]datasource.Datasource{"npm": ds}, plans)
+
+	want := []engine.YankedDependency{
+		{Dependency: leftPad, SafeVersion: "1.1.0"},
+		{Dependency: isOdd, SafeVersion: "2.0.0"},
+	}
+	if !reflect.DeepEqual(plans[0].Yanked, want) {
+		t.Errorf("Yanked = %+v, want %+v", plans[0].Yanked, want)
+	}
+	if len(plans[0].Updates) != 1 || plans[0].Updates[0].Dependency.Name != "express" {
+		t.Errorf("Updates = %+v, want the express update unchanged", plans[0].Updates)
+	}
+	if len(plans[1].Yanked) != 0 {
+		t.Errorf("precommit Yanked = %+v, want none (no yank datasource)", plans[1].Yanked)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0], "registry unreachable") {
+		t.Errorf("errors = %v, want one for the failed lookup", errs)
+	}
+
+	out := captureStdout(t, func() { printYanked(plans) })
+	if !strings.Contains(out, "package.json: left-pad 1.0.1 (nearest safe version 1.1.0)") {
+		t.Errorf("printYanked() output:\n%s", out)
+	}
+}
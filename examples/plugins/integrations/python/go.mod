@@ -2,9 +2,18 @@ module github.com/santosr2/uptool/examples/plugins/python
 
 go 1.25
 
-require github.com/santosr2/uptool v0.2.0-alpha20251130
+require (
+	github.com/pelletier/go-toml/v2 v2.2.4
+	github.com/santosr2/uptool v0.2.0-alpha20251130
+)
 
-require golang.org/x/text v0.25.0 // indirect
+require (
+	github.com/Masterminds/semver/v3 v3.4.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/robfig/cron/v3 v3.0.1 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
 
 // Better local development
 replace github.com/santosr2/uptool => ../../../..
@@ -24,23 +24,30 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
 )
 
 const integrationName = "python"
 
-// Integration implements the engine.Integration interface for Python requirements.txt.
+// Integration implements the engine.Integration interface for Python requirements.txt,
+// pip-tools requirements.in, pyproject.toml, and legacy setup.py/setup.cfg files.
 type Integration struct {
 	client *PyPIClient
+	// run executes pip-compile; replaced in tests.
+	run func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
 }
 
 // New creates a new Python integration instance.
 func New() engine.Integration {
 	return &Integration{
 		client: NewPyPIClient(),
+		run:    runCommand,
 	}
 }
 
@@ -49,7 +56,9 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
-// Detect finds requirements.txt files in the repository.
+// Detect finds requirements.txt, requirements.in, pyproject.toml, setup.py and setup.cfg files in the repository.
+// When a requirements.in has a compiled requirements.txt next to it, the .in
+// file is the edit target and the .txt file is skipped as generated output.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
 
@@ -72,12 +81,14 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 			return nil
 		}
 
-		// Check if this is a requirements.txt file
-		basename := filepath.Base(path)
-		if basename != "requirements.txt" && !strings.HasPrefix(basename, "requirements-") {
+		// Check if this is a requirements, pyproject or setuptools file
+		base := filepath.Base(path)
+		if !isRequirementsFile(base) && !isPyprojectFile(base) && !isSetupFile(base) {
 			return nil
 		}
-		if !strings.HasSuffix(basename, ".txt") {
+
+		// requirements.txt compiled from a requirements.in is generated by pip-compile
+		if strings.HasSuffix(path, ".txt") && sourceExists(path) {
 			return nil
 		}
 
@@ -88,7 +99,7 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 		}
 
 		// Parse dependencies
-		deps, err := ParseRequirements(string(content))
+		deps, err := parseManifest(path, string(content))
 		if err != nil {
 			return fmt.Errorf("parsing %s: %w", path, err)
 		}
@@ -105,24 +116,35 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 		}
 
 		// Create manifest
-		manifests = append(manifests, &engine.Manifest{
+		manifest := &engine.Manifest{
 			Path:         path,
 			Type:         integrationName,
 			Dependencies: valueDeps,
-		})
+		}
+		if compiled := compiledPath(path); compiled != "" {
+			if _, err := os.Stat(compiled); err == nil {
+				manifest.Metadata = map[string]interface{}{"compiled": compiled}
+			}
+		}
+		manifests = append(manifests, manifest)
 
 		return nil
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("scanning for requirements.txt: %w", err)
+		return nil, fmt.Errorf("scanning for requirements files: %w", err)
 	}
 
 	return manifests, nil
 }
 
 // Plan generates an update plan for a requirements.txt file.
+// pyproject.toml specifiers are resolved against every PyPI release instead.
 func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	if isPyprojectFile(filepath.Base(manifest.Path)) {
+		return i.planPyproject(ctx, manifest, planCtx)
+	}
+
 	var updates []engine.Update
 
 	for _, dep := range manifest.Dependencies {
@@ -134,6 +156,12 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 			continue
 		}
 
+		// Never plan a specifier that would corrupt the file when written
+		if err := resolve.ValidateConstraint(integrationName, requirementOperator(dep)+latestVersion); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", dep.Name, err)
+			continue
+		}
+
 		// Check if update is needed
 		if dep.CurrentVersion != latestVersion {
 			// TODO: Calculate semantic version impact
@@ -152,8 +180,15 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 	}, nil
 }
 
-// Apply executes the update plan by rewriting requirements.txt.
+// Apply executes the update plan by rewriting pinned versions in the manifest.
+// The requirements.txt compiled from an updated requirements.in is regenerated
+// with pip-compile when FixLockfile is set; otherwise the result notes that it
+// is out of date.
 func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if isPyprojectFile(filepath.Base(plan.Manifest.Path)) {
+		return i.applyPyproject(plan)
+	}
+
 	// Read current file content
 	content, err := os.ReadFile(plan.Manifest.Path)
 	if err != nil {
@@ -163,10 +198,11 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 	// Apply updates to content
 	updated := string(content)
 	for _, update := range plan.Updates {
-		// Replace version in requirements.txt
+		// Replace version in place, which works alike for requirements and setuptools files
 		// This is a simplified implementation - a production version would be more robust
-		oldSpec := fmt.Sprintf("%s==%s", update.Dependency.Name, update.Dependency.CurrentVersion)
-		newSpec := fmt.Sprintf("%s==%s", update.Dependency.Name, update.TargetVersion)
+		operator := requirementOperator(update.Dependency)
+		oldSpec := fmt.Sprintf("%s%s%s", update.Dependency.Name, operator, update.Dependency.CurrentVersion)
+		newSpec := fmt.Sprintf("%s%s%s", update.Dependency.Name, operator, update.TargetVersion)
 		updated = strings.ReplaceAll(updated, oldSpec, newSpec)
 	}
 
@@ -175,10 +211,65 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("writing %s: %w", plan.Manifest.Path, err)
 	}
 
-	return &engine.ApplyResult{
+	result := &engine.ApplyResult{
 		Manifest: plan.Manifest,
 		Applied:  len(plan.Updates),
-	}, nil
+	}
+
+	// The compiled requirements.txt is never edited directly; pip-compile owns it
+	if compiled, ok := plan.Manifest.Metadata["compiled"].(string); ok && len(plan.Updates) > 0 && plan.WritesLockfile() {
+		if !plan.FixLockfile {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s not regenerated; run pip-compile or use --fix-lockfile", filepath.Base(compiled)))
+			return result, nil
+		}
+		diff, err := i.compile(ctx, plan.Manifest.Path, compiled)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		result.LockfileDiff = diff
+	}
+
+	return result, nil
+}
+
+// requirementOperator returns the operator a requirement pins its version
+// with; a bare requirement is pinned with ==.
+func requirementOperator(dep engine.Dependency) string {
+	if dep.Constraint == "" {
+		return "=="
+	}
+	return dep.Constraint
+}
+
+// compile regenerates a compiled requirements.txt by running pip-compile on
+// its requirements.in and returns the diff of the compiled file.
+func (i *Integration) compile(ctx context.Context, source, compiled string) (string, error) {
+	oldContent, err := os.ReadFile(compiled) // #nosec G304 -- compiled path is derived from a detected requirements.in
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", compiled, err)
+	}
+
+	dir := filepath.Dir(source)
+	output, err := i.run(ctx, dir, "pip-compile", "--quiet", "--output-file", filepath.Base(compiled), filepath.Base(source))
+	if err != nil {
+		return "", fmt.Errorf("%s: pip-compile failed: %v\n%s", filepath.Base(compiled), err, output)
+	}
+
+	newContent, err := os.ReadFile(compiled) // #nosec G304 -- compiled path is derived from a detected requirements.in
+	if err != nil {
+		return "", fmt.Errorf("reading updated %s: %w", compiled, err)
+	}
+	return rewrite.GenerateUnifiedDiff(filepath.Base(compiled), string(oldContent), string(newContent))
+}
+
+// runCommand runs a command in dir and returns its combined output.
+func runCommand(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s command not found", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 -- fixed pip-compile arguments, not a shell
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
 }
 
 // Validate checks if a requirements.txt file is valid.
@@ -190,10 +281,54 @@ func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) e
 	}
 
 	// Try to parse
-	_, err = ParseRequirements(string(content))
+	_, err = parseManifest(manifest.Path, string(content))
 	if err != nil {
-		return fmt.Errorf("invalid requirements.txt: %w", err)
+		return fmt.Errorf("invalid %s: %w", filepath.Base(manifest.Path), err)
 	}
 
 	return nil
 }
+
+// parseManifest parses a requirements or setuptools file based on its name.
+func parseManifest(path, content string) ([]*engine.Dependency, error) {
+	switch filepath.Base(path) {
+	case "setup.py":
+		return ParseSetupPy(content)
+	case "setup.cfg":
+		return ParseSetupCfg(content)
+	case "pyproject.toml":
+		return ParsePyproject(content)
+	default:
+		return ParseRequirements(content)
+	}
+}
+
+// isSetupFile reports whether basename is a setuptools setup.py or setup.cfg file.
+func isSetupFile(basename string) bool {
+	return basename == "setup.py" || basename == "setup.cfg"
+}
+
+// isRequirementsFile reports whether basename is a requirements.txt or
+// requirements.in file, including suffixed variants like requirements-dev.in.
+func isRequirementsFile(basename string) bool {
+	if basename != "requirements.txt" && basename != "requirements.in" && !strings.HasPrefix(basename, "requirements-") {
+		return false
+	}
+	return strings.HasSuffix(basename, ".txt") || strings.HasSuffix(basename, ".in")
+}
+
+// compiledPath returns the requirements.txt that pip-compile generates from a
+// requirements.in path, or "" if path is not a .in file.
+func compiledPath(path string) string {
+	if !strings.HasSuffix(path, ".in") {
+		return ""
+	}
+	return strings.TrimSuffix(path, ".in") + ".txt"
+}
+
+// sourceExists reports whether a requirements.txt path has a requirements.in
+// next to it that it is compiled from.
+func sourceExists(path string) bool {
+	_, err := os.Stat(strings.TrimSuffix(path, ".txt") + ".in")
+	return err == nil
+}
@@ -22,8 +22,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/santosr2/uptool/internal/engine"
@@ -233,6 +237,311 @@ flask==2.2.0`
 	}
 }
 
+// TestIntegrationDetect_PipTools tests that requirements.in is the edit target
+// and its compiled requirements.txt is treated as generated
+func TestIntegrationDetect_PipTools(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inPath := filepath.Join(tmpDir, "requirements.in")
+	if err := os.WriteFile(inPath, []byte("requests>=2.28.0\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	txtPath := filepath.Join(tmpDir, "requirements.txt")
+	compiled := `# This file is autogenerated by pip-compile
+certifi==2023.7.22
+requests==2.31.0
+`
+	if err := os.WriteFile(txtPath, []byte(compiled), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	integration := New().(*Integration)
+	manifests, err := integration.Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+	manifest := manifests[0]
+	if manifest.Path != inPath {
+		t.Errorf("Detect() path = %v, want %v", manifest.Path, inPath)
+	}
+	if got, _ := manifest.Metadata["compiled"].(string); got != txtPath {
+		t.Errorf("Detect() compiled = %q, want %q", got, txtPath)
+	}
+
+	// Apply edits requirements.in and leaves the generated file alone
+	plan := &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates: []engine.Update{{
+			Dependency:    manifest.Dependencies[0],
+			TargetVersion: "2.31.0",
+		}},
+	}
+	result, err := integration.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "run pip-compile") {
+		t.Errorf("Apply() = %+v, want one applied update and a pip-compile note", result)
+	}
+
+	content, err := os.ReadFile(inPath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != "requests>=2.31.0\n" {
+		t.Errorf("requirements.in = %q, want %q", content, "requests>=2.31.0\n")
+	}
+	content, err = os.ReadFile(txtPath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if string(content) != compiled {
+		t.Error("Generated requirements.txt was modified")
+	}
+}
+
+// TestIntegrationApply_FixLockfile tests that pip-compile regenerates the
+// compiled requirements.txt when FixLockfile is set
+func TestIntegrationApply_FixLockfile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inPath := filepath.Join(tmpDir, "requirements.in")
+	txtPath := filepath.Join(tmpDir, "requirements.txt")
+	if err := os.WriteFile(inPath, []byte("requests>=2.28.0\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	if err := os.WriteFile(txtPath, []byte("requests==2.28.0\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	var gotDir string
+	var gotArgs []string
+	integration := &Integration{
+		client: NewPyPIClient(),
+		run: func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+			gotDir, gotArgs = dir, append([]string{name}, args...)
+			return nil, os.WriteFile(filepath.Join(dir, "requirements.txt"), []byte("requests==2.31.0\n"), 0o644)
+		},
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:         inPath,
+			Type:         integrationName,
+			Dependencies: []engine.Dependency{{Name: "requests", CurrentVersion: "2.28.0", Constraint: ">="}},
+			Metadata:     map[string]interface{}{"compiled": txtPath},
+		},
+		Updates:     []engine.Update{{Dependency: engine.Dependency{Name: "requests", CurrentVersion: "2.28.0", Constraint: ">="}, TargetVersion: "2.31.0"}},
+		FixLockfile: true,
+	}
+	result, err := integration.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Apply() errors = %v, want none", result.Errors)
+	}
+	if !strings.Contains(result.LockfileDiff, "+requests==2.31.0") {
+		t.Errorf("Apply() lockfile diff = %q, want the regenerated pin", result.LockfileDiff)
+	}
+
+	wantArgs := []string{"pip-compile", "--quiet", "--output-file", "requirements.txt", "requirements.in"}
+	if gotDir != tmpDir || strings.Join(gotArgs, " ") != strings.Join(wantArgs, " ") {
+		t.Errorf("ran %v in %s, want %v in %s", gotArgs, gotDir, wantArgs, tmpDir)
+	}
+}
+
+// TestIntegrationDetect_InWithoutCompiled tests a requirements.in that has not been compiled yet
+func TestIntegrationDetect_InWithoutCompiled(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	inPath := filepath.Join(tmpDir, "requirements-dev.in")
+	if err := os.WriteFile(inPath, []byte("pytest>=7.0.0\n"), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	integration := New().(*Integration)
+	manifests, err := integration.Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+	if manifests[0].Path != inPath {
+		t.Errorf("Detect() path = %v, want %v", manifests[0].Path, inPath)
+	}
+	if _, ok := manifests[0].Metadata["compiled"]; ok {
+		t.Error("Detect() set compiled metadata without a requirements-dev.txt")
+	}
+}
+
+// TestIntegrationDetect_SetupCfg tests install_requires and extras in setup.cfg
+func TestIntegrationDetect_SetupCfg(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	cfgPath := filepath.Join(tmpDir, "setup.cfg")
+	content := `[metadata]
+name = example
+version = attr: example.__version__
+
+[options]
+packages = find:
+install_requires =
+    requests==2.28.0
+    click>=8.0.0
+    six
+; comment
+
+[options.extras_require]
+test =
+    pytest==7.0.0
+`
+	if err := os.WriteFile(cfgPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	integration := New().(*Integration)
+	manifests, err := integration.Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	if len(manifests) != 1 || manifests[0].Path != cfgPath {
+		t.Fatalf("Detect() = %+v, want setup.cfg", manifests)
+	}
+
+	// Unpinned requirements such as six are skipped
+	var got []string
+	for _, dep := range manifests[0].Dependencies {
+		got = append(got, dep.Name+dep.Constraint+dep.CurrentVersion)
+	}
+	want := []string{"requests==2.28.0", "click>=8.0.0", "pytest==7.0.0"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Detect() dependencies = %v, want %v", got, want)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: manifests[0],
+		Updates: []engine.Update{{
+			Dependency:    manifests[0].Dependencies[0],
+			TargetVersion: "2.31.0",
+		}},
+	}
+	if _, err := integration.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(cfgPath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if want := strings.Replace(content, "requests==2.28.0", "requests==2.31.0", 1); string(updated) != want {
+		t.Errorf("setup.cfg = %q, want %q", updated, want)
+	}
+}
+
+// TestIntegrationDetect_SetupPy tests literal requirement lists in setup.py
+func TestIntegrationDetect_SetupPy(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pyPath := filepath.Join(tmpDir, "setup.py")
+	content := `from setuptools import setup
+
+with open("requirements.txt") as f:
+    dynamic = f.read().splitlines()
+
+setup(
+    name="example",
+    install_requires=[
+        "requests==2.28.0",  # HTTP
+        'flask>=2.2.0',
+        "six",
+    ],
+    extras_require={
+        "test": ["pytest==7.0.0"],
+        "docs": ("sphinx==5.0.0",),
+    },
+    tests_require=dynamic,
+)
+`
+	if err := os.WriteFile(pyPath, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	integration := New().(*Integration)
+	manifests, err := integration.Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	if len(manifests) != 1 || manifests[0].Path != pyPath {
+		t.Fatalf("Detect() = %+v, want setup.py", manifests)
+	}
+
+	var got []string
+	for _, dep := range manifests[0].Dependencies {
+		got = append(got, dep.Name+dep.Constraint+dep.CurrentVersion)
+	}
+	want := []string{"requests==2.28.0", "flask>=2.2.0", "pytest==7.0.0", "sphinx==5.0.0"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("Detect() dependencies = %v, want %v", got, want)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: manifests[0],
+		Updates: []engine.Update{{
+			Dependency:    manifests[0].Dependencies[1],
+			TargetVersion: "3.0.0",
+		}},
+	}
+	if _, err := integration.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	updated, err := os.ReadFile(pyPath)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !strings.Contains(string(updated), `'flask>=3.0.0',`) {
+		t.Errorf("setup.py not rewritten:\n%s", updated)
+	}
+}
+
+// TestParseSetupPy_Dynamic tests that computed requirement lists are skipped
+func TestParseSetupPy_Dynamic(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"variable", `setup(install_requires=REQUIREMENTS)`},
+		{"call", `setup(install_requires=read_requirements("requirements.txt"))`},
+		{"comprehension", `setup(install_requires=[r for r in open("requirements.txt")])`},
+		{"concatenation", `setup(install_requires=["requests==2.28.0"] + EXTRA)`},
+		{"f-string", `setup(install_requires=[f"requests=={VERSION}"])`},
+		{"dict values", `setup(extras_require={"test": TEST_REQUIRES})`},
+		{"string mention", `# install_requires=["requests==2.28.0"]
+print("install_requires=['flask==2.2.0']")`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps, err := ParseSetupPy(tt.content)
+			if err != nil {
+				t.Fatalf("ParseSetupPy() error = %v", err)
+			}
+			if len(deps) != 0 {
+				t.Errorf("ParseSetupPy() = %d dependencies, want 0", len(deps))
+			}
+		})
+	}
+}
+
 // TestIntegrationValidate tests the Validate method
 func TestIntegrationValidate(t *testing.T) {
 	tests := []struct {
@@ -294,6 +603,51 @@ func TestIntegrationName(t *testing.T) {
 	}
 }
 
+func TestIntegrationPlan_InvalidSpecifier(t *testing.T) {
+	// A registry version that would plan the specifier requests>=1.0,<
+	latest := map[string]string{"requests": "1.0,<", "flask": "2.3.0"}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"info": map[string]string{"name": name, "version": latest[name]}})
+	}))
+	defer srv.Close()
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "requirements.txt")
+	content := "requests>=0.9\nflask==2.2.0\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	integration := New().(*Integration)
+	integration.client.baseURL = srv.URL
+	ctx := context.Background()
+
+	manifests, err := integration.Detect(ctx, tmpDir)
+	if err != nil || len(manifests) != 1 {
+		t.Fatalf("Detect() = %v, %v; want one manifest", manifests, err)
+	}
+
+	plan, err := integration.Plan(ctx, manifests[0], engine.NewPlanContext())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].Dependency.Name != "flask" {
+		t.Fatalf("Plan() updates = %+v, want only flask", plan.Updates)
+	}
+
+	if _, err := integration.Apply(ctx, plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if want := "requests>=0.9\nflask==2.3.0\n"; string(updated) != want {
+		t.Errorf("requirements.txt = %q, want %q", updated, want)
+	}
+}
+
 // TestIntegration_EndToEnd tests the full workflow
 func TestIntegration_EndToEnd(t *testing.T) {
 	if testing.Short() {
@@ -34,6 +34,12 @@ func RegisterWith(register func(name string, constructor func() engine.Integrati
 	register("python", New)
 }
 
+// InterfaceVersion declares the engine.Integration interface version this plugin
+// was built against. uptool checks it before calling RegisterWith.
+func InterfaceVersion() int {
+	return engine.InterfaceVersion
+}
+
 // main is not used when building as a plugin, but helps during development and testing.
 // You can run tests using `go test` even though the main function isn't called in plugin mode.
 func main() {
@@ -59,13 +59,54 @@ type PyPIResponse struct {
 
 // GetLatestVersion fetches the latest stable version for a package from PyPI.
 func (c *PyPIClient) GetLatestVersion(ctx context.Context, packageName string) (string, error) {
+	pypiResp, err := c.getPackage(ctx, packageName)
+	if err != nil {
+		return "", err
+	}
+
+	// Return latest version from info.version
+	// PyPI's JSON API returns the latest stable version in info.version
+	return pypiResp.Info.Version, nil
+}
+
+// GetReleases returns every release of a package that has files and is not
+// yanked, in no particular order.
+func (c *PyPIClient) GetReleases(ctx context.Context, packageName string) ([]string, error) {
+	pypiResp, err := c.getPackage(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []string
+	for version, files := range pypiResp.Releases {
+		if len(files) == 0 {
+			continue
+		}
+		// A release is yanked when all of its files are
+		yanked := true
+		for _, file := range files {
+			if !file.Yanked {
+				yanked = false
+				break
+			}
+		}
+		if !yanked {
+			releases = append(releases, version)
+		}
+	}
+
+	return releases, nil
+}
+
+// getPackage fetches package metadata from the PyPI JSON API.
+func (c *PyPIClient) getPackage(ctx context.Context, packageName string) (*PyPIResponse, error) {
 	// Construct URL: https://pypi.org/pypi/{package}/json
 	url := fmt.Sprintf("%s/%s/json", c.baseURL, packageName)
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	// Set User-Agent
@@ -74,22 +115,20 @@ func (c *PyPIClient) GetLatestVersion(ctx context.Context, packageName string) (
 	// Execute request
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("querying PyPI: %w", err)
+		return nil, fmt.Errorf("querying PyPI: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("PyPI returned status %d for package %s", resp.StatusCode, packageName)
+		return nil, fmt.Errorf("PyPI returned status %d for package %s", resp.StatusCode, packageName)
 	}
 
 	// Parse response
 	var pypiResp PyPIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&pypiResp); err != nil {
-		return "", fmt.Errorf("parsing PyPI response: %w", err)
+		return nil, fmt.Errorf("parsing PyPI response: %w", err)
 	}
 
-	// Return latest version from info.version
-	// PyPI's JSON API returns the latest stable version in info.version
-	return pypiResp.Info.Version, nil
+	return &pypiResp, nil
 }
@@ -0,0 +1,738 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/resolve"
+)
+
+// pyprojectFile holds the dependency tables of a pyproject.toml file.
+type pyprojectFile struct {
+	Project struct {
+		Dependencies []string `toml:"dependencies"`
+	} `toml:"project"`
+	Tool struct {
+		Poetry struct {
+			Dependencies map[string]any `toml:"dependencies"`
+		} `toml:"poetry"`
+	} `toml:"tool"`
+}
+
+// isPyprojectFile reports whether basename is a pyproject.toml file.
+func isPyprojectFile(basename string) bool {
+	return basename == "pyproject.toml"
+}
+
+// ParsePyproject extracts dependencies from the PEP 621 [project].dependencies
+// array and the Poetry [tool.poetry.dependencies] table of a pyproject.toml
+// file. Each dependency keeps its full specifier in Constraint and the version
+// it anchors on (the ==, >=, ~=, ^ or ~ clause) in CurrentVersion. Poetry's
+// python entry, git/path/url dependencies and specifiers without a version to
+// anchor on are skipped.
+func ParsePyproject(content string) ([]*engine.Dependency, error) {
+	var file pyprojectFile
+	if err := toml.Unmarshal([]byte(content), &file); err != nil {
+		return nil, fmt.Errorf("parse pyproject.toml: %w", err)
+	}
+
+	var deps []*engine.Dependency
+	seen := make(map[string]bool)
+	add := func(name, spec string) {
+		clauses, anchor := parseSpecifier(spec)
+		if anchor < 0 || seen[normalizeName(name)] {
+			return
+		}
+		seen[normalizeName(name)] = true
+		deps = append(deps, &engine.Dependency{
+			Name:           name,
+			CurrentVersion: clauses[anchor].version,
+			Constraint:     spec,
+			Type:           "direct",
+		})
+	}
+
+	for _, spec := range file.Project.Dependencies {
+		if req, ok := parsePEP508(spec); ok && !req.url {
+			add(req.name, req.specifier)
+		}
+	}
+
+	// Map order is random; sort so dependencies come out the same every time
+	names := make([]string, 0, len(file.Tool.Poetry.Dependencies))
+	for name := range file.Tool.Poetry.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.EqualFold(name, "python") {
+			continue
+		}
+		if spec, ok := poetryVersion(file.Tool.Poetry.Dependencies[name]); ok {
+			add(name, spec)
+		}
+	}
+
+	return deps, nil
+}
+
+// poetryVersion returns the version constraint of a Poetry dependency, given
+// either as a string or as a table with a version key. Git, path, url and file
+// dependencies, and lists of per-marker constraints, have none.
+func poetryVersion(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return strings.TrimSpace(v), true
+	case map[string]any:
+		for _, source := range []string{"git", "path", "url", "file"} {
+			if _, ok := v[source]; ok {
+				return "", false
+			}
+		}
+		version, ok := v["version"].(string)
+		return strings.TrimSpace(version), ok
+	default:
+		return "", false
+	}
+}
+
+// requirement is a parsed PEP 508 dependency specification.
+type requirement struct {
+	name string
+	// specifier is the version specifier as written, e.g. ">=2.0,<3".
+	specifier string
+	// offset is the index of specifier in the specification.
+	offset int
+	// url is set for direct references such as "pkg @ https://...".
+	url bool
+}
+
+// pep508Name matches the name and optional extras at the start of a PEP 508
+// specification.
+var pep508Name = regexp.MustCompile(`^\s*([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)\s*(?:\[[^\]]*\])?\s*`)
+
+// parsePEP508 splits a specification such as
+// "requests[security]>=2.0,<3; python_version >= '3.8'" into its name and
+// version specifier. Extras and environment markers are not returned.
+func parsePEP508(spec string) (requirement, bool) {
+	loc := pep508Name.FindStringSubmatchIndex(spec)
+	if loc == nil {
+		return requirement{}, false
+	}
+	req := requirement{name: spec[loc[2]:loc[3]]}
+
+	rest := spec[loc[1]:]
+	if strings.HasPrefix(rest, "@") {
+		req.url = true
+		return req, true
+	}
+	if idx := strings.Index(rest, ";"); idx >= 0 {
+		rest = rest[:idx]
+	}
+
+	// Specifiers may be wrapped in parentheses: name (>=1.0)
+	start, end := 0, len(strings.TrimRight(rest, " \t"))
+	if strings.HasPrefix(rest, "(") && end > 0 && rest[end-1] == ')' {
+		start, end = 1, end-1
+	}
+	trimmed := strings.TrimSpace(rest[start:end])
+	req.specifier = trimmed
+	req.offset = loc[1] + start + strings.Index(rest[start:end], trimmed)
+	return req, true
+}
+
+// normalizeName normalizes a package name as PEP 503 does for comparison.
+func normalizeName(name string) string {
+	return strings.ToLower(nameSeparators.ReplaceAllString(name, "-"))
+}
+
+var nameSeparators = regexp.MustCompile(`[-_.]+`)
+
+// clause is a single comparison of a version specifier, such as ">=2.0".
+type clause struct {
+	op      string
+	version string
+}
+
+// clausePattern matches one clause of a PEP 440 or Poetry specifier. A bare
+// version is an exact pin in Poetry.
+var clausePattern = regexp.MustCompile(`^(===|==|!=|~=|<=|>=|<|>|=|\^|~)?\s*([A-Za-z0-9.*+!_-]+)$`)
+
+// parseSpecifier splits a specifier into its comma-separated clauses and
+// returns the index of the anchor clause, the one whose version is bumped, or
+// -1 if there is none. Poetry "||" unions are not supported.
+func parseSpecifier(spec string) ([]clause, int) {
+	if strings.TrimSpace(spec) == "" || strings.Contains(spec, "||") {
+		return nil, -1
+	}
+
+	var clauses []clause
+	anchor := -1
+	for _, part := range strings.Split(spec, ",") {
+		m := clausePattern.FindStringSubmatch(strings.TrimSpace(part))
+		if m == nil {
+			return nil, -1
+		}
+		c := clause{op: m[1], version: m[2]}
+		if c.version == "*" {
+			continue
+		}
+		if anchor < 0 && isAnchorOp(c.op) && !strings.Contains(c.version, "*") {
+			if _, ok := parsePyVersion(c.version); ok {
+				anchor = len(clauses)
+			}
+		}
+		clauses = append(clauses, c)
+	}
+	return clauses, anchor
+}
+
+// isAnchorOp reports whether a clause with op names the version in use.
+func isAnchorOp(op string) bool {
+	switch op {
+	case "", "=", "==", "===", ">=", "~=", "^", "~":
+		return true
+	default:
+		return false
+	}
+}
+
+// isPin reports whether op pins an exact version.
+func isPin(op string) bool {
+	return op == "" || op == "=" || op == "==" || op == "==="
+}
+
+// pyVersion is a parsed PEP 440 version.
+type pyVersion struct {
+	release []int
+	epoch   int
+	// stage orders the version against its final release: dev (0),
+	// pre-release (1), final (2) or post-release (3).
+	stage int
+	// label orders pre-releases: a (0), b (1), rc (2).
+	label int
+	num   int
+	dev   bool
+}
+
+var pyVersionPattern = regexp.MustCompile(`(?i)^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|alpha|b|beta|c|rc|pre|preview)[-_.]?(\d*))?` +
+	`(?:[-_.]?(post|rev|r)[-_.]?(\d*))?` +
+	`(?:[-_.]?(dev)[-_.]?(\d*))?(?:\+[a-z0-9.]+)?$`)
+
+// parsePyVersion parses a PEP 440 version.
+func parsePyVersion(s string) (pyVersion, bool) {
+	m := pyVersionPattern.FindStringSubmatch(s)
+	if m == nil {
+		return pyVersion{}, false
+	}
+
+	v := pyVersion{stage: 2}
+	v.epoch, _ = strconv.Atoi(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return pyVersion{}, false
+		}
+		v.release = append(v.release, n)
+	}
+
+	switch {
+	case m[3] != "":
+		v.stage = 1
+		switch strings.ToLower(m[3]) {
+		case "a", "alpha":
+			v.label = 0
+		case "b", "beta":
+			v.label = 1
+		default:
+			v.label = 2
+		}
+		v.num, _ = strconv.Atoi(m[4])
+	case m[5] != "":
+		v.stage = 3
+		v.num, _ = strconv.Atoi(m[6])
+	case m[7] != "":
+		v.stage = 0
+	}
+	v.dev = m[7] != ""
+	return v, true
+}
+
+// prerelease reports whether v is a pre-release or development release.
+func (v pyVersion) prerelease() bool {
+	return v.stage < 2 || v.dev
+}
+
+// compare returns -1, 0 or 1 as v sorts before, with or after o.
+func (v pyVersion) compare(o pyVersion) int {
+	if c := compareInts(v.epoch, o.epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(v.release) || i < len(o.release); i++ {
+		if c := compareInts(segment(v.release, i), segment(o.release, i)); c != 0 {
+			return c
+		}
+	}
+	for _, c := range []int{
+		compareInts(v.stage, o.stage),
+		compareInts(v.label, o.label),
+		compareInts(v.num, o.num),
+	} {
+		if c != 0 {
+			return c
+		}
+	}
+	// A development release sorts before the release it leads up to
+	switch {
+	case v.dev && !o.dev:
+		return -1
+	case !v.dev && o.dev:
+		return 1
+	}
+	return 0
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// segment returns release segment i, treating missing segments as zero.
+func segment(release []int, i int) int {
+	if i < len(release) {
+		return release[i]
+	}
+	return 0
+}
+
+// hasPrefix reports whether v's release starts with prefix.
+func (v pyVersion) hasPrefix(prefix []int) bool {
+	for i, n := range prefix {
+		if segment(v.release, i) != n {
+			return false
+		}
+	}
+	return true
+}
+
+// matches reports whether v satisfies c.
+func (c clause) matches(v pyVersion, raw string) bool {
+	if c.op == "===" {
+		return raw == c.version
+	}
+
+	if strings.HasSuffix(c.version, ".*") {
+		prefix, ok := parsePyVersion(strings.TrimSuffix(c.version, ".*"))
+		if !ok {
+			return false
+		}
+		switch c.op {
+		case "", "=", "==":
+			return v.hasPrefix(prefix.release)
+		case "!=":
+			return !v.hasPrefix(prefix.release)
+		}
+		return false
+	}
+
+	cv, ok := parsePyVersion(c.version)
+	if !ok {
+		return false
+	}
+	cmp := v.compare(cv)
+	switch c.op {
+	case "", "=", "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case ">=":
+		return cmp >= 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case "<":
+		return cmp < 0
+	case "~=":
+		// Compatible release: ~=1.4.2 is >=1.4.2, ==1.4.*
+		return cmp >= 0 && len(cv.release) > 1 && v.hasPrefix(cv.release[:len(cv.release)-1])
+	case "^":
+		// Poetry caret: the left-most non-zero segment may not change
+		i := 0
+		for i < len(cv.release)-1 && cv.release[i] == 0 {
+			i++
+		}
+		return cmp >= 0 && v.hasPrefix(cv.release[:i+1])
+	case "~":
+		// Poetry tilde: ~1.2.3 is >=1.2.3,<1.3.0 and ~1 is >=1,<2
+		i := min(1, len(cv.release)-1)
+		return cmp >= 0 && v.hasPrefix(cv.release[:i+1])
+	}
+	return false
+}
+
+// allows reports whether a candidate version satisfies a specifier once its
+// anchor clause is bumped. Pins and lower bounds allow any newer version;
+// compatible-release, caret and tilde clauses keep their range, and the
+// remaining clauses (upper bounds, exclusions) apply as written.
+func allows(clauses []clause, anchor int, v pyVersion, raw string) bool {
+	for i, c := range clauses {
+		if i == anchor && (isPin(c.op) || c.op == ">=") {
+			current, _ := parsePyVersion(c.version)
+			if v.compare(current) < 0 {
+				return false
+			}
+			continue
+		}
+		if !c.matches(v, raw) {
+			return false
+		}
+	}
+	return true
+}
+
+// bumpSpecifier rewrites the anchor clause of spec to target, leaving the other
+// clauses and all spacing as written. Pins take the full target version; other
+// operators keep the precision they were written with, so ~=1.4 becomes ~=1.9
+// rather than ~=1.9.2. It returns false when the rewrite changes nothing.
+func bumpSpecifier(spec string, target pyVersion, targetRaw string) (string, bool) {
+	clauses, anchor := parseSpecifier(spec)
+	if anchor < 0 {
+		return spec, false
+	}
+
+	// parseSpecifier drops "*" clauses; find the anchor among the raw parts
+	parts := strings.Split(spec, ",")
+	idx := -1
+	for i, n := 0, 0; i < len(parts); i++ {
+		if strings.TrimSpace(parts[i]) == "*" {
+			continue
+		}
+		if n == anchor {
+			idx = i
+			break
+		}
+		n++
+	}
+
+	c := clauses[anchor]
+	version := targetRaw
+	if !isPin(c.op) {
+		current, _ := parsePyVersion(c.version)
+		segments := make([]string, len(current.release))
+		for i := range segments {
+			segments[i] = strconv.Itoa(segment(target.release, i))
+		}
+		version = strings.Join(segments, ".")
+	}
+	if version == c.version {
+		return spec, false
+	}
+
+	pos := strings.LastIndex(parts[idx], c.version)
+	parts[idx] = parts[idx][:pos] + version + parts[idx][pos+len(c.version):]
+	return strings.Join(parts, ","), true
+}
+
+// specifierEcosystem returns the ecosystem resolve.ValidateConstraint checks
+// spec against: poetry when it uses a clause only Poetry accepts (^, ~, = or a
+// bare version), python for plain PEP 440.
+func specifierEcosystem(spec string) string {
+	clauses, _ := parseSpecifier(spec)
+	for _, c := range clauses {
+		switch c.op {
+		case "", "=", "^", "~":
+			return "poetry"
+		}
+	}
+	return integrationName
+}
+
+// pyImpact classifies the update from current to target.
+func pyImpact(current, target pyVersion) engine.Impact {
+	switch {
+	case segment(current.release, 0) != segment(target.release, 0):
+		return engine.ImpactMajor
+	case segment(current.release, 1) != segment(target.release, 1):
+		return engine.ImpactMinor
+	default:
+		return engine.ImpactPatch
+	}
+}
+
+// planPyproject resolves, for each dependency, the newest release that its
+// specifier allows and bumps the specifier to it.
+func (i *Integration) planPyproject(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	var updates []engine.Update
+
+	for _, dep := range manifest.Dependencies {
+		clauses, anchor := parseSpecifier(dep.Constraint)
+		if anchor < 0 {
+			continue
+		}
+		current, ok := parsePyVersion(dep.CurrentVersion)
+		if !ok {
+			continue
+		}
+
+		releases, err := i.client.GetReleases(ctx, dep.Name)
+		if err != nil {
+			// Log warning but continue with other packages
+			fmt.Fprintf(os.Stderr, "Warning: failed to get releases for %s: %v\n", dep.Name, err)
+			continue
+		}
+
+		var best pyVersion
+		bestRaw := ""
+		for _, raw := range releases {
+			v, ok := parsePyVersion(raw)
+			if !ok || (v.prerelease() && !planCtx.EffectiveAllowPrerelease()) {
+				continue
+			}
+			if v.compare(current) <= 0 || !allows(clauses, anchor, v, raw) {
+				continue
+			}
+			if bestRaw == "" || v.compare(best) > 0 {
+				best, bestRaw = v, raw
+			}
+		}
+		if bestRaw == "" {
+			continue
+		}
+
+		// A bump that keeps the written precision may not change the specifier
+		spec, changed := bumpSpecifier(dep.Constraint, best, bestRaw)
+		if !changed {
+			continue
+		}
+		if err := resolve.ValidateConstraint(specifierEcosystem(spec), spec); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", dep.Name, err)
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: bestRaw,
+			Impact:        string(pyImpact(current, best)),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+	}, nil
+}
+
+// applyPyproject rewrites the version portion of each updated specifier in a
+// pyproject.toml file, leaving names, extras and markers untouched.
+func (i *Integration) applyPyproject(plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	content, err := os.ReadFile(plan.Manifest.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", plan.Manifest.Path, err)
+	}
+
+	updated := string(content)
+	result := &engine.ApplyResult{Manifest: plan.Manifest}
+	for _, update := range plan.Updates {
+		target, ok := parsePyVersion(update.TargetVersion)
+		if !ok {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: invalid version %q", update.Dependency.Name, update.TargetVersion))
+			continue
+		}
+		newSpec, changed := bumpSpecifier(update.Dependency.Constraint, target, update.TargetVersion)
+		if !changed {
+			continue
+		}
+
+		var n int
+		updated, n = rewritePyproject(updated, update.Dependency.Name, update.Dependency.Constraint, newSpec)
+		if n == 0 {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: specifier %q not found", update.Dependency.Name, update.Dependency.Constraint))
+			continue
+		}
+		result.AppliedUpdates = append(result.AppliedUpdates, update.Dependency.Name)
+		result.Applied++
+	}
+
+	if err := os.WriteFile(plan.Manifest.Path, []byte(updated), 0600); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", plan.Manifest.Path, err)
+	}
+
+	return result, nil
+}
+
+// tomlHeader matches a TOML table header and captures its name.
+var tomlHeader = regexp.MustCompile(`^\s*\[([^\[\]]+)\]\s*(?:#.*)?$`)
+
+// inlineVersion matches the version key of a Poetry inline table.
+var inlineVersion = regexp.MustCompile(`\bversion\s*=\s*`)
+
+// rewritePyproject replaces oldSpec with newSpec in every entry for name in
+// the [project].dependencies array and the Poetry dependency tables. It
+// returns the new content and the number of entries rewritten.
+func rewritePyproject(content, name, oldSpec, newSpec string) (string, int) {
+	lines := strings.Split(content, "\n")
+	section := ""
+	inDeps := false
+	count := 0
+	want := normalizeName(name)
+
+	for li, line := range lines {
+		if !inDeps {
+			if m := tomlHeader.FindStringSubmatch(line); m != nil {
+				section = normalizeTable(m[1])
+				continue
+			}
+		}
+
+		switch {
+		case section == "project":
+			from := 0
+			if !inDeps {
+				key, _, ok := strings.Cut(line, "=")
+				if !ok || strings.TrimSpace(key) != "dependencies" {
+					continue
+				}
+				inDeps = true
+				from = strings.Index(line, "=") + 1
+			}
+			spans, closed := stringSpans(line, from)
+			// Rewrite from the end so earlier spans keep their offsets
+			for s := len(spans) - 1; s >= 0; s-- {
+				lit := line[spans[s][0]:spans[s][1]]
+				req, ok := parsePEP508(lit)
+				if !ok || req.url || normalizeName(req.name) != want || req.specifier != oldSpec {
+					continue
+				}
+				start := spans[s][0] + req.offset
+				line = line[:start] + newSpec + line[start+len(oldSpec):]
+				count++
+			}
+			lines[li] = line
+			if closed {
+				inDeps = false
+			}
+
+		case section == "tool.poetry.dependencies":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || normalizeName(strings.Trim(strings.TrimSpace(key), `"'`)) != want {
+				continue
+			}
+			offset := len(key) + 1
+			if strings.HasPrefix(strings.TrimSpace(value), "{") {
+				// Inline table: name = { version = "^2.0", extras = [...] }
+				loc := inlineVersion.FindStringIndex(value)
+				if loc == nil {
+					continue
+				}
+				offset += loc[1]
+			}
+			if replaced, ok := replaceLiteral(line, offset, oldSpec, newSpec); ok {
+				lines[li] = replaced
+				count++
+			}
+
+		case strings.HasPrefix(section, "tool.poetry.dependencies."):
+			// Sub-table: [tool.poetry.dependencies.name] with version = "^2.0"
+			if normalizeName(strings.TrimPrefix(section, "tool.poetry.dependencies.")) != want {
+				continue
+			}
+			key, _, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "version" {
+				continue
+			}
+			if replaced, ok := replaceLiteral(line, len(key)+1, oldSpec, newSpec); ok {
+				lines[li] = replaced
+				count++
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n"), count
+}
+
+// normalizeTable strips whitespace and quotes from a TOML table name.
+func normalizeTable(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// replaceLiteral replaces the string literal starting at or after offset in
+// line when its value is oldValue.
+func replaceLiteral(line string, offset int, oldValue, newValue string) (string, bool) {
+	spans, _ := stringSpans(line, offset)
+	if len(spans) == 0 || strings.TrimSpace(line[spans[0][0]:spans[0][1]]) != oldValue {
+		return line, false
+	}
+	lit := line[spans[0][0]:spans[0][1]]
+	start := spans[0][0] + strings.Index(lit, oldValue)
+	return line[:start] + newValue + line[start+len(oldValue):], true
+}
+
+// stringSpans returns the [start, end) offsets of the contents of the quoted
+// strings in line from offset on, stopping at a comment. closed reports
+// whether an array-closing "]" was found outside the strings.
+func stringSpans(line string, offset int) (spans [][2]int, closed bool) {
+	for i := offset; i < len(line); i++ {
+		switch c := line[i]; c {
+		case '#':
+			return spans, false
+		case ']':
+			return spans, true
+		case '"', '\'':
+			end := i + 1
+			for end < len(line) && line[end] != c {
+				if c == '"' && line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return spans, false
+			}
+			spans = append(spans, [2]int{i + 1, end})
+			i = end
+		}
+	}
+	return spans, false
+}
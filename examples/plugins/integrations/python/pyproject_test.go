@@ -0,0 +1,272 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testPyproject = `[project]
+name = "example"
+requires-python = ">=3.9"
+dependencies = [
+    "requests[security]>=2.0,<3; python_version >= '3.8'",
+    "django==4.2.0",
+    "rich",
+    "mylib @ https://example.com/mylib-1.0.tar.gz",
+]
+
+[tool.poetry.dependencies]
+python = "^3.9"
+click = "^1.2"
+httpx = { version = "~0.24.1", extras = ["http2"] }
+local = { path = "../local" }
+forked = { git = "https://github.com/example/forked.git" }
+`
+
+func TestParsePyproject(t *testing.T) {
+	deps, err := ParsePyproject(testPyproject)
+	if err != nil {
+		t.Fatalf("ParsePyproject() error = %v", err)
+	}
+
+	var got []string
+	for _, dep := range deps {
+		got = append(got, dep.Name+" "+dep.Constraint+" "+dep.CurrentVersion)
+	}
+	want := []string{
+		"requests >=2.0,<3 2.0",
+		"django ==4.2.0 4.2.0",
+		"click ^1.2 1.2",
+		"httpx ~0.24.1 0.24.1",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("ParsePyproject() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if _, err := ParsePyproject("[project\n"); err == nil {
+		t.Error("ParsePyproject() error = nil for invalid TOML")
+	}
+}
+
+func TestParsePEP508(t *testing.T) {
+	tests := []struct {
+		spec      string
+		name      string
+		specifier string
+		url       bool
+	}{
+		{spec: "requests", name: "requests"},
+		{spec: "requests>=2.0,<3", name: "requests", specifier: ">=2.0,<3"},
+		{spec: "requests[security,socks] >= 2.0 ; python_version < '3.8'", name: "requests", specifier: ">= 2.0"},
+		{spec: "zope.interface (>=5.0)", name: "zope.interface", specifier: ">=5.0"},
+		{spec: "mylib @ https://example.com/mylib.tar.gz", name: "mylib", url: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			req, ok := parsePEP508(tt.spec)
+			if !ok {
+				t.Fatalf("parsePEP508(%q) failed", tt.spec)
+			}
+			if req.name != tt.name || req.specifier != tt.specifier || req.url != tt.url {
+				t.Errorf("parsePEP508(%q) = %+v", tt.spec, req)
+			}
+			if !tt.url && tt.spec[req.offset:req.offset+len(req.specifier)] != req.specifier {
+				t.Errorf("parsePEP508(%q) offset %d does not point at %q", tt.spec, req.offset, req.specifier)
+			}
+		})
+	}
+}
+
+func TestBumpSpecifier(t *testing.T) {
+	tests := []struct {
+		spec    string
+		target  string
+		want    string
+		changed bool
+	}{
+		{spec: "==4.2.0", target: "5.0.1", want: "==5.0.1", changed: true},
+		{spec: ">=2.0,<3", target: "2.31.0", want: ">=2.31,<3", changed: true},
+		{spec: ">= 2.0, < 3", target: "2.31.0", want: ">= 2.31, < 3", changed: true},
+		{spec: "~=1.4", target: "1.9.2", want: "~=1.9", changed: true},
+		{spec: "^1.2", target: "1.9.1", want: "^1.9", changed: true},
+		{spec: "1.2.3", target: "1.2.4", want: "1.2.4", changed: true},
+		{spec: ">=2.0", target: "2.0.5", want: ">=2.0", changed: false},
+		{spec: "<3,>=2.1", target: "2.5.0", want: "<3,>=2.5", changed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			target, _ := parsePyVersion(tt.target)
+			got, changed := bumpSpecifier(tt.spec, target, tt.target)
+			if got != tt.want || changed != tt.changed {
+				t.Errorf("bumpSpecifier(%q, %s) = %q, %v, want %q, %v", tt.spec, tt.target, got, changed, tt.want, tt.changed)
+			}
+		})
+	}
+}
+
+func TestPyVersionCompare(t *testing.T) {
+	ordered := []string{"1.0.dev1", "1.0a1", "1.0b2", "1.0rc1", "1.0", "1.0.post1", "1.0.1", "1.1", "2!0.1"}
+	for i := 1; i < len(ordered); i++ {
+		a, okA := parsePyVersion(ordered[i-1])
+		b, okB := parsePyVersion(ordered[i])
+		if !okA || !okB {
+			t.Fatalf("parsePyVersion(%q / %q) failed", ordered[i-1], ordered[i])
+		}
+		if a.compare(b) >= 0 {
+			t.Errorf("%s should sort before %s", ordered[i-1], ordered[i])
+		}
+	}
+
+	if v, _ := parsePyVersion("1.0"); v.compare(mustPyVersion(t, "1.0.0")) != 0 {
+		t.Error("1.0 and 1.0.0 should compare equal")
+	}
+}
+
+func mustPyVersion(t *testing.T, s string) pyVersion {
+	t.Helper()
+	v, ok := parsePyVersion(s)
+	if !ok {
+		t.Fatalf("parsePyVersion(%q) failed", s)
+	}
+	return v
+}
+
+// newPyPIServer serves release lists from the PyPI JSON API.
+func newPyPIServer(t *testing.T, releases map[string][]string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/json")
+		versions, ok := releases[name]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		files := make(map[string][]map[string]bool)
+		for _, v := range versions {
+			yanked := strings.HasSuffix(v, "!yanked")
+			files[strings.TrimSuffix(v, "!yanked")] = []map[string]bool{{"yanked": yanked}}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"info": map[string]string{"name": name}, "releases": files})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestIntegrationPyproject(t *testing.T) {
+	srv := newPyPIServer(t, map[string][]string{
+		"requests": {"2.0.0", "2.31.0", "2.32.0!yanked", "2.33.0rc1", "3.0.0"},
+		"django":   {"4.2.0", "4.2.7", "5.0.1"},
+		"click":    {"1.2.0", "1.9.1", "2.0.0"},
+		"httpx":    {"0.24.1"},
+	})
+
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "pyproject.toml")
+	if err := os.WriteFile(path, []byte(testPyproject), 0o644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	integration := New().(*Integration)
+	integration.client.baseURL = srv.URL
+	ctx := context.Background()
+
+	manifests, err := integration.Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != path {
+		t.Fatalf("Detect() = %+v, want pyproject.toml", manifests)
+	}
+
+	plan, err := integration.Plan(ctx, manifests[0], engine.NewPlanContext())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// Yanked releases, pre-releases and versions outside the specifier are skipped
+	var got []string
+	for _, u := range plan.Updates {
+		got = append(got, u.Dependency.Name+" "+u.TargetVersion+" "+u.Impact)
+	}
+	want := []string{"requests 2.31.0 minor", "django 5.0.1 major", "click 1.9.1 minor"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Fatalf("Plan() updates = %v, want %v", got, want)
+	}
+
+	result, err := integration.Apply(ctx, plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 3 || result.Failed != 0 {
+		t.Errorf("Apply() applied/failed = %d/%d, want 3/0: %v", result.Applied, result.Failed, result.Errors)
+	}
+
+	updated, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	wantContent := strings.NewReplacer(
+		`"requests[security]>=2.0,<3; python_version >= '3.8'"`, `"requests[security]>=2.31,<3; python_version >= '3.8'"`,
+		`"django==4.2.0"`, `"django==5.0.1"`,
+		`click = "^1.2"`, `click = "^1.9"`,
+	).Replace(testPyproject)
+	if string(updated) != wantContent {
+		t.Errorf("pyproject.toml =\n%s\nwant\n%s", updated, wantContent)
+	}
+}
+
+func TestRewritePyproject_PoetryForms(t *testing.T) {
+	content := `[tool.poetry.dependencies]
+python = "^3.9"
+httpx = { version = "~0.24.1", extras = ["http2"] }  # pinned for http2
+
+[tool.poetry.dependencies.pydantic]
+version = "^1.10"
+markers = "python_version >= '3.9'"
+`
+
+	got, n := rewritePyproject(content, "httpx", "~0.24.1", "~0.24.2")
+	got, m := rewritePyproject(got, "Pydantic", "^1.10", "^1.11")
+	if n != 1 || m != 1 {
+		t.Fatalf("rewritePyproject() rewrote %d/%d entries, want 1/1", n, m)
+	}
+
+	want := strings.NewReplacer(`"~0.24.1"`, `"~0.24.2"`, `"^1.10"`, `"^1.11"`).Replace(content)
+	if got != want {
+		t.Errorf("rewritePyproject() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, n := rewritePyproject(content, "httpx", "~0.23", "~0.24"); n != 0 {
+		t.Errorf("rewritePyproject() rewrote a mismatched specifier")
+	}
+}
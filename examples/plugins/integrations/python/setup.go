@@ -0,0 +1,341 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package main
+
+import (
+	"bufio"
+	"strings"
+	"unicode"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// ParseSetupCfg extracts pinned dependencies from a setup.cfg file.
+// It reads install_requires in the [options] section and every extra in the
+// [options.extras_require] section. Requirements without a version and
+// "file:"/"attr:" directives are skipped.
+func ParseSetupCfg(content string) ([]*engine.Dependency, error) {
+	var specs []string
+	var section, key string
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") {
+			continue
+		}
+
+		// Indented lines continue the value of the previous key
+		if line[0] == ' ' || line[0] == '\t' {
+			if key != "" {
+				specs = append(specs, trimmed)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			section = strings.TrimSpace(trimmed[1 : len(trimmed)-1])
+			key = ""
+			continue
+		}
+
+		name, value, ok := strings.Cut(trimmed, "=")
+		if !ok {
+			name, value, ok = strings.Cut(trimmed, ":")
+		}
+		if !ok {
+			key = ""
+			continue
+		}
+		name = strings.TrimSpace(name)
+
+		switch {
+		case section == "options" && name == "install_requires":
+			key = name
+		case section == "options.extras_require":
+			key = name
+		default:
+			key = ""
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "file:") || strings.HasPrefix(value, "attr:") {
+			// Computed by setuptools at build time
+			key = ""
+			continue
+		}
+		if value != "" {
+			specs = append(specs, value)
+		}
+	}
+
+	return parseSpecs(specs), scanner.Err()
+}
+
+// ParseSetupPy extracts pinned dependencies from the install_requires and
+// extras_require arguments of a setup.py file. Only literal values are read,
+// as ast.literal_eval would accept them: a list or tuple of strings for
+// install_requires and a dict of such lists for extras_require. Values that
+// are computed at runtime (variables, comprehensions, function calls,
+// concatenation) are skipped.
+func ParseSetupPy(content string) ([]*engine.Dependency, error) {
+	var specs []string
+
+	s := &pyScanner{src: content}
+	for s.pos < len(s.src) {
+		ident := s.nextIdentifier()
+		if ident != "install_requires" && ident != "extras_require" {
+			continue
+		}
+
+		// Only keyword arguments and assignments: install_requires=[...]
+		s.skipSpace()
+		if !s.consume('=') || s.peek() == '=' {
+			continue
+		}
+		s.skipSpace()
+
+		start := s.pos
+		var values []string
+		var ok bool
+		if ident == "install_requires" {
+			values, ok = s.stringList()
+		} else {
+			values, ok = s.stringListDict()
+		}
+		if !ok {
+			// Dynamic value; resume scanning after the keyword
+			s.pos = start
+			continue
+		}
+		specs = append(specs, values...)
+	}
+
+	return parseSpecs(specs), nil
+}
+
+// parseSpecs parses requirement specifiers, keeping the first pinned
+// occurrence of each package.
+func parseSpecs(specs []string) []*engine.Dependency {
+	var deps []*engine.Dependency
+	seen := make(map[string]bool)
+
+	for _, spec := range specs {
+		// Unpinned requirements have nothing to update
+		dep, err := parseRequirement(strings.TrimSpace(spec))
+		if err != nil || seen[dep.Name] {
+			continue
+		}
+		seen[dep.Name] = true
+		deps = append(deps, dep)
+	}
+
+	return deps
+}
+
+// pyScanner reads Python literals from source text. It understands just enough
+// of the language to skip comments and strings while looking for identifiers.
+type pyScanner struct {
+	src string
+	pos int
+}
+
+func (s *pyScanner) peek() byte {
+	if s.pos >= len(s.src) {
+		return 0
+	}
+	return s.src[s.pos]
+}
+
+func (s *pyScanner) consume(c byte) bool {
+	if s.peek() != c {
+		return false
+	}
+	s.pos++
+	return true
+}
+
+// skipSpace skips whitespace, line continuations and comments.
+func (s *pyScanner) skipSpace() {
+	for s.pos < len(s.src) {
+		switch c := s.src[s.pos]; {
+		case c == '#':
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.pos++
+			}
+		case c == '\\' || unicode.IsSpace(rune(c)):
+			s.pos++
+		default:
+			return
+		}
+	}
+}
+
+// nextIdentifier advances past the next token and returns it if it is an identifier.
+func (s *pyScanner) nextIdentifier() string {
+	s.skipSpace()
+	c := s.peek()
+	switch {
+	case c == '"' || c == '\'':
+		s.str()
+		return ""
+	case c == '_' || unicode.IsLetter(rune(c)):
+		start := s.pos
+		for s.pos < len(s.src) && (s.src[s.pos] == '_' || unicode.IsLetter(rune(s.src[s.pos])) || unicode.IsDigit(rune(s.src[s.pos]))) {
+			s.pos++
+		}
+		// String prefixes such as r"..." and f"..."
+		if q := s.peek(); q == '"' || q == '\'' {
+			s.str()
+			return ""
+		}
+		return s.src[start:s.pos]
+	default:
+		s.pos++
+		return ""
+	}
+}
+
+// str reads a single- or double-quoted string literal, including triple-quoted ones.
+func (s *pyScanner) str() (string, bool) {
+	quote := s.peek()
+	if quote != '"' && quote != '\'' {
+		return "", false
+	}
+
+	delim := string(quote)
+	if strings.HasPrefix(s.src[s.pos:], strings.Repeat(delim, 3)) {
+		delim = strings.Repeat(delim, 3)
+	}
+	s.pos += len(delim)
+
+	var b strings.Builder
+	for s.pos < len(s.src) {
+		if strings.HasPrefix(s.src[s.pos:], delim) {
+			s.pos += len(delim)
+			return b.String(), true
+		}
+		c := s.src[s.pos]
+		if c == '\\' && s.pos+1 < len(s.src) {
+			s.pos++
+			c = s.src[s.pos]
+		} else if c == '\n' && len(delim) == 1 {
+			return "", false
+		}
+		b.WriteByte(c)
+		s.pos++
+	}
+	return "", false
+}
+
+// literalString reads a string literal, joining implicitly concatenated literals.
+func (s *pyScanner) literalString() (string, bool) {
+	value, ok := s.str()
+	if !ok {
+		return "", false
+	}
+	for {
+		s.skipSpace()
+		if q := s.peek(); q != '"' && q != '\'' {
+			return value, true
+		}
+		next, ok := s.str()
+		if !ok {
+			return "", false
+		}
+		value += next
+	}
+}
+
+// stringList reads a list or tuple literal of strings.
+func (s *pyScanner) stringList() ([]string, bool) {
+	closing := byte(']')
+	if s.consume('(') {
+		closing = ')'
+	} else if !s.consume('[') {
+		return nil, false
+	}
+
+	var values []string
+	for {
+		s.skipSpace()
+		if s.consume(closing) {
+			return values, s.literalEnds()
+		}
+		value, ok := s.literalString()
+		if !ok {
+			return nil, false
+		}
+		values = append(values, value)
+
+		s.skipSpace()
+		if !s.consume(',') && s.peek() != closing {
+			return nil, false
+		}
+	}
+}
+
+// stringListDict reads a dict literal mapping strings to lists of strings.
+func (s *pyScanner) stringListDict() ([]string, bool) {
+	if !s.consume('{') {
+		return nil, false
+	}
+
+	var values []string
+	for {
+		s.skipSpace()
+		if s.consume('}') {
+			return values, s.literalEnds()
+		}
+		if _, ok := s.literalString(); !ok {
+			return nil, false
+		}
+		s.skipSpace()
+		if !s.consume(':') {
+			return nil, false
+		}
+		s.skipSpace()
+		list, ok := s.stringList()
+		if !ok {
+			return nil, false
+		}
+		values = append(values, list...)
+
+		s.skipSpace()
+		if !s.consume(',') && s.peek() != '}' {
+			return nil, false
+		}
+	}
+}
+
+// literalEnds reports whether the value just read is complete, rather than the
+// operand of an expression such as ["a"] + extra or ["a"][:1].
+func (s *pyScanner) literalEnds() bool {
+	save := s.pos
+	s.skipSpace()
+	c := s.peek()
+	s.pos = save
+	return c == 0 || c == ',' || c == ')' || c == '}' || c == ';' ||
+		unicode.IsLetter(rune(c)) || c == '_' || c == '#'
+}
@@ -0,0 +1,310 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package dashboard renders a dependency dashboard: a markdown file that lists
+// every available update with a checkbox, the pull requests uptool would open,
+// the pull requests held back by open_pull_requests_limit, and updates waiting
+// out a cooldown. The file is regenerated on every run. Ticked checkboxes and
+// any text outside the dashboard markers survive regeneration.
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/pullrequest"
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+// Markers delimit the generated part of the dashboard file.
+const (
+	startMarker = "<!-- uptool-dashboard:start -->"
+	endMarker   = "<!-- uptool-dashboard:end -->"
+)
+
+// checkedPattern matches a ticked checkbox and captures its item key.
+var checkedPattern = regexp.MustCompile(`(?m)^\s*- \[[xX]\] <!-- (.+?) -->`)
+
+// Options configures how pull requests are listed on the dashboard.
+type Options struct {
+	// Limit returns the open pull request limit for an integration.
+	// A nil Limit, or a limit of zero or less, means no limit.
+	Limit func(integration string) int
+	// Branch is the pull request branch prefix (default pullrequest.DefaultBranch).
+	Branch string
+	// Title is the pull request title (default pullrequest.DefaultTitle).
+	Title string
+	// CollapseDuplicates lists an update planned in several manifests once,
+	// with the manifests it appears in, instead of once per manifest.
+	CollapseDuplicates bool
+}
+
+// Write renders the dashboard for result into path, updating the file in place
+// when it already exists.
+func Write(path string, result *engine.PlanResult, opts Options) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve dashboard path: %w", err)
+	}
+
+	previous, err := secureio.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read dashboard: %w", err)
+	}
+
+	if err := secureio.WriteFile(path, Render(result, opts, previous), 0o600); err != nil {
+		return fmt.Errorf("write dashboard: %w", err)
+	}
+	return nil
+}
+
+// Render returns the dashboard for result merged into previous, the current
+// contents of the dashboard file (nil when there is none). The output depends
+// only on its inputs, so an unchanged plan regenerates an identical file.
+func Render(result *engine.PlanResult, opts Options, previous []byte) []byte {
+	if opts.Branch == "" {
+		opts.Branch = pullrequest.DefaultBranch
+	}
+	if opts.Title == "" {
+		opts.Title = pullrequest.DefaultTitle
+	}
+	checked := checkedKeys(previous)
+
+	var withUpdates []*engine.UpdatePlan
+	for _, p := range result.Plans {
+		if len(p.Updates) > 0 {
+			withUpdates = append(withUpdates, p)
+		}
+	}
+	pending, limited := splitByLimit(pullrequest.PerGroup(withUpdates, opts.Branch, opts.Title), opts.Limit)
+
+	var b strings.Builder
+	b.WriteString(startMarker + "\n")
+	b.WriteString("# Dependency Dashboard\n\n")
+	b.WriteString("This file is generated by `uptool plan --dashboard` and regenerated on every run. ")
+	b.WriteString("Ticked checkboxes and text outside the dashboard markers are kept.\n")
+
+	b.WriteString("\n## Pending Pull Requests\n\n")
+	if len(pending) == 0 {
+		b.WriteString("No pull requests pending.\n")
+	}
+	for _, req := range pending {
+		writePullRequest(&b, req, checked)
+	}
+
+	b.WriteString("\n## Rate-Limited\n\n")
+	if len(limited) == 0 {
+		b.WriteString("No pull requests are held back by `open_pull_requests_limit`.\n")
+	} else {
+		b.WriteString("These pull requests exceed `open_pull_requests_limit` and open once earlier ones are merged.\n\n")
+	}
+	for _, req := range limited {
+		writePullRequest(&b, req, checked)
+	}
+
+	b.WriteString("\n## Awaiting Cooldown\n\n")
+	writeHeld(&b, result.Plans)
+
+	b.WriteString("\n## Available Updates\n")
+	if len(withUpdates) == 0 {
+		b.WriteString("\nAll dependencies are up-to-date.\n")
+	}
+	if opts.CollapseDuplicates {
+		writeCollapsed(&b, withUpdates, checked)
+	} else {
+		for _, p := range sortedPlans(withUpdates) {
+			fmt.Fprintf(&b, "\n### %s (%s)\n\n", strings.TrimPrefix(p.Manifest.Path, "./"), p.Manifest.Type)
+			for _, u := range sortedUpdates(p.Updates) {
+				key := "update:" + p.Manifest.Path + ":" + u.Dependency.Name
+				writeCheckbox(&b, key, checked, fmt.Sprintf("**%s** `%s` → `%s` (%s)",
+					u.Dependency.Name, u.Dependency.CurrentVersion, u.TargetVersion, pullrequest.ImpactLabel(u.Impact)))
+			}
+		}
+	}
+
+	if len(result.Errors) > 0 {
+		b.WriteString("\n## Errors\n\n")
+		for _, e := range result.Errors {
+			fmt.Fprintf(&b, "- %s\n", e)
+		}
+	}
+
+	b.WriteString(endMarker + "\n")
+	return splice(previous, b.String())
+}
+
+// splitByLimit keeps at most the limit of pull requests per integration
+// pending and returns the rest as rate-limited. A pull request counts against
+// the integration of its first manifest.
+func splitByLimit(requests []pullrequest.Request, limit func(string) int) (pending, limited []pullrequest.Request) {
+	open := make(map[string]int)
+	for _, req := range requests {
+		integration := req.Plans[0].Manifest.Type
+		if limit != nil {
+			if n := limit(integration); n > 0 && open[integration] >= n {
+				limited = append(limited, req)
+				continue
+			}
+		}
+		open[integration]++
+		pending = append(pending, req)
+	}
+	return pending, limited
+}
+
+func writePullRequest(b *strings.Builder, req pullrequest.Request, checked map[string]bool) {
+	count := 0
+	for _, p := range req.Plans {
+		count += len(p.Updates)
+	}
+	noun := "updates"
+	if count == 1 {
+		noun = "update"
+	}
+	writeCheckbox(b, "pr:"+req.Branch, checked, fmt.Sprintf("**%s** (`%s`, %d %s)", req.Title, req.Branch, count, noun))
+}
+
+// writeHeld lists updates held by a cooldown policy, ordered by manifest and name.
+func writeHeld(b *strings.Builder, plans []*engine.UpdatePlan) {
+	var lines []string
+	for _, p := range sortedPlans(plans) {
+		held := append([]engine.HeldUpdate(nil), p.Held...)
+		sort.SliceStable(held, func(i, j int) bool {
+			return held[i].Update.Dependency.Name < held[j].Update.Dependency.Name
+		})
+		for i := range held {
+			h := &held[i]
+			lines = append(lines, fmt.Sprintf("- **%s** `%s` → `%s` in %s (%d days remaining)\n",
+				h.Update.Dependency.Name, h.Update.Dependency.CurrentVersion, h.Update.TargetVersion,
+				strings.TrimPrefix(p.Manifest.Path, "./"), h.DaysRemaining))
+		}
+	}
+	if len(lines) == 0 {
+		b.WriteString("No updates are waiting out a cooldown.\n")
+		return
+	}
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// writeCollapsed lists each distinct update once, ordered by dependency name
+// and integration, with the manifests it is planned in.
+func writeCollapsed(b *strings.Builder, plans []*engine.UpdatePlan, checked map[string]bool) {
+	if len(plans) == 0 {
+		return
+	}
+	collapsed := engine.CollapseDuplicates(sortedPlans(plans))
+	sort.SliceStable(collapsed, func(i, j int) bool {
+		if collapsed[i].Name != collapsed[j].Name {
+			return collapsed[i].Name < collapsed[j].Name
+		}
+		return collapsed[i].Integration < collapsed[j].Integration
+	})
+
+	b.WriteString("\n")
+	for i := range collapsed {
+		c := &collapsed[i]
+		paths := make([]string, len(c.Manifests))
+		for j, path := range c.Manifests {
+			paths[j] = "`" + strings.TrimPrefix(path, "./") + "`"
+		}
+		noun := "manifests"
+		if len(paths) == 1 {
+			noun = "manifest"
+		}
+		key := "update:" + c.Integration + ":" + c.Name + "@" + c.TargetVersion
+		writeCheckbox(b, key, checked, fmt.Sprintf("**%s** `%s` → `%s` (%s) in %d %s: %s",
+			c.Name, c.CurrentVersion, c.TargetVersion, pullrequest.ImpactLabel(c.Impact),
+			len(paths), noun, strings.Join(paths, ", ")))
+	}
+}
+
+// writeCheckbox writes a checklist item whose key is kept in an HTML comment,
+// ticked when the previous dashboard had it ticked.
+func writeCheckbox(b *strings.Builder, key string, checked map[string]bool, text string) {
+	box := " "
+	if checked[key] {
+		box = "x"
+	}
+	fmt.Fprintf(b, "- [%s] <!-- %s --> %s\n", box, key, text)
+}
+
+// checkedKeys returns the keys of the ticked checkboxes in a previous dashboard.
+func checkedKeys(previous []byte) map[string]bool {
+	checked := make(map[string]bool)
+	for _, m := range checkedPattern.FindAllSubmatch(dashboardSection(previous), -1) {
+		checked[string(m[1])] = true
+	}
+	return checked
+}
+
+// dashboardSection returns the generated part of a dashboard file, or the
+// whole file when it has no markers.
+func dashboardSection(content []byte) []byte {
+	s := string(content)
+	start := strings.Index(s, startMarker)
+	end := strings.Index(s, endMarker)
+	if start < 0 || end < start {
+		return content
+	}
+	return content[start:end]
+}
+
+// splice replaces the generated part of previous with block. Without markers,
+// block is appended after the existing content.
+func splice(previous []byte, block string) []byte {
+	s := string(previous)
+	start := strings.Index(s, startMarker)
+	end := strings.Index(s, endMarker)
+	if start >= 0 && end > start {
+		rest := strings.TrimPrefix(s[end+len(endMarker):], "\n")
+		return []byte(s[:start] + block + rest)
+	}
+	if strings.TrimSpace(s) == "" {
+		return []byte(block)
+	}
+	return []byte(strings.TrimRight(s, "\n") + "\n\n" + block)
+}
+
+// sortedPlans returns plans ordered by manifest path.
+func sortedPlans(plans []*engine.UpdatePlan) []*engine.UpdatePlan {
+	sorted := append([]*engine.UpdatePlan(nil), plans...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Manifest.Path < sorted[j].Manifest.Path
+	})
+	return sorted
+}
+
+// sortedUpdates returns updates ordered by dependency name. Grouping leaves
+// updates in map order, so the dashboard sorts them to stay stable.
+func sortedUpdates(updates []engine.Update) []engine.Update {
+	sorted := append([]engine.Update(nil), updates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Dependency.Name < sorted[j].Dependency.Name
+	})
+	return sorted
+}
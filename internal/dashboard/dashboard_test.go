@@ -0,0 +1,214 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dashboard
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func testResult() *engine.PlanResult {
+	return &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{
+			{
+				Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+				Updates: []engine.Update{
+					{Dependency: engine.Dependency{Name: "react", CurrentVersion: "17.0.2"}, TargetVersion: "18.2.0", Impact: "major"},
+					{Dependency: engine.Dependency{Name: "express", CurrentVersion: "4.18.0"}, TargetVersion: "4.19.2", Impact: "minor"},
+				},
+				Held: []engine.HeldUpdate{
+					{
+						Update:        engine.Update{Dependency: engine.Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21"},
+						ReleasedAt:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+						CooldownDays:  7,
+						DaysRemaining: 3,
+					},
+				},
+			},
+			{
+				Manifest: &engine.Manifest{Path: "web/package.json", Type: "npm"},
+				Updates: []engine.Update{
+					{Dependency: engine.Dependency{Name: "vite", CurrentVersion: "5.0.0"}, TargetVersion: "5.0.1", Impact: "patch"},
+				},
+			},
+			{
+				Manifest: &engine.Manifest{Path: "ios/Podfile", Type: "cocoapods"},
+			},
+		},
+		Errors: []string{"Cargo.toml: fetch crate: timeout"},
+	}
+}
+
+func TestRender(t *testing.T) {
+	got := string(Render(testResult(), Options{Limit: func(string) int { return 1 }}, nil))
+
+	want := startMarker + "\n" +
+		"# Dependency Dashboard\n\n" +
+		"This file is generated by `uptool plan --dashboard` and regenerated on every run. " +
+		"Ticked checkboxes and text outside the dashboard markers are kept.\n" +
+		"\n## Pending Pull Requests\n\n" +
+		"- [ ] <!-- pr:uptool/dependency-updates/package.json --> **chore: update dependencies (package.json)** (`uptool/dependency-updates/package.json`, 2 updates)\n" +
+		"\n## Rate-Limited\n\n" +
+		"These pull requests exceed `open_pull_requests_limit` and open once earlier ones are merged.\n\n" +
+		"- [ ] <!-- pr:uptool/dependency-updates/web/package.json --> **chore: update dependencies (web/package.json)** (`uptool/dependency-updates/web/package.json`, 1 update)\n" +
+		"\n## Awaiting Cooldown\n\n" +
+		"- **lodash** `4.17.20` → `4.17.21` in package.json (3 days remaining)\n" +
+		"\n## Available Updates\n" +
+		"\n### package.json (npm)\n\n" +
+		"- [ ] <!-- update:package.json:express --> **express** `4.18.0` → `4.19.2` (🟡 Minor)\n" +
+		"- [ ] <!-- update:package.json:react --> **react** `17.0.2` → `18.2.0` (🔴 Major)\n" +
+		"\n### web/package.json (npm)\n\n" +
+		"- [ ] <!-- update:web/package.json:vite --> **vite** `5.0.0` → `5.0.1` (🟢 Patch)\n" +
+		"\n## Errors\n\n" +
+		"- Cargo.toml: fetch crate: timeout\n" +
+		endMarker + "\n"
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRender_CollapseDuplicates(t *testing.T) {
+	lodash := engine.Update{Dependency: engine.Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21", Impact: "patch"}
+	result := &engine.PlanResult{
+		Plans: []*engine.UpdatePlan{
+			{Manifest: &engine.Manifest{Path: "web/package.json", Type: "npm"}, Updates: []engine.Update{lodash}},
+			{Manifest: &engine.Manifest{Path: "api/package.json", Type: "npm"}, Updates: []engine.Update{
+				lodash,
+				{Dependency: engine.Dependency{Name: "express", CurrentVersion: "4.18.0"}, TargetVersion: "4.19.2", Impact: "minor"},
+			}},
+			{Manifest: &engine.Manifest{Path: "ui/package.json", Type: "npm"}, Updates: []engine.Update{lodash}},
+		},
+	}
+
+	got := string(Render(result, Options{CollapseDuplicates: true}, nil))
+
+	want := "\n## Available Updates\n\n" +
+		"- [ ] <!-- update:npm:express@4.19.2 --> **express** `4.18.0` → `4.19.2` (🟡 Minor) in 1 manifest: `api/package.json`\n" +
+		"- [ ] <!-- update:npm:lodash@4.17.21 --> **lodash** `4.17.20` → `4.17.21` (🟢 Patch) in 3 manifests: " +
+		"`api/package.json`, `ui/package.json`, `web/package.json`\n" +
+		endMarker
+	if !strings.Contains(got, want) {
+		t.Errorf("Render() =\n%s\nwant it to contain\n%s", got, want)
+	}
+	if strings.Contains(got, "### ") {
+		t.Errorf("Render() still has per-manifest sections:\n%s", got)
+	}
+}
+
+func TestRender_Empty(t *testing.T) {
+	got := string(Render(&engine.PlanResult{}, Options{}, nil))
+
+	for _, want := range []string{
+		"No pull requests pending.",
+		"No pull requests are held back by `open_pull_requests_limit`.",
+		"No updates are waiting out a cooldown.",
+		"All dependencies are up-to-date.",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Render() missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "## Errors") {
+		t.Errorf("Render() has an Errors section without errors:\n%s", got)
+	}
+}
+
+func TestWrite_UpdatesInPlace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "DASHBOARD.md")
+
+	if err := Write(path, testResult(), Options{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(first), startMarker+"\n# Dependency Dashboard\n") {
+		t.Fatalf("dashboard does not start with the marker and title:\n%s", first)
+	}
+
+	// A second run with the same plan regenerates an identical file
+	if err := Write(path, testResult(), Options{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	second, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(second) != string(first) {
+		t.Fatalf("second run changed the dashboard:\n%s\nwant\n%s", second, first)
+	}
+
+	// Tick a checkbox and surround the dashboard with hand-written text
+	edited := "Notes for maintainers.\n\n" +
+		strings.Replace(string(first), "- [ ] <!-- update:package.json:react -->", "- [x] <!-- update:package.json:react -->", 1) +
+		"\nFooter.\n"
+	if err := os.WriteFile(path, []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result := testResult()
+	result.Plans[1].Updates[0].TargetVersion = "5.0.2"
+	if err := Write(path, result, Options{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	third, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(third)
+	if !strings.HasPrefix(got, "Notes for maintainers.\n\n"+startMarker) || !strings.HasSuffix(got, endMarker+"\n\nFooter.\n") {
+		t.Errorf("text outside the markers was not kept:\n%s", got)
+	}
+	if !strings.Contains(got, "- [x] <!-- update:package.json:react -->") {
+		t.Errorf("ticked checkbox was not kept:\n%s", got)
+	}
+	if !strings.Contains(got, "**vite** `5.0.0` → `5.0.2`") || strings.Contains(got, "`5.0.1`") {
+		t.Errorf("dashboard was not regenerated from the new plan:\n%s", got)
+	}
+	if strings.Count(got, startMarker) != 1 {
+		t.Errorf("dashboard duplicated on update:\n%s", got)
+	}
+}
+
+func TestWrite_AppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "DASHBOARD.md")
+	if err := os.WriteFile(path, []byte("# Project notes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Write(path, testResult(), Options{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "# Project notes\n\n"+startMarker+"\n") {
+		t.Errorf("dashboard was not appended after the existing content:\n%s", got)
+	}
+}
@@ -0,0 +1,250 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// ErrLookupTimeout is returned when a single lookup exceeds the per-dependency
+// timeout configured with engine.WithLookupTimeout.
+var ErrLookupTimeout = errors.New("lookup timed out")
+
+// cacheEntry holds the result of a single lookup. done is closed once the
+// lookup completes so concurrent callers for the same key wait instead of
+// issuing duplicate requests.
+type cacheEntry struct {
+	expires time.Time // zero while in flight or when entries never expire
+	value   any
+	err     error
+	done    chan struct{}
+}
+
+// CacheStats counts memoized lookups for one datasource.
+// A hit is a lookup answered from cache (including waiting on an identical
+// in-flight lookup); a miss is a lookup that called the wrapped datasource.
+type CacheStats struct {
+	Hits   int `json:"hits"`
+	Misses int `json:"misses"`
+}
+
+var (
+	cache      = make(map[string]*cacheEntry)
+	cacheStats = make(map[string]*CacheStats)
+	cacheTTL   time.Duration
+	cacheMu    sync.Mutex
+)
+
+// registryResolver is implemented by datasources that route packages to
+// different registries, such as npm scopes configured in .npmrc. The registry
+// a package resolves to is part of its memo key.
+type registryResolver interface {
+	RegistryFor(pkg string) string
+}
+
+// CachedDatasource memoizes lookups of a wrapped datasource for the lifetime of
+// the process, or for the duration set with SetCacheTTL. Entries are shared by every CachedDatasource wrapping a
+// datasource with the same name, so integrations querying the same registry
+// (e.g., actions and tflint on github-releases) reuse each other's results.
+// Failed lookups are not cached.
+type CachedDatasource struct {
+	ds Datasource
+}
+
+// Cached wraps ds with the process-wide memoization layer.
+// Wrapping an already cached datasource returns it unchanged.
+func Cached(ds Datasource) Datasource {
+	if _, ok := ds.(*CachedDatasource); ok {
+		return ds
+	}
+	return &CachedDatasource{ds: ds}
+}
+
+// SetCacheTTL makes memoized lookups expire ttl after they complete, for
+// long-running processes such as uptool serve. A ttl of zero or less keeps
+// them for the lifetime of the process, the default.
+func SetCacheTTL(ttl time.Duration) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cacheTTL = ttl
+}
+
+// ResetCache discards all memoized lookups and their hit/miss counters.
+func ResetCache() {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	cache = make(map[string]*cacheEntry)
+	cacheStats = make(map[string]*CacheStats)
+}
+
+// GetCacheStats returns a snapshot of cache hits and misses per datasource name.
+func GetCacheStats() map[string]CacheStats {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	snapshot := make(map[string]CacheStats, len(cacheStats))
+	for name, stats := range cacheStats {
+		snapshot[name] = *stats
+	}
+	return snapshot
+}
+
+// statsFor returns the counters for a datasource. cacheMu must be held.
+func statsFor(name string) *CacheStats {
+	stats, ok := cacheStats[name]
+	if !ok {
+		stats = &CacheStats{}
+		cacheStats[name] = stats
+	}
+	return stats
+}
+
+// Name returns the wrapped datasource identifier.
+func (c *CachedDatasource) Name() string {
+	return c.ds.Name()
+}
+
+// GetLatestVersion returns the latest version, served from cache when available.
+func (c *CachedDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	v, err := c.lookup(ctx, "latest", pkg, func(ctx context.Context) (any, error) {
+		return c.ds.GetLatestVersion(ctx, pkg)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// GetVersions returns all versions, served from cache when available.
+// The returned slice is a copy and may be modified by the caller.
+func (c *CachedDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	v, err := c.lookup(ctx, "versions", pkg, func(ctx context.Context) (any, error) {
+		return c.ds.GetVersions(ctx, pkg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return slices.Clone(v.([]string)), nil
+}
+
+// GetPackageInfo returns package metadata, served from cache when available.
+func (c *CachedDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	v, err := c.lookup(ctx, "info", pkg, func(ctx context.Context) (any, error) {
+		return c.ds.GetPackageInfo(ctx, pkg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*PackageInfo), nil
+}
+
+// lookup returns the memoized result for (datasource, kind, pkg, registry),
+// calling fetch on a miss or once the entry has expired. Waiting on an
+// in-flight lookup stops when ctx is done.
+func (c *CachedDatasource) lookup(ctx context.Context, kind, pkg string, fetch func(context.Context) (any, error)) (any, error) {
+	key := c.ds.Name() + "\x00" + kind + "\x00" + pkg
+	if r, ok := c.ds.(registryResolver); ok {
+		key += "\x00" + r.RegistryFor(pkg)
+	}
+
+	cacheMu.Lock()
+	if entry, ok := cache[key]; ok && (entry.expires.IsZero() || time.Now().Before(entry.expires)) {
+		cacheMu.Unlock()
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if entry.err == nil {
+			cacheMu.Lock()
+			statsFor(c.ds.Name()).Hits++
+			cacheMu.Unlock()
+			return entry.value, nil
+		}
+		// The in-flight lookup failed; fall through and try again ourselves
+		cacheMu.Lock()
+		statsFor(c.ds.Name()).Misses++
+		cacheMu.Unlock()
+		return c.fetch(ctx, pkg, fetch)
+	}
+
+	entry := &cacheEntry{done: make(chan struct{})}
+	cache[key] = entry
+	statsFor(c.ds.Name()).Misses++
+	cacheMu.Unlock()
+
+	entry.value, entry.err = c.fetch(ctx, pkg, fetch)
+
+	cacheMu.Lock()
+	if entry.err != nil && cache[key] == entry {
+		delete(cache, key)
+	} else if cacheTTL > 0 {
+		entry.expires = time.Now().Add(cacheTTL)
+	}
+	cacheMu.Unlock()
+	close(entry.done)
+
+	return entry.value, entry.err
+}
+
+// fetch calls the wrapped datasource under the per-dependency lookup timeout.
+// A lookup that outlives the timeout is abandoned, even if the datasource
+// ignores cancellation, and recorded as unchecked so the rest of the plan can
+// still complete.
+func (c *CachedDatasource) fetch(ctx context.Context, pkg string, fetch func(context.Context) (any, error)) (any, error) {
+	lookupCtx, cancel := engine.LookupContext(ctx)
+	defer cancel()
+
+	type result struct {
+		value any
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fetch(lookupCtx)
+		done <- result{value: value, err: err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-lookupCtx.Done():
+		r.err = lookupCtx.Err()
+	}
+
+	if r.err != nil && ctx.Err() == nil && errors.Is(lookupCtx.Err(), context.DeadlineExceeded) {
+		engine.RecordUnchecked(ctx, engine.UncheckedDependency{
+			Name:       pkg,
+			Datasource: c.ds.Name(),
+			Reason:     ErrLookupTimeout.Error(),
+		})
+		return nil, fmt.Errorf("%s %s: %w", c.ds.Name(), pkg, ErrLookupTimeout)
+	}
+
+	return r.value, r.err
+}
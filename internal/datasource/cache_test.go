@@ -0,0 +1,409 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// countingDatasource records how many times each method reaches the backend.
+type countingDatasource struct {
+	err      error
+	name     string
+	versions atomic.Int32
+	latest   atomic.Int32
+	info     atomic.Int32
+}
+
+func (c *countingDatasource) Name() string {
+	return c.name
+}
+
+func (c *countingDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	c.latest.Add(1)
+	return "2.0.0", c.err
+}
+
+func (c *countingDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	c.versions.Add(1)
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []string{"1.0.0", "2.0.0"}, nil
+}
+
+func (c *countingDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	c.info.Add(1)
+	return &PackageInfo{Name: pkg}, c.err
+}
+
+// registryDatasource resolves every package to a fixed registry.
+type registryDatasource struct {
+	countingDatasource
+	registry string
+}
+
+func (r *registryDatasource) RegistryFor(pkg string) string {
+	return r.registry
+}
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("second lookup does not hit backend", func(t *testing.T) {
+		ResetCache()
+		backend := &countingDatasource{name: "counting"}
+		ds := Cached(backend)
+
+		for range 2 {
+			if _, err := ds.GetVersions(ctx, "pkg"); err != nil {
+				t.Fatalf("GetVersions() error = %v", err)
+			}
+			if _, err := ds.GetLatestVersion(ctx, "pkg"); err != nil {
+				t.Fatalf("GetLatestVersion() error = %v", err)
+			}
+			if _, err := ds.GetPackageInfo(ctx, "pkg"); err != nil {
+				t.Fatalf("GetPackageInfo() error = %v", err)
+			}
+		}
+
+		if got := backend.versions.Load(); got != 1 {
+			t.Errorf("GetVersions backend calls = %d, want 1", got)
+		}
+		if got := backend.latest.Load(); got != 1 {
+			t.Errorf("GetLatestVersion backend calls = %d, want 1", got)
+		}
+		if got := backend.info.Load(); got != 1 {
+			t.Errorf("GetPackageInfo backend calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("shared across wrappers of the same datasource", func(t *testing.T) {
+		ResetCache()
+		backend := &countingDatasource{name: "shared"}
+
+		_, _ = Cached(backend).GetVersions(ctx, "pkg")
+		_, _ = Cached(&countingDatasource{name: "shared"}).GetVersions(ctx, "pkg")
+
+		if got := backend.versions.Load(); got != 1 {
+			t.Errorf("GetVersions backend calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("keyed by package and datasource", func(t *testing.T) {
+		ResetCache()
+		a := &countingDatasource{name: "a"}
+		b := &countingDatasource{name: "b"}
+
+		_, _ = Cached(a).GetVersions(ctx, "one")
+		_, _ = Cached(a).GetVersions(ctx, "two")
+		_, _ = Cached(b).GetVersions(ctx, "one")
+
+		if got := a.versions.Load(); got != 2 {
+			t.Errorf("datasource a backend calls = %d, want 2", got)
+		}
+		if got := b.versions.Load(); got != 1 {
+			t.Errorf("datasource b backend calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		ResetCache()
+		backend := &countingDatasource{name: "failing", err: errors.New("unavailable")}
+		ds := Cached(backend)
+
+		for range 2 {
+			if _, err := ds.GetVersions(ctx, "pkg"); err == nil {
+				t.Fatal("GetVersions() error = nil, want error")
+			}
+		}
+
+		if got := backend.versions.Load(); got != 2 {
+			t.Errorf("GetVersions backend calls = %d, want 2", got)
+		}
+	})
+
+	t.Run("returned versions are a copy", func(t *testing.T) {
+		ResetCache()
+		ds := Cached(&countingDatasource{name: "copy"})
+
+		first, _ := ds.GetVersions(ctx, "pkg")
+		first[0] = "mutated"
+
+		second, _ := ds.GetVersions(ctx, "pkg")
+		if second[0] != "1.0.0" {
+			t.Errorf("GetVersions()[0] = %q, want %q", second[0], "1.0.0")
+		}
+	})
+
+	t.Run("concurrent lookups share one request", func(t *testing.T) {
+		ResetCache()
+		backend := &countingDatasource{name: "concurrent"}
+		ds := Cached(backend)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = ds.GetVersions(ctx, "pkg")
+			}()
+		}
+		wg.Wait()
+
+		if got := backend.versions.Load(); got != 1 {
+			t.Errorf("GetVersions backend calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("keyed by registry", func(t *testing.T) {
+		ResetCache()
+		public := &registryDatasource{countingDatasource: countingDatasource{name: "scoped"}, registry: "https://registry.npmjs.org"}
+		private := &registryDatasource{countingDatasource: countingDatasource{name: "scoped"}, registry: "https://npm.example.com"}
+
+		_, _ = Cached(public).GetVersions(ctx, "@acme/lib")
+		_, _ = Cached(private).GetVersions(ctx, "@acme/lib")
+		_, _ = Cached(private).GetVersions(ctx, "@acme/lib")
+
+		if got := public.versions.Load(); got != 1 {
+			t.Errorf("public registry backend calls = %d, want 1", got)
+		}
+		if got := private.versions.Load(); got != 1 {
+			t.Errorf("private registry backend calls = %d, want 1", got)
+		}
+	})
+
+	t.Run("entries expire after the TTL", func(t *testing.T) {
+		ResetCache()
+		SetCacheTTL(time.Millisecond)
+		defer SetCacheTTL(0)
+		backend := &countingDatasource{name: "expiring"}
+		ds := Cached(backend)
+
+		_, _ = ds.GetVersions(ctx, "pkg")
+		time.Sleep(5 * time.Millisecond)
+		_, _ = ds.GetVersions(ctx, "pkg")
+
+		if got := backend.versions.Load(); got != 2 {
+			t.Errorf("GetVersions backend calls = %d, want 2", got)
+		}
+	})
+
+	t.Run("waiting honours the context", func(t *testing.T) {
+		ResetCache()
+		release := make(chan struct{})
+		defer close(release)
+		ds := Cached(&blockingDatasource{release: release, slow: "pkg"})
+
+		started := make(chan struct{})
+		go func() {
+			close(started)
+			_, _ = ds.GetVersions(ctx, "pkg")
+		}()
+		<-started
+		// Let the first lookup register as in flight
+		time.Sleep(10 * time.Millisecond)
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if _, err := ds.GetVersions(waitCtx, "pkg"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GetVersions() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+
+	t.Run("does not double wrap", func(t *testing.T) {
+		ds := Cached(&countingDatasource{name: "wrapped"})
+		if Cached(ds) != ds {
+			t.Error("Cached() wrapped an already cached datasource")
+		}
+	})
+}
+
+func TestCacheStats(t *testing.T) {
+	ctx := context.Background()
+	ResetCache()
+	defer ResetCache()
+
+	ds := Cached(&countingDatasource{name: "stats"})
+
+	// miss, hit, miss, hit, hit
+	for _, pkg := range []string{"a", "a", "b", "b", "a"} {
+		if _, err := ds.GetVersions(ctx, pkg); err != nil {
+			t.Fatalf("GetVersions(%q) error = %v", pkg, err)
+		}
+	}
+
+	got := GetCacheStats()["stats"]
+	if got.Hits != 3 || got.Misses != 2 {
+		t.Errorf("GetCacheStats() = %+v, want 3 hits and 2 misses", got)
+	}
+
+	// Failed lookups are not cached, so every retry is a miss
+	failing := Cached(&countingDatasource{name: "stats-failing", err: errors.New("boom")})
+	for range 2 {
+		if _, err := failing.GetVersions(ctx, "a"); err == nil {
+			t.Fatal("GetVersions() expected error")
+		}
+	}
+	if got := GetCacheStats()["stats-failing"]; got.Hits != 0 || got.Misses != 2 {
+		t.Errorf("GetCacheStats() for failing datasource = %+v, want 0 hits and 2 misses", got)
+	}
+
+	ResetCache()
+	if len(GetCacheStats()) != 0 {
+		t.Error("ResetCache() did not clear counters")
+	}
+}
+
+// blockingDatasource hangs on one package until released, ignoring ctx, and
+// answers every other package immediately.
+type blockingDatasource struct {
+	release chan struct{}
+	slow    string
+}
+
+func (b *blockingDatasource) Name() string {
+	return "blocking"
+}
+
+func (b *blockingDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	if pkg == b.slow {
+		<-b.release
+	}
+	return "2.0.0", nil
+}
+
+func (b *blockingDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	v, err := b.GetLatestVersion(ctx, pkg)
+	return []string{"1.0.0", v}, err
+}
+
+func (b *blockingDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	return &PackageInfo{Name: pkg}, nil
+}
+
+// lookupIntegration plans an update to the latest version of every dependency
+// in a manifest, skipping lookups that fail.
+type lookupIntegration struct {
+	ds Datasource
+}
+
+func (l *lookupIntegration) Name() string { return "lookup" }
+
+func (l *lookupIntegration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	return nil, nil
+}
+
+func (l *lookupIntegration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	var updates []engine.Update
+	for _, dep := range manifest.Dependencies {
+		latest, err := l.ds.GetLatestVersion(ctx, dep.Name)
+		if err != nil {
+			continue
+		}
+		updates = append(updates, engine.Update{Dependency: dep, TargetVersion: latest})
+	}
+	return &engine.UpdatePlan{Manifest: manifest, Updates: updates}, nil
+}
+
+func (l *lookupIntegration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	return &engine.ApplyResult{Manifest: plan.Manifest}, nil
+}
+
+func (l *lookupIntegration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	return nil
+}
+
+func TestCached_LookupTimeout(t *testing.T) {
+	ResetCache()
+	t.Cleanup(ResetCache)
+
+	backend := &blockingDatasource{slow: "slow", release: make(chan struct{})}
+	t.Cleanup(func() { close(backend.release) })
+	ds := Cached(backend)
+
+	ctx := engine.WithLookupTimeout(context.Background(), 50*time.Millisecond)
+
+	if _, err := ds.GetLatestVersion(ctx, "slow"); !errors.Is(err, ErrLookupTimeout) {
+		t.Fatalf("GetLatestVersion(slow) error = %v, want ErrLookupTimeout", err)
+	}
+	if v, err := ds.GetLatestVersion(ctx, "fast"); err != nil || v != "2.0.0" {
+		t.Fatalf("GetLatestVersion(fast) = %q, %v, want 2.0.0", v, err)
+	}
+
+	unchecked := engine.UncheckedLookups(ctx)
+	if len(unchecked) != 1 || unchecked[0].Name != "slow" || unchecked[0].Datasource != "blocking" {
+		t.Errorf("UncheckedLookups() = %+v, want only slow from blocking", unchecked)
+	}
+}
+
+func TestCached_LookupTimeoutPartialPlan(t *testing.T) {
+	ResetCache()
+	t.Cleanup(ResetCache)
+
+	backend := &blockingDatasource{slow: "b", release: make(chan struct{})}
+	t.Cleanup(func() { close(backend.release) })
+
+	eng := engine.NewEngine(nil)
+	eng.Register(&lookupIntegration{ds: Cached(backend)})
+
+	manifest := &engine.Manifest{
+		Path: "deps.txt",
+		Type: "lookup",
+		Dependencies: []engine.Dependency{
+			{Name: "a", CurrentVersion: "1.0.0"},
+			{Name: "b", CurrentVersion: "1.0.0"},
+			{Name: "c", CurrentVersion: "1.0.0"},
+		},
+	}
+
+	result, err := eng.PlanWithOptions(context.Background(), []*engine.Manifest{manifest}, &engine.PlanOptions{
+		LookupTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("PlanWithOptions() errors = %v, want none", result.Errors)
+	}
+	if len(result.Plans) != 1 {
+		t.Fatalf("PlanWithOptions() returned %d plans, want 1", len(result.Plans))
+	}
+
+	plan := result.Plans[0]
+	var names []string
+	for _, u := range plan.Updates {
+		names = append(names, u.Dependency.Name)
+	}
+	if len(names) != 2 || names[0] != "a" || names[1] != "c" {
+		t.Errorf("plan updates = %v, want [a c]", names)
+	}
+	if len(plan.Unchecked) != 1 || plan.Unchecked[0].Name != "b" {
+		t.Errorf("plan unchecked = %+v, want only b", plan.Unchecked)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func init() {
+	Register(NewCocoaPodsDatasource())
+}
+
+// CocoaPodsDatasource implements the Datasource interface for the CocoaPods trunk CDN.
+type CocoaPodsDatasource struct {
+	client *registry.CocoaPodsClient
+}
+
+// NewCocoaPodsDatasource creates a new CocoaPods datasource.
+func NewCocoaPodsDatasource() *CocoaPodsDatasource {
+	return &CocoaPodsDatasource{
+		client: registry.NewCocoaPodsClient(),
+	}
+}
+
+// Name returns the datasource identifier.
+func (d *CocoaPodsDatasource) Name() string {
+	return "cocoapods"
+}
+
+// GetLatestVersion returns the latest stable version for a pod.
+func (d *CocoaPodsDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return d.client.GetLatestVersion(ctx, pkg)
+}
+
+// GetVersions returns all available versions for a pod.
+func (d *CocoaPodsDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	return d.client.GetVersions(ctx, pkg)
+}
+
+// GetPackageInfo returns detailed information about a pod.
+func (d *CocoaPodsDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	versions, err := d.client.GetVersions(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	versionInfos := make([]VersionInfo, len(versions))
+	for i, v := range versions {
+		versionInfos[i] = VersionInfo{
+			Version: v,
+		}
+	}
+
+	return &PackageInfo{
+		Name:     pkg,
+		Versions: versionInfos,
+	}, nil
+}
+
+// GetPodspec returns the published podspec JSON for a pod version.
+// The cocoapods integration uses it to refresh Podfile.lock checksums.
+func (d *CocoaPodsDatasource) GetPodspec(ctx context.Context, pod, version string) ([]byte, error) {
+	return d.client.GetPodspec(ctx, pod, version)
+}
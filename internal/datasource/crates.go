@@ -0,0 +1,86 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func init() {
+	Register(NewCratesDatasource())
+}
+
+// CratesDatasource implements the Datasource interface for crates.io.
+type CratesDatasource struct {
+	client *registry.CratesClient
+}
+
+// NewCratesDatasource creates a new crates.io datasource.
+func NewCratesDatasource() *CratesDatasource {
+	return &CratesDatasource{
+		client: registry.NewCratesClient(),
+	}
+}
+
+// Name returns the datasource identifier.
+func (d *CratesDatasource) Name() string {
+	return "crates"
+}
+
+// GetLatestVersion returns the latest stable version for a crate.
+func (d *CratesDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return d.client.GetLatestVersion(ctx, pkg)
+}
+
+// GetVersions returns all non-yanked versions for a crate.
+func (d *CratesDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	return d.client.GetVersions(ctx, pkg)
+}
+
+// GetPackageInfo returns detailed information about a crate.
+func (d *CratesDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	info, err := d.client.GetCrateInfo(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]VersionInfo, 0, len(info.Versions))
+	for _, v := range info.Versions {
+		versions = append(versions, VersionInfo{
+			Version:      v.Num,
+			PublishedAt:  v.CreatedAt,
+			IsPrerelease: strings.Contains(v.Num, "-"),
+			Deprecated:   v.Yanked,
+			Yanked:       v.Yanked,
+		})
+	}
+
+	return &PackageInfo{
+		Name:        pkg,
+		Description: info.Crate.Description,
+		Homepage:    info.Crate.Homepage,
+		Repository:  info.Crate.Repository,
+		Versions:    versions,
+	}, nil
+}
@@ -58,6 +58,44 @@ type VersionInfo struct {
 	PublishedAt  string
 	IsPrerelease bool
 	Deprecated   bool
+	// Yanked reports that the registry withdrew the version (crates.io yank,
+	// npm unpublish); it can no longer be newly installed.
+	Yanked bool
+}
+
+// Excluded reports whether the registry marks the version as one that should
+// not be proposed as an update.
+func (v VersionInfo) Excluded() bool {
+	return v.Deprecated || v.Yanked
+}
+
+// WithoutExcluded returns versions minus those ds reports as deprecated or
+// yanked for pkg. Registries that already leave yanked releases out of
+// GetVersions (crates.io, RubyGems) report nothing further. Lookup failures
+// leave versions unchanged.
+func WithoutExcluded(ctx context.Context, ds Datasource, pkg string, versions []string) []string {
+	info, err := ds.GetPackageInfo(ctx, pkg)
+	if err != nil || info == nil {
+		return versions
+	}
+
+	excluded := make(map[string]bool)
+	for _, v := range info.Versions {
+		if v.Excluded() {
+			excluded[v.Version] = true
+		}
+	}
+	if len(excluded) == 0 {
+		return versions
+	}
+
+	kept := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if !excluded[v] {
+			kept = append(kept, v)
+		}
+	}
+	return kept
 }
 
 var (
@@ -22,9 +22,15 @@ package datasource
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/santosr2/uptool/internal/registry"
 )
 
 // mockDatasource implements Datasource for testing
@@ -281,6 +287,20 @@ func TestTerraformDatasource(t *testing.T) {
 	testDatasourceBasicOps(t, "terraform", NewTerraformDatasource(), "hashicorp/consul/aws")
 }
 
+func TestGalaxyDatasource(t *testing.T) {
+	ds := NewGalaxyDatasource()
+	if ds.Name() != "ansible-galaxy" {
+		t.Errorf("Name() = %q, want %q", ds.Name(), "ansible-galaxy")
+	}
+
+	ctx := context.Background()
+	for _, pkg := range []string{"geerlingguy.docker", "module|community.general", "role|nodot"} {
+		if _, err := ds.GetVersions(ctx, pkg); err == nil {
+			t.Errorf("GetVersions(%q) error = nil, want error", pkg)
+		}
+	}
+}
+
 func TestGitHubDatasource(t *testing.T) {
 	t.Run("returns correct name", func(t *testing.T) {
 		ds := NewGitHubDatasource()
@@ -479,6 +499,71 @@ func TestNPMDatasource_GetPackageInfo(t *testing.T) {
 	_, _ = ds.GetPackageInfo(ctx, "lodash")
 }
 
+func TestNPMDatasource_GetPackageInfo_Unpublished(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"name": "left-pad",
+			"versions": {"1.0.0": {"deprecated": ""}, "1.1.0": {"deprecated": "use String.prototype.padStart"}},
+			"time": {
+				"created": "2016-01-01T00:00:00Z",
+				"modified": "2016-03-01T00:00:00Z",
+				"1.0.0": "2016-01-01T00:00:00Z",
+				"1.0.1": "2016-02-01T00:00:00Z",
+				"1.1.0": "2016-03-01T00:00:00Z"
+			}
+		}`))
+	}))
+	defer srv.Close()
+
+	ds := &NPMDatasource{client: registry.NewNPMClient(registry.NPMConfig{Registry: srv.URL})}
+	info, err := ds.GetPackageInfo(context.Background(), "left-pad")
+	if err != nil {
+		t.Fatalf("GetPackageInfo() error = %v", err)
+	}
+
+	got := make(map[string]VersionInfo)
+	for _, v := range info.Versions {
+		got[v.Version] = v
+	}
+	if len(got) != 3 {
+		t.Fatalf("GetPackageInfo() versions = %+v, want 1.0.0, 1.0.1 and 1.1.0", info.Versions)
+	}
+	if v := got["1.0.1"]; !v.Yanked || v.PublishedAt != "2016-02-01T00:00:00Z" {
+		t.Errorf("unpublished 1.0.1 = %+v, want yanked", v)
+	}
+	if v := got["1.1.0"]; v.Yanked || !v.Deprecated {
+		t.Errorf("1.1.0 = %+v, want deprecated but not yanked", v)
+	}
+	if v := got["1.0.0"]; v.Excluded() {
+		t.Errorf("1.0.0 = %+v, want deprecation cleared by empty message", v)
+	}
+}
+
+func TestWithoutExcluded(t *testing.T) {
+	versions := []string{"1.0.0", "1.1.0", "1.2.0", "2.0.0"}
+	ds := &mockDatasource{
+		name: "mock",
+		packageInfo: &PackageInfo{Versions: []VersionInfo{
+			{Version: "1.0.0"},
+			{Version: "1.1.0"},
+			{Version: "1.2.0", Deprecated: true},
+			{Version: "1.2.1", Yanked: true},
+			{Version: "2.0.0", Yanked: true},
+		}},
+	}
+
+	got := WithoutExcluded(context.Background(), ds, "pkg", versions)
+	if want := []string{"1.0.0", "1.1.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("WithoutExcluded() = %v, want %v", got, want)
+	}
+
+	ds.getPackageInfoErr = errors.New("registry down")
+	got = WithoutExcluded(context.Background(), ds, "pkg", versions)
+	if !reflect.DeepEqual(got, versions) {
+		t.Errorf("WithoutExcluded() on lookup failure = %v, want versions unchanged", got)
+	}
+}
+
 func TestTerraformDatasource_GetVersions(t *testing.T) {
 	ds := NewTerraformDatasource()
 	ctx := context.Background()
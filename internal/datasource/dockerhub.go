@@ -30,6 +30,9 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/version"
 )
 
 func init() {
@@ -37,19 +40,51 @@ func init() {
 }
 
 // DockerHubDatasource implements the Datasource interface for Docker Hub.
+// Images on configured private registries are looked up through the
+// registry's own v2 API instead.
 type DockerHubDatasource struct {
-	client  *http.Client
-	baseURL string
+	client      *http.Client
+	registries  map[string]dockerRegistry
+	baseURL     string
+	registryURL string
+	mirror      string
+}
+
+// dockerRegistry is a private registry host and its credentials.
+type dockerRegistry struct {
+	scheme   string
+	username string
+	password string
 }
 
 // NewDockerHubDatasource creates a new Docker Hub datasource.
 func NewDockerHubDatasource() *DockerHubDatasource {
 	return &DockerHubDatasource{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: "https://hub.docker.com/v2",
+		client:      registry.NewHTTPClient("docker-hub"),
+		baseURL:     "https://hub.docker.com/v2",
+		registryURL: "https://registry-1.docker.io",
+		registries:  make(map[string]dockerRegistry),
+	}
+}
+
+// ConfigureRegistry registers a "docker-registry" definition. Images whose
+// name starts with the registry host are looked up there; with ReplacesBase,
+// images without a host are too.
+func (d *DockerHubDatasource) ConfigureRegistry(creds RegistryCredentials) bool {
+	if creds.Type != "docker-registry" || creds.URL == "" {
+		return false
+	}
+
+	scheme, host := splitRegistryURL(creds.URL)
+	password := creds.Password
+	if password == "" {
+		password = creds.Token
+	}
+	d.registries[host] = dockerRegistry{scheme: scheme, username: creds.Username, password: password}
+	if creds.ReplacesBase {
+		d.mirror = host
 	}
+	return true
 }
 
 // Name returns the datasource identifier.
@@ -92,6 +127,39 @@ func (d *DockerHubDatasource) GetLatestVersion(ctx context.Context, pkg string)
 
 // GetVersions returns all available tags for a Docker image.
 func (d *DockerHubDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	var (
+		tags []string
+		err  error
+	)
+	if base, repo, reg := d.registryLocation(pkg); base != d.registryURL {
+		tags, err = d.registryTags(ctx, base, repo, reg)
+	} else {
+		tags, err = d.hubTags(ctx, pkg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter and sort tags
+	versions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		// Skip non-semver tags like "latest", "alpine", "slim"
+		if !isSemverTag(tag) {
+			continue
+		}
+		versions = append(versions, tag)
+	}
+
+	// Sort versions in descending order (newest first)
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(versions[i], versions[j]) > 0
+	})
+
+	return versions, nil
+}
+
+// hubTags lists the most recent tags of an image through the Docker Hub API.
+func (d *DockerHubDatasource) hubTags(ctx context.Context, pkg string) ([]string, error) {
 	// Normalize image name
 	namespace, repo := normalizeImageName(pkg)
 
@@ -121,22 +189,210 @@ func (d *DockerHubDatasource) GetVersions(ctx context.Context, pkg string) ([]st
 		return nil, err
 	}
 
-	// Filter and sort tags
-	versions := make([]string, 0, len(tagsResp.Results))
+	tags := make([]string, 0, len(tagsResp.Results))
 	for _, tag := range tagsResp.Results {
-		// Skip non-semver tags like "latest", "alpine", "slim"
-		if !isSemverTag(tag.Name) {
-			continue
+		tags = append(tags, tag.Name)
+	}
+	return tags, nil
+}
+
+// RegistryFor returns the configured registry host serving an image, or an
+// empty string for images looked up on Docker Hub.
+func (d *DockerHubDatasource) RegistryFor(image string) string {
+	host, _, _ := d.privateRegistry(image)
+	return host
+}
+
+// privateRegistry returns the configured registry host serving an image and
+// the repository path within it. Images without a host are served by the
+// registry that replaces Docker Hub, if any.
+func (d *DockerHubDatasource) privateRegistry(image string) (host, repo string, ok bool) {
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		_, ok = d.registries[first]
+		return first, rest, ok
+	}
+	if d.mirror == "" {
+		return "", "", false
+	}
+	if !found {
+		image = "library/" + image
+	}
+	return d.mirror, image, true
+}
+
+// registryTags lists an image's tags with the registry v2 API at base, such
+// as a configured private registry or a public one like ghcr.io.
+func (d *DockerHubDatasource) registryTags(ctx context.Context, base, repo string, reg dockerRegistry) ([]string, error) {
+	_, host, _ := strings.Cut(base, "://")
+	url := fmt.Sprintf("%s/v2/%s/tags/list", base, repo)
+
+	resp, err := d.registryDo(ctx, http.MethodGet, url, reg, "application/json")
+	if err != nil {
+		return nil, fmt.Errorf("registry %s: %w", host, err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("registry %s returned status %d", host, resp.StatusCode)
+	}
+
+	var list struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list.Tags, nil
+}
+
+// manifestMediaTypes are the manifest formats accepted when resolving a
+// digest. Multi-platform indexes come first so the digest pins every platform.
+var manifestMediaTypes = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// digestPattern matches a sha256 content digest.
+var digestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// GetDigest returns the manifest digest ("sha256:...") that tag of image
+// currently points at, read from the registry v2 manifest API. Images on
+// Docker Hub are resolved through its registry, other hosts directly.
+func (d *DockerHubDatasource) GetDigest(ctx context.Context, image, tag string) (string, error) {
+	base, repo, reg := d.registryLocation(image)
+	url := fmt.Sprintf("%s/v2/%s/manifests/%s", base, repo, tag)
+
+	resp, err := d.registryDo(ctx, http.MethodHead, url, reg, manifestMediaTypes)
+	if err != nil {
+		return "", err
+	}
+	_ = resp.Body.Close() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("manifest %s:%s: registry returned status %d", image, tag, resp.StatusCode)
+	}
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if !digestPattern.MatchString(digest) {
+		return "", fmt.Errorf("manifest %s:%s: registry returned no sha256 digest", image, tag)
+	}
+	return digest, nil
+}
+
+// registryLocation returns the v2 API base URL serving an image, its
+// repository path there, and the credentials to use.
+func (d *DockerHubDatasource) registryLocation(image string) (base, repo string, reg dockerRegistry) {
+	if host, path, ok := d.privateRegistry(image); ok {
+		reg = d.registries[host]
+		return reg.scheme + "://" + host, path, reg
+	}
+
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return "https://" + first, rest, dockerRegistry{scheme: "https"}
+	}
+	if !found {
+		image = "library/" + image
+	}
+	return d.registryURL, image, dockerRegistry{}
+}
+
+// registryDo sends a request to a registry v2 API, answering a bearer token
+// challenge with the registry's credentials when one is issued.
+func (d *DockerHubDatasource) registryDo(ctx context.Context, method, url string, reg dockerRegistry, accept string) (*http.Response, error) {
+	resp, err := d.registryRequest(ctx, method, url, reg, "", accept)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	_ = resp.Body.Close() //nolint:errcheck // HTTP cleanup best effort
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, fmt.Errorf("registry returned status %d", http.StatusUnauthorized)
+	}
+	token, err := d.registryToken(ctx, challenge, reg)
+	if err != nil {
+		return nil, fmt.Errorf("authenticate to registry: %w", err)
+	}
+	return d.registryRequest(ctx, method, url, reg, token, accept)
+}
+
+// registryRequest sends a request to a registry, authenticated with token
+// when given and with basic auth otherwise.
+func (d *DockerHubDatasource) registryRequest(ctx context.Context, method, url string, reg dockerRegistry, token, accept string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case reg.username != "" || reg.password != "":
+		req.SetBasicAuth(reg.username, reg.password)
+	}
+	return d.client.Do(req)
+}
+
+// challengeParam matches a key="value" parameter of a WWW-Authenticate header.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// registryToken fetches a pull token from the realm named in a bearer
+// challenge, as registries implementing the Docker token auth spec expect.
+func (d *DockerHubDatasource) registryToken(ctx context.Context, challenge string, reg dockerRegistry) (string, error) {
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("bearer challenge without realm")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
 		}
-		versions = append(versions, tag.Name)
+	}
+	req.URL.RawQuery = query.Encode()
+	// Tokens are short-lived, so they must never come from the HTTP cache
+	req.Header.Set("Cache-Control", "no-store")
+	if reg.username != "" || reg.password != "" {
+		req.SetBasicAuth(reg.username, reg.password)
 	}
 
-	// Sort versions in descending order (newest first)
-	sort.Slice(versions, func(i, j int) bool {
-		return compareVersions(versions[i], versions[j]) > 0
-	})
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
 
-	return versions, nil
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", fmt.Errorf("token endpoint returned no token")
 }
 
 // GetPackageInfo returns detailed information about a Docker image.
@@ -211,9 +467,8 @@ func isPrerelease(version string) bool {
 // compareVersions compares two version strings.
 // Returns positive if v1 > v2, negative if v1 < v2, 0 if equal.
 func compareVersions(v1, v2 string) int {
-	// Strip common prefixes
-	v1 = strings.TrimPrefix(v1, "v")
-	v2 = strings.TrimPrefix(v2, "v")
+	v1 = version.Bare(v1)
+	v2 = version.Bare(v2)
 
 	parts1 := strings.Split(v1, ".")
 	parts2 := strings.Split(v2, ".")
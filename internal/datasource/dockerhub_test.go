@@ -21,6 +21,10 @@
 package datasource
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -159,3 +163,97 @@ func TestCompareVersions(t *testing.T) {
 		})
 	}
 }
+
+func TestDockerHubDatasource_GetDigest(t *testing.T) {
+	const digest = "sha256:0d17b565c37bcbd895e9d92315a05c1c3c9a29f762b011a10c54a66cd53c9b31"
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.URL.Query().Get("scope") != "repository:library/nginx:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"anon"}`))
+		case "/v2/library/nginx/manifests/1.27.0":
+			if r.Method != http.MethodHead {
+				t.Errorf("method = %s, want HEAD", r.Method)
+			}
+			if r.Header.Get("Authorization") != "Bearer anon" {
+				w.Header().Set("WWW-Authenticate",
+					`Bearer realm="`+server.URL+`/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if !strings.HasPrefix(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+				t.Errorf("Accept = %q, want the image index first", r.Header.Get("Accept"))
+			}
+			w.Header().Set("Docker-Content-Digest", digest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ds := NewDockerHubDatasource()
+	ds.client = server.Client()
+	ds.registryURL = server.URL
+
+	got, err := ds.GetDigest(context.Background(), "nginx", "1.27.0")
+	if err != nil {
+		t.Fatalf("GetDigest() error = %v", err)
+	}
+	if got != digest {
+		t.Errorf("GetDigest() = %q, want %q", got, digest)
+	}
+
+	if _, err := ds.GetDigest(context.Background(), "nginx", "9.9.9"); err == nil {
+		t.Error("GetDigest() expected error for unknown tag")
+	}
+}
+
+func TestDockerHubDatasource_GetVersions_PublicRegistry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/devcontainers/features/node/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"devcontainers/features/node","tags":["1","1.5.0","1.6.1","latest"]}`))
+	}))
+	defer server.Close()
+
+	ds := NewDockerHubDatasource()
+	ds.client = server.Client()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	got, err := ds.GetVersions(context.Background(), host+"/devcontainers/features/node")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	want := []string{"1.6.1", "1.5.0", "1"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("GetVersions() = %v, want %v", got, want)
+	}
+}
+
+func TestDockerHubDatasource_RegistryLocation(t *testing.T) {
+	ds := NewDockerHubDatasource()
+
+	tests := []struct {
+		image    string
+		wantBase string
+		wantRepo string
+	}{
+		{"nginx", "https://registry-1.docker.io", "library/nginx"},
+		{"bitnami/redis", "https://registry-1.docker.io", "bitnami/redis"},
+		{"ghcr.io/org/app", "https://ghcr.io", "org/app"},
+		{"localhost/app", "https://localhost", "app"},
+	}
+	for _, tt := range tests {
+		base, repo, _ := ds.registryLocation(tt.image)
+		if base != tt.wantBase || repo != tt.wantRepo {
+			t.Errorf("registryLocation(%q) = %q, %q; want %q, %q", tt.image, base, repo, tt.wantBase, tt.wantRepo)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func init() {
+	Register(NewGalaxyDatasource())
+}
+
+// Galaxy content kinds accepted in the package identifier.
+const (
+	GalaxyKindRole       = "role"
+	GalaxyKindCollection = "collection"
+)
+
+// GalaxyDatasource implements the Datasource interface for Ansible Galaxy roles and collections.
+type GalaxyDatasource struct {
+	client *registry.GalaxyClient
+}
+
+// NewGalaxyDatasource creates a new Ansible Galaxy datasource.
+func NewGalaxyDatasource() *GalaxyDatasource {
+	return &GalaxyDatasource{
+		client: registry.NewGalaxyClient(),
+	}
+}
+
+// Name returns the datasource identifier.
+func (d *GalaxyDatasource) Name() string {
+	return "ansible-galaxy"
+}
+
+// GetLatestVersion returns the latest stable version for a role or collection.
+func (d *GalaxyDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	versions, err := d.GetVersions(ctx, pkg)
+	if err != nil {
+		return "", err
+	}
+
+	return registry.LatestStableVersion(versions)
+}
+
+// GetVersions returns all available versions for a role or collection.
+func (d *GalaxyDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	info, err := d.GetPackageInfo(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, len(info.Versions))
+	for i, v := range info.Versions {
+		result[i] = v.Version
+	}
+
+	return result, nil
+}
+
+// GetPackageInfo returns detailed information about a role or collection.
+func (d *GalaxyDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	// pkg format: "role|namespace.name" or "collection|namespace.name"
+	kind, name, ok := strings.Cut(pkg, "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid galaxy package format: %s", pkg)
+	}
+
+	var versionInfos []VersionInfo
+	switch kind {
+	case GalaxyKindRole:
+		versions, err := d.client.GetRoleVersions(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		versionInfos = make([]VersionInfo, len(versions))
+		for i, v := range versions {
+			versionInfos[i] = VersionInfo{
+				Version:     v.Name,
+				PublishedAt: v.Created,
+			}
+		}
+	case GalaxyKindCollection:
+		versions, err := d.client.GetCollectionVersions(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		versionInfos = make([]VersionInfo, len(versions))
+		for i, v := range versions {
+			versionInfos[i] = VersionInfo{
+				Version:     v.Version,
+				PublishedAt: v.CreatedAt,
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported galaxy content kind: %s", kind)
+	}
+
+	return &PackageInfo{
+		Name:     name,
+		Versions: versionInfos,
+	}, nil
+}
@@ -22,10 +22,12 @@ package datasource
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"strings"
 
 	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/version"
 )
 
 func init() {
@@ -41,7 +43,7 @@ type GitHubDatasource struct {
 func NewGitHubDatasource() *GitHubDatasource {
 	token := os.Getenv("GITHUB_TOKEN")
 	return &GitHubDatasource{
-		client: registry.NewGitHubClient(token),
+		client: registry.NewGitHubClient(token, registry.DefaultGitHubMaxRetries, registry.DefaultGitHubMaxWait),
 	}
 }
 
@@ -86,14 +88,35 @@ func (d *GitHubDatasource) GetVersions(ctx context.Context, pkg string) ([]strin
 			continue
 		}
 
-		// Strip 'v' prefix if present
-		version := strings.TrimPrefix(rel.TagName, "v")
-		versions = append(versions, version)
+		versions = append(versions, version.Bare(rel.TagName))
 	}
 
 	return versions, nil
 }
 
+// GetTags returns the tag names of a GitHub repository, including tags
+// without a release.
+func (d *GitHubDatasource) GetTags(ctx context.Context, pkg string) ([]string, error) {
+	// pkg format: "owner/repo"
+	parts := strings.Split(pkg, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repository: %s", pkg)
+	}
+
+	return d.client.GetTags(ctx, parts[0], parts[1])
+}
+
+// GetCommitSHA resolves a tag or other ref of a GitHub repository to its commit SHA.
+func (d *GitHubDatasource) GetCommitSHA(ctx context.Context, pkg, ref string) (string, error) {
+	// pkg format: "owner/repo"
+	parts := strings.Split(pkg, "/")
+	if len(parts) != 2 {
+		return "", fmt.Errorf("invalid repository: %s", pkg)
+	}
+
+	return d.client.GetCommitSHA(ctx, parts[0], parts[1], ref)
+}
+
 // GetPackageInfo returns detailed information about a GitHub repository's releases.
 func (d *GitHubDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
 	// pkg format: "owner/repo"
@@ -109,10 +132,8 @@ func (d *GitHubDatasource) GetPackageInfo(ctx context.Context, pkg string) (*Pac
 
 	versions := make([]VersionInfo, 0, len(releases))
 	for _, rel := range releases {
-		version := strings.TrimPrefix(rel.TagName, "v")
-
 		versions = append(versions, VersionInfo{
-			Version:      version,
+			Version:      version.Bare(rel.TagName),
 			PublishedAt:  rel.PublishedAt,
 			IsPrerelease: rel.Prerelease,
 			Deprecated:   false, // GitHub doesn't track deprecated releases
@@ -57,6 +57,16 @@ func (d *GoDatasource) GetVersions(ctx context.Context, pkg string) ([]string, e
 	return d.client.GetVersions(ctx, pkg)
 }
 
+// GetRetractions returns the version intervals a Go module's author retracted.
+func (d *GoDatasource) GetRetractions(ctx context.Context, pkg string) ([]registry.GoRetraction, error) {
+	return d.client.GetRetractions(ctx, pkg)
+}
+
+// GetModuleSums returns the go.sum hashes of a Go module version.
+func (d *GoDatasource) GetModuleSums(ctx context.Context, pkg, version string) (*registry.GoModuleSums, error) {
+	return d.client.GetModuleSums(ctx, pkg, version)
+}
+
 // GetPackageInfo returns detailed information about a Go module.
 func (d *GoDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
 	versions, err := d.client.GetVersions(ctx, pkg)
@@ -48,6 +48,11 @@ func (d *HelmDatasource) Name() string {
 	return "helm"
 }
 
+// EnableChecksumVerification requires the index of repository to match its published checksum.
+func (d *HelmDatasource) EnableChecksumVerification(repository string) {
+	d.client.EnableChecksumVerification(repository)
+}
+
 // GetLatestVersion returns the latest stable version for a Helm chart.
 func (d *HelmDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
 	// pkg format: "repository_url|chart_name"
@@ -83,9 +88,10 @@ func (d *HelmDatasource) GetPackageInfo(ctx context.Context, pkg string) (*Packa
 
 	versionInfos := make([]VersionInfo, len(versions))
 	for i, v := range versions {
-		versionInfos[i] = VersionInfo{
-			Version:     v.Version,
-			PublishedAt: v.Created.Format("2006-01-02T15:04:05Z07:00"),
+		versionInfos[i] = VersionInfo{Version: v.Version}
+		// Charts from OCI registries have no creation time
+		if !v.Created.IsZero() {
+			versionInfos[i].PublishedAt = v.Created.Format("2006-01-02T15:04:05Z07:00")
 		}
 	}
 
@@ -0,0 +1,85 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func init() {
+	Register(NewHexDatasource())
+}
+
+// HexDatasource implements the Datasource interface for hex.pm.
+type HexDatasource struct {
+	client *registry.HexClient
+}
+
+// NewHexDatasource creates a new hex.pm datasource.
+func NewHexDatasource() *HexDatasource {
+	return &HexDatasource{
+		client: registry.NewHexClient(),
+	}
+}
+
+// Name returns the datasource identifier.
+func (d *HexDatasource) Name() string {
+	return "hex"
+}
+
+// GetLatestVersion returns the latest stable version for a package.
+func (d *HexDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return d.client.GetLatestVersion(ctx, pkg)
+}
+
+// GetVersions returns all published versions for a package.
+func (d *HexDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	return d.client.GetVersions(ctx, pkg)
+}
+
+// GetPackageInfo returns detailed information about a package.
+func (d *HexDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	releases, err := d.client.GetReleases(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	versionInfos := make([]VersionInfo, len(releases))
+	for i, r := range releases {
+		versionInfos[i] = VersionInfo{
+			Version:     r.Version,
+			PublishedAt: r.InsertedAt,
+		}
+	}
+
+	return &PackageInfo{
+		Name:     pkg,
+		Versions: versionInfos,
+	}, nil
+}
+
+// GetRegistryRelease returns a release with its checksums and requirements.
+// The mix integration uses it to refresh mix.lock entries.
+func (d *HexDatasource) GetRegistryRelease(ctx context.Context, pkg, version string) (*registry.HexRegistryRelease, error) {
+	return d.client.GetRegistryRelease(ctx, pkg, version)
+}
@@ -22,8 +22,12 @@ package datasource
 
 import (
 	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
 
 	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/secureio"
 )
 
 func init() {
@@ -35,18 +39,74 @@ type NPMDatasource struct {
 	client *registry.NPMClient
 }
 
-// NewNPMDatasource creates a new npm datasource.
+// NewNPMDatasource creates a new npm datasource. Registry and auth settings
+// are read from the user's ~/.npmrc and then the project's .npmrc, so scoped
+// packages resolve against the same registries npm itself uses.
 func NewNPMDatasource() *NPMDatasource {
 	return &NPMDatasource{
-		client: registry.NewNPMClient(),
+		client: registry.NewNPMClient(loadNPMRC()...),
 	}
 }
 
+// loadNPMRC parses the .npmrc files that exist, user-level first so that
+// project settings take precedence.
+func loadNPMRC() []registry.NPMConfig {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".npmrc"))
+	}
+	paths = append(paths, ".npmrc")
+
+	var configs []registry.NPMConfig
+	for _, path := range paths {
+		data, err := secureio.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		configs = append(configs, registry.ParseNPMRC(data))
+	}
+	return configs
+}
+
 // Name returns the datasource identifier.
 func (d *NPMDatasource) Name() string {
 	return "npm"
 }
 
+// ConfigureRegistry adds the credentials of an "npm-registry" definition to
+// the client. A token is sent as a bearer token and a username and password
+// as basic auth; with ReplacesBase the registry also serves unscoped packages.
+func (d *NPMDatasource) ConfigureRegistry(creds RegistryCredentials) bool {
+	if creds.Type != "npm-registry" || creds.URL == "" {
+		return false
+	}
+
+	scheme, rest := splitRegistryURL(creds.URL)
+	key := "//" + rest + "/"
+	cfg := registry.NPMConfig{
+		Tokens: make(map[string]string),
+		Auths:  make(map[string]string),
+	}
+	switch {
+	case creds.Token != "":
+		cfg.Tokens[key] = creds.Token
+	case creds.Username != "" || creds.Password != "":
+		cfg.Auths[key] = base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+	}
+	if creds.ReplacesBase {
+		cfg.Registry = scheme + "://" + rest
+	}
+
+	d.client.Configure(cfg)
+	return true
+}
+
+// RegistryFor returns the registry URL a package is looked up in, which
+// differs between scopes configured in .npmrc.
+func (d *NPMDatasource) RegistryFor(pkg string) string {
+	return d.client.RegistryFor(pkg)
+}
+
 // GetLatestVersion returns the latest stable version for an npm package.
 func (d *NPMDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
 	return d.client.GetLatestVersion(ctx, pkg)
@@ -68,6 +128,17 @@ func (d *NPMDatasource) GetVersions(ctx context.Context, pkg string) ([]string,
 	return versions, nil
 }
 
+// GetTarball downloads the published tarball of an npm package version.
+func (d *NPMDatasource) GetTarball(ctx context.Context, pkg, version string) ([]byte, error) {
+	return d.client.GetTarball(ctx, pkg, version)
+}
+
+// GetDist returns the tarball URL and integrity string of an npm package
+// version.
+func (d *NPMDatasource) GetDist(ctx context.Context, pkg, version string) (*registry.NPMDist, error) {
+	return d.client.GetDist(ctx, pkg, version)
+}
+
 // GetPackageInfo returns detailed information about an npm package.
 func (d *NPMDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
 	info, err := d.client.GetPackageInfo(ctx, pkg)
@@ -85,9 +156,11 @@ func (d *NPMDatasource) GetPackageInfo(ctx context.Context, pkg string) (*Packag
 			}
 		}
 
+		// "npm deprecate pkg@version ''" clears a deprecation by leaving an
+		// empty message behind
 		deprecated := false
 		if versionData != nil {
-			if dep, ok := versionData["deprecated"]; ok && dep != nil {
+			if dep, ok := versionData["deprecated"]; ok && dep != nil && dep != "" && dep != false {
 				deprecated = true
 			}
 		}
@@ -99,11 +172,23 @@ func (d *NPMDatasource) GetPackageInfo(ctx context.Context, pkg string) (*Packag
 		})
 	}
 
+	// Unpublished versions keep their entry in "time" but leave "versions"
+	for version, publishDate := range info.Time {
+		if _, ok := info.Versions[version]; ok || version == "created" || version == "modified" {
+			continue
+		}
+		versions = append(versions, VersionInfo{
+			Version:     version,
+			PublishedAt: publishDate,
+			Yanked:      true,
+		})
+	}
+
 	return &PackageInfo{
 		Name:        info.Name,
 		Description: "", // Not exposed in registry.PackageInfo
 		Homepage:    "", // Not exposed in registry.PackageInfo
-		Repository:  "", // Not exposed in registry.PackageInfo
+		Repository:  info.Repository.WebURL(),
 		Versions:    versions,
 	}, nil
 }
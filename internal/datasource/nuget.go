@@ -0,0 +1,78 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func init() {
+	Register(NewNuGetDatasource())
+}
+
+// NuGetDatasource implements the Datasource interface for nuget.org.
+type NuGetDatasource struct {
+	client *registry.NuGetClient
+}
+
+// NewNuGetDatasource creates a new NuGet datasource.
+func NewNuGetDatasource() *NuGetDatasource {
+	return &NuGetDatasource{
+		client: registry.NewNuGetClient(),
+	}
+}
+
+// Name returns the datasource identifier.
+func (d *NuGetDatasource) Name() string {
+	return "nuget"
+}
+
+// GetLatestVersion returns the latest stable version for a package.
+func (d *NuGetDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return d.client.GetLatestVersion(ctx, pkg)
+}
+
+// GetVersions returns all listed versions for a package.
+func (d *NuGetDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	return d.client.GetVersions(ctx, pkg)
+}
+
+// GetPackageInfo returns detailed information about a package.
+func (d *NuGetDatasource) GetPackageInfo(ctx context.Context, pkg string) (*PackageInfo, error) {
+	versions, err := d.client.GetVersions(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	versionInfos := make([]VersionInfo, len(versions))
+	for i, v := range versions {
+		versionInfos[i] = VersionInfo{
+			Version: v,
+		}
+	}
+
+	return &PackageInfo{
+		Name:     pkg,
+		Versions: versionInfos,
+	}, nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"sort"
+	"strings"
+)
+
+// RegistryCredentials describes a private registry and how to authenticate
+// against it, following the shape of dependabot.yml "registries" entries.
+type RegistryCredentials struct {
+	// Type is the registry kind, e.g. "npm-registry" or "docker-registry".
+	Type string
+	// URL is the registry endpoint. A URL without a scheme defaults to https.
+	URL      string
+	Username string
+	Password string
+	Token    string
+	// ReplacesBase routes lookups that would go to the public registry
+	// to this one instead.
+	ReplacesBase bool
+}
+
+// registryConfigurer is implemented by datasources that can query private
+// registries. ConfigureRegistry reports whether creds was of a type the
+// datasource handles.
+type registryConfigurer interface {
+	ConfigureRegistry(creds RegistryCredentials) bool
+}
+
+// ConfigureRegistries hands each registry definition to the registered
+// datasources that support its type. It must be called before any lookups
+// are made. The types no datasource accepted are returned, sorted and
+// without duplicates.
+func ConfigureRegistries(registries []RegistryCredentials) (unsupported []string) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, creds := range registries {
+		accepted := false
+		for _, ds := range datasources {
+			if c, ok := ds.(registryConfigurer); ok && c.ConfigureRegistry(creds) {
+				accepted = true
+			}
+		}
+		if !accepted && !seen[creds.Type] {
+			seen[creds.Type] = true
+			unsupported = append(unsupported, creds.Type)
+		}
+	}
+
+	sort.Strings(unsupported)
+	return unsupported
+}
+
+// splitRegistryURL returns the scheme of a registry URL, defaulting to https,
+// and the URL without scheme or trailing slash.
+func splitRegistryURL(url string) (scheme, rest string) {
+	scheme, rest, ok := strings.Cut(url, "://")
+	if !ok {
+		scheme, rest = "https", url
+	}
+	return scheme, strings.TrimSuffix(rest, "/")
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNPMDatasource_ConfigureRegistry(t *testing.T) {
+	tests := []struct {
+		name     string
+		creds    RegistryCredentials
+		wantAuth string
+	}{
+		{
+			name:     "token",
+			creds:    RegistryCredentials{Type: "npm-registry", Token: "secret", ReplacesBase: true},
+			wantAuth: "Bearer secret",
+		},
+		{
+			name:     "username and password",
+			creds:    RegistryCredentials{Type: "npm-registry", Username: "user", Password: "pass", ReplacesBase: true},
+			wantAuth: "Basic dXNlcjpwYXNz",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotAuth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAuth = r.Header.Get("Authorization")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"name":     "lodash",
+					"versions": map[string]any{"4.17.21": map[string]any{}},
+				})
+			}))
+			defer server.Close()
+
+			ds := NewNPMDatasource()
+			tt.creds.URL = server.URL + "/"
+			if !ds.ConfigureRegistry(tt.creds) {
+				t.Fatal("ConfigureRegistry() = false, want true")
+			}
+
+			versions, err := ds.GetVersions(context.Background(), "lodash")
+			if err != nil {
+				t.Fatalf("GetVersions() error = %v", err)
+			}
+			if !reflect.DeepEqual(versions, []string{"4.17.21"}) {
+				t.Errorf("GetVersions() = %v, want [4.17.21]", versions)
+			}
+			if gotAuth != tt.wantAuth {
+				t.Errorf("Authorization = %q, want %q", gotAuth, tt.wantAuth)
+			}
+		})
+	}
+
+	if NewNPMDatasource().ConfigureRegistry(RegistryCredentials{Type: "docker-registry", URL: "https://r.example.com"}) {
+		t.Error("ConfigureRegistry(docker-registry) = true, want false")
+	}
+}
+
+func TestDockerHubDatasource_ConfigureRegistry(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "bot" || pass != "s3cret" || r.URL.Query().Get("scope") != "repository:team/app:pull" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+		case "/v2/team/app/tags/list":
+			if r.Header.Get("Authorization") != "Bearer pull-token" {
+				w.Header().Set("WWW-Authenticate",
+					`Bearer realm="`+server.URL+`/token",service="registry",scope="repository:team/app:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"name": "team/app",
+				"tags": []string{"1.0.0", "latest", "1.2.0", "1.1.0"},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ds := NewDockerHubDatasource()
+	ds.client = server.Client()
+	// Docker Hub must not be queried for images on the private registry
+	ds.baseURL = "http://127.0.0.1:1"
+
+	if !ds.ConfigureRegistry(RegistryCredentials{
+		Type:     "docker-registry",
+		URL:      server.URL,
+		Username: "bot",
+		Password: "s3cret",
+	}) {
+		t.Fatal("ConfigureRegistry() = false, want true")
+	}
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	versions, err := ds.GetVersions(context.Background(), host+"/team/app")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if want := []string{"1.2.0", "1.1.0", "1.0.0"}; !reflect.DeepEqual(versions, want) {
+		t.Errorf("GetVersions() = %v, want %v", versions, want)
+	}
+}
+
+func TestDockerHubDatasource_ReplacesBase(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(map[string]any{"tags": []string{"1.25.0"}})
+	}))
+	defer server.Close()
+
+	ds := NewDockerHubDatasource()
+	ds.client = server.Client()
+	ds.ConfigureRegistry(RegistryCredentials{Type: "docker-registry", URL: server.URL, Token: "tok", ReplacesBase: true})
+
+	if _, err := ds.GetVersions(context.Background(), "nginx"); err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if gotPath != "/v2/library/nginx/tags/list" {
+		t.Errorf("path = %q, want %q", gotPath, "/v2/library/nginx/tags/list")
+	}
+	if !strings.HasPrefix(gotAuth, "Basic ") {
+		t.Errorf("Authorization = %q, want basic auth", gotAuth)
+	}
+}
+
+func TestConfigureRegistries_Unsupported(t *testing.T) {
+	got := ConfigureRegistries([]RegistryCredentials{
+		{Type: "maven-repository", URL: "https://maven.example.com"},
+		{Type: "rubygems-server", URL: "https://gems.example.com"},
+		{Type: "maven-repository", URL: "https://other.example.com"},
+	})
+	if want := []string{"maven-repository", "rubygems-server"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ConfigureRegistries() unsupported = %v, want %v", got, want)
+	}
+}
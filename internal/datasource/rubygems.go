@@ -0,0 +1,79 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package datasource
+
+import (
+	"context"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+func init() {
+	Register(NewRubyGemsDatasource())
+}
+
+// RubyGemsDatasource implements the Datasource interface for RubyGems.org.
+type RubyGemsDatasource struct {
+	client *registry.RubyGemsClient
+}
+
+// NewRubyGemsDatasource creates a new RubyGems.org datasource.
+func NewRubyGemsDatasource() *RubyGemsDatasource {
+	return &RubyGemsDatasource{
+		client: registry.NewRubyGemsClient(),
+	}
+}
+
+// Name returns the datasource identifier.
+func (d *RubyGemsDatasource) Name() string {
+	return "rubygems"
+}
+
+// GetLatestVersion returns the latest stable version for a gem.
+func (d *RubyGemsDatasource) GetLatestVersion(ctx context.Context, gem string) (string, error) {
+	return d.client.GetLatestVersion(ctx, gem)
+}
+
+// GetVersions returns all published versions for a gem.
+func (d *RubyGemsDatasource) GetVersions(ctx context.Context, gem string) ([]string, error) {
+	return d.client.GetVersions(ctx, gem)
+}
+
+// GetPackageInfo returns detailed information about a gem.
+func (d *RubyGemsDatasource) GetPackageInfo(ctx context.Context, gem string) (*PackageInfo, error) {
+	releases, err := d.client.GetReleases(ctx, gem)
+	if err != nil {
+		return nil, err
+	}
+
+	versionInfos := make([]VersionInfo, len(releases))
+	for i, r := range releases {
+		versionInfos[i] = VersionInfo{
+			Version:     r.Number,
+			PublishedAt: r.CreatedAt,
+		}
+	}
+
+	return &PackageInfo{
+		Name:     gem,
+		Versions: versionInfos,
+	}, nil
+}
@@ -54,7 +54,9 @@ package dependabot
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -231,6 +233,25 @@ type Registry struct {
 	ReplacesBase bool `yaml:"replaces-base,omitempty"`
 }
 
+// secretPattern matches a GitHub Actions style ${{ secrets.NAME }} reference.
+var secretPattern = regexp.MustCompile(`\$\{\{\s*secrets\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// ExpandEnv returns the registry with secret references in its URL and
+// credentials resolved from the environment. Dependabot's ${{secrets.NAME}}
+// reads the NAME environment variable, as do ${NAME} and $NAME.
+func (r Registry) ExpandEnv() Registry {
+	expand := func(s string) string {
+		s = secretPattern.ReplaceAllString(s, "$${$1}")
+		return os.ExpandEnv(s)
+	}
+	r.URL = expand(r.URL)
+	r.Username = expand(r.Username)
+	r.Password = expand(r.Password)
+	r.Token = expand(r.Token)
+	r.Key = expand(r.Key)
+	return r
+}
+
 // LoadConfig reads and parses a dependabot.yml file.
 func LoadConfig(path string) (*Config, error) {
 	// Convert to absolute path if relative
@@ -350,9 +371,10 @@ var EcosystemToIntegration = map[string]string{
 	"maven":                "maven",
 	"gradle":               "gradle",
 	"nuget":                "nuget",
-	"mix":                  "hex",
+	"mix":                  "mix",
 	"pub":                  "pub",
 	"swift":                "swift",
+	"gitsubmodule":         "gitsubmodule",
 	"devcontainers":        "devcontainers",
 	"elm":                  "elm",
 	"bun":                  "bun",
@@ -443,6 +465,8 @@ func generateFilePattern(ecosystem, dir string) string {
 		return dir + "elm.json"
 	case "devcontainers":
 		return ".devcontainer/devcontainer.json"
+	case "gitsubmodule":
+		return ".gitmodules"
 	default:
 		return filepath.Join(dir, "*")
 	}
@@ -584,6 +584,32 @@ updates:
 	}
 }
 
+func TestRegistry_ExpandEnv(t *testing.T) {
+	t.Setenv("NPM_TOKEN", "npm-secret")
+	t.Setenv("DOCKER_USER", "bot")
+	t.Setenv("REGISTRY_HOST", "registry.example.com")
+
+	reg := Registry{
+		Type:     "docker-registry",
+		URL:      "https://${REGISTRY_HOST}",
+		Username: "${{secrets.DOCKER_USER}}",
+		Token:    "${{ secrets.NPM_TOKEN }}",
+		Password: "literal",
+	}
+
+	got := reg.ExpandEnv()
+	want := Registry{
+		Type:     "docker-registry",
+		URL:      "https://registry.example.com",
+		Username: "bot",
+		Token:    "npm-secret",
+		Password: "literal",
+	}
+	if got != want {
+		t.Errorf("ExpandEnv() = %+v, want %+v", got, want)
+	}
+}
+
 func TestGetIntegrationID(t *testing.T) {
 	tests := []struct {
 		ecosystem string
@@ -779,12 +805,9 @@ updates:
 	})
 }
 
-func TestLoadConfig_CompleteExample(t *testing.T) {
-	tmpDir := t.TempDir()
-	configPath := filepath.Join(tmpDir, "dependabot.yml")
-
-	// This is a comprehensive example matching the project's actual dependabot.yml format
-	configContent := `version: 2
+// completeExampleConfig is a comprehensive example matching the project's
+// actual dependabot.yml format.
+const completeExampleConfig = `version: 2
 updates:
   - package-ecosystem: "gomod"
     directory: "/"
@@ -831,7 +854,11 @@ updates:
           - "*"
 `
 
-	if err := os.WriteFile(configPath, []byte(configContent), 0o644); err != nil {
+func TestLoadConfig_CompleteExample(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "dependabot.yml")
+
+	if err := os.WriteFile(configPath, []byte(completeExampleConfig), 0o644); err != nil {
 		t.Fatalf("failed to create test config: %v", err)
 	}
 
@@ -0,0 +1,389 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dependabot
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/policy"
+)
+
+// maxOpenPullRequestsLimit is the largest open-pull-requests-limit Validate accepts.
+const maxOpenPullRequestsLimit = 10
+
+// ExportReport provides information about an export to dependabot.yml.
+type ExportReport struct {
+	// EcosystemsExported lists the package-ecosystems that were written
+	EcosystemsExported []string
+
+	// Skipped lists integrations that have no Dependabot ecosystem or are disabled
+	Skipped []string
+
+	// Untranslated lists settings that have no dependabot.yml equivalent
+	Untranslated []string
+}
+
+// GetEcosystem converts an uptool integration ID to a Dependabot
+// package-ecosystem. It reports false for integrations Dependabot does not
+// support. When several ecosystems map to the integration, the one named
+// like it is preferred.
+func GetEcosystem(integrationID string) (string, bool) {
+	var matches []string
+	for ecosystem, id := range EcosystemToIntegration {
+		if id == integrationID {
+			matches = append(matches, ecosystem)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+	sort.Strings(matches)
+	for _, ecosystem := range matches {
+		if ecosystem == integrationID {
+			return ecosystem, true
+		}
+	}
+	return matches[0], true
+}
+
+// ExportFromUptool converts an uptool configuration to a Dependabot
+// configuration, the reverse of MigrateWithReport. Only enabled integrations
+// with a Dependabot ecosystem are exported; the result passes Validate.
+func ExportFromUptool(cfg *policy.Config) (*Config, *ExportReport) {
+	report := &ExportReport{
+		EcosystemsExported: make([]string, 0),
+		Skipped:            make([]string, 0),
+		Untranslated:       make([]string, 0),
+	}
+
+	depConfig := &Config{
+		Version: 2,
+		Updates: make([]UpdateConfig, 0, len(cfg.Integrations)),
+	}
+
+	for i := range cfg.Integrations {
+		integration := &cfg.Integrations[i]
+		if !integration.Enabled {
+			report.Skipped = append(report.Skipped, integration.ID+": disabled")
+			continue
+		}
+		ecosystem, ok := GetEcosystem(integration.ID)
+		if !ok {
+			report.Skipped = append(report.Skipped, integration.ID+": no Dependabot package-ecosystem")
+			continue
+		}
+
+		update := exportIntegration(ecosystem, integration, report)
+		depConfig.Updates = append(depConfig.Updates, update)
+		report.EcosystemsExported = append(report.EcosystemsExported, ecosystem)
+	}
+
+	if cfg.RegistriesFromDependabot != "" {
+		report.Untranslated = append(report.Untranslated,
+			"registries_from_dependabot: registries stay in "+cfg.RegistriesFromDependabot)
+	}
+	if cfg.OrgPolicy != nil {
+		report.Untranslated = append(report.Untranslated, "org_policy")
+	}
+
+	return depConfig, report
+}
+
+func exportIntegration(ecosystem string, integration *policy.IntegrationConfig, report *ExportReport) UpdateConfig {
+	pol := &integration.Policy
+	note := func(setting string) {
+		report.Untranslated = append(report.Untranslated, integration.ID+": "+setting)
+	}
+
+	update := UpdateConfig{
+		PackageEcosystem: ecosystem,
+		Schedule:         exportSchedule(pol, note),
+		Groups:           exportGroups(pol.Groups),
+		Allow:            exportAllowRules(pol.Allow),
+		Ignore:           exportIgnoreRules(pol.Ignore),
+		Cooldown:         exportCooldown(pol.Cooldown),
+		CommitMessage:    exportCommitMessage(pol.CommitMessage),
+		Labels:           pol.Labels,
+		Assignees:        pol.Assignees,
+		Reviewers:        pol.Reviewers,
+	}
+
+	dirs := exportDirectories(ecosystem, integration.Match)
+	if len(dirs) == 1 {
+		update.Directory = dirs[0]
+	} else {
+		update.Directories = dirs
+	}
+	if integration.Match != nil {
+		update.ExcludePaths = integration.Match.Exclude
+	}
+
+	switch limit := pol.OpenPullRequestsLimit; {
+	case limit > maxOpenPullRequestsLimit:
+		update.OpenPullRequestsLimit = maxOpenPullRequestsLimit
+		note(fmt.Sprintf("open_pull_requests_limit %d lowered to %d", limit, maxOpenPullRequestsLimit))
+	case limit > 0:
+		update.OpenPullRequestsLimit = limit
+	}
+
+	// Dependabot has no update level; ignore the update types above it
+	switch pol.Update {
+	case policyMinor:
+		update.Ignore = append(update.Ignore, IgnoreRule{
+			DependencyName: "*",
+			UpdateTypes:    []string{"version-update:semver-major"},
+		})
+	case "patch":
+		update.Ignore = append(update.Ignore, IgnoreRule{
+			DependencyName: "*",
+			UpdateTypes:    []string{"version-update:semver-major", "version-update:semver-minor"},
+		})
+	case policyNone:
+		note("update: none exported as open-pull-requests-limit 0 (security updates only)")
+		update.OpenPullRequestsLimit = 0
+	}
+
+	if pol.VersioningStrategy != "" {
+		if isValidVersioningStrategy(pol.VersioningStrategy) {
+			update.VersioningStrategy = pol.VersioningStrategy
+		} else {
+			note("versioning_strategy " + pol.VersioningStrategy)
+		}
+	}
+
+	if pol.AllowPrerelease || len(pol.PrereleaseChannels) > 0 {
+		note("allow_prerelease and prerelease_channels")
+	}
+	if pol.Pin || pol.PinDigest {
+		note("pin and pin_digest")
+	}
+	if pol.Segment != "" {
+		note("segment")
+	}
+	if pol.AllowDeprecated {
+		note("allow_deprecated")
+	}
+	if len(pol.Repositories) > 0 {
+		note("repositories")
+	}
+	if len(pol.Custom) > 0 {
+		note("integration-specific settings")
+	}
+
+	return update
+}
+
+// leadingKeys are written first in each mapping by Marshal, in this order, so
+// the output reads like a hand-written dependabot.yml.
+var leadingKeys = []string{"version", "package-ecosystem", "directory", "directories", "schedule", "interval"}
+
+// Marshal encodes the configuration as a dependabot.yml document.
+func (c *Config) Marshal() ([]byte, error) {
+	var node yaml.Node
+	if err := node.Encode(c); err != nil {
+		return nil, fmt.Errorf("encode dependabot config: %w", err)
+	}
+	orderKeys(&node)
+
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return nil, fmt.Errorf("encode dependabot config: %w", err)
+	}
+	return data, nil
+}
+
+// orderKeys moves leadingKeys to the front of every mapping under node,
+// keeping the order of the remaining keys.
+func orderKeys(node *yaml.Node) {
+	for _, child := range node.Content {
+		orderKeys(child)
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	rank := func(key string) int {
+		for i, k := range leadingKeys {
+			if k == key {
+				return i
+			}
+		}
+		return len(leadingKeys)
+	}
+
+	pairs := make([][2]*yaml.Node, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return rank(pairs[i][0].Value) < rank(pairs[j][0].Value)
+	})
+	for i, pair := range pairs {
+		node.Content[2*i], node.Content[2*i+1] = pair[0], pair[1]
+	}
+}
+
+// exportSchedule returns the Dependabot schedule of a policy: its schedule
+// when it has one, otherwise its cadence, otherwise weekly.
+func exportSchedule(pol *engine.IntegrationPolicy, note func(string)) Schedule {
+	if s := pol.Schedule; s != nil && s.Interval != "" {
+		schedule := Schedule{
+			Interval: s.Interval,
+			Day:      s.Day,
+			Time:     s.Time,
+			Timezone: s.Timezone,
+			Cronjob:  s.Cron,
+		}
+		if err := validateSchedule(&schedule); err == nil {
+			return schedule
+		}
+		note("schedule interval " + s.Interval + " exported as weekly")
+		return Schedule{Interval: intervalWeekly}
+	}
+
+	switch pol.Cadence {
+	case "daily", intervalWeekly, "monthly":
+		return Schedule{Interval: pol.Cadence}
+	case "":
+		return Schedule{Interval: intervalWeekly}
+	default:
+		note("cadence " + pol.Cadence + " exported as weekly")
+		return Schedule{Interval: intervalWeekly}
+	}
+}
+
+// exportDirectories returns the directories holding an integration's
+// manifests: the directory of each match.files pattern, or the repository
+// root. GitHub Actions workflows are always found from the root.
+func exportDirectories(ecosystem string, match *policy.MatchConfig) []string {
+	if ecosystem == ecosystemGitHubActions || match == nil || len(match.Files) == 0 {
+		return []string{"/"}
+	}
+
+	seen := make(map[string]bool, len(match.Files))
+	dirs := make([]string, 0, len(match.Files))
+	for _, pattern := range match.Files {
+		dir := path.Dir(strings.TrimPrefix(pattern, "./"))
+		if dir == "." {
+			dir = "/"
+		} else {
+			dir = "/" + strings.TrimPrefix(dir, "/")
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// exportGroups converts uptool DependencyGroups to Dependabot groups.
+func exportGroups(groups map[string]*engine.DependencyGroup) map[string]Group {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	result := make(map[string]Group, len(groups))
+	for name, group := range groups {
+		if group == nil {
+			continue
+		}
+		result[name] = Group{
+			AppliesTo:       group.AppliesTo,
+			DependencyType:  group.DependencyType,
+			Patterns:        group.Patterns,
+			ExcludePatterns: group.ExcludePatterns,
+			UpdateTypes:     group.UpdateTypes,
+		}
+	}
+	return result
+}
+
+// exportAllowRules converts uptool DependencyRules to Dependabot allow rules.
+func exportAllowRules(rules []engine.DependencyRule) []AllowRule {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	result := make([]AllowRule, len(rules))
+	for i, rule := range rules {
+		result[i] = AllowRule{
+			DependencyName: rule.DependencyName,
+			DependencyType: rule.DependencyType,
+		}
+	}
+	return result
+}
+
+// exportIgnoreRules converts uptool IgnoreRules to Dependabot ignore rules.
+func exportIgnoreRules(rules []engine.IgnoreRule) []IgnoreRule {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	result := make([]IgnoreRule, len(rules))
+	for i, rule := range rules {
+		result[i] = IgnoreRule{
+			DependencyName: rule.DependencyName,
+			Versions:       rule.Versions,
+			UpdateTypes:    rule.UpdateTypes,
+		}
+	}
+	return result
+}
+
+// exportCooldown converts uptool CooldownConfig to Dependabot cooldown.
+func exportCooldown(cooldown *engine.CooldownConfig) *Cooldown {
+	if cooldown == nil {
+		return nil
+	}
+
+	return &Cooldown{
+		DefaultDays:     cooldown.DefaultDays,
+		SemverMajorDays: cooldown.SemverMajorDays,
+		SemverMinorDays: cooldown.SemverMinorDays,
+		SemverPatchDays: cooldown.SemverPatchDays,
+		Include:         cooldown.Include,
+		Exclude:         cooldown.Exclude,
+	}
+}
+
+// exportCommitMessage converts uptool CommitMessageConfig to Dependabot commit-message.
+func exportCommitMessage(cm *engine.CommitMessageConfig) *CommitMessage {
+	if cm == nil {
+		return nil
+	}
+
+	result := &CommitMessage{
+		Prefix:            cm.Prefix,
+		PrefixDevelopment: cm.PrefixDevelopment,
+	}
+	if cm.IncludeScope {
+		result.Include = "scope"
+	}
+	return result
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package dependabot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/policy"
+)
+
+func TestGetEcosystem(t *testing.T) {
+	tests := []struct {
+		id   string
+		want string
+		ok   bool
+	}{
+		{testActions, ecosystemGitHubActions, true},
+		{"docker", "docker", true},
+		{testNpm, testNpm, true},
+		{"gitsubmodule", "gitsubmodule", true},
+		{"precommit", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := GetEcosystem(tt.id)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("GetEcosystem(%q) = %q, %v; want %q, %v", tt.id, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// writeAndLoad marshals config, writes it as dependabot.yml, and loads it
+// back through LoadConfig.
+func writeAndLoad(t *testing.T, config *Config) *Config {
+	t.Helper()
+	data, err := config.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), "version: 2\nupdates:\n    - package-ecosystem: ") {
+		t.Errorf("Marshal() does not lead with version and package-ecosystem:\n%s", data)
+	}
+
+	path := filepath.Join(t.TempDir(), "dependabot.yml")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v\n%s", err, data)
+	}
+	return loaded
+}
+
+func TestExportFromUptool_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourcePath := filepath.Join(tmpDir, "dependabot.yml")
+	if err := os.WriteFile(sourcePath, []byte(completeExampleConfig), 0o644); err != nil {
+		t.Fatalf("failed to create test config: %v", err)
+	}
+	original, err := LoadConfig(sourcePath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	exported, report := ExportFromUptool(original.MigrateToUptool())
+	if want := []string{testGomod, ecosystemGitHubActions}; !reflect.DeepEqual(report.EcosystemsExported, want) {
+		t.Errorf("EcosystemsExported = %v, want %v", report.EcosystemsExported, want)
+	}
+
+	loaded := writeAndLoad(t, exported)
+	if len(loaded.Updates) != 2 {
+		t.Fatalf("len(Updates) = %d, want 2", len(loaded.Updates))
+	}
+
+	gomod := loaded.Updates[0]
+	if gomod.PackageEcosystem != testGomod || gomod.Directory != "/" {
+		t.Errorf("gomod update = %s in %q, want gomod in /", gomod.PackageEcosystem, gomod.Directory)
+	}
+	if !reflect.DeepEqual(gomod.Groups, original.Updates[0].Groups) {
+		t.Errorf("gomod Groups = %+v, want %+v", gomod.Groups, original.Updates[0].Groups)
+	}
+	if gomod.Schedule != original.Updates[0].Schedule {
+		t.Errorf("gomod Schedule = %+v, want %+v", gomod.Schedule, original.Updates[0].Schedule)
+	}
+	if gomod.OpenPullRequestsLimit != 5 {
+		t.Errorf("gomod OpenPullRequestsLimit = %d, want 5", gomod.OpenPullRequestsLimit)
+	}
+	if !reflect.DeepEqual(gomod.CommitMessage, original.Updates[0].CommitMessage) {
+		t.Errorf("gomod CommitMessage = %+v, want %+v", gomod.CommitMessage, original.Updates[0].CommitMessage)
+	}
+	if !reflect.DeepEqual(gomod.Reviewers, original.Updates[0].Reviewers) {
+		t.Errorf("gomod Reviewers = %v, want %v", gomod.Reviewers, original.Updates[0].Reviewers)
+	}
+
+	actions := loaded.Updates[1]
+	if actions.PackageEcosystem != ecosystemGitHubActions || actions.Directory != "/" {
+		t.Errorf("actions update = %s in %q, want github-actions in /", actions.PackageEcosystem, actions.Directory)
+	}
+	if _, ok := actions.Groups["github-actions"]; !ok {
+		t.Errorf("actions Groups = %+v, want github-actions group", actions.Groups)
+	}
+}
+
+func TestExportFromUptool(t *testing.T) {
+	cfg := &policy.Config{
+		Version: 1,
+		Integrations: []policy.IntegrationConfig{
+			{
+				ID:      testNpm,
+				Enabled: true,
+				Match: &policy.MatchConfig{
+					Files:   []string{"package.json", "apps/web/package.json", "apps/web/package.json"},
+					Exclude: []string{"examples/**"},
+				},
+				Policy: engine.IntegrationPolicy{
+					Update:                "patch",
+					Cadence:               "monthly",
+					OpenPullRequestsLimit: 25,
+					AllowPrerelease:       true,
+					Ignore: []engine.IgnoreRule{
+						{DependencyName: "react", Versions: []string{">=19"}},
+					},
+				},
+			},
+			{
+				ID:      "docker",
+				Enabled: true,
+				Policy: engine.IntegrationPolicy{
+					Schedule: &engine.Schedule{Interval: "cron"},
+				},
+			},
+			{ID: "precommit", Enabled: true},
+			{ID: "helm", Enabled: false},
+		},
+	}
+
+	exported, report := ExportFromUptool(cfg)
+	loaded := writeAndLoad(t, exported)
+	if len(loaded.Updates) != 2 {
+		t.Fatalf("len(Updates) = %d, want 2", len(loaded.Updates))
+	}
+
+	npm := loaded.Updates[0]
+	if want := []string{"/", "/apps/web"}; !reflect.DeepEqual(npm.Directories, want) {
+		t.Errorf("npm Directories = %v, want %v", npm.Directories, want)
+	}
+	if want := []string{"examples/**"}; !reflect.DeepEqual(npm.ExcludePaths, want) {
+		t.Errorf("npm ExcludePaths = %v, want %v", npm.ExcludePaths, want)
+	}
+	if npm.Schedule.Interval != "monthly" {
+		t.Errorf("npm Schedule.Interval = %q, want monthly", npm.Schedule.Interval)
+	}
+	if npm.OpenPullRequestsLimit != maxOpenPullRequestsLimit {
+		t.Errorf("npm OpenPullRequestsLimit = %d, want %d", npm.OpenPullRequestsLimit, maxOpenPullRequestsLimit)
+	}
+	wantIgnore := []IgnoreRule{
+		{DependencyName: "react", Versions: []string{">=19"}},
+		{DependencyName: "*", UpdateTypes: []string{"version-update:semver-major", "version-update:semver-minor"}},
+	}
+	if !reflect.DeepEqual(npm.Ignore, wantIgnore) {
+		t.Errorf("npm Ignore = %+v, want %+v", npm.Ignore, wantIgnore)
+	}
+
+	// A cron schedule without an expression is not valid for Dependabot
+	if docker := loaded.Updates[1]; docker.Schedule.Interval != intervalWeekly {
+		t.Errorf("docker Schedule.Interval = %q, want weekly", docker.Schedule.Interval)
+	}
+
+	wantSkipped := []string{"precommit: no Dependabot package-ecosystem", "helm: disabled"}
+	if !reflect.DeepEqual(report.Skipped, wantSkipped) {
+		t.Errorf("Skipped = %v, want %v", report.Skipped, wantSkipped)
+	}
+	wantUntranslated := []string{
+		"npm: open_pull_requests_limit 25 lowered to 10",
+		"npm: allow_prerelease and prerelease_channels",
+		"docker: schedule interval cron exported as weekly",
+	}
+	if !reflect.DeepEqual(report.Untranslated, wantUntranslated) {
+		t.Errorf("Untranslated = %v, want %v", report.Untranslated, wantUntranslated)
+	}
+}
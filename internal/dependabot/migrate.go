@@ -306,6 +306,26 @@ func checkUnsupportedFeatures(update *UpdateConfig, report *MigrationReport) {
 			update.PackageEcosystem+": vendor mode requires manual configuration")
 	}
 
+	// Settings with no uptool equivalent are dropped from the output
+	untranslated := []struct {
+		key string
+		set bool
+	}{
+		{"target-branch", update.TargetBranch != ""},
+		{"rebase-strategy", update.RebaseStrategy != ""},
+		{"pull-request-branch-name", update.PullRequestBranchName != nil},
+		{"milestone", update.Milestone != 0},
+		{"insecure-external-code-execution", update.InsecureExternalCodeExecution != ""},
+		{"registries", update.Registries != nil},
+		{"multi-ecosystem-group", update.MultiEcosystemGroup != ""},
+	}
+	for _, u := range untranslated {
+		if u.set {
+			report.UnsupportedFeatures = append(report.UnsupportedFeatures,
+				update.PackageEcosystem+": "+u.key+" is not translated")
+		}
+	}
+
 	// Check if integration is supported
 	if _, ok := EcosystemToIntegration[update.PackageEcosystem]; !ok {
 		report.Warnings = append(report.Warnings,
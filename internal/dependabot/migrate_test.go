@@ -23,7 +23,12 @@ package dependabot
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santosr2/uptool/internal/policy"
 )
 
 const (
@@ -362,7 +367,7 @@ func TestMigrateToUptool_AllEcosystems(t *testing.T) {
 		{"maven", "maven"},
 		{"gradle", "gradle"},
 		{"nuget", "nuget"},
-		{"mix", "hex"},
+		{"mix", "mix"},
 		{"pub", "pub"},
 		{"swift", "swift"},
 	}
@@ -469,3 +474,92 @@ updates:
 		t.Errorf("len(EcosystemsMigrated) = %d, want 2", len(report.EcosystemsMigrated))
 	}
 }
+
+func TestMigrateWithReport_RoundTrip(t *testing.T) {
+	tmpDir := t.TempDir()
+	sourcePath := filepath.Join(tmpDir, "dependabot.yml")
+	if err := os.WriteFile(sourcePath, []byte(completeExampleConfig), 0o644); err != nil {
+		t.Fatalf("failed to create test config: %v", err)
+	}
+
+	depConfig, err := LoadConfig(sourcePath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	migrated, report := depConfig.MigrateWithReport(sourcePath)
+	if len(report.UnsupportedFeatures) != 0 {
+		t.Errorf("UnsupportedFeatures = %v, want none", report.UnsupportedFeatures)
+	}
+
+	data, err := yaml.Marshal(migrated)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	outputPath := filepath.Join(tmpDir, "uptool.yaml")
+	if err := os.WriteFile(outputPath, data, 0o644); err != nil {
+		t.Fatalf("failed to write uptool.yaml: %v", err)
+	}
+
+	loaded, err := policy.LoadConfig(outputPath)
+	if err != nil {
+		t.Fatalf("policy.LoadConfig() error = %v\n%s", err, data)
+	}
+
+	if !reflect.DeepEqual(loaded.Integrations, migrated.Integrations) {
+		t.Errorf("round trip changed integrations:\ngot  %+v\nwant %+v", loaded.Integrations, migrated.Integrations)
+	}
+
+	gomod := loaded.ToPolicyMap()[testGomod]
+	if gomod.Schedule == nil || gomod.Schedule.Interval != testWeekly || gomod.Schedule.Day != "monday" {
+		t.Errorf("gomod Schedule = %+v, want weekly on monday", gomod.Schedule)
+	}
+	if gomod.OpenPullRequestsLimit != 5 {
+		t.Errorf("gomod OpenPullRequestsLimit = %d, want 5", gomod.OpenPullRequestsLimit)
+	}
+	if want := []string{"dependencies", "go", "dependabot"}; !reflect.DeepEqual(gomod.Labels, want) {
+		t.Errorf("gomod Labels = %v, want %v", gomod.Labels, want)
+	}
+	if want := []string{"santosr2"}; !reflect.DeepEqual(gomod.Reviewers, want) {
+		t.Errorf("gomod Reviewers = %v, want %v", gomod.Reviewers, want)
+	}
+	if cm := gomod.CommitMessage; cm == nil || cm.Prefix != "deps" || cm.PrefixDevelopment != "deps(dev)" || !cm.IncludeScope {
+		t.Errorf("gomod CommitMessage = %+v, want deps/deps(dev) with scope", cm)
+	}
+	group, ok := gomod.Groups["go-dependencies"]
+	if !ok {
+		t.Fatalf("gomod Groups = %v, want go-dependencies", gomod.Groups)
+	}
+	if want := []string{"minor", "patch"}; !reflect.DeepEqual(group.UpdateTypes, want) {
+		t.Errorf("go-dependencies UpdateTypes = %v, want %v", group.UpdateTypes, want)
+	}
+}
+
+func TestMigrateWithReport_UntranslatedSettings(t *testing.T) {
+	config := &Config{
+		Version: 2,
+		Updates: []UpdateConfig{
+			{
+				PackageEcosystem:      testNpm,
+				Directory:             "/",
+				Schedule:              Schedule{Interval: testWeekly},
+				TargetBranch:          "develop",
+				RebaseStrategy:        "disabled",
+				PullRequestBranchName: &BranchName{Separator: "-"},
+				Milestone:             4,
+			},
+		},
+	}
+
+	_, report := config.MigrateWithReport("dependabot.yml")
+
+	want := []string{
+		"npm: target-branch is not translated",
+		"npm: rebase-strategy is not translated",
+		"npm: pull-request-branch-name is not translated",
+		"npm: milestone is not translated",
+	}
+	if !reflect.DeepEqual(report.UnsupportedFeatures, want) {
+		t.Errorf("UnsupportedFeatures = %v, want %v", report.UnsupportedFeatures, want)
+	}
+}
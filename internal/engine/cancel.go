@@ -0,0 +1,58 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+)
+
+// acquire waits for a free worker slot in sem. It returns false without a
+// slot when ctx is done first, so queued work is abandoned once a deadline
+// passes or the run is cancelled.
+func acquire(ctx context.Context, sem chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case sem <- struct{}{}:
+		// Both cases may be ready at once; never start work after cancellation
+		if ctx.Err() != nil {
+			<-sem
+			return false
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// abortedError describes work abandoned because ctx is done, e.g.
+// "plan aborted: context deadline exceeded (3 manifests not planned)".
+func abortedError(ctx context.Context, operation string, skipped int, unit string) string {
+	return fmt.Sprintf("%s aborted: %v (%d %s not %s)", operation, context.Cause(ctx), skipped, unit, pastTense[operation])
+}
+
+var pastTense = map[string]string{
+	"scan":   "scanned",
+	"plan":   "planned",
+	"update": "updated",
+}
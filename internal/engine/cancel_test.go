@@ -0,0 +1,132 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowPlanIntegration takes delay to plan each manifest.
+type slowPlanIntegration struct {
+	mockIntegration
+	delay time.Duration
+}
+
+func (s *slowPlanIntegration) Plan(ctx context.Context, manifest *Manifest, planCtx *PlanContext) (*UpdatePlan, error) {
+	time.Sleep(s.delay)
+	return s.mockIntegration.Plan(ctx, manifest, planCtx)
+}
+
+func TestScan_Timeout(t *testing.T) {
+	e := NewEngine(nil)
+	if err := e.SetConcurrency(1); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := &concurrencyTracker{}
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("integration-%d", i)
+		e.Register(&slowMockIntegration{
+			mockIntegration: mockIntegration{
+				name:            name,
+				detectManifests: []*Manifest{{Path: name + ".yaml", Type: name}},
+			},
+			delay:              40 * time.Millisecond,
+			concurrencyTracker: tracker,
+		})
+	}
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 60*time.Millisecond, errors.New("timed out after 60ms"))
+	defer cancel()
+
+	start := time.Now()
+	result, err := e.Scan(ctx, "/test", nil, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v, want partial results", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Scan() took %s, want it to stop shortly after the deadline", elapsed)
+	}
+
+	if n := len(result.Manifests); n == 0 || n == 5 {
+		t.Errorf("Scan() found %d manifests, want partial results", n)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "scan aborted: timed out after 60ms") {
+		t.Fatalf("Scan() errors = %v, want one timeout error", result.Errors)
+	}
+	want := fmt.Sprintf("(%d integrations not scanned)", 5-len(result.Manifests))
+	if !strings.HasSuffix(result.Errors[0], want) {
+		t.Errorf("Scan() error = %q, want suffix %q", result.Errors[0], want)
+	}
+}
+
+func TestPlan_Timeout(t *testing.T) {
+	e := NewEngine(nil)
+	if err := e.SetConcurrency(2); err != nil {
+		t.Fatal(err)
+	}
+	e.Register(&slowPlanIntegration{mockIntegration: mockIntegration{name: "slow"}, delay: 40 * time.Millisecond})
+
+	manifests := make([]*Manifest, 8)
+	for i := range manifests {
+		manifests[i] = &Manifest{Path: fmt.Sprintf("m%d.yaml", i), Type: "slow"}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Millisecond)
+	defer cancel()
+
+	result, err := e.Plan(ctx, manifests)
+	if err != nil {
+		t.Fatalf("Plan() error = %v, want partial results", err)
+	}
+	if n := len(result.Plans); n == 0 || n == len(manifests) {
+		t.Errorf("Plan() returned %d plans, want partial results", n)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "plan aborted: context deadline exceeded") {
+		t.Errorf("Plan() errors = %v, want one timeout error", result.Errors)
+	}
+}
+
+func TestUpdate_Cancelled(t *testing.T) {
+	e := NewEngine(nil)
+	integ := &mockIntegration{name: "mock"}
+	e.Register(integ)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	plans := []*UpdatePlan{{Manifest: &Manifest{Path: "a.yaml", Type: "mock"}}}
+	result, err := e.Update(ctx, plans, false)
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if integ.applyCalls != 0 {
+		t.Errorf("Update() applied %d plans after cancellation, want 0", integ.applyCalls)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "update aborted: context canceled (1 manifests not updated)") {
+		t.Errorf("Update() errors = %v, want one cancellation error", result.Errors)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import "strings"
+
+// CollapsedUpdate is one update of a dependency from one version to another,
+// listed once with every manifest it is planned in.
+type CollapsedUpdate struct {
+	Name           string   `json:"name"`
+	Integration    string   `json:"integration"`
+	CurrentVersion string   `json:"current_version"`
+	TargetVersion  string   `json:"target_version"`
+	Impact         string   `json:"impact"`
+	Manifests      []string `json:"manifests"`
+}
+
+// CollapseDuplicates merges the updates of plans that move the same
+// dependency of the same integration between the same versions, as in a
+// monorepo where many manifests pin the same package. Updates keep the order
+// in which they are first seen, and manifests the order of plans.
+func CollapseDuplicates(plans []*UpdatePlan) []CollapsedUpdate {
+	var collapsed []CollapsedUpdate
+	index := make(map[string]int)
+
+	for _, plan := range plans {
+		for i := range plan.Updates {
+			update := &plan.Updates[i]
+			key := strings.Join([]string{
+				plan.Manifest.Type, update.Dependency.Name, update.Dependency.CurrentVersion, update.TargetVersion,
+			}, "\x00")
+
+			if n, ok := index[key]; ok {
+				c := &collapsed[n]
+				if c.Manifests[len(c.Manifests)-1] != plan.Manifest.Path {
+					c.Manifests = append(c.Manifests, plan.Manifest.Path)
+				}
+				continue
+			}
+
+			index[key] = len(collapsed)
+			collapsed = append(collapsed, CollapsedUpdate{
+				Name:           update.Dependency.Name,
+				Integration:    plan.Manifest.Type,
+				CurrentVersion: update.Dependency.CurrentVersion,
+				TargetVersion:  update.TargetVersion,
+				Impact:         update.Impact,
+				Manifests:      []string{plan.Manifest.Path},
+			})
+		}
+	}
+
+	return collapsed
+}
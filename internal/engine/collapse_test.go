@@ -0,0 +1,71 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollapseDuplicates(t *testing.T) {
+	lodash := Update{Dependency: Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21", Impact: "patch"}
+	plans := []*UpdatePlan{
+		{Manifest: &Manifest{Path: "apps/web/package.json", Type: "npm"}, Updates: []Update{
+			lodash,
+			{Dependency: Dependency{Name: "react", CurrentVersion: "17.0.2"}, TargetVersion: "18.2.0", Impact: "major"},
+		}},
+		{Manifest: &Manifest{Path: "apps/api/package.json", Type: "npm"}, Updates: []Update{lodash}},
+		{Manifest: &Manifest{Path: "libs/ui/package.json", Type: "npm"}, Updates: []Update{
+			lodash,
+			// Same dependency from a different version stays separate
+			{Dependency: Dependency{Name: "react", CurrentVersion: "18.0.0"}, TargetVersion: "18.2.0", Impact: "patch"},
+		}},
+		// Same name in another ecosystem is a different dependency
+		{Manifest: &Manifest{Path: "Cargo.toml", Type: "cargo"}, Updates: []Update{lodash}},
+		{Manifest: &Manifest{Path: "go.mod", Type: "gomod"}},
+	}
+
+	want := []CollapsedUpdate{
+		{
+			Name: "lodash", Integration: "npm", CurrentVersion: "4.17.20", TargetVersion: "4.17.21", Impact: "patch",
+			Manifests: []string{"apps/web/package.json", "apps/api/package.json", "libs/ui/package.json"},
+		},
+		{
+			Name: "react", Integration: "npm", CurrentVersion: "17.0.2", TargetVersion: "18.2.0", Impact: "major",
+			Manifests: []string{"apps/web/package.json"},
+		},
+		{
+			Name: "react", Integration: "npm", CurrentVersion: "18.0.0", TargetVersion: "18.2.0", Impact: "patch",
+			Manifests: []string{"libs/ui/package.json"},
+		},
+		{
+			Name: "lodash", Integration: "cargo", CurrentVersion: "4.17.20", TargetVersion: "4.17.21", Impact: "patch",
+			Manifests: []string{"Cargo.toml"},
+		},
+	}
+
+	if got := CollapseDuplicates(plans); !reflect.DeepEqual(got, want) {
+		t.Errorf("CollapseDuplicates() =\n%+v\nwant\n%+v", got, want)
+	}
+	if got := CollapseDuplicates(nil); got != nil {
+		t.Errorf("CollapseDuplicates(nil) = %+v, want nil", got)
+	}
+}
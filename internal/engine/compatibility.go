@@ -0,0 +1,101 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"strings"
+	"time"
+)
+
+// Base compatibility scores by update impact, before adjustments.
+var impactScores = map[Impact]int{
+	ImpactNone:  100,
+	ImpactPatch: 90,
+	ImpactMinor: 75,
+	ImpactMajor: 45,
+}
+
+// unknownImpactScore is the base score of an update with an unknown impact.
+const unknownImpactScore = 60
+
+// breakingPenalty is subtracted when the update or its release notes
+// announce a breaking change.
+const breakingPenalty = 25
+
+// breakingMarkers are phrases in release notes that announce a breaking
+// change. "BREAKING" is matched case-sensitively, the rest in any case.
+var breakingMarkers = []string{
+	"breaking change",
+	"backwards incompatible",
+	"backward incompatible",
+	"backwards-incompatible",
+	"backward-incompatible",
+}
+
+// ComputeCompatibilityScore estimates how likely an update is to apply
+// without breaking the project, from 0 (almost certainly breaks) to 100.
+//
+// The score starts from the update's impact (patch high, major low), loses
+// breakingPenalty when the update is flagged breaking or notes mention a
+// breaking change, and is adjusted by how long the target version has been
+// released: versions out for a day or less lose points, versions proven for
+// a month or more gain some. A releaseAge of zero means the release time is
+// unknown and leaves the score unadjusted.
+func ComputeCompatibilityScore(update Update, releaseAge time.Duration, notes string) int {
+	score, ok := impactScores[Impact(strings.ToLower(update.Impact))]
+	if !ok {
+		score = unknownImpactScore
+	}
+
+	if update.Breaking || mentionsBreakingChange(notes) {
+		score -= breakingPenalty
+	}
+
+	const day = 24 * time.Hour
+	switch {
+	case releaseAge <= 0:
+	case releaseAge < day:
+		score -= 10
+	case releaseAge < 7*day:
+		score -= 5
+	case releaseAge >= 90*day:
+		score += 10
+	case releaseAge >= 30*day:
+		score += 5
+	}
+
+	return min(max(score, 0), 100)
+}
+
+// mentionsBreakingChange reports whether release notes announce a breaking
+// change.
+func mentionsBreakingChange(notes string) bool {
+	if strings.Contains(notes, "BREAKING") {
+		return true
+	}
+	lower := strings.ToLower(notes)
+	for _, marker := range breakingMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeCompatibilityScore(t *testing.T) {
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		update Update
+		age    time.Duration
+		notes  string
+		want   int
+	}{
+		{"patch", Update{Impact: "patch"}, 0, "", 90},
+		{"minor", Update{Impact: "minor"}, 0, "", 75},
+		{"major", Update{Impact: "major"}, 0, "", 45},
+		{"unknown impact", Update{Impact: "digest"}, 0, "", 60},
+		{"impact is case-insensitive", Update{Impact: "PATCH"}, 0, "", 90},
+		{"breaking change in notes", Update{Impact: "minor"}, 0, "## BREAKING CHANGE\nDropped Node 16", 50},
+		{"breaking change in lower case", Update{Impact: "minor"}, 0, "This is a backwards incompatible release", 50},
+		{"breaking flag", Update{Impact: "major", Breaking: true}, 0, "", 20},
+		{"breaking is not counted twice", Update{Impact: "major", Breaking: true}, 0, "BREAKING: removed API", 20},
+		{"notes without breaking change", Update{Impact: "patch"}, 0, "Fixes a breaking bug in the parser", 90},
+		{"released hours ago", Update{Impact: "patch"}, 3 * time.Hour, "", 80},
+		{"released this week", Update{Impact: "patch"}, 3 * day, "", 85},
+		{"released two weeks ago", Update{Impact: "patch"}, 14 * day, "", 90},
+		{"released last month", Update{Impact: "patch"}, 45 * day, "", 95},
+		{"proven release", Update{Impact: "patch"}, 200 * day, "", 100},
+		{"clamped at 100", Update{Impact: "none"}, 200 * day, "", 100},
+		{"fresh breaking major", Update{Impact: "major"}, time.Hour, "BREAKING CHANGE: config format", 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ComputeCompatibilityScore(tt.update, tt.age, tt.notes); got != tt.want {
+				t.Errorf("ComputeCompatibilityScore() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeCompatibilityScore_Ordering(t *testing.T) {
+	age := 10 * 24 * time.Hour
+	patch := ComputeCompatibilityScore(Update{Impact: "patch"}, age, "")
+	minor := ComputeCompatibilityScore(Update{Impact: "minor"}, age, "")
+	major := ComputeCompatibilityScore(Update{Impact: "major"}, age, "")
+	if patch <= minor || minor <= major {
+		t.Errorf("scores patch/minor/major = %d/%d/%d, want strictly decreasing", patch, minor, major)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+// ConflictPolicy controls what Update does when a file on disk no longer
+// matches the content captured at scan time (manual edits, a rebase, or
+// unresolved merge conflict markers).
+type ConflictPolicy string
+
+// Conflict policies accepted by update --on-conflict.
+const (
+	// ConflictSkip leaves the files untouched and reports the plan's updates as
+	// failed. It applies to manifests as well as lockfiles, so a manifest edited
+	// between scan and update is not rewritten.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite discards the on-disk changes and applies updates to the scanned content.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictMerge applies updates to the scanned content and three-way merges
+	// the result with the on-disk changes.
+	ConflictMerge ConflictPolicy = "merge"
+)
+
+// ParseConflictPolicy validates a conflict policy name.
+// An empty string selects the default, ConflictSkip.
+func ParseConflictPolicy(s string) (ConflictPolicy, error) {
+	switch ConflictPolicy(s) {
+	case "":
+		return ConflictSkip, nil
+	case ConflictSkip, ConflictOverwrite, ConflictMerge:
+		return ConflictPolicy(s), nil
+	default:
+		return "", fmt.Errorf("invalid conflict policy %q (must be skip, overwrite, or merge)", s)
+	}
+}
+
+// SetConflictPolicy configures how Update handles files that changed since scan.
+func (e *Engine) SetConflictPolicy(policy ConflictPolicy) {
+	e.conflictPolicy = policy
+	e.logger.Debug("set conflict policy", "policy", policy)
+}
+
+// captureLockfiles records the scanned content of each manifest's lockfile,
+// so Update can tell whether it changed before rewriting it.
+func captureLockfiles(manifests []*Manifest, repoRoot string) {
+	for _, m := range manifests {
+		lockPath, ok := m.Metadata["lockfile"].(string)
+		if !ok || m.LockfileContent != nil {
+			continue
+		}
+		if content, err := secureio.ReadFile(filepath.Join(repoRoot, lockPath)); err == nil {
+			m.LockfileContent = content
+		}
+	}
+}
+
+// conflictFile is a file Apply may rewrite, with its content at scan time and on disk.
+type conflictFile struct {
+	path     string
+	absPath  string
+	lockfile bool
+	scanned  []byte
+	onDisk   []byte
+	markers  bool
+}
+
+// changed reports whether the file no longer matches its scanned content.
+func (f *conflictFile) changed() bool {
+	return f.markers || !bytes.Equal(f.onDisk, f.scanned)
+}
+
+// reason describes why the file conflicts with the update.
+func (f *conflictFile) reason() string {
+	subject := "manifest"
+	if f.lockfile {
+		subject = "lockfile " + f.path
+	}
+	if f.markers {
+		return subject + " has unresolved conflict markers"
+	}
+	return subject + " changed since scan"
+}
+
+// conflictFiles returns the manifest and, when the plan writes it, the
+// lockfile, for those whose scanned content is known.
+func conflictFiles(p *UpdatePlan) ([]*conflictFile, error) {
+	var files []*conflictFile
+	add := func(path string, scanned []byte, lockfile bool) error {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("resolve path: %w", err)
+		}
+		onDisk, err := secureio.ReadFile(absPath)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", path, err)
+		}
+		files = append(files, &conflictFile{
+			path:     path,
+			absPath:  absPath,
+			lockfile: lockfile,
+			scanned:  scanned,
+			onDisk:   onDisk,
+			markers:  rewrite.HasConflictMarkers(string(onDisk)),
+		})
+		return nil
+	}
+
+	if p.Manifest.Content != nil {
+		if err := add(p.Manifest.Path, p.Manifest.Content, false); err != nil {
+			return nil, err
+		}
+	}
+	if lockPath, ok := p.Manifest.Metadata["lockfile"].(string); ok && p.Manifest.LockfileContent != nil && p.WritesLockfile() {
+		if err := add(lockPath, p.Manifest.LockfileContent, true); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// applyWithConflictPolicy applies a plan, first checking whether the manifest
+// and the lockfile it writes still match their scanned content. Files without
+// scanned content are applied directly since there is nothing to compare against.
+func (e *Engine) applyWithConflictPolicy(ctx context.Context, integration Integration, p *UpdatePlan) (*ApplyResult, error) {
+	files, err := conflictFiles(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []*conflictFile
+	hasMarkers := false
+	for _, f := range files {
+		if f.changed() {
+			changed = append(changed, f)
+			hasMarkers = hasMarkers || f.markers
+		}
+	}
+	if len(changed) == 0 {
+		return integration.Apply(ctx, p)
+	}
+
+	reason := changed[0].reason()
+
+	switch e.conflictPolicy {
+	case ConflictOverwrite:
+		for _, f := range changed {
+			e.logger.Warn("overwriting file", "manifest", p.Manifest.Path, "file", f.path, "reason", f.reason())
+			if err := secureio.WriteFile(f.absPath, f.scanned, 0o600); err != nil {
+				return nil, fmt.Errorf("restore scanned content: %w", err)
+			}
+		}
+		return integration.Apply(ctx, p)
+
+	case ConflictMerge:
+		if hasMarkers {
+			return skippedResult(p, reason+"; resolve them before merging"), nil
+		}
+		return e.mergeApply(ctx, integration, p, files)
+
+	default:
+		e.logger.Warn("skipping file", "manifest", p.Manifest.Path, "reason", reason)
+		return skippedResult(p, reason+"; use --on-conflict=overwrite or merge"), nil
+	}
+}
+
+// mergeApply applies updates to the scanned content of the files and
+// three-way merges each changed file with its on-disk content. On conflict
+// every file is restored to its on-disk content.
+func (e *Engine) mergeApply(ctx context.Context, integration Integration, p *UpdatePlan, files []*conflictFile) (*ApplyResult, error) {
+	restore := func() error {
+		var errs []error
+		for _, f := range files {
+			errs = append(errs, secureio.WriteFile(f.absPath, f.onDisk, 0o600))
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, f := range files {
+		if f.changed() {
+			if err := secureio.WriteFile(f.absPath, f.scanned, 0o600); err != nil {
+				return nil, errors.Join(fmt.Errorf("stage scanned content: %w", err), restore())
+			}
+		}
+	}
+
+	result, err := integration.Apply(ctx, p)
+	if err != nil {
+		return nil, errors.Join(err, restore())
+	}
+
+	merged := make(map[*conflictFile]string)
+	for _, f := range files {
+		if !f.changed() {
+			continue
+		}
+		updated, err := secureio.ReadFile(f.absPath)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("read updated content: %w", err), restore())
+		}
+		content, err := rewrite.Merge3(string(f.scanned), string(updated), string(f.onDisk))
+		if err != nil {
+			if restoreErr := restore(); restoreErr != nil {
+				return nil, fmt.Errorf("restore on-disk content: %w", restoreErr)
+			}
+			e.logger.Warn("merge failed", "manifest", p.Manifest.Path, "file", f.path, "error", err)
+			if f.lockfile {
+				return skippedResult(p, fmt.Sprintf("cannot merge updates with on-disk changes to lockfile %s: %v", f.path, err)), nil
+			}
+			return skippedResult(p, fmt.Sprintf("cannot merge updates with on-disk changes: %v", err)), nil
+		}
+		merged[f] = content
+	}
+
+	for _, f := range files {
+		content, ok := merged[f]
+		if !ok {
+			continue
+		}
+		if err := secureio.WriteFile(f.absPath, []byte(content), 0o600); err != nil {
+			return nil, fmt.Errorf("write merged content: %w", err)
+		}
+
+		diff, err := rewrite.GenerateUnifiedDiff(filepath.Base(f.path), string(f.onDisk), content)
+		if err != nil {
+			return nil, err
+		}
+		if f.lockfile {
+			result.LockfileDiff = diff
+		} else {
+			result.ManifestDiff = diff
+		}
+	}
+
+	e.logger.Info("merged updates with on-disk changes", "manifest", p.Manifest.Path)
+	return result, nil
+}
+
+// skippedResult reports every update in the plan as failed with the given reason.
+func skippedResult(p *UpdatePlan, reason string) *ApplyResult {
+	return &ApplyResult{
+		Manifest: p.Manifest,
+		Failed:   len(p.Updates),
+		Errors:   []string{fmt.Sprintf("skipped: %s", reason)},
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// rewritingIntegration bumps "version: 1.0.0" to "version: 2.0.0" in the manifest file.
+type rewritingIntegration struct {
+	mockIntegration
+}
+
+func (r *rewritingIntegration) Apply(ctx context.Context, plan *UpdatePlan) (*ApplyResult, error) {
+	content, err := os.ReadFile(plan.Manifest.Path)
+	if err != nil {
+		return nil, err
+	}
+	updated := strings.Replace(string(content), "version: 1.0.0", "version: 2.0.0", 1)
+	if err := os.WriteFile(plan.Manifest.Path, []byte(updated), 0o600); err != nil {
+		return nil, err
+	}
+	return &ApplyResult{Manifest: plan.Manifest, Applied: 1}, nil
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ConflictPolicy
+		wantErr bool
+	}{
+		{input: "", want: ConflictSkip},
+		{input: "skip", want: ConflictSkip},
+		{input: "overwrite", want: ConflictOverwrite},
+		{input: "merge", want: ConflictMerge},
+		{input: "force", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseConflictPolicy(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseConflictPolicy(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseConflictPolicy(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUpdate_ConflictPolicy(t *testing.T) {
+	const scanned = "name: app\nversion: 1.0.0\ndescription: demo\n"
+	const edited = "name: app\nversion: 1.0.0\ndescription: edited by hand\n"
+	const conflicted = "name: app\n<<<<<<< HEAD\nversion: 1.0.0\n=======\nversion: 1.1.0\n>>>>>>> feature\ndescription: demo\n"
+
+	tests := []struct {
+		name        string
+		policy      ConflictPolicy
+		onDisk      string
+		wantContent string
+		wantApplied int
+		wantFailed  int
+	}{
+		{
+			name:        "skip unchanged file applies",
+			policy:      ConflictSkip,
+			onDisk:      scanned,
+			wantContent: "name: app\nversion: 2.0.0\ndescription: demo\n",
+			wantApplied: 1,
+		},
+		{
+			name:        "skip drifted file",
+			policy:      ConflictSkip,
+			onDisk:      edited,
+			wantContent: edited,
+			wantFailed:  1,
+		},
+		{
+			name:        "skip file with conflict markers",
+			policy:      ConflictSkip,
+			onDisk:      conflicted,
+			wantContent: conflicted,
+			wantFailed:  1,
+		},
+		{
+			name:        "overwrite drifted file",
+			policy:      ConflictOverwrite,
+			onDisk:      edited,
+			wantContent: "name: app\nversion: 2.0.0\ndescription: demo\n",
+			wantApplied: 1,
+		},
+		{
+			name:        "overwrite file with conflict markers",
+			policy:      ConflictOverwrite,
+			onDisk:      conflicted,
+			wantContent: "name: app\nversion: 2.0.0\ndescription: demo\n",
+			wantApplied: 1,
+		},
+		{
+			name:        "merge drifted file keeps manual edit",
+			policy:      ConflictMerge,
+			onDisk:      edited,
+			wantContent: "name: app\nversion: 2.0.0\ndescription: edited by hand\n",
+			wantApplied: 1,
+		},
+		{
+			name:        "merge conflicting edit restores file",
+			policy:      ConflictMerge,
+			onDisk:      "name: app\nversion: 1.5.0\ndescription: demo\n",
+			wantContent: "name: app\nversion: 1.5.0\ndescription: demo\n",
+			wantFailed:  1,
+		},
+		{
+			name:        "merge refuses file with conflict markers",
+			policy:      ConflictMerge,
+			onDisk:      conflicted,
+			wantContent: conflicted,
+			wantFailed:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.yaml")
+			if err := os.WriteFile(path, []byte(tt.onDisk), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			e := NewEngine(nil)
+			e.Register(&rewritingIntegration{mockIntegration: mockIntegration{name: "mock"}})
+			e.SetConflictPolicy(tt.policy)
+
+			plan := &UpdatePlan{
+				Manifest: &Manifest{Path: path, Type: "mock", Content: []byte(scanned)},
+				Updates: []Update{
+					{Dependency: Dependency{Name: "app", CurrentVersion: "1.0.0"}, TargetVersion: "2.0.0"},
+				},
+			}
+
+			result, err := e.Update(context.Background(), []*UpdatePlan{plan}, false)
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if len(result.Results) != 1 {
+				t.Fatalf("Update() results = %d, want 1 (errors: %v)", len(result.Results), result.Errors)
+			}
+
+			got := result.Results[0]
+			if got.Applied != tt.wantApplied || got.Failed != tt.wantFailed {
+				t.Errorf("Update() applied/failed = %d/%d, want %d/%d (errors: %v)",
+					got.Applied, got.Failed, tt.wantApplied, tt.wantFailed, got.Errors)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(content) != tt.wantContent {
+				t.Errorf("file content = %q, want %q", content, tt.wantContent)
+			}
+		})
+	}
+}
+
+// lockingIntegration bumps "version: 1.0.0" to "version: 2.0.0" in the
+// manifest and in its lockfile.
+type lockingIntegration struct {
+	rewritingIntegration
+}
+
+func (l *lockingIntegration) Apply(ctx context.Context, plan *UpdatePlan) (*ApplyResult, error) {
+	result, err := l.rewritingIntegration.Apply(ctx, plan)
+	if err != nil || !plan.WritesLockfile() {
+		return result, err
+	}
+	lockPath := plan.Manifest.Metadata["lockfile"].(string)
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+	updated := strings.Replace(string(content), "version: 1.0.0", "version: 2.0.0", 1)
+	return result, os.WriteFile(lockPath, []byte(updated), 0o600)
+}
+
+func TestUpdate_ConflictPolicyLockfile(t *testing.T) {
+	const manifest = "name: app\nversion: 1.0.0\n"
+	const scanned = "app:\n  version: 1.0.0\n  integrity: abc\n"
+	const edited = "app:\n  version: 1.0.0\n  integrity: abc\n# regenerated\n"
+
+	tests := []struct {
+		name         string
+		policy       ConflictPolicy
+		mode         LockfileMode
+		manifestDisk string
+		onDisk       string
+		wantManifest string
+		wantLockfile string
+		wantApplied  int
+		wantError    string
+	}{
+		{
+			name:         "skip drifted lockfile",
+			policy:       ConflictSkip,
+			onDisk:       edited,
+			wantManifest: manifest,
+			wantLockfile: edited,
+			wantError:    "lockfile",
+		},
+		{
+			name:         "overwrite drifted lockfile",
+			policy:       ConflictOverwrite,
+			onDisk:       edited,
+			wantManifest: "name: app\nversion: 2.0.0\n",
+			wantLockfile: "app:\n  version: 2.0.0\n  integrity: abc\n",
+			wantApplied:  1,
+		},
+		{
+			name:         "merge drifted lockfile keeps its edit",
+			policy:       ConflictMerge,
+			onDisk:       edited,
+			wantManifest: "name: app\nversion: 2.0.0\n",
+			wantLockfile: "app:\n  version: 2.0.0\n  integrity: abc\n# regenerated\n",
+			wantApplied:  1,
+		},
+		{
+			name:         "merge conflicting lockfile restores both files",
+			policy:       ConflictMerge,
+			onDisk:       "app:\n  version: 1.5.0\n  integrity: abc\n",
+			wantManifest: manifest,
+			wantLockfile: "app:\n  version: 1.5.0\n  integrity: abc\n",
+			wantError:    "lockfile",
+		},
+		{
+			name:         "skip drifted manifest with unchanged lockfile",
+			policy:       ConflictSkip,
+			manifestDisk: "name: app\nversion: 1.0.0\n# pinned\n",
+			onDisk:       scanned,
+			wantManifest: "name: app\nversion: 1.0.0\n# pinned\n",
+			wantLockfile: scanned,
+			wantError:    "manifest changed since scan",
+		},
+		{
+			name:         "lockfile left alone is not checked",
+			policy:       ConflictSkip,
+			mode:         LockfileSkip,
+			onDisk:       edited,
+			wantManifest: "name: app\nversion: 2.0.0\n",
+			wantLockfile: edited,
+			wantApplied:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "manifest.yaml")
+			lockPath := filepath.Join(dir, "manifest.lock")
+			manifestDisk := tt.manifestDisk
+			if manifestDisk == "" {
+				manifestDisk = manifest
+			}
+			if err := os.WriteFile(path, []byte(manifestDisk), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(lockPath, []byte(tt.onDisk), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			e := NewEngine(nil)
+			e.Register(&lockingIntegration{rewritingIntegration{mockIntegration: mockIntegration{name: "mock"}}})
+			e.SetConflictPolicy(tt.policy)
+			e.SetLockfileMode(tt.mode)
+
+			plan := &UpdatePlan{
+				Manifest: &Manifest{
+					Path:            path,
+					Type:            "mock",
+					Content:         []byte(manifest),
+					Metadata:        map[string]interface{}{"lockfile": lockPath},
+					LockfileContent: []byte(scanned),
+				},
+				Updates: []Update{
+					{Dependency: Dependency{Name: "app", CurrentVersion: "1.0.0"}, TargetVersion: "2.0.0"},
+				},
+			}
+
+			result, err := e.Update(context.Background(), []*UpdatePlan{plan}, false)
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if len(result.Results) != 1 {
+				t.Fatalf("Update() results = %d, want 1 (errors: %v)", len(result.Results), result.Errors)
+			}
+
+			got := result.Results[0]
+			if got.Applied != tt.wantApplied {
+				t.Errorf("Update() applied = %d, want %d (errors: %v)", got.Applied, tt.wantApplied, got.Errors)
+			}
+			if tt.wantError != "" && (len(got.Errors) != 1 || !strings.Contains(got.Errors[0], tt.wantError)) {
+				t.Errorf("Update() errors = %v, want one mentioning %q", got.Errors, tt.wantError)
+			}
+
+			for file, want := range map[string]string{path: tt.wantManifest, lockPath: tt.wantLockfile} {
+				content, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(content) != want {
+					t.Errorf("%s = %q, want %q", filepath.Base(file), content, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCaptureLockfiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), []byte("sums\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	withLock := &Manifest{Path: "go.mod", Metadata: map[string]interface{}{"lockfile": "go.sum"}}
+	missing := &Manifest{Path: "sub/go.mod", Metadata: map[string]interface{}{"lockfile": "sub/go.sum"}}
+	captureLockfiles([]*Manifest{withLock, missing, {Path: "package.json"}}, dir)
+
+	if string(withLock.LockfileContent) != "sums\n" {
+		t.Errorf("LockfileContent = %q, want %q", withLock.LockfileContent, "sums\n")
+	}
+	if missing.LockfileContent != nil {
+		t.Errorf("LockfileContent of a missing lockfile = %q, want nil", missing.LockfileContent)
+	}
+}
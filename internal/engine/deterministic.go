@@ -0,0 +1,73 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"sort"
+	"time"
+)
+
+// SetDeterministic makes results reproducible byte for byte: operations run one
+// at a time, plans, results, and errors are sorted, and result timestamps are
+// pinned to the zero time.
+func (e *Engine) SetDeterministic(deterministic bool) {
+	e.deterministic = deterministic
+	if deterministic {
+		e.concurrency = 1
+	}
+	e.logger.Debug("set deterministic", "enabled", deterministic)
+}
+
+// timestamp returns the time recorded on a result.
+func (e *Engine) timestamp() time.Time {
+	if e.deterministic {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// sortManifests orders manifests by integration, then path.
+func sortManifests(manifests []*Manifest) {
+	sort.SliceStable(manifests, func(i, j int) bool {
+		return manifestLess(manifests[i], manifests[j])
+	})
+}
+
+// sortPlans orders plans by the manifest they update.
+func sortPlans(plans []*UpdatePlan) {
+	sort.SliceStable(plans, func(i, j int) bool {
+		return manifestLess(plans[i].Manifest, plans[j].Manifest)
+	})
+}
+
+// sortApplyResults orders apply results by the manifest they updated.
+func sortApplyResults(results []*ApplyResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		return manifestLess(results[i].Manifest, results[j].Manifest)
+	})
+}
+
+func manifestLess(a, b *Manifest) bool {
+	if a.Type != b.Type {
+		return a.Type < b.Type
+	}
+	return a.Path < b.Path
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDeterministic(t *testing.T) {
+	e := NewEngine(nil)
+	e.SetDeterministic(true)
+	if e.concurrency != 1 {
+		t.Errorf("concurrency = %d, want 1", e.concurrency)
+	}
+
+	e.Register(&mockIntegration{
+		name: "npm",
+		detectManifests: []*Manifest{
+			{Path: "web/package.json", Type: "npm"},
+			{Path: "api/package.json", Type: "npm"},
+		},
+		planUpdates: []Update{{Dependency: Dependency{Name: "express"}, TargetVersion: "4.18.2"}},
+	})
+	e.Register(&mockIntegration{
+		name:            "helm",
+		detectManifests: []*Manifest{{Path: "chart/Chart.yaml", Type: "helm"}},
+		planUpdates:     []Update{{Dependency: Dependency{Name: "redis"}, TargetVersion: "18.0.0"}},
+	})
+
+	ctx := context.Background()
+	scan, err := e.Scan(ctx, "/test", nil, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	wantPaths := []string{"chart/Chart.yaml", "api/package.json", "web/package.json"}
+	for i, m := range scan.Manifests {
+		if m.Path != wantPaths[i] {
+			t.Errorf("Manifests[%d] = %s, want %s", i, m.Path, wantPaths[i])
+		}
+	}
+	if !scan.Timestamp.IsZero() {
+		t.Errorf("scan Timestamp = %v, want zero", scan.Timestamp)
+	}
+
+	// Reverse the manifests so plans complete out of order
+	manifests := []*Manifest{scan.Manifests[2], scan.Manifests[1], scan.Manifests[0]}
+	plan, err := e.Plan(ctx, manifests)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Plans) != len(wantPaths) {
+		t.Fatalf("len(Plans) = %d, want %d", len(plan.Plans), len(wantPaths))
+	}
+	for i, p := range plan.Plans {
+		if p.Manifest.Path != wantPaths[i] {
+			t.Errorf("Plans[%d] = %s, want %s", i, p.Manifest.Path, wantPaths[i])
+		}
+	}
+	if !plan.Timestamp.IsZero() {
+		t.Errorf("plan Timestamp = %v, want zero", plan.Timestamp)
+	}
+}
@@ -28,18 +28,35 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"sync"
 	"time"
 )
 
+// MaxDefaultConcurrency caps the default concurrency on machines with many
+// CPUs, since most operations wait on registries rather than the CPU.
+const MaxDefaultConcurrency = 16
+
+// DefaultConcurrency returns the number of CPUs, at most MaxDefaultConcurrency.
+func DefaultConcurrency() int {
+	return min(runtime.NumCPU(), MaxDefaultConcurrency)
+}
+
 // Engine orchestrates the scan, plan, and update operations.
 type Engine struct {
-	integrations map[string]Integration
-	policies     map[string]IntegrationPolicy
-	matchConfigs map[string]*MatchConfig // integration -> match configuration (files + exclude)
-	logger       *slog.Logger
-	cliFlags     *CLIFlags
-	concurrency  int
+	integrations   map[string]Integration
+	policies       map[string]IntegrationPolicy
+	matchConfigs   map[string]*MatchConfig // integration -> match configuration (files + exclude)
+	logger         *slog.Logger
+	cliFlags       *CLIFlags
+	excludePaths   []string
+	conflictPolicy ConflictPolicy
+	lockfileMode   LockfileMode
+	fixLockfile    bool
+	trackedOnly    bool
+	deterministic  bool
+	concurrency    int
 }
 
 // NewEngine creates a new engine with the given integrations.
@@ -49,14 +66,26 @@ func NewEngine(logger *slog.Logger) *Engine {
 	}
 
 	return &Engine{
-		integrations: make(map[string]Integration),
-		policies:     make(map[string]IntegrationPolicy),
-		matchConfigs: make(map[string]*MatchConfig),
-		logger:       logger,
-		concurrency:  4,
+		integrations:   make(map[string]Integration),
+		policies:       make(map[string]IntegrationPolicy),
+		matchConfigs:   make(map[string]*MatchConfig),
+		logger:         logger,
+		conflictPolicy: ConflictSkip,
+		concurrency:    DefaultConcurrency(),
 	}
 }
 
+// SetConcurrency sets how many integrations detect, plan, or apply at once.
+// n must be at least 1; a limit of 1 runs operations one at a time.
+func (e *Engine) SetConcurrency(n int) error {
+	if n < 1 {
+		return fmt.Errorf("invalid concurrency %d: must be at least 1", n)
+	}
+	e.concurrency = n
+	e.logger.Debug("set concurrency", "limit", n)
+	return nil
+}
+
 // SetPolicies configures integration policies from uptool.yaml.
 // These policies have the highest precedence in determining allowed updates.
 func (e *Engine) SetPolicies(policies map[string]IntegrationPolicy) {
@@ -111,12 +140,19 @@ func (e *Engine) Scan(ctx context.Context, repoRoot string, only, exclude []stri
 	start := time.Now()
 
 	integrations := e.filterIntegrations(only, exclude)
+	if len(e.excludePaths) > 0 {
+		ctx = WithExcludePaths(ctx, e.excludePaths)
+	}
 
+	// Results are collected per integration and merged by integration name
+	// and manifest path below, so output does not depend on goroutine
+	// scheduling.
 	var (
-		mu        sync.Mutex
-		manifests []*Manifest
-		errors    []string
-		wg        sync.WaitGroup
+		mu         sync.Mutex
+		found      = make(map[string][]*Manifest, len(integrations))
+		detectErrs = make(map[string]error)
+		skipped    int
+		wg         sync.WaitGroup
 	)
 
 	sem := make(chan struct{}, e.concurrency)
@@ -125,38 +161,82 @@ func (e *Engine) Scan(ctx context.Context, repoRoot string, only, exclude []stri
 		wg.Add(1)
 		go func(n string, integ Integration) {
 			defer wg.Done()
-			sem <- struct{}{}
+			if !acquire(ctx, sem) {
+				mu.Lock()
+				skipped++
+				mu.Unlock()
+				return
+			}
 			defer func() { <-sem }()
 
-			found, err := integ.Detect(ctx, repoRoot)
+			detected, err := integ.Detect(ctx, repoRoot)
 			mu.Lock()
 			defer mu.Unlock()
 
 			if err != nil {
-				errors = append(errors, fmt.Sprintf("%s: %v", n, err))
+				detectErrs[n] = err
 				e.logger.Error("detect failed", "integration", n, "error", err)
 				return
 			}
 
 			// Filter manifests by match patterns if configured
 			if matchConfig, ok := e.matchConfigs[n]; ok && matchConfig != nil {
-				filtered := e.filterManifestsByPattern(found, matchConfig, repoRoot)
-				manifests = append(manifests, filtered...)
-				e.logger.Info("scan complete", "integration", n, "found", len(found), "filtered", len(filtered))
+				filtered := e.filterManifestsByPattern(detected, matchConfig, repoRoot)
+				found[n] = filtered
+				e.logger.Info("scan complete", "integration", n, "found", len(detected), "filtered", len(filtered))
 			} else {
-				manifests = append(manifests, found...)
-				e.logger.Info("scan complete", "integration", n, "found", len(found))
+				found[n] = detected
+				e.logger.Info("scan complete", "integration", n, "found", len(detected))
 			}
 		}(name, integration)
 	}
 
 	wg.Wait()
 
+	names := make([]string, 0, len(integrations))
+	for name := range integrations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		manifests []*Manifest
+		errors    []string
+	)
+	for _, name := range names {
+		if err, ok := detectErrs[name]; ok {
+			errors = append(errors, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		// Detect may return manifests in any order, so sort them by path too
+		detected := found[name]
+		sort.SliceStable(detected, func(i, j int) bool {
+			return detected[i].Path < detected[j].Path
+		})
+		manifests = append(manifests, detected...)
+	}
+	if skipped > 0 {
+		errors = append(errors, abortedError(ctx, "scan", skipped, "integrations"))
+	}
+
+	manifests = e.filterIgnored(ctx, manifests, repoRoot)
+	manifests = e.resolveManifestLinks(manifests, repoRoot)
+	captureLockfiles(manifests, repoRoot)
+
+	if e.trackedOnly {
+		manifests = e.filterTracked(ctx, manifests, repoRoot)
+	}
+
+	if e.deterministic {
+		sortManifests(manifests)
+		sort.Strings(errors)
+	}
+
 	e.logger.Info("scan finished", "duration", time.Since(start), "manifests", len(manifests))
 
 	return &ScanResult{
 		Manifests: manifests,
-		Timestamp: time.Now(),
+		Timestamp: e.timestamp(),
 		RepoRoot:  repoRoot,
 		Errors:    errors,
 	}, nil
@@ -166,7 +246,15 @@ func (e *Engine) Scan(ctx context.Context, repoRoot string, only, exclude []stri
 type PlanOptions struct {
 	Now               time.Time
 	ReleaseTimestamps map[string]time.Time
-	CheckSchedule     bool
+	// LookupTimeout bounds each dependency lookup; zero selects DefaultLookupTimeout.
+	LookupTimeout time.Duration
+	// OnlyGroup restricts plans to updates in this dependency group. The
+	// group must be defined in at least one integration policy.
+	OnlyGroup string
+	// Resume, when set, reuses plans recorded by an earlier interrupted run
+	// and records each newly completed plan.
+	Resume        *ResumeState
+	CheckSchedule bool
 }
 
 // Plan generates update plans for all manifests.
@@ -188,12 +276,20 @@ func (e *Engine) PlanWithOptions(ctx context.Context, manifests []*Manifest, opt
 	if opts.Now.IsZero() {
 		opts.Now = time.Now()
 	}
+	lookupTimeout := opts.LookupTimeout
+	if lookupTimeout == 0 {
+		lookupTimeout = DefaultLookupTimeout
+	}
+	if opts.OnlyGroup != "" && !e.HasGroup(opts.OnlyGroup) {
+		return nil, fmt.Errorf("dependency group %q is not defined in any integration policy", opts.OnlyGroup)
+	}
 
 	var (
-		mu     sync.Mutex
-		plans  []*UpdatePlan
-		errors []string
-		wg     sync.WaitGroup
+		mu      sync.Mutex
+		plans   []*UpdatePlan
+		errors  []string
+		skipped int
+		wg      sync.WaitGroup
 	)
 
 	sem := make(chan struct{}, e.concurrency)
@@ -202,7 +298,12 @@ func (e *Engine) PlanWithOptions(ctx context.Context, manifests []*Manifest, opt
 		wg.Add(1)
 		go func(m *Manifest) {
 			defer wg.Done()
-			sem <- struct{}{}
+			if !acquire(ctx, sem) {
+				mu.Lock()
+				skipped++
+				mu.Unlock()
+				return
+			}
 			defer func() { <-sem }()
 
 			integration, ok := e.integrations[m.Type]
@@ -231,6 +332,16 @@ func (e *Engine) PlanWithOptions(ctx context.Context, manifests []*Manifest, opt
 				}
 			}
 
+			if opts.Resume != nil {
+				if plan, ok := opts.Resume.lookup(m, planCtx); ok {
+					e.logger.Debug("resumed plan", "manifest", m.Path, "updates", len(plan.Updates))
+					mu.Lock()
+					plans = append(plans, plan)
+					mu.Unlock()
+					return
+				}
+			}
+
 			e.logger.Debug("planning manifest",
 				"manifest", m.Path,
 				"integration", m.Type,
@@ -238,7 +349,8 @@ func (e *Engine) PlanWithOptions(ctx context.Context, manifests []*Manifest, opt
 				"allow_prerelease", planCtx.EffectiveAllowPrerelease(),
 			)
 
-			plan, err := integration.Plan(ctx, m, planCtx)
+			lookupCtx := WithLookupTimeout(ctx, lookupTimeout)
+			plan, err := integration.Plan(lookupCtx, m, planCtx)
 			if err != nil {
 				mu.Lock()
 				errors = append(errors, fmt.Sprintf("%s (%s): %v", m.Path, m.Type, err))
@@ -247,9 +359,16 @@ func (e *Engine) PlanWithOptions(ctx context.Context, manifests []*Manifest, opt
 				return
 			}
 
-			// Apply allow/ignore rules, cooldown, and grouping
-			if planCtx.Policy != nil && len(plan.Updates) > 0 {
-				plan = e.applyPolicyFilters(plan, planCtx.Policy, opts.ReleaseTimestamps)
+			if unchecked := UncheckedLookups(lookupCtx); len(unchecked) > 0 {
+				plan.Unchecked = unchecked
+				e.logger.Warn("dependency lookups timed out", "manifest", m.Path, "count", len(unchecked))
+			} else if opts.Resume != nil {
+				// Plans with timed-out lookups are incomplete, so only
+				// fully checked ones are recorded. Policy filters run after
+				// recording, so a resumed run applies its own.
+				if err := opts.Resume.record(m, planCtx, plan); err != nil {
+					e.logger.Warn("failed to record plan progress", "manifest", m.Path, "error", err)
+				}
 			}
 
 			mu.Lock()
@@ -268,21 +387,83 @@ func (e *Engine) PlanWithOptions(ctx context.Context, manifests []*Manifest, opt
 
 	wg.Wait()
 
+	if skipped > 0 {
+		errors = append(errors, abortedError(ctx, "plan", skipped, "manifests"))
+	}
+
+	plans = e.reconcilePlans(plans)
+	plans = e.filterPlans(plans, opts)
+
+	if e.deterministic {
+		sortPlans(plans)
+		sort.Strings(errors)
+	}
+
 	e.logger.Info("plan finished", "duration", time.Since(start), "plans", len(plans))
 
 	return &PlanResult{
 		Plans:     plans,
-		Timestamp: time.Now(),
+		Timestamp: e.timestamp(),
 		Errors:    errors,
 	}, nil
 }
 
+// filterPlans applies each integration's allow/ignore rules, cooldown, and
+// grouping, then restricts plans to opts.OnlyGroup when set.
+func (e *Engine) filterPlans(plans []*UpdatePlan, opts *PlanOptions) []*UpdatePlan {
+	filtered := make([]*UpdatePlan, 0, len(plans))
+	for _, plan := range plans {
+		planCtx := e.getPlanContext(plan.Manifest.Type)
+		unchecked := plan.Unchecked
+
+		if planCtx.Policy != nil && len(plan.Updates) > 0 {
+			plan = e.applyPolicyFilters(plan, planCtx.Policy, opts.ReleaseTimestamps, opts.Now)
+		}
+		if opts.OnlyGroup != "" {
+			plan = restrictToGroup(plan, planCtx.Policy, opts.OnlyGroup)
+		}
+
+		plan.Unchecked = unchecked
+		filtered = append(filtered, plan)
+	}
+	return filtered
+}
+
+// reconcilePlans gives integrations implementing PlanReconciler a chance to adjust
+// their plans across manifests. It runs before policy filters, so whatever a
+// reconciler proposes is still subject to allow/ignore rules and cooldown.
+// Plans of other integrations are returned unchanged.
+func (e *Engine) reconcilePlans(plans []*UpdatePlan) []*UpdatePlan {
+	byType := make(map[string][]*UpdatePlan)
+	var order []string
+	for _, p := range plans {
+		if _, ok := byType[p.Manifest.Type]; !ok {
+			order = append(order, p.Manifest.Type)
+		}
+		byType[p.Manifest.Type] = append(byType[p.Manifest.Type], p)
+	}
+
+	result := make([]*UpdatePlan, 0, len(plans))
+	for _, name := range order {
+		typed := byType[name]
+		if reconciler, ok := e.integrations[name].(PlanReconciler); ok {
+			typed = reconciler.ReconcilePlans(typed, e.getPlanContext(name))
+			e.logger.Debug("reconciled plans", "integration", name, "plans", len(typed))
+		}
+		result = append(result, typed...)
+	}
+
+	return result
+}
+
 // applyPolicyFilters applies allow/ignore rules, cooldown, and grouping to a plan.
-func (e *Engine) applyPolicyFilters(plan *UpdatePlan, policy *IntegrationPolicy, releaseTimestamps map[string]time.Time) *UpdatePlan {
+// Updates held by cooldown, measured at now, are recorded in the plan's Held list.
+func (e *Engine) applyPolicyFilters(plan *UpdatePlan, policy *IntegrationPolicy, releaseTimestamps map[string]time.Time, now time.Time) *UpdatePlan {
 	filter := NewUpdateFilter(policy)
+	filter.now = now
 
 	// Apply allow/ignore rules and cooldown
-	filteredUpdates, reasons := filter.FilterUpdates(plan.Updates, releaseTimestamps)
+	filteredUpdates, reasons, held := filter.filterUpdates(plan.Updates, releaseTimestamps)
 
 	// Log filtered updates
 	for dep, reason := range reasons {
@@ -303,11 +484,59 @@ func (e *Engine) applyPolicyFilters(plan *UpdatePlan, policy *IntegrationPolicy,
 	}
 	finalUpdates = append(finalUpdates, ungrouped...)
 
-	return &UpdatePlan{
+	filtered := &UpdatePlan{
 		Manifest: plan.Manifest,
 		Strategy: plan.Strategy,
 		Updates:  finalUpdates,
+		Held:     held,
+		Filtered: plan.Filtered,
+	}
+	filtered.RecordFiltered(plan.Updates)
+	return filtered
+}
+
+// restrictToGroup keeps only the updates, and cooldown-held updates, that
+// belong to the named dependency group.
+func restrictToGroup(plan *UpdatePlan, policy *IntegrationPolicy, group string) *UpdatePlan {
+	filter := NewUpdateFilter(policy)
+	inGroup := func(u *Update) bool {
+		if u.Group != "" {
+			return u.Group == group
+		}
+		return policy != nil && filter.findGroup(u) == group
+	}
+
+	restricted := *plan
+	restricted.Updates = nil
+	for i := range plan.Updates {
+		if inGroup(&plan.Updates[i]) {
+			restricted.Updates = append(restricted.Updates, plan.Updates[i])
+		}
 	}
+	restricted.Held = nil
+	for i := range plan.Held {
+		if inGroup(&plan.Held[i].Update) {
+			restricted.Held = append(restricted.Held, plan.Held[i])
+		}
+	}
+	held := make([]Update, 0, len(plan.Held))
+	for i := range plan.Held {
+		held = append(held, plan.Held[i].Update)
+	}
+	restricted.RecordFiltered(plan.Updates)
+	restricted.RecordFiltered(held)
+	return &restricted
+}
+
+// HasGroup reports whether any integration policy defines the named
+// dependency group.
+func (e *Engine) HasGroup(name string) bool {
+	for _, policy := range e.policies {
+		if _, ok := policy.Groups[name]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 // GetUpdateFilter returns an UpdateFilter for the given integration.
@@ -319,6 +548,16 @@ func (e *Engine) GetUpdateFilter(integrationName string) *UpdateFilter {
 	return NewUpdateFilter(nil)
 }
 
+// GetPolicy returns the uptool.yaml policy of the given integration, or nil
+// if none is configured.
+func (e *Engine) GetPolicy(integrationName string) *IntegrationPolicy {
+	policy, ok := e.policies[integrationName]
+	if !ok {
+		return nil
+	}
+	return &policy
+}
+
 // GetScheduleChecker returns a ScheduleChecker for the given integration.
 // Returns nil if no schedule is configured.
 func (e *Engine) GetScheduleChecker(integrationName string) (*ScheduleChecker, error) {
@@ -338,7 +577,7 @@ func (e *Engine) Update(ctx context.Context, plans []*UpdatePlan, dryRun bool) (
 		e.logger.Info("dry-run mode: no changes will be applied")
 		return &UpdateResult{
 			Results:   nil,
-			Timestamp: time.Now(),
+			Timestamp: e.timestamp(),
 		}, nil
 	}
 
@@ -346,6 +585,7 @@ func (e *Engine) Update(ctx context.Context, plans []*UpdatePlan, dryRun bool) (
 		mu      sync.Mutex
 		results []*ApplyResult
 		errors  []string
+		skipped int
 		wg      sync.WaitGroup
 	)
 
@@ -355,7 +595,12 @@ func (e *Engine) Update(ctx context.Context, plans []*UpdatePlan, dryRun bool) (
 		wg.Add(1)
 		go func(p *UpdatePlan) {
 			defer wg.Done()
-			sem <- struct{}{}
+			if !acquire(ctx, sem) {
+				mu.Lock()
+				skipped++
+				mu.Unlock()
+				return
+			}
 			defer func() { <-sem }()
 
 			integration, ok := e.integrations[p.Manifest.Type]
@@ -366,7 +611,20 @@ func (e *Engine) Update(ctx context.Context, plans []*UpdatePlan, dryRun bool) (
 				return
 			}
 
-			result, err := integration.Apply(ctx, p)
+			mode := e.effectiveLockfileMode(p.Manifest.Type)
+			if mode == LockfileOnly {
+				updater, ok := integration.(LockfileUpdater)
+				if !ok || !updater.HasLockfile(p.Manifest) {
+					e.logger.Debug("skipping manifest without lockfile", "manifest", p.Manifest.Path)
+					return
+				}
+			}
+
+			applied := *p
+			applied.Lockfile = mode
+			applied.FixLockfile = e.fixLockfile
+
+			result, err := e.applyWithConflictPolicy(ctx, integration, &applied)
 			mu.Lock()
 			defer mu.Unlock()
 
@@ -383,11 +641,20 @@ func (e *Engine) Update(ctx context.Context, plans []*UpdatePlan, dryRun bool) (
 
 	wg.Wait()
 
+	if skipped > 0 {
+		errors = append(errors, abortedError(ctx, "update", skipped, "manifests"))
+	}
+
+	if e.deterministic {
+		sortApplyResults(results)
+		sort.Strings(errors)
+	}
+
 	e.logger.Info("update finished", "duration", time.Since(start), "results", len(results))
 
 	return &UpdateResult{
 		Results:   results,
-		Timestamp: time.Now(),
+		Timestamp: e.timestamp(),
 		Errors:    errors,
 	}, nil
 }
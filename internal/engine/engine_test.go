@@ -27,6 +27,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 	"testing"
@@ -119,8 +120,8 @@ func TestNewEngine(t *testing.T) {
 		if e.integrations == nil {
 			t.Error("NewEngine() created nil integrations map")
 		}
-		if e.concurrency != 4 {
-			t.Errorf("NewEngine() concurrency = %d, want 4", e.concurrency)
+		if e.concurrency != DefaultConcurrency() {
+			t.Errorf("NewEngine() concurrency = %d, want %d", e.concurrency, DefaultConcurrency())
 		}
 	})
 
@@ -815,6 +816,108 @@ func TestConcurrency(t *testing.T) {
 			t.Errorf("Scan() maxConcurrent = %d, want >= 1", maxConcurrent)
 		}
 	})
+
+	// scanWithLimit registers count slow integrations, scans with the given
+	// limit, and returns the highest number of concurrent Detect calls.
+	scanWithLimit := func(t *testing.T, limit, count int) int {
+		t.Helper()
+
+		e := NewEngine(nil)
+		if err := e.SetConcurrency(limit); err != nil {
+			t.Fatalf("SetConcurrency(%d) error = %v", limit, err)
+		}
+
+		tracker := &concurrencyTracker{}
+		for i := 0; i < count; i++ {
+			e.Register(&slowMockIntegration{
+				mockIntegration: mockIntegration{
+					name:            fmt.Sprintf("integration-%d", i),
+					detectManifests: []*Manifest{},
+				},
+				delay:              50 * time.Millisecond,
+				concurrencyTracker: tracker,
+			})
+		}
+
+		if _, err := e.Scan(ctx, "/test", nil, nil); err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+		return tracker.getMax()
+	}
+
+	t.Run("limit of 1 serializes Detect calls", func(t *testing.T) {
+		if got := scanWithLimit(t, 1, 4); got != 1 {
+			t.Errorf("Scan() maxConcurrent = %d, want 1", got)
+		}
+	})
+
+	t.Run("limit of 8 allows 8 concurrent Detect calls", func(t *testing.T) {
+		if got := scanWithLimit(t, 8, 12); got != 8 {
+			t.Errorf("Scan() maxConcurrent = %d, want 8", got)
+		}
+	})
+
+	t.Run("rejects limits below 1", func(t *testing.T) {
+		e := NewEngine(nil)
+		for _, n := range []int{0, -1} {
+			if err := e.SetConcurrency(n); err == nil {
+				t.Errorf("SetConcurrency(%d) expected error", n)
+			}
+		}
+		if e.concurrency != DefaultConcurrency() {
+			t.Errorf("SetConcurrency() changed concurrency to %d after an invalid value", e.concurrency)
+		}
+	})
+}
+
+func TestScanDeterministicOrder(t *testing.T) {
+	ctx := context.Background()
+
+	e := NewEngine(nil)
+	for _, name := range []string{"terraform", "actions", "npm", "helm", "mise"} {
+		e.Register(&mockIntegration{
+			name:        name,
+			detectError: fmt.Errorf("%s detection failed", name),
+		})
+	}
+	for _, name := range []string{"zeta", "alpha"} {
+		e.Register(&mockIntegration{
+			name: name,
+			detectManifests: []*Manifest{
+				{Path: name + "/b.yaml", Type: name},
+				{Path: name + "/a.yaml", Type: name},
+			},
+		})
+	}
+
+	wantErrors := []string{
+		"actions: actions detection failed",
+		"helm: helm detection failed",
+		"mise: mise detection failed",
+		"npm: npm detection failed",
+		"terraform: terraform detection failed",
+	}
+	wantPaths := []string{"alpha/a.yaml", "alpha/b.yaml", "zeta/a.yaml", "zeta/b.yaml"}
+
+	// Repeat to give goroutine scheduling a chance to reorder results
+	for i := 0; i < 20; i++ {
+		result, err := e.Scan(ctx, "/test/repo", nil, nil)
+		if err != nil {
+			t.Fatalf("Scan() error = %v", err)
+		}
+
+		if strings.Join(result.Errors, "\n") != strings.Join(wantErrors, "\n") {
+			t.Fatalf("Scan() errors = %v, want %v", result.Errors, wantErrors)
+		}
+
+		paths := make([]string, len(result.Manifests))
+		for j, m := range result.Manifests {
+			paths[j] = m.Path
+		}
+		if strings.Join(paths, ",") != strings.Join(wantPaths, ",") {
+			t.Fatalf("Scan() manifests = %v, want %v", paths, wantPaths)
+		}
+	}
 }
 
 func TestScanTimestamp(t *testing.T) {
@@ -1020,3 +1123,205 @@ func TestEngine_ScanWithMatchFiltering(t *testing.T) {
 		t.Errorf("Scan() filtered manifest path = %s, want package.json", result.Manifests[0].Path)
 	}
 }
+
+// reconcilingIntegration records the plans it is asked to reconcile.
+type reconcilingIntegration struct {
+	mockIntegration
+	reconciled []*UpdatePlan
+}
+
+func (r *reconcilingIntegration) ReconcilePlans(plans []*UpdatePlan, _ *PlanContext) []*UpdatePlan {
+	r.reconciled = plans
+	for _, p := range plans {
+		for i := range p.Updates {
+			p.Updates[i].TargetVersion = "2.0.0"
+		}
+	}
+	return plans
+}
+
+func TestPlan_ReconcilesPlans(t *testing.T) {
+	e := NewEngine(nil)
+
+	reconciler := &reconcilingIntegration{
+		mockIntegration: mockIntegration{
+			name: "gomod",
+			planUpdates: []Update{
+				{Dependency: Dependency{Name: "example.com/lib", CurrentVersion: "v1.0.0"}, TargetVersion: "1.1.0"},
+			},
+		},
+	}
+	other := &mockIntegration{
+		name: "npm",
+		planUpdates: []Update{
+			{Dependency: Dependency{Name: "react", CurrentVersion: "17.0.0"}, TargetVersion: "18.0.0"},
+		},
+	}
+	e.Register(reconciler)
+	e.Register(other)
+
+	manifests := []*Manifest{
+		{Path: "a/go.mod", Type: "gomod"},
+		{Path: "b/go.mod", Type: "gomod"},
+		{Path: "package.json", Type: "npm"},
+	}
+
+	result, err := e.Plan(context.Background(), manifests)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	if len(result.Plans) != 3 {
+		t.Fatalf("Plan() plans count = %d, want 3", len(result.Plans))
+	}
+	if len(reconciler.reconciled) != 2 {
+		t.Errorf("ReconcilePlans() received %d plans, want 2", len(reconciler.reconciled))
+	}
+
+	for _, p := range result.Plans {
+		want := "2.0.0"
+		if p.Manifest.Type == "npm" {
+			want = "18.0.0"
+		}
+		if got := p.Updates[0].TargetVersion; got != want {
+			t.Errorf("%s target = %q, want %q", p.Manifest.Path, got, want)
+		}
+	}
+}
+
+func TestPlan_FiltersReconciledPlans(t *testing.T) {
+	e := NewEngine(nil)
+	e.Register(&reconcilingIntegration{
+		mockIntegration: mockIntegration{
+			name: "gomod",
+			planUpdates: []Update{
+				{Dependency: Dependency{Name: "example.com/lib", CurrentVersion: "v1.0.0"}, TargetVersion: "1.1.0"},
+			},
+		},
+	})
+	e.SetPolicies(map[string]IntegrationPolicy{"gomod": {
+		Enabled: true,
+		Ignore:  []IgnoreRule{{DependencyName: "example.com/lib", Versions: []string{">= 2.0.0"}}},
+	}})
+
+	result, err := e.Plan(context.Background(), []*Manifest{{Path: "go.mod", Type: "gomod"}})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	if len(result.Plans) != 1 || len(result.Plans[0].Updates) != 0 {
+		t.Errorf("Plan() updates = %+v, want the reconciled 2.0.0 target ignored by policy", result.Plans[0].Updates)
+	}
+}
+
+func TestPrereleaseAllowed(t *testing.T) {
+	tests := []struct {
+		prerelease string
+		channels   []string
+		allow      bool
+		want       bool
+	}{
+		{prerelease: "", want: true},
+		{prerelease: "beta.1", want: false},
+		{prerelease: "beta.1", allow: true, want: true},
+		{prerelease: "rc1", channels: []string{"rc"}, want: true},
+		{prerelease: "rc.2", channels: []string{"rc"}, want: true},
+		{prerelease: "beta2", channels: []string{"rc"}, allow: true, want: false},
+		{prerelease: "alpha20250708", channels: []string{"rc", "alpha"}, want: true},
+		{prerelease: "rcx", channels: []string{"rc"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prerelease, func(t *testing.T) {
+			if got := PrereleaseAllowed(tt.prerelease, tt.allow, tt.channels); got != tt.want {
+				t.Errorf("PrereleaseAllowed(%q, %v, %v) = %v, want %v", tt.prerelease, tt.allow, tt.channels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanWithOptions_RecordsCooldownHeld(t *testing.T) {
+	now := time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC)
+	express := Dependency{Name: "express", CurrentVersion: "4.17.0"}
+
+	e := NewEngine(nil)
+	e.Register(&mockIntegration{
+		name: "npm",
+		planUpdates: []Update{
+			{Dependency: express, TargetVersion: "5.0.0", Impact: "major"},
+		},
+	})
+	e.SetPolicies(map[string]IntegrationPolicy{
+		"npm": {Cooldown: &CooldownConfig{SemverMajorDays: 14}},
+	})
+
+	result, err := e.PlanWithOptions(context.Background(), []*Manifest{{Path: "package.json", Type: "npm"}}, &PlanOptions{
+		Now:               now,
+		ReleaseTimestamps: map[string]time.Time{"express@5.0.0": now.AddDate(0, 0, -4)},
+	})
+	if err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	plan := result.Plans[0]
+	if len(plan.Updates) != 0 {
+		t.Errorf("Updates = %+v, want express held by cooldown", plan.Updates)
+	}
+	if len(plan.Held) != 1 || plan.Held[0].DaysRemaining != 10 || plan.Held[0].CooldownDays != 14 {
+		t.Errorf("Held = %+v, want express with 10 of 14 days remaining", plan.Held)
+	}
+}
+
+func TestPlanWithOptions_OnlyGroup(t *testing.T) {
+	e := NewEngine(nil)
+	e.Register(&mockIntegration{
+		name: "npm",
+		planUpdates: []Update{
+			{Dependency: Dependency{Name: "jsonwebtoken", CurrentVersion: "8.5.1"}, TargetVersion: "9.0.2", Impact: "major"},
+			{Dependency: Dependency{Name: "helmet", CurrentVersion: "7.0.0"}, TargetVersion: "7.1.0", Impact: "minor"},
+			{Dependency: Dependency{Name: "react", CurrentVersion: "18.2.0"}, TargetVersion: "18.3.1", Impact: "minor"},
+			{Dependency: Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21", Impact: "patch"},
+		},
+	})
+	e.Register(&mockIntegration{
+		name: "helm",
+		planUpdates: []Update{
+			{Dependency: Dependency{Name: "postgresql", CurrentVersion: "12.0.0"}, TargetVersion: "12.1.0", Impact: "minor"},
+		},
+	})
+	e.SetPolicies(map[string]IntegrationPolicy{
+		"npm": {Groups: map[string]*DependencyGroup{
+			"security-deps": {Patterns: []string{"jsonwebtoken", "helmet"}},
+			"frontend":      {Patterns: []string{"react*"}},
+		}},
+	})
+	manifests := []*Manifest{{Path: "package.json", Type: "npm"}, {Path: "Chart.yaml", Type: "helm"}}
+
+	t.Run("keeps only the named group", func(t *testing.T) {
+		result, err := e.PlanWithOptions(context.Background(), manifests, &PlanOptions{OnlyGroup: "security-deps"})
+		if err != nil {
+			t.Fatalf("PlanWithOptions() error = %v", err)
+		}
+
+		var planned []string
+		for _, plan := range result.Plans {
+			for _, u := range plan.Updates {
+				if u.Group != "security-deps" {
+					t.Errorf("update %s has group %q, want security-deps", u.Dependency.Name, u.Group)
+				}
+				planned = append(planned, u.Dependency.Name)
+			}
+		}
+		sort.Strings(planned)
+		if strings.Join(planned, ",") != "helmet,jsonwebtoken" {
+			t.Errorf("planned updates = %v, want [helmet jsonwebtoken]", planned)
+		}
+	})
+
+	t.Run("undefined group", func(t *testing.T) {
+		_, err := e.PlanWithOptions(context.Background(), manifests, &PlanOptions{OnlyGroup: "backend"})
+		if err == nil || !strings.Contains(err.Error(), `"backend"`) {
+			t.Errorf("PlanWithOptions() error = %v, want undefined group error", err)
+		}
+	})
+}
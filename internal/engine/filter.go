@@ -21,11 +21,17 @@
 package engine
 
 import (
+	"math"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/version"
 )
 
 // Constants for dependency types.
@@ -45,6 +51,7 @@ func parseIntSafe(s string) int {
 // It enforces allow/ignore rules, cooldown periods, and versioning strategies.
 type UpdateFilter struct {
 	policy *IntegrationPolicy
+	now    time.Time // reference time for cooldowns; zero means time.Now()
 }
 
 // NewUpdateFilter creates a new filter with the given policy.
@@ -55,12 +62,19 @@ func NewUpdateFilter(policy *IntegrationPolicy) *UpdateFilter {
 // FilterUpdates applies all policy filters to a list of updates.
 // It returns the filtered updates and the reason each update was filtered (if any).
 func (f *UpdateFilter) FilterUpdates(updates []Update, releaseTimestamps map[string]time.Time) ([]Update, map[string]string) {
+	filtered, reasons, _ := f.filterUpdates(updates, releaseTimestamps)
+	return filtered, reasons
+}
+
+// filterUpdates is FilterUpdates that also returns the updates held by cooldown.
+func (f *UpdateFilter) filterUpdates(updates []Update, releaseTimestamps map[string]time.Time) ([]Update, map[string]string, []HeldUpdate) {
 	if f.policy == nil {
-		return updates, nil
+		return updates, nil, nil
 	}
 
 	filtered := make([]Update, 0, len(updates))
 	reasons := make(map[string]string)
+	var held []HeldUpdate
 
 	for i := range updates {
 		update := &updates[i]
@@ -80,8 +94,9 @@ func (f *UpdateFilter) FilterUpdates(updates []Update, releaseTimestamps map[str
 
 		// Check cooldown
 		if releaseTimestamps != nil {
-			if reason := f.checkCooldown(update, releaseTimestamps); reason != "" {
-				reasons[depName] = reason
+			if hold, ok := f.cooldownHold(update, releaseTimestamps); ok {
+				reasons[depName] = cooldownReason(hold)
+				held = append(held, hold)
 				continue
 			}
 		}
@@ -89,7 +104,7 @@ func (f *UpdateFilter) FilterUpdates(updates []Update, releaseTimestamps map[str
 		filtered = append(filtered, *update)
 	}
 
-	return filtered, reasons
+	return filtered, reasons, held
 }
 
 // isAllowed checks if an update matches any allow rule.
@@ -193,8 +208,22 @@ func (f *UpdateFilter) matchesIgnoreRule(update *Update, rule IgnoreRule) string
 // checkCooldown checks if an update should be delayed due to cooldown settings.
 // Returns the reason if in cooldown, empty string otherwise.
 func (f *UpdateFilter) checkCooldown(update *Update, releaseTimestamps map[string]time.Time) string {
+	if hold, ok := f.cooldownHold(update, releaseTimestamps); ok {
+		return cooldownReason(hold)
+	}
+	return ""
+}
+
+// cooldownReason formats the filter reason for an update held by cooldown.
+func cooldownReason(hold HeldUpdate) string {
+	return "cooldown: " + strconv.Itoa(hold.DaysRemaining) + " days remaining"
+}
+
+// cooldownHold reports whether an update is held by cooldown settings, and if so
+// when its release was published and how many days remain before it is proposed.
+func (f *UpdateFilter) cooldownHold(update *Update, releaseTimestamps map[string]time.Time) (HeldUpdate, bool) {
 	if f.policy.Cooldown == nil {
-		return ""
+		return HeldUpdate{}, false
 	}
 
 	cooldown := f.policy.Cooldown
@@ -203,7 +232,7 @@ func (f *UpdateFilter) checkCooldown(update *Update, releaseTimestamps map[strin
 	// Check if dependency is excluded from cooldown
 	for _, pattern := range cooldown.Exclude {
 		if matchGlob(pattern, depName) {
-			return ""
+			return HeldUpdate{}, false
 		}
 	}
 
@@ -217,7 +246,7 @@ func (f *UpdateFilter) checkCooldown(update *Update, releaseTimestamps map[strin
 			}
 		}
 		if !included {
-			return ""
+			return HeldUpdate{}, false
 		}
 	}
 
@@ -226,38 +255,49 @@ func (f *UpdateFilter) checkCooldown(update *Update, releaseTimestamps map[strin
 	releaseTime, ok := releaseTimestamps[key]
 	if !ok {
 		// No timestamp available, can't enforce cooldown
-		return ""
-	}
-
-	// Determine cooldown days based on update type
-	cooldownDays := cooldown.DefaultDays
-	switch strings.ToLower(update.Impact) {
-	case string(ImpactMajor):
-		if cooldown.SemverMajorDays > 0 {
-			cooldownDays = cooldown.SemverMajorDays
-		}
-	case string(ImpactMinor):
-		if cooldown.SemverMinorDays > 0 {
-			cooldownDays = cooldown.SemverMinorDays
-		}
-	case string(ImpactPatch):
-		if cooldown.SemverPatchDays > 0 {
-			cooldownDays = cooldown.SemverPatchDays
-		}
+		return HeldUpdate{}, false
 	}
 
+	cooldownDays := f.GetCooldownDays(update.Impact)
 	if cooldownDays <= 0 {
-		return ""
+		return HeldUpdate{}, false
 	}
 
 	// Check if release is old enough
+	remaining := cooldownDaysRemaining(releaseTime, cooldownDays, f.clock())
+	if remaining <= 0 {
+		return HeldUpdate{}, false
+	}
+
+	return HeldUpdate{
+		Update:        *update,
+		ReleasedAt:    releaseTime,
+		CooldownDays:  cooldownDays,
+		DaysRemaining: remaining,
+	}, true
+}
+
+// cooldownDaysRemaining returns the whole days, rounded up, until a release
+// published at releaseTime has aged cooldownDays. It is 0 once the cooldown is over.
+func cooldownDaysRemaining(releaseTime time.Time, cooldownDays int, now time.Time) int {
 	cooldownEnd := releaseTime.AddDate(0, 0, cooldownDays)
-	if time.Now().Before(cooldownEnd) {
-		daysRemaining := int(time.Until(cooldownEnd).Hours() / 24)
-		return "cooldown: " + strconv.Itoa(daysRemaining) + " days remaining"
+	if !now.Before(cooldownEnd) {
+		return 0
 	}
+	return int(math.Ceil(cooldownEnd.Sub(now).Hours() / 24))
+}
 
-	return ""
+// clock returns the reference time for cooldown checks.
+func (f *UpdateFilter) clock() time.Time {
+	if f.now.IsZero() {
+		return time.Now()
+	}
+	return f.now
+}
+
+// HasCooldown reports whether the policy configures a cooldown.
+func (f *UpdateFilter) HasCooldown() bool {
+	return f.policy != nil && f.policy.Cooldown != nil
 }
 
 // GetCooldownDays returns the cooldown days for a specific update type.
@@ -284,6 +324,37 @@ func (f *UpdateFilter) GetCooldownDays(impact string) int {
 	return cooldown.DefaultDays
 }
 
+// RecordFiltered adds to p.Filtered the dependencies of updates that are no
+// longer among p.Updates or held by a cooldown. Filters call it with the
+// updates they started from.
+func (p *UpdatePlan) RecordFiltered(updates []Update) {
+	kept := make(map[string]bool, len(p.Updates)+len(p.Held))
+	for i := range p.Updates {
+		kept[dependencyKey(p.Updates[i].Dependency)] = true
+	}
+	for i := range p.Held {
+		kept[dependencyKey(p.Held[i].Update.Dependency)] = true
+	}
+	for _, dep := range p.Filtered {
+		kept[dependencyKey(dep)] = true
+	}
+
+	filtered := slices.Clip(p.Filtered)
+	for i := range updates {
+		key := dependencyKey(updates[i].Dependency)
+		if !kept[key] {
+			kept[key] = true
+			filtered = append(filtered, updates[i].Dependency)
+		}
+	}
+	p.Filtered = filtered
+}
+
+// dependencyKey identifies a dependency within a manifest.
+func dependencyKey(dep Dependency) string {
+	return dep.Name + "\x00" + dep.Type
+}
+
 // matchGlob matches a pattern against a string.
 // Supports * as wildcard for any characters.
 func matchGlob(pattern, str string) bool {
@@ -356,7 +427,7 @@ func matchVersion(pattern, version string) bool {
 	}
 	if strings.HasPrefix(pattern, "=") {
 		compareVer := strings.TrimSpace(strings.TrimPrefix(pattern, "="))
-		return version == compareVer
+		return compareVersions(version, compareVer) == 0
 	}
 
 	// Exact match
@@ -365,10 +436,26 @@ func matchVersion(pattern, version string) bool {
 
 // compareVersions compares two semver versions.
 // Returns: -1 if v1 < v2, 0 if v1 == v2, 1 if v1 > v2
+//
+// Prerelease identifiers are ordered per the semver spec (1.0.0-alpha <
+// 1.0.0-alpha.1 < 1.0.0-beta < 1.0.0) and build metadata is ignored. Versions
+// that are not semver (e.g., four-part versions) fall back to a numeric
+// comparison of their dot-separated parts.
 func compareVersions(v1, v2 string) int {
-	// Remove 'v' prefix
-	v1 = strings.TrimPrefix(v1, "v")
-	v2 = strings.TrimPrefix(v2, "v")
+	sv1, err1 := semver.NewVersion(v1)
+	sv2, err2 := semver.NewVersion(v2)
+	if err1 == nil && err2 == nil {
+		return sv1.Compare(sv2)
+	}
+
+	return compareNumericParts(v1, v2)
+}
+
+// compareNumericParts compares the numeric dot-separated parts of two
+// versions, ignoring prerelease suffixes.
+func compareNumericParts(v1, v2 string) int {
+	v1 = version.Bare(v1)
+	v2 = version.Bare(v2)
 
 	// Split into parts
 	parts1 := strings.Split(v1, ".")
@@ -576,11 +663,30 @@ func (f *UpdateFilter) ApplyVersioningStrategy(update *Update, currentConstraint
 }
 
 // constraintAllowsVersion checks if a version constraint allows a specific version.
-// This is a simplified implementation - full implementation would use a proper semver library.
+// OR'd ranges (e.g., "^1.0.0 || ^2.0.0") allow the version if any branch does.
 func constraintAllowsVersion(constraint, version string) bool {
-	constraint = strings.TrimSpace(constraint)
 	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
 
+	for _, branch := range strings.Split(constraint, "||") {
+		if rangeAllowsVersion(strings.TrimSpace(branch), version) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rangeAllowsVersion checks a single range (without "||") against a version.
+// Ranges are evaluated with semver; Terraform's ~> and unparsable ranges fall back to prefix matching.
+func rangeAllowsVersion(constraint, version string) bool {
+	if !strings.HasPrefix(constraint, "~>") {
+		if v, err := semver.NewVersion(version); err == nil {
+			if c, err := semver.NewConstraint(constraint); err == nil {
+				return c.Check(v)
+			}
+		}
+	}
+
 	// Handle caret constraint (^)
 	if strings.HasPrefix(constraint, "^") {
 		baseVersion := strings.TrimPrefix(constraint, "^")
@@ -640,6 +746,14 @@ func constraintAllowsVersion(constraint, version string) bool {
 
 // widenConstraint creates a wider constraint that includes both old and new versions.
 func widenConstraint(currentConstraint, newVersion string) string {
+	// For OR'd ranges, add a branch for the new version unless one already covers it
+	if strings.Contains(currentConstraint, "||") {
+		if constraintAllowsVersion(currentConstraint, newVersion) {
+			return currentConstraint
+		}
+		return currentConstraint + " || ^" + strings.TrimPrefix(newVersion, "v")
+	}
+
 	// Extract the base version from the constraint
 	baseVersion := currentConstraint
 	prefix := ""
@@ -766,9 +880,44 @@ func (f *UpdateFilter) FormatCommitMessage(updates []Update, manifestPath string
 	msg.WriteString(" in ")
 	msg.WriteString(filepath.Base(manifestPath))
 
+	// Add body listing each update, separated from the subject by a blank line
+	if cm.IncludeBody && len(updates) > 0 {
+		msg.WriteString("\n\n")
+		msg.WriteString(commitMessageBody(updates))
+	}
+
 	return msg.String()
 }
 
+// commitMessageBody lists each update on its own line, with the changelog link indented below it.
+func commitMessageBody(updates []Update) string {
+	var body strings.Builder
+
+	for i := range updates {
+		u := updates[i]
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		body.WriteString("- ")
+		body.WriteString(u.Dependency.Name)
+		body.WriteString(": ")
+		body.WriteString(u.Dependency.CurrentVersion)
+		body.WriteString(" → ")
+		body.WriteString(u.TargetVersion)
+		if u.Impact != "" {
+			body.WriteString(" (")
+			body.WriteString(u.Impact)
+			body.WriteString(")")
+		}
+		if u.ChangelogURL != "" {
+			body.WriteString("\n  Changelog: ")
+			body.WriteString(u.ChangelogURL)
+		}
+	}
+
+	return body.String()
+}
+
 // defaultCommitMessage generates a default commit message.
 func (f *UpdateFilter) defaultCommitMessage(updates []Update, manifestPath string) string {
 	var msg strings.Builder
@@ -83,6 +83,12 @@ func TestMatchVersion(t *testing.T) {
 		{"lt equal", "< 2.0.0", "2.0.0", false},
 		{"equal prefix", "= 2.0.0", "2.0.0", true},
 		{"equal prefix no match", "= 2.0.0", "2.0.1", false},
+		{"equal ignores build metadata", "= 2.0.0", "2.0.0+build.5", true},
+		{"gte prerelease", ">= 2.0.0-rc", "2.0.0-rc.1", true},
+		{"gte prerelease release", ">= 2.0.0-rc", "2.0.0", true},
+		{"gte prerelease earlier", ">= 2.0.0-rc", "2.0.0-beta.3", false},
+		{"gte release excludes prerelease", ">= 2.0.0", "2.0.0-rc.1", false},
+		{"lt release includes prerelease", "< 2.0.0", "2.0.0-rc.1", true},
 	}
 
 	for _, tt := range tests {
@@ -111,7 +117,15 @@ func TestCompareVersions(t *testing.T) {
 		{"v1 greater patch", "1.0.1", "1.0.0", 1},
 		{"v prefix", "v1.0.0", "1.0.0", 0},
 		{"different lengths", "1.0", "1.0.0", 0},
-		{"prerelease stripped", "1.0.0-beta", "1.0.0", 0},
+		{"prerelease before release", "1.0.0-beta", "1.0.0", -1},
+		{"release after prerelease", "1.0.0", "1.0.0-beta", 1},
+		{"alpha before alpha.1", "1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"alpha.1 before beta", "1.0.0-alpha.1", "1.0.0-beta", -1},
+		{"numeric identifiers compare numerically", "1.0.0-rc.2", "1.0.0-rc.10", -1},
+		{"alphanumeric after numeric identifier", "1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"build metadata ignored", "1.0.0+build.1", "1.0.0+build.2", 0},
+		{"build metadata with prerelease", "1.0.0-rc.1+sha.abc", "1.0.0-rc.1", 0},
+		{"non-semver falls back to numeric", "1.2.3.4", "1.2.3.10", -1},
 	}
 
 	for _, tt := range tests {
@@ -124,6 +138,29 @@ func TestCompareVersions(t *testing.T) {
 	}
 }
 
+func TestCompareVersions_PrereleaseOrdering(t *testing.T) {
+	// Each version sorts strictly before the next, per semver 2.0.0 section 11
+	ordered := []string{
+		"1.0.0-alpha",
+		"1.0.0-alpha.1",
+		"1.0.0-alpha.beta",
+		"1.0.0-beta",
+		"1.0.0-beta.2",
+		"1.0.0-beta.11",
+		"1.0.0-rc.1",
+		"1.0.0",
+	}
+
+	for i := 0; i < len(ordered)-1; i++ {
+		if got := compareVersions(ordered[i], ordered[i+1]); got != -1 {
+			t.Errorf("compareVersions(%q, %q) = %d, want -1", ordered[i], ordered[i+1], got)
+		}
+		if got := compareVersions(ordered[i+1], ordered[i]); got != 1 {
+			t.Errorf("compareVersions(%q, %q) = %d, want 1", ordered[i+1], ordered[i], got)
+		}
+	}
+}
+
 func TestNormalizeDependencyType(t *testing.T) {
 	tests := []struct {
 		input string
@@ -377,6 +414,69 @@ func TestUpdateFilter_FilterUpdates_CooldownExclude(t *testing.T) {
 	}
 }
 
+func TestUpdateFilter_CooldownHeld(t *testing.T) {
+	policy := &IntegrationPolicy{
+		Cooldown: &CooldownConfig{
+			DefaultDays:     3,
+			SemverMajorDays: 7,
+			SemverPatchDays: 1,
+		},
+	}
+	filter := NewUpdateFilter(policy)
+	filter.now = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	releaseTimestamps := map[string]time.Time{
+		"express@5.0.0":  filter.now.AddDate(0, 0, -2),                // major: 7 - 2 = 5 days left
+		"express@4.19.0": filter.now.Add(-36 * time.Hour),             // minor (default 3): 1.5 days left, rounded up
+		"lodash@4.17.22": filter.now.AddDate(0, 0, -1),                // patch: cooldown just ended
+		"qs@6.12.0":      filter.now.AddDate(0, 0, -1).Add(time.Hour), // patch: 1 hour left
+	}
+
+	updates := []Update{
+		{Dependency: Dependency{Name: "express"}, TargetVersion: "5.0.0", Impact: "major"},
+		{Dependency: Dependency{Name: "express"}, TargetVersion: "4.19.0", Impact: "minor"},
+		{Dependency: Dependency{Name: "lodash"}, TargetVersion: "4.17.22", Impact: "patch"},
+		{Dependency: Dependency{Name: "qs"}, TargetVersion: "6.12.0", Impact: "patch"},
+	}
+
+	filtered, reasons, held := filter.filterUpdates(updates, releaseTimestamps)
+
+	if len(filtered) != 1 || filtered[0].Dependency.Name != "lodash" {
+		t.Errorf("filtered = %+v, want only lodash", filtered)
+	}
+
+	want := []struct {
+		target        string
+		cooldownDays  int
+		daysRemaining int
+	}{
+		{"5.0.0", 7, 5},
+		{"4.19.0", 3, 2},
+		{"6.12.0", 1, 1},
+	}
+	if len(held) != len(want) {
+		t.Fatalf("held = %+v, want %d entries", held, len(want))
+	}
+	for i, w := range want {
+		h := held[i]
+		if h.Update.TargetVersion != w.target || h.CooldownDays != w.cooldownDays || h.DaysRemaining != w.daysRemaining {
+			t.Errorf("held[%d] = %s (%d-day cooldown, %d remaining), want %s (%d-day cooldown, %d remaining)",
+				i, h.Update.TargetVersion, h.CooldownDays, h.DaysRemaining, w.target, w.cooldownDays, w.daysRemaining)
+		}
+		if !h.ReleasedAt.Equal(releaseTimestamps[h.Update.Dependency.Name+"@"+w.target]) {
+			t.Errorf("held[%d].ReleasedAt = %v", i, h.ReleasedAt)
+		}
+		if h.CooldownDays != filter.GetCooldownDays(h.Update.Impact) {
+			t.Errorf("held[%d].CooldownDays = %d, want GetCooldownDays(%q) = %d",
+				i, h.CooldownDays, h.Update.Impact, filter.GetCooldownDays(h.Update.Impact))
+		}
+	}
+
+	if reasons["qs"] != "cooldown: 1 days remaining" {
+		t.Errorf("reasons[qs] = %q", reasons["qs"])
+	}
+}
+
 func TestUpdateFilter_GroupUpdates(t *testing.T) {
 	policy := &IntegrationPolicy{
 		Groups: map[string]*DependencyGroup{
@@ -561,6 +661,55 @@ func TestUpdateFilter_FormatCommitMessage(t *testing.T) {
 			manifest: "package.json",
 			want:     "deps: update 2 dependencies in express-deps group in package.json",
 		},
+		{
+			name: "body lists each update",
+			policy: &IntegrationPolicy{
+				CommitMessage: &CommitMessageConfig{
+					Prefix:      "deps",
+					IncludeBody: true,
+				},
+			},
+			updates: []Update{
+				{
+					Dependency:    Dependency{Name: "express", CurrentVersion: "4.18.0"},
+					TargetVersion: "4.19.0",
+					Impact:        "minor",
+					ChangelogURL:  "https://github.com/expressjs/express/releases/tag/4.19.0",
+				},
+				{
+					Dependency:    Dependency{Name: "lodash", CurrentVersion: "4.17.20"},
+					TargetVersion: "4.17.21",
+					Impact:        "patch",
+				},
+			},
+			manifest: "package.json",
+			want: "deps: update 2 dependencies in package.json\n\n" +
+				"- express: 4.18.0 → 4.19.0 (minor)\n" +
+				"  Changelog: https://github.com/expressjs/express/releases/tag/4.19.0\n" +
+				"- lodash: 4.17.20 → 4.17.21 (patch)",
+		},
+		{
+			name: "body omitted when disabled",
+			policy: &IntegrationPolicy{
+				CommitMessage: &CommitMessageConfig{
+					Prefix: "deps",
+				},
+			},
+			updates: []Update{
+				{
+					Dependency:    Dependency{Name: "express", CurrentVersion: "4.18.0"},
+					TargetVersion: "4.19.0",
+					Impact:        "minor",
+				},
+				{
+					Dependency:    Dependency{Name: "lodash", CurrentVersion: "4.17.20"},
+					TargetVersion: "4.17.21",
+					Impact:        "patch",
+				},
+			},
+			manifest: "package.json",
+			want:     "deps: update 2 dependencies in package.json",
+		},
 	}
 
 	for _, tt := range tests {
@@ -631,6 +780,30 @@ func TestUpdateFilter_ApplyVersioningStrategy(t *testing.T) {
 			wantVersion: ">=1.0.0",
 			wantApply:   true,
 		},
+		{
+			name:        "increase-if-necessary - union range accommodates target",
+			strategy:    "increase-if-necessary",
+			update:      Update{TargetVersion: "2.4.0"},
+			constraint:  "^1.0.0 || ^2.0.0",
+			wantVersion: "2.4.0",
+			wantApply:   false,
+		},
+		{
+			name:        "increase-if-necessary - union range needs increase",
+			strategy:    "increase-if-necessary",
+			update:      Update{TargetVersion: "3.0.0"},
+			constraint:  "^1.0.0 || ^2.0.0",
+			wantVersion: "3.0.0",
+			wantApply:   true,
+		},
+		{
+			name:        "widen union range",
+			strategy:    "widen",
+			update:      Update{TargetVersion: "3.1.0"},
+			constraint:  "^1.0.0 || ^2.0.0",
+			wantVersion: "^1.0.0 || ^2.0.0 || ^3.1.0",
+			wantApply:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -744,6 +917,13 @@ func TestConstraintAllowsVersion(t *testing.T) {
 		{"exact no match", "1.0.0", "1.0.1", false},
 		{"caret with 0.x", "^0.1.0", "0.1.5", true},
 		{"caret with 0.x blocks minor", "^0.1.0", "0.2.0", false},
+		{"union allows first branch", "^1.0.0 || ^2.0.0", "1.5.0", true},
+		{"union allows second branch", "^1.0.0 || ^2.0.0", "2.3.1", true},
+		{"union blocks outside all branches", "^1.0.0 || ^2.0.0", "3.0.0", false},
+		{"union blocks below all branches", "^1.0.0 || ^2.0.0", "0.9.0", false},
+		{"union of exact versions", "1.0.0 || 1.2.0", "1.2.0", true},
+		{"union with hyphen range", "1.0.0 - 1.5.0 || >=3.0.0", "2.0.0", false},
+		{"space separated range", ">=1.2.0 <2.0.0", "1.9.9", true},
 	}
 
 	for _, tt := range tests {
@@ -755,3 +935,21 @@ func TestConstraintAllowsVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdatePlan_RecordFiltered(t *testing.T) {
+	express := Dependency{Name: "express", Type: "direct"}
+	lodash := Dependency{Name: "lodash", Type: "direct"}
+	react := Dependency{Name: "react", Type: "direct"}
+
+	plan := &UpdatePlan{
+		Updates: []Update{{Dependency: express}},
+		Held:    []HeldUpdate{{Update: Update{Dependency: react}}},
+	}
+	before := []Update{{Dependency: express}, {Dependency: lodash}, {Dependency: react}}
+	plan.RecordFiltered(before)
+	plan.RecordFiltered(before)
+
+	if len(plan.Filtered) != 1 || plan.Filtered[0].Name != "lodash" {
+		t.Errorf("Filtered = %v, want [lodash]", plan.Filtered)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LockfileMode controls which files Update writes for a plan.
+type LockfileMode string
+
+// Lockfile modes accepted by update --lockfile-only and --no-lockfile.
+const (
+	// LockfileDefault writes manifests and, for integrations that maintain
+	// them, lockfiles.
+	LockfileDefault LockfileMode = ""
+	// LockfileOnly writes lockfiles and leaves manifests untouched.
+	LockfileOnly LockfileMode = "lockfile-only"
+	// LockfileSkip writes manifests and leaves lockfiles untouched.
+	LockfileSkip LockfileMode = "no-lockfile"
+)
+
+// ParseLockfileMode validates a lockfile mode name.
+// An empty string selects the default, LockfileDefault.
+func ParseLockfileMode(s string) (LockfileMode, error) {
+	switch LockfileMode(s) {
+	case LockfileDefault, LockfileOnly, LockfileSkip:
+		return LockfileMode(s), nil
+	default:
+		return "", fmt.Errorf("invalid lockfile mode %q (must be lockfile-only or no-lockfile)", s)
+	}
+}
+
+// LockfileUpdater is an optional interface for integrations whose Apply also
+// rewrites lockfiles. Such integrations honor UpdatePlan.Lockfile; plans of
+// other integrations are not applied at all in a lockfile-only run, since they
+// have nothing to write.
+type LockfileUpdater interface {
+	// HasLockfile reports whether the manifest has a lockfile Apply maintains.
+	HasLockfile(manifest *Manifest) bool
+}
+
+// WritesManifest reports whether Apply should rewrite the plan's manifest.
+func (p *UpdatePlan) WritesManifest() bool {
+	return p.Lockfile != LockfileOnly
+}
+
+// WritesLockfile reports whether Apply should rewrite the plan's lockfile.
+func (p *UpdatePlan) WritesLockfile() bool {
+	return p.Lockfile != LockfileSkip
+}
+
+// SetLockfileMode overrides the versioning strategy of every integration for
+// the files Update writes. LockfileDefault restores the configured behavior.
+func (e *Engine) SetLockfileMode(mode LockfileMode) {
+	e.lockfileMode = mode
+	e.logger.Debug("set lockfile mode", "mode", mode)
+}
+
+// SetFixLockfile makes Update ask integrations that support it to regenerate
+// lockfiles with their native tool (e.g., nix flake lock) instead of editing
+// them directly.
+func (e *Engine) SetFixLockfile(fix bool) {
+	e.fixLockfile = fix
+	e.logger.Debug("set fix lockfile", "enabled", fix)
+}
+
+// effectiveLockfileMode returns the lockfile mode for an integration: the mode
+// set on the engine, otherwise lockfile-only when the integration's
+// versioning_strategy is lockfile-only.
+func (e *Engine) effectiveLockfileMode(integrationName string) LockfileMode {
+	if e.lockfileMode != LockfileDefault {
+		return e.lockfileMode
+	}
+	if policy, ok := e.policies[integrationName]; ok && strings.EqualFold(policy.VersioningStrategy, string(LockfileOnly)) {
+		return LockfileOnly
+	}
+	return LockfileDefault
+}
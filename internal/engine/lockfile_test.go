@@ -0,0 +1,149 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// lockfileIntegration records the lockfile mode of each plan it applies.
+type lockfileIntegration struct {
+	mockIntegration
+	modes   []LockfileMode
+	fixes   []bool
+	modesMu sync.Mutex
+}
+
+func (l *lockfileIntegration) HasLockfile(manifest *Manifest) bool {
+	return true
+}
+
+func (l *lockfileIntegration) Apply(ctx context.Context, plan *UpdatePlan) (*ApplyResult, error) {
+	l.modesMu.Lock()
+	l.modes = append(l.modes, plan.Lockfile)
+	l.fixes = append(l.fixes, plan.FixLockfile)
+	l.modesMu.Unlock()
+	return &ApplyResult{Manifest: plan.Manifest, Applied: len(plan.Updates)}, nil
+}
+
+func TestParseLockfileMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    LockfileMode
+		wantErr bool
+	}{
+		{input: "", want: LockfileDefault},
+		{input: "lockfile-only", want: LockfileOnly},
+		{input: "no-lockfile", want: LockfileSkip},
+		{input: "both", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLockfileMode(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLockfileMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLockfileMode(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUpdate_LockfileMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           LockfileMode
+		strategy       string
+		wantMode       LockfileMode
+		wantPlainApply int
+	}{
+		{name: "default", wantMode: LockfileDefault, wantPlainApply: 1},
+		{name: "no-lockfile", mode: LockfileSkip, wantMode: LockfileSkip, wantPlainApply: 1},
+		{name: "lockfile-only skips manifests without lockfile", mode: LockfileOnly, wantMode: LockfileOnly},
+		{name: "versioning_strategy lockfile-only", strategy: "lockfile-only", wantMode: LockfileOnly},
+		{name: "flag overrides versioning_strategy", mode: LockfileSkip, strategy: "lockfile-only", wantMode: LockfileSkip, wantPlainApply: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withLock := &lockfileIntegration{mockIntegration: mockIntegration{name: "locked"}}
+			plain := &mockIntegration{name: "plain"}
+
+			eng := NewEngine(nil)
+			eng.Register(withLock)
+			eng.Register(plain)
+			eng.SetLockfileMode(tt.mode)
+			if tt.strategy != "" {
+				eng.SetPolicies(map[string]IntegrationPolicy{
+					"locked": {VersioningStrategy: tt.strategy},
+					"plain":  {VersioningStrategy: tt.strategy},
+				})
+			}
+
+			update := Update{Dependency: Dependency{Name: "dep"}, TargetVersion: "2.0.0"}
+			plans := []*UpdatePlan{
+				{Manifest: &Manifest{Path: "locked.txt", Type: "locked"}, Updates: []Update{update}},
+				{Manifest: &Manifest{Path: "plain.txt", Type: "plain"}, Updates: []Update{update}},
+			}
+
+			if _, err := eng.Update(context.Background(), plans, false); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+
+			if len(withLock.modes) != 1 || withLock.modes[0] != tt.wantMode {
+				t.Errorf("lockfile integration modes = %v, want [%q]", withLock.modes, tt.wantMode)
+			}
+			if plain.applyCalls != tt.wantPlainApply {
+				t.Errorf("plain integration applied %d times, want %d", plain.applyCalls, tt.wantPlainApply)
+			}
+			if plans[0].Lockfile != LockfileDefault {
+				t.Errorf("Update() modified caller's plan: Lockfile = %q", plans[0].Lockfile)
+			}
+		})
+	}
+}
+
+func TestUpdate_FixLockfile(t *testing.T) {
+	withLock := &lockfileIntegration{mockIntegration: mockIntegration{name: "locked"}}
+
+	eng := NewEngine(nil)
+	eng.Register(withLock)
+	eng.SetFixLockfile(true)
+
+	plans := []*UpdatePlan{{
+		Manifest: &Manifest{Path: "locked.txt", Type: "locked"},
+		Updates:  []Update{{Dependency: Dependency{Name: "dep"}, TargetVersion: "2.0.0"}},
+	}}
+
+	if _, err := eng.Update(context.Background(), plans, false); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	if len(withLock.fixes) != 1 || !withLock.fixes[0] {
+		t.Errorf("applied FixLockfile = %v, want [true]", withLock.fixes)
+	}
+	if plans[0].FixLockfile {
+		t.Error("Update() modified caller's plan: FixLockfile = true")
+	}
+}
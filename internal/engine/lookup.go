@@ -0,0 +1,95 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultLookupTimeout bounds a single dependency lookup during Plan. It is
+// shorter than the registry HTTP timeout so one slow registry request cannot
+// hold up the rest of a manifest's plan.
+const DefaultLookupTimeout = 20 * time.Second
+
+// UncheckedDependency is a dependency whose registry lookup did not finish in
+// time. It is reported alongside the plan rather than as an error.
+type UncheckedDependency struct {
+	Name       string `json:"name"`
+	Datasource string `json:"datasource,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+type lookupKey struct{}
+
+// lookupState carries the per-dependency timeout of a Plan call and collects
+// the lookups that exceeded it.
+type lookupState struct {
+	unchecked []UncheckedDependency
+	timeout   time.Duration
+	mu        sync.Mutex
+}
+
+// WithLookupTimeout returns a context under which each dependency lookup made
+// through LookupContext is limited to timeout. Timed-out lookups recorded with
+// RecordUnchecked are returned by UncheckedLookups.
+func WithLookupTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, lookupKey{}, &lookupState{timeout: timeout})
+}
+
+// LookupContext derives the context for a single dependency lookup, bounded by
+// the lookup timeout configured on ctx. Without one, ctx is returned as is.
+func LookupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	state, ok := ctx.Value(lookupKey{}).(*lookupState)
+	if !ok || state.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, state.timeout)
+}
+
+// RecordUnchecked notes a dependency whose lookup timed out. It is a no-op
+// when ctx carries no lookup timeout.
+func RecordUnchecked(ctx context.Context, dep UncheckedDependency) {
+	state, ok := ctx.Value(lookupKey{}).(*lookupState)
+	if !ok {
+		return
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	for _, existing := range state.unchecked {
+		if existing.Name == dep.Name && existing.Datasource == dep.Datasource {
+			return
+		}
+	}
+	state.unchecked = append(state.unchecked, dep)
+}
+
+// UncheckedLookups returns the dependencies recorded as unchecked under ctx.
+func UncheckedLookups(ctx context.Context) []UncheckedDependency {
+	state, ok := ctx.Value(lookupKey{}).(*lookupState)
+	if !ok {
+		return nil
+	}
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	return append([]UncheckedDependency(nil), state.unchecked...)
+}
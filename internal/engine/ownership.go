@@ -0,0 +1,117 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SupportedFiles returns the manifest file patterns an integration declares,
+// or nil when it does not implement FileLister.
+func SupportedFiles(integration Integration) []string {
+	lister, ok := integration.(FileLister)
+	if !ok {
+		return nil
+	}
+	return lister.SupportedFiles()
+}
+
+// MatchesSupportedFile reports whether the slash-separated relative path
+// matches a SupportedFiles pattern. A pattern with n segments is matched
+// against the last n segments of the path, so "Chart.yaml" matches
+// "charts/app/Chart.yaml" and ".github/workflows/*.yml" matches
+// ".github/workflows/ci.yml".
+func MatchesSupportedFile(pattern, relPath string) bool {
+	patternParts := strings.Split(pattern, "/")
+	pathParts := strings.Split(relPath, "/")
+	if len(patternParts) > len(pathParts) {
+		return false
+	}
+	tail := strings.Join(pathParts[len(pathParts)-len(patternParts):], "/")
+	matched, err := path.Match(pattern, tail)
+	return err == nil && matched
+}
+
+// ManifestOwners returns the registered integrations, sorted by name, whose
+// SupportedFiles match relPath. Integrations that declare no files never own
+// a manifest.
+func (e *Engine) ManifestOwners(relPath string) []string {
+	relPath = filepath.ToSlash(filepath.Clean(relPath))
+
+	var owners []string
+	for name, integration := range e.integrations {
+		for _, pattern := range SupportedFiles(integration) {
+			if MatchesSupportedFile(pattern, relPath) {
+				owners = append(owners, name)
+				break
+			}
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// ScanManifest scans a single manifest. Only the integrations owning the file
+// are run, and only the manifest at manifestPath is kept, or the directory
+// manifest containing it for integrations such as terraform that group files
+// by directory. manifestPath may be absolute or relative to repoRoot.
+func (e *Engine) ScanManifest(ctx context.Context, repoRoot, manifestPath string) (*ScanResult, error) {
+	relPath := manifestPath
+	if filepath.IsAbs(manifestPath) {
+		rel, err := filepath.Rel(repoRoot, manifestPath)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("manifest %s is outside the repository", manifestPath)
+		}
+		relPath = rel
+	}
+	relPath = filepath.Clean(relPath)
+
+	owners := e.ManifestOwners(relPath)
+	if len(owners) == 0 {
+		return nil, fmt.Errorf("no integration handles %s", manifestPath)
+	}
+
+	result, err := e.Scan(ctx, repoRoot, owners, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	kept := result.Manifests[:0]
+	for _, m := range result.Manifests {
+		p := m.Path
+		if filepath.IsAbs(p) {
+			if rel, relErr := filepath.Rel(repoRoot, p); relErr == nil {
+				p = rel
+			}
+		}
+		p = filepath.Clean(p)
+		if p == relPath || p == filepath.Dir(relPath) {
+			kept = append(kept, m)
+		}
+	}
+	result.Manifests = kept
+	return result, nil
+}
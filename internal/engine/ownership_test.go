@@ -0,0 +1,140 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// fileIntegration declares the manifest files it handles.
+type fileIntegration struct {
+	mockIntegration
+	files []string
+}
+
+func (f *fileIntegration) SupportedFiles() []string {
+	return f.files
+}
+
+func TestMatchesSupportedFile(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "package.json", path: "package.json", want: true},
+		{pattern: "package.json", path: "services/api/package.json", want: true},
+		{pattern: "package.json", path: "package.json.bak", want: false},
+		{pattern: "Dockerfile.*", path: "build/Dockerfile.dev", want: true},
+		{pattern: "*.tf", path: "infra/main.tf", want: true},
+		{pattern: ".github/workflows/*.yml", path: ".github/workflows/ci.yml", want: true},
+		{pattern: ".github/workflows/*.yml", path: "ci.yml", want: false},
+		{pattern: ".github/workflows/*.yml", path: "docs/workflows/ci.yml", want: false},
+		{pattern: ".devcontainer/*/devcontainer.json", path: ".devcontainer/go/devcontainer.json", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := MatchesSupportedFile(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchesSupportedFile(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestManifestOwners(t *testing.T) {
+	eng := NewEngine(nil)
+	eng.Register(&fileIntegration{mockIntegration: mockIntegration{name: "npm"}, files: []string{"package.json"}})
+	eng.Register(&fileIntegration{mockIntegration: mockIntegration{name: "actions"}, files: []string{".github/workflows/*.yml", "action.yml"}})
+	eng.Register(&fileIntegration{mockIntegration: mockIntegration{name: "yaml"}, files: []string{"*.yml"}})
+	eng.Register(&mockIntegration{name: "plain"})
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "web/package.json", want: []string{"npm"}},
+		{path: filepath.Join(".github", "workflows", "ci.yml"), want: []string{"actions", "yaml"}},
+		{path: "action.yml", want: []string{"actions", "yaml"}},
+		{path: "Cargo.toml", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := eng.ManifestOwners(tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ManifestOwners(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScanManifest(t *testing.T) {
+	repoRoot := t.TempDir()
+
+	npm := &fileIntegration{
+		mockIntegration: mockIntegration{
+			name: "npm",
+			detectManifests: []*Manifest{
+				{Path: "package.json", Type: "npm"},
+				{Path: filepath.Join("web", "package.json"), Type: "npm"},
+			},
+		},
+		files: []string{"package.json"},
+	}
+	helm := &fileIntegration{
+		mockIntegration: mockIntegration{name: "helm", detectManifests: []*Manifest{{Path: "Chart.yaml", Type: "helm"}}},
+		files:           []string{"Chart.yaml"},
+	}
+	terraform := &fileIntegration{
+		mockIntegration: mockIntegration{name: "terraform", detectManifests: []*Manifest{{Path: "infra", Type: "terraform"}}},
+		files:           []string{"*.tf"},
+	}
+
+	eng := NewEngine(nil)
+	eng.Register(npm)
+	eng.Register(helm)
+	eng.Register(terraform)
+
+	result, err := eng.ScanManifest(context.Background(), repoRoot, filepath.Join(repoRoot, "web", "package.json"))
+	if err != nil {
+		t.Fatalf("ScanManifest() error = %v", err)
+	}
+	if len(result.Manifests) != 1 || result.Manifests[0].Path != filepath.Join("web", "package.json") {
+		t.Errorf("ScanManifest() manifests = %v, want web/package.json only", result.Manifests)
+	}
+	if helm.detectCalls != 0 || terraform.detectCalls != 0 {
+		t.Errorf("ScanManifest() ran integrations that do not own the file: helm %d, terraform %d", helm.detectCalls, terraform.detectCalls)
+	}
+
+	result, err = eng.ScanManifest(context.Background(), repoRoot, filepath.Join("infra", "main.tf"))
+	if err != nil {
+		t.Fatalf("ScanManifest() error = %v", err)
+	}
+	if len(result.Manifests) != 1 || result.Manifests[0].Path != "infra" {
+		t.Errorf("ScanManifest() manifests = %v, want the infra directory manifest", result.Manifests)
+	}
+
+	if _, err := eng.ScanManifest(context.Background(), repoRoot, "Cargo.toml"); err == nil {
+		t.Error("ScanManifest() expected error for a file no integration handles")
+	}
+	if _, err := eng.ScanManifest(context.Background(), repoRoot, filepath.Join(filepath.Dir(repoRoot), "package.json")); err == nil {
+		t.Error("ScanManifest() expected error for a file outside the repository")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+// Preview computes the changes Update would make to each manifest without
+// writing any file. Each plan is applied in memory by its integration's
+// Rewriter and the result carries a unified diff of the manifest; plans of
+// integrations that cannot rewrite in memory are reported as errors.
+// Lockfiles are not previewed.
+func (e *Engine) Preview(ctx context.Context, plans []*UpdatePlan) (*UpdateResult, error) {
+	e.logger.Info("starting preview", "plans", len(plans))
+
+	var (
+		results []*ApplyResult
+		errs    []string
+	)
+	for _, p := range plans {
+		if len(p.Updates) == 0 {
+			continue
+		}
+
+		integration, ok := e.integrations[p.Manifest.Type]
+		if !ok {
+			errs = append(errs, fmt.Sprintf("no integration for type: %s", p.Manifest.Type))
+			continue
+		}
+		rewriter, ok := integration.(Rewriter)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: %s does not support previews", p.Manifest.Path, p.Manifest.Type))
+			continue
+		}
+
+		result, err := e.preview(ctx, rewriter, p)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", p.Manifest.Path, err))
+			continue
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Manifest.Path < results[j].Manifest.Path })
+
+	return &UpdateResult{
+		Results:   results,
+		Timestamp: e.timestamp(),
+		Errors:    errs,
+	}, nil
+}
+
+// preview rewrites one manifest in memory and diffs it against the file.
+func (e *Engine) preview(ctx context.Context, rewriter Rewriter, p *UpdatePlan) (*ApplyResult, error) {
+	absPath, err := filepath.Abs(p.Manifest.Path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+	content, err := secureio.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", p.Manifest.Path, err)
+	}
+
+	planned := *p
+	planned.Lockfile = e.effectiveLockfileMode(p.Manifest.Type)
+
+	rewritten, err := rewriter.Rewrite(ctx, &planned, content)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(p.Manifest.Path, string(content), string(rewritten.Content))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApplyResult{
+		Manifest:       p.Manifest,
+		ManifestDiff:   diff,
+		Errors:         rewritten.Errors,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+	}, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// previewIntegration bumps "version: 1.0.0" to "version: 2.0.0" in memory.
+type previewIntegration struct {
+	mockIntegration
+}
+
+func (p *previewIntegration) Rewrite(ctx context.Context, plan *UpdatePlan, content []byte) (*RewriteResult, error) {
+	updated := strings.Replace(string(content), "version: 1.0.0", "version: 2.0.0", 1)
+	return &RewriteResult{Content: []byte(updated), Applied: len(plan.Updates)}, nil
+}
+
+func TestPreview(t *testing.T) {
+	const content = "name: app\nversion: 1.0.0\ndescription: demo\n"
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Chart.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "other.yaml")
+	if err := os.WriteFile(other, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rewriter := &previewIntegration{mockIntegration{name: "preview"}}
+	plain := &mockIntegration{name: "plain"}
+	eng := NewEngine(nil)
+	eng.Register(rewriter)
+	eng.Register(plain)
+
+	update := Update{Dependency: Dependency{Name: "app", CurrentVersion: "1.0.0"}, TargetVersion: "2.0.0"}
+	plans := []*UpdatePlan{
+		{Manifest: &Manifest{Path: path, Type: "preview"}, Updates: []Update{update}},
+		{Manifest: &Manifest{Path: other, Type: "plain"}, Updates: []Update{update}},
+		{Manifest: &Manifest{Path: other, Type: "preview"}},
+	}
+
+	result, err := eng.Preview(context.Background(), plans)
+	if err != nil {
+		t.Fatalf("Preview() error = %v", err)
+	}
+
+	if len(result.Results) != 1 {
+		t.Fatalf("Preview() returned %d results, want 1", len(result.Results))
+	}
+	diff := result.Results[0].ManifestDiff
+	if !strings.Contains(diff, "-version: 1.0.0") || !strings.Contains(diff, "+version: 2.0.0") {
+		t.Errorf("ManifestDiff = %q, want the version bump", diff)
+	}
+	if result.Results[0].Applied != 1 {
+		t.Errorf("Applied = %d, want 1", result.Results[0].Applied)
+	}
+
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "does not support previews") {
+		t.Errorf("Preview() errors = %v, want one for the integration without Rewrite", result.Errors)
+	}
+
+	for _, p := range []string{path, other} {
+		got, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != content {
+			t.Errorf("Preview() modified %s: %q", p, got)
+		}
+	}
+	if rewriter.applyCalls != 0 || plain.applyCalls != 0 {
+		t.Error("Preview() called Apply")
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+// DefaultResumeStatePath is where plan progress is recorded for --resume.
+const DefaultResumeStatePath = ".uptool/plan-state.json"
+
+// resumeStateVersion is bumped when the state file format changes; state
+// files of another version are ignored.
+const resumeStateVersion = 2
+
+// ResumeState records the plans of manifests that finished planning, so a
+// run interrupted partway through (e.g. by a CI timeout) can be resumed
+// without repeating their registry lookups. Plans are recorded as the
+// integration produced them, before policy filters, and keyed by manifest
+// type, path, a hash of the scanned content, and a hash of the policy and CLI
+// flags, so an edited manifest or changed policy is planned again. The state
+// is saved after every completed manifest.
+type ResumeState struct {
+	plans map[string]*resumedPlan
+	path  string
+	mu    sync.Mutex
+}
+
+// resumedPlan is the part of an UpdatePlan stored in the state file; the
+// manifest itself comes from the current scan.
+type resumedPlan struct {
+	Strategy string   `json:"strategy"`
+	Updates  []Update `json:"updates"`
+}
+
+type resumeStateFile struct {
+	Plans   map[string]*resumedPlan `json:"plans"`
+	Version int                     `json:"version"`
+}
+
+// LoadResumeState reads the state file at path. A missing file, or one
+// written by an incompatible version, yields an empty state.
+func LoadResumeState(path string) (*ResumeState, error) {
+	state := &ResumeState{path: path, plans: make(map[string]*resumedPlan)}
+
+	data, err := secureio.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read resume state: %w", err)
+	}
+
+	var file resumeStateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse resume state %s: %w", path, err)
+	}
+	if file.Version == resumeStateVersion && file.Plans != nil {
+		state.plans = file.Plans
+	}
+	return state, nil
+}
+
+// Len returns the number of manifests with a recorded plan.
+func (s *ResumeState) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.plans)
+}
+
+// Clear removes the state file once a run has completed.
+func (s *ResumeState) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.plans = make(map[string]*resumedPlan)
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove resume state: %w", err)
+	}
+	return nil
+}
+
+// lookup returns the recorded plan for m, if its content and planCtx are
+// unchanged.
+func (s *ResumeState) lookup(m *Manifest, planCtx *PlanContext) (*UpdatePlan, bool) {
+	key, ok := resumeKey(m, planCtx)
+	if !ok {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recorded, ok := s.plans[key]
+	if !ok {
+		return nil, false
+	}
+	return &UpdatePlan{
+		Manifest: m,
+		Strategy: recorded.Strategy,
+		Updates:  recorded.Updates,
+	}, true
+}
+
+// record stores the unfiltered plan for m under planCtx and saves the state
+// file.
+func (s *ResumeState) record(m *Manifest, planCtx *PlanContext, plan *UpdatePlan) error {
+	key, ok := resumeKey(m, planCtx)
+	if !ok {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.plans[key] = &resumedPlan{Strategy: plan.Strategy, Updates: plan.Updates}
+	return s.save()
+}
+
+// save writes the state atomically so an interrupted write never leaves a
+// truncated file behind. The caller holds s.mu.
+func (s *ResumeState) save() error {
+	data, err := json.Marshal(resumeStateFile{Version: resumeStateVersion, Plans: s.plans})
+	if err != nil {
+		return fmt.Errorf("marshal resume state: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("create resume state directory: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := secureio.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write resume state: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write resume state: %w", err)
+	}
+	return nil
+}
+
+// resumeKey identifies a manifest by type, path, and content hash, and the
+// settings it is planned under by a hash of planCtx. Manifests scanned
+// without content cannot be told apart from edited ones and are always
+// planned.
+func resumeKey(m *Manifest, planCtx *PlanContext) (string, bool) {
+	if len(m.Content) == 0 {
+		return "", false
+	}
+	settings, err := json.Marshal(planCtx)
+	if err != nil {
+		return "", false
+	}
+
+	sum := sha256.Sum256(m.Content)
+	settingsSum := sha256.Sum256(settings)
+	return m.Type + ":" + filepath.ToSlash(m.Path) + "@" + hex.EncodeToString(sum[:]) +
+		"#" + hex.EncodeToString(settingsSum[:8]), true
+}
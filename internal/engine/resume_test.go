@@ -0,0 +1,282 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+// interruptingIntegration fails to plan the manifests in interrupted,
+// simulating a run that is cut off partway through.
+type interruptingIntegration struct {
+	interrupted map[string]bool
+	planned     []string
+	mu          sync.Mutex
+}
+
+func (i *interruptingIntegration) Name() string { return "npm" }
+
+func (i *interruptingIntegration) Detect(ctx context.Context, repoRoot string) ([]*Manifest, error) {
+	return nil, nil
+}
+
+func (i *interruptingIntegration) Plan(ctx context.Context, manifest *Manifest, planCtx *PlanContext) (*UpdatePlan, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.interrupted[manifest.Path] {
+		return nil, context.Canceled
+	}
+	i.planned = append(i.planned, manifest.Path)
+	return &UpdatePlan{
+		Manifest: manifest,
+		Strategy: "native_command",
+		Updates: []Update{
+			{Dependency: Dependency{Name: "lodash", CurrentVersion: "4.17.20"}, TargetVersion: "4.17.21", Impact: "patch"},
+		},
+	}, nil
+}
+
+func (i *interruptingIntegration) Apply(ctx context.Context, plan *UpdatePlan) (*ApplyResult, error) {
+	return &ApplyResult{Manifest: plan.Manifest}, nil
+}
+
+func (i *interruptingIntegration) Validate(ctx context.Context, manifest *Manifest) error {
+	return nil
+}
+
+func resumeManifests() []*Manifest {
+	return []*Manifest{
+		{Path: "a/package.json", Type: "npm", Content: []byte(`{"name":"a"}`)},
+		{Path: "b/package.json", Type: "npm", Content: []byte(`{"name":"b"}`)},
+		{Path: "c/package.json", Type: "npm", Content: []byte(`{"name":"c"}`)},
+	}
+}
+
+func TestPlanWithOptions_Resume(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), ".uptool", "plan-state.json")
+
+	integration := &interruptingIntegration{interrupted: map[string]bool{"c/package.json": true}}
+	e := NewEngine(nil)
+	e.Register(integration)
+
+	// First run is interrupted before c/package.json finishes
+	state, err := LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	result, err := e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state})
+	if err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("first run errors = %v, want 1", result.Errors)
+	}
+	if state.Len() != 2 {
+		t.Errorf("recorded plans = %d, want 2", state.Len())
+	}
+
+	// Second run resumes from the state file on disk
+	integration.interrupted = nil
+	integration.planned = nil
+	state, err = LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	result, err = e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state})
+	if err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	if len(integration.planned) != 1 || integration.planned[0] != "c/package.json" {
+		t.Errorf("second run planned %v, want only [c/package.json]", integration.planned)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("second run errors = %v, want none", result.Errors)
+	}
+
+	var paths []string
+	for _, plan := range result.Plans {
+		paths = append(paths, plan.Manifest.Path)
+		if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != "4.17.21" {
+			t.Errorf("plan for %s has updates %+v, want lodash 4.17.21", plan.Manifest.Path, plan.Updates)
+		}
+	}
+	sort.Strings(paths)
+	if len(paths) != 3 {
+		t.Errorf("second run plans = %v, want all three manifests", paths)
+	}
+
+	if err := state.Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := os.Stat(statePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("state file still exists after Clear(): %v", err)
+	}
+}
+
+func TestPlanWithOptions_ResumeReplansChangedManifest(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "plan-state.json")
+
+	integration := &interruptingIntegration{}
+	e := NewEngine(nil)
+	e.Register(integration)
+
+	state, err := LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	if _, err := e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state}); err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	manifests := resumeManifests()
+	manifests[1].Content = []byte(`{"name":"b","version":"2.0.0"}`)
+	integration.planned = nil
+
+	state, err = LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	if _, err := e.PlanWithOptions(context.Background(), manifests, &PlanOptions{Resume: state}); err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	if len(integration.planned) != 1 || integration.planned[0] != "b/package.json" {
+		t.Errorf("planned %v, want only the edited [b/package.json]", integration.planned)
+	}
+}
+
+func TestPlanWithOptions_ResumeAppliesCurrentFilters(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "plan-state.json")
+
+	integration := &interruptingIntegration{}
+	e := NewEngine(nil)
+	e.Register(integration)
+	e.SetPolicies(map[string]IntegrationPolicy{"npm": {
+		Enabled: true,
+		Groups: map[string]*DependencyGroup{
+			"lodash": {Patterns: []string{"lodash"}},
+			"react":  {Patterns: []string{"react*"}},
+		},
+	}})
+
+	state, err := LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	if _, err := e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state, OnlyGroup: "react"}); err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	// A resumed run with another group sees the updates the first run filtered out
+	integration.planned = nil
+	state, err = LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	result, err := e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state, OnlyGroup: "lodash"})
+	if err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	if len(integration.planned) != 0 {
+		t.Errorf("resumed run planned %v, want none", integration.planned)
+	}
+	for _, plan := range result.Plans {
+		if len(plan.Updates) != 1 || plan.Updates[0].Group != "lodash" {
+			t.Errorf("plan for %s has updates %+v, want lodash in group lodash", plan.Manifest.Path, plan.Updates)
+		}
+	}
+}
+
+func TestPlanWithOptions_ResumeReplansChangedPolicy(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "plan-state.json")
+
+	integration := &interruptingIntegration{}
+	e := NewEngine(nil)
+	e.Register(integration)
+
+	state, err := LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	if _, err := e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state}); err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	integration.planned = nil
+	e.SetCLIFlags(&CLIFlags{UpdateLevel: "patch"})
+	state, err = LoadResumeState(statePath)
+	if err != nil {
+		t.Fatalf("LoadResumeState() error = %v", err)
+	}
+	if _, err := e.PlanWithOptions(context.Background(), resumeManifests(), &PlanOptions{Resume: state}); err != nil {
+		t.Fatalf("PlanWithOptions() error = %v", err)
+	}
+
+	if len(integration.planned) != 3 {
+		t.Errorf("planned %v, want every manifest planned again under the new flags", integration.planned)
+	}
+}
+
+func TestLoadResumeState(t *testing.T) {
+	t.Run("missing file", func(t *testing.T) {
+		state, err := LoadResumeState(filepath.Join(t.TempDir(), "missing.json"))
+		if err != nil {
+			t.Fatalf("LoadResumeState() error = %v", err)
+		}
+		if state.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", state.Len())
+		}
+	})
+
+	t.Run("corrupt file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "plan-state.json")
+		if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadResumeState(path); err == nil {
+			t.Error("LoadResumeState() expected error for corrupt file")
+		}
+	})
+
+	t.Run("other version is ignored", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "plan-state.json")
+		data := []byte(`{"version":99,"plans":{"npm:package.json@abc":{"strategy":"x","updates":[]}}}`)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		state, err := LoadResumeState(path)
+		if err != nil {
+			t.Fatalf("LoadResumeState() error = %v", err)
+		}
+		if state.Len() != 0 {
+			t.Errorf("Len() = %d, want 0", state.Len())
+		}
+	})
+}
@@ -26,6 +26,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/robfig/cron/v3"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 )
@@ -40,6 +41,19 @@ const (
 	intervalYearly       = "yearly"
 )
 
+// cronParser parses standard 5-field cron expressions:
+// minute hour day-of-month month day-of-week.
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// ParseCron parses a standard 5-field cron expression such as "0 9 * * 1".
+func ParseCron(expr string) (cron.Schedule, error) {
+	schedule, err := cronParser.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	return schedule, nil
+}
+
 // ScheduleChecker handles schedule validation and enforcement.
 type ScheduleChecker struct {
 	schedule *Schedule
@@ -271,6 +285,46 @@ func matchCronField(field string, value int) bool {
 	return value == exactVal
 }
 
+// NextRun returns the first time after now at which the schedule is due, in
+// the schedule's timezone. Interval schedules run at the configured Time
+// (midnight by default) on their Day; cron schedules follow the expression.
+// A nil schedule or one without an interval is always due and returns now.
+func (s *Schedule) NextRun(now time.Time) (time.Time, error) {
+	if s == nil || s.Interval == "" {
+		return now, nil
+	}
+
+	checker, err := NewScheduleChecker(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if strings.EqualFold(s.Interval, "cron") {
+		schedule, err := ParseCron(s.Cron)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return schedule.Next(now.In(checker.timezone)), nil
+	}
+
+	if s.Time != "" {
+		if _, _, err := parseTimeOfDay(s.Time); err != nil {
+			return time.Time{}, err
+		}
+	}
+
+	return checker.GetNextRunTime(now), nil
+}
+
+// parseTimeOfDay parses a 24-hour "HH:MM" time.
+func parseTimeOfDay(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q (must be HH:MM)", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
 // GetNextRunTime calculates the next scheduled run time.
 func (sc *ScheduleChecker) GetNextRunTime(from time.Time) time.Time {
 	if sc.schedule == nil || sc.schedule.Interval == "" {
@@ -482,3 +482,100 @@ func TestScheduleChecker_GetScheduleDescription(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCron(t *testing.T) {
+	for _, expr := range []string{"0 9 * * 1", "*/15 9-17 * * mon-fri", "0 0 1 1,7 *"} {
+		if _, err := ParseCron(expr); err != nil {
+			t.Errorf("ParseCron(%q) error = %v", expr, err)
+		}
+	}
+
+	for _, expr := range []string{"", "0 9 * *", "0 9 * * 1 2025", "60 * * * *", "@daily"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) error = nil, want error", expr)
+		}
+	}
+}
+
+func TestScheduleNextRun(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	tests := []struct {
+		now      time.Time
+		want     time.Time
+		schedule *Schedule
+		name     string
+	}{
+		{
+			name:     "weekly on monday 09:00 across the spring DST change",
+			schedule: &Schedule{Interval: "weekly", Day: "monday", Time: "09:00", Timezone: "America/New_York"},
+			// Friday before clocks move forward on Sunday 9 March
+			now:  time.Date(2025, 3, 7, 12, 0, 0, 0, newYork),
+			want: time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC), // 09:00 EDT
+		},
+		{
+			name:     "weekly on monday 09:00 before the scheduled time",
+			schedule: &Schedule{Interval: "weekly", Day: "monday", Time: "09:00", Timezone: "America/New_York"},
+			now:      time.Date(2025, 3, 10, 8, 59, 0, 0, newYork),
+			want:     time.Date(2025, 3, 10, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "weekly on monday 09:00 just after the scheduled time",
+			schedule: &Schedule{Interval: "weekly", Day: "monday", Time: "09:00", Timezone: "America/New_York"},
+			now:      time.Date(2025, 3, 10, 9, 0, 0, 0, newYork),
+			want:     time.Date(2025, 3, 17, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "cron across the autumn DST change",
+			schedule: &Schedule{Interval: "cron", Cron: "0 9 * * *", Timezone: "America/New_York"},
+			// Clocks move back on Sunday 2 November
+			now:  time.Date(2025, 11, 1, 10, 0, 0, 0, newYork),
+			want: time.Date(2025, 11, 2, 14, 0, 0, 0, time.UTC), // 09:00 EST
+		},
+		{
+			name:     "cron defaults to UTC",
+			schedule: &Schedule{Interval: "cron", Cron: "30 6 * * 1-5"},
+			now:      time.Date(2025, 1, 17, 7, 0, 0, 0, time.UTC), // Friday
+			want:     time.Date(2025, 1, 20, 6, 30, 0, 0, time.UTC),
+		},
+		{
+			name:     "daily defaults to midnight",
+			schedule: &Schedule{Interval: "daily"},
+			now:      time.Date(2025, 1, 17, 7, 0, 0, 0, time.UTC),
+			want:     time.Date(2025, 1, 18, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.schedule.NextRun(tt.now)
+			if err != nil {
+				t.Fatalf("NextRun() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextRun() = %v, want %v", got, tt.want.In(got.Location()))
+			}
+		})
+	}
+
+	t.Run("no schedule is always due", func(t *testing.T) {
+		now := time.Date(2025, 1, 17, 7, 0, 0, 0, time.UTC)
+		var schedule *Schedule
+		if got, err := schedule.NextRun(now); err != nil || !got.Equal(now) {
+			t.Errorf("NextRun() = %v, %v, want %v", got, err, now)
+		}
+	})
+
+	for _, schedule := range []*Schedule{
+		{Interval: "cron", Cron: "0 9 * *"},
+		{Interval: "daily", Timezone: "Mars/Olympus_Mons"},
+		{Interval: "daily", Time: "9am"},
+	} {
+		if _, err := schedule.NextRun(time.Now()); err == nil {
+			t.Errorf("NextRun() for %+v error = nil, want error", *schedule)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SetTrackedOnly makes Scan skip manifests that git does not track, such as
+// generated or vendored files. Outside a git repository every manifest is kept.
+func (e *Engine) SetTrackedOnly(trackedOnly bool) {
+	e.trackedOnly = trackedOnly
+	e.logger.Debug("set tracked-only", "enabled", trackedOnly)
+}
+
+// trackedFiles lists the files git tracks under repoRoot, as slash-separated
+// paths relative to it. ok is false when repoRoot is not in a git repository
+// or git is unavailable.
+func trackedFiles(ctx context.Context, repoRoot string) (tracked map[string]bool, ok bool) {
+	cmd := exec.CommandContext(ctx, "git", "ls-files", "-z")
+	cmd.Dir = repoRoot
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, false
+	}
+
+	tracked = make(map[string]bool)
+	for _, name := range bytes.Split(out, []byte{0}) {
+		if len(name) > 0 {
+			tracked[string(name)] = true
+		}
+	}
+	return tracked, true
+}
+
+// filterTracked drops manifests git does not track. Manifests are returned
+// unchanged when repoRoot is not in a git repository.
+func (e *Engine) filterTracked(ctx context.Context, manifests []*Manifest, repoRoot string) []*Manifest {
+	tracked, ok := trackedFiles(ctx, repoRoot)
+	if !ok {
+		e.logger.Warn("not a git repository, scanning untracked manifests too", "repo", repoRoot)
+		return manifests
+	}
+
+	filtered := make([]*Manifest, 0, len(manifests))
+	for _, m := range manifests {
+		rel := m.Path
+		if filepath.IsAbs(rel) {
+			var err error
+			if rel, err = filepath.Rel(repoRoot, rel); err != nil || strings.HasPrefix(rel, "..") {
+				e.logger.Debug("manifest outside repository skipped (tracked-only)", "path", m.Path)
+				continue
+			}
+		}
+		if !tracked[filepath.ToSlash(filepath.Clean(rel))] {
+			e.logger.Debug("manifest not tracked by git skipped", "path", m.Path)
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
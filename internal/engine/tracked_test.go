@@ -0,0 +1,124 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// trackedRepo creates a directory with a tracked and an untracked manifest.
+// When initGit is false the directory is not a git repository.
+func trackedRepo(t *testing.T, initGit bool) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+
+	for _, path := range []string{"app/package.json", "vendor/lib/package.json"} {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte("{}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if initGit {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available")
+		}
+		for _, args := range [][]string{{"init", "-q"}, {"add", "app/package.json"}} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+	}
+
+	return dir
+}
+
+func TestScan_TrackedOnly(t *testing.T) {
+	tests := []struct {
+		name        string
+		initGit     bool
+		trackedOnly bool
+		want        []string
+	}{
+		{name: "tracked only", initGit: true, trackedOnly: true, want: []string{"app/package.json"}},
+		{name: "flag off", initGit: true, trackedOnly: false, want: []string{"app/package.json", "vendor/lib/package.json"}},
+		{name: "not a git repository", initGit: false, trackedOnly: true, want: []string{"app/package.json", "vendor/lib/package.json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := trackedRepo(t, tt.initGit)
+
+			mock := &mockIntegration{
+				name: "npm",
+				detectManifests: []*Manifest{
+					{Path: "app/package.json", Type: "npm"},
+					{Path: filepath.Join(dir, "vendor/lib/package.json"), Type: "npm"},
+				},
+				planUpdates: []Update{
+					{Dependency: Dependency{Name: "express", CurrentVersion: "4.17.0"}, TargetVersion: "4.18.2", Impact: "minor"},
+				},
+			}
+
+			e := NewEngine(nil)
+			e.Register(mock)
+			e.SetTrackedOnly(tt.trackedOnly)
+
+			ctx := context.Background()
+			scanResult, err := e.Scan(ctx, dir, nil, nil)
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			planResult, err := e.Plan(ctx, scanResult.Manifests)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+
+			got := make(map[string]bool)
+			for _, plan := range planResult.Plans {
+				rel := plan.Manifest.Path
+				if filepath.IsAbs(rel) {
+					rel, _ = filepath.Rel(dir, rel)
+				}
+				got[filepath.ToSlash(rel)] = true
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("planned manifests = %v, want %v", got, tt.want)
+			}
+			for _, path := range tt.want {
+				if !got[path] {
+					t.Errorf("planned manifests = %v, missing %s", got, path)
+				}
+			}
+		})
+	}
+}
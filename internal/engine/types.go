@@ -25,6 +25,7 @@ package engine
 
 import (
 	"context"
+	"strings"
 	"time"
 )
 
@@ -48,8 +49,9 @@ type PlanContext struct {
 
 // CLIFlags represents command-line flag overrides for update behavior.
 type CLIFlags struct {
-	AllowPrerelease *bool
-	UpdateLevel     string
+	AllowPrerelease    *bool
+	UpdateLevel        string
+	PrereleaseChannels []string
 }
 
 // NewPlanContext creates a new PlanContext with default settings.
@@ -84,7 +86,34 @@ func (pc *PlanContext) WithCLIFlags(flags *CLIFlags) *PlanContext {
 // 1. CLI flags (highest)
 // 2. uptool.yaml policy
 // 3. Default ("major" - allow all updates, let constraints filter)
+//
+// The result is capped by the policy segment, if any.
 func (pc *PlanContext) EffectiveUpdateLevel() string {
+	level := pc.updateLevel()
+	if pc == nil || pc.Policy == nil || pc.Policy.Segment == "" {
+		return level
+	}
+
+	rank, ok := updateLevelRank[level]
+	if !ok {
+		rank = updateLevelRank["major"]
+	}
+	if segmentRank, ok := updateLevelRank[pc.Policy.Segment]; ok && segmentRank < rank {
+		return pc.Policy.Segment
+	}
+	return level
+}
+
+// updateLevelRank orders update levels from most to least restrictive.
+var updateLevelRank = map[string]int{
+	"none":  0,
+	"patch": 1,
+	"minor": 2,
+	"major": 3,
+}
+
+// updateLevel returns the update level before the segment cap is applied.
+func (pc *PlanContext) updateLevel() string {
 	if pc == nil {
 		return "major"
 	}
@@ -126,6 +155,69 @@ func (pc *PlanContext) EffectiveAllowPrerelease() bool {
 	return false
 }
 
+// EffectiveAllowDeprecated returns whether deprecated and retracted versions
+// may be proposed. Only uptool.yaml policy can allow them; the default is false.
+func (pc *PlanContext) EffectiveAllowDeprecated() bool {
+	if pc == nil || pc.Policy == nil {
+		return false
+	}
+	return pc.Policy.AllowDeprecated
+}
+
+// EffectivePrereleaseChannels returns the prerelease channels to consider, following precedence:
+// 1. CLI flags (highest)
+// 2. uptool.yaml policy
+// 3. Default (none - prereleases are governed by EffectiveAllowPrerelease alone)
+func (pc *PlanContext) EffectivePrereleaseChannels() []string {
+	if pc == nil {
+		return nil
+	}
+
+	// Highest precedence: CLI flags
+	if pc.CLIFlags != nil && len(pc.CLIFlags.PrereleaseChannels) > 0 {
+		return pc.CLIFlags.PrereleaseChannels
+	}
+
+	// Second precedence: uptool.yaml policy
+	if pc.Policy != nil {
+		return pc.Policy.PrereleaseChannels
+	}
+
+	return nil
+}
+
+// PrereleaseAllowed reports whether a version with the given prerelease
+// identifier (e.g. "rc.1", "beta2") may be selected. Stable versions are always
+// allowed. When channels is non-empty, only prereleases whose leading
+// identifier matches one of the channels are allowed, regardless of
+// allowPrerelease; otherwise allowPrerelease decides.
+func PrereleaseAllowed(prerelease string, allowPrerelease bool, channels []string) bool {
+	if prerelease == "" {
+		return true
+	}
+	if len(channels) == 0 {
+		return allowPrerelease
+	}
+
+	channel := prereleaseChannel(prerelease)
+	for _, c := range channels {
+		if strings.EqualFold(strings.TrimSpace(c), channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// prereleaseChannel extracts the channel name from a prerelease identifier:
+// "rc.1" -> "rc", "beta2" -> "beta", "alpha-3" -> "alpha".
+func prereleaseChannel(prerelease string) string {
+	channel := prerelease
+	if idx := strings.IndexAny(channel, ".-"); idx >= 0 {
+		channel = channel[:idx]
+	}
+	return strings.TrimRight(channel, "0123456789")
+}
+
 // ShouldRespectConstraints returns whether manifest constraints should be respected.
 // Constraints are always respected unless explicitly disabled.
 func (pc *PlanContext) ShouldRespectConstraints() bool {
@@ -168,6 +260,10 @@ type Manifest struct {
 	Type         string                 `json:"type"`
 	Dependencies []Dependency           `json:"dependencies"`
 	Content      []byte                 `json:"-"`
+	// LockfileContent is the content of the lockfile named by
+	// Metadata["lockfile"] at scan time, checked for changes before Update
+	// rewrites it.
+	LockfileContent []byte `json:"-"`
 }
 
 // Dependency represents a single dependency in a manifest.
@@ -177,6 +273,12 @@ type Dependency struct {
 	Constraint     string `json:"constraint,omitempty"`
 	Type           string `json:"type"` // direct, dev, peer, optional
 	Registry       string `json:"registry,omitempty"`
+	// Digest is the content digest the reference is pinned to alongside its
+	// version (e.g. "sha256:..." for container images), if any.
+	Digest string `json:"digest,omitempty"`
+	// Line is the 1-based manifest line declaring the dependency, or 0 when
+	// the integration does not track positions.
+	Line int `json:"line,omitempty"`
 }
 
 // IntegrationPolicy contains policy settings that apply to a specific integration.
@@ -201,6 +303,7 @@ type Dependency struct {
 //	      enabled: true
 //	      update: minor              # Allow patch + minor updates only
 //	      allow_prerelease: false    # Exclude beta/alpha versions
+//	      prerelease_channels: [rc]  # ...but opt into release candidates
 //	      pin: false                 # Keep version ranges (^1.2.3)
 //	      cadence: weekly            # Check for updates weekly
 //
@@ -215,14 +318,27 @@ type IntegrationPolicy struct {
 	Cadence               string                      `yaml:"cadence,omitempty" json:"cadence,omitempty"`
 	Update                string                      `yaml:"update" json:"update"`
 	Ignore                []IgnoreRule                `yaml:"ignore,omitempty" json:"ignore,omitempty"`
+	Repositories          []RepositoryConfig          `yaml:"repositories,omitempty" json:"repositories,omitempty"`
 	Reviewers             []string                    `yaml:"reviewers,omitempty" json:"reviewers,omitempty"`
 	Assignees             []string                    `yaml:"assignees,omitempty" json:"assignees,omitempty"`
 	Labels                []string                    `yaml:"labels,omitempty" json:"labels,omitempty"`
 	Allow                 []DependencyRule            `yaml:"allow,omitempty" json:"allow,omitempty"`
+	PrereleaseChannels    []string                    `yaml:"prerelease_channels,omitempty" json:"prerelease_channels,omitempty"`
 	OpenPullRequestsLimit int                         `yaml:"open_pull_requests_limit,omitempty" json:"open_pull_requests_limit,omitempty"`
 	Enabled               bool                        `yaml:"enabled" json:"enabled"`
 	AllowPrerelease       bool                        `yaml:"allow_prerelease" json:"allow_prerelease"`
 	Pin                   bool                        `yaml:"pin" json:"pin"`
+	// PinDigest pins updated container images by digest as well as by tag,
+	// including references that were pinned by tag only.
+	PinDigest bool `yaml:"pin_digest,omitempty" json:"pin_digest,omitempty"`
+	// AllowDeprecated lets version selection propose releases the registry
+	// marks as bad: npm deprecations and Go retractions. Releases that can no
+	// longer be installed (yanked, unpublished) are never proposed.
+	AllowDeprecated bool `yaml:"allow_deprecated,omitempty" json:"allow_deprecated,omitempty"`
+	// Segment is the highest version segment that may advance ("patch" or
+	// "minor"). Unlike Update it also caps CLI flags and keeps manifest
+	// constraints in effect: segment "patch" never leaves the current minor.
+	Segment string `yaml:"segment,omitempty" json:"segment,omitempty"`
 }
 
 // Impact describes the severity of an update.
@@ -269,21 +385,75 @@ const (
 
 // UpdatePlan describes planned updates for a manifest.
 type UpdatePlan struct {
-	Manifest *Manifest `json:"manifest"`
-	Strategy string    `json:"strategy"`
-	Updates  []Update  `json:"updates"`
+	Manifest *Manifest    `json:"manifest"`
+	Strategy string       `json:"strategy"`
+	Lockfile LockfileMode `json:"lockfile,omitempty"` // set by Engine.Update before Apply
+	Updates  []Update     `json:"updates"`
+	Held     []HeldUpdate `json:"held,omitempty"`
+	// FixLockfile asks Apply to regenerate lockfiles with the ecosystem's
+	// native tool rather than editing them; set by Engine.Update.
+	FixLockfile bool `json:"fix_lockfile,omitempty"`
+	// Unchecked lists dependencies whose lookup timed out; they may have updates.
+	Unchecked []UncheckedDependency `json:"unchecked,omitempty"`
+	// Yanked lists dependencies whose current version was withdrawn from
+	// their registry; set by plan --check-yanked.
+	Yanked []YankedDependency `json:"yanked,omitempty"`
+	// Filtered lists dependencies whose update a policy rule or a plan
+	// filter (e.g., --only-dependency) removed.
+	Filtered []Dependency `json:"filtered,omitempty"`
 }
 
 // Update represents a planned update for a dependency.
 type Update struct {
 	Info          *UpdateInfo  `json:"info,omitempty"`
+	Age           *UpdateAge   `json:"age,omitempty"` // release times, set by --fetch-info
 	Dependency    Dependency   `json:"dependency"`
 	TargetVersion string       `json:"target_version"`
+	TargetDigest  string       `json:"target_digest,omitempty"` // digest to pin TargetVersion to, if any
 	Impact        string       `json:"impact"`
 	ChangelogURL  string       `json:"changelog_url,omitempty"`
 	PolicySource  PolicySource `json:"policy_source,omitempty"`
 	Group         string       `json:"group,omitempty"`
 	Breaking      bool         `json:"breaking"`
+	Security      bool         `json:"security,omitempty"` // target fixes a known vulnerability
+	// SecurityAdvisories lists the advisories affecting the current version
+	// that the target version fixes; set by --only-security.
+	SecurityAdvisories []Advisory `json:"security_advisories,omitempty"`
+}
+
+// UpdateAge describes how old the current version of an update is and when
+// its target was released. Unknown release times are left zero.
+type UpdateAge struct {
+	CurrentReleasedAt time.Time `json:"current_released_at,omitzero"`
+	TargetReleasedAt  time.Time `json:"target_released_at,omitzero"`
+	// CurrentAgeDays is the whole days between the current release and the plan.
+	CurrentAgeDays int `json:"current_age_days,omitempty"`
+}
+
+// Advisory is a published security advisory, such as a GitHub Security
+// Advisory (GHSA).
+type Advisory struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+}
+
+// HeldUpdate is an available update withheld by a cooldown policy because its
+// release is newer than the configured number of days.
+type HeldUpdate struct {
+	ReleasedAt    time.Time `json:"released_at"`
+	Update        Update    `json:"update"`
+	CooldownDays  int       `json:"cooldown_days"`
+	DaysRemaining int       `json:"days_remaining"`
+}
+
+// YankedDependency is a dependency whose current version was yanked or
+// unpublished by its registry.
+type YankedDependency struct {
+	Dependency Dependency `json:"dependency"`
+	// SafeVersion is the nearest version that has not been withdrawn: the
+	// lowest stable release above the current one, else the highest below it.
+	SafeVersion string `json:"safe_version,omitempty"`
 }
 
 // ApplyResult contains the outcome of applying updates.
@@ -292,10 +462,27 @@ type ApplyResult struct {
 	ManifestDiff string    `json:"manifest_diff,omitempty"`
 	LockfileDiff string    `json:"lockfile_diff,omitempty"`
 	Errors       []string  `json:"errors,omitempty"`
-	Applied      int       `json:"applied"`
-	Failed       int       `json:"failed"`
+	// AppliedUpdates names the dependencies whose update was written, so
+	// callers can tell which updates of a partly failed plan took effect.
+	// Integrations that do not report it leave it nil.
+	AppliedUpdates []string `json:"applied_updates,omitempty"`
+	Applied        int      `json:"applied"`
+	Failed         int      `json:"failed"`
 }
 
+// Integration interface versions. Plugins declare the version they were built
+// against by exporting a func InterfaceVersion() int; uptool loads plugins whose
+// version falls within [MinInterfaceVersion, InterfaceVersion]. Capabilities
+// added after MinInterfaceVersion are exposed as optional interfaces (such as
+// PlanReconciler) so that older plugins keep working without them.
+const (
+	// InterfaceVersion is the Integration interface version of this build.
+	InterfaceVersion = 1
+
+	// MinInterfaceVersion is the oldest interface version plugins may declare.
+	MinInterfaceVersion = 1
+)
+
 // Integration defines the interface for ecosystem integrations.
 type Integration interface {
 	// Name returns the integration identifier
@@ -317,6 +504,46 @@ type Integration interface {
 	Validate(ctx context.Context, manifest *Manifest) error
 }
 
+// PlanReconciler is an optional interface for integrations that need to adjust
+// plans across manifests after each manifest has been planned individually.
+// For example, gomod uses it to propose a single target version for a module
+// required by several members of the same Go workspace.
+type PlanReconciler interface {
+	// ReconcilePlans receives all plans produced by the integration in a run,
+	// before policy filters are applied, along with the integration's plan
+	// context, and returns the (possibly modified) plans.
+	ReconcilePlans(plans []*UpdatePlan, planCtx *PlanContext) []*UpdatePlan
+}
+
+// Rewriter is an optional interface for integrations whose Apply rewrites the
+// manifest file. Rewrite applies a plan to manifest content in memory without
+// touching disk; Apply writes its result. Engine.Preview uses it to show the
+// changes an update would make.
+type Rewriter interface {
+	// Rewrite returns content with the plan's updates applied. Lockfiles are
+	// not part of the result.
+	Rewrite(ctx context.Context, plan *UpdatePlan, content []byte) (*RewriteResult, error)
+}
+
+// FileLister is an optional interface for integrations that declare the
+// manifest files they handle. Patterns are file names ("package.json") or
+// slash-separated globs matched against the end of a path
+// (".github/workflows/*.yml"). The engine uses them to find the integrations
+// owning a single manifest, and `uptool list -v` shows them.
+type FileLister interface {
+	// SupportedFiles returns the file name patterns of the integration's manifests.
+	SupportedFiles() []string
+}
+
+// RewriteResult is manifest content with a plan's updates applied in memory.
+type RewriteResult struct {
+	Content        []byte
+	Errors         []string // updates that could not be applied
+	AppliedUpdates []string // dependencies whose update was applied
+	Applied        int
+	Failed         int
+}
+
 // ScanResult aggregates all discovered manifests.
 type ScanResult struct {
 	Manifests []*Manifest `json:"manifests"`
@@ -425,6 +652,18 @@ type CooldownConfig struct {
 	SemverPatchDays int      `yaml:"semver_patch_days,omitempty" json:"semver_patch_days,omitempty"`
 }
 
+// RepositoryConfig configures how a package repository is queried.
+type RepositoryConfig struct {
+	// URL is the repository base URL (e.g., "https://charts.bitnami.com/bitnami").
+	URL string `yaml:"url" json:"url"`
+
+	// VerifyChecksum requires the repository index to match the SHA-256
+	// checksum the repository publishes next to it. This detects corrupted
+	// or truncated downloads, not a compromised repository, since the
+	// checksum is served by the same host.
+	VerifyChecksum bool `yaml:"verify_checksum,omitempty" json:"verify_checksum,omitempty"`
+}
+
 // CommitMessageConfig customizes the commit message format.
 type CommitMessageConfig struct {
 	// Prefix is prepended to commit messages (max 50 chars).
@@ -438,6 +677,10 @@ type CommitMessageConfig struct {
 	// IncludeScope adds dependency scope to commit messages.
 	// When true, adds "deps" or "deps-dev" scope.
 	IncludeScope bool `yaml:"include_scope,omitempty" json:"include_scope,omitempty"`
+
+	// IncludeBody adds a commit body listing each dependency bump.
+	// Each line shows the name, old and new versions, impact, and changelog link if known.
+	IncludeBody bool `yaml:"include_body,omitempty" json:"include_body,omitempty"`
 }
 
 // UpdateInfo contains detailed information about an update for PR descriptions.
@@ -0,0 +1,339 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// skippedDirs are never walked into by Detect: dependency caches and vendored
+// or fixture trees that hold manifests uptool does not own.
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"testdata":     true,
+}
+
+type excludePathsKey struct{}
+
+// SetExcludePaths adds gitignore-style patterns, relative to the repository
+// root, for Detect walks to skip on top of the root .gitignore.
+func (e *Engine) SetExcludePaths(patterns []string) {
+	e.excludePaths = patterns
+	e.logger.Debug("set exclude paths", "patterns", patterns)
+}
+
+// WithExcludePaths returns a context carrying extra exclude patterns for the
+// WalkFilter built under it. Scan attaches the engine's exclude paths this way.
+func WithExcludePaths(ctx context.Context, patterns []string) context.Context {
+	return context.WithValue(ctx, excludePathsKey{}, patterns)
+}
+
+// WalkFilter decides which paths a Detect walk skips. It skips hidden
+// directories, the directories in skippedDirs, paths ignored by the
+// repository root's .gitignore, and paths matching the exclude patterns
+// carried by the context. Nested .gitignore files are not read.
+type WalkFilter struct {
+	root     string
+	patterns []ignorePattern
+}
+
+// ignorePattern is one parsed line of a .gitignore file.
+type ignorePattern struct {
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// NewWalkFilter builds the filter for a Detect walk of repoRoot. A missing or
+// unreadable .gitignore is treated as empty.
+func NewWalkFilter(ctx context.Context, repoRoot string) *WalkFilter {
+	f := &WalkFilter{root: repoRoot}
+
+	// #nosec G304 - .gitignore is read from the repository being scanned
+	if content, err := os.ReadFile(filepath.Join(repoRoot, ".gitignore")); err == nil {
+		f.patterns = parseIgnorePatterns(content)
+	}
+
+	// Exclude patterns come last so they win over .gitignore negations
+	if extra, ok := ctx.Value(excludePathsKey{}).([]string); ok {
+		for _, line := range extra {
+			if p, ok := parseIgnorePattern(line); ok {
+				f.patterns = append(f.patterns, p)
+			}
+		}
+	}
+
+	return f
+}
+
+// ShouldSkipDir reports whether the walk should not descend into the
+// directory at path. The repository root itself is never skipped.
+func (f *WalkFilter) ShouldSkipDir(path string) bool {
+	rel, ok := f.relative(path)
+	if !ok {
+		return false
+	}
+	name := filepath.Base(path)
+	if strings.HasPrefix(name, ".") || skippedDirs[name] {
+		return true
+	}
+	return f.ignored(rel, true)
+}
+
+// ShouldSkipFile reports whether the file at path is ignored. Files inside
+// skipped directories are not reached by the walk, so only the file's own
+// path is matched. Symlinks resolving outside the repository are skipped so
+// Detect never reads files the repository does not own.
+func (f *WalkFilter) ShouldSkipFile(path string) bool {
+	rel, ok := f.relative(path)
+	if !ok {
+		return false
+	}
+	if escapesRoot(f.root, path) {
+		return true
+	}
+	return f.ignored(rel, false)
+}
+
+// Excludes reports whether a detected manifest at path is ignored by the
+// .gitignore or exclude patterns, either itself or through one of its
+// directories. Unlike ShouldSkipDir it does not apply the built-in skipped
+// and hidden directories, so Scan can use it on every integration's result.
+func (f *WalkFilter) Excludes(path string) bool {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.root, path)
+	}
+	rel, ok := f.relative(path)
+	if !ok {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if f.ignored(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+	return f.ignored(rel, false)
+}
+
+// relative returns path relative to the root in slash form. ok is false for
+// the root itself and for paths outside it.
+func (f *WalkFilter) relative(p string) (string, bool) {
+	rel, err := filepath.Rel(f.root, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// ignored applies the patterns in order; as in git, the last match decides.
+func (f *WalkFilter) ignored(rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range f.patterns {
+		if p.matches(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnorePatterns parses .gitignore content, skipping blank lines and comments.
+func parseIgnorePatterns(content []byte) []ignorePattern {
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if p, ok := parseIgnorePattern(scanner.Text()); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// parseIgnorePattern parses a single gitignore-style pattern. A pattern with
+// a slash other than a trailing one is anchored to the root; otherwise it
+// matches a file or directory name at any depth.
+func parseIgnorePattern(line string) (ignorePattern, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignorePattern{}, false
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, `\`)
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	if line == "" {
+		return ignorePattern{}, false
+	}
+
+	p.segments = strings.Split(line, "/")
+	return p, true
+}
+
+// matches reports whether the pattern matches a slash-separated relative path.
+func (p ignorePattern) matches(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	if !p.anchored {
+		parts = parts[len(parts)-1:]
+	}
+	return matchSegments(p.segments, parts)
+}
+
+// matchSegments matches path segments against pattern segments, where "**"
+// spans any number of segments and other segments use path.Match globbing.
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, err := path.Match(pattern[0], parts[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// escapesRoot reports whether path is a symlink whose target lies outside
+// root. Dangling symlinks count as escaping; regular files never do.
+func escapesRoot(root, path string) bool {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return true
+	}
+	return !within(realRoot, target)
+}
+
+// within reports whether path is root or lies beneath it.
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// filterIgnored drops manifests that the repository's .gitignore or the
+// exclude paths ignore, whatever walk their integration's Detect used.
+func (e *Engine) filterIgnored(ctx context.Context, manifests []*Manifest, repoRoot string) []*Manifest {
+	filter := NewWalkFilter(ctx, repoRoot)
+	if len(filter.patterns) == 0 {
+		return manifests
+	}
+
+	kept := make([]*Manifest, 0, len(manifests))
+	for _, m := range manifests {
+		if filter.Excludes(m.Path) {
+			e.logger.Debug("manifest ignored", "path", m.Path)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept
+}
+
+// resolveManifestLinks drops manifests that are symlinks escaping repoRoot
+// and keeps one manifest per integration for each real file, so a manifest
+// shared through symlinks is planned and rewritten once. The manifest at
+// the file's real path wins over its links. Manifests that cannot be
+// resolved on disk, such as one read from stdin, are kept as they are.
+func (e *Engine) resolveManifestLinks(manifests []*Manifest, repoRoot string) []*Manifest {
+	realRoot, err := filepath.EvalSymlinks(repoRoot)
+	if err != nil {
+		return manifests
+	}
+
+	type seenManifest struct {
+		index  int
+		isLink bool
+	}
+	seen := make(map[string]seenManifest)
+	kept := make([]*Manifest, 0, len(manifests))
+
+	for _, m := range manifests {
+		path := m.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(repoRoot, path)
+		}
+		info, err := os.Lstat(path)
+		if err != nil {
+			kept = append(kept, m)
+			continue
+		}
+
+		realPath, err := filepath.EvalSymlinks(path)
+		if err != nil || !within(realRoot, realPath) {
+			e.logger.Warn("manifest symlink points outside the repository, skipped", "path", m.Path)
+			continue
+		}
+
+		isLink := info.Mode()&os.ModeSymlink != 0
+		key := m.Type + "\x00" + realPath
+		prev, ok := seen[key]
+		if !ok {
+			seen[key] = seenManifest{index: len(kept), isLink: isLink}
+			kept = append(kept, m)
+			continue
+		}
+
+		e.logger.Debug("manifest resolves to an already detected file, skipped", "path", m.Path, "real_path", realPath)
+		if prev.isLink && !isLink {
+			kept[prev.index] = m
+			seen[key] = seenManifest{index: prev.index, isLink: false}
+		}
+	}
+
+	return kept
+}
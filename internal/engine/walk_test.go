@@ -0,0 +1,225 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package engine
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWalkFilter(t *testing.T) {
+	root := t.TempDir()
+	gitignore := `# build output
+/build
+dist/
+*.log
+!keep.log
+generated/**/go.mod
+`
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte(gitignore), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := WithExcludePaths(context.Background(), []string{"examples/", "keep.log"})
+	f := NewWalkFilter(ctx, root)
+
+	dirs := map[string]bool{
+		".":                 false,
+		"build":             true,
+		"src/build":         false,
+		"dist":              true,
+		"src/dist":          true,
+		"node_modules":      true,
+		"src/vendor":        true,
+		"testdata":          true,
+		".terraform":        true,
+		"examples":          true,
+		"src":               false,
+		"src/examples-more": false,
+	}
+	for rel, want := range dirs {
+		if got := f.ShouldSkipDir(filepath.Join(root, rel)); got != want {
+			t.Errorf("ShouldSkipDir(%q) = %v, want %v", rel, got, want)
+		}
+	}
+
+	files := map[string]bool{
+		"app.log":                 true,
+		"src/debug.log":           true,
+		"keep.log":                true, // negated by .gitignore, excluded again by --exclude-path
+		"dist":                    false,
+		"generated/a/b/go.mod":    true,
+		"generated/go.mod":        true,
+		"src/generated/go.mod":    false,
+		"go.mod":                  false,
+		"build.gradle":            false,
+		"src/package.json":        false,
+		"examples/package.json":   false, // directory-only pattern
+		"../outside/package.json": false,
+	}
+	for rel, want := range files {
+		if got := f.ShouldSkipFile(filepath.Join(root, rel)); got != want {
+			t.Errorf("ShouldSkipFile(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestWalkFilter_Negation(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("*.log\n!keep.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	f := NewWalkFilter(context.Background(), root)
+	if f.ShouldSkipFile(filepath.Join(root, "keep.log")) {
+		t.Error("ShouldSkipFile(keep.log) = true, want false (negated)")
+	}
+	if !f.ShouldSkipFile(filepath.Join(root, "other.log")) {
+		t.Error("ShouldSkipFile(other.log) = false, want true")
+	}
+}
+
+func TestWalkFilter_NoGitignore(t *testing.T) {
+	root := t.TempDir()
+	f := NewWalkFilter(context.Background(), root)
+
+	if f.ShouldSkipDir(root) {
+		t.Error("ShouldSkipDir(root) = true, want false")
+	}
+	if f.ShouldSkipFile(filepath.Join(root, "package.json")) {
+		t.Error("ShouldSkipFile(package.json) = true, want false")
+	}
+	if !f.ShouldSkipDir(filepath.Join(root, "node_modules")) {
+		t.Error("ShouldSkipDir(node_modules) = false, want true")
+	}
+}
+
+// symlinkRepo creates a repository with app/package.json, a link to it at
+// shared/package.json, and escape/package.json linking outside the repository.
+func symlinkRepo(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "package.json")
+	if err := os.WriteFile(outside, []byte(`{"name":"outside"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{"app", "shared", "escape"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(root, "app", "package.json"), []byte(`{"name":"app"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..", "app", "package.json"), filepath.Join(root, "shared", "package.json")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape", "package.json")); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestWalkFilter_Symlinks(t *testing.T) {
+	root := symlinkRepo(t)
+	f := NewWalkFilter(context.Background(), root)
+
+	files := map[string]bool{
+		"app/package.json":    false,
+		"shared/package.json": false,
+		"escape/package.json": true,
+	}
+	for rel, want := range files {
+		if got := f.ShouldSkipFile(filepath.Join(root, rel)); got != want {
+			t.Errorf("ShouldSkipFile(%q) = %v, want %v", rel, got, want)
+		}
+	}
+}
+
+func TestScan_Symlinks(t *testing.T) {
+	root := symlinkRepo(t)
+
+	e := NewEngine(nil)
+	e.Register(&mockIntegration{
+		name: "npm",
+		// Listed link first: the manifest at the real path must still win
+		detectManifests: []*Manifest{
+			{Path: "shared/package.json", Type: "npm"},
+			{Path: "app/package.json", Type: "npm"},
+			{Path: "escape/package.json", Type: "npm"},
+		},
+	})
+
+	result, err := e.Scan(context.Background(), root, nil, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	if len(result.Manifests) != 1 {
+		var paths []string
+		for _, m := range result.Manifests {
+			paths = append(paths, m.Path)
+		}
+		t.Fatalf("Scan() manifests = %v, want only app/package.json", paths)
+	}
+	if result.Manifests[0].Path != "app/package.json" {
+		t.Errorf("Scan() kept %s, want app/package.json", result.Manifests[0].Path)
+	}
+}
+
+func TestScan_IgnoredPaths(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ".gitignore"), []byte("build/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mock's Detect does not walk, so only Scan can drop these
+	e := NewEngine(nil)
+	e.SetExcludePaths([]string{"examples/"})
+	e.Register(&mockIntegration{
+		name: "npm",
+		detectManifests: []*Manifest{
+			{Path: "package.json", Type: "npm"},
+			{Path: "build/out/package.json", Type: "npm"},
+			{Path: "examples/demo/package.json", Type: "npm"},
+			{Path: filepath.Join(root, "examples", "package.json"), Type: "npm"},
+			{Path: "vendor/package.json", Type: "npm"},
+		},
+	})
+
+	result, err := e.Scan(context.Background(), root, nil, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	var paths []string
+	for _, m := range result.Manifests {
+		paths = append(paths, m.Path)
+	}
+	want := []string{"package.json", "vendor/package.json"}
+	if strings.Join(paths, ",") != strings.Join(want, ",") {
+		t.Errorf("Scan() manifests = %v, want %v", paths, want)
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package history records the dependency updates applied by uptool.
+// Entries are appended to a JSON Lines log (one entry per line) so the file
+// can be kept in version control and audited by date range.
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+// DefaultPath is the history log location relative to the repository root.
+const DefaultPath = ".uptool/history.jsonl"
+
+// Entry is a single applied dependency update.
+type Entry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Manifest      string    `json:"manifest"`
+	Integration   string    `json:"integration"`
+	Name          string    `json:"name"`
+	FromVersion   string    `json:"from_version"`
+	ToVersion     string    `json:"to_version"`
+	Impact        string    `json:"impact,omitempty"`
+	CommitMessage string    `json:"commit_message,omitempty"`
+}
+
+// EntriesFromPlan builds history entries for every update in plan.
+func EntriesFromPlan(plan *engine.UpdatePlan, commitMessage string, at time.Time) []Entry {
+	entries := make([]Entry, 0, len(plan.Updates))
+	for i := range plan.Updates {
+		u := &plan.Updates[i]
+		entries = append(entries, Entry{
+			Timestamp:     at,
+			Manifest:      plan.Manifest.Path,
+			Integration:   plan.Manifest.Type,
+			Name:          u.Dependency.Name,
+			FromVersion:   u.Dependency.CurrentVersion,
+			ToVersion:     u.TargetVersion,
+			Impact:        u.Impact,
+			CommitMessage: commitMessage,
+		})
+	}
+	return entries
+}
+
+// Append adds entries to the history log at path, creating it if needed.
+// Existing entries are never rewritten.
+func Append(path string, entries []Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	if err := secureio.ValidateFilePath(path); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range entries {
+		if err := enc.Encode(&entries[i]); err != nil {
+			return fmt.Errorf("encode history entry: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600) // #nosec G304 - path validated above
+	if err != nil {
+		return fmt.Errorf("open history: %w", err)
+	}
+
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close() //nolint:errcheck // write error takes precedence
+		return fmt.Errorf("write history: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close history: %w", err)
+	}
+
+	return nil
+}
+
+// Read loads all entries from the history log at path.
+// A missing log is not an error and yields no entries.
+func Read(path string) ([]Entry, error) {
+	data, err := secureio.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("parse history line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Filter returns entries recorded within [since, until], sorted by time.
+// A zero since or until leaves that end of the range open.
+func Filter(entries []Entry, since, until time.Time) []Entry {
+	result := make([]Entry, 0, len(entries))
+	for i := range entries {
+		ts := entries[i].Timestamp
+		if !since.IsZero() && ts.Before(since) {
+			continue
+		}
+		if !until.IsZero() && ts.After(until) {
+			continue
+		}
+		result = append(result, entries[i])
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Timestamp.Before(result[j].Timestamp)
+	})
+
+	return result
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".uptool", "history.jsonl")
+
+	jan := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	mar := time.Date(2026, 3, 2, 9, 30, 0, 0, time.UTC)
+
+	if err := Append(path, []Entry{{
+		Timestamp:     jan,
+		Manifest:      "package.json",
+		Integration:   "npm",
+		Name:          "express",
+		FromVersion:   "4.17.0",
+		ToVersion:     "4.18.2",
+		Impact:        "minor",
+		CommitMessage: "chore(deps): update express from 4.17.0 to 4.18.2",
+	}}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	if err := Append(path, []Entry{{
+		Timestamp:   mar,
+		Manifest:    "go.mod",
+		Integration: "gomod",
+		Name:        "github.com/spf13/cobra",
+		FromVersion: "v1.8.0",
+		ToVersion:   "v1.9.1",
+		Impact:      "minor",
+	}}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Read() returned %d entries, want 2", len(entries))
+	}
+
+	t.Run("filters by date range", func(t *testing.T) {
+		got := Filter(entries,
+			time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2026, 1, 31, 23, 59, 59, 0, time.UTC))
+
+		if len(got) != 1 {
+			t.Fatalf("Filter() returned %d entries, want 1", len(got))
+		}
+		if got[0].Name != "express" || got[0].ToVersion != "4.18.2" {
+			t.Errorf("Filter() = %+v, want express 4.18.2", got[0])
+		}
+		if got[0].CommitMessage == "" {
+			t.Error("Filter() entry lost its commit message")
+		}
+	})
+
+	t.Run("open range returns all entries in order", func(t *testing.T) {
+		got := Filter(entries, time.Time{}, time.Time{})
+		if len(got) != 2 {
+			t.Fatalf("Filter() returned %d entries, want 2", len(got))
+		}
+		if !got[0].Timestamp.Equal(jan) || !got[1].Timestamp.Equal(mar) {
+			t.Errorf("Filter() not sorted by time: %v, %v", got[0].Timestamp, got[1].Timestamp)
+		}
+	})
+
+	t.Run("since only", func(t *testing.T) {
+		got := Filter(entries, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), time.Time{})
+		if len(got) != 1 || got[0].Name != "github.com/spf13/cobra" {
+			t.Errorf("Filter() = %+v, want only the cobra update", got)
+		}
+	})
+}
+
+func TestRead_MissingFile(t *testing.T) {
+	entries, err := Read(filepath.Join(t.TempDir(), "history.jsonl"))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Read() returned %d entries, want 0", len(entries))
+	}
+}
+
+func TestRead_InvalidLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	if err := os.WriteFile(path, []byte("{not json}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path); err == nil {
+		t.Error("Read() expected error for invalid line")
+	}
+}
+
+func TestAppend_RelativePath(t *testing.T) {
+	if err := Append("history.jsonl", []Entry{{Name: "x"}}); err == nil {
+		t.Error("Append() expected error for relative path")
+	}
+}
+
+func TestEntriesFromPlan(t *testing.T) {
+	at := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "express", CurrentVersion: "4.17.0"},
+				TargetVersion: "4.18.2",
+				Impact:        "minor",
+			},
+		},
+	}
+
+	entries := EntriesFromPlan(plan, "chore(deps): update express", at)
+	if len(entries) != 1 {
+		t.Fatalf("EntriesFromPlan() returned %d entries, want 1", len(entries))
+	}
+
+	want := Entry{
+		Timestamp:     at,
+		Manifest:      "package.json",
+		Integration:   "npm",
+		Name:          "express",
+		FromVersion:   "4.17.0",
+		ToVersion:     "4.18.2",
+		Impact:        "minor",
+		CommitMessage: "chore(deps): update express",
+	}
+	if entries[0] != want {
+		t.Errorf("EntriesFromPlan() = %+v, want %+v", entries[0], want)
+	}
+}
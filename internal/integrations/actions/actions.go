@@ -19,7 +19,9 @@
 // SOFTWARE.
 
 // Package actions implements the GitHub Actions integration for updating workflow files.
-// It detects .github/workflows/*.yml files, parses action references (uses: owner/repo@ref),
+// It detects .github/workflows/*.yml files and composite action metadata files
+// (action.yml, action.yaml), parses action references (uses: owner/repo@ref)
+// and reusable workflow references (uses: owner/repo/.github/workflows/ci.yml@ref),
 // queries GitHub Releases for version updates, and rewrites workflow files while preserving
 // YAML structure and comments.
 //
@@ -50,15 +52,44 @@ func init() {
 
 const integrationName = "actions"
 
-// actionRefPattern matches GitHub Action references like:
+// Manifest kinds recorded in the "kind" metadata of detected files.
+const (
+	kindWorkflow        = "workflow"
+	kindCompositeAction = "composite-action"
+)
+
+// actionFiles are the metadata file names of an action.
+var actionFiles = map[string]bool{
+	"action.yml":  true,
+	"action.yaml": true,
+}
+
+// actionRefPattern matches GitHub Action and reusable workflow references like:
 // uses: actions/checkout@v4
 // uses: actions/checkout@v4.2.2
 // uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683
-var actionRefPattern = regexp.MustCompile(`^([a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+)@(.+)$`)
+// uses: github/codeql-action/init@v3
+// uses: owner/repo/.github/workflows/ci.yml@v1
+//
+// The first group is the full reference without the ref, the second the ref.
+var actionRefPattern = regexp.MustCompile(`^([a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+(?:/[^@\s]+)?)@(.+)$`)
+
+// tagCommentPattern matches a SHA-pinned reference followed by the tag it was resolved from:
+// uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4.2.2
+//
+// The first group is the full reference including the SHA, the second the tag.
+var tagCommentPattern = regexp.MustCompile(`uses:\s*["']?([^\s"'#]+@[0-9a-fA-F]{40})["']?\s+#\s*(v?\d+(?:\.\d+)*)\b`)
+
+// commitResolver resolves a tag to the commit SHA it points at. It is implemented
+// by the GitHub datasource and used to re-pin SHA-pinned actions.
+type commitResolver interface {
+	GetCommitSHA(ctx context.Context, pkg, ref string) (string, error)
+}
 
 // Integration implements GitHub Actions workflow updates.
 type Integration struct {
-	ds datasource.Datasource
+	ds      datasource.Datasource
+	commits commitResolver
 }
 
 // New creates a new GitHub Actions integration.
@@ -67,8 +98,10 @@ func New() *Integration {
 	if err != nil {
 		ds = datasource.NewGitHubDatasource()
 	}
+	commits, _ := ds.(commitResolver)
 	return &Integration{
-		ds: ds,
+		ds:      datasource.Cached(ds),
+		commits: commits,
 	}
 }
 
@@ -77,6 +110,16 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of workflow files and composite action metadata.
+func (i *Integration) SupportedFiles() []string {
+	return []string{
+		".github/workflows/*.yml",
+		".github/workflows/*.yaml",
+		"action.yml",
+		"action.yaml",
+	}
+}
+
 // Workflow represents the structure of a GitHub Actions workflow file.
 type Workflow struct {
 	Name string                 `yaml:"name,omitempty"`
@@ -95,9 +138,22 @@ type Job struct {
 	Env         map[string]string      `yaml:"env,omitempty"`
 	Needs       interface{}            `yaml:"needs,omitempty"`
 	If          string                 `yaml:"if,omitempty"`
+	Uses        string                 `yaml:"uses,omitempty"` // reusable workflow reference
 	Raw         map[string]interface{} `yaml:",inline"`
 }
 
+// Action represents the structure of an action metadata file (action.yml).
+type Action struct {
+	Name string     `yaml:"name,omitempty"`
+	Runs ActionRuns `yaml:"runs,omitempty"`
+}
+
+// ActionRuns describes how an action runs. Only composite actions have steps.
+type ActionRuns struct {
+	Using string `yaml:"using,omitempty"`
+	Steps []Step `yaml:"steps,omitempty"`
+}
+
 // Step represents a step in a job.
 type Step struct {
 	Name            string                 `yaml:"name,omitempty"`
@@ -111,8 +167,24 @@ type Step struct {
 	Raw             map[string]interface{} `yaml:",inline"`
 }
 
-// Detect finds GitHub Actions workflow files in the repository.
+// Detect finds GitHub Actions workflow files and composite action metadata
+// files in the repository.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	manifests, err := i.detectWorkflows(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	actions, err := i.detectCompositeActions(ctx, repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(manifests, actions...), nil
+}
+
+// detectWorkflows finds workflow files under .github/workflows.
+func (i *Integration) detectWorkflows(repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
 
 	workflowsDir := filepath.Join(repoRoot, ".github", "workflows")
@@ -159,6 +231,7 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 			Dependencies: deps,
 			Content:      content,
 			Metadata: map[string]interface{}{
+				"kind":          kindWorkflow,
 				"workflow_name": workflowName,
 				"action_count":  len(deps),
 			},
@@ -171,62 +244,186 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 	return manifests, err
 }
 
+// detectCompositeActions finds action.yml and action.yaml files of composite
+// actions anywhere in the repository, including local actions under .github.
+// Other action types have no steps and are skipped.
+func (i *Integration) detectCompositeActions(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	workflowsDir := filepath.Join(repoRoot, ".github", "workflows")
+
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// Local actions conventionally live under .github/actions
+			if path == workflowsDir || (info.Name() != ".github" && filter.ShouldSkipDir(path)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !actionFiles[info.Name()] || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		deps, actionName := i.extractActionDependencies(content)
+		if len(deps) == 0 {
+			return nil
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: deps,
+			Content:      content,
+			Metadata: map[string]interface{}{
+				"kind":         kindCompositeAction,
+				"action_name":  actionName,
+				"action_count": len(deps),
+			},
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
 // extractDependencies parses workflow content and extracts action references.
+// SHA-pinned references with a trailing tag comment use the tag as their constraint.
 func (i *Integration) extractDependencies(content []byte) ([]engine.Dependency, string) {
 	var workflow Workflow
 	if err := yaml.Unmarshal(content, &workflow); err != nil {
 		return nil, ""
 	}
 
-	deps := make([]engine.Dependency, 0)
-	seen := make(map[string]bool)
-
+	var refs []string
 	for jobName := range workflow.Jobs {
 		job := workflow.Jobs[jobName]
+
+		// Jobs that call a reusable workflow have no steps
+		refs = append(refs, job.Uses)
+
 		for _, step := range job.Steps {
-			if step.Uses == "" {
-				continue
-			}
+			refs = append(refs, step.Uses)
+		}
+	}
 
-			// Skip local actions (e.g., uses: ./.github/actions/my-action)
-			if strings.HasPrefix(step.Uses, "./") || strings.HasPrefix(step.Uses, ".\\") {
-				continue
-			}
+	return dependenciesFromRefs(content, refs), workflow.Name
+}
 
-			// Skip Docker Hub references (e.g., uses: docker://alpine:3.8)
-			if strings.HasPrefix(step.Uses, "docker://") {
-				continue
-			}
+// extractActionDependencies parses action metadata and extracts the action
+// references of a composite action's steps.
+func (i *Integration) extractActionDependencies(content []byte) ([]engine.Dependency, string) {
+	var action Action
+	if err := yaml.Unmarshal(content, &action); err != nil {
+		return nil, ""
+	}
 
-			matches := actionRefPattern.FindStringSubmatch(step.Uses)
-			if matches == nil {
-				continue
-			}
+	if !strings.EqualFold(action.Runs.Using, "composite") {
+		return nil, action.Name
+	}
 
-			repo := matches[1]
-			version := matches[2]
+	refs := make([]string, 0, len(action.Runs.Steps))
+	for _, step := range action.Runs.Steps {
+		refs = append(refs, step.Uses)
+	}
 
-			// Create unique key to avoid duplicates
-			key := fmt.Sprintf("%s@%s", repo, version)
-			if seen[key] {
-				continue
-			}
-			seen[key] = true
+	return dependenciesFromRefs(content, refs), action.Name
+}
+
+// dependenciesFromRefs converts uses: references found in content into
+// dependencies, skipping local and Docker references and duplicates.
+func dependenciesFromRefs(content []byte, refs []string) []engine.Dependency {
+	// Comments are dropped by the YAML decoder, so read tag comments from the raw content
+	tagComments := make(map[string]string)
+	for _, m := range tagCommentPattern.FindAllStringSubmatch(string(content), -1) {
+		tagComments[m[1]] = m[2]
+	}
+
+	deps := make([]engine.Dependency, 0)
+	seen := make(map[string]bool)
 
-			// Determine version type
-			depType := determineVersionType(version)
+	addRef := func(uses string) {
+		if uses == "" {
+			return
+		}
+
+		// Skip local actions and workflows (e.g., uses: ./.github/actions/my-action)
+		if strings.HasPrefix(uses, "./") || strings.HasPrefix(uses, ".\\") {
+			return
+		}
 
-			deps = append(deps, engine.Dependency{
-				Name:           repo,
-				CurrentVersion: version,
-				Constraint:     version,
-				Type:           depType,
-				Registry:       "github",
-			})
+		// Skip Docker Hub references (e.g., uses: docker://alpine:3.8)
+		if strings.HasPrefix(uses, "docker://") {
+			return
 		}
+
+		matches := actionRefPattern.FindStringSubmatch(uses)
+		if matches == nil {
+			return
+		}
+
+		// The name keeps any path segment so Apply can rewrite the reference verbatim
+		name := matches[1]
+		version := matches[2]
+
+		// Create unique key to avoid duplicates
+		key := fmt.Sprintf("%s@%s", name, version)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		// Determine version type
+		depType := determineVersionType(version)
+
+		constraint := version
+		if tag, ok := tagComments[uses]; ok && depType == "sha" {
+			constraint = tag
+		}
+
+		deps = append(deps, engine.Dependency{
+			Name:           name,
+			CurrentVersion: version,
+			Constraint:     constraint,
+			Type:           depType,
+			Registry:       "github",
+		})
 	}
 
-	return deps, workflow.Name
+	for _, uses := range refs {
+		addRef(uses)
+	}
+
+	return deps
+}
+
+// repository returns the owner/repo part of an action or reusable workflow reference.
+// For example, "owner/repo/.github/workflows/ci.yml" returns "owner/repo".
+func repository(name string) string {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return name
+	}
+	return parts[0] + "/" + parts[1]
 }
 
 // determineVersionType determines if a version is a tag, branch, or commit SHA.
@@ -263,13 +460,22 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 	updates := make([]engine.Update, 0, len(manifest.Dependencies))
 
 	for _, dep := range manifest.Dependencies {
-		// Skip SHA pinned actions by default (they're usually pinned for security)
+		// SHA pins are only updated when a "# vX.Y.Z" comment records their tag;
+		// the tag is then bumped and re-pinned to the new tag's SHA on Apply
+		currentRef := dep.CurrentVersion
+		prefix := "v"
 		if dep.Type == "sha" {
-			continue
+			if dep.Constraint == "" || dep.Constraint == dep.CurrentVersion {
+				continue
+			}
+			currentRef = dep.Constraint
+			if !strings.HasPrefix(currentRef, "v") {
+				prefix = ""
+			}
 		}
 
-		// Query GitHub releases for this action
-		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		// Query GitHub releases for the repository hosting this action or workflow
+		availableVersions, err := i.ds.GetVersions(ctx, repository(dep.Name))
 		if err != nil {
 			continue
 		}
@@ -279,7 +485,7 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 		}
 
 		// Extract current version number (strip 'v' prefix if present)
-		currentVersion := strings.TrimPrefix(dep.CurrentVersion, "v")
+		currentVersion := strings.TrimPrefix(currentRef, "v")
 
 		// Use policy-aware version selection
 		targetVersion, impact, err := resolve.SelectVersionWithContext(
@@ -292,11 +498,14 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 			continue
 		}
 
+		// Floating tags (v4, v4.1) keep their precision so they stay floating
+		targetVersion = truncateVersion(targetVersion, len(strings.Split(currentVersion, ".")))
+
 		// Add 'v' prefix back for GitHub Actions
-		targetVersionWithPrefix := "v" + targetVersion
+		targetVersionWithPrefix := prefix + targetVersion
 
 		// Skip if no update needed
-		if targetVersionWithPrefix == dep.CurrentVersion {
+		if targetVersionWithPrefix == currentRef {
 			continue
 		}
 
@@ -315,7 +524,18 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 	}, nil
 }
 
-// Apply executes the update by rewriting workflow files.
+// truncateVersion keeps the first n dot-separated components of version.
+func truncateVersion(version string, n int) string {
+	parts := strings.Split(version, ".")
+	if n <= 0 || n >= len(parts) {
+		return version
+	}
+	return strings.Join(parts[:n], ".")
+}
+
+// Apply executes the update by rewriting workflow and composite action files.
+// SHA-pinned references are re-pinned to the SHA of the new tag and their
+// tag comment is updated with it, so they stay pinned by SHA.
 func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
 	if len(plan.Updates) == 0 {
 		return &engine.ApplyResult{
@@ -334,44 +554,108 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("read workflow: %w", err)
 	}
 
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+
+	// Write updated content
+	if err := os.WriteFile(plan.Manifest.Path, rewritten.Content, 0o600); err != nil {
+		return nil, fmt.Errorf("write workflow: %w", err)
+	}
+
+	// Generate diff
+	diff := generateDiff(plan.Manifest.Path, string(oldContent), string(rewritten.Content))
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		ManifestDiff:   diff,
+		Errors:         rewritten.Errors,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to workflow content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent := string(content)
+	var applied, errs []string
+
 	// Create update map: old uses -> new uses
 	updateMap := make(map[string]string)
+	refNames := make(map[string]string)
 	for idx := range plan.Updates {
 		update := &plan.Updates[idx]
+		if update.Dependency.Type == "sha" {
+			updated, err := i.repinSHA(ctx, newContent, update)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+				continue
+			}
+			newContent = updated
+			applied = append(applied, update.Dependency.Name)
+			continue
+		}
+
 		oldRef := fmt.Sprintf("%s@%s", update.Dependency.Name, update.Dependency.CurrentVersion)
 		newRef := fmt.Sprintf("%s@%s", update.Dependency.Name, update.TargetVersion)
 		updateMap[oldRef] = newRef
+		refNames[oldRef] = update.Dependency.Name
 	}
 
 	// Replace action references in content
-	newContent := string(oldContent)
-	applied := 0
-
 	for oldRef, newRef := range updateMap {
 		if strings.Contains(newContent, oldRef) {
 			newContent = strings.ReplaceAll(newContent, oldRef, newRef)
-			applied++
+			applied = append(applied, refNames[oldRef])
 		}
 	}
 
-	// Write updated content
-	if err := os.WriteFile(plan.Manifest.Path, []byte(newContent), 0o600); err != nil {
-		return nil, fmt.Errorf("write workflow: %w", err)
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// repinSHA resolves the commit SHA of the update's target tag and rewrites the
+// SHA-pinned reference and its trailing tag comment together.
+func (i *Integration) repinSHA(ctx context.Context, content string, update *engine.Update) (string, error) {
+	if i.commits == nil {
+		return "", fmt.Errorf("cannot resolve commit SHA for %s", update.TargetVersion)
 	}
 
-	// Generate diff
-	diff := generateDiff(plan.Manifest.Path, string(oldContent), newContent)
+	sha, err := i.commits.GetCommitSHA(ctx, repository(update.Dependency.Name), update.TargetVersion)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s: %w", update.TargetVersion, err)
+	}
 
-	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       0,
-		ManifestDiff: diff,
-	}, nil
+	re := regexp.MustCompile(`(` + regexp.QuoteMeta(update.Dependency.Name) + `@)` +
+		regexp.QuoteMeta(update.Dependency.CurrentVersion) + `(["']?\s+#\s*)` +
+		regexp.QuoteMeta(update.Dependency.Constraint) + `\b`)
+	if !re.MatchString(content) {
+		return "", fmt.Errorf("pinned reference %s@%s not found", update.Dependency.Name, update.Dependency.CurrentVersion)
+	}
+
+	return re.ReplaceAllString(content, "${1}"+sha+"${2}"+update.TargetVersion), nil
 }
 
-// Validate checks if the workflow file is valid YAML.
+// Validate checks if the workflow or composite action file is valid YAML.
 func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	if kind, _ := manifest.Metadata["kind"].(string); kind == kindCompositeAction { //nolint:errcheck // absent kind means workflow
+		var action Action
+		if err := yaml.Unmarshal(manifest.Content, &action); err != nil {
+			return fmt.Errorf("invalid action YAML: %w", err)
+		}
+		if len(action.Runs.Steps) == 0 {
+			return fmt.Errorf("composite action has no steps defined")
+		}
+		return nil
+	}
+
 	var workflow Workflow
 	if err := yaml.Unmarshal(manifest.Content, &workflow); err != nil {
 		return fmt.Errorf("invalid workflow YAML: %w", err)
@@ -23,6 +23,7 @@ package actions
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -99,6 +100,26 @@ func TestIsHex(t *testing.T) {
 	}
 }
 
+func TestRepository(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"actions/checkout", "actions/checkout"},
+		{"github/codeql-action/init", "github/codeql-action"},
+		{"octo-org/shared/.github/workflows/ci.yml", "octo-org/shared"},
+		{"invalid", "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := repository(tt.name); got != tt.want {
+				t.Errorf("repository(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIntegration_ExtractDependencies(t *testing.T) {
 	integration := New()
 
@@ -216,6 +237,46 @@ jobs:
 			expectedCount:   2,
 			expectedActions: []string{"actions/checkout", "actions/setup-go"},
 		},
+		{
+			name: "reusable workflow",
+			content: `
+name: CI
+on: push
+jobs:
+  call:
+    uses: octo-org/shared/.github/workflows/ci.yml@v1.2.0
+    with:
+      go-version: "1.25"
+`,
+			expectedCount:   1,
+			expectedActions: []string{"octo-org/shared/.github/workflows/ci.yml"},
+		},
+		{
+			name: "action in repository subdirectory",
+			content: `
+name: CI
+on: push
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: github/codeql-action/init@v3
+`,
+			expectedCount:   1,
+			expectedActions: []string{"github/codeql-action/init"},
+		},
+		{
+			name: "skip local reusable workflow",
+			content: `
+name: CI
+on: push
+jobs:
+  call:
+    uses: ./.github/workflows/shared.yml
+`,
+			expectedCount:   0,
+			expectedActions: []string{},
+		},
 		{
 			name:            "no jobs",
 			content:         `name: Empty`,
@@ -457,6 +518,43 @@ func TestIntegration_Plan(t *testing.T) {
 		}
 	})
 
+	t.Run("resolves reusable workflows by repository", func(t *testing.T) {
+		mockDS := &mockDatasource{
+			versions: []string{"1.3.0", "1.2.0"},
+		}
+		integration := &Integration{ds: mockDS}
+
+		manifest := &engine.Manifest{
+			Path: ".github/workflows/ci.yml",
+			Type: "actions",
+			Dependencies: []engine.Dependency{
+				{
+					Name:           "octo-org/shared/.github/workflows/ci.yml",
+					CurrentVersion: "v1.2.0",
+					Type:           "tag",
+					Registry:       "github",
+				},
+			},
+		}
+
+		plan, err := integration.Plan(ctx, manifest, engine.NewPlanContext())
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		if len(mockDS.requested) != 1 || mockDS.requested[0] != "octo-org/shared" {
+			t.Errorf("GetVersions() called with %v, want [octo-org/shared]", mockDS.requested)
+		}
+
+		if len(plan.Updates) != 1 {
+			t.Fatalf("Plan() returned %d updates, want 1", len(plan.Updates))
+		}
+
+		if plan.Updates[0].TargetVersion != "v1.3.0" {
+			t.Errorf("Plan() target = %q, want %q", plan.Updates[0].TargetVersion, "v1.3.0")
+		}
+	})
+
 	t.Run("skips SHA pinned actions", func(t *testing.T) {
 		mockDS := &mockDatasource{
 			versions: []string{"4.2.2", "4.2.1"},
@@ -488,6 +586,73 @@ func TestIntegration_Plan(t *testing.T) {
 		}
 	})
 
+	t.Run("plans SHA pinned actions with a tag comment", func(t *testing.T) {
+		mockDS := &mockDatasource{
+			versions: []string{"4.2.2", "4.1.0"},
+		}
+		integration := &Integration{ds: mockDS}
+
+		content := []byte(`name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4.1.0
+`)
+		deps, _ := integration.extractDependencies(content)
+		if len(deps) != 1 || deps[0].Constraint != "v4.1.0" {
+			t.Fatalf("extractDependencies() = %+v, want constraint v4.1.0 from tag comment", deps)
+		}
+
+		planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "minor"}}
+		plan, err := integration.Plan(ctx, &engine.Manifest{Dependencies: deps}, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != "v4.2.2" {
+			t.Fatalf("Plan() updates = %+v, want v4.2.2", plan.Updates)
+		}
+		if plan.Updates[0].Impact != "minor" {
+			t.Errorf("Plan() impact = %q, want minor", plan.Updates[0].Impact)
+		}
+	})
+
+	t.Run("keeps floating tags floating", func(t *testing.T) {
+		mockDS := &mockDatasource{
+			versions: []string{"5.0.0", "4.2.2", "4.1.0"},
+		}
+		integration := &Integration{ds: mockDS}
+
+		manifest := &engine.Manifest{
+			Dependencies: []engine.Dependency{
+				{Name: "actions/checkout", CurrentVersion: "v4", Constraint: "v4", Type: "tag"},
+				{Name: "actions/cache", CurrentVersion: "v4.1", Constraint: "v4.1", Type: "tag"},
+			},
+		}
+
+		planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "minor"}}
+		plan, err := integration.Plan(ctx, manifest, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		// v4 already floats over 4.2.2; v4.1 moves to v4.2, not v4.2.2
+		if len(plan.Updates) != 1 || plan.Updates[0].Dependency.Name != "actions/cache" || plan.Updates[0].TargetVersion != "v4.2" {
+			t.Errorf("Plan() updates = %+v, want only actions/cache -> v4.2", plan.Updates)
+		}
+
+		planCtx = &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "major"}}
+		plan, err = integration.Plan(ctx, manifest, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 2 || plan.Updates[0].TargetVersion != "v5" {
+			t.Errorf("Plan() updates = %+v, want actions/checkout -> v5", plan.Updates)
+		}
+	})
+
 	t.Run("handles no available versions", func(t *testing.T) {
 		mockDS := &mockDatasource{
 			versions: []string{},
@@ -571,6 +736,165 @@ jobs:
 		}
 	})
 
+	t.Run("applies action and reusable workflow updates", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		workflowPath := filepath.Join(tmpDir, "ci.yml")
+		originalContent := `name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: octo-org/shared@v1.2.0
+  call:
+    uses: octo-org/shared/.github/workflows/ci.yml@v1.2.0
+`
+		if err := os.WriteFile(workflowPath, []byte(originalContent), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{
+				Path: workflowPath,
+			},
+			Updates: []engine.Update{
+				{
+					Dependency: engine.Dependency{
+						Name:           "octo-org/shared",
+						CurrentVersion: "v1.2.0",
+					},
+					TargetVersion: "v1.3.0",
+				},
+				{
+					Dependency: engine.Dependency{
+						Name:           "octo-org/shared/.github/workflows/ci.yml",
+						CurrentVersion: "v1.2.0",
+					},
+					TargetVersion: "v1.3.0",
+				},
+			},
+		}
+
+		result, err := integration.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+
+		if result.Applied != 2 {
+			t.Errorf("Apply() applied = %d, want 2", result.Applied)
+		}
+
+		updatedContent, _ := os.ReadFile(workflowPath)
+		for _, want := range []string{
+			"- uses: octo-org/shared@v1.3.0",
+			"uses: octo-org/shared/.github/workflows/ci.yml@v1.3.0",
+		} {
+			if !strings.Contains(string(updatedContent), want) {
+				t.Errorf("Apply() content missing %q:\n%s", want, updatedContent)
+			}
+		}
+	})
+
+	t.Run("re-pins SHA and tag comment together", func(t *testing.T) {
+		const (
+			oldSHA = "11bd71901bbe5b1630ceea73d27597364c9af683"
+			newSHA = "692973e3d937129bcbf40652eb9f2f61becf3332"
+		)
+
+		tmpDir := t.TempDir()
+		workflowPath := filepath.Join(tmpDir, "ci.yml")
+		originalContent := `name: CI
+on: push
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@` + oldSHA + `  # v4.1.0
+      - uses: actions/setup-node@v4
+`
+		if err := os.WriteFile(workflowPath, []byte(originalContent), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		pinned := &Integration{
+			ds:      &mockDatasource{},
+			commits: mockCommits{"actions/checkout@v4.2.2": newSHA},
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{
+				Path: workflowPath,
+			},
+			Updates: []engine.Update{
+				{
+					Dependency: engine.Dependency{
+						Name:           "actions/checkout",
+						CurrentVersion: oldSHA,
+						Constraint:     "v4.1.0",
+						Type:           "sha",
+					},
+					TargetVersion: "v4.2.2",
+				},
+			},
+		}
+
+		result, err := pinned.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+
+		if result.Applied != 1 || result.Failed != 0 {
+			t.Errorf("Apply() = %+v, want 1 applied", result)
+		}
+
+		updatedContent, _ := os.ReadFile(workflowPath)
+		want := "- uses: actions/checkout@" + newSHA + "  # v4.2.2\n"
+		if !strings.Contains(string(updatedContent), want) {
+			t.Errorf("Apply() content missing %q:\n%s", want, updatedContent)
+		}
+		if !strings.Contains(string(updatedContent), "- uses: actions/setup-node@v4\n") {
+			t.Errorf("Apply() changed the floating tag:\n%s", updatedContent)
+		}
+	})
+
+	t.Run("never replaces a SHA pin with a tag", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		workflowPath := filepath.Join(tmpDir, "ci.yml")
+		originalContent := "      - uses: actions/checkout@11bd71901bbe5b1630ceea73d27597364c9af683 # v4.1.0\n"
+		if err := os.WriteFile(workflowPath, []byte(originalContent), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		unresolved := &Integration{ds: &mockDatasource{}, commits: mockCommits{}}
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: workflowPath},
+			Updates: []engine.Update{
+				{
+					Dependency: engine.Dependency{
+						Name:           "actions/checkout",
+						CurrentVersion: "11bd71901bbe5b1630ceea73d27597364c9af683",
+						Constraint:     "v4.1.0",
+						Type:           "sha",
+					},
+					TargetVersion: "v4.2.2",
+				},
+			},
+		}
+
+		result, err := unresolved.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Failed != 1 || len(result.Errors) != 1 {
+			t.Errorf("Apply() = %+v, want 1 failure", result)
+		}
+
+		updatedContent, _ := os.ReadFile(workflowPath)
+		if string(updatedContent) != originalContent {
+			t.Errorf("Apply() modified the workflow:\n%s", updatedContent)
+		}
+	})
+
 	t.Run("handles empty updates", func(t *testing.T) {
 		plan := &engine.UpdatePlan{
 			Manifest: &engine.Manifest{
@@ -640,8 +964,9 @@ func TestGenerateDiff(t *testing.T) {
 
 // mockDatasource is a test double for datasource.Datasource
 type mockDatasource struct {
-	versions []string
-	err      error
+	versions  []string
+	requested []string
+	err       error
 }
 
 func (m *mockDatasource) Name() string {
@@ -659,6 +984,7 @@ func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (stri
 }
 
 func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	m.requested = append(m.requested, pkg)
 	if m.err != nil {
 		return nil, m.err
 	}
@@ -674,3 +1000,184 @@ func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datas
 		Versions: []datasource.VersionInfo{},
 	}, nil
 }
+
+// mockCommits is a test double for commitResolver keyed by "owner/repo@ref".
+type mockCommits map[string]string
+
+func (m mockCommits) GetCommitSHA(ctx context.Context, pkg, ref string) (string, error) {
+	if sha, ok := m[pkg+"@"+ref]; ok {
+		return sha, nil
+	}
+	return "", fmt.Errorf("ref not found: %s@%s", pkg, ref)
+}
+
+const compositeAction = `name: Setup toolchain
+description: Installs the toolchain
+runs:
+  using: composite
+  steps:
+    - uses: actions/setup-go@v5.0.0
+      with:
+        go-version: "1.25"
+    - uses: ./.github/actions/cache
+    - run: go version
+      shell: bash
+`
+
+func TestIntegration_DetectCompositeActions(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"action.yml":                         compositeAction,
+		".github/actions/lint/action.yaml":   compositeAction,
+		".github/workflows/ci.yml":           "on: push\njobs:\n  build:\n    runs-on: ubuntu-latest\n    steps:\n      - uses: actions/checkout@v4\n",
+		"docker-action/action.yml":           "name: Docker\nruns:\n  using: docker\n  image: Dockerfile\n",
+		"node_modules/some-pkg/action.yml":   compositeAction,
+		".github/workflows/nested/other.yml": "on: push\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := New().Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	kinds := make(map[string]string)
+	for _, m := range manifests {
+		kinds[filepath.ToSlash(m.Path)], _ = m.Metadata["kind"].(string)
+	}
+	want := map[string]string{
+		".github/workflows/ci.yml":         kindWorkflow,
+		"action.yml":                       kindCompositeAction,
+		".github/actions/lint/action.yaml": kindCompositeAction,
+	}
+	if len(kinds) != len(want) {
+		t.Errorf("Detect() found %v, want %v", kinds, want)
+	}
+	for path, kind := range want {
+		if kinds[path] != kind {
+			t.Errorf("Detect() kind of %s = %q, want %q", path, kinds[path], kind)
+		}
+	}
+
+	for _, m := range manifests {
+		if m.Path != "action.yml" {
+			continue
+		}
+		if len(m.Dependencies) != 1 || m.Dependencies[0].Name != "actions/setup-go" || m.Dependencies[0].CurrentVersion != "v5.0.0" {
+			t.Errorf("action.yml dependencies = %+v, want actions/setup-go@v5.0.0 only", m.Dependencies)
+		}
+		if m.Metadata["action_name"] != "Setup toolchain" {
+			t.Errorf("action_name = %v, want %q", m.Metadata["action_name"], "Setup toolchain")
+		}
+		if err := New().Validate(context.Background(), m); err != nil {
+			t.Errorf("Validate() error = %v", err)
+		}
+	}
+}
+
+func TestIntegration_ApplyCompositeAction(t *testing.T) {
+	actionPath := filepath.Join(t.TempDir(), "action.yml")
+	if err := os.WriteFile(actionPath, []byte(compositeAction), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:     actionPath,
+			Metadata: map[string]interface{}{"kind": kindCompositeAction},
+		},
+		Updates: []engine.Update{{
+			Dependency:    engine.Dependency{Name: "actions/setup-go", CurrentVersion: "v5.0.0", Type: "tag"},
+			TargetVersion: "v5.4.0",
+		}},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 {
+		t.Errorf("Apply() applied = %d, want 1", result.Applied)
+	}
+
+	content, err := os.ReadFile(actionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(compositeAction, "actions/setup-go@v5.0.0", "actions/setup-go@v5.4.0", 1)
+	if string(content) != want {
+		t.Errorf("Apply() wrote:\n%s\nwant:\n%s", content, want)
+	}
+}
+
+func TestIntegration_RepinCompositeActionSHA(t *testing.T) {
+	const (
+		oldSHA = "5d7f1f8f1b1a4d8f1c0e3a6b9d2c4e7f8a1b2c3d"
+		newSHA = "d72941d797fd3113feb6b93fd0dec494b13a2547"
+	)
+
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	actionPath := filepath.Join(tmpDir, ".github", "actions", "token", "action.yml")
+	original := `name: Token
+runs:
+  using: composite
+  steps:
+    - uses: actions/create-github-app-token@` + oldSHA + ` # v1.2.0
+    - uses: ./.github/actions/setup
+    - uses: docker://alpine:3.20
+`
+	if err := os.MkdirAll(filepath.Dir(actionPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(actionPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	integration := &Integration{
+		ds:      &mockDatasource{},
+		commits: mockCommits{"actions/create-github-app-token@v1.12.0": newSHA},
+	}
+
+	manifests, err := integration.Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+
+	deps := manifests[0].Dependencies
+	if len(deps) != 1 {
+		t.Fatalf("Detect() found %d dependencies, want only the SHA pin: %+v", len(deps), deps)
+	}
+	if deps[0].Type != "sha" || deps[0].Constraint != "v1.2.0" {
+		t.Errorf("dependency = %+v, want sha pin with constraint v1.2.0", deps[0])
+	}
+
+	manifests[0].Path = actionPath
+	plan := &engine.UpdatePlan{
+		Manifest: manifests[0],
+		Updates:  []engine.Update{{Dependency: deps[0], TargetVersion: "v1.12.0"}},
+	}
+	if _, err := integration.Apply(ctx, plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, err := os.ReadFile(actionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(original, oldSHA+" # v1.2.0", newSHA+" # v1.12.0", 1)
+	if string(content) != want {
+		t.Errorf("Apply() wrote:\n%s\nwant:\n%s", content, want)
+	}
+}
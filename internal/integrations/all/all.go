@@ -29,13 +29,27 @@ package all
 import (
 	// Import all integration packages to trigger init() functions
 	_ "github.com/santosr2/uptool/internal/integrations/actions"
+	_ "github.com/santosr2/uptool/internal/integrations/ansible"
 	_ "github.com/santosr2/uptool/internal/integrations/asdf"
+	_ "github.com/santosr2/uptool/internal/integrations/bundler"
+	_ "github.com/santosr2/uptool/internal/integrations/cargo"
+	_ "github.com/santosr2/uptool/internal/integrations/cocoapods"
+	_ "github.com/santosr2/uptool/internal/integrations/composer"
+	_ "github.com/santosr2/uptool/internal/integrations/devcontainer"
 	_ "github.com/santosr2/uptool/internal/integrations/docker"
+	_ "github.com/santosr2/uptool/internal/integrations/gitlabci"
+	_ "github.com/santosr2/uptool/internal/integrations/gitsubmodule"
 	_ "github.com/santosr2/uptool/internal/integrations/gomod"
+	_ "github.com/santosr2/uptool/internal/integrations/gradle"
 	_ "github.com/santosr2/uptool/internal/integrations/helm"
+	_ "github.com/santosr2/uptool/internal/integrations/maven"
 	_ "github.com/santosr2/uptool/internal/integrations/mise"
+	_ "github.com/santosr2/uptool/internal/integrations/mix"
+	_ "github.com/santosr2/uptool/internal/integrations/nix"
 	_ "github.com/santosr2/uptool/internal/integrations/npm"
+	_ "github.com/santosr2/uptool/internal/integrations/nuget"
 	_ "github.com/santosr2/uptool/internal/integrations/precommit"
+	_ "github.com/santosr2/uptool/internal/integrations/swift"
 	_ "github.com/santosr2/uptool/internal/integrations/terraform"
 	_ "github.com/santosr2/uptool/internal/integrations/tflint"
 )
@@ -0,0 +1,511 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package ansible implements the Ansible Galaxy integration for updating role and collection versions.
+// It detects requirements.yml and galaxy.yml files, queries the Ansible Galaxy API for newer
+// versions, and rewrites version fields in place so comments, quoting and layout are preserved.
+package ansible
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+)
+
+func init() {
+	integrations.Register("ansible", func() engine.Integration {
+		return New()
+	})
+}
+
+const integrationName = "ansible"
+
+// Manifest file names recognized by the integration.
+const (
+	requirementsFile    = "requirements.yml"
+	requirementsFileAlt = "requirements.yaml"
+	galaxyFile          = "galaxy.yml"
+)
+
+// Integration implements Ansible Galaxy role and collection updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new ansible integration.
+func New() *Integration {
+	ds, err := datasource.Get("ansible-galaxy")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewGalaxyDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Galaxy requirements and collection metadata files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{requirementsFile, requirementsFileAlt, galaxyFile}
+}
+
+// requirement is a versioned role or collection reference found in a manifest.
+type requirement struct {
+	// node is the scalar holding the version, used to rewrite it in place.
+	node    *yaml.Node
+	name    string
+	version string
+	kind    string
+}
+
+// Detect finds requirements.yml and galaxy.yml files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip hidden directories
+		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != repoRoot {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || !isManifestFile(info.Name()) {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		// Validate path for security
+		err = integrations.ValidateFilePath(path)
+		if err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		reqs, err := parseManifest(info.Name(), content)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+
+		manifest := &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: extractDependencies(reqs),
+			Content:      content,
+			Metadata: map[string]any{
+				"file_type": info.Name(),
+			},
+		}
+
+		manifests = append(manifests, manifest)
+
+		return nil
+	})
+
+	return manifests, err
+}
+
+// isManifestFile reports whether name is a file handled by the integration.
+func isManifestFile(name string) bool {
+	return name == requirementsFile || name == requirementsFileAlt || name == galaxyFile
+}
+
+// extractDependencies converts parsed requirements to engine dependencies.
+func extractDependencies(reqs []requirement) []engine.Dependency {
+	deps := make([]engine.Dependency, 0, len(reqs))
+
+	for _, req := range reqs {
+		deps = append(deps, engine.Dependency{
+			Name:           req.name,
+			CurrentVersion: req.version,
+			Constraint:     req.version, // Store original constraint (e.g., ">=1.0.0")
+			Type:           req.kind,
+			Registry:       "ansible-galaxy",
+		})
+	}
+
+	return deps
+}
+
+// parseManifest extracts versioned requirements from a requirements.yml or galaxy.yml file.
+func parseManifest(fileName string, content []byte) ([]requirement, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+
+	if fileName == galaxyFile {
+		return parseGalaxyDependencies(root), nil
+	}
+
+	switch root.Kind {
+	case yaml.SequenceNode:
+		// Legacy format: a top-level list of roles
+		return parseEntries(root, datasource.GalaxyKindRole), nil
+	case yaml.MappingNode:
+		var reqs []requirement
+		if roles := mappingValue(root, "roles"); roles != nil {
+			reqs = append(reqs, parseEntries(roles, datasource.GalaxyKindRole)...)
+		}
+		if collections := mappingValue(root, "collections"); collections != nil {
+			reqs = append(reqs, parseEntries(collections, datasource.GalaxyKindCollection)...)
+		}
+		return reqs, nil
+	default:
+		return nil, nil
+	}
+}
+
+// parseEntries parses a sequence of role or collection entries.
+// Entries without a version or sourced from git, URLs or local paths are skipped.
+func parseEntries(seq *yaml.Node, kind string) []requirement {
+	if seq.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	var reqs []requirement
+	for _, item := range seq.Content {
+		// Plain string entries carry no version to update
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+
+		versionNode := mappingValue(item, "version")
+		if versionNode == nil || versionNode.Kind != yaml.ScalarNode || versionNode.Value == "" {
+			continue
+		}
+
+		if isExternalSource(item) {
+			continue
+		}
+
+		name := scalarValue(item, "name")
+		if src := scalarValue(item, "src"); src != "" && kind == datasource.GalaxyKindRole {
+			name = src
+		}
+		if !isGalaxyName(name) {
+			continue
+		}
+
+		reqs = append(reqs, requirement{
+			name:    name,
+			version: versionNode.Value,
+			kind:    kind,
+			node:    versionNode,
+		})
+	}
+
+	return reqs
+}
+
+// parseGalaxyDependencies parses the dependencies map of a collection's galaxy.yml.
+func parseGalaxyDependencies(root *yaml.Node) []requirement {
+	deps := mappingValue(root, "dependencies")
+	if deps == nil || deps.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var reqs []requirement
+	for j := 0; j+1 < len(deps.Content); j += 2 {
+		name := deps.Content[j].Value
+		versionNode := deps.Content[j+1]
+
+		if versionNode.Kind != yaml.ScalarNode || !isGalaxyName(name) {
+			continue
+		}
+
+		reqs = append(reqs, requirement{
+			name:    name,
+			version: versionNode.Value,
+			kind:    datasource.GalaxyKindCollection,
+			node:    versionNode,
+		})
+	}
+
+	return reqs
+}
+
+// isExternalSource reports whether an entry is installed from git, a URL or the filesystem
+// rather than from Galaxy.
+func isExternalSource(entry *yaml.Node) bool {
+	if scm := scalarValue(entry, "scm"); scm != "" {
+		return true
+	}
+
+	switch scalarValue(entry, "type") {
+	case "", "galaxy":
+	default:
+		return true
+	}
+
+	for _, key := range []string{"src", "name"} {
+		value := scalarValue(entry, key)
+		if strings.Contains(value, "://") || strings.HasPrefix(value, "git+") ||
+			strings.HasPrefix(value, "git@") || strings.Contains(value, ",") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isGalaxyName reports whether name looks like a Galaxy "namespace.name" reference.
+func isGalaxyName(name string) bool {
+	if strings.ContainsAny(name, "/:@ ") || strings.HasPrefix(name, ".") {
+		return false
+	}
+	namespace, rest, ok := strings.Cut(name, ".")
+	return ok && namespace != "" && rest != ""
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for j := 0; j+1 < len(node.Content); j += 2 {
+		if node.Content[j].Value == key {
+			return node.Content[j+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the scalar value for key in a mapping node, or "".
+func scalarValue(node *yaml.Node, key string) string {
+	value := mappingValue(node, key)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return value.Value
+}
+
+// Plan determines available updates for Ansible roles and collections.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		// Compound ranges (">=1.0.0,<2.0.0") and wildcards cannot be rewritten safely
+		if dep.CurrentVersion == "*" || strings.Contains(dep.CurrentVersion, ",") {
+			continue
+		}
+
+		// Datasource expects format: "kind|namespace.name"
+		pkg := fmt.Sprintf("%s|%s", dep.Type, dep.Name)
+
+		availableVersions, err := i.ds.GetVersions(ctx, pkg)
+		if err != nil {
+			// Skip content we can't query
+			continue
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			dep.Constraint,
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "yaml_rewrite",
+	}, nil
+}
+
+// Apply executes the update by rewriting version fields in place.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", plan.Manifest.Path, err)
+	}
+
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+
+	newContent := string(rewritten.Content)
+	if newContent != string(oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, rewritten.Content, 0o600); err != nil {
+			return nil, fmt.Errorf("write %s: %w", plan.Manifest.Path, err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(filepath.Base(plan.Manifest.Path), string(oldContent), newContent)
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		Errors:         rewritten.Errors,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to requirements content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	reqs, err := parseManifest(filepath.Base(plan.Manifest.Path), content)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", plan.Manifest.Path, err)
+	}
+
+	// Create update map for quick lookup, keyed by kind and name
+	updateMap := make(map[string]string)
+	for j := range plan.Updates {
+		update := &plan.Updates[j]
+		updateMap[update.Dependency.Type+"|"+update.Dependency.Name] = update.TargetVersion
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var applied, errs []string
+
+	for _, req := range reqs {
+		target, ok := updateMap[req.kind+"|"+req.name]
+		if !ok {
+			continue
+		}
+
+		newValue := versionPrefix(req.version) + target
+		if err := resolve.ValidateConstraint(integrationName, newValue); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", req.name, err))
+			continue
+		}
+		if err := replaceScalar(lines, req.node, req.version, newValue); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", req.name, err))
+			continue
+		}
+		applied = append(applied, req.name)
+	}
+
+	return &engine.RewriteResult{
+		Content:        []byte(strings.Join(lines, "\n")),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// versionPrefix returns the range operator preceding a version (e.g., ">=" in ">=1.0.0").
+func versionPrefix(version string) string {
+	idx := strings.IndexFunc(version, func(r rune) bool {
+		return (r >= '0' && r <= '9') || r == 'v'
+	})
+	if idx <= 0 {
+		return ""
+	}
+	return version[:idx]
+}
+
+// replaceScalar replaces oldValue with newValue on the source line of node,
+// starting at the node's column so quoting and surrounding text are untouched.
+func replaceScalar(lines []string, node *yaml.Node, oldValue, newValue string) error {
+	lineIdx := node.Line - 1
+	if lineIdx < 0 || lineIdx >= len(lines) {
+		return fmt.Errorf("version line %d out of range", node.Line)
+	}
+
+	line := lines[lineIdx]
+	col := node.Column - 1
+	if col < 0 || col > len(line) {
+		col = 0
+	}
+
+	pos := strings.Index(line[col:], oldValue)
+	if pos < 0 {
+		return fmt.Errorf("version %q not found on line %d", oldValue, node.Line)
+	}
+	pos += col
+
+	lines[lineIdx] = line[:pos] + newValue + line[pos+len(oldValue):]
+	return nil
+}
+
+// Validate checks if the manifest is valid YAML with the expected structure.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	if _, err := parseManifest(filepath.Base(manifest.Path), manifest.Content); err != nil {
+		return fmt.Errorf("invalid %s: %w", filepath.Base(manifest.Path), err)
+	}
+	return nil
+}
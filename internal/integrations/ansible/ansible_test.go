@@ -0,0 +1,361 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package ansible
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testRequirements = `---
+# Roles pinned for the web tier
+roles:
+  - name: geerlingguy.docker
+    version: "6.1.0"
+  - src: geerlingguy.nginx
+    version: 3.1.4
+  - name: internal-role
+    src: git+https://github.com/example/internal-role.git
+    version: v1.0.0
+  - name: scm-role
+    src: https://github.com/example/scm-role
+    scm: git
+    version: main
+  - geerlingguy.java
+
+collections:
+  - name: community.general
+    version: ">=7.0.0"
+  - name: amazon.aws
+    version: 6.0.0 # keep in sync with CI
+  - name: https://github.com/example/collection.git
+    type: git
+    version: main
+`
+
+const testGalaxy = `namespace: example
+name: platform
+version: 1.0.0
+dependencies:
+  ansible.posix: "1.5.0"
+  community.docker: ">=3.0.0,<4.0.0"
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func TestNew(t *testing.T) {
+	integ := New()
+	if integ == nil {
+		t.Fatal("New() returned nil")
+	}
+	if integ.ds == nil {
+		t.Error("New() datasource is nil")
+	}
+}
+
+func TestName(t *testing.T) {
+	integ := New()
+	if integ.Name() != "ansible" {
+		t.Errorf("Name() = %q, want %q", integ.Name(), "ansible")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "requirements.yml"), []byte(testRequirements), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	collectionDir := filepath.Join(tmpDir, "collections", "platform")
+	if err := os.MkdirAll(collectionDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(collectionDir, "galaxy.yml"), []byte(testGalaxy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "playbook.yml"), []byte("- hosts: all\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	integ := New()
+	manifests, err := integ.Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("Detect() found %d manifests, want 2", len(manifests))
+	}
+
+	byPath := make(map[string]*engine.Manifest)
+	for _, m := range manifests {
+		byPath[m.Path] = m
+	}
+
+	t.Run("requirements.yml roles and collections", func(t *testing.T) {
+		m := byPath["requirements.yml"]
+		if m == nil {
+			t.Fatal("requirements.yml not detected")
+		}
+		if m.Type != "ansible" {
+			t.Errorf("Detect() type = %q, want %q", m.Type, "ansible")
+		}
+
+		want := map[string]string{
+			"role|geerlingguy.docker":      "6.1.0",
+			"role|geerlingguy.nginx":       "3.1.4",
+			"collection|community.general": ">=7.0.0",
+			"collection|amazon.aws":        "6.0.0",
+		}
+		if len(m.Dependencies) != len(want) {
+			t.Fatalf("Detect() dependencies = %d, want %d (git-sourced entries must be skipped): %+v",
+				len(m.Dependencies), len(want), m.Dependencies)
+		}
+		for _, dep := range m.Dependencies {
+			key := dep.Type + "|" + dep.Name
+			if want[key] != dep.CurrentVersion {
+				t.Errorf("dependency %s version = %q, want %q", key, dep.CurrentVersion, want[key])
+			}
+		}
+	})
+
+	t.Run("galaxy.yml collection dependencies", func(t *testing.T) {
+		m := byPath[filepath.Join("collections", "platform", "galaxy.yml")]
+		if m == nil {
+			t.Fatal("galaxy.yml not detected")
+		}
+		if len(m.Dependencies) != 2 {
+			t.Fatalf("Detect() dependencies = %d, want 2", len(m.Dependencies))
+		}
+		for _, dep := range m.Dependencies {
+			if dep.Type != datasource.GalaxyKindCollection {
+				t.Errorf("dependency %s type = %q, want %q", dep.Name, dep.Type, datasource.GalaxyKindCollection)
+			}
+		}
+	})
+
+	t.Run("legacy list format", func(t *testing.T) {
+		reqs, err := parseManifest("requirements.yml", []byte("- src: geerlingguy.mysql\n  version: 4.3.0\n"))
+		if err != nil {
+			t.Fatalf("parseManifest() error = %v", err)
+		}
+		if len(reqs) != 1 || reqs[0].name != "geerlingguy.mysql" || reqs[0].kind != datasource.GalaxyKindRole {
+			t.Errorf("parseManifest() = %+v, want single geerlingguy.mysql role", reqs)
+		}
+	})
+}
+
+func TestPlan(t *testing.T) {
+	ctx := context.Background()
+
+	reqs, err := parseManifest("requirements.yml", []byte(testRequirements))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &engine.Manifest{
+		Path:         "requirements.yml",
+		Type:         "ansible",
+		Dependencies: extractDependencies(reqs),
+	}
+
+	integ := &Integration{
+		ds: &mockDatasource{
+			versions: map[string][]string{
+				"role|geerlingguy.docker":      {"6.0.0", "6.1.0", "7.0.1"},
+				"role|geerlingguy.nginx":       {"3.1.4"},
+				"collection|community.general": {"7.0.0", "8.2.0", "9.0.0-rc1"},
+			},
+		},
+	}
+
+	planCtx := &engine.PlanContext{
+		Policy: &engine.IntegrationPolicy{
+			Update: "major",
+		},
+	}
+
+	plan, err := integ.Plan(ctx, manifest, planCtx)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+
+	if got["geerlingguy.docker"] != "7.0.1" {
+		t.Errorf("geerlingguy.docker target = %q, want %q", got["geerlingguy.docker"], "7.0.1")
+	}
+	if got["community.general"] != "8.2.0" {
+		t.Errorf("community.general target = %q, want %q", got["community.general"], "8.2.0")
+	}
+	if _, ok := got["geerlingguy.nginx"]; ok {
+		t.Error("geerlingguy.nginx is up to date and should not be planned")
+	}
+	if _, ok := got["amazon.aws"]; ok {
+		t.Error("amazon.aws could not be queried and should not be planned")
+	}
+}
+
+func TestApply(t *testing.T) {
+	ctx := context.Background()
+	integ := New()
+
+	t.Run("returns early for no updates", func(t *testing.T) {
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: "requirements.yml"},
+		}
+
+		result, err := integ.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 0 {
+			t.Errorf("Apply() applied = %d, want 0", result.Applied)
+		}
+	})
+
+	t.Run("updates roles and collections preserving formatting", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "requirements.yml")
+		if err := os.WriteFile(path, []byte(testRequirements), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: path, Type: "ansible"},
+			Updates: []engine.Update{
+				{
+					Dependency:    engine.Dependency{Name: "geerlingguy.docker", CurrentVersion: "6.1.0", Type: "role"},
+					TargetVersion: "7.0.1",
+				},
+				{
+					Dependency:    engine.Dependency{Name: "community.general", CurrentVersion: ">=7.0.0", Type: "collection"},
+					TargetVersion: "8.2.0",
+				},
+				{
+					Dependency:    engine.Dependency{Name: "amazon.aws", CurrentVersion: "6.0.0", Type: "collection"},
+					TargetVersion: "6.4.0",
+				},
+			},
+		}
+
+		result, err := integ.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 3 {
+			t.Errorf("Apply() applied = %d, want 3 (errors: %v)", result.Applied, result.Errors)
+		}
+		if result.ManifestDiff == "" {
+			t.Error("Apply() should produce a diff")
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := strings.NewReplacer(
+			`version: "6.1.0"`, `version: "7.0.1"`,
+			`version: ">=7.0.0"`, `version: ">=8.2.0"`,
+			"version: 6.0.0 # keep in sync with CI", "version: 6.4.0 # keep in sync with CI",
+		).Replace(testRequirements)
+		if string(content) != want {
+			t.Errorf("Apply() content =\n%s\nwant\n%s", content, want)
+		}
+	})
+
+	t.Run("updates galaxy.yml dependencies", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "galaxy.yml")
+		if err := os.WriteFile(path, []byte(testGalaxy), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: path, Type: "ansible"},
+			Updates: []engine.Update{
+				{
+					Dependency:    engine.Dependency{Name: "ansible.posix", CurrentVersion: "1.5.0", Type: "collection"},
+					TargetVersion: "1.6.2",
+				},
+			},
+		}
+
+		result, err := integ.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 1 {
+			t.Errorf("Apply() applied = %d, want 1", result.Applied)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(content), `ansible.posix: "1.6.2"`) {
+			t.Errorf("Apply() content = %s, want ansible.posix bumped to 1.6.2", content)
+		}
+	})
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+
+	valid := &engine.Manifest{Path: "requirements.yml", Content: []byte(testRequirements)}
+	if err := integ.Validate(context.Background(), valid); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+
+	invalid := &engine.Manifest{Path: "requirements.yml", Content: []byte("roles: [unclosed")}
+	if err := integ.Validate(context.Background(), invalid); err == nil {
+		t.Error("Validate() error = nil, want error for invalid YAML")
+	}
+}
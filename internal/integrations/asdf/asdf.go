@@ -57,6 +57,11 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of .tool-versions files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{".tool-versions"}
+}
+
 // Detect scans for .tool-versions files.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
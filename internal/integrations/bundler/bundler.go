@@ -0,0 +1,745 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package bundler implements the Bundler integration for Ruby gems.
+// It detects Gemfile and *.gemspec files, reads gem declarations together with
+// the group blocks they sit in, queries RubyGems.org for the newest version
+// each requirement allows, and rewrites the requirement strings in place,
+// keeping their operators and precision as well as comments and options such
+// as require: or platforms: on the same line. Gems fetched with git:, github:
+// or path:, or from a source other than RubyGems.org, are skipped. Gems
+// declared without a requirement are reported but never updated. Gemfile.lock
+// is not rewritten; run bundle update after applying.
+package bundler
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("bundler", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "bundler"
+	gemfileName     = "Gemfile"
+	gemspecSuffix   = ".gemspec"
+	rubyGemsSource  = "rubygems.org"
+)
+
+var (
+	// gemPattern matches a Gemfile gem declaration, such as gem "rails" or
+	// gem("rails"), capturing the gem name.
+	gemPattern = regexp.MustCompile(`^\s*gem[\s(]+['"]([^'"]+)['"]`)
+
+	// gemspecPattern matches a dependency of a gemspec, such as
+	// spec.add_development_dependency "rspec", capturing its kind and name.
+	gemspecPattern = regexp.MustCompile(`^\s*\w+\.add_(runtime_|development_)?dependency[\s(]+['"]([^'"]+)['"]`)
+
+	// argPattern matches the next quoted argument of a declaration, optionally
+	// opening an array, capturing the string without quotes.
+	argPattern = regexp.MustCompile(`^\s*,\s*\[?\s*['"]([^'"]*)['"]`)
+
+	// clausePattern splits one requirement clause such as "~> 1.15" into
+	// operator and version.
+	clausePattern = regexp.MustCompile(`^\s*(~>|>=|<=|!=|>|<|=)?\s*(\d+(?:\.[0-9A-Za-z]+)*)\s*$`)
+
+	// externalSourcePattern matches options that fetch a gem from somewhere
+	// other than a gem server.
+	externalSourcePattern = regexp.MustCompile(`(?::(?:git|github|gist|bitbucket|path)\s*=>|\b(?:git|github|gist|bitbucket|path):)`)
+
+	// sourceOptionPattern matches the source option of a gem, capturing the URL.
+	sourceOptionPattern = regexp.MustCompile(`(?::source\s*=>|\bsource:)\s*['"]([^'"]+)['"]`)
+
+	// groupOptionPattern matches the group or groups option of a gem.
+	groupOptionPattern = regexp.MustCompile(`(?::groups?\s*=>|\bgroups?:)\s*(\[[^\]]*\]|:\w+|['"][^'"]*['"])`)
+
+	// groupNamePattern matches one group name, as a symbol or a string.
+	groupNamePattern = regexp.MustCompile(`:(\w+)|['"](\w+)['"]`)
+
+	// blockPattern matches a line opening a do block, capturing the method.
+	blockPattern = regexp.MustCompile(`^(\w+)\b.*\bdo(?:\s*\|[^|]*\|)?$`)
+
+	// keywordPattern matches a line opening a Ruby construct closed by end.
+	keywordPattern = regexp.MustCompile(`^(?:if|unless|case|begin|while|until|def|class|module)\b`)
+
+	// quotedPattern matches the first string literal of a line.
+	quotedPattern = regexp.MustCompile(`['"]([^'"]+)['"]`)
+
+	// endPattern matches a line closing a block.
+	endPattern = regexp.MustCompile(`^end\b`)
+)
+
+// declaration is a gem read from a Gemfile or gemspec, with its requirement
+// clauses in file order.
+type declaration struct {
+	name    string
+	clauses []clause
+	depType string
+	line    int
+}
+
+// clause is one quoted requirement string and the byte offsets of its
+// contents (without quotes).
+type clause struct {
+	value string
+	start int
+	end   int
+}
+
+// requirement returns the clauses of d joined the way RubyGems prints them,
+// such as "~> 1.15, >= 1.15.2".
+func (d declaration) requirement() string {
+	values := make([]string, len(d.clauses))
+	for j, c := range d.clauses {
+		values[j] = c.value
+	}
+	return strings.Join(values, ", ")
+}
+
+// block is an open do or keyword block of a Gemfile.
+type block struct {
+	depType  string
+	external bool
+}
+
+// Integration implements Gemfile and gemspec updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new Bundler integration.
+func New() *Integration {
+	ds, err := datasource.Get("rubygems")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewRubyGemsDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Gemfiles and gemspecs.
+func (i *Integration) SupportedFiles() []string {
+	return []string{gemfileName, "*" + gemspecSuffix}
+}
+
+// Detect finds Gemfile and *.gemspec files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// vendor/ and .bundle/ hold installed gems
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && (info.Name() == "vendor" || info.Name() == ".bundle")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !isManifest(info.Name()) || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: dependencies(parse(info.Name(), content)),
+			Content:      content,
+			Metadata:     map[string]any{},
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// isManifest reports whether name is a Gemfile or a gemspec.
+func isManifest(name string) bool {
+	return name == gemfileName || (strings.HasSuffix(name, gemspecSuffix) && name != gemspecSuffix)
+}
+
+// parse reads the declarations of a Gemfile or, for names ending in
+// .gemspec, a gemspec.
+func parse(name string, content []byte) []declaration {
+	if strings.HasSuffix(name, gemspecSuffix) {
+		return parseGemspec(content)
+	}
+	return parseGemfile(content)
+}
+
+// parseGemfile reads the gem declarations of a Gemfile in file order. Gems in
+// a group block whose groups are all development or test are dev
+// dependencies. Gems fetched from git, a local path or another gem server,
+// inline or through a block, are skipped.
+func parseGemfile(content []byte) []declaration {
+	var decls []declaration
+	var stack []block
+
+	forEachLine(content, func(code string, offset, line int) {
+		trimmed := strings.TrimSpace(code)
+
+		if endPattern.MatchString(trimmed) {
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			return
+		}
+
+		if m := gemPattern.FindStringSubmatchIndex(code); m != nil {
+			clauses, opts := parseArgs(code, m[1], offset)
+			if externalSourcePattern.MatchString(opts) || !fromRubyGems(sourceOptionPattern.FindStringSubmatch(opts)) {
+				return
+			}
+
+			depType := "direct"
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j].external {
+					return
+				}
+			}
+			for j := len(stack) - 1; j >= 0; j-- {
+				if stack[j].depType != "" {
+					depType = stack[j].depType
+					break
+				}
+			}
+			if group := groupOptionPattern.FindStringSubmatch(opts); group != nil {
+				depType = groupType(group[1])
+			}
+
+			decls = append(decls, declaration{
+				name:    code[m[2]:m[3]],
+				clauses: clauses,
+				depType: depType,
+				line:    line,
+			})
+			return
+		}
+
+		if m := blockPattern.FindStringSubmatch(trimmed); m != nil {
+			b := block{}
+			switch m[1] {
+			case "group":
+				b.depType = groupType(strings.TrimPrefix(trimmed, "group"))
+			case "git", "github", "path":
+				b.external = true
+			case "source":
+				b.external = !fromRubyGems(quotedPattern.FindStringSubmatch(trimmed))
+			}
+			stack = append(stack, b)
+			return
+		}
+
+		if keywordPattern.MatchString(trimmed) {
+			stack = append(stack, block{})
+		}
+	})
+
+	return decls
+}
+
+// parseGemspec reads the dependencies of a gemspec in file order.
+// Development dependencies are dev dependencies.
+func parseGemspec(content []byte) []declaration {
+	var decls []declaration
+
+	forEachLine(content, func(code string, offset, line int) {
+		m := gemspecPattern.FindStringSubmatchIndex(code)
+		if m == nil {
+			return
+		}
+		clauses, _ := parseArgs(code, m[1], offset)
+
+		depType := "direct"
+		if m[2] >= 0 && code[m[2]:m[3]] == "development_" {
+			depType = "dev"
+		}
+
+		decls = append(decls, declaration{
+			name:    code[m[4]:m[5]],
+			clauses: clauses,
+			depType: depType,
+			line:    line,
+		})
+	})
+
+	return decls
+}
+
+// forEachLine calls fn with the code of each line, without its comment, the
+// byte offset of the line in content and its 1-based line number.
+func forEachLine(content []byte, fn func(code string, offset, line int)) {
+	offset, line := 0, 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		text := scanner.Text()
+		line++
+		fn(stripComment(text), offset, line)
+		offset += len(text) + 1
+	}
+}
+
+// stripComment removes a trailing # comment from a line of Ruby, leaving #
+// characters inside string literals alone.
+func stripComment(line string) string {
+	var quote byte
+	for j := 0; j < len(line); j++ {
+		switch c := line[j]; {
+		case quote != 0:
+			if c == '\\' {
+				j++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:j]
+		}
+	}
+	return line
+}
+
+// parseArgs reads the requirement strings that follow the gem name, which
+// ends at pos in code, and returns them with the remaining options. Reading
+// stops at the first argument that is not a requirement.
+func parseArgs(code string, pos, offset int) ([]clause, string) {
+	var clauses []clause
+	for {
+		m := argPattern.FindStringSubmatchIndex(code[pos:])
+		if m == nil || !clausePattern.MatchString(code[pos+m[2]:pos+m[3]]) {
+			break
+		}
+		clauses = append(clauses, clause{
+			value: code[pos+m[2] : pos+m[3]],
+			start: offset + pos + m[2],
+			end:   offset + pos + m[3],
+		})
+		pos += m[1]
+	}
+	return clauses, code[pos:]
+}
+
+// groupType returns "dev" when every group named in groups is development or
+// test, and "direct" otherwise.
+func groupType(groups string) string {
+	names := groupNamePattern.FindAllStringSubmatch(groups, -1)
+	if len(names) == 0 {
+		return "direct"
+	}
+	for _, n := range names {
+		name := n[1] + n[2]
+		if name != "development" && name != "test" {
+			return "direct"
+		}
+	}
+	return "dev"
+}
+
+// fromRubyGems reports whether a source match, if any, names RubyGems.org.
+func fromRubyGems(source []string) bool {
+	return source == nil || strings.Contains(source[1], rubyGemsSource)
+}
+
+// dependencies returns the dependencies of decls, once per gem. The current
+// version is the highest lower bound of the requirement. Gems without a
+// requirement have neither a current version nor a constraint.
+func dependencies(decls []declaration) []engine.Dependency {
+	deps := make([]engine.Dependency, 0, len(decls))
+	seen := make(map[string]bool, len(decls))
+	for _, d := range decls {
+		if seen[d.name] {
+			continue
+		}
+		seen[d.name] = true
+
+		req := d.requirement()
+		deps = append(deps, engine.Dependency{
+			Name:           d.name,
+			CurrentVersion: currentVersion(req),
+			Constraint:     req,
+			Type:           d.depType,
+			Registry:       "rubygems",
+			Line:           d.line,
+		})
+	}
+	return deps
+}
+
+// splitClauses splits a requirement into its operator and version pairs.
+func splitClauses(req string) ([][2]string, bool) {
+	var clauses [][2]string
+	for _, part := range strings.Split(req, ",") {
+		m := clausePattern.FindStringSubmatch(part)
+		if m == nil {
+			return nil, false
+		}
+		clauses = append(clauses, [2]string{m[1], m[2]})
+	}
+	return clauses, true
+}
+
+// currentVersion returns the highest version a requirement's "~>", ">=", ">"
+// or exact clauses name, or "" when it has none.
+func currentVersion(req string) string {
+	clauses, ok := splitClauses(req)
+	if !ok {
+		return ""
+	}
+	var current string
+	for _, c := range clauses {
+		switch c[0] {
+		case "<", "<=", "!=":
+			continue
+		}
+		if current == "" {
+			current = c[1]
+			continue
+		}
+		if cmp, err := resolve.CompareVersions(c[1], current); err == nil && cmp > 0 {
+			current = c[1]
+		}
+	}
+	return current
+}
+
+// constraintFor returns the semver constraint a RubyGems requirement
+// expresses. A single clause maps directly: "~>" has the same meaning as in
+// uptool's constraints and "=" or a bare version is exact. Several clauses
+// become a range with "~>" expanded to its bounds, so "~> 1.15, >= 1.15.2"
+// becomes ">= 1.15, < 2, >= 1.15.2". ok is false for requirements without a
+// lower bound.
+func constraintFor(req string) (string, bool) {
+	clauses, ok := splitClauses(req)
+	if !ok {
+		return "", false
+	}
+
+	if len(clauses) == 1 {
+		op, v := clauses[0][0], clauses[0][1]
+		switch op {
+		case "", "=":
+			return v, true
+		case "~>", ">=", ">":
+			return op + " " + v, true
+		}
+		return "", false
+	}
+
+	var lower, upper []string
+	for _, c := range clauses {
+		op, v := c[0], c[1]
+		switch op {
+		case "~>":
+			lower = append(lower, ">= "+v)
+			upper = append(upper, "< "+optimisticBound(v))
+		case ">=", ">":
+			lower = append(lower, op+" "+v)
+		case "", "=":
+			lower = append(lower, "= "+v)
+		default:
+			upper = append(upper, op+" "+v)
+		}
+	}
+	if len(lower) == 0 || strings.HasPrefix(lower[0], "=") {
+		return "", false
+	}
+	return strings.Join(append(lower, upper...), ", "), true
+}
+
+// optimisticBound returns the exclusive upper bound of "~> v": "~> 1.15"
+// allows versions below 2 and "~> 1.15.2" versions below 1.16.
+func optimisticBound(v string) string {
+	parts := strings.Split(v, ".")
+	if len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	last, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return v
+	}
+	parts[len(parts)-1] = strconv.Itoa(last + 1)
+	return strings.Join(parts, ".")
+}
+
+// newClause rewrites one requirement clause to target. "~>", ">=" and exact
+// clauses move to target at their original precision, so "~> 1.15" becomes
+// "~> 1.17" rather than "~> 1.17.2"; upper bounds and exclusions are kept.
+func newClause(value, target string) string {
+	m := clausePattern.FindStringSubmatch(value)
+	if m == nil {
+		return value
+	}
+	switch m[1] {
+	case "~>", ">=", "=", "":
+		return strings.TrimSuffix(strings.TrimRight(value, " "), m[2]) + truncate(target, m[2])
+	}
+	return value
+}
+
+// newRequirement rewrites every clause of a requirement to target.
+func newRequirement(req, target string) string {
+	parts := strings.Split(req, ",")
+	for j, part := range parts {
+		parts[j] = strings.TrimSpace(newClause(strings.TrimSpace(part), target))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// truncate cuts target to the number of components in like, so "1.17.2" with
+// "1.15" becomes "1.17".
+func truncate(target, like string) string {
+	parts := strings.Split(target, ".")
+	if n := len(strings.Split(like, ".")); n < len(parts) {
+		return strings.Join(parts[:n], ".")
+	}
+	return target
+}
+
+// requirementAllows reports whether a RubyGems requirement allows version.
+func requirementAllows(req, version string) bool {
+	constraint, ok := constraintFor(req)
+	return ok && resolve.ParseConstraint(constraint).Allows(version)
+}
+
+// Plan determines available updates for gems with a requirement.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		// Gems without a requirement, or with only an upper bound, have
+		// nothing to rewrite
+		constraint, ok := constraintFor(dep.Constraint)
+		if !ok {
+			continue
+		}
+
+		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, dep.Name)
+			if latestErr != nil {
+				// Skip gems we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			constraint,
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+		targetVersion = version.Normalize(integrationName, targetVersion)
+
+		// A target the requirement already covers at its precision changes nothing
+		if newRequirement(dep.Constraint, targetVersion) == dep.Constraint {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			ChangelogURL:  fmt.Sprintf("https://rubygems.org/gems/%s", dep.Name),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "bundler_requirement_rewrite",
+	}, nil
+}
+
+// Apply executes the update plan by rewriting requirement strings in the
+// Gemfile or gemspec. Gemfile.lock is left for bundle update to refresh.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(plan.Manifest.Path), err)
+	}
+
+	newContent, applied, errs := rewriteRequirements(plan, oldContent)
+
+	if !bytes.Equal(newContent, oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+			return nil, fmt.Errorf("write %s: %w", filepath.Base(plan.Manifest.Path), err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(filepath.Base(plan.Manifest.Path), string(oldContent), string(newContent))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to Gemfile or gemspec content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewriteRequirements(plan, content)
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewriteRequirements rewrites the requirement strings of the plan's updates
+// in content, in every declaration of the gem with the planned requirement,
+// and returns the names of the gems it updated. Only the text inside the
+// quotes changes.
+func rewriteRequirements(plan *engine.UpdatePlan, content []byte) (newContent []byte, applied, errs []string) {
+	file := filepath.Base(plan.Manifest.Path)
+	decls := parse(file, content)
+
+	var edits []clause
+
+	for _, update := range plan.Updates {
+		dep := update.Dependency
+		target := version.Normalize(integrationName, update.TargetVersion)
+
+		req := newRequirement(dep.Constraint, target)
+		if err := resolve.ValidateConstraint(integrationName, req); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dep.Name, err))
+			continue
+		}
+		if !requirementAllows(req, target) {
+			errs = append(errs, fmt.Sprintf("%s: requirement %q does not allow %s", dep.Name, req, target))
+			continue
+		}
+
+		found := false
+		for _, d := range decls {
+			if d.name != dep.Name || d.requirement() != dep.Constraint {
+				continue
+			}
+			for _, c := range d.clauses {
+				edits = append(edits, clause{value: newClause(c.value, target), start: c.start, end: c.end})
+			}
+			found = true
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: requirement %q not found in %s", dep.Name, dep.Constraint, file))
+			continue
+		}
+		applied = append(applied, dep.Name)
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+	newContent = content
+	for _, e := range edits {
+		newContent = append(append(append([]byte{}, newContent[:e.start]...), e.value...), newContent[e.end:]...)
+	}
+
+	return newContent, applied, errs
+}
+
+// Validate checks that every requirement in the Gemfile or gemspec is a
+// well-formed RubyGems requirement.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	for _, d := range parse(filepath.Base(manifest.Path), manifest.Content) {
+		if len(d.clauses) == 0 {
+			continue
+		}
+		if err := resolve.ValidateConstraint(integrationName, d.requirement()); err != nil {
+			return fmt.Errorf("gem %s: %w", d.name, err)
+		}
+	}
+	return nil
+}
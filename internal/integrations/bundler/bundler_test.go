@@ -0,0 +1,420 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package bundler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testGemfile = `source "https://rubygems.org"
+
+ruby "3.2.2"
+
+gem "rails", "~> 7.1.0"
+gem "pg", ">= 1.1", "< 2.0"
+gem "puma"
+gem "nokogiri", "~> 1.15", ">= 1.15.2", require: false # CVE-2023-44487
+gem "bootsnap", require: false
+gem "rack-cors", git: "https://github.com/cyu/rack-cors.git"
+gem("sidekiq", "~> 7.0")
+
+group :development, :test do
+  gem "rspec-rails", "~> 6.0"
+  gem "debug", platforms: %i[mri windows]
+end
+
+group :production do
+  if ENV["LOGRAGE"]
+    gem "lograge", "~> 0.12"
+  end
+end
+
+gem "rubocop", "~> 1.50", group: :development
+
+git "https://github.com/rails/rails.git" do
+  gem "actioncable", "~> 7.1"
+end
+
+source "https://gems.contribsys.com/" do
+  gem "sidekiq-pro", "~> 7.0"
+end
+
+# gem "commented", "~> 1.0"
+`
+
+const testGemspec = `Gem::Specification.new do |spec|
+  spec.name = "my_gem"
+  spec.version = MyGem::VERSION
+
+  spec.add_dependency "activesupport", ">= 6.1", "< 8"
+  spec.add_runtime_dependency "zeitwerk", "~> 2.6"
+  spec.add_development_dependency "rake", "~> 13.0"
+  spec.add_dependency("concurrent-ruby")
+end
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, gem string) ([]string, error) {
+	if versions, ok := m.versions[gem]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, gem string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, gem string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+// rubygems mirrors what RubyGems.org publishes for the test gems.
+func rubygems() *mockDatasource {
+	return &mockDatasource{
+		versions: map[string][]string{
+			"rails":         {"8.0.0", "7.1.3", "7.1.0", "7.0.8"},
+			"pg":            {"2.0.0", "1.5.4", "1.1.0"},
+			"puma":          {"6.4.2"},
+			"nokogiri":      {"2.0.0", "1.16.0", "1.15.5", "1.15.2"},
+			"sidekiq":       {"7.2.0", "7.0.0"},
+			"rspec-rails":   {"6.1.0", "6.0.0"},
+			"lograge":       {"0.14.0", "0.12.0"},
+			"rubocop":       {"1.60.0", "1.50.0"},
+			"activesupport": {"8.0.0", "7.1.3", "6.1.0"},
+			"zeitwerk":      {"2.6.12", "2.6.0"},
+			"rake":          {"13.1.0", "13.0.0"},
+		},
+	}
+}
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		gemfileName:              testGemfile,
+		"my_gem.gemspec":         testGemspec,
+		"vendor/bundle/Gemfile":  testGemfile,
+		".bundle/config.gemspec": testGemspec,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeProject(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("Detect() found %d manifests, want 2 (vendor/ and .bundle/ skipped)", len(manifests))
+	}
+
+	got := make(map[string]map[string]engine.Dependency)
+	for _, m := range manifests {
+		deps := make(map[string]engine.Dependency)
+		for _, dep := range m.Dependencies {
+			deps[dep.Name] = dep
+		}
+		got[m.Path] = deps
+	}
+
+	tests := []struct {
+		file, name, current, constraint, depType string
+	}{
+		{gemfileName, "rails", "7.1.0", "~> 7.1.0", "direct"},
+		{gemfileName, "pg", "1.1", ">= 1.1, < 2.0", "direct"},
+		{gemfileName, "puma", "", "", "direct"},
+		{gemfileName, "nokogiri", "1.15.2", "~> 1.15, >= 1.15.2", "direct"},
+		{gemfileName, "bootsnap", "", "", "direct"},
+		{gemfileName, "sidekiq", "7.0", "~> 7.0", "direct"},
+		{gemfileName, "rspec-rails", "6.0", "~> 6.0", "dev"},
+		{gemfileName, "debug", "", "", "dev"},
+		{gemfileName, "lograge", "0.12", "~> 0.12", "direct"},
+		{gemfileName, "rubocop", "1.50", "~> 1.50", "dev"},
+		{"my_gem.gemspec", "activesupport", "6.1", ">= 6.1, < 8", "direct"},
+		{"my_gem.gemspec", "zeitwerk", "2.6", "~> 2.6", "direct"},
+		{"my_gem.gemspec", "rake", "13.0", "~> 13.0", "dev"},
+		{"my_gem.gemspec", "concurrent-ruby", "", "", "direct"},
+	}
+	for _, tt := range tests {
+		dep, ok := got[tt.file][tt.name]
+		if !ok {
+			t.Errorf("%s: %s not detected", tt.file, tt.name)
+			continue
+		}
+		if dep.CurrentVersion != tt.current || dep.Constraint != tt.constraint || dep.Type != tt.depType {
+			t.Errorf("%s: %s = {%q, %q, %q}, want {%q, %q, %q}", tt.file, tt.name,
+				dep.CurrentVersion, dep.Constraint, dep.Type, tt.current, tt.constraint, tt.depType)
+		}
+	}
+
+	// git, github and other gem servers are skipped, as are comments
+	for _, name := range []string{"rack-cors", "actioncable", "sidekiq-pro", "commented"} {
+		if _, ok := got[gemfileName][name]; ok {
+			t.Errorf("%s should be skipped", name)
+		}
+	}
+	if n := len(got[gemfileName]); n != 10 {
+		t.Errorf("Gemfile has %d dependencies, want 10", n)
+	}
+}
+
+func TestConstraintFor(t *testing.T) {
+	tests := []struct {
+		req    string
+		want   string
+		wantOK bool
+	}{
+		{"~> 7.1", "~> 7.1", true},
+		{">= 1.2", ">= 1.2", true},
+		{"= 1.2.3", "1.2.3", true},
+		{"1.2.3", "1.2.3", true},
+		{"~> 1.15, >= 1.15.2", ">= 1.15, >= 1.15.2, < 2", true},
+		{"~> 1.15.2, != 1.15.3", ">= 1.15.2, < 1.16, != 1.15.3", true},
+		{"< 8, >= 6.1", ">= 6.1, < 8", true},
+		{"< 2.0", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := constraintFor(tt.req)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("constraintFor(%q) = %q, %v, want %q, %v", tt.req, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestNewRequirement(t *testing.T) {
+	tests := []struct {
+		req, target, want string
+	}{
+		{"~> 7.1", "7.2.1", "~> 7.2"},
+		{"~> 7.1.0", "7.1.3", "~> 7.1.3"},
+		{">= 1.1, < 2.0", "1.5.4", ">= 1.5, < 2.0"},
+		{"~> 1.15, >= 1.15.2", "1.16.0", "~> 1.16, >= 1.16.0"},
+		{"= 1.2.3", "1.2.4", "= 1.2.4"},
+		{"1.2.3", "1.2.4", "1.2.4"},
+		{"> 1.0, != 1.3", "1.4.0", "> 1.0, != 1.3"},
+	}
+	for _, tt := range tests {
+		if got := newRequirement(tt.req, tt.target); got != tt.want {
+			t.Errorf("newRequirement(%q, %q) = %q, want %q", tt.req, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeProject(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: rubygems()}
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	var gemfile *engine.Manifest
+	for _, m := range manifests {
+		if m.Path == gemfileName {
+			gemfile = m
+		}
+	}
+
+	plan, err := integ.Plan(context.Background(), gemfile, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// The newest version each requirement allows; unconstrained gems are not updated
+	want := map[string]string{
+		"rails":       "7.1.3",
+		"pg":          "1.5.4",
+		"nokogiri":    "1.16.0",
+		"sidekiq":     "7.2.0",
+		"rspec-rails": "6.1.0",
+		"lograge":     "0.14.0",
+		"rubocop":     "1.60.0",
+	}
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan() updates = %v, want %v", got, want)
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("Plan() target for %s = %q, want %q", name, got[name], target)
+		}
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 7 || result.Failed != 0 {
+		t.Errorf("Apply() = %d applied, %d failed (%v), want 7 applied", result.Applied, result.Failed, result.Errors)
+	}
+
+	// Only requirement strings change; options and comments are kept
+	content, _ := os.ReadFile(gemfileName)
+	wantContent := `source "https://rubygems.org"
+
+ruby "3.2.2"
+
+gem "rails", "~> 7.1.3"
+gem "pg", ">= 1.5", "< 2.0"
+gem "puma"
+gem "nokogiri", "~> 1.16", ">= 1.16.0", require: false # CVE-2023-44487
+gem "bootsnap", require: false
+gem "rack-cors", git: "https://github.com/cyu/rack-cors.git"
+gem("sidekiq", "~> 7.2")
+
+group :development, :test do
+  gem "rspec-rails", "~> 6.1"
+  gem "debug", platforms: %i[mri windows]
+end
+
+group :production do
+  if ENV["LOGRAGE"]
+    gem "lograge", "~> 0.14"
+  end
+end
+
+gem "rubocop", "~> 1.60", group: :development
+
+git "https://github.com/rails/rails.git" do
+  gem "actioncable", "~> 7.1"
+end
+
+source "https://gems.contribsys.com/" do
+  gem "sidekiq-pro", "~> 7.0"
+end
+
+# gem "commented", "~> 1.0"
+`
+	if string(content) != wantContent {
+		t.Errorf("Gemfile after Apply:\n%s\nwant:\n%s", content, wantContent)
+	}
+}
+
+func TestApply_Gemspec(t *testing.T) {
+	dir := writeProject(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: rubygems()}
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	var gemspec *engine.Manifest
+	for _, m := range manifests {
+		if m.Path == "my_gem.gemspec" {
+			gemspec = m
+		}
+	}
+
+	plan, err := integ.Plan(context.Background(), gemspec, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	// zeitwerk 2.6.12 is still "~> 2.6"
+	if len(plan.Updates) != 2 {
+		t.Fatalf("Plan() = %d updates, want 2 (activesupport, rake)", len(plan.Updates))
+	}
+
+	if _, err := integ.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, _ := os.ReadFile("my_gem.gemspec")
+	wantContent := `Gem::Specification.new do |spec|
+  spec.name = "my_gem"
+  spec.version = MyGem::VERSION
+
+  spec.add_dependency "activesupport", ">= 7.1", "< 8"
+  spec.add_runtime_dependency "zeitwerk", "~> 2.6"
+  spec.add_development_dependency "rake", "~> 13.1"
+  spec.add_dependency("concurrent-ruby")
+end
+`
+	if string(content) != wantContent {
+		t.Errorf("gemspec after Apply:\n%s\nwant:\n%s", content, wantContent)
+	}
+}
+
+func TestRewrite_NotAllowed(t *testing.T) {
+	dep := engine.Dependency{Name: "pg", CurrentVersion: "1.1", Constraint: ">= 1.1, < 2.0"}
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: gemfileName},
+		Updates:  []engine.Update{{Dependency: dep, TargetVersion: "2.0.0"}},
+	}
+
+	result, err := New().Rewrite(context.Background(), plan, []byte(testGemfile))
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("Rewrite() = %d applied, %d failed, want the upper bound reported", result.Applied, result.Failed)
+	}
+	if string(result.Content) != testGemfile {
+		t.Error("Rewrite() changed content for an update the requirement does not allow")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+
+	valid := &engine.Manifest{Path: gemfileName, Content: []byte(testGemfile)}
+	if err := integ.Validate(context.Background(), valid); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	valid = &engine.Manifest{Path: "my_gem.gemspec", Content: []byte(testGemspec)}
+	if err := integ.Validate(context.Background(), valid); err != nil {
+		t.Errorf("Validate() gemspec error = %v", err)
+	}
+}
@@ -0,0 +1,524 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package cargo implements the Cargo integration for updating Rust dependencies.
+// It detects Cargo.toml files, queries crates.io for version updates, and
+// rewrites version requirements in place so TOML formatting and comments are
+// preserved. Dependencies sourced from git, a local path, another registry, or
+// inherited from the workspace are skipped.
+package cargo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("cargo", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "cargo"
+	manifestName    = "Cargo.toml"
+)
+
+var (
+	// headerPattern matches a TOML table header such as [dependencies] or
+	// [workspace.dependencies.serde], capturing the table name.
+	headerPattern = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+
+	// operatorPattern splits a requirement into operator and version (e.g., "^1.2").
+	operatorPattern = regexp.MustCompile(`^(\^|~|=|>=|>|<=|<)?\s*(\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.-]+)?)$`)
+)
+
+// sections are the dependency tables uptool updates, with the dependency type
+// recorded for entries found in each.
+var sections = []struct {
+	table   string
+	depType string
+}{
+	{table: "dependencies", depType: "direct"},
+	{table: "dev-dependencies", depType: "dev"},
+	{table: "build-dependencies", depType: "build"},
+	{table: "workspace.dependencies", depType: "workspace"},
+}
+
+// Integration implements Cargo.toml updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new Cargo integration.
+func New() *Integration {
+	ds, err := datasource.Get("crates")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewCratesDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Cargo manifests.
+func (i *Integration) SupportedFiles() []string {
+	return []string{manifestName}
+}
+
+// CargoToml represents the parts of Cargo.toml the integration reads.
+type CargoToml struct {
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+	Package           struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Workspace *struct {
+		Dependencies map[string]any `toml:"dependencies"`
+	} `toml:"workspace"`
+}
+
+// table returns the dependency table of c with the given name.
+func (c *CargoToml) table(name string) map[string]any {
+	switch name {
+	case "dependencies":
+		return c.Dependencies
+	case "dev-dependencies":
+		return c.DevDependencies
+	case "build-dependencies":
+		return c.BuildDependencies
+	case "workspace.dependencies":
+		if c.Workspace != nil {
+			return c.Workspace.Dependencies
+		}
+	}
+	return nil
+}
+
+// Detect finds Cargo.toml files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip hidden directories, build output, and vendored crates
+		if info.IsDir() && path != repoRoot &&
+			(strings.HasPrefix(info.Name(), ".") || info.Name() == "target" || info.Name() == "vendor") {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || info.Name() != manifestName {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		// Validate path for security
+		if err := integrations.ValidateFilePath(path); err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		var cargo CargoToml
+		if err := toml.Unmarshal(content, &cargo); err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+
+		deps, crates := extractDependencies(&cargo)
+		metadata := map[string]any{
+			"package_name": cargo.Package.Name,
+			"workspace":    cargo.Workspace != nil,
+		}
+		if len(crates) > 0 {
+			metadata["crates"] = crates
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: deps,
+			Content:      content,
+			Metadata:     metadata,
+		})
+
+		return nil
+	})
+
+	return manifests, err
+}
+
+// extractDependencies returns the crates.io dependencies of every dependency
+// table, in table then name order. Renamed dependencies (package = "...") are
+// returned in crates, mapping the dependency key to the published crate name.
+func extractDependencies(cargo *CargoToml) (deps []engine.Dependency, crates map[string]string) {
+	crates = make(map[string]string)
+
+	for _, section := range sections {
+		table := cargo.table(section.table)
+
+		names := make([]string, 0, len(table))
+		for name := range table {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			req, crate, ok := parseSpec(table[name])
+			if !ok {
+				continue
+			}
+			if crate != "" && crate != name {
+				crates[name] = crate
+			}
+
+			_, version, _ := splitRequirement(req)
+			deps = append(deps, engine.Dependency{
+				Name:           name,
+				CurrentVersion: version,
+				Constraint:     req,
+				Type:           section.depType,
+				Registry:       "crates",
+			})
+		}
+	}
+
+	return deps, crates
+}
+
+// parseSpec reads a dependency specification, either a version string or a
+// table with a version key. ok is false for dependencies that are not
+// resolved from crates.io or have no version requirement.
+func parseSpec(spec any) (req, crate string, ok bool) {
+	switch v := spec.(type) {
+	case string:
+		return strings.TrimSpace(v), "", true
+	case map[string]any:
+		for _, key := range []string{"git", "path", "registry", "workspace"} {
+			if _, found := v[key]; found {
+				return "", "", false
+			}
+		}
+		version, isString := v["version"].(string)
+		if !isString {
+			return "", "", false
+		}
+		crate, _ = v["package"].(string) //nolint:errcheck // optional key
+		return strings.TrimSpace(version), crate, true
+	}
+	return "", "", false
+}
+
+// splitRequirement splits a single-clause requirement such as "^1.2" into its
+// operator and version. ok is false for ranges, wildcards, and other
+// requirements uptool does not rewrite.
+func splitRequirement(req string) (op, version string, ok bool) {
+	m := operatorPattern.FindStringSubmatch(strings.TrimSpace(req))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// constraintFor returns the semver constraint a Cargo requirement expresses.
+// A bare requirement is a caret requirement in Cargo, so "1.2" means "^1.2".
+func constraintFor(req string) string {
+	op, version, ok := splitRequirement(req)
+	if ok && op == "" {
+		return "^" + version
+	}
+	return req
+}
+
+// newRequirement rewrites a requirement to target, keeping its operator and
+// precision, so "1.0" becomes "1.2" rather than "1.2.3".
+func newRequirement(req, target string) string {
+	op, version, ok := splitRequirement(req)
+	if !ok {
+		return target
+	}
+
+	parts := strings.Split(target, ".")
+	if n := len(strings.Split(version, ".")); n < len(parts) && !strings.Contains(target, "-") {
+		target = strings.Join(parts[:n], ".")
+	}
+	return op + target
+}
+
+// crateName returns the published crate name of a dependency.
+func crateName(manifest *engine.Manifest, dep string) string {
+	switch crates := manifest.Metadata["crates"].(type) {
+	case map[string]string:
+		if crate, ok := crates[dep]; ok {
+			return crate
+		}
+	case map[string]any: // metadata decoded from a saved plan
+		if crate, ok := crates[dep].(string); ok {
+			return crate
+		}
+	}
+	return dep
+}
+
+// Plan determines available updates for Cargo dependencies.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		// Ranges and wildcards can't be rewritten to a single version
+		if _, _, ok := splitRequirement(dep.Constraint); !ok {
+			continue
+		}
+
+		crate := crateName(manifest, dep.Name)
+		availableVersions, err := i.ds.GetVersions(ctx, crate)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, crate)
+			if latestErr != nil {
+				// Skip crates we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			constraintFor(dep.Constraint),
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+
+		// A target the requirement already covers at its precision changes nothing
+		if newRequirement(dep.Constraint, targetVersion) == dep.Constraint {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			ChangelogURL:  fmt.Sprintf("https://crates.io/crates/%s", crate),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "cargo_rewrite",
+	}, nil
+}
+
+// Apply executes the update plan by rewriting version requirements in Cargo.toml.
+// Cargo.lock is not modified; run `cargo update` afterwards to refresh it.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read Cargo.toml: %w", err)
+	}
+
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+	newContent := string(rewritten.Content)
+	errs := rewritten.Errors
+
+	if newContent != string(oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, []byte(newContent), 0o600); err != nil {
+			return nil, fmt.Errorf("write Cargo.toml: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(manifestName, string(oldContent), newContent)
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to Cargo.toml content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent := string(content)
+	var applied, errs []string
+
+	for j := range plan.Updates {
+		update := &plan.Updates[j]
+		dep := update.Dependency
+
+		req := newRequirement(dep.Constraint, version.Normalize(integrationName, update.TargetVersion))
+		if err := resolve.ValidateConstraint(integrationName, req); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dep.Name, err))
+			continue
+		}
+
+		var ok bool
+		newContent, ok = replaceRequirement(newContent, sectionFor(dep.Type), dep.Name, dep.Constraint, req)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: requirement %q not found in Cargo.toml", dep.Name, dep.Constraint))
+			continue
+		}
+		applied = append(applied, dep.Name)
+	}
+
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// sectionFor returns the dependency table that holds dependencies of depType.
+func sectionFor(depType string) string {
+	for _, section := range sections {
+		if section.depType == depType {
+			return section.table
+		}
+	}
+	return "dependencies"
+}
+
+// replaceRequirement rewrites the version requirement of one dependency in
+// section, leaving every other byte of the file untouched. It handles the
+// string form (serde = "1.0"), inline tables (serde = { version = "1.0" }),
+// dotted keys (serde.version = "1.0"), and dependency tables
+// ([dependencies.serde] followed by version = "1.0").
+func replaceRequirement(content, section, name, oldReq, newReq string) (string, bool) {
+	key := `["']?` + regexp.QuoteMeta(name) + `["']?`
+	value := `["'])` + regexp.QuoteMeta(oldReq) + `(["'].*)$`
+
+	inSection := []*regexp.Regexp{
+		regexp.MustCompile(`^(\s*` + key + `\s*=\s*` + value),
+		regexp.MustCompile(`^(\s*` + key + `\s*=\s*\{.*\bversion\s*=\s*` + value),
+		regexp.MustCompile(`^(\s*` + key + `\s*\.\s*version\s*=\s*` + value),
+	}
+	inDependencyTable := []*regexp.Regexp{
+		regexp.MustCompile(`^(\s*version\s*=\s*` + value),
+	}
+
+	lines := strings.SplitAfter(content, "\n")
+	table := ""
+	for idx, line := range lines {
+		body := strings.TrimRight(line, "\r\n")
+		eol := line[len(body):]
+
+		if m := headerPattern.FindStringSubmatch(body); m != nil {
+			table = normalizeTable(m[1])
+			continue
+		}
+
+		var patterns []*regexp.Regexp
+		switch table {
+		case section:
+			patterns = inSection
+		case section + "." + name:
+			patterns = inDependencyTable
+		default:
+			continue
+		}
+
+		for _, re := range patterns {
+			if re.MatchString(body) {
+				lines[idx] = re.ReplaceAllString(body, "${1}"+newReq+"${2}") + eol
+				return strings.Join(lines, ""), true
+			}
+		}
+	}
+
+	return content, false
+}
+
+// normalizeTable strips quotes and whitespace from a table name, so
+// [ dependencies . "serde" ] is recognized as dependencies.serde.
+func normalizeTable(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Validate checks that Cargo.toml is valid TOML.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	var cargo CargoToml
+	return toml.Unmarshal(manifest.Content, &cargo)
+}
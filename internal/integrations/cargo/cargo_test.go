@@ -0,0 +1,386 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cargo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testCargoToml = `[package]
+name = "app"
+version = "0.1.0"
+
+[dependencies]
+# Serialization
+serde = { version = "1.0", features = ["derive"] } # keep derive
+tokio = "1.38"
+rand.version = "0.7"
+exact = "=2.0.0"
+local = { path = "../local" }
+git-dep = { git = "https://github.com/example/git-dep" }
+inherited = { workspace = true }
+json = { package = "serde_json", version = "1.0.100" }
+range = ">=1.0, <2"
+
+[dev-dependencies]
+criterion = "0.4"
+
+[build-dependencies.cc]
+version = "1.0.80"
+features = ["parallel"]
+
+[target.'cfg(unix)'.dependencies]
+libc = "0.2"
+`
+
+const testWorkspaceToml = `[workspace]
+members = ["app"]
+
+[workspace.dependencies]
+anyhow = "1.0.70"
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+var testVersions = map[string][]string{
+	"serde":      {"1.0.150", "1.0.210"},
+	"tokio":      {"1.38.0", "1.40.0", "2.0.0"},
+	"rand":       {"0.7.3", "0.8.5"},
+	"exact":      {"2.0.0", "2.1.0"},
+	"serde_json": {"1.0.100", "1.0.128"},
+	"range":      {"1.5.0"},
+	"criterion":  {"0.4.0", "0.5.1"},
+	"cc":         {"1.0.80", "1.1.30"},
+	"anyhow":     {"1.0.70", "1.0.89"},
+	"libc":       {"0.2.150"},
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), testWorkspaceToml)
+	writeFile(t, filepath.Join(dir, "app", "Cargo.toml"), testCargoToml)
+	writeFile(t, filepath.Join(dir, "target", "package", "Cargo.toml"), testCargoToml)
+	writeFile(t, filepath.Join(dir, "vendor", "dep", "Cargo.toml"), testCargoToml)
+	writeFile(t, filepath.Join(dir, ".cargo", "Cargo.toml"), testCargoToml)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("Detect() found %d manifests, want 2 (target, vendor, hidden skipped)", len(manifests))
+	}
+
+	var app, workspace *engine.Manifest
+	for _, m := range manifests {
+		switch m.Path {
+		case "Cargo.toml":
+			workspace = m
+		case filepath.Join("app", "Cargo.toml"):
+			app = m
+		}
+	}
+	if app == nil || workspace == nil {
+		t.Fatalf("Detect() paths = %s, %s", manifests[0].Path, manifests[1].Path)
+	}
+
+	got := make(map[string]engine.Dependency)
+	for _, dep := range app.Dependencies {
+		got[dep.Name] = dep
+	}
+
+	want := map[string]struct{ constraint, current, depType string }{
+		"serde":     {"1.0", "1.0", "direct"},
+		"tokio":     {"1.38", "1.38", "direct"},
+		"rand":      {"0.7", "0.7", "direct"},
+		"exact":     {"=2.0.0", "2.0.0", "direct"},
+		"json":      {"1.0.100", "1.0.100", "direct"},
+		"range":     {">=1.0, <2", "", "direct"},
+		"criterion": {"0.4", "0.4", "dev"},
+		"cc":        {"1.0.80", "1.0.80", "build"},
+	}
+	if len(got) != len(want) {
+		t.Errorf("Detect() dependencies = %+v, want %d entries", app.Dependencies, len(want))
+	}
+	for name, w := range want {
+		dep, ok := got[name]
+		if !ok {
+			t.Errorf("dependency %s not detected", name)
+			continue
+		}
+		if dep.Constraint != w.constraint || dep.CurrentVersion != w.current || dep.Type != w.depType {
+			t.Errorf("%s = %+v, want constraint %q, current %q, type %q", name, dep, w.constraint, w.current, w.depType)
+		}
+	}
+	for _, skipped := range []string{"local", "git-dep", "inherited", "libc"} {
+		if _, ok := got[skipped]; ok {
+			t.Errorf("dependency %s should be skipped", skipped)
+		}
+	}
+
+	if crateName(app, "json") != "serde_json" {
+		t.Errorf("crateName(json) = %q, want serde_json", crateName(app, "json"))
+	}
+
+	if len(workspace.Dependencies) != 1 || workspace.Dependencies[0].Type != "workspace" {
+		t.Errorf("workspace dependencies = %+v, want anyhow of type workspace", workspace.Dependencies)
+	}
+}
+
+func TestNewRequirement(t *testing.T) {
+	tests := []struct {
+		req, target, want string
+	}{
+		{"1.0", "1.2.3", "1.2"},
+		{"1", "2.0.1", "2"},
+		{"1.0.80", "1.1.30", "1.1.30"},
+		{"^0.7", "0.8.5", "^0.8"},
+		{"~1.2", "1.4.0", "~1.4"},
+		{"=2.0.0", "2.1.0", "=2.1.0"},
+		{"1.0", "1.1.0-rc.1", "1.1.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		if got := newRequirement(tt.req, tt.target); got != tt.want {
+			t.Errorf("newRequirement(%q, %q) = %q, want %q", tt.req, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), testCargoToml)
+
+	integ := &Integration{ds: &mockDatasource{versions: testVersions}}
+	manifests, err := integ.Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+
+	// Bare requirements are caret requirements: tokio stays on 1.x, and
+	// 0.x requirements only take patch releases of their minor version
+	want := map[string]string{
+		"tokio": "1.40.0",
+		"json":  "1.0.128",
+		"cc":    "1.1.30",
+	}
+	if len(got) != len(want) {
+		t.Errorf("Plan() updates = %v, want %v", got, want)
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("Plan() %s -> %q, want %q", name, got[name], target)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), testCargoToml)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: &mockDatasource{versions: testVersions}}
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	dep := func(name string) engine.Dependency {
+		for _, d := range manifests[0].Dependencies {
+			if d.Name == name {
+				return d
+			}
+		}
+		t.Fatalf("dependency %s not detected", name)
+		return engine.Dependency{}
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: manifests[0],
+		Updates: []engine.Update{
+			{Dependency: dep("serde"), TargetVersion: "1.2.0", Impact: "minor"},
+			{Dependency: dep("tokio"), TargetVersion: "2.0.0", Impact: "major"},
+			{Dependency: dep("rand"), TargetVersion: "0.8.5", Impact: "minor"},
+			{Dependency: dep("json"), TargetVersion: "1.0.128", Impact: "patch"},
+			{Dependency: dep("criterion"), TargetVersion: "0.5.1", Impact: "minor"},
+			{Dependency: dep("cc"), TargetVersion: "1.1.30", Impact: "minor"},
+		},
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 6 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 6 applied and no errors", result)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.NewReplacer(
+		`serde = { version = "1.0", features`, `serde = { version = "1.2", features`,
+		`tokio = "1.38"`, `tokio = "2.0"`,
+		`rand.version = "0.7"`, `rand.version = "0.8"`,
+		`json = { package = "serde_json", version = "1.0.100" }`, `json = { package = "serde_json", version = "1.0.128" }`,
+		`criterion = "0.4"`, `criterion = "0.5"`,
+		`version = "1.0.80"`, `version = "1.1.30"`,
+	).Replace(testCargoToml)
+	if string(content) != want {
+		t.Errorf("Cargo.toml after Apply:\n%s\nwant:\n%s", content, want)
+	}
+	if !strings.Contains(result.ManifestDiff, `+tokio = "2.0"`) {
+		t.Errorf("ManifestDiff missing tokio change:\n%s", result.ManifestDiff)
+	}
+}
+
+func TestApply_PrefixedVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), "[dependencies]\nserde = \"1.0.100\"\n")
+	t.Chdir(dir)
+
+	// Versions taken from v-prefixed tags are written back in Cargo's bare form
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "Cargo.toml"},
+		Updates: []engine.Update{
+			{Dependency: engine.Dependency{Name: "serde", Constraint: "1.0.100", Type: "direct"}, TargetVersion: "v1.0.200"},
+		},
+	}
+
+	result, err := (&Integration{ds: &mockDatasource{}}).Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 1 applied and no errors", result)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[dependencies]\nserde = \"1.0.200\"\n"; string(content) != want {
+		t.Errorf("Cargo.toml = %q, want %q", content, want)
+	}
+}
+
+func TestApply_Workspace(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "Cargo.toml"), testWorkspaceToml)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: &mockDatasource{versions: testVersions}}
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != "1.0.89" {
+		t.Fatalf("Plan() updates = %+v, want anyhow -> 1.0.89", plan.Updates)
+	}
+
+	if _, err := integ.Apply(context.Background(), plan); err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `anyhow = "1.0.89"`) {
+		t.Errorf("Cargo.toml after Apply:\n%s", content)
+	}
+}
+
+func TestReplaceRequirement_NotFound(t *testing.T) {
+	content := "[dependencies]\nserde = \"1.0\"\n"
+	if _, ok := replaceRequirement(content, "dev-dependencies", "serde", "1.0", "1.2"); ok {
+		t.Error("replaceRequirement() matched a dependency in another table")
+	}
+	if _, ok := replaceRequirement(content, "dependencies", "serde_json", "1.0", "1.2"); ok {
+		t.Error("replaceRequirement() matched a dependency with a different name")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+	if err := integ.Validate(context.Background(), &engine.Manifest{Content: []byte(testCargoToml)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := integ.Validate(context.Background(), &engine.Manifest{Content: []byte("[dependencies\n")}); err == nil {
+		t.Error("Validate() expected error for invalid TOML")
+	}
+}
@@ -0,0 +1,577 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package cocoapods implements the CocoaPods integration for updating Podfile pods.
+// It detects Podfile files, queries the CocoaPods trunk CDN for version updates,
+// and rewrites pod version requirements in place, preserving the Ruby DSL formatting.
+// Pods sourced from :git, :path, or :podspec are skipped. When a Podfile.lock is
+// present, resolved versions and checksums are updated alongside the Podfile.
+package cocoapods
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // #nosec G505 - CocoaPods lockfile checksums are SHA-1, not used for security
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("cocoapods", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "cocoapods"
+	lockfileName    = "Podfile.lock"
+)
+
+var (
+	// podPattern matches a pod declaration: pod 'Name', 'requirement', options...
+	podPattern = regexp.MustCompile(`^\s*pod\s+['"]([^'"]+)['"]\s*(.*)$`)
+
+	// requirementPattern matches the first quoted argument after the pod name.
+	requirementPattern = regexp.MustCompile(`^,\s*['"]([^'"]+)['"]`)
+
+	// externalSourcePattern matches options that fetch a pod outside trunk.
+	externalSourcePattern = regexp.MustCompile(`(?::(?:git|path|podspec)\s*=>|\b(?:git|path|podspec):)`)
+
+	// operatorPattern splits a requirement into operator and version (e.g., "~> 5.6").
+	operatorPattern = regexp.MustCompile(`^(~>|>=|<=|>|<|=)?\s*(\S+)$`)
+
+	// lockPodPattern matches a top-level entry in the PODS section of Podfile.lock.
+	lockPodPattern = regexp.MustCompile(`^  - "?([^\s"(]+)"? \(([^)]+)\)`)
+)
+
+// specSource fetches published podspecs. It is implemented by the CocoaPods
+// datasource and used to refresh SPEC CHECKSUMS in Podfile.lock.
+type specSource interface {
+	GetPodspec(ctx context.Context, pod, version string) ([]byte, error)
+}
+
+// Integration implements CocoaPods Podfile updates.
+type Integration struct {
+	ds    datasource.Datasource
+	specs specSource
+}
+
+// New creates a new CocoaPods integration.
+func New() *Integration {
+	ds, err := datasource.Get("cocoapods")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewCocoaPodsDatasource()
+	}
+	specs, _ := ds.(specSource) //nolint:errcheck // optional capability
+	return &Integration{
+		ds:    datasource.Cached(ds),
+		specs: specs,
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Podfiles.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"Podfile"}
+}
+
+// Detect finds Podfile files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip hidden directories and installed pods
+		if info.IsDir() && path != repoRoot && (strings.HasPrefix(info.Name(), ".") || info.Name() == "Pods") {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || info.Name() != "Podfile" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		// Validate path for security
+		if err := integrations.ValidateFilePath(path); err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		metadata := map[string]any{}
+		var resolved map[string]string
+		lockPath := filepath.Join(filepath.Dir(path), lockfileName)
+		if lockContent, err := os.ReadFile(lockPath); err == nil { // #nosec G304 - sibling of a validated path
+			resolved = parseLockfilePods(string(lockContent))
+			metadata["lockfile"] = filepath.Join(filepath.Dir(relPath), lockfileName)
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: parsePodfile(string(content), resolved),
+			Content:      content,
+			Metadata:     metadata,
+		})
+
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parsePodfile extracts trunk pods with a version requirement from a Podfile.
+// The current version is the resolved version from Podfile.lock when known,
+// otherwise the version in the requirement.
+func parsePodfile(content string, resolved map[string]string) []engine.Dependency {
+	var deps []engine.Dependency
+	seen := make(map[string]bool)
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "#"); idx >= 0 {
+			line = line[:idx]
+		}
+
+		m := podPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name, rest := m[1], m[2]
+
+		// Pods from git, a local path, or an explicit podspec aren't on trunk
+		if externalSourcePattern.MatchString(rest) {
+			continue
+		}
+
+		// Unpinned pods have nothing to update
+		req := requirementPattern.FindStringSubmatch(rest)
+		if req == nil {
+			continue
+		}
+		_, version, ok := splitRequirement(req[1])
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		current := version
+		if v, ok := resolved[name]; ok {
+			current = v
+		}
+
+		deps = append(deps, engine.Dependency{
+			Name:           name,
+			CurrentVersion: current,
+			Constraint:     req[1],
+			Type:           "pod",
+		})
+	}
+
+	return deps
+}
+
+// parseLockfilePods returns the resolved version of each pod in a Podfile.lock.
+func parseLockfilePods(content string) map[string]string {
+	pods := make(map[string]string)
+	inPods := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if !strings.HasPrefix(line, " ") && strings.HasSuffix(strings.TrimSpace(line), ":") {
+			inPods = strings.TrimSpace(line) == "PODS:"
+			continue
+		}
+		if !inPods {
+			continue
+		}
+		if m := lockPodPattern.FindStringSubmatch(line); m != nil {
+			pods[m[1]] = m[2]
+		}
+	}
+
+	return pods
+}
+
+// splitRequirement splits a requirement such as "~> 5.6" into its operator and version.
+func splitRequirement(req string) (op, version string, ok bool) {
+	m := operatorPattern.FindStringSubmatch(strings.TrimSpace(req))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
+
+// newRequirement rewrites a requirement to target, keeping its operator.
+// Optimistic ("~>") requirements keep their precision, so "~> 5.6" becomes
+// "~> 5.9" rather than the narrower "~> 5.9.1".
+func newRequirement(req, target string) string {
+	op, version, ok := splitRequirement(req)
+	if !ok {
+		return target
+	}
+
+	if op == "~>" {
+		parts := strings.Split(target, ".")
+		if n := len(strings.Split(version, ".")); n < len(parts) {
+			target = strings.Join(parts[:n], ".")
+		}
+	}
+
+	if op == "" {
+		return target
+	}
+	return op + " " + target
+}
+
+// requirementAllows reports whether version satisfies every clause of a
+// CocoaPods requirement. "~> 5.6" allows 5.6 up to, not including, 6.0 and
+// "~> 5.6.1" allows 5.6.1 up to, not including, 5.7. An empty requirement
+// allows any version.
+func requirementAllows(req, version string) bool {
+	for _, clause := range strings.Split(req, ",") {
+		if strings.TrimSpace(clause) == "" {
+			continue
+		}
+		op, bound, ok := splitRequirement(clause)
+		if !ok {
+			return false
+		}
+		cmp, err := resolve.CompareVersions(version, bound)
+		if err != nil {
+			return false
+		}
+
+		switch op {
+		case "", "=":
+			ok = cmp == 0
+		case ">":
+			ok = cmp > 0
+		case ">=":
+			ok = cmp >= 0
+		case "<":
+			ok = cmp < 0
+		case "<=":
+			ok = cmp <= 0
+		case "~>":
+			ok = cmp >= 0 && optimisticAllows(bound, version)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// optimisticAllows reports whether version is below the upper bound of "~> bound".
+func optimisticAllows(bound, version string) bool {
+	parts := strings.Split(bound, ".")
+	if len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+	last, err := strconv.Atoi(parts[len(parts)-1])
+	if err != nil {
+		return false
+	}
+	parts[len(parts)-1] = strconv.Itoa(last + 1)
+
+	cmp, err := resolve.CompareVersions(version, strings.Join(parts, "."))
+	return err == nil && cmp < 0
+}
+
+// HasLockfile reports whether a Podfile has a Podfile.lock next to it.
+func (i *Integration) HasLockfile(manifest *engine.Manifest) bool {
+	_, ok := manifest.Metadata["lockfile"].(string)
+	return ok
+}
+
+// Plan determines available updates for Podfile pods.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, dep.Name)
+			if latestErr != nil {
+				// Skip pods we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			dep.Constraint,
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "podfile_rewrite",
+	}, nil
+}
+
+// Apply executes the update by rewriting pod requirements in the Podfile and,
+// when present, resolved versions and checksums in Podfile.lock. In a
+// lockfile-only run the Podfile is left untouched and only updates its
+// requirements already allow are written to Podfile.lock; with no-lockfile
+// Podfile.lock is left untouched.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 || (!plan.WritesManifest() && !i.HasLockfile(plan.Manifest)) {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read Podfile: %w", err)
+	}
+
+	newContent, applied, errs := rewritePodfile(plan, string(oldContent))
+
+	if newContent != string(oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, []byte(newContent), 0o600); err != nil {
+			return nil, fmt.Errorf("write Podfile: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff("Podfile", string(oldContent), newContent)
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	result := &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: integrations.DependencyNames(applied),
+		Applied:        len(applied),
+		Failed:         len(errs),
+		ManifestDiff:   diff,
+	}
+
+	if lockPath, ok := plan.Manifest.Metadata["lockfile"].(string); ok && len(applied) > 0 && plan.WritesLockfile() {
+		lockDiff, lockErrs, err := i.updateLockfile(ctx, lockPath, applied, newContent, plan.WritesManifest())
+		if err != nil {
+			return nil, err
+		}
+		result.LockfileDiff = lockDiff
+		errs = append(errs, lockErrs...)
+	}
+
+	result.Errors = errs
+	return result, nil
+}
+
+// Rewrite applies the plan's updates to Podfile content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewritePodfile(plan, string(content))
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: integrations.DependencyNames(applied),
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewritePodfile rewrites the requirements of the plan's updates in content
+// and returns the updates that apply. With lockfile-only, content is returned
+// unchanged and only updates its requirements already allow apply.
+func rewritePodfile(plan *engine.UpdatePlan, content string) (newContent string, applied []*engine.Update, errs []string) {
+	newContent = content
+
+	for j := range plan.Updates {
+		update := plan.Updates[j]
+		update.TargetVersion = version.Normalize(integrationName, update.TargetVersion)
+		if !plan.WritesManifest() {
+			if !requirementAllows(update.Dependency.Constraint, update.TargetVersion) {
+				errs = append(errs, fmt.Sprintf("%s: requirement %q does not allow %s; lockfile-only leaves the Podfile unchanged",
+					update.Dependency.Name, update.Dependency.Constraint, update.TargetVersion))
+				continue
+			}
+			applied = append(applied, &update)
+			continue
+		}
+
+		req := newRequirement(update.Dependency.Constraint, update.TargetVersion)
+		if err := resolve.ValidateConstraint(integrationName, req); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
+
+		re := regexp.MustCompile(`(?m)^(\s*pod\s+['"]` + regexp.QuoteMeta(update.Dependency.Name) +
+			`['"]\s*,\s*['"])` + regexp.QuoteMeta(update.Dependency.Constraint) + `(['"])`)
+		if !re.MatchString(newContent) {
+			errs = append(errs, fmt.Sprintf("%s: requirement %q not found in Podfile", update.Dependency.Name, update.Dependency.Constraint))
+			continue
+		}
+		newContent = re.ReplaceAllString(newContent, "${1}"+req+"${2}")
+		applied = append(applied, &update)
+	}
+
+	return newContent, applied, errs
+}
+
+// updateLockfile rewrites resolved versions, spec checksums, the Podfile checksum
+// and, when the Podfile was rewritten, Podfile requirements in Podfile.lock for the
+// applied updates. Checksums that cannot be refreshed are reported so the user
+// knows to run `pod install`.
+func (i *Integration) updateLockfile(ctx context.Context, lockPath string, updates []*engine.Update, podfile string, requirements bool) (string, []string, error) {
+	if err := integrations.ValidateFilePath(lockPath); err != nil {
+		return "", nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(lockPath) // #nosec G304 - path is validated above
+	if err != nil {
+		return "", nil, fmt.Errorf("read Podfile.lock: %w", err)
+	}
+
+	content := string(oldContent)
+	var errs []string
+
+	for _, update := range updates {
+		name := update.Dependency.Name
+		root := name
+		if idx := strings.Index(name, "/"); idx > 0 {
+			root = name[:idx]
+		}
+
+		// Resolved versions of the pod and its subspecs in PODS
+		pods := regexp.MustCompile(`(?m)^(  - "?` + regexp.QuoteMeta(root) + `(?:/[^\s"(]+)?"? \()` +
+			regexp.QuoteMeta(update.Dependency.CurrentVersion) + `(\))`)
+		content = pods.ReplaceAllString(content, "${1}"+update.TargetVersion+"${2}")
+
+		// Podfile requirement recorded in DEPENDENCIES
+		if requirements {
+			req := newRequirement(update.Dependency.Constraint, update.TargetVersion)
+			deps := regexp.MustCompile(`(?m)^(  - "?` + regexp.QuoteMeta(name) + `"? \()` +
+				regexp.QuoteMeta(update.Dependency.Constraint) + `(\))`)
+			content = deps.ReplaceAllString(content, "${1}"+req+"${2}")
+		}
+
+		// Podspec checksum in SPEC CHECKSUMS
+		checksum := regexp.MustCompile(`(?m)^(  "?` + regexp.QuoteMeta(root) + `"?: )[0-9a-f]+$`)
+		if !checksum.MatchString(content) {
+			continue
+		}
+		sum, err := i.podspecChecksum(ctx, root, update.TargetVersion)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: checksum not refreshed (%v); run pod install", root, err))
+			continue
+		}
+		content = checksum.ReplaceAllString(content, "${1}"+sum)
+	}
+
+	podfileSum := sha1.Sum([]byte(podfile)) // #nosec G401 - CocoaPods checksum format
+	content = regexp.MustCompile(`(?m)^(PODFILE CHECKSUM: )[0-9a-f]+$`).
+		ReplaceAllString(content, "${1}"+hex.EncodeToString(podfileSum[:]))
+
+	if content == string(oldContent) {
+		return "", errs, nil
+	}
+
+	if err := os.WriteFile(lockPath, []byte(content), 0o600); err != nil {
+		return "", nil, fmt.Errorf("write Podfile.lock: %w", err)
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(lockfileName, string(oldContent), content)
+	if err != nil {
+		return "", nil, fmt.Errorf("generate diff: %w", err)
+	}
+	return diff, errs, nil
+}
+
+// podspecChecksum returns the SHA-1 of the published podspec, as recorded by
+// CocoaPods in SPEC CHECKSUMS.
+func (i *Integration) podspecChecksum(ctx context.Context, pod, version string) (string, error) {
+	if i.specs == nil {
+		return "", fmt.Errorf("podspec source unavailable")
+	}
+	spec, err := i.specs.GetPodspec(ctx, pod, version)
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum(spec) // #nosec G401 - CocoaPods checksum format
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Validate checks that every pod declaration in the Podfile can be parsed.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	for _, dep := range parsePodfile(string(manifest.Content), nil) {
+		if _, _, ok := splitRequirement(dep.Constraint); !ok {
+			return fmt.Errorf("invalid requirement for pod %s: %q", dep.Name, dep.Constraint)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,313 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package cocoapods
+
+import (
+	"context"
+	"crypto/sha1" // #nosec G505 - matches the checksum format under test
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testPodfile = `platform :ios, '15.0'
+
+target 'App' do
+  use_frameworks!
+
+  pod 'Alamofire', '~> 5.6'
+  pod "SnapKit",   '5.0.1' # exact pin
+  pod 'Kingfisher', :git => 'https://github.com/onevcat/Kingfisher.git', :tag => '7.0.0'
+  pod 'Local', :path => '../Local'
+  pod 'Unpinned'
+end
+`
+
+const testLockfile = `PODS:
+  - Alamofire (5.6.4)
+  - SnapKit (5.0.1)
+
+DEPENDENCIES:
+  - Alamofire (~> 5.6)
+  - SnapKit (= 5.0.1)
+
+SPEC CHECKSUMS:
+  Alamofire: 0123456789abcdef0123456789abcdef01234567
+  SnapKit: fedcba9876543210fedcba9876543210fedcba98
+
+PODFILE CHECKSUM: 00000000000000000000000000000000000000aa
+
+COCOAPODS: 1.15.2
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+	specs    map[string]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func (m *mockDatasource) GetPodspec(ctx context.Context, pod, version string) ([]byte, error) {
+	if spec, ok := m.specs[pod+"@"+version]; ok {
+		return []byte(spec), nil
+	}
+	return nil, context.Canceled
+}
+
+func writeProject(t *testing.T, withLock bool) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Podfile"), []byte(testPodfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if withLock {
+		if err := os.WriteFile(filepath.Join(dir, "Podfile.lock"), []byte(testLockfile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeProject(t, true)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+
+	m := manifests[0]
+	if m.Path != "Podfile" {
+		t.Errorf("Path = %q, want %q", m.Path, "Podfile")
+	}
+	if m.Metadata["lockfile"] != "Podfile.lock" {
+		t.Errorf("Metadata[lockfile] = %v, want %q", m.Metadata["lockfile"], "Podfile.lock")
+	}
+
+	// Git, path, and unpinned pods are skipped
+	if len(m.Dependencies) != 2 {
+		t.Fatalf("Dependencies = %+v, want Alamofire and SnapKit", m.Dependencies)
+	}
+
+	alamofire := m.Dependencies[0]
+	if alamofire.Name != "Alamofire" || alamofire.Constraint != "~> 5.6" || alamofire.CurrentVersion != "5.6.4" {
+		t.Errorf("Alamofire = %+v, want constraint ~> 5.6 resolved to 5.6.4", alamofire)
+	}
+	if snapkit := m.Dependencies[1]; snapkit.Name != "SnapKit" || snapkit.Constraint != "5.0.1" {
+		t.Errorf("SnapKit = %+v, want constraint 5.0.1", snapkit)
+	}
+}
+
+func TestDetect_SkipsGitPod(t *testing.T) {
+	deps := parsePodfile(`pod 'Kingfisher', git: 'https://github.com/onevcat/Kingfisher.git', tag: '7.0.0'`, nil)
+	if len(deps) != 0 {
+		t.Errorf("parsePodfile() = %+v, want git pod skipped", deps)
+	}
+}
+
+func TestNewRequirement(t *testing.T) {
+	tests := []struct {
+		req, target, want string
+	}{
+		{"~> 5.6", "5.9.1", "~> 5.9"},
+		{"~> 5.6.0", "5.9.1", "~> 5.9.1"},
+		{"~> 5", "6.2.0", "~> 6"},
+		{">= 1.0", "2.0.0", ">= 2.0.0"},
+		{"5.0.1", "5.7.1", "5.7.1"},
+	}
+
+	for _, tt := range tests {
+		if got := newRequirement(tt.req, tt.target); got != tt.want {
+			t.Errorf("newRequirement(%q, %q) = %q, want %q", tt.req, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeProject(t, true)
+	t.Chdir(dir)
+
+	spec := `{"name":"Alamofire","version":"5.9.1"}`
+	integ := &Integration{}
+	mock := &mockDatasource{
+		versions: map[string][]string{"Alamofire": {"5.6.4", "5.9.1", "6.0.0"}},
+		specs:    map[string]string{"Alamofire@5.9.1": spec},
+	}
+	integ.ds, integ.specs = mock, mock
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != "5.9.1" {
+		t.Fatalf("Plan() updates = %+v, want Alamofire -> 5.9.1", plan.Updates)
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 1 applied and no errors", result)
+	}
+
+	podfile, _ := os.ReadFile("Podfile")
+	if !strings.Contains(string(podfile), "  pod 'Alamofire', '~> 5.9'\n") {
+		t.Errorf("Podfile not rewritten:\n%s", podfile)
+	}
+	if !strings.Contains(string(podfile), ":git => 'https://github.com/onevcat/Kingfisher.git', :tag => '7.0.0'") {
+		t.Errorf("git pod was modified:\n%s", podfile)
+	}
+
+	lock, _ := os.ReadFile("Podfile.lock")
+	specSum := sha1.Sum([]byte(spec))
+	podfileSum := sha1.Sum(podfile)
+	for _, want := range []string{
+		"  - Alamofire (5.9.1)\n",
+		"  - Alamofire (~> 5.9)\n",
+		"  Alamofire: " + hex.EncodeToString(specSum[:]) + "\n",
+		"  SnapKit: fedcba9876543210fedcba9876543210fedcba98\n",
+		"PODFILE CHECKSUM: " + hex.EncodeToString(podfileSum[:]) + "\n",
+	} {
+		if !strings.Contains(string(lock), want) {
+			t.Errorf("Podfile.lock missing %q:\n%s", want, lock)
+		}
+	}
+	if result.LockfileDiff == "" {
+		t.Error("Apply() LockfileDiff is empty")
+	}
+}
+
+func TestApply_LockfileMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        engine.LockfileMode
+		wantPodfile bool
+		wantLock    bool
+	}{
+		{name: "lockfile-only", mode: engine.LockfileOnly, wantLock: true},
+		{name: "no-lockfile", mode: engine.LockfileSkip, wantPodfile: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeProject(t, true)
+			t.Chdir(dir)
+
+			integ := &Integration{}
+			mock := &mockDatasource{
+				versions: map[string][]string{"Alamofire": {"5.6.4", "5.9.1"}},
+				specs:    map[string]string{"Alamofire@5.9.1": `{"name":"Alamofire","version":"5.9.1"}`},
+			}
+			integ.ds, integ.specs = mock, mock
+
+			manifests, err := integ.Detect(context.Background(), ".")
+			if err != nil {
+				t.Fatalf("Detect() error = %v", err)
+			}
+			plan, err := integ.Plan(context.Background(), manifests[0], nil)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			plan.Lockfile = tt.mode
+
+			result, err := integ.Apply(context.Background(), plan)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if result.Applied != 1 || len(result.Errors) != 0 {
+				t.Fatalf("Apply() = %+v, want 1 applied and no errors", result)
+			}
+
+			podfile, _ := os.ReadFile("Podfile")
+			if podfileChanged := string(podfile) != testPodfile; podfileChanged != tt.wantPodfile {
+				t.Errorf("Podfile changed = %v, want %v:\n%s", podfileChanged, tt.wantPodfile, podfile)
+			}
+
+			lock, _ := os.ReadFile("Podfile.lock")
+			if lockChanged := string(lock) != testLockfile; lockChanged != tt.wantLock {
+				t.Errorf("Podfile.lock changed = %v, want %v:\n%s", lockChanged, tt.wantLock, lock)
+			}
+			if tt.wantLock {
+				for _, want := range []string{"  - Alamofire (5.9.1)\n", "  - Alamofire (~> 5.6)\n"} {
+					if !strings.Contains(string(lock), want) {
+						t.Errorf("Podfile.lock missing %q:\n%s", want, lock)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRequirementAllows(t *testing.T) {
+	tests := []struct {
+		req     string
+		version string
+		want    bool
+	}{
+		{req: "~> 5.6", version: "5.9.1", want: true},
+		{req: "~> 5.6", version: "6.0.0", want: false},
+		{req: "~> 5.6.1", version: "5.6.9", want: true},
+		{req: "~> 5.6.1", version: "5.7.0", want: false},
+		{req: "= 5.0.1", version: "5.0.2", want: false},
+		{req: "5.0.1", version: "5.0.1", want: true},
+		{req: ">= 1.0, < 2.0", version: "1.5.0", want: true},
+		{req: ">= 1.0, < 2.0", version: "2.0.0", want: false},
+		{req: "", version: "1.0.0", want: true},
+	}
+
+	for _, tt := range tests {
+		if got := requirementAllows(tt.req, tt.version); got != tt.want {
+			t.Errorf("requirementAllows(%q, %q) = %v, want %v", tt.req, tt.version, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package composer implements the Composer integration for PHP dependencies.
+// It detects composer.json files, reads require and require-dev, queries
+// Packagist for the newest version the constraint allows, and rewrites the
+// constraint in place, keeping its operator and precision (^8.1 becomes ^8.2)
+// and every other byte of the file. Platform requirements (php, ext-*, lib-*)
+// are skipped.
+package composer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("composer", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "composer"
+	manifestName    = "composer.json"
+)
+
+// sections are the requirement objects uptool updates, with the dependency
+// type recorded for entries found in each.
+var sections = map[string]string{
+	"require":     "direct",
+	"require-dev": "dev",
+}
+
+var (
+	// clausePattern splits a single-clause constraint such as "^8.1" into
+	// operator and version.
+	clausePattern = regexp.MustCompile(`^(\^|~|>=|>|==|=)?\s*(\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.]+)?)$`)
+
+	// wildcardPattern matches a wildcard constraint such as "2.1.*",
+	// capturing its fixed part.
+	wildcardPattern = regexp.MustCompile(`^(\d+(?:\.\d+){0,1})\.\*$`)
+
+	// rangePattern matches a lower and upper bound such as ">=1.0 <2.0" or
+	// ">=1.0,<2.0", capturing the lower bound, separator and upper bound.
+	rangePattern = regexp.MustCompile(`^(>=?\s*\d+(?:\.\d+){0,2})(\s*,\s*|\s+)(<=?\s*\d+(?:\.\d+){0,2})$`)
+)
+
+// requirement is a package constraint read from composer.json, with the byte
+// offsets of its JSON string value.
+type requirement struct {
+	name       string
+	constraint string
+	section    string
+	line       int
+	start      int
+	end        int
+}
+
+// Integration implements composer.json updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new Composer integration.
+func New() *Integration {
+	ds, err := datasource.Get("packagist")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewPackagistDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Composer manifests.
+func (i *Integration) SupportedFiles() []string {
+	return []string{manifestName}
+}
+
+// Detect finds composer.json files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// vendor/ holds the manifests of installed packages
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && info.Name() == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Name() != manifestName || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		reqs, err := parseRequirements(content)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: dependencies(reqs),
+			Content:      content,
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parseRequirements reads the string constraints of require and require-dev
+// in file order.
+func parseRequirements(content []byte) ([]requirement, error) {
+	var reqs []requirement
+	lineAt := func(offset int64) int {
+		return bytes.Count(content[:offset], []byte("\n")) + 1
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("%s is not a JSON object", manifestName)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		section, _ := tok.(string) //nolint:errcheck // object keys are always strings
+
+		if _, ok := sections[section]; !ok {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil {
+			return nil, err
+		} else if tok != json.Delim('{') {
+			return nil, fmt.Errorf("%s is not an object", section)
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			name, _ := tok.(string) //nolint:errcheck // object keys are always strings
+			keyEnd := dec.InputOffset()
+
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return nil, err
+			}
+			var constraint string
+			if err := json.Unmarshal(value, &constraint); err != nil {
+				continue
+			}
+
+			end := int(dec.InputOffset())
+			reqs = append(reqs, requirement{
+				name:       name,
+				constraint: constraint,
+				section:    section,
+				line:       lineAt(keyEnd),
+				start:      end - len(value),
+				end:        end,
+			})
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+
+	return reqs, nil
+}
+
+// isPlatform reports whether a requirement names the PHP runtime, an
+// extension or a system library rather than a Packagist package.
+func isPlatform(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+// dependencies returns the package requirements, skipping platform ones.
+func dependencies(reqs []requirement) []engine.Dependency {
+	deps := make([]engine.Dependency, 0, len(reqs))
+	for _, req := range reqs {
+		if isPlatform(req.name) {
+			continue
+		}
+		deps = append(deps, engine.Dependency{
+			Name:           req.name,
+			CurrentVersion: currentVersion(req.constraint),
+			Constraint:     req.constraint,
+			Type:           sections[req.section],
+			Registry:       "packagist",
+			Line:           req.line,
+		})
+	}
+	return deps
+}
+
+// currentVersion returns the lowest version a constraint names: the version
+// of a single clause, the fixed part of a wildcard, or the lower bound of a
+// range. Other constraints are returned unchanged.
+func currentVersion(constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+	if m := clausePattern.FindStringSubmatch(constraint); m != nil {
+		return m[2]
+	}
+	if m := wildcardPattern.FindStringSubmatch(constraint); m != nil {
+		return m[1]
+	}
+	if m := rangePattern.FindStringSubmatch(constraint); m != nil {
+		return strings.TrimLeft(m[1], ">= ")
+	}
+	return constraint
+}
+
+// constraintFor returns the semver constraint a Composer constraint
+// expresses. Composer's tilde is pessimistic, so "~1.2" means ">=1.2 <2.0",
+// and a wildcard such as "1.2.*" means ">=1.2.0 <1.3.0". ok is false for
+// unions, stability flags, branches and other constraints uptool does not
+// rewrite.
+func constraintFor(constraint string) (string, bool) {
+	constraint = strings.TrimSpace(constraint)
+	if m := clausePattern.FindStringSubmatch(constraint); m != nil {
+		switch m[1] {
+		case "~":
+			return "~> " + m[2], true
+		case "==":
+			return m[2], true
+		}
+		return m[1] + m[2], true
+	}
+	if m := wildcardPattern.FindStringSubmatch(constraint); m != nil {
+		return "~> " + m[1] + ".0", true
+	}
+	if m := rangePattern.FindStringSubmatch(constraint); m != nil {
+		return m[1] + ", " + m[3], true
+	}
+	return "", false
+}
+
+// newConstraint rewrites a constraint to target, keeping its operator and
+// precision, so "^8.1" becomes "^8.2" rather than a pinned "8.2.3". A range
+// moves its lower bound and keeps the upper one.
+func newConstraint(constraint, target string) string {
+	constraint = strings.TrimSpace(constraint)
+	target = version.Normalize(integrationName, target)
+
+	if m := clausePattern.FindStringSubmatch(constraint); m != nil {
+		return strings.TrimSuffix(constraint, m[2]) + truncate(target, m[2])
+	}
+	if m := wildcardPattern.FindStringSubmatch(constraint); m != nil {
+		return truncate(target, m[1]) + ".*"
+	}
+	if m := rangePattern.FindStringSubmatch(constraint); m != nil {
+		lower := strings.TrimLeft(m[1], ">= ")
+		return strings.TrimSuffix(m[1], lower) + truncate(target, lower) + m[2] + m[3]
+	}
+	return target
+}
+
+// truncate cuts target to the number of components in like, so "8.2.3" with
+// "8.1" becomes "8.2". Prereleases are kept whole.
+func truncate(target, like string) string {
+	parts := strings.Split(target, ".")
+	if n := len(strings.Split(like, ".")); n < len(parts) && !strings.Contains(target, "-") {
+		return strings.Join(parts[:n], ".")
+	}
+	return target
+}
+
+// belowUpperBound reports whether target satisfies the upper bound of a range
+// constraint; other constraints have none.
+func belowUpperBound(constraint, target string) bool {
+	m := rangePattern.FindStringSubmatch(strings.TrimSpace(constraint))
+	if m == nil {
+		return true
+	}
+	upper, err := semver.NewConstraint(m[3])
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(target)
+	return err == nil && upper.Check(v)
+}
+
+// Plan determines available updates for Composer packages.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		// Unions, branches and stability flags can't be rewritten to a version
+		constraint, ok := constraintFor(dep.Constraint)
+		if !ok {
+			continue
+		}
+
+		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, dep.Name)
+			if latestErr != nil {
+				// Skip packages we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			constraint,
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+		targetVersion = version.Normalize(integrationName, targetVersion)
+
+		// A target the constraint already covers at its precision changes
+		// nothing, and a range can't move its lower bound past its upper one
+		if newConstraint(dep.Constraint, targetVersion) == strings.TrimSpace(dep.Constraint) || !belowUpperBound(dep.Constraint, targetVersion) {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			ChangelogURL:  fmt.Sprintf("https://packagist.org/packages/%s", dep.Name),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "json_value_rewrite",
+	}, nil
+}
+
+// Apply executes the update plan by rewriting constraints in composer.json.
+// composer.lock is not modified; run `composer update` afterwards to refresh it.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read composer.json: %w", err)
+	}
+
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(rewritten.Content, oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, rewritten.Content, 0o600); err != nil {
+			return nil, fmt.Errorf("write composer.json: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(manifestName, string(oldContent), string(rewritten.Content))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		Errors:         rewritten.Errors,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to composer.json content in memory.
+// Only the constraint strings change, so key order, indentation and escaping
+// elsewhere are kept.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	reqs, err := parseRequirements(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse composer.json: %w", err)
+	}
+
+	type edit struct {
+		start, end int
+		value      string
+	}
+	var (
+		edits   []edit
+		applied []string
+		errs    []string
+	)
+
+	for _, update := range plan.Updates {
+		dep := update.Dependency
+		constraint := newConstraint(dep.Constraint, update.TargetVersion)
+		if err := resolve.ValidateConstraint(integrationName, constraint); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dep.Name, err))
+			continue
+		}
+
+		found := false
+		for _, req := range reqs {
+			if req.name == dep.Name && sections[req.section] == dep.Type && req.constraint == dep.Constraint {
+				edits = append(edits, edit{start: req.start, end: req.end, value: `"` + constraint + `"`})
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: constraint %q not found in composer.json", dep.Name, dep.Constraint))
+			continue
+		}
+		applied = append(applied, dep.Name)
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+	newContent := content
+	for _, e := range edits {
+		newContent = append(append(append([]byte{}, newContent[:e.start]...), e.value...), newContent[e.end:]...)
+	}
+
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// Validate checks that composer.json is valid JSON with well-formed
+// requirement sections.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	if _, err := parseRequirements(manifest.Content); err != nil {
+		return fmt.Errorf("invalid composer.json %s: %w", manifest.Path, err)
+	}
+	return nil
+}
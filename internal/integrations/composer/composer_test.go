@@ -0,0 +1,264 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package composer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testComposer = `{
+    "name": "acme/app",
+    "require": {
+        "php": "^8.1",
+        "ext-json": "*",
+        "laravel/framework": "^10.1",
+        "guzzlehttp/guzzle": "~7.5",
+        "symfony/yaml": ">=6.0 <7.0",
+        "monolog/monolog": "^2.0 || ^3.0",
+        "acme/internal": "dev-main"
+    },
+    "require-dev": {
+        "phpunit/phpunit": "10.2.*"
+    },
+    "config": {
+        "sort-packages": true
+    }
+}
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+// packagist mirrors the versions Packagist publishes for the test packages.
+func packagist() *mockDatasource {
+	return &mockDatasource{versions: map[string][]string{
+		"laravel/framework": {"v11.0.0", "v10.48.4", "v10.2.0", "v10.1.0"},
+		"guzzlehttp/guzzle": {"7.8.1", "7.5.0", "6.5.8"},
+		"symfony/yaml":      {"v7.0.3", "v6.4.3", "v6.0.0"},
+		"monolog/monolog":   {"3.6.0", "2.9.1"},
+		"phpunit/phpunit":   {"11.0.0", "10.5.10", "10.2.7", "10.2.1"},
+	}}
+}
+
+func writeComposer(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestName), []byte(testComposer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	vendor := filepath.Join(dir, "vendor", "acme", "lib")
+	if err := os.MkdirAll(vendor, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(vendor, manifestName), []byte(testComposer), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeComposer(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != manifestName {
+		t.Fatalf("Detect() = %+v, want only composer.json (vendor/ skipped)", manifests)
+	}
+
+	// Platform requirements are skipped
+	want := []engine.Dependency{
+		{Name: "laravel/framework", CurrentVersion: "10.1", Constraint: "^10.1", Type: "direct", Registry: "packagist", Line: 6},
+		{Name: "guzzlehttp/guzzle", CurrentVersion: "7.5", Constraint: "~7.5", Type: "direct", Registry: "packagist", Line: 7},
+		{Name: "symfony/yaml", CurrentVersion: "6.0", Constraint: ">=6.0 <7.0", Type: "direct", Registry: "packagist", Line: 8},
+		{Name: "monolog/monolog", CurrentVersion: "^2.0 || ^3.0", Constraint: "^2.0 || ^3.0", Type: "direct", Registry: "packagist", Line: 9},
+		{Name: "acme/internal", CurrentVersion: "dev-main", Constraint: "dev-main", Type: "direct", Registry: "packagist", Line: 10},
+		{Name: "phpunit/phpunit", CurrentVersion: "10.2", Constraint: "10.2.*", Type: "dev", Registry: "packagist", Line: 13},
+	}
+	deps := manifests[0].Dependencies
+	if len(deps) != len(want) {
+		t.Fatalf("Dependencies = %+v, want %+v", deps, want)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Errorf("Dependencies[%d] = %+v, want %+v", i, deps[i], want[i])
+		}
+	}
+}
+
+func TestNewConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		target     string
+		want       string
+	}{
+		{constraint: "^8.1", target: "8.2.3", want: "^8.2"},
+		{constraint: "^1.2.3", target: "1.4.0", want: "^1.4.0"},
+		{constraint: "~7.5", target: "7.8.1", want: "~7.8"},
+		{constraint: "1.2.3", target: "v1.2.4", want: "1.2.4"},
+		{constraint: ">= 6.0", target: "7.0.3", want: ">= 7.0"},
+		{constraint: ">=6.0 <7.0", target: "6.4.3", want: ">=6.4 <7.0"},
+		{constraint: ">=6.0,<7.0", target: "6.4.3", want: ">=6.4,<7.0"},
+		{constraint: "10.2.*", target: "10.5.10", want: "10.5.*"},
+		{constraint: "^2.0", target: "3.0.0-beta1", want: "^3.0.0-beta1"},
+	}
+
+	for _, tt := range tests {
+		if got := newConstraint(tt.constraint, tt.target); got != tt.want {
+			t.Errorf("newConstraint(%q, %q) = %q, want %q", tt.constraint, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	integ := &Integration{ds: packagist()}
+
+	reqs, err := parseRequirements([]byte(testComposer))
+	if err != nil {
+		t.Fatalf("parseRequirements() error = %v", err)
+	}
+	manifest := &engine.Manifest{Path: manifestName, Type: integrationName, Dependencies: dependencies(reqs)}
+
+	plan, err := integ.Plan(context.Background(), manifest, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// The newest version each constraint allows. Unions and branches are
+	// skipped, and "10.2.*" already covers 10.2.7 so it needs no rewrite.
+	want := map[string]string{
+		"laravel/framework": "10.48.4",
+		"guzzlehttp/guzzle": "7.8.1",
+		"symfony/yaml":      "6.4.3",
+	}
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan() updates = %v, want %v", got, want)
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("Plan() target for %s = %q, want %q", name, got[name], target)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeComposer(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: packagist()}
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	planCtx := engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Update: "minor"})
+	plan, err := integ.Plan(context.Background(), manifests[0], planCtx)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 4 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 4 applied and no errors", result)
+	}
+
+	content, _ := os.ReadFile(manifestName)
+
+	// Constraints keep their operator and precision; nothing else changes
+	want := strings.NewReplacer(
+		`"^10.1"`, `"^10.48"`,
+		`"~7.5"`, `"~7.8"`,
+		`">=6.0 <7.0"`, `">=6.4 <7.0"`,
+		`"10.2.*"`, `"10.5.*"`,
+	).Replace(testComposer)
+	if string(content) != want {
+		t.Errorf("rewritten composer.json =\n%s\nwant\n%s", content, want)
+	}
+}
+
+func TestRewrite_NotFound(t *testing.T) {
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: manifestName, Type: integrationName},
+		Updates: []engine.Update{{
+			Dependency:    engine.Dependency{Name: "laravel/framework", Constraint: "^9.0", Type: "direct"},
+			TargetVersion: "10.48.4",
+		}},
+	}
+
+	result, err := New().Rewrite(context.Background(), plan, []byte(testComposer))
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Applied != 0 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], `constraint "^9.0" not found`) {
+		t.Errorf("Rewrite() = %+v, want the missing constraint reported", result)
+	}
+	if string(result.Content) != testComposer {
+		t.Error("Rewrite() changed content without applying an update")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: manifestName, Content: []byte(testComposer)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	for _, content := range []string{`{"require": {`, `{"require": []}`, `[]`} {
+		if err := integ.Validate(context.Background(), &engine.Manifest{Path: manifestName, Content: []byte(content)}); err == nil {
+			t.Errorf("Validate(%q) expected error", content)
+		}
+	}
+}
@@ -0,0 +1,373 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package devcontainer implements the Dev Container integration.
+// It detects devcontainer.json files (JSON with comments), collects the base
+// image and the OCI references of features, resolves newer tags through the
+// container registry, and rewrites tags in place so comments and trailing
+// commas are preserved.
+package devcontainer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+)
+
+func init() {
+	integrations.Register(integrationName, func() engine.Integration {
+		return New()
+	})
+}
+
+const integrationName = "devcontainer"
+
+// Dependency types of dev container references.
+const (
+	depTypeImage   = "image"
+	depTypeFeature = "feature"
+)
+
+// Config is the part of devcontainer.json that pins versions.
+type Config struct {
+	Features map[string]json.RawMessage `json:"features"`
+	Image    string                     `json:"image"`
+}
+
+// Integration implements dev container updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new dev container integration.
+func New() *Integration {
+	ds, err := datasource.Get("docker-hub")
+	if err != nil {
+		ds = datasource.NewDockerHubDatasource()
+	}
+	return &Integration{ds: datasource.Cached(ds)}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of dev container configuration files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{
+		".devcontainer.json",
+		".devcontainer/devcontainer.json",
+		".devcontainer/*/devcontainer.json",
+	}
+}
+
+// isConfigFile reports whether path is a dev container configuration:
+// .devcontainer.json, .devcontainer/devcontainer.json, or
+// .devcontainer/<name>/devcontainer.json.
+func isConfigFile(path string) bool {
+	name := filepath.Base(path)
+	if name == ".devcontainer.json" {
+		return true
+	}
+	if name != "devcontainer.json" {
+		return false
+	}
+	dir := filepath.Dir(path)
+	return filepath.Base(dir) == ".devcontainer" || filepath.Base(filepath.Dir(dir)) == ".devcontainer"
+}
+
+// Detect finds devcontainer.json files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// Configurations conventionally live under the hidden .devcontainer
+			if info.Name() != ".devcontainer" && filter.ShouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !isConfigFile(path) || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		deps, err := parseConfig(content)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+		if len(deps) == 0 {
+			return nil
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: deps,
+			Content:      content,
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parseConfig returns the versioned image and features of a devcontainer.json.
+func parseConfig(content []byte) ([]engine.Dependency, error) {
+	var config Config
+	if err := json.Unmarshal(sanitize(content), &config); err != nil {
+		return nil, err
+	}
+
+	var deps []engine.Dependency
+	if name, tag, ok := splitReference(config.Image); ok {
+		deps = append(deps, engine.Dependency{
+			Name:           name,
+			CurrentVersion: tag,
+			Type:           depTypeImage,
+			Registry:       "docker",
+		})
+	}
+
+	features := make([]engine.Dependency, 0, len(config.Features))
+	for ref := range config.Features {
+		name, tag, ok := splitReference(ref)
+		if !ok {
+			continue
+		}
+		features = append(features, engine.Dependency{
+			Name:           name,
+			CurrentVersion: tag,
+			Type:           depTypeFeature,
+			Registry:       "oci",
+		})
+	}
+	sort.Slice(features, func(a, b int) bool { return features[a].Name < features[b].Name })
+
+	return append(deps, features...), nil
+}
+
+// splitReference splits an OCI reference into its repository and tag. Local
+// features ("./feature"), tarball URLs, digest pins and references without a
+// tag or tagged latest are not versioned.
+func splitReference(ref string) (name, tag string, ok bool) {
+	if ref == "" || strings.HasPrefix(ref, ".") || strings.Contains(ref, "://") || strings.Contains(ref, "@") {
+		return "", "", false
+	}
+	slash := strings.LastIndex(ref, "/")
+	colon := strings.LastIndex(ref, ":")
+	if colon <= slash {
+		return "", "", false
+	}
+	name, tag = ref[:colon], ref[colon+1:]
+	if tag == "" || tag == "latest" {
+		return "", "", false
+	}
+	return name, tag, true
+}
+
+// Plan determines available updates for a devcontainer.json. Tags keep their
+// precision: a feature pinned to major version "1" moves to "2", not "2.1.0".
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		available, err := i.ds.GetVersions(ctx, dep.Name)
+		if err != nil {
+			continue
+		}
+
+		candidates := samePrecision(available, dep.CurrentVersion)
+		if len(candidates) == 0 {
+			continue
+		}
+
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			dep.Constraint,
+			candidates,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" || targetVersion == dep.CurrentVersion {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "jsonc_rewrite",
+	}, nil
+}
+
+// samePrecision keeps the versions with as many dot-separated components as
+// current.
+func samePrecision(versions []string, current string) []string {
+	parts := strings.Count(current, ".")
+	kept := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if strings.Count(v, ".") == parts {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// Apply executes the update by rewriting tags in devcontainer.json.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read devcontainer.json: %w", err)
+	}
+
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(rewritten.Content) != string(oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, rewritten.Content, 0o600); err != nil {
+			return nil, fmt.Errorf("write devcontainer.json: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(plan.Manifest.Path, string(oldContent), string(rewritten.Content))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		Errors:         rewritten.Errors,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to devcontainer.json content in memory.
+// Only the string literals holding the references are edited, so comments,
+// trailing commas and formatting are kept.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	literals := stringLiterals(sanitize(content))
+
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	var applied, errs []string
+
+	for j := range plan.Updates {
+		update := &plan.Updates[j]
+		oldRef := update.Dependency.Name + ":" + update.Dependency.CurrentVersion
+		newRef := update.Dependency.Name + ":" + update.TargetVersion
+
+		found := false
+		for _, lit := range literals {
+			if lit.value != oldRef {
+				continue
+			}
+			raw := string(content[lit.start:lit.end])
+			edits = append(edits, edit{start: lit.start, end: lit.end, text: strings.Replace(raw, oldRef, newRef, 1)})
+			found = true
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: %s not found in %s", update.Dependency.Name, oldRef, plan.Manifest.Path))
+			continue
+		}
+		applied = append(applied, update.Dependency.Name)
+	}
+
+	// Splice from the end so earlier offsets stay valid
+	sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+	newContent := string(content)
+	for _, e := range edits {
+		newContent = newContent[:e.start] + e.text + newContent[e.end:]
+	}
+
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// Validate checks that devcontainer.json parses as JSON with comments.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	var config Config
+	if err := json.Unmarshal(sanitize(manifest.Content), &config); err != nil {
+		return fmt.Errorf("invalid devcontainer.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package devcontainer
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const fixture = `// Dev container for local development
+{
+	"name": "uptool", // shown in the editor
+	/* The base image is pinned to a minor release */
+	"image": "mcr.microsoft.com/devcontainers/go:1.24",
+	"features": {
+		"ghcr.io/devcontainers/features/node:1": {
+			"version": "lts", // keep on LTS
+		},
+		"ghcr.io/devcontainers/features/docker-in-docker:2.11.0": {},
+		"./local-feature": {},
+	},
+	"postCreateCommand": "echo \"// not a comment\"",
+}
+`
+
+func TestSanitize(t *testing.T) {
+	sanitized := sanitize([]byte(fixture))
+	if len(sanitized) != len(fixture) {
+		t.Fatalf("sanitize changed length: %d != %d", len(sanitized), len(fixture))
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(sanitized, &got); err != nil {
+		t.Fatalf("sanitized content is not JSON: %v\n%s", err, sanitized)
+	}
+	if got["postCreateCommand"] != `echo "// not a comment"` {
+		t.Errorf("postCreateCommand = %q, comment markers inside strings must be kept", got["postCreateCommand"])
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	deps, err := parseConfig([]byte(fixture))
+	if err != nil {
+		t.Fatalf("parseConfig() error = %v", err)
+	}
+
+	want := []engine.Dependency{
+		{Name: "mcr.microsoft.com/devcontainers/go", CurrentVersion: "1.24", Type: depTypeImage, Registry: "docker"},
+		{Name: "ghcr.io/devcontainers/features/docker-in-docker", CurrentVersion: "2.11.0", Type: depTypeFeature, Registry: "oci"},
+		{Name: "ghcr.io/devcontainers/features/node", CurrentVersion: "1", Type: depTypeFeature, Registry: "oci"},
+	}
+	if !reflect.DeepEqual(deps, want) {
+		t.Errorf("parseConfig() =\n%+v\nwant\n%+v", deps, want)
+	}
+}
+
+func TestSplitReference(t *testing.T) {
+	tests := []struct {
+		ref  string
+		name string
+		tag  string
+		ok   bool
+	}{
+		{ref: "ghcr.io/devcontainers/features/node:1", name: "ghcr.io/devcontainers/features/node", tag: "1", ok: true},
+		{ref: "localhost:5000/feature:1.2", name: "localhost:5000/feature", tag: "1.2", ok: true},
+		{ref: "localhost:5000/feature"},
+		{ref: "ghcr.io/devcontainers/features/node"},
+		{ref: "ghcr.io/devcontainers/features/node:latest"},
+		{ref: "ghcr.io/devcontainers/features/node@sha256:abc"},
+		{ref: "./local-feature"},
+		{ref: "https://example.com/feature.tgz"},
+	}
+
+	for _, tt := range tests {
+		name, tag, ok := splitReference(tt.ref)
+		if name != tt.name || tag != tt.tag || ok != tt.ok {
+			t.Errorf("splitReference(%q) = %q, %q, %v; want %q, %q, %v", tt.ref, name, tag, ok, tt.name, tt.tag, tt.ok)
+		}
+	}
+}
+
+func TestIntegration_Detect(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, rel := range []string{
+		filepath.Join(".devcontainer", "devcontainer.json"),
+		filepath.Join(".devcontainer", "python", "devcontainer.json"),
+		".devcontainer.json",
+		filepath.Join("other", "devcontainer.json"),
+	} {
+		path := filepath.Join(tmpDir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := New().Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	var paths []string
+	for _, m := range manifests {
+		paths = append(paths, m.Path)
+	}
+	want := []string{
+		filepath.Join(".devcontainer", "devcontainer.json"),
+		filepath.Join(".devcontainer", "python", "devcontainer.json"),
+		".devcontainer.json",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Detect() paths = %v, want %v", paths, want)
+	}
+}
+
+func TestIntegration_Plan(t *testing.T) {
+	integ := &Integration{ds: mockDatasource{
+		"mcr.microsoft.com/devcontainers/go":              {"1.23", "1.24", "1.25", "1.25.1", "latest"},
+		"ghcr.io/devcontainers/features/node":             {"1", "1.6", "1.6.2", "2", "2.0.0"},
+		"ghcr.io/devcontainers/features/docker-in-docker": {"2.11.0", "2.12.0", "2", "2.12"},
+	}}
+
+	deps, err := parseConfig([]byte(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := &engine.Manifest{Path: "devcontainer.json", Type: integrationName, Dependencies: deps}
+
+	plan, err := integ.Plan(context.Background(), manifest, engine.NewPlanContext())
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	want := map[string]string{
+		"mcr.microsoft.com/devcontainers/go":              "1.25",
+		"ghcr.io/devcontainers/features/node":             "2",
+		"ghcr.io/devcontainers/features/docker-in-docker": "2.12.0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Plan() targets = %v, want %v", got, want)
+	}
+}
+
+func TestIntegration_RewritePreservesComments(t *testing.T) {
+	deps, err := parseConfig([]byte(fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	targets := map[string]string{
+		"mcr.microsoft.com/devcontainers/go":  "1.25",
+		"ghcr.io/devcontainers/features/node": "2",
+	}
+	plan := &engine.UpdatePlan{Manifest: &engine.Manifest{Path: "devcontainer.json"}}
+	for _, dep := range deps {
+		if target, ok := targets[dep.Name]; ok {
+			plan.Updates = append(plan.Updates, engine.Update{Dependency: dep, TargetVersion: target})
+		}
+	}
+
+	result, err := New().Rewrite(context.Background(), plan, []byte(fixture))
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Applied != 2 || result.Failed != 0 {
+		t.Fatalf("Rewrite() applied %d, failed %d (%v)", result.Applied, result.Failed, result.Errors)
+	}
+
+	want := strings.NewReplacer(
+		"devcontainers/go:1.24", "devcontainers/go:1.25",
+		"features/node:1", "features/node:2",
+	).Replace(fixture)
+	if string(result.Content) != want {
+		t.Errorf("Rewrite() content =\n%s\nwant\n%s", result.Content, want)
+	}
+
+	for _, comment := range []string{"// Dev container", "// shown in the editor", "/* The base image", "// keep on LTS", `"./local-feature": {},`} {
+		if !strings.Contains(string(result.Content), comment) {
+			t.Errorf("Rewrite() dropped %q", comment)
+		}
+	}
+}
+
+func TestIntegration_Apply(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "devcontainer.json")
+	if err := os.WriteFile(path, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: path},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "ghcr.io/devcontainers/features/docker-in-docker", CurrentVersion: "2.11.0"},
+				TargetVersion: "2.12.0",
+			},
+			{
+				Dependency:    engine.Dependency{Name: "ghcr.io/devcontainers/features/missing", CurrentVersion: "1"},
+				TargetVersion: "2",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || result.Failed != 1 {
+		t.Errorf("Apply() applied %d, failed %d; want 1, 1", result.Applied, result.Failed)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"ghcr.io/devcontainers/features/docker-in-docker:2.12.0": {},`) {
+		t.Errorf("Apply() did not update the feature:\n%s", content)
+	}
+	if !strings.Contains(string(content), "// keep on LTS") {
+		t.Errorf("Apply() dropped a comment:\n%s", content)
+	}
+}
+
+// mockDatasource maps package names to their available versions.
+type mockDatasource map[string][]string
+
+func (m mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", nil
+}
+
+func (m mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	return m[pkg], nil
+}
+
+func (m mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return &datasource.PackageInfo{Name: pkg}, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package devcontainer
+
+import (
+	"encoding/json"
+)
+
+// jsonString is a string literal of a JSONC document and its byte range.
+type jsonString struct {
+	value      string
+	start, end int // content[start:end] is the literal, quotes included
+}
+
+// sanitize returns a copy of a JSONC document that encoding/json accepts:
+// comments and trailing commas are replaced by spaces. Every other byte keeps
+// its offset, so positions found in the copy apply to the original.
+func sanitize(content []byte) []byte {
+	out := make([]byte, len(content))
+	copy(out, content)
+
+	// Blank out // and /* */ comments, keeping newlines
+	for i := 0; i < len(out); i++ {
+		switch {
+		case out[i] == '"':
+			i = stringEnd(out, i) - 1
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+
+	// Drop commas followed only by whitespace before a closing bracket
+	for i := 0; i < len(out); i++ {
+		switch out[i] {
+		case '"':
+			i = stringEnd(out, i) - 1
+		case ',':
+			j := i + 1
+			for j < len(out) && isSpace(out[j]) {
+				j++
+			}
+			if j < len(out) && (out[j] == '}' || out[j] == ']') {
+				out[i] = ' '
+			}
+		}
+	}
+
+	return out
+}
+
+// stringLiterals returns the string literals of a sanitized document in order.
+func stringLiterals(sanitized []byte) []jsonString {
+	var literals []jsonString
+	for i := 0; i < len(sanitized); i++ {
+		if sanitized[i] != '"' {
+			continue
+		}
+		end := stringEnd(sanitized, i)
+		var value string
+		if err := json.Unmarshal(sanitized[i:end], &value); err == nil {
+			literals = append(literals, jsonString{value: value, start: i, end: end})
+		}
+		i = end - 1
+	}
+	return literals
+}
+
+// stringEnd returns the offset just past the string literal starting at start.
+func stringEnd(content []byte, start int) int {
+	for i := start + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(content)
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
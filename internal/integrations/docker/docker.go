@@ -18,9 +18,10 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 // SOFTWARE.
 
-// Package docker implements the Docker integration for updating Dockerfile and docker-compose files.
-// It detects Dockerfile and docker-compose.yml files, parses image references (FROM image:tag),
-// queries Docker Hub for version updates, and rewrites files while preserving structure.
+// Package docker implements the Docker integration for updating Dockerfile, docker-compose
+// and kustomization files. It detects Dockerfile, docker-compose.yml and kustomization.yaml
+// files, parses image references (FROM image:tag, image:, images: transformers), queries
+// Docker Hub for version updates, and rewrites files while preserving structure.
 //
 //nolint:govet // YAML struct field order is intentional for readability
 package docker
@@ -54,13 +55,21 @@ const integrationName = "docker"
 // FROM image:tag
 // FROM image:tag AS builder
 // FROM --platform=linux/amd64 image:tag
-var fromPattern = regexp.MustCompile(`^FROM\s+(?:--platform=[^\s]+\s+)?([^:\s]+)(?::([^\s@]+))?(?:@sha256:[a-f0-9]+)?(?:\s+AS\s+\S+)?`)
+// FROM image:tag@sha256:digest
+var fromPattern = regexp.MustCompile(`^FROM\s+(?:--platform=[^\s]+\s+)?([^:\s@]+)(?::([^\s@]+))?(?:@(sha256:[a-f0-9]+))?(?:\s+AS\s+\S+)?`)
 
 const defaultTag = "latest"
 
+// digestResolver looks up the digest an image tag currently points at. It is
+// implemented by the Docker Hub datasource and used to pin images by digest.
+type digestResolver interface {
+	GetDigest(ctx context.Context, image, tag string) (string, error)
+}
+
 // Integration implements Docker file updates.
 type Integration struct {
-	ds datasource.Datasource
+	ds      datasource.Datasource
+	digests digestResolver
 }
 
 // New creates a new Docker integration.
@@ -69,8 +78,10 @@ func New() *Integration {
 	if err != nil {
 		ds = datasource.NewDockerHubDatasource()
 	}
+	digests, _ := ds.(digestResolver)
 	return &Integration{
-		ds: ds,
+		ds:      datasource.Cached(ds),
+		digests: digests,
 	}
 }
 
@@ -79,6 +90,21 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of Dockerfiles, compose files and kustomizations.
+func (i *Integration) SupportedFiles() []string {
+	return []string{
+		"Dockerfile",
+		"Dockerfile.*",
+		"docker-compose.yml",
+		"docker-compose.yaml",
+		"compose.yml",
+		"compose.yaml",
+		"kustomization.yaml",
+		"kustomization.yml",
+		"Kustomization",
+	}
+}
+
 // ComposeFile represents the structure of a docker-compose.yml file.
 type ComposeFile struct {
 	Version  string                 `yaml:"version,omitempty"`
@@ -100,23 +126,22 @@ type Service struct {
 // Detect finds Dockerfile and docker-compose files in the repository.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
+	kustomizeRefs := make(map[string][]string)
 
+	filter := engine.NewWalkFilter(ctx, repoRoot)
 	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip hidden directories
-		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != repoRoot {
-			return filepath.SkipDir
-		}
-
-		// Skip vendor and node_modules directories
-		if info.IsDir() && (info.Name() == "vendor" || info.Name() == "node_modules") {
-			return filepath.SkipDir
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
-		if info.IsDir() {
+		if filter.ShouldSkipFile(path) {
 			return nil
 		}
 
@@ -125,7 +150,9 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 		isCompose := name == "docker-compose.yml" || name == "docker-compose.yaml" ||
 			name == "compose.yml" || name == "compose.yaml"
 
-		if !isDockerfile && !isCompose {
+		isKustomize := kustomizationFiles[name]
+
+		if !isDockerfile && !isCompose && !isKustomize {
 			return nil
 		}
 
@@ -146,13 +173,22 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 		var deps []engine.Dependency
 		var metadata map[string]interface{}
 
-		if isDockerfile {
+		switch {
+		case isDockerfile:
 			deps = i.extractDockerfileDeps(content)
 			metadata = map[string]interface{}{
 				"file_type":   "dockerfile",
 				"image_count": len(deps),
 			}
-		} else {
+		case isKustomize:
+			dir := filepath.Dir(relPath)
+			kustomizeRefs[dir] = kustomizeReferences(content, dir)
+			deps = i.extractKustomizeDeps(content)
+			metadata = map[string]interface{}{
+				"file_type":   "kustomize",
+				"image_count": len(deps),
+			}
+		default:
 			deps = i.extractComposeDeps(content)
 			metadata = map[string]interface{}{
 				"file_type":   "compose",
@@ -175,8 +211,13 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 		manifests = append(manifests, manifest)
 		return nil
 	})
+	if err != nil {
+		return manifests, err
+	}
+
+	linkKustomizations(manifests, kustomizeRefs)
 
-	return manifests, err
+	return manifests, nil
 }
 
 // extractDockerfileDeps parses Dockerfile content and extracts image references.
@@ -202,6 +243,7 @@ func (i *Integration) extractDockerfileDeps(content []byte) []engine.Dependency
 
 			image := matches[1]
 			tag := matches[2]
+			digest := matches[3]
 
 			// Skip scratch images
 			if image == "scratch" {
@@ -231,6 +273,7 @@ func (i *Integration) extractDockerfileDeps(content []byte) []engine.Dependency
 				Constraint:     tag,
 				Type:           "image",
 				Registry:       "docker-hub",
+				Digest:         digest,
 			})
 		}
 	}
@@ -271,6 +314,7 @@ func (i *Integration) extractComposeDeps(content []byte) []engine.Dependency {
 			Constraint:     tag,
 			Type:           "image",
 			Registry:       "docker-hub",
+			Digest:         imageDigest(service.Image),
 		})
 	}
 
@@ -278,11 +322,14 @@ func (i *Integration) extractComposeDeps(content []byte) []engine.Dependency {
 }
 
 // parseImageReference parses an image reference into image name and tag.
+// A reference pinned by digest alone (image@sha256:...) has the tag "sha256".
 func parseImageReference(ref string) (string, string) {
-	// Handle digest references (image@sha256:...)
-	if strings.Contains(ref, "@sha256:") {
-		parts := strings.SplitN(ref, "@", 2)
-		return parts[0], "sha256"
+	// Handle digest references (image@sha256:... or image:tag@sha256:...)
+	if name, _, ok := strings.Cut(ref, "@sha256:"); ok {
+		if !strings.Contains(name, ":") {
+			return name, "sha256"
+		}
+		ref = name
 	}
 
 	// Handle normal references (image:tag)
@@ -302,10 +349,24 @@ func parseImageReference(ref string) (string, string) {
 	return image, tag
 }
 
-// Plan determines available updates for Docker images.
+// imageDigest returns the "sha256:..." digest a reference is pinned to, if any.
+func imageDigest(ref string) string {
+	if _, digest, ok := strings.Cut(ref, "@"); ok && strings.HasPrefix(digest, "sha256:") {
+		return digest
+	}
+	return ""
+}
+
+// Plan determines available updates for Docker images. Images pinned by
+// digest, and all images when the policy sets pin_digest, are proposed with
+// the digest of the target tag so Apply can rewrite tag and digest together.
 func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
 	updates := make([]engine.Update, 0, len(manifest.Dependencies))
 
+	// Kustomize image entries keep their digest in a separate field
+	fileType, _ := manifest.Metadata["file_type"].(string)
+	pinDigest := planCtx != nil && planCtx.Policy != nil && planCtx.Policy.PinDigest && fileType != "kustomize"
+
 	for _, dep := range manifest.Dependencies {
 		// Skip latest tag (no specific version to update from)
 		if dep.CurrentVersion == defaultTag || dep.CurrentVersion == "sha256" {
@@ -338,12 +399,27 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 			continue
 		}
 
-		updates = append(updates, engine.Update{
+		update := engine.Update{
 			Dependency:    dep,
 			TargetVersion: targetVersion,
 			Impact:        string(impact),
 			PolicySource:  planCtx.GetPolicySource(),
-		})
+		}
+
+		// A digest pin must move with its tag, so without the new digest
+		// the update is not proposed at all
+		if dep.Digest != "" || pinDigest {
+			if i.digests == nil {
+				continue
+			}
+			digest, err := i.digests.GetDigest(ctx, dep.Name, targetVersion)
+			if err != nil {
+				continue
+			}
+			update.TargetDigest = digest
+		}
+
+		updates = append(updates, update)
 	}
 
 	return &engine.UpdatePlan{
@@ -372,42 +448,90 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("read docker file: %w", err)
 	}
 
-	// Create update map: old reference -> new reference
-	updateMap := make(map[string]string)
-	for idx := range plan.Updates {
-		update := &plan.Updates[idx]
-		oldRef := fmt.Sprintf("%s:%s", update.Dependency.Name, update.Dependency.CurrentVersion)
-		newRef := fmt.Sprintf("%s:%s", update.Dependency.Name, update.TargetVersion)
-		updateMap[oldRef] = newRef
-	}
-
-	// Replace image references in content
-	newContent := string(oldContent)
-	applied := 0
-
-	for oldRef, newRef := range updateMap {
-		if strings.Contains(newContent, oldRef) {
-			newContent = strings.ReplaceAll(newContent, oldRef, newRef)
-			applied++
-		}
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
 	}
 
 	// Write updated content
-	if err := os.WriteFile(plan.Manifest.Path, []byte(newContent), 0o600); err != nil {
+	if err := os.WriteFile(plan.Manifest.Path, rewritten.Content, 0o600); err != nil {
 		return nil, fmt.Errorf("write docker file: %w", err)
 	}
 
 	// Generate diff
-	diff := generateDiff(plan.Manifest.Path, string(oldContent), newContent)
+	diff := generateDiff(plan.Manifest.Path, string(oldContent), string(rewritten.Content))
 
 	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       0,
-		ManifestDiff: diff,
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		ManifestDiff:   diff,
 	}, nil
 }
 
+// Rewrite applies the plan's updates to Dockerfile, compose, or kustomization
+// content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	if fileType, _ := plan.Manifest.Metadata["file_type"].(string); fileType == "kustomize" {
+		newContent, applied, err := rewriteKustomizeTags(string(content), plan.Updates)
+		if err != nil {
+			return nil, err
+		}
+		return &engine.RewriteResult{
+			Content:        []byte(newContent),
+			AppliedUpdates: applied,
+			Applied:        len(applied),
+			Failed:         len(plan.Updates) - len(applied),
+		}, nil
+	}
+
+	// Replace image references in content
+	newContent := string(content)
+	var applied []string
+
+	for idx := range plan.Updates {
+		var ok bool
+		newContent, ok = rewriteImageReference(newContent, &plan.Updates[idx])
+		if ok {
+			applied = append(applied, plan.Updates[idx].Dependency.Name)
+		}
+	}
+
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+	}, nil
+}
+
+// rewriteImageReference replaces every name:tag reference of the update's
+// dependency, with or without a digest, by the target tag and digest. A
+// digest-pinned reference is left alone when the update carries no digest,
+// so a stale digest never ends up next to a new tag.
+func rewriteImageReference(content string, update *engine.Update) (string, bool) {
+	dep := update.Dependency
+	pattern := regexp.MustCompile(`(?m)(^|[\s"'])` + regexp.QuoteMeta(dep.Name+":"+dep.CurrentVersion) +
+		`(@sha256:[a-f0-9]+)?([\s"']|$)`)
+
+	replaced := false
+	content = pattern.ReplaceAllStringFunc(content, func(match string) string {
+		m := pattern.FindStringSubmatch(match)
+		pinned := m[2] != ""
+		if pinned && update.TargetDigest == "" {
+			return match
+		}
+
+		ref := dep.Name + ":" + update.TargetVersion
+		if update.TargetDigest != "" {
+			ref += "@" + update.TargetDigest
+		}
+		replaced = true
+		return m[1] + ref + m[3]
+	})
+	return content, replaced
+}
+
 // Validate checks if the Docker file is valid.
 func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
 	fileType, ok := manifest.Metadata["file_type"].(string)
@@ -415,6 +539,14 @@ func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) e
 		return fmt.Errorf("unknown docker file type")
 	}
 
+	if fileType == "kustomize" {
+		var k Kustomization
+		if err := yaml.Unmarshal(manifest.Content, &k); err != nil {
+			return fmt.Errorf("invalid kustomization YAML: %w", err)
+		}
+		return nil
+	}
+
 	if fileType == "compose" {
 		var compose ComposeFile
 		if err := yaml.Unmarshal(manifest.Content, &compose); err != nil {
@@ -476,7 +608,9 @@ func generateDiff(path, old, newContent string) string {
 			if strings.Contains(strings.ToUpper(oldLine), "FROM") ||
 				strings.Contains(strings.ToUpper(newLine), "FROM") ||
 				strings.Contains(oldLine, "image:") ||
-				strings.Contains(newLine, "image:") {
+				strings.Contains(newLine, "image:") ||
+				strings.Contains(oldLine, "newTag:") ||
+				strings.Contains(newLine, "newTag:") {
 				if oldLine != "" {
 					diff.WriteString("- " + oldLine + "\n")
 				}
@@ -23,6 +23,7 @@ package docker
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
@@ -61,6 +62,7 @@ func TestParseImageReference(t *testing.T) {
 		{"org/image:tag", "library/nginx:1.25", "library/nginx", "1.25"},
 		{"registry/org/image:tag", "gcr.io/project/image:1.0", "gcr.io/project/image", "1.0"},
 		{"digest reference", "nginx@sha256:abc123", "nginx", "sha256"},
+		{"tag and digest", "nginx:1.25@sha256:abc123", "nginx", "1.25"},
 		{"variable reference", "${IMAGE}:${TAG}", "", ""},
 	}
 
@@ -823,3 +825,230 @@ func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datas
 		Versions: []datasource.VersionInfo{},
 	}, nil
 }
+
+func TestIntegration_DetectGitignored(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"Dockerfile":          "FROM golang:1.21\n",
+		"build/Dockerfile":    "FROM node:20\n",
+		"Dockerfile.local":    "FROM alpine:3.19\n",
+		".gitignore":          "build/\nDockerfile.local\n",
+		"sandbox/compose.yml": "services:\n  db:\n    image: postgres:16\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := engine.WithExcludePaths(context.Background(), []string{"sandbox"})
+	manifests, err := New().Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != "Dockerfile" {
+		paths := make([]string, 0, len(manifests))
+		for _, m := range manifests {
+			paths = append(paths, m.Path)
+		}
+		t.Errorf("Detect() paths = %v, want only Dockerfile", paths)
+	}
+}
+
+// mockDigests resolves "image:tag" to a digest.
+type mockDigests map[string]string
+
+func (m mockDigests) GetDigest(ctx context.Context, image, tag string) (string, error) {
+	if digest, ok := m[image+":"+tag]; ok {
+		return digest, nil
+	}
+	return "", errors.New("manifest unknown")
+}
+
+const (
+	oldDigest = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	newDigest = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+)
+
+func TestIntegration_ExtractDigests(t *testing.T) {
+	integration := New()
+
+	dockerfile := "FROM nginx:1.25@" + oldDigest + " AS web\nFROM golang:1.22\n"
+	deps := integration.extractDockerfileDeps([]byte(dockerfile))
+	if len(deps) != 2 {
+		t.Fatalf("extractDockerfileDeps() returned %d deps, want 2", len(deps))
+	}
+	if deps[0].Name != "nginx" || deps[0].CurrentVersion != "1.25" || deps[0].Digest != oldDigest {
+		t.Errorf("pinned dep = %+v, want nginx 1.25 with digest", deps[0])
+	}
+	if deps[1].Digest != "" {
+		t.Errorf("tag-only dep has digest %q", deps[1].Digest)
+	}
+
+	compose := "services:\n  web:\n    image: nginx:1.25@" + oldDigest + "\n"
+	deps = integration.extractComposeDeps([]byte(compose))
+	if len(deps) != 1 || deps[0].CurrentVersion != "1.25" || deps[0].Digest != oldDigest {
+		t.Errorf("extractComposeDeps() = %+v, want nginx 1.25 with digest", deps)
+	}
+}
+
+func TestIntegration_PlanDigest(t *testing.T) {
+	ctx := context.Background()
+	integration := &Integration{
+		ds:      &mockDatasource{versions: []string{"1.27", "1.26", "1.25"}},
+		digests: mockDigests{"nginx:1.27": newDigest},
+	}
+
+	t.Run("digest pin gets the target digest", func(t *testing.T) {
+		manifest := &engine.Manifest{Dependencies: []engine.Dependency{
+			{Name: "nginx", CurrentVersion: "1.25", Type: "image", Digest: oldDigest},
+		}}
+		plan, err := integration.Plan(ctx, manifest, engine.NewPlanContext())
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != "1.27" || plan.Updates[0].TargetDigest != newDigest {
+			t.Errorf("Plan() updates = %+v, want 1.27 with new digest", plan.Updates)
+		}
+	})
+
+	t.Run("tag-only is pinned with pin_digest", func(t *testing.T) {
+		manifest := &engine.Manifest{Dependencies: []engine.Dependency{
+			{Name: "nginx", CurrentVersion: "1.25", Type: "image"},
+		}}
+
+		plan, err := integration.Plan(ctx, manifest, engine.NewPlanContext())
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 1 || plan.Updates[0].TargetDigest != "" {
+			t.Errorf("Plan() without pin_digest = %+v, want tag-only update", plan.Updates)
+		}
+
+		planCtx := engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Enabled: true, Update: "major", PinDigest: true})
+		plan, err = integration.Plan(ctx, manifest, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 1 || plan.Updates[0].TargetDigest != newDigest {
+			t.Errorf("Plan() with pin_digest = %+v, want new digest", plan.Updates)
+		}
+	})
+
+	t.Run("skips a digest pin whose digest cannot be resolved", func(t *testing.T) {
+		unresolved := &Integration{ds: integration.ds, digests: mockDigests{}}
+		manifest := &engine.Manifest{Dependencies: []engine.Dependency{
+			{Name: "nginx", CurrentVersion: "1.25", Type: "image", Digest: oldDigest},
+		}}
+		plan, err := unresolved.Plan(ctx, manifest, engine.NewPlanContext())
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 0 {
+			t.Errorf("Plan() updates = %+v, want none", plan.Updates)
+		}
+	})
+}
+
+func TestIntegration_ApplyDigest(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		update   engine.Update
+		want     string
+		wantDone int
+	}{
+		{
+			name:    "Dockerfile tag and digest",
+			file:    "Dockerfile",
+			content: "FROM nginx:1.25@" + oldDigest + " AS web\nFROM nginx:1.25.3\n",
+			update: engine.Update{
+				Dependency:    engine.Dependency{Name: "nginx", CurrentVersion: "1.25", Digest: oldDigest},
+				TargetVersion: "1.27",
+				TargetDigest:  newDigest,
+			},
+			want:     "FROM nginx:1.27@" + newDigest + " AS web\nFROM nginx:1.25.3\n",
+			wantDone: 1,
+		},
+		{
+			name:    "compose tag and digest",
+			file:    "compose.yaml",
+			content: "services:\n  web:\n    image: \"nginx:1.25@" + oldDigest + "\"\n",
+			update: engine.Update{
+				Dependency:    engine.Dependency{Name: "nginx", CurrentVersion: "1.25", Digest: oldDigest},
+				TargetVersion: "1.27",
+				TargetDigest:  newDigest,
+			},
+			want:     "services:\n  web:\n    image: \"nginx:1.27@" + newDigest + "\"\n",
+			wantDone: 1,
+		},
+		{
+			name:    "compose tag-only gains digest",
+			file:    "compose.yaml",
+			content: "services:\n  web:\n    image: nginx:1.25\n",
+			update: engine.Update{
+				Dependency:    engine.Dependency{Name: "nginx", CurrentVersion: "1.25"},
+				TargetVersion: "1.27",
+				TargetDigest:  newDigest,
+			},
+			want:     "services:\n  web:\n    image: nginx:1.27@" + newDigest + "\n",
+			wantDone: 1,
+		},
+		{
+			name:    "Dockerfile tag-only gains digest",
+			file:    "Dockerfile",
+			content: "FROM --platform=linux/amd64 nginx:1.25\n",
+			update: engine.Update{
+				Dependency:    engine.Dependency{Name: "nginx", CurrentVersion: "1.25"},
+				TargetVersion: "1.27",
+				TargetDigest:  newDigest,
+			},
+			want:     "FROM --platform=linux/amd64 nginx:1.27@" + newDigest + "\n",
+			wantDone: 1,
+		},
+		{
+			name:    "digest pin without a new digest is kept",
+			file:    "Dockerfile",
+			content: "FROM nginx:1.25@" + oldDigest + "\n",
+			update: engine.Update{
+				Dependency:    engine.Dependency{Name: "nginx", CurrentVersion: "1.25", Digest: oldDigest},
+				TargetVersion: "1.27",
+			},
+			want:     "FROM nginx:1.25@" + oldDigest + "\n",
+			wantDone: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := New().Apply(context.Background(), &engine.UpdatePlan{
+				Manifest: &engine.Manifest{Path: path},
+				Updates:  []engine.Update{tt.update},
+			})
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if result.Applied != tt.wantDone {
+				t.Errorf("Apply() applied = %d, want %d", result.Applied, tt.wantDone)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Apply() wrote:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
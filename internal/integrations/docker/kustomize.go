@@ -0,0 +1,282 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/resolve"
+)
+
+// kustomizationFiles are the file names kustomize recognizes as a kustomization.
+var kustomizationFiles = map[string]bool{
+	"kustomization.yaml": true,
+	"kustomization.yml":  true,
+	"Kustomization":      true,
+}
+
+// Kustomization represents the parts of a kustomization file uptool reads.
+type Kustomization struct {
+	Images     []KustomizeImage `yaml:"images,omitempty"`
+	Resources  []string         `yaml:"resources,omitempty"`
+	Bases      []string         `yaml:"bases,omitempty"`
+	Components []string         `yaml:"components,omitempty"`
+}
+
+// KustomizeImage is an entry of the kustomize images transformer.
+type KustomizeImage struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName,omitempty"`
+	NewTag  string `yaml:"newTag,omitempty"`
+	Digest  string `yaml:"digest,omitempty"`
+}
+
+// imageName returns the image the entry resolves to.
+func (img KustomizeImage) imageName() string {
+	if img.NewName != "" {
+		return img.NewName
+	}
+	return img.Name
+}
+
+// extractKustomizeDeps parses a kustomization and extracts images transformer entries.
+// Entries pinned by digest or without a newTag have no version to update and are skipped.
+func (i *Integration) extractKustomizeDeps(content []byte) []engine.Dependency {
+	var k Kustomization
+	if err := yaml.Unmarshal(content, &k); err != nil {
+		return nil
+	}
+
+	deps := make([]engine.Dependency, 0, len(k.Images))
+	seen := make(map[string]bool)
+
+	for _, img := range k.Images {
+		if img.NewTag == "" || img.Digest != "" {
+			continue
+		}
+
+		name := img.imageName()
+		if name == "" || strings.Contains(name, "$") {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s", name, img.NewTag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		deps = append(deps, engine.Dependency{
+			Name:           name,
+			CurrentVersion: img.NewTag,
+			Constraint:     img.NewTag,
+			Type:           "image",
+			Registry:       "docker-hub",
+		})
+	}
+
+	return deps
+}
+
+// kustomizeReferences returns the directories a kustomization builds on
+// (resources, bases and components), relative to repoRoot. File resources
+// and remote URLs are not followed.
+func kustomizeReferences(content []byte, dir string) []string {
+	var k Kustomization
+	if err := yaml.Unmarshal(content, &k); err != nil {
+		return nil
+	}
+
+	refs := make([]string, 0, len(k.Resources)+len(k.Bases)+len(k.Components))
+	for _, list := range [][]string{k.Resources, k.Bases, k.Components} {
+		for _, ref := range list {
+			if strings.Contains(ref, "://") || strings.HasPrefix(ref, "github.com/") {
+				continue
+			}
+			if ext := filepath.Ext(ref); ext == ".yaml" || ext == ".yml" || ext == ".json" {
+				continue
+			}
+			refs = append(refs, filepath.Clean(filepath.Join(dir, ref)))
+		}
+	}
+
+	return refs
+}
+
+// linkKustomizations records in each kustomize manifest's metadata the overlay
+// family it belongs to. Kustomizations connected through resources, bases or
+// components (a base and all overlays built on it) share a family, named after
+// the lexically first directory in it.
+func linkKustomizations(manifests []*engine.Manifest, refs map[string][]string) {
+	dirs := make(map[string]*engine.Manifest)
+	for _, m := range manifests {
+		if fileType, _ := m.Metadata["file_type"].(string); fileType == "kustomize" {
+			dirs[filepath.Dir(m.Path)] = m
+		}
+	}
+
+	parent := make(map[string]string, len(dirs))
+	var find func(string) string
+	find = func(d string) string {
+		if parent[d] == d {
+			return d
+		}
+		parent[d] = find(parent[d])
+		return parent[d]
+	}
+	for d := range dirs {
+		parent[d] = d
+	}
+
+	for d := range dirs {
+		for _, ref := range refs[d] {
+			if _, ok := dirs[ref]; !ok {
+				continue
+			}
+			a, b := find(d), find(ref)
+			if a == b {
+				continue
+			}
+			if b < a {
+				a, b = b, a
+			}
+			parent[b] = a
+		}
+	}
+
+	size := make(map[string]int)
+	for d := range dirs {
+		size[find(d)]++
+	}
+
+	for d, m := range dirs {
+		if root := find(d); size[root] > 1 {
+			m.Metadata["kustomize_family"] = root
+		}
+	}
+}
+
+// ReconcilePlans aligns kustomize image tags across a base and its overlays.
+// When the same image appears in several kustomizations of one family, every
+// occurrence is proposed the highest planned target its policy allows, and
+// updates without a group are grouped under the family so they are reported
+// together.
+func (i *Integration) ReconcilePlans(plans []*engine.UpdatePlan, planCtx *engine.PlanContext) []*engine.UpdatePlan {
+	families := make(map[string][]*engine.UpdatePlan)
+	for _, p := range plans {
+		if p.Manifest == nil || p.Manifest.Metadata == nil {
+			continue
+		}
+		if family, ok := p.Manifest.Metadata["kustomize_family"].(string); ok && family != "" {
+			families[family] = append(families[family], p)
+		}
+	}
+
+	names := make([]string, 0, len(families))
+	for family := range families {
+		names = append(names, family)
+	}
+	sort.Strings(names)
+
+	for _, family := range names {
+		if members := families[family]; len(members) > 1 {
+			resolve.AlignTargets(members, planCtx, "kustomize:"+family, nil)
+		}
+	}
+
+	return plans
+}
+
+// rewriteKustomizeTags updates the newTag of images transformer entries.
+// Only the tag scalar is replaced, so comments and formatting are preserved.
+// It returns the new content and the names of the images it updated.
+func rewriteKustomizeTags(content string, updates []engine.Update) (string, []string, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return "", nil, fmt.Errorf("parse kustomization: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return content, nil, nil
+	}
+
+	targets := make(map[string]*engine.Update, len(updates))
+	for idx := range updates {
+		u := &updates[idx]
+		targets[u.Dependency.Name+":"+u.Dependency.CurrentVersion] = u
+	}
+
+	lines := strings.Split(content, "\n")
+	seen := make(map[string]bool)
+	var applied []string
+
+	root := doc.Content[0]
+	for idx := 0; idx+1 < len(root.Content); idx += 2 {
+		if root.Content[idx].Value != "images" || root.Content[idx+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, entry := range root.Content[idx+1].Content {
+			var img KustomizeImage
+			if err := entry.Decode(&img); err != nil {
+				continue
+			}
+			key := img.imageName() + ":" + img.NewTag
+			update, ok := targets[key]
+			if !ok {
+				continue
+			}
+			tag := mappingValue(entry, "newTag")
+			if tag == nil || tag.Line < 1 || tag.Line > len(lines) {
+				continue
+			}
+			line := lines[tag.Line-1]
+			col := tag.Column - 1
+			if col < 0 || col > len(line) {
+				continue
+			}
+			lines[tag.Line-1] = line[:col] + strings.Replace(line[col:], img.NewTag, update.TargetVersion, 1)
+			if !seen[key] {
+				seen[key] = true
+				applied = append(applied, update.Dependency.Name)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n"), applied, nil
+}
+
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package docker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const kustomizeBase = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - deployment.yaml
+images:
+  - name: nginx
+    newTag: 1.24.0 # pinned for base
+  - name: app
+    newName: ghcr.io/example/app
+    digest: sha256:0123456789abcdef
+`
+
+const kustomizeOverlay = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+  - ../../base
+images:
+  - name: nginx
+    newTag: "1.25.0"
+`
+
+func writeKustomizeTree(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	files := map[string]string{
+		"k8s/base/kustomization.yaml":          kustomizeBase,
+		"k8s/overlays/prod/kustomization.yaml": kustomizeOverlay,
+		"other/kustomization.yaml":             "images:\n  - name: redis\n    newTag: 7.0.0\n",
+	}
+	for path, content := range files {
+		full := filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestIntegration_DetectKustomize(t *testing.T) {
+	root := writeKustomizeTree(t)
+
+	manifests, err := New().Detect(context.Background(), root)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 3 {
+		t.Fatalf("Detect() found %d manifests, want 3", len(manifests))
+	}
+
+	families := make(map[string]string)
+	for _, m := range manifests {
+		if m.Metadata["file_type"] != "kustomize" {
+			t.Errorf("%s: file_type = %v, want kustomize", m.Path, m.Metadata["file_type"])
+		}
+		family, _ := m.Metadata["kustomize_family"].(string)
+		families[filepath.ToSlash(m.Path)] = filepath.ToSlash(family)
+	}
+
+	if families["k8s/base/kustomization.yaml"] != "k8s/base" || families["k8s/overlays/prod/kustomization.yaml"] != "k8s/base" {
+		t.Errorf("base and overlay families = %v, want both k8s/base", families)
+	}
+	if families["other/kustomization.yaml"] != "" {
+		t.Errorf("standalone kustomization family = %q, want none", families["other/kustomization.yaml"])
+	}
+
+	for _, m := range manifests {
+		if filepath.ToSlash(m.Path) != "k8s/base/kustomization.yaml" {
+			continue
+		}
+		// The digest-pinned entry has no tag to update
+		if len(m.Dependencies) != 1 || m.Dependencies[0].Name != "nginx" || m.Dependencies[0].CurrentVersion != "1.24.0" {
+			t.Errorf("base dependencies = %+v, want only nginx 1.24.0", m.Dependencies)
+		}
+	}
+}
+
+func TestIntegration_KustomizeOverlayConsistent(t *testing.T) {
+	ctx := context.Background()
+	root := writeKustomizeTree(t)
+
+	integration := New()
+	manifests, err := integration.Detect(ctx, root)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	integration.ds = &mockDatasource{versions: []string{"1.24.0", "1.24.1", "1.25.0", "1.25.1"}}
+	planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "minor"}}
+
+	var plans []*engine.UpdatePlan
+	for _, m := range manifests {
+		if m.Metadata["kustomize_family"] == nil {
+			continue
+		}
+		plan, err := integration.Plan(ctx, m, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		plans = append(plans, plan)
+	}
+
+	plans = integration.ReconcilePlans(plans, planCtx)
+
+	for _, plan := range plans {
+		if len(plan.Updates) != 1 {
+			t.Fatalf("%s: %d updates, want 1", plan.Manifest.Path, len(plan.Updates))
+		}
+		u := plan.Updates[0]
+		if u.TargetVersion != "1.25.1" {
+			t.Errorf("%s: target = %q, want 1.25.1", plan.Manifest.Path, u.TargetVersion)
+		}
+		if u.Group != "kustomize:k8s/base" {
+			t.Errorf("%s: group = %q, want kustomize:k8s/base", plan.Manifest.Path, u.Group)
+		}
+
+		plan.Manifest.Path = filepath.Join(root, plan.Manifest.Path)
+		result, err := integration.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 1 || result.Failed != 0 {
+			t.Errorf("%s: applied = %d, failed = %d, want 1 and 0", plan.Manifest.Path, result.Applied, result.Failed)
+		}
+	}
+
+	base, err := os.ReadFile(filepath.Join(root, "k8s/base/kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(base), "newTag: 1.25.1 # pinned for base") {
+		t.Errorf("base not rewritten in place:\n%s", base)
+	}
+
+	overlay, err := os.ReadFile(filepath.Join(root, "k8s/overlays/prod/kustomization.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(overlay), `newTag: "1.25.1"`) {
+		t.Errorf("overlay not rewritten in place:\n%s", overlay)
+	}
+}
+
+func TestIntegration_KustomizeOverlayRespectsPolicy(t *testing.T) {
+	ctx := context.Background()
+	root := writeKustomizeTree(t)
+
+	integration := New()
+	manifests, err := integration.Detect(ctx, root)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	integration.ds = &mockDatasource{versions: []string{"1.24.0", "1.24.1", "1.25.0", "1.25.1"}}
+	planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "patch"}}
+
+	plans := make(map[string]*engine.UpdatePlan)
+	var family []*engine.UpdatePlan
+	for _, m := range manifests {
+		if m.Metadata["kustomize_family"] == nil {
+			continue
+		}
+		plan, err := integration.Plan(ctx, m, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		plans[filepath.ToSlash(m.Path)] = plan
+		family = append(family, plan)
+	}
+	overlay := plans["k8s/overlays/prod/kustomization.yaml"]
+	overlay.Updates[0].Group = "web"
+
+	integration.ReconcilePlans(family, planCtx)
+
+	// Raising the base from 1.24.0 to the overlay's 1.25.1 would be a minor update
+	if u := plans["k8s/base/kustomization.yaml"].Updates[0]; u.TargetVersion != "1.24.1" || u.Group != "" {
+		t.Errorf("base update = %s (group %q), want 1.24.1 without a group", u.TargetVersion, u.Group)
+	}
+	if u := overlay.Updates[0]; u.TargetVersion != "1.25.1" || u.Group != "web" {
+		t.Errorf("overlay update = %s (group %q), want 1.25.1 keeping group web", u.TargetVersion, u.Group)
+	}
+}
@@ -0,0 +1,566 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package gitlabci implements the GitLab CI integration.
+// It detects .gitlab-ci.yml files, collects the Docker images named by image:
+// and services: and the refs of include: entries that pull templates from
+// other projects, and rewrites tags and refs in place so YAML anchors and
+// comments are preserved.
+package gitlabci
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register(integrationName, func() engine.Integration {
+		return New()
+	})
+}
+
+const integrationName = "gitlabci"
+
+// Dependency types of pipeline references.
+const (
+	depTypeImage   = "image"
+	depTypeService = "service"
+	depTypeInclude = "include"
+)
+
+// Registries dependencies are resolved against.
+const (
+	registryDocker = "docker-hub"
+	registryGitLab = "gitlab"
+)
+
+const defaultTag = "latest"
+
+// pipelineFiles are the file names GitLab reads a pipeline from by default.
+var pipelineFiles = map[string]bool{
+	".gitlab-ci.yml":  true,
+	".gitlab-ci.yaml": true,
+}
+
+// tagLister lists the tags of a GitLab project ("group/name").
+type tagLister interface {
+	GetTags(ctx context.Context, project string) ([]string, error)
+}
+
+// Integration implements GitLab CI pipeline updates.
+type Integration struct {
+	images datasource.Datasource
+	refs   tagLister
+}
+
+// New creates a new GitLab CI integration. Included projects are resolved on
+// the GitLab instance named by CI_SERVER_HOST (gitlab.com when unset), with
+// GITLAB_TOKEN for private projects.
+func New() *Integration {
+	ds, err := datasource.Get("docker-hub")
+	if err != nil {
+		ds = datasource.NewDockerHubDatasource()
+	}
+	return &Integration{
+		images: datasource.Cached(ds),
+		refs:   registry.NewGitLabClient(os.Getenv("CI_SERVER_HOST"), os.Getenv("GITLAB_TOKEN")),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of GitLab CI pipeline files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{".gitlab-ci.yml", ".gitlab-ci.yaml"}
+}
+
+// reference is an image or include ref found in a pipeline, along with the
+// YAML scalar whose text holds its version.
+type reference struct {
+	node    *yaml.Node
+	kind    string
+	name    string
+	version string
+}
+
+// registry returns the registry the reference is resolved against.
+func (r *reference) registry() string {
+	if r.kind == depTypeInclude {
+		return registryGitLab
+	}
+	return registryDocker
+}
+
+// Detect finds .gitlab-ci.yml files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !pipelineFiles[info.Name()] || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		refs, err := findReferences(content)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+
+		deps := dependencies(refs)
+		if len(deps) == 0 {
+			return nil
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: deps,
+			Content:      content,
+			Metadata: map[string]interface{}{
+				"image_count":   countKind(deps, depTypeImage, depTypeService),
+				"include_count": countKind(deps, depTypeInclude),
+			},
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// dependencies converts references into dependencies, listing each image tag
+// or project ref once.
+func dependencies(refs []reference) []engine.Dependency {
+	deps := make([]engine.Dependency, 0, len(refs))
+	seen := make(map[string]bool)
+
+	for _, ref := range refs {
+		key := ref.registry() + "|" + ref.name + "|" + ref.version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		// Tags and refs are exact pins, so only policy limits how far they move
+		deps = append(deps, engine.Dependency{
+			Name:           ref.name,
+			CurrentVersion: ref.version,
+			Type:           ref.kind,
+			Registry:       ref.registry(),
+			Line:           ref.node.Line,
+		})
+	}
+
+	return deps
+}
+
+func countKind(deps []engine.Dependency, kinds ...string) int {
+	count := 0
+	for _, dep := range deps {
+		for _, kind := range kinds {
+			if dep.Type == kind {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// findReferences parses a pipeline and returns its image, service, and
+// include references in document order. Aliases are not followed, so a
+// reference inside an anchored block is reported once, where it is defined.
+func findReferences(content []byte) ([]reference, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	var refs []reference
+	collectReferences(&doc, &refs)
+	return refs, nil
+}
+
+func collectReferences(node *yaml.Node, refs *[]reference) {
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			collectReferences(child, refs)
+		}
+	case yaml.MappingNode:
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			key, value := node.Content[j], node.Content[j+1]
+			switch key.Value {
+			case "image":
+				addImage(value, depTypeImage, refs)
+			case "services":
+				if value.Kind == yaml.SequenceNode {
+					for _, service := range value.Content {
+						addImage(service, depTypeService, refs)
+					}
+				}
+			case "include":
+				addIncludes(value, refs)
+			default:
+				collectReferences(value, refs)
+			}
+		}
+	}
+}
+
+// addImage records an image given either as a scalar ("node:20") or as a
+// mapping with a name: key ({name: node:20, entrypoint: [""]}).
+func addImage(node *yaml.Node, kind string, refs *[]reference) {
+	if node.Kind == yaml.MappingNode {
+		node = mappingValue(node, "name")
+	}
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return
+	}
+
+	name, tag := parseImageReference(node.Value)
+	if name == "" {
+		return
+	}
+	*refs = append(*refs, reference{node: node, kind: kind, name: name, version: tag})
+}
+
+// addIncludes records include: entries that pin a ref of another project.
+// Local, remote, template, and component includes are ignored.
+func addIncludes(node *yaml.Node, refs *[]reference) {
+	entries := []*yaml.Node{node}
+	if node.Kind == yaml.SequenceNode {
+		entries = node.Content
+	}
+
+	for _, entry := range entries {
+		if entry.Kind != yaml.MappingNode {
+			continue
+		}
+		project, ref := mappingValue(entry, "project"), mappingValue(entry, "ref")
+		if project == nil || ref == nil || project.Kind != yaml.ScalarNode || ref.Kind != yaml.ScalarNode {
+			continue
+		}
+		if project.Value == "" || ref.Value == "" || strings.Contains(project.Value+ref.Value, "$") {
+			continue
+		}
+		*refs = append(*refs, reference{node: ref, kind: depTypeInclude, name: project.Value, version: ref.Value})
+	}
+}
+
+// mappingValue returns the value node of key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for j := 0; j+1 < len(node.Content); j += 2 {
+		if node.Content[j].Value == key {
+			return node.Content[j+1]
+		}
+	}
+	return nil
+}
+
+// parseImageReference splits an image reference into name and tag. The tag
+// separator is the last colon after the last slash, so registry ports
+// (registry.example.com:5000/app:1.2) are kept in the name. Images pinned by
+// digest, without a tag, or built from CI variables are not tracked.
+func parseImageReference(ref string) (name, tag string) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.Contains(ref, "$") || strings.Contains(ref, "@") {
+		return "", ""
+	}
+
+	idx := strings.LastIndex(ref, ":")
+	if idx < 0 || idx < strings.LastIndex(ref, "/") {
+		return "", ""
+	}
+	return ref[:idx], ref[idx+1:]
+}
+
+// Plan determines available updates for pipeline images and included projects.
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		var (
+			update *engine.Update
+			err    error
+		)
+		if dep.Type == depTypeInclude {
+			update, err = i.planInclude(ctx, dep, planCtx)
+		} else {
+			update, err = i.planImage(ctx, dep, planCtx)
+		}
+		if err != nil || update == nil {
+			continue
+		}
+		updates = append(updates, *update)
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "yaml_rewrite",
+	}, nil
+}
+
+// planImage selects the newest allowed tag of an image from its registry.
+func (i *Integration) planImage(ctx context.Context, dep engine.Dependency, planCtx *engine.PlanContext) (*engine.Update, error) {
+	if dep.CurrentVersion == defaultTag {
+		return nil, nil
+	}
+
+	availableVersions, err := i.images.GetVersions(ctx, dep.Name)
+	if err != nil || len(availableVersions) == 0 {
+		return nil, err
+	}
+
+	targetVersion, impact, err := resolve.SelectVersionWithContext(dep.CurrentVersion, "", availableVersions, planCtx)
+	if err != nil || targetVersion == "" || targetVersion == dep.CurrentVersion {
+		return nil, err
+	}
+
+	return &engine.Update{
+		Dependency:    dep,
+		TargetVersion: targetVersion,
+		Impact:        string(impact),
+		PolicySource:  planCtx.GetPolicySource(),
+	}, nil
+}
+
+// planInclude selects the newest allowed tag of an included project. Refs
+// that are not versions, such as branches and commit SHAs, are left alone.
+// The target keeps the "v" prefix style of the current ref.
+func (i *Integration) planInclude(ctx context.Context, dep engine.Dependency, planCtx *engine.PlanContext) (*engine.Update, error) {
+	current := version.Bare(dep.CurrentVersion)
+	if _, err := semver.StrictNewVersion(current); err != nil {
+		return nil, nil
+	}
+	if i.refs == nil {
+		return nil, nil
+	}
+
+	tags, err := i.refs.GetTags(ctx, dep.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.TrimSuffix(dep.CurrentVersion, current)
+	availableVersions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix) {
+			availableVersions = append(availableVersions, version.Bare(tag))
+		}
+	}
+	if len(availableVersions) == 0 {
+		return nil, nil
+	}
+
+	targetVersion, impact, err := resolve.SelectVersionWithContext(current, "", availableVersions, planCtx)
+	if err != nil || targetVersion == "" || targetVersion == current {
+		return nil, err
+	}
+
+	return &engine.Update{
+		Dependency:    dep,
+		TargetVersion: prefix + targetVersion,
+		Impact:        string(impact),
+		PolicySource:  planCtx.GetPolicySource(),
+	}, nil
+}
+
+// Apply rewrites image tags and include refs in the pipeline file. Only the
+// version text of each matching scalar changes, so anchors, aliases,
+// comments, and quoting are preserved.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read pipeline file: %w", err)
+	}
+
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+	newContent := string(rewritten.Content)
+	errs := rewritten.Errors
+
+	if newContent != string(oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, []byte(newContent), 0o600); err != nil {
+			return nil, fmt.Errorf("write pipeline file: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(plan.Manifest.Path, string(oldContent), newContent)
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to pipeline content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	refs, err := findReferences(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse pipeline file: %w", err)
+	}
+
+	// Rewrite from the end of the file so earlier positions stay valid
+	sort.SliceStable(refs, func(a, b int) bool {
+		if refs[a].node.Line != refs[b].node.Line {
+			return refs[a].node.Line > refs[b].node.Line
+		}
+		return refs[a].node.Column > refs[b].node.Column
+	})
+
+	lines := strings.Split(string(content), "\n")
+	var applied, errs []string
+
+	for j := range plan.Updates {
+		update := &plan.Updates[j]
+		dep := update.Dependency
+
+		rewritten := false
+		for k := range refs {
+			ref := &refs[k]
+			isInclude := dep.Type == depTypeInclude
+			if (ref.kind == depTypeInclude) != isInclude || ref.name != dep.Name || ref.version != dep.CurrentVersion {
+				continue
+			}
+			if replaceVersion(lines, ref, update.TargetVersion) {
+				rewritten = true
+			}
+		}
+
+		if !rewritten {
+			errs = append(errs, fmt.Sprintf("%s: %s not found in %s", dep.Name, dep.CurrentVersion, plan.Manifest.Path))
+			continue
+		}
+		applied = append(applied, dep.Name)
+	}
+
+	newContent := strings.Join(lines, "\n")
+
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// replaceVersion swaps the version in the source text of ref's scalar for
+// target. Images are matched as name:tag so a tag equal to part of the name
+// is not touched.
+func replaceVersion(lines []string, ref *reference, target string) bool {
+	if ref.node.Line < 1 || ref.node.Line > len(lines) {
+		return false
+	}
+	line := lines[ref.node.Line-1]
+	col := ref.node.Column - 1
+	if col < 0 || col > len(line) {
+		return false
+	}
+
+	oldText, newText := ref.version, target
+	if ref.kind != depTypeInclude {
+		oldText, newText = ref.name+":"+ref.version, ref.name+":"+target
+	}
+
+	idx := strings.Index(line[col:], oldText)
+	if idx < 0 {
+		return false
+	}
+	start := col + idx
+	lines[ref.node.Line-1] = line[:start] + newText + line[start+len(oldText):]
+	return true
+}
+
+// Validate checks that the pipeline file is a YAML mapping.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	var pipeline map[string]interface{}
+	if err := yaml.Unmarshal(manifest.Content, &pipeline); err != nil {
+		return fmt.Errorf("invalid pipeline YAML: %w", err)
+	}
+	if len(pipeline) == 0 {
+		return fmt.Errorf("pipeline file is empty")
+	}
+	return nil
+}
@@ -0,0 +1,467 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+//nolint:dupl,goconst,govet // Test files use similar table-driven patterns
+package gitlabci
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testPipeline = `# Shared defaults
+default:
+  image:
+    name: node:20.10.0  # build image
+    entrypoint: [""]
+
+.db: &db
+  services:
+    - postgres:15.4
+    - name: redis:7.0.0
+      alias: cache
+
+include:
+  - local: /ci/lint.yml
+  - project: platform/ci-templates
+    ref: v1.2.0
+    file: /templates/build.yml
+  - project: platform/security
+    ref: main
+    file: /sast.yml
+  - remote: https://example.com/ci.yml
+
+test:
+  <<: *db
+  image: "python:3.11.4"
+  script:
+    - pytest
+
+deploy:
+  image: registry.example.com:5000/tools/deployer:2.1.0
+  services:
+    - docker:24.0.5-dind
+  script:
+    - ./deploy.sh
+
+build:
+  image: $CI_REGISTRY_IMAGE/builder:latest
+  script:
+    - make
+`
+
+func TestNew(t *testing.T) {
+	integration := New()
+	if integration == nil {
+		t.Fatal("New() returned nil")
+	}
+	if integration.images == nil {
+		t.Error("New() created integration with nil datasource")
+	}
+	if integration.refs == nil {
+		t.Error("New() created integration with nil GitLab client")
+	}
+}
+
+func TestIntegration_Name(t *testing.T) {
+	integration := New()
+	if got := integration.Name(); got != "gitlabci" {
+		t.Errorf("Name() = %q, want %q", got, "gitlabci")
+	}
+}
+
+func TestParseImageReference(t *testing.T) {
+	tests := []struct {
+		ref      string
+		wantName string
+		wantTag  string
+	}{
+		{"node:20", "node", "20"},
+		{"library/python:3.11-slim", "library/python", "3.11-slim"},
+		{"registry.example.com:5000/app:1.2.3", "registry.example.com:5000/app", "1.2.3"},
+		{"registry.example.com:5000/app", "", ""},
+		{"alpine", "", ""},
+		{"alpine@sha256:abc123", "", ""},
+		{"$CI_REGISTRY_IMAGE:latest", "", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			name, tag := parseImageReference(tt.ref)
+			if name != tt.wantName || tag != tt.wantTag {
+				t.Errorf("parseImageReference(%q) = (%q, %q), want (%q, %q)", tt.ref, name, tag, tt.wantName, tt.wantTag)
+			}
+		})
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	refs, err := findReferences([]byte(testPipeline))
+	if err != nil {
+		t.Fatalf("findReferences() error = %v", err)
+	}
+
+	got := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		got = append(got, ref.kind+" "+ref.name+"@"+ref.version)
+	}
+	want := []string{
+		"image node@20.10.0",
+		"service postgres@15.4",
+		"service redis@7.0.0",
+		"include platform/ci-templates@v1.2.0",
+		"include platform/security@main",
+		"image python@3.11.4",
+		"image registry.example.com:5000/tools/deployer@2.1.0",
+		"service docker@24.0.5-dind",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("findReferences() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	t.Run("single include mapping", func(t *testing.T) {
+		refs, err := findReferences([]byte("include:\n  project: group/templates\n  ref: 2.0.0\n  file: /a.yml\n"))
+		if err != nil {
+			t.Fatalf("findReferences() error = %v", err)
+		}
+		if len(refs) != 1 || refs[0].name != "group/templates" || refs[0].version != "2.0.0" {
+			t.Errorf("findReferences() = %+v, want group/templates@2.0.0", refs)
+		}
+	})
+
+	t.Run("invalid YAML", func(t *testing.T) {
+		if _, err := findReferences([]byte("image: [unclosed")); err == nil {
+			t.Error("findReferences() expected error for invalid YAML")
+		}
+	})
+}
+
+func TestIntegration_Detect(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".gitlab-ci.yml":               testPipeline,
+		"services/api/.gitlab-ci.yaml": "test:\n  image: golang:1.21.5\n",
+		"services/web/.gitlab-ci.yml":  "test:\n  script:\n    - echo no images\n",
+		"ci/lint.yml":                  "lint:\n  image: node:18.0.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := New().Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 2 {
+		t.Fatalf("Detect() found %d manifests, want 2", len(manifests))
+	}
+
+	var root *engine.Manifest
+	for _, m := range manifests {
+		if m.Path == ".gitlab-ci.yml" {
+			root = m
+		}
+	}
+	if root == nil {
+		t.Fatal("Detect() did not find .gitlab-ci.yml")
+	}
+	if root.Type != "gitlabci" {
+		t.Errorf("manifest type = %q, want gitlabci", root.Type)
+	}
+	if len(root.Dependencies) != 8 {
+		t.Errorf("dependencies = %d, want 8", len(root.Dependencies))
+	}
+	if root.Metadata["image_count"] != 6 || root.Metadata["include_count"] != 2 {
+		t.Errorf("metadata = %v, want 6 images and 2 includes", root.Metadata)
+	}
+
+	for _, dep := range root.Dependencies {
+		switch dep.Name {
+		case "node":
+			if dep.Type != depTypeImage || dep.Registry != "docker-hub" || dep.Line != 4 {
+				t.Errorf("node dependency = %+v, want image from docker-hub on line 4", dep)
+			}
+		case "platform/ci-templates":
+			if dep.Type != depTypeInclude || dep.Registry != "gitlab" || dep.CurrentVersion != "v1.2.0" {
+				t.Errorf("include dependency = %+v, want platform/ci-templates@v1.2.0 from gitlab", dep)
+			}
+		}
+	}
+}
+
+func TestIntegration_Validate(t *testing.T) {
+	integration := New()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr bool
+	}{
+		{name: "valid pipeline", content: testPipeline},
+		{name: "invalid YAML", content: "image: [unclosed", wantErr: true},
+		{name: "empty file", content: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := integration.Validate(ctx, &engine.Manifest{Content: []byte(tt.content)})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIntegration_Plan(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("plans image and include updates", func(t *testing.T) {
+		integration := &Integration{
+			images: &mockDatasource{versions: []string{"20.11.1", "20.10.0", "21.0.0"}},
+			refs:   &mockTags{tags: []string{"v1.3.0", "v1.2.0", "1.9.0", "v2.0.0-rc.1"}},
+		}
+
+		manifest := &engine.Manifest{
+			Path: ".gitlab-ci.yml",
+			Type: "gitlabci",
+			Dependencies: []engine.Dependency{
+				{Name: "node", CurrentVersion: "20.10.0", Type: depTypeImage, Registry: "docker-hub"},
+				{Name: "platform/ci-templates", CurrentVersion: "v1.2.0", Type: depTypeInclude, Registry: "gitlab"},
+				{Name: "platform/security", CurrentVersion: "main", Type: depTypeInclude, Registry: "gitlab"},
+				{Name: "builder", CurrentVersion: "latest", Type: depTypeImage, Registry: "docker-hub"},
+			},
+		}
+
+		plan, err := integration.Plan(ctx, manifest, engine.NewPlanContext())
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+
+		targets := make(map[string]string)
+		for _, u := range plan.Updates {
+			targets[u.Dependency.Name] = u.TargetVersion
+		}
+		if len(targets) != 2 {
+			t.Errorf("Plan() updates = %v, want node and platform/ci-templates only", targets)
+		}
+		if targets["node"] != "21.0.0" {
+			t.Errorf("node target = %q, want 21.0.0", targets["node"])
+		}
+		// Tags without the current "v" prefix are ignored
+		if targets["platform/ci-templates"] != "v1.3.0" {
+			t.Errorf("include target = %q, want v1.3.0", targets["platform/ci-templates"])
+		}
+	})
+
+	t.Run("skips unresolvable dependencies", func(t *testing.T) {
+		integration := &Integration{
+			images: &mockDatasource{err: errors.New("registry unavailable")},
+			refs:   &mockTags{err: errors.New("not found")},
+		}
+
+		manifest := &engine.Manifest{
+			Dependencies: []engine.Dependency{
+				{Name: "node", CurrentVersion: "20.10.0", Type: depTypeImage},
+				{Name: "platform/ci-templates", CurrentVersion: "1.2.0", Type: depTypeInclude},
+			},
+		}
+
+		plan, err := integration.Plan(ctx, manifest, engine.NewPlanContext())
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 0 {
+			t.Errorf("Plan() returned %d updates, want 0", len(plan.Updates))
+		}
+	})
+}
+
+func TestIntegration_Apply(t *testing.T) {
+	integration := New()
+	ctx := context.Background()
+
+	t.Run("rewrites tags and refs preserving anchors and comments", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".gitlab-ci.yml")
+		if err := os.WriteFile(path, []byte(testPipeline), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: path},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "node", CurrentVersion: "20.10.0", Type: depTypeImage}, TargetVersion: "20.11.1"},
+				{Dependency: engine.Dependency{Name: "redis", CurrentVersion: "7.0.0", Type: depTypeService}, TargetVersion: "7.2.4"},
+				{Dependency: engine.Dependency{Name: "python", CurrentVersion: "3.11.4", Type: depTypeImage}, TargetVersion: "3.12.1"},
+				{Dependency: engine.Dependency{Name: "registry.example.com:5000/tools/deployer", CurrentVersion: "2.1.0", Type: depTypeImage}, TargetVersion: "2.2.0"},
+				{Dependency: engine.Dependency{Name: "platform/ci-templates", CurrentVersion: "v1.2.0", Type: depTypeInclude}, TargetVersion: "v1.3.0"},
+			},
+		}
+
+		result, err := integration.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 5 || result.Failed != 0 {
+			t.Errorf("Apply() applied = %d, failed = %d (%v), want 5 and 0", result.Applied, result.Failed, result.Errors)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := strings.NewReplacer(
+			"node:20.10.0  # build image", "node:20.11.1  # build image",
+			"redis:7.0.0", "redis:7.2.4",
+			`"python:3.11.4"`, `"python:3.12.1"`,
+			"deployer:2.1.0", "deployer:2.2.0",
+			"ref: v1.2.0", "ref: v1.3.0",
+		).Replace(testPipeline)
+		if string(content) != want {
+			t.Errorf("Apply() wrote:\n%s\nwant:\n%s", content, want)
+		}
+		if result.ManifestDiff == "" {
+			t.Error("Apply() returned empty diff")
+		}
+	})
+
+	t.Run("reports missing references", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), ".gitlab-ci.yml")
+		if err := os.WriteFile(path, []byte("test:\n  image: node:20.10.0\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: path},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "node", CurrentVersion: "18.0.0", Type: depTypeImage}, TargetVersion: "20.11.1"},
+			},
+		}
+
+		result, err := integration.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 0 || result.Failed != 1 {
+			t.Errorf("Apply() applied = %d, failed = %d, want 0 and 1", result.Applied, result.Failed)
+		}
+	})
+
+	t.Run("no updates", func(t *testing.T) {
+		result, err := integration.Apply(ctx, &engine.UpdatePlan{Manifest: &engine.Manifest{Path: "missing.yml"}})
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 0 {
+			t.Errorf("Apply() applied = %d, want 0", result.Applied)
+		}
+	})
+}
+
+func TestIntegration_DetectGitignored(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		".gitlab-ci.yml":              "test:\n  image: node:20.10.0\n",
+		"build/.gitlab-ci.yml":        "test:\n  image: node:18.0.0\n",
+		".gitignore":                  "build/\n",
+		"sandbox/.gitlab-ci.yml":      "test:\n  image: golang:1.21.5\n",
+		"node_modules/.gitlab-ci.yml": "test:\n  image: node:16.0.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := engine.WithExcludePaths(context.Background(), []string{"sandbox"})
+	manifests, err := New().Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != ".gitlab-ci.yml" {
+		paths := make([]string, 0, len(manifests))
+		for _, m := range manifests {
+			paths = append(paths, m.Path)
+		}
+		t.Errorf("Detect() paths = %v, want only .gitlab-ci.yml", paths)
+	}
+}
+
+// mockDatasource is a test double for datasource.Datasource
+type mockDatasource struct {
+	versions []string
+	err      error
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	if len(m.versions) > 0 {
+		return m.versions[0], nil
+	}
+	return "", nil
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.versions, nil
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &datasource.PackageInfo{Name: pkg}, nil
+}
+
+// mockTags is a test double for the GitLab tags API
+type mockTags struct {
+	err  error
+	tags []string
+}
+
+func (m *mockTags) GetTags(ctx context.Context, project string) ([]string, error) {
+	return m.tags, m.err
+}
@@ -0,0 +1,569 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package gitsubmodule implements the git submodule integration.
+// It reads .gitmodules, resolves the latest commit on the branch each
+// submodule tracks, and advances the submodule's gitlink when asked to fix
+// the lockfile.
+package gitsubmodule
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/rewrite"
+)
+
+func init() {
+	integrations.Register("gitsubmodule", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "gitsubmodule"
+	manifestName    = ".gitmodules"
+
+	// gitlinkMode is the index mode git records submodule commits with.
+	gitlinkMode = "160000"
+)
+
+// commitResolver resolves a ref of a repository to the commit SHA it points
+// at. "HEAD" resolves the default branch.
+type commitResolver interface {
+	GetCommitSHA(ctx context.Context, repo, ref string) (string, error)
+}
+
+// runner executes a command in dir and returns its combined output.
+type runner func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+
+// Integration implements git submodule updates.
+type Integration struct {
+	github commitResolver
+	gitlab func(host string) commitResolver
+	run    runner
+}
+
+// New creates a new git submodule integration.
+func New() *Integration {
+	ds, err := datasource.Get("github-releases")
+	if err != nil {
+		ds = datasource.NewGitHubDatasource()
+	}
+	github, _ := ds.(commitResolver)
+	return &Integration{
+		github: github,
+		gitlab: func(host string) commitResolver {
+			return registry.NewGitLabClient(host, os.Getenv("GITLAB_TOKEN"))
+		},
+		run: runCommand,
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of submodule configuration.
+func (i *Integration) SupportedFiles() []string {
+	return []string{manifestName}
+}
+
+// Submodule is one [submodule "name"] section of .gitmodules.
+type Submodule struct {
+	Name   string
+	Path   string
+	URL    string
+	Branch string
+	// Line is the 1-based line of the section header.
+	Line int
+}
+
+// parseGitmodules decodes .gitmodules content. Sections other than
+// submodules and keys uptool does not use are ignored.
+func parseGitmodules(content []byte) ([]Submodule, error) {
+	var (
+		submodules []Submodule
+		current    *Submodule
+		lineNo     int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("parse %s: line %d: unterminated section header", manifestName, lineNo)
+			}
+			current = nil
+			section, name, ok := strings.Cut(strings.TrimSpace(line[1:len(line)-1]), " ")
+			if !ok || !strings.EqualFold(section, "submodule") {
+				continue
+			}
+			name = strings.Trim(strings.TrimSpace(name), `"`)
+			if name == "" {
+				return nil, fmt.Errorf("parse %s: line %d: submodule without a name", manifestName, lineNo)
+			}
+			submodules = append(submodules, Submodule{Name: name, Line: lineNo})
+			current = &submodules[len(submodules)-1]
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "path":
+			current.Path = value
+		case "url":
+			current.URL = value
+		case "branch":
+			current.Branch = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", manifestName, err)
+	}
+
+	for _, sub := range submodules {
+		if sub.Path == "" || sub.URL == "" {
+			return nil, fmt.Errorf("parse %s: submodule %q needs both path and url", manifestName, sub.Name)
+		}
+	}
+
+	return submodules, nil
+}
+
+// find returns the submodule named name.
+func find(submodules []Submodule, name string) (Submodule, bool) {
+	for _, sub := range submodules {
+		if sub.Name == name {
+			return sub, true
+		}
+	}
+	return Submodule{}, false
+}
+
+// remote describes where a submodule's repository is hosted.
+type remote struct {
+	// kind is "github", "gitlab", or "git" for any other host.
+	kind string
+	host string
+	// repo is "owner/repo" for GitHub and GitLab, and the clone URL otherwise.
+	repo string
+}
+
+// parseRemote classifies a submodule URL given in SCP (git@host:owner/repo.git)
+// or URL (https://host/owner/repo.git) form. Relative URLs, which resolve
+// against the superproject's own remote, and local paths return false.
+func parseRemote(rawURL string) (remote, bool) {
+	var host, path string
+	switch {
+	case strings.HasPrefix(rawURL, "git@"):
+		host, path, _ = strings.Cut(strings.TrimPrefix(rawURL, "git@"), ":")
+	case strings.Contains(rawURL, "://"):
+		u, err := url.Parse(rawURL)
+		if err != nil || u.Scheme == "file" {
+			return remote{}, false
+		}
+		host, path = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+	default:
+		return remote{}, false
+	}
+
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	if host == "" || !strings.Contains(path, "/") {
+		return remote{}, false
+	}
+
+	switch {
+	case host == "github.com":
+		return remote{kind: "github", host: host, repo: path}, true
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return remote{kind: "gitlab", host: host, repo: path}, true
+	default:
+		return remote{kind: "git", host: host, repo: rawURL}, true
+	}
+}
+
+// Detect finds .gitmodules at the repository root and reads the commit each
+// submodule is pinned to from the git index.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	path := filepath.Join(repoRoot, manifestName)
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(path); err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", manifestName, err)
+	}
+
+	submodules, err := parseGitmodules(content)
+	if err != nil {
+		return nil, err
+	}
+
+	// Submodules without a gitlink (not yet added, or git unavailable) are
+	// listed without a current commit and never planned
+	gitlinks, _ := i.gitlinks(ctx, repoRoot, submodules) //nolint:errcheck // missing gitlinks are reported as unpinned
+
+	deps := make([]engine.Dependency, 0, len(submodules))
+	for _, sub := range submodules {
+		dep := engine.Dependency{
+			Name:           sub.Name,
+			CurrentVersion: gitlinks[sub.Path],
+			Constraint:     sub.Branch,
+			Type:           "direct",
+			Line:           sub.Line,
+		}
+		if r, ok := parseRemote(sub.URL); ok {
+			dep.Registry = r.kind
+		}
+		deps = append(deps, dep)
+	}
+
+	return []*engine.Manifest{{
+		Path:         manifestName,
+		Type:         integrationName,
+		Dependencies: deps,
+		Content:      content,
+		Metadata: map[string]any{
+			"submodules": len(submodules),
+		},
+	}}, nil
+}
+
+// gitlinks returns the commit recorded in the index for each submodule path.
+func (i *Integration) gitlinks(ctx context.Context, repoRoot string, submodules []Submodule) (map[string]string, error) {
+	links := make(map[string]string, len(submodules))
+	if len(submodules) == 0 || i.run == nil {
+		return links, nil
+	}
+
+	args := []string{"ls-files", "--stage", "--"}
+	for _, sub := range submodules {
+		args = append(args, sub.Path)
+	}
+	output, err := i.run(ctx, repoRoot, "git", args...)
+	if err != nil {
+		return links, fmt.Errorf("git ls-files: %w", err)
+	}
+
+	// <mode> SP <object> SP <stage> TAB <path>
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		info, path, ok := strings.Cut(scanner.Text(), "\t")
+		fields := strings.Fields(info)
+		if !ok || len(fields) != 3 || fields[0] != gitlinkMode {
+			continue
+		}
+		links[path] = fields[1]
+	}
+	return links, scanner.Err()
+}
+
+// Plan resolves the latest commit on the branch each submodule tracks (the
+// default branch when it tracks none) and proposes a gitlink bump when it
+// moved. "branch = ." follows the superproject's branch in git; it is
+// resolved as the submodule's default branch here.
+//
+// A commit bump carries no semantic version, so it is reported as a minor
+// update: patch-only policies leave submodules alone, and update: none
+// disables them.
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	plan := &engine.UpdatePlan{
+		Manifest: manifest,
+		Strategy: "gitlink_update",
+	}
+
+	if level := planCtx.EffectiveUpdateLevel(); level == "none" || level == string(engine.ImpactPatch) {
+		return plan, nil
+	}
+
+	submodules, err := parseGitmodules(manifest.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dep := range manifest.Dependencies {
+		if dep.CurrentVersion == "" {
+			continue
+		}
+		sub, ok := find(submodules, dep.Name)
+		if !ok {
+			continue
+		}
+		r, ok := parseRemote(sub.URL)
+		if !ok {
+			continue
+		}
+		resolver := i.resolver(r)
+		if resolver == nil {
+			continue
+		}
+
+		ref := dep.Constraint
+		if ref == "" || ref == "." {
+			ref = "HEAD"
+		}
+
+		sha, err := resolver.GetCommitSHA(ctx, r.repo, ref)
+		if err != nil || sha == "" || strings.EqualFold(sha, dep.CurrentVersion) {
+			continue
+		}
+
+		plan.Updates = append(plan.Updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: sha,
+			Impact:        string(engine.ImpactMinor),
+			ChangelogURL:  compareURL(r, dep.CurrentVersion, sha),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return plan, nil
+}
+
+// resolver returns the commit resolver for a submodule's host.
+func (i *Integration) resolver(r remote) commitResolver {
+	switch r.kind {
+	case "github":
+		return i.github
+	case "gitlab":
+		if i.gitlab == nil {
+			return nil
+		}
+		return i.gitlab(r.host)
+	default:
+		if i.run == nil {
+			return nil
+		}
+		return lsRemote{run: i.run}
+	}
+}
+
+// compareURL links the commits between two revs of a GitHub or GitLab
+// submodule.
+func compareURL(r remote, from, to string) string {
+	switch r.kind {
+	case "github":
+		return fmt.Sprintf("https://github.com/%s/compare/%s...%s", r.repo, from, to)
+	case "gitlab":
+		return fmt.Sprintf("https://%s/%s/-/compare/%s...%s", r.host, r.repo, from, to)
+	default:
+		return ""
+	}
+}
+
+// lsRemote resolves refs of any git repository with git ls-remote.
+type lsRemote struct {
+	run runner
+}
+
+// GetCommitSHA returns the commit ref points at in the repository at cloneURL.
+func (l lsRemote) GetCommitSHA(ctx context.Context, cloneURL, ref string) (string, error) {
+	// A URL starting with "-" would be read as an option
+	if strings.HasPrefix(cloneURL, "-") {
+		return "", fmt.Errorf("invalid repository URL: %s", cloneURL)
+	}
+	if ref != "HEAD" {
+		ref = "refs/heads/" + ref
+	}
+
+	output, err := l.run(ctx, "", "git", "ls-remote", cloneURL, ref)
+	if err != nil {
+		return "", fmt.Errorf("git ls-remote %s: %w: %s", cloneURL, err, strings.TrimSpace(string(output)))
+	}
+
+	sha, _, _ := strings.Cut(string(output), "\t")
+	return strings.TrimSpace(sha), nil
+}
+
+// HasLockfile reports that the gitlink is the submodule's lock, so submodules
+// are updated in lockfile-only runs too.
+func (i *Integration) HasLockfile(manifest *engine.Manifest) bool {
+	return true
+}
+
+// Apply advances the gitlink of each updated submodule. The gitlink lives in
+// the git index rather than a file, so it is only written with FixLockfile
+// set: an initialized submodule is fetched and checked out at the new commit,
+// then the commit is staged with 'git update-index'. Without FixLockfile each
+// update is reported as failed with a hint.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 || !plan.WritesLockfile() {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	if !plan.FixLockfile {
+		errs := make([]string, 0, len(plan.Updates))
+		for _, update := range plan.Updates {
+			errs = append(errs, fmt.Sprintf("%s: updating the submodule gitlink requires --fix-lockfile", update.Dependency.Name))
+		}
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Failed:   len(errs),
+			Errors:   errs,
+		}, nil
+	}
+
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	content, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", manifestName, err)
+	}
+
+	submodules, err := parseGitmodules(content)
+	if err != nil {
+		return nil, err
+	}
+
+	root := filepath.Dir(plan.Manifest.Path)
+	gitlinks, err := i.gitlinks(ctx, root, submodules)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		applied []string
+		errs    []string
+		diff    strings.Builder
+	)
+	for _, update := range plan.Updates {
+		name := update.Dependency.Name
+		sub, ok := find(submodules, name)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: submodule not found in %s", name, manifestName))
+			continue
+		}
+		if gitlinks[sub.Path] != update.Dependency.CurrentVersion {
+			errs = append(errs, fmt.Sprintf("%s: gitlink is no longer %s", name, update.Dependency.CurrentVersion))
+			continue
+		}
+
+		if err := i.advance(ctx, root, sub, update.TargetVersion); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		applied = append(applied, name)
+
+		// Shown the way 'git diff' shows a submodule change
+		d, err := rewrite.GenerateUnifiedDiff(sub.Path,
+			"Subproject commit "+update.Dependency.CurrentVersion+"\n",
+			"Subproject commit "+update.TargetVersion+"\n")
+		if err != nil {
+			return nil, fmt.Errorf("generate diff: %w", err)
+		}
+		diff.WriteString(d)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   diff.String(),
+	}, nil
+}
+
+// advance checks out sha in the submodule's working tree when it is
+// initialized and records sha as the submodule's gitlink in the index.
+func (i *Integration) advance(ctx context.Context, root string, sub Submodule, sha string) error {
+	dir := filepath.Join(root, sub.Path)
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		if output, err := i.run(ctx, dir, "git", "fetch", "--quiet", "origin"); err != nil {
+			return fmt.Errorf("git fetch failed: %w\n%s", err, output)
+		}
+		if output, err := i.run(ctx, dir, "git", "checkout", "--quiet", "--detach", sha); err != nil {
+			return fmt.Errorf("git checkout failed: %w\n%s", err, output)
+		}
+	}
+
+	cacheinfo := gitlinkMode + "," + sha + "," + sub.Path
+	if output, err := i.run(ctx, root, "git", "update-index", "--cacheinfo", cacheinfo); err != nil {
+		return fmt.Errorf("git update-index failed: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// runCommand runs a command in dir and returns its combined output.
+func runCommand(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s command not found", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 - arguments are paths, URLs, and commits from .gitmodules and the index, not a shell
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// Validate checks that .gitmodules is well-formed.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	content := manifest.Content
+	if len(content) == 0 {
+		if err := integrations.ValidateFilePath(manifest.Path); err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		data, err := os.ReadFile(manifest.Path) // #nosec G304 - path is validated above
+		if err != nil {
+			return fmt.Errorf("read %s: %w", manifestName, err)
+		}
+		content = data
+	}
+
+	_, err := parseGitmodules(content)
+	return err
+}
@@ -0,0 +1,345 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gitsubmodule
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const (
+	oldRev  = "a3a3dda3bacf61e8a39258a0ed9c924eeca8e293"
+	newRev  = "5e4fbfb6b3de1aa2872b76d49fafc942626e2add"
+	libRev  = "11707dc2f618dd54ca8739b309ec4fc024de578b"
+	docsRev = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c"
+)
+
+const testGitmodules = `[core]
+	bare = false
+[submodule "vendor/json"]
+	path = vendor/json
+	url = https://github.com/nlohmann/json.git
+	branch = develop
+[submodule "lib"]
+	path = third_party/lib
+	url = git@gitlab.com:group/sub/lib.git
+# internal tooling
+[submodule "tools"]
+	path = tools
+	url = ../tools.git
+[submodule "docs"]
+	path = docs
+	url = https://git.example.com/team/docs.git
+`
+
+// lsFiles is the 'git ls-files --stage' output for testGitmodules.
+const lsFiles = gitlinkMode + " " + libRev + " 0\tthird_party/lib\n" +
+	gitlinkMode + " " + docsRev + " 0\tdocs\n" +
+	gitlinkMode + " " + oldRev + " 0\tvendor/json\n"
+
+// mockCommits is a test double for commitResolver keyed by "repo@ref".
+type mockCommits map[string]string
+
+func (m mockCommits) GetCommitSHA(ctx context.Context, repo, ref string) (string, error) {
+	if sha, ok := m[repo+"@"+ref]; ok {
+		return sha, nil
+	}
+	return "", context.Canceled
+}
+
+// fakeGit answers 'git ls-files' and 'git ls-remote' and records every
+// command it runs.
+type fakeGit struct {
+	calls  [][]string
+	remote map[string]string
+}
+
+func (f *fakeGit) run(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	f.calls = append(f.calls, append([]string{name}, args...))
+	switch args[0] {
+	case "ls-files":
+		return []byte(lsFiles), nil
+	case "ls-remote":
+		if sha, ok := f.remote[args[1]+"@"+args[2]]; ok {
+			return []byte(sha + "\t" + args[2] + "\n"), nil
+		}
+		return nil, context.Canceled
+	default:
+		return nil, nil
+	}
+}
+
+func writeRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestName), []byte(testGitmodules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestParseGitmodules(t *testing.T) {
+	submodules, err := parseGitmodules([]byte(testGitmodules))
+	if err != nil {
+		t.Fatalf("parseGitmodules() error = %v", err)
+	}
+
+	want := []Submodule{
+		{Name: "vendor/json", Path: "vendor/json", URL: "https://github.com/nlohmann/json.git", Branch: "develop", Line: 3},
+		{Name: "lib", Path: "third_party/lib", URL: "git@gitlab.com:group/sub/lib.git", Line: 7},
+		{Name: "tools", Path: "tools", URL: "../tools.git", Line: 11},
+		{Name: "docs", Path: "docs", URL: "https://git.example.com/team/docs.git", Line: 14},
+	}
+	if !reflect.DeepEqual(submodules, want) {
+		t.Errorf("parseGitmodules() = %+v, want %+v", submodules, want)
+	}
+
+	if _, err := parseGitmodules([]byte("[submodule \"x\"]\n\tpath = x\n")); err == nil {
+		t.Error("parseGitmodules() without url should return error")
+	}
+	if _, err := parseGitmodules([]byte("[submodule \"x\"\n")); err == nil {
+		t.Error("parseGitmodules() with unterminated header should return error")
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		url  string
+		want remote
+		ok   bool
+	}{
+		{"https://github.com/nlohmann/json.git", remote{kind: "github", host: "github.com", repo: "nlohmann/json"}, true},
+		{"git@github.com:nlohmann/json", remote{kind: "github", host: "github.com", repo: "nlohmann/json"}, true},
+		{"ssh://git@gitlab.com/group/sub/lib.git", remote{kind: "gitlab", host: "gitlab.com", repo: "group/sub/lib"}, true},
+		{"https://git.example.com/team/docs.git", remote{kind: "git", host: "git.example.com", repo: "https://git.example.com/team/docs.git"}, true},
+		{"../tools.git", remote{}, false},
+		{"file:///srv/git/repo.git", remote{}, false},
+		{"https://github.com/nlohmann", remote{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRemote(tt.url)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseRemote(%q) = %+v, %v; want %+v, %v", tt.url, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeRepo(t)
+	git := &fakeGit{}
+	integ := &Integration{run: git.run}
+
+	manifests, err := integ.Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+
+	m := manifests[0]
+	if m.Path != manifestName || m.Type != integrationName {
+		t.Errorf("manifest = %s (%s), want %s (%s)", m.Path, m.Type, manifestName, integrationName)
+	}
+
+	// tools has no gitlink in the index and a relative URL
+	want := []engine.Dependency{
+		{Name: "vendor/json", CurrentVersion: oldRev, Constraint: "develop", Type: "direct", Registry: "github", Line: 3},
+		{Name: "lib", CurrentVersion: libRev, Type: "direct", Registry: "gitlab", Line: 7},
+		{Name: "tools", Type: "direct", Line: 11},
+		{Name: "docs", CurrentVersion: docsRev, Type: "direct", Registry: "git", Line: 14},
+	}
+	if !reflect.DeepEqual(m.Dependencies, want) {
+		t.Errorf("Dependencies = %+v, want %+v", m.Dependencies, want)
+	}
+
+	wantCall := []string{"git", "ls-files", "--stage", "--", "vendor/json", "third_party/lib", "tools", "docs"}
+	if len(git.calls) != 1 || !reflect.DeepEqual(git.calls[0], wantCall) {
+		t.Errorf("commands = %v, want [%v]", git.calls, wantCall)
+	}
+
+	manifests, err = integ.Detect(context.Background(), t.TempDir())
+	if err != nil || len(manifests) != 0 {
+		t.Errorf("Detect() without .gitmodules = %v, %v; want none", manifests, err)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := writeRepo(t)
+	git := &fakeGit{remote: map[string]string{
+		"https://git.example.com/team/docs.git@HEAD": docsRev,
+	}}
+	integ := &Integration{
+		github: mockCommits{"nlohmann/json@develop": newRev},
+		gitlab: func(host string) commitResolver {
+			if host != "gitlab.com" {
+				t.Errorf("gitlab host = %q, want gitlab.com", host)
+			}
+			// lib tracks the default branch and has not moved
+			return mockCommits{"group/sub/lib@HEAD": libRev}
+		},
+		run: git.run,
+	}
+
+	manifests, err := integ.Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 1 {
+		t.Fatalf("Plan() returned %d updates, want 1: %+v", len(plan.Updates), plan.Updates)
+	}
+
+	update := plan.Updates[0]
+	if update.Dependency.Name != "vendor/json" || update.TargetVersion != newRev {
+		t.Errorf("update = %s -> %s, want vendor/json -> %s", update.Dependency.Name, update.TargetVersion, newRev)
+	}
+	if update.Impact != string(engine.ImpactMinor) {
+		t.Errorf("Impact = %q, want minor", update.Impact)
+	}
+	if want := "https://github.com/nlohmann/json/compare/" + oldRev + "..." + newRev; update.ChangelogURL != want {
+		t.Errorf("ChangelogURL = %q, want %q", update.ChangelogURL, want)
+	}
+
+	wantRemote := []string{"git", "ls-remote", "https://git.example.com/team/docs.git", "HEAD"}
+	if !reflect.DeepEqual(git.calls[len(git.calls)-1], wantRemote) {
+		t.Errorf("last command = %v, want %v", git.calls[len(git.calls)-1], wantRemote)
+	}
+
+	patchOnly := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "patch"}}
+	plan, err = integ.Plan(context.Background(), manifests[0], patchOnly)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 0 {
+		t.Errorf("Plan() with update: patch returned %d updates, want 0", len(plan.Updates))
+	}
+}
+
+func jsonUpdate() engine.Update {
+	return engine.Update{
+		Dependency: engine.Dependency{
+			Name:           "vendor/json",
+			CurrentVersion: oldRev,
+			Constraint:     "develop",
+			Type:           "direct",
+			Registry:       "github",
+		},
+		TargetVersion: newRev,
+	}
+}
+
+func TestApply_RequiresFixLockfile(t *testing.T) {
+	dir := writeRepo(t)
+	git := &fakeGit{}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: filepath.Join(dir, manifestName), Type: integrationName},
+		Updates:  []engine.Update{jsonUpdate()},
+	}
+
+	result, err := (&Integration{run: git.run}).Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("Apply() applied %d, failed %d; want 0, 1", result.Applied, result.Failed)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "--fix-lockfile") {
+		t.Errorf("Errors = %v, want a --fix-lockfile hint", result.Errors)
+	}
+	if len(git.calls) != 0 {
+		t.Errorf("commands = %v, want none", git.calls)
+	}
+}
+
+func TestApply_FixLockfile(t *testing.T) {
+	dir := writeRepo(t)
+	// vendor/json is initialized, so its checkout is moved as well
+	if err := os.MkdirAll(filepath.Join(dir, "vendor", "json"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "vendor", "json", ".git"), []byte("gitdir: ../../.git/modules/vendor/json\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	git := &fakeGit{}
+	plan := &engine.UpdatePlan{
+		Manifest:    &engine.Manifest{Path: filepath.Join(dir, manifestName), Type: integrationName},
+		Updates:     []engine.Update{jsonUpdate()},
+		FixLockfile: true,
+	}
+
+	result, err := (&Integration{run: git.run}).Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || result.Failed != 0 {
+		t.Errorf("Apply() applied %d, failed %d (%v); want 1, 0", result.Applied, result.Failed, result.Errors)
+	}
+
+	want := [][]string{
+		{"git", "ls-files", "--stage", "--", "vendor/json", "third_party/lib", "tools", "docs"},
+		{"git", "fetch", "--quiet", "origin"},
+		{"git", "checkout", "--quiet", "--detach", newRev},
+		{"git", "update-index", "--cacheinfo", gitlinkMode + "," + newRev + ",vendor/json"},
+	}
+	if !reflect.DeepEqual(git.calls, want) {
+		t.Errorf("commands = %v, want %v", git.calls, want)
+	}
+	if !strings.Contains(result.ManifestDiff, "+Subproject commit "+newRev) {
+		t.Errorf("ManifestDiff missing gitlink bump:\n%s", result.ManifestDiff)
+	}
+
+	// A gitlink that has already moved is not touched
+	stale := jsonUpdate()
+	stale.Dependency.CurrentVersion = newRev
+	plan.Updates = []engine.Update{stale}
+	result, err = (&Integration{run: (&fakeGit{}).run}).Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("stale Apply() applied %d, failed %d; want 0, 1", result.Applied, result.Failed)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+
+	if err := integ.Validate(context.Background(), &engine.Manifest{Content: []byte(testGitmodules)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := integ.Validate(context.Background(), &engine.Manifest{Content: []byte("[submodule \"x\"]\n\turl = https://github.com/o/r\n")}); err == nil {
+		t.Error("Validate() without path should return error")
+	}
+}
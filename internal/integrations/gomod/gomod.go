@@ -21,6 +21,7 @@
 // Package gomod implements the Go modules integration for updating go.mod dependencies.
 // It detects go.mod files, queries the Go module proxy for version updates,
 // and rewrites dependency versions while preserving the go.mod format.
+// The go.sum next to go.mod gets the hashes of the new versions.
 package gomod
 
 import (
@@ -35,7 +36,9 @@ import (
 	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
 	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/version"
 )
 
 func init() {
@@ -44,9 +47,23 @@ func init() {
 	})
 }
 
+// sumSource computes the go.sum hashes of a module version. It is
+// implemented by the Go datasource and used to keep go.sum in step with go.mod.
+type sumSource interface {
+	GetModuleSums(ctx context.Context, module, version string) (*registry.GoModuleSums, error)
+}
+
+// retractionSource looks up the versions a module's author retracted. It is
+// implemented by the Go datasource and used to keep Plan from proposing them.
+type retractionSource interface {
+	GetRetractions(ctx context.Context, module string) ([]registry.GoRetraction, error)
+}
+
 // Integration implements Go modules go.mod updates.
 type Integration struct {
-	ds datasource.Datasource
+	ds          datasource.Datasource
+	sums        sumSource
+	retractions retractionSource
 }
 
 // New creates a new gomod integration.
@@ -56,8 +73,12 @@ func New() *Integration {
 		// Fallback to creating a new instance if not registered
 		ds = datasource.NewGoDatasource()
 	}
+	sums, _ := ds.(sumSource)               //nolint:errcheck // optional capability
+	retractions, _ := ds.(retractionSource) //nolint:errcheck // optional capability
 	return &Integration{
-		ds: ds,
+		ds:          datasource.Cached(ds),
+		sums:        sums,
+		retractions: retractions,
 	}
 }
 
@@ -66,6 +87,11 @@ func (i *Integration) Name() string {
 	return "gomod"
 }
 
+// SupportedFiles returns the file patterns of Go module files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"go.mod"}
+}
+
 // Regex patterns for parsing go.mod files.
 var (
 	modulePattern  = regexp.MustCompile(`^module\s+(.+)$`)
@@ -75,30 +101,30 @@ var (
 )
 
 // Detect finds go.mod files in the repository.
+// Modules listed in the go.work file (or the file named by GOWORK) are tagged
+// with a "workspace" metadata entry so their plans can be reconciled.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
 
-	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+	workspace, members, err := loadWorkspace(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err = filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip vendor directories
-		if info.IsDir() && info.Name() == "vendor" {
-			return filepath.SkipDir
-		}
-
-		// Skip hidden directories
-		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && info.Name() != "." {
-			return filepath.SkipDir
-		}
-
-		// Skip testdata directories
-		if info.IsDir() && info.Name() == "testdata" {
-			return filepath.SkipDir
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
-		if info.Name() == "go.mod" {
+		if info.Name() == "go.mod" && !filter.ShouldSkipFile(path) {
 			relPath, err := filepath.Rel(repoRoot, path)
 			if err != nil {
 				return err
@@ -116,6 +142,12 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 			}
 
 			deps, metadata := i.parseGoMod(content)
+			if members[filepath.Dir(relPath)] {
+				metadata["workspace"] = workspace
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), sumFileName)); err == nil {
+				metadata["lockfile"] = filepath.Join(filepath.Dir(relPath), sumFileName)
+			}
 
 			manifest := &engine.Manifest{
 				Path:         relPath,
@@ -240,28 +272,10 @@ func (i *Integration) parseDependencyLine(line string) *engine.Dependency {
 func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
 	updates := make([]engine.Update, 0, len(manifest.Dependencies))
 
-	// Get replacements from manifest metadata
-	replacements := make(map[string]bool)
-	if manifest.Metadata != nil {
-		if repl, ok := manifest.Metadata["replacements"].(map[string]bool); ok {
-			replacements = repl
-		}
-	}
+	replacements := manifestReplacements(manifest)
 
 	for _, dep := range manifest.Dependencies {
-		// Skip indirect dependencies by default (they're managed by go mod tidy)
-		if dep.Type == "indirect" {
-			continue
-		}
-
-		// Skip replaced modules
-		if replacements[dep.Name] {
-			continue
-		}
-
-		// Skip local paths and git references
-		if strings.HasPrefix(dep.CurrentVersion, "v0.0.0-") {
-			// This is likely a pseudo-version from a git commit
+		if skipDependency(&dep, replacements) {
 			continue
 		}
 
@@ -276,6 +290,7 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 			}
 			availableVersions = []string{latest}
 		}
+		availableVersions = i.eligibleVersions(ctx, &dep, availableVersions, planCtx.EffectiveAllowDeprecated())
 
 		// Use policy-aware version selection
 		targetVersion, impact, err := resolve.SelectVersionWithContext(
@@ -304,7 +319,60 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 	}, nil
 }
 
-// Apply executes the update plan by rewriting go.mod.
+// eligibleVersions drops the versions the go command would not select for
+// an upgrade: retracted versions (unless allowRetracted, from the
+// allow_deprecated policy) and, unless the dependency is already on one,
+// "+incompatible" versions of modules without a major version suffix.
+// Retractions that cannot be looked up are not filtered.
+func (i *Integration) eligibleVersions(ctx context.Context, dep *engine.Dependency, versions []string, allowRetracted bool) []string {
+	var retractions []registry.GoRetraction
+	if i.retractions != nil && !allowRetracted {
+		retractions, _ = i.retractions.GetRetractions(ctx, dep.Name) //nolint:errcheck // best effort
+	}
+	allowIncompatible := strings.HasSuffix(dep.CurrentVersion, "+incompatible")
+
+	eligible := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if strings.HasSuffix(v, "+incompatible") && !allowIncompatible {
+			continue
+		}
+		if registry.Retracted(v, retractions) {
+			continue
+		}
+		eligible = append(eligible, v)
+	}
+	return eligible
+}
+
+// manifestReplacements returns the replaced modules recorded in manifest metadata.
+func manifestReplacements(manifest *engine.Manifest) map[string]bool {
+	if manifest.Metadata != nil {
+		if repl, ok := manifest.Metadata["replacements"].(map[string]bool); ok {
+			return repl
+		}
+	}
+	return make(map[string]bool)
+}
+
+// skipDependency reports whether a dependency should never be proposed for update.
+func skipDependency(dep *engine.Dependency, replacements map[string]bool) bool {
+	// Skip indirect dependencies by default (they're managed by go mod tidy)
+	if dep.Type == "indirect" {
+		return true
+	}
+
+	// Skip replaced modules
+	if replacements[dep.Name] {
+		return true
+	}
+
+	// Skip local paths and git references
+	// This is likely a pseudo-version from a git commit
+	return strings.HasPrefix(dep.CurrentVersion, "v0.0.0-")
+}
+
+// Apply executes the update plan by rewriting go.mod and, when present, the
+// go.sum lines of updated modules. With no-lockfile go.sum is left untouched.
 func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
 	if len(plan.Updates) == 0 {
 		return &engine.ApplyResult{
@@ -327,15 +395,76 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("read go.mod: %w", err)
 	}
 
+	rewritten, appliedUpdates, err := i.rewrite(plan, content)
+	if err != nil {
+		return nil, err
+	}
 	oldContent := string(content)
-	newContent := oldContent
-	applied := 0
+	newContent := string(rewritten.Content)
+	applied := rewritten.Applied
+	errs := rewritten.Errors
+
+	if applied == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   len(plan.Updates),
+			Errors:   errs,
+		}, nil
+	}
+
+	// Write back to go.mod
+	if err := os.WriteFile(fullPath, []byte(newContent), 0o600); err != nil {
+		return nil, fmt.Errorf("write go.mod: %w", err)
+	}
+
+	// Generate diff
+	diff := generateDiff(oldContent, newContent)
+
+	result := &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        applied,
+		Failed:         len(plan.Updates) - applied,
+		Errors:         errs,
+		ManifestDiff:   diff,
+	}
+
+	if sumPath, ok := plan.Manifest.Metadata["lockfile"].(string); ok && plan.WritesLockfile() {
+		sumDiff, sumErrs, err := i.updateGoSum(ctx, sumPath, appliedUpdates)
+		if err != nil {
+			return nil, err
+		}
+		result.LockfileDiff = sumDiff
+		result.Errors = append(result.Errors, sumErrs...)
+	}
+
+	return result, nil
+}
+
+// Rewrite applies the plan's updates to go.mod content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	rewritten, _, err := i.rewrite(plan, content)
+	return rewritten, err
+}
+
+// rewrite implements Rewrite and also returns the updates that apply.
+func (i *Integration) rewrite(plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, []*engine.Update, error) {
+	newContent := string(content)
+	var appliedUpdates []*engine.Update
+	var applied, errs []string
 
 	// Apply updates by replacing version strings
 	for idx := range plan.Updates {
 		update := &plan.Updates[idx]
 		oldVersion := update.Dependency.CurrentVersion
-		newVersion := update.TargetVersion
+		newVersion := version.Normalize(i.Name(), update.TargetVersion)
+
+		// Never write a version the go command would reject
+		if err := resolve.ValidateConstraint(i.Name(), newVersion); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
 
 		// Build the pattern to find and replace
 		// Match: "module/path vX.Y.Z" in require statements
@@ -349,32 +478,18 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 
 		if re.MatchString(newContent) {
 			newContent = re.ReplaceAllString(newContent, newReplacement)
-			applied++
+			applied = append(applied, update.Dependency.Name)
+			appliedUpdates = append(appliedUpdates, update)
 		}
 	}
 
-	if applied == 0 {
-		return &engine.ApplyResult{
-			Manifest: plan.Manifest,
-			Applied:  0,
-			Failed:   len(plan.Updates),
-		}, nil
-	}
-
-	// Write back to go.mod
-	if err := os.WriteFile(fullPath, []byte(newContent), 0o600); err != nil {
-		return nil, fmt.Errorf("write go.mod: %w", err)
-	}
-
-	// Generate diff
-	diff := generateDiff(oldContent, newContent)
-
-	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       len(plan.Updates) - applied,
-		ManifestDiff: diff,
-	}, nil
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(plan.Updates) - len(applied),
+		Errors:         errs,
+	}, appliedUpdates, nil
 }
 
 // Validate checks if go.mod is valid.
@@ -28,7 +28,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
 )
 
 const (
@@ -651,6 +653,46 @@ require github.com/pkg/errors v0.9.1
 			t.Errorf("Apply() failed = %d, want 1", result.Failed)
 		}
 	})
+
+	t.Run("reports which updates were applied", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		goModPath := filepath.Join(tmpDir, goModFilename)
+
+		content := `module example.com/test
+
+go 1.21
+
+require github.com/pkg/errors v0.9.0
+`
+		if err := os.WriteFile(goModPath, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		plan := &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: goModPath},
+			Updates: []engine.Update{
+				{
+					Dependency:    engine.Dependency{Name: "github.com/pkg/errors", CurrentVersion: "v0.9.0"},
+					TargetVersion: "v0.9.1",
+				},
+				{
+					Dependency:    engine.Dependency{Name: "github.com/nonexistent/pkg", CurrentVersion: "v1.0.0"},
+					TargetVersion: "v2.0.0",
+				},
+			},
+		}
+
+		result, err := integ.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 1 || result.Failed != 1 {
+			t.Errorf("Apply() = %d applied, %d failed; want 1 and 1", result.Applied, result.Failed)
+		}
+		if len(result.AppliedUpdates) != 1 || result.AppliedUpdates[0] != "github.com/pkg/errors" {
+			t.Errorf("AppliedUpdates = %v, want [github.com/pkg/errors]", result.AppliedUpdates)
+		}
+	})
 }
 
 func TestValidate(t *testing.T) {
@@ -798,3 +840,231 @@ func TestModulePatterns(t *testing.T) {
 		}
 	})
 }
+
+func TestApply_InvalidVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	goModPath := filepath.Join(tmpDir, goModFilename)
+
+	original := "module example.com/test\n\ngo 1.21\n\nrequire github.com/pkg/errors v0.9.1\n"
+	if err := os.WriteFile(goModPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: goModPath},
+		Updates: []engine.Update{
+			{
+				Dependency: engine.Dependency{
+					Name:           "github.com/pkg/errors",
+					CurrentVersion: "v0.9.1",
+				},
+				// Not a canonical semantic version, even once prefixed
+				TargetVersion: "0.9",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("Apply() applied/failed = %d/%d, want 0/1", result.Applied, result.Failed)
+	}
+
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != original {
+		t.Errorf("Apply() wrote go.mod despite invalid version:\n%s", content)
+	}
+}
+
+func TestApply_BareVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	goModPath := filepath.Join(tmpDir, goModFilename)
+
+	original := "module example.com/test\n\ngo 1.21\n\nrequire github.com/pkg/errors v0.9.1\n"
+	if err := os.WriteFile(goModPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Versions from sources that drop the prefix are written back in Go's form
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: goModPath},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "github.com/pkg/errors", CurrentVersion: "v0.9.1"},
+				TargetVersion: "0.9.2",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 {
+		t.Errorf("Apply() applied = %d, want 1: %v", result.Applied, result.Errors)
+	}
+
+	content, err := os.ReadFile(goModPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.Replace(original, "v0.9.1", "v0.9.2", 1); string(content) != want {
+		t.Errorf("go.mod =\n%s\nwant\n%s", content, want)
+	}
+}
+
+func TestDetect_Gitignored(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, dir := range []string{".", "generated/api", "tools"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, goModFilename), []byte(sampleGoMod), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("generated/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := engine.WithExcludePaths(context.Background(), []string{"/tools"})
+	manifests, err := New().Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != goModFilename {
+		paths := make([]string, 0, len(manifests))
+		for _, m := range manifests {
+			paths = append(paths, m.Path)
+		}
+		t.Errorf("Detect() paths = %v, want only %q (gitignored and excluded dirs skipped)", paths, goModFilename)
+	}
+}
+
+// mockProxy implements datasource.Datasource and retractionSource for testing.
+type mockProxy struct {
+	versions    map[string][]string
+	retractions map[string][]registry.GoRetraction
+}
+
+func (m *mockProxy) Name() string { return "mock" }
+
+func (m *mockProxy) GetLatestVersion(context.Context, string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockProxy) GetVersions(_ context.Context, module string) ([]string, error) {
+	if versions, ok := m.versions[module]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockProxy) GetPackageInfo(context.Context, string) (*datasource.PackageInfo, error) {
+	return nil, context.Canceled
+}
+
+func (m *mockProxy) GetRetractions(_ context.Context, module string) ([]registry.GoRetraction, error) {
+	return m.retractions[module], nil
+}
+
+func TestPlan_EligibleVersions(t *testing.T) {
+	proxy := &mockProxy{
+		versions: map[string][]string{
+			"gopkg.in/yaml.v3":          {"v3.0.0", "v3.0.1"},
+			"example.com/retracted":     {"v1.0.0", "v1.1.0", "v1.2.0", "v1.2.1", "v1.3.0"},
+			"github.com/docker/docker":  {"v24.0.0+incompatible", "v25.0.0+incompatible"},
+			"github.com/old/incompat":   {"v1.4.0", "v2.0.0+incompatible"},
+			"example.com/all-retracted": {"v1.0.0", "v1.1.0"},
+		},
+		retractions: map[string][]registry.GoRetraction{
+			"example.com/retracted":     {{Low: "v1.3.0", High: "v1.3.0"}, {Low: "v1.2.0", High: "v1.2.1", Rationale: "broken"}},
+			"example.com/all-retracted": {{Low: "v1.1.0", High: "v1.1.0"}},
+		},
+	}
+	integ := &Integration{ds: proxy, retractions: proxy}
+
+	dep := func(name, current string) engine.Dependency {
+		return engine.Dependency{Name: name, CurrentVersion: current, Constraint: current, Type: depTypeDirect, Registry: "go"}
+	}
+	manifest := &engine.Manifest{
+		Path: goModFilename,
+		Type: integrationName,
+		Dependencies: []engine.Dependency{
+			dep("gopkg.in/yaml.v3", "v3.0.0"),
+			dep("example.com/retracted", "v1.0.0"),
+			dep("github.com/docker/docker", "v24.0.0+incompatible"),
+			dep("github.com/old/incompat", "v1.3.0"),
+			dep("example.com/all-retracted", "v1.0.0"),
+		},
+	}
+
+	plan, err := integ.Plan(context.Background(), manifest, &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "major"}})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	want := map[string]string{
+		"gopkg.in/yaml.v3":         "v3.0.1",
+		"example.com/retracted":    "v1.1.0",
+		"github.com/docker/docker": "v25.0.0+incompatible",
+		"github.com/old/incompat":  "v1.4.0",
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("%s target = %q, want %q", name, got[name], target)
+		}
+	}
+	if target, ok := got["example.com/all-retracted"]; ok {
+		t.Errorf("example.com/all-retracted target = %q, want no update to a retracted version", target)
+	}
+}
+
+func TestPlan_AllowDeprecated(t *testing.T) {
+	proxy := &mockProxy{
+		versions: map[string][]string{
+			"example.com/retracted": {"v1.0.0", "v1.1.0", "v1.2.0"},
+		},
+		retractions: map[string][]registry.GoRetraction{
+			"example.com/retracted": {{Low: "v1.2.0", High: "v1.2.0"}},
+		},
+	}
+	integ := &Integration{ds: proxy, retractions: proxy}
+	manifest := &engine.Manifest{
+		Path: goModFilename,
+		Type: integrationName,
+		Dependencies: []engine.Dependency{
+			{Name: "example.com/retracted", CurrentVersion: "v1.0.0", Constraint: "v1.0.0", Type: depTypeDirect, Registry: "go"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		want            string
+		allowDeprecated bool
+	}{
+		{name: "retracted newest skipped", want: "v1.1.0"},
+		{name: "allow_deprecated proposes retracted", allowDeprecated: true, want: "v1.2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "major", AllowDeprecated: tt.allowDeprecated}}
+			plan, err := integ.Plan(context.Background(), manifest, planCtx)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != tt.want {
+				t.Errorf("Plan() updates = %+v, want target %s", plan.Updates, tt.want)
+			}
+		})
+	}
+}
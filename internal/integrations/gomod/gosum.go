@@ -0,0 +1,158 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gomod
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+const sumFileName = "go.sum"
+
+// updateGoSum adds the go.sum lines of the applied updates' new versions,
+// the "h1:" hash of the module zip and of its go.mod, and removes the old
+// version's zip hash. The old version's go.mod hash is kept, since other
+// modules in the build graph may still require that version; go mod tidy
+// drops it once it is unused. Modules whose hashes cannot be computed are
+// left as they are and reported so the user knows to run `go mod tidy`.
+func (i *Integration) updateGoSum(ctx context.Context, sumPath string, updates []*engine.Update) (string, []string, error) {
+	if err := integrations.ValidateFilePath(sumPath); err != nil {
+		return "", nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(sumPath) // #nosec G304 - path is validated above
+	if err != nil {
+		return "", nil, fmt.Errorf("read go.sum: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(oldContent), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		lines = nil
+	}
+	var errs []string
+
+	for _, update := range updates {
+		module := update.Dependency.Name
+		target := version.Normalize(i.Name(), update.TargetVersion)
+
+		sums, err := i.moduleSums(ctx, module, target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: go.sum not updated (%v); run go mod tidy", module, err))
+			continue
+		}
+
+		if old := update.Dependency.CurrentVersion; old != sums.Version {
+			lines = removeSumLine(lines, module+" "+old+" ")
+		}
+		lines = insertSumLine(lines, module+" "+sums.Version+" "+sums.Zip)
+		lines = insertSumLine(lines, module+" "+sums.Version+"/go.mod "+sums.GoMod)
+	}
+
+	newContent := strings.Join(lines, "\n") + "\n"
+	if newContent == string(oldContent) {
+		return "", errs, nil
+	}
+
+	if err := os.WriteFile(sumPath, []byte(newContent), 0o600); err != nil {
+		return "", nil, fmt.Errorf("write go.sum: %w", err)
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(sumFileName, string(oldContent), newContent)
+	if err != nil {
+		return "", nil, fmt.Errorf("generate diff: %w", err)
+	}
+	return diff, errs, nil
+}
+
+// moduleSums fetches the go.sum hashes of a module version from the proxy.
+func (i *Integration) moduleSums(ctx context.Context, module, ver string) (*registry.GoModuleSums, error) {
+	if i.sums == nil {
+		return nil, fmt.Errorf("go module proxy unavailable")
+	}
+	return i.sums.GetModuleSums(ctx, module, ver)
+}
+
+// removeSumLine drops the lines starting with prefix ("<module> <version> ").
+func removeSumLine(lines []string, prefix string) []string {
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(line, prefix) {
+			kept = append(kept, line)
+		}
+	}
+	return kept
+}
+
+// insertSumLine adds a go.sum line in the order the go command writes them,
+// replacing a line for the same module version and file. Existing lines keep
+// their order.
+func insertSumLine(lines []string, line string) []string {
+	key := sumLineKey(line)
+	for j, existing := range lines {
+		if sumLineKey(existing) == key {
+			lines[j] = line
+			return lines
+		}
+	}
+	for j, existing := range lines {
+		if sumLineLess(line, existing) {
+			return append(lines[:j], append([]string{line}, lines[j:]...)...)
+		}
+	}
+	return append(lines, line)
+}
+
+// sumLineKey returns the "<module> <version>[/go.mod]" part of a go.sum line.
+func sumLineKey(line string) string {
+	module, rest, _ := strings.Cut(line, " ")
+	ver, _, _ := strings.Cut(rest, " ")
+	return module + " " + ver
+}
+
+// sumLineLess orders go.sum lines by module path, then by semantic version,
+// with a version's zip hash before its go.mod hash.
+func sumLineLess(a, b string) bool {
+	moduleA, verA, _ := strings.Cut(sumLineKey(a), " ")
+	moduleB, verB, _ := strings.Cut(sumLineKey(b), " ")
+	if moduleA != moduleB {
+		return moduleA < moduleB
+	}
+
+	baseA, goModA := strings.CutSuffix(verA, "/go.mod")
+	baseB, goModB := strings.CutSuffix(verB, "/go.mod")
+	if c := semver.Compare(baseA, baseB); c != 0 {
+		return c < 0
+	}
+	if baseA != baseB {
+		return baseA < baseB
+	}
+	return !goModA && goModB
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gomod
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+// mockSums serves go.sum hashes keyed by "module@version".
+type mockSums map[string]*registry.GoModuleSums
+
+func (m mockSums) GetModuleSums(_ context.Context, module, version string) (*registry.GoModuleSums, error) {
+	if sums, ok := m[module+"@"+version]; ok {
+		return sums, nil
+	}
+	return nil, errors.New("not found")
+}
+
+const goSumFixture = `github.com/davecgh/go-spew v1.1.1 h1:vj9j/u1bqnvCEfJOwUhtlOARqs3+rkHYY13jYWTU97c=
+github.com/davecgh/go-spew v1.1.1/go.mod h1:J7Y8YcW2NihsgmVo/mv3lAwl/skON4iLHjSsI+c5H38=
+github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=
+github.com/pkg/errors v0.9.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=
+github.com/sirupsen/logrus v1.9.0 h1:trlNQbNUG3OdDrDil03MCb1H2o9nJ1x4/5LYw7byDE0=
+github.com/sirupsen/logrus v1.9.0/go.mod h1:naHLuLoDiP4jHNo9R0sCBMtWGeIprob74mVsIT4qYEQ=
+golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 h1:0A+M6Uqn+Eje4kHMK80dtF3JCXC4ykBgQG4Fj4qI6lw=
+`
+
+func goSumTestDir(t *testing.T) (goModPath, goSumPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	goModPath = filepath.Join(dir, goModFilename)
+	goSumPath = filepath.Join(dir, sumFileName)
+	goMod := "module example.com/test\n\ngo 1.21\n\nrequire (\n\tgithub.com/pkg/errors v0.9.1\n\tgithub.com/sirupsen/logrus v1.9.0\n)\n"
+	if err := os.WriteFile(goModPath, []byte(goMod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(goSumPath, []byte(goSumFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return goModPath, goSumPath
+}
+
+func goSumPlan(goModPath, goSumPath string, mode engine.LockfileMode, updates ...engine.Update) *engine.UpdatePlan {
+	return &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:     goModPath,
+			Metadata: map[string]interface{}{"lockfile": goSumPath},
+		},
+		Updates:  updates,
+		Lockfile: mode,
+	}
+}
+
+var logrusUpdate = engine.Update{
+	Dependency:    engine.Dependency{Name: "github.com/sirupsen/logrus", CurrentVersion: "v1.9.0"},
+	TargetVersion: "v1.9.3",
+}
+
+func TestApply_GoSum(t *testing.T) {
+	goModPath, goSumPath := goSumTestDir(t)
+	integ := &Integration{sums: mockSums{
+		"github.com/sirupsen/logrus@v1.9.3": {
+			Version: "v1.9.3",
+			Zip:     "h1:dueUQJ1C2q9oE3F7wvmSGAaVtTmUizReu6fjN8uqzbQ=",
+			GoMod:   "h1:EOAn2pEsUyEpoF5/l+pz9/u8qQnAs6uuVJV4LS8uOSNo=",
+		},
+	}}
+
+	result, err := integ.Apply(context.Background(), goSumPlan(goModPath, goSumPath, engine.LockfileDefault, logrusUpdate))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 0 {
+		t.Errorf("Apply() applied = %d, errors = %v; want 1 and none", result.Applied, result.Errors)
+	}
+
+	want := `github.com/davecgh/go-spew v1.1.1 h1:vj9j/u1bqnvCEfJOwUhtlOARqs3+rkHYY13jYWTU97c=
+github.com/davecgh/go-spew v1.1.1/go.mod h1:J7Y8YcW2NihsgmVo/mv3lAwl/skON4iLHjSsI+c5H38=
+github.com/pkg/errors v0.9.1 h1:FEBLx1zS214owpjy7qsBeixbURkuhQAwrK5UwLGTwt4=
+github.com/pkg/errors v0.9.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=
+github.com/sirupsen/logrus v1.9.0/go.mod h1:naHLuLoDiP4jHNo9R0sCBMtWGeIprob74mVsIT4qYEQ=
+github.com/sirupsen/logrus v1.9.3 h1:dueUQJ1C2q9oE3F7wvmSGAaVtTmUizReu6fjN8uqzbQ=
+github.com/sirupsen/logrus v1.9.3/go.mod h1:EOAn2pEsUyEpoF5/l+pz9/u8qQnAs6uuVJV4LS8uOSNo=
+golang.org/x/sys v0.0.0-20220715151400-c0bba94af5f8 h1:0A+M6Uqn+Eje4kHMK80dtF3JCXC4ykBgQG4Fj4qI6lw=
+`
+	got, err := os.ReadFile(goSumPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("go.sum =\n%s\nwant\n%s", got, want)
+	}
+
+	if !strings.Contains(result.LockfileDiff, "-github.com/sirupsen/logrus v1.9.0 h1:") ||
+		!strings.Contains(result.LockfileDiff, "+github.com/sirupsen/logrus v1.9.3 h1:dueUQJ1C2q9oE3F7wvmSGAaVtTmUizReu6fjN8uqzbQ=") {
+		t.Errorf("Apply() LockfileDiff =\n%s", result.LockfileDiff)
+	}
+}
+
+func TestApply_GoSumUnavailable(t *testing.T) {
+	goModPath, goSumPath := goSumTestDir(t)
+	integ := &Integration{sums: mockSums{}}
+
+	result, err := integ.Apply(context.Background(), goSumPlan(goModPath, goSumPath, engine.LockfileDefault, logrusUpdate))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "go mod tidy") {
+		t.Errorf("Apply() applied = %d, errors = %v; want go.mod updated and a go.sum note", result.Applied, result.Errors)
+	}
+	if got, _ := os.ReadFile(goSumPath); string(got) != goSumFixture {
+		t.Errorf("go.sum changed without hashes:\n%s", got)
+	}
+	if result.LockfileDiff != "" {
+		t.Errorf("Apply() LockfileDiff = %q, want none", result.LockfileDiff)
+	}
+}
+
+func TestApply_GoSumNoLockfile(t *testing.T) {
+	goModPath, goSumPath := goSumTestDir(t)
+	integ := &Integration{sums: mockSums{
+		"github.com/sirupsen/logrus@v1.9.3": {Version: "v1.9.3", Zip: "h1:zip=", GoMod: "h1:mod="},
+	}}
+
+	result, err := integ.Apply(context.Background(), goSumPlan(goModPath, goSumPath, engine.LockfileSkip, logrusUpdate))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || result.LockfileDiff != "" {
+		t.Errorf("Apply() applied = %d, LockfileDiff = %q; want go.mod only", result.Applied, result.LockfileDiff)
+	}
+	if got, _ := os.ReadFile(goSumPath); string(got) != goSumFixture {
+		t.Errorf("go.sum changed with no-lockfile:\n%s", got)
+	}
+}
+
+func TestSumLineLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"a.com/x v1.0.0 h1:a", "b.com/x v0.1.0 h1:b", true},
+		{"a.com/x v1.10.0 h1:a", "a.com/x v1.9.0 h1:b", false},
+		{"a.com/x v1.9.0 h1:a", "a.com/x v1.10.0 h1:b", true},
+		{"a.com/x v1.9.0 h1:a", "a.com/x v1.9.0/go.mod h1:b", true},
+		{"a.com/x v1.9.0/go.mod h1:a", "a.com/x v1.9.0 h1:b", false},
+		{"a.com/x v1.0.0-rc.1 h1:a", "a.com/x v1.0.0 h1:b", true},
+	}
+
+	for _, tt := range tests {
+		if got := sumLineLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("sumLineLess(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestDetect_GoSum(t *testing.T) {
+	goModPath, _ := goSumTestDir(t)
+	dir := filepath.Dir(goModPath)
+	if err := os.MkdirAll(filepath.Join(dir, "tools"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "tools", goModFilename), []byte("module example.com/tools\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	for _, m := range manifests {
+		want := ""
+		if m.Path == goModFilename {
+			want = sumFileName
+		}
+		if got, _ := m.Metadata["lockfile"].(string); got != want {
+			t.Errorf("Detect() %s lockfile = %q, want %q", m.Path, got, want)
+		}
+	}
+}
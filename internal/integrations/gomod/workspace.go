@@ -0,0 +1,154 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gomod
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+)
+
+const workspaceFile = "go.work"
+
+// findWorkspaceFile locates the go.work file for a repository.
+// It honors GOWORK the same way the go command does: "off" disables workspace
+// mode, a path selects a specific file, and an empty value falls back to
+// go.work in the repository root.
+func findWorkspaceFile(repoRoot string) string {
+	gowork := os.Getenv("GOWORK")
+	switch gowork {
+	case "off":
+		return ""
+	case "":
+		path := filepath.Join(repoRoot, workspaceFile)
+		if _, err := os.Stat(path); err != nil {
+			return ""
+		}
+		return path
+	default:
+		if !filepath.IsAbs(gowork) {
+			gowork = filepath.Join(repoRoot, gowork)
+		}
+		return gowork
+	}
+}
+
+// loadWorkspace reads the workspace file for a repository and returns its path
+// relative to repoRoot along with the set of member module directories (also
+// relative to repoRoot). Both are empty when no workspace applies.
+func loadWorkspace(repoRoot string) (string, map[string]bool, error) {
+	members := make(map[string]bool)
+
+	path := findWorkspaceFile(repoRoot)
+	if path == "" {
+		return "", members, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(path); err != nil {
+		return "", nil, err
+	}
+
+	content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+	if err != nil {
+		return "", nil, fmt.Errorf("read %s: %w", workspaceFile, err)
+	}
+
+	relPath, err := filepath.Rel(repoRoot, path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	workDir := filepath.Dir(path)
+	for _, use := range parseWorkspaceUses(content) {
+		dir := filepath.Join(workDir, use)
+		relDir, err := filepath.Rel(repoRoot, dir)
+		if err != nil {
+			continue
+		}
+		members[relDir] = true
+	}
+
+	return relPath, members, nil
+}
+
+// parseWorkspaceUses extracts the module directories from go.work use directives.
+// Both the single-line form (use ./a) and the block form (use ( ... )) are supported.
+func parseWorkspaceUses(content []byte) []string {
+	var uses []string
+
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	inUseBlock := false
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if idx := strings.Index(line, "//"); idx >= 0 {
+			line = line[:idx]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "use (":
+			inUseBlock = true
+		case inUseBlock && line == ")":
+			inUseBlock = false
+		case inUseBlock && line != "":
+			uses = append(uses, strings.Trim(line, `"`))
+		case strings.HasPrefix(line, "use "):
+			uses = append(uses, strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "use ")), `"`))
+		}
+	}
+
+	return uses
+}
+
+// ReconcilePlans aligns target versions across members of the same Go workspace.
+// When several workspace modules require the same dependency, every member is
+// proposed the highest target version planned for any of them that its
+// policy allows, so the workspace builds against a single version of each
+// shared module.
+func (i *Integration) ReconcilePlans(plans []*engine.UpdatePlan, planCtx *engine.PlanContext) []*engine.UpdatePlan {
+	workspaces := make(map[string][]*engine.UpdatePlan)
+	for _, p := range plans {
+		if p.Manifest == nil || p.Manifest.Metadata == nil {
+			continue
+		}
+		if ws, ok := p.Manifest.Metadata["workspace"].(string); ok && ws != "" {
+			workspaces[ws] = append(workspaces[ws], p)
+		}
+	}
+
+	for _, members := range workspaces {
+		if len(members) > 1 {
+			resolve.AlignTargets(members, planCtx, "", func(m *engine.Manifest, dep *engine.Dependency) bool {
+				return skipDependency(dep, manifestReplacements(m))
+			})
+		}
+	}
+
+	return plans
+}
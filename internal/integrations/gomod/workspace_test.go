@@ -0,0 +1,295 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gomod
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// mockDatasource is a test double for datasource.Datasource
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	versions := m.versions[pkg]
+	if len(versions) == 0 {
+		return "", nil
+	}
+	return versions[len(versions)-1], nil
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	return m.versions[pkg], nil
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return &datasource.PackageInfo{Name: pkg}, nil
+}
+
+const workspaceMemberA = `module example.com/a
+
+go 1.21
+
+require github.com/shared/lib v1.1.0
+`
+
+const workspaceMemberB = `module example.com/b
+
+go 1.21
+
+require github.com/shared/lib v1.2.0
+`
+
+// setupWorkspace creates a repository with a go.work file and two member modules.
+func setupWorkspace(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+
+	files := map[string]string{
+		"go.work":  "go 1.21\n\nuse (\n\t./a\n\t./b // second module\n)\n",
+		"a/go.mod": workspaceMemberA,
+		"b/go.mod": workspaceMemberB,
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tmpDir
+}
+
+func TestParseWorkspaceUses(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "single-line use",
+			content: "go 1.21\n\nuse ./tools\n",
+			want:    []string{"./tools"},
+		},
+		{
+			name:    "use block with comments",
+			content: "go 1.21\n\nuse (\n\t./a // first\n\t\"./b\"\n)\n",
+			want:    []string{"./a", "./b"},
+		},
+		{
+			name:    "no use directives",
+			content: "go 1.21\n",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseWorkspaceUses([]byte(tt.content))
+			if len(got) != len(tt.want) {
+				t.Fatalf("parseWorkspaceUses() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("parseWorkspaceUses()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDetect_Workspace(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("tags workspace members", func(t *testing.T) {
+		t.Setenv("GOWORK", "")
+		tmpDir := setupWorkspace(t)
+
+		manifests, err := New().Detect(ctx, tmpDir)
+		if err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+		if len(manifests) != 2 {
+			t.Fatalf("Detect() found %d manifests, want 2", len(manifests))
+		}
+		for _, m := range manifests {
+			if ws, _ := m.Metadata["workspace"].(string); ws != "go.work" {
+				t.Errorf("%s workspace = %q, want %q", m.Path, ws, "go.work")
+			}
+		}
+	})
+
+	t.Run("GOWORK=off disables workspace mode", func(t *testing.T) {
+		t.Setenv("GOWORK", "off")
+		tmpDir := setupWorkspace(t)
+
+		manifests, err := New().Detect(ctx, tmpDir)
+		if err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+		for _, m := range manifests {
+			if _, ok := m.Metadata["workspace"]; ok {
+				t.Errorf("%s should not be tagged as a workspace member", m.Path)
+			}
+		}
+	})
+
+	t.Run("GOWORK selects a custom file", func(t *testing.T) {
+		tmpDir := setupWorkspace(t)
+		custom := filepath.Join(tmpDir, "dev.work")
+		if err := os.WriteFile(custom, []byte("go 1.21\n\nuse ./a\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("GOWORK", custom)
+
+		manifests, err := New().Detect(ctx, tmpDir)
+		if err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+		for _, m := range manifests {
+			ws, _ := m.Metadata["workspace"].(string)
+			if m.Path == filepath.Join("a", goModFilename) && ws != "dev.work" {
+				t.Errorf("a/go.mod workspace = %q, want %q", ws, "dev.work")
+			}
+			if m.Path == filepath.Join("b", goModFilename) && ws != "" {
+				t.Errorf("b/go.mod should not be a workspace member, got %q", ws)
+			}
+		}
+	})
+}
+
+func TestReconcilePlans_AlignsWorkspaceMembers(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	ctx := context.Background()
+	tmpDir := setupWorkspace(t)
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"github.com/shared/lib": {"v1.1.0", "v1.1.2", "v1.2.0", "v1.2.3"},
+	}}}
+
+	manifests, err := integ.Detect(ctx, tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	tests := []struct {
+		want        map[string]string
+		name        string
+		updateLevel string
+	}{
+		{
+			name:        "minor aligns both members",
+			updateLevel: "minor",
+			want:        map[string]string{"a/go.mod": "v1.2.3", "b/go.mod": "v1.2.3"},
+		},
+		{
+			// Raising a/go.mod from v1.1.0 to b's v1.2.3 would be a minor update
+			name:        "patch keeps each member within its update level",
+			updateLevel: "patch",
+			want:        map[string]string{"a/go.mod": "v1.1.2", "b/go.mod": "v1.2.3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planCtx := engine.NewPlanContext().WithCLIFlags(&engine.CLIFlags{UpdateLevel: tt.updateLevel})
+
+			plans := make([]*engine.UpdatePlan, 0, len(manifests))
+			for _, m := range manifests {
+				plan, err := integ.Plan(ctx, m, planCtx)
+				if err != nil {
+					t.Fatalf("Plan() error = %v", err)
+				}
+				plans = append(plans, plan)
+			}
+
+			plans = integ.ReconcilePlans(plans, planCtx)
+
+			for _, p := range plans {
+				if len(p.Updates) != 1 {
+					t.Fatalf("%s: got %d updates, want 1", p.Manifest.Path, len(p.Updates))
+				}
+				want := tt.want[filepath.ToSlash(p.Manifest.Path)]
+				if got := p.Updates[0].TargetVersion; got != want {
+					t.Errorf("%s: target = %q, want %q", p.Manifest.Path, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcilePlans_AddsMissingMemberUpdate(t *testing.T) {
+	dep := engine.Dependency{Name: "github.com/shared/lib", Type: depTypeDirect, Registry: "go"}
+
+	depA := dep
+	depA.CurrentVersion = "v1.2.0"
+	depB := dep
+	depB.CurrentVersion = "v1.0.0"
+
+	planA := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:         "a/go.mod",
+			Dependencies: []engine.Dependency{depA},
+			Metadata:     map[string]interface{}{"workspace": "go.work"},
+		},
+		Updates: []engine.Update{{Dependency: depA, TargetVersion: "v1.3.0", Impact: "minor"}},
+	}
+	planB := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:         "b/go.mod",
+			Dependencies: []engine.Dependency{depB},
+			Metadata:     map[string]interface{}{"workspace": "go.work"},
+		},
+	}
+	standalone := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:         "c/go.mod",
+			Dependencies: []engine.Dependency{depB},
+			Metadata:     map[string]interface{}{},
+		},
+	}
+
+	New().ReconcilePlans([]*engine.UpdatePlan{planA, planB, standalone}, nil)
+
+	if len(planB.Updates) != 1 || planB.Updates[0].TargetVersion != "v1.3.0" {
+		t.Fatalf("workspace member updates = %+v, want single update to v1.3.0", planB.Updates)
+	}
+	if planB.Updates[0].Impact != "minor" {
+		t.Errorf("impact = %q, want %q", planB.Updates[0].Impact, "minor")
+	}
+	if len(standalone.Updates) != 0 {
+		t.Errorf("non-workspace module should not be changed, got %+v", standalone.Updates)
+	}
+}
@@ -0,0 +1,741 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package gradle implements the Gradle integration for version catalogs.
+// It detects gradle/*.versions.toml files, reads the [versions], [libraries]
+// and [plugins] tables, queries Maven Central for newer releases, and rewrites
+// versions in place so TOML comments and table ordering are preserved. A
+// version shared through version.ref is planned and updated once, in
+// [versions], for every library and plugin that references it. When a BOM
+// (a library the project's build scripts import with platform() or
+// enforcedPlatform()) shares the version, the update follows the BOM's
+// releases, since the libraries it manages are released with it.
+package gradle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+)
+
+func init() {
+	integrations.Register("gradle", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "gradle"
+	catalogDir      = "gradle"
+	catalogSuffix   = ".versions.toml"
+)
+
+// Dependency types reported by Detect.
+const (
+	depTypeLibrary = "library"
+	depTypePlugin  = "plugin"
+	depTypeBOM     = "bom"
+)
+
+// refPrefix marks the constraint of a dependency whose version is defined in
+// [versions], e.g. "versions.kotlin".
+const refPrefix = "versions."
+
+var (
+	// headerPattern matches a TOML table header such as [libraries] or
+	// [libraries.guava], capturing the table name.
+	headerPattern = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+
+	// keyPattern matches the key of a key/value line, quoted or bare.
+	keyPattern = regexp.MustCompile(`^\s*["']?([A-Za-z0-9_-]+)["']?\s*[.=]`)
+
+	// versionPattern matches a plain version; rich versions and ranges do not match.
+	versionPattern = regexp.MustCompile(`^[0-9][0-9A-Za-z._-]*$`)
+
+	// platformPattern matches a catalog library imported as a BOM in a build
+	// script, such as platform(libs.okhttp.bom) or enforcedPlatform(libs.spring.boot.bom),
+	// capturing the catalog accessor and the library's accessor path.
+	platformPattern = regexp.MustCompile(`\b(?:enforcedPlatform|platform)\s*\(\s*([A-Za-z_][A-Za-z0-9_]*)\.([A-Za-z0-9_.]+?)(?:\.get\(\))?\s*\)`)
+
+	// aliasSeparators are the characters Gradle turns into "." in catalog accessors.
+	aliasSeparators = strings.NewReplacer("-", ".", "_", ".")
+)
+
+// entry is a library or plugin declared in a catalog.
+type entry struct {
+	alias      string
+	table      string
+	coordinate string // group:artifact for a library, the id for a plugin
+	version    string // inline version, when ref is empty
+	ref        string // [versions] key the version is taken from
+}
+
+// catalog holds the parts of a version catalog the integration reads.
+type catalog struct {
+	versions map[string]string
+	entries  []entry
+	lines    map[string]int // 1-based line of each "table.key"
+}
+
+// Integration implements Gradle version catalog updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new Gradle integration. Catalog artifacts are Maven artifacts,
+// so it queries the Maven datasource.
+func New() *Integration {
+	ds, err := datasource.Get("maven")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewMavenDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Gradle version catalogs.
+func (i *Integration) SupportedFiles() []string {
+	return []string{catalogDir + "/*" + catalogSuffix}
+}
+
+// Detect finds version catalogs in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Build output holds generated copies of catalogs
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && (info.Name() == "build" || info.Name() == ".gradle")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !isCatalog(path) || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		parsed, err := parseCatalog(content)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+
+		// Build scripts live in the project the gradle directory belongs to
+		platforms, err := platformAliases(filepath.Dir(filepath.Dir(path)), catalogAccessor(path), filter)
+		if err != nil {
+			return err
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: parsed.dependencies(platforms),
+			Content:      content,
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// isCatalog reports whether path is a *.versions.toml file in a gradle directory.
+func isCatalog(path string) bool {
+	return strings.HasSuffix(filepath.Base(path), catalogSuffix) && filepath.Base(filepath.Dir(path)) == catalogDir
+}
+
+// catalogAccessor returns the name build scripts use for a catalog, e.g.
+// "libs" for gradle/libs.versions.toml.
+func catalogAccessor(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), catalogSuffix)
+}
+
+// aliasAccessor returns the accessor path of a catalog alias, e.g.
+// "okhttp.bom" for okhttp-bom.
+func aliasAccessor(alias string) string {
+	return aliasSeparators.Replace(alias)
+}
+
+// platformAliases returns the accessor paths of the catalog libraries that
+// the build scripts under projectRoot import with platform() or
+// enforcedPlatform().
+func platformAliases(projectRoot, accessor string, filter *engine.WalkFilter) (map[string]bool, error) {
+	platforms := make(map[string]bool)
+
+	err := filepath.Walk(projectRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != projectRoot && (info.Name() == "build" || info.Name() == ".gradle")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		name := info.Name()
+		if (!strings.HasSuffix(name, ".gradle") && !strings.HasSuffix(name, ".gradle.kts")) || filter.ShouldSkipFile(path) {
+			return nil
+		}
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		for _, m := range platformPattern.FindAllStringSubmatch(string(content), -1) {
+			if m[1] == accessor {
+				platforms[m[2]] = true
+			}
+		}
+		return nil
+	})
+
+	return platforms, err
+}
+
+// parseCatalog decodes a catalog and records the line of each table key.
+// Rich versions (strictly, prefer, require) and entries without a version,
+// such as BOM-managed libraries, which take theirs from the BOM, are not read.
+func parseCatalog(content []byte) (*catalog, error) {
+	var raw struct {
+		Versions  map[string]any `toml:"versions"`
+		Libraries map[string]any `toml:"libraries"`
+		Plugins   map[string]any `toml:"plugins"`
+	}
+	if err := toml.Unmarshal(content, &raw); err != nil {
+		return nil, err
+	}
+
+	result := &catalog{
+		versions: make(map[string]string),
+		lines:    keyLines(content),
+	}
+	for key, value := range raw.Versions {
+		if v, ok := value.(string); ok {
+			result.versions[key] = v
+		}
+	}
+
+	for _, section := range []struct {
+		table  string
+		values map[string]any
+	}{
+		{table: "libraries", values: raw.Libraries},
+		{table: "plugins", values: raw.Plugins},
+	} {
+		for alias, value := range section.values {
+			e, ok := parseEntry(section.table, value)
+			if !ok {
+				continue
+			}
+			e.alias = alias
+			result.entries = append(result.entries, e)
+		}
+	}
+
+	// Report entries in file order
+	sortEntries(result.entries, result.lines)
+	return result, nil
+}
+
+// parseEntry reads a library ("group:artifact:version" or a table with module,
+// or group and name) or a plugin ("id:version" or a table with id).
+func parseEntry(table string, value any) (entry, bool) {
+	e := entry{table: table}
+
+	switch v := value.(type) {
+	case string:
+		idx := strings.LastIndex(v, ":")
+		if idx < 0 {
+			return entry{}, false
+		}
+		e.coordinate, e.version = v[:idx], v[idx+1:]
+		if table == "libraries" && strings.Count(e.coordinate, ":") != 1 {
+			return entry{}, false
+		}
+
+	case map[string]any:
+		if table == "plugins" {
+			e.coordinate, _ = v["id"].(string)
+		} else if module, ok := v["module"].(string); ok {
+			e.coordinate = module
+		} else {
+			group, _ := v["group"].(string)
+			name, _ := v["name"].(string)
+			if group != "" && name != "" {
+				e.coordinate = group + ":" + name
+			}
+		}
+
+		switch version := v["version"].(type) {
+		case string:
+			e.version = version
+		case map[string]any:
+			e.ref, _ = version["ref"].(string)
+		}
+
+	default:
+		return entry{}, false
+	}
+
+	if e.coordinate == "" || (e.version == "" && e.ref == "") {
+		return entry{}, false
+	}
+	return e, true
+}
+
+// keyLines maps "table.key" to the 1-based line declaring it. A sub-table
+// such as [libraries.guava] is recorded at its header.
+func keyLines(content []byte) map[string]int {
+	lines := make(map[string]int)
+	table := ""
+	for idx, line := range strings.Split(string(content), "\n") {
+		if m := headerPattern.FindStringSubmatch(line); m != nil {
+			table = normalizeTable(m[1])
+			if _, seen := lines[table]; !seen {
+				lines[table] = idx + 1
+			}
+			continue
+		}
+		if m := keyPattern.FindStringSubmatch(line); m != nil {
+			key := table + "." + m[1]
+			if _, seen := lines[key]; !seen {
+				lines[key] = idx + 1
+			}
+		}
+	}
+	return lines
+}
+
+// normalizeTable strips quotes and whitespace from a table name, so
+// [ libraries . "guava" ] is recognized as libraries.guava.
+func normalizeTable(name string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
+
+// sortEntries orders entries by declaring line, then by table and alias.
+func sortEntries(entries []entry, lines map[string]int) {
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		la, lb := lines[a.table+"."+a.alias], lines[b.table+"."+b.alias]
+		if la != lb {
+			return la < lb
+		}
+		if a.table != b.table {
+			return a.table < b.table
+		}
+		return a.alias < b.alias
+	})
+}
+
+// resolve returns the version of an entry and the "table.key" line where it
+// is written. References to undefined or rich versions do not resolve.
+func (c *catalog) resolve(e entry) (version, key string, ok bool) {
+	version, key = e.version, e.table+"."+e.alias
+	if e.ref != "" {
+		if version, ok = c.versions[e.ref]; !ok {
+			return "", "", false
+		}
+		key = "versions." + e.ref
+	}
+	if !versionPattern.MatchString(version) {
+		return "", "", false
+	}
+	return version, key, true
+}
+
+// dependencies returns the entries with a resolvable version. The constraint
+// of a referenced version is its [versions] key, e.g. "versions.kotlin".
+// Libraries whose accessor path is in platforms are reported as BOMs.
+func (c *catalog) dependencies(platforms map[string]bool) []engine.Dependency {
+	var deps []engine.Dependency
+
+	for _, e := range c.entries {
+		version, key, ok := c.resolve(e)
+		if !ok {
+			continue
+		}
+
+		dep := engine.Dependency{
+			Name:           e.coordinate,
+			CurrentVersion: version,
+			Type:           depTypeLibrary,
+			Line:           c.lines[key],
+		}
+		switch {
+		case e.table == "plugins":
+			dep.Type = depTypePlugin
+		case platforms[aliasAccessor(e.alias)]:
+			dep.Type = depTypeBOM
+		}
+		if e.ref != "" {
+			dep.Constraint = refPrefix + e.ref
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// artifact returns the Maven coordinate to query for a dependency. Plugins
+// are published under their marker artifact, id:id.gradle.plugin.
+func artifact(dep engine.Dependency) string {
+	if dep.Type == depTypePlugin {
+		return dep.Name + ":" + dep.Name + ".gradle.plugin"
+	}
+	return dep.Name
+}
+
+// Plan determines available updates for catalog entries. Entries sharing a
+// version.ref produce one update, to the newest version all of them publish,
+// or to the newest version of the BOM among them, which the managed libraries
+// follow. It applies policy precedence: CLI flags > uptool.yaml > constraints.
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+	planned := make(map[string]bool)
+
+	for _, dep := range manifest.Dependencies {
+		members := []engine.Dependency{dep}
+		if strings.HasPrefix(dep.Constraint, refPrefix) {
+			if planned[dep.Constraint] {
+				continue
+			}
+			planned[dep.Constraint] = true
+			members = sharing(manifest.Dependencies, dep.Constraint)
+			if managing := boms(members); len(managing) > 0 {
+				dep, members = managing[0], managing
+			}
+		}
+
+		availableVersions := i.sharedVersions(ctx, members)
+		if len(availableVersions) == 0 {
+			// Skip artifacts we can't query
+			continue
+		}
+
+		// Use policy-aware version selection; a catalog version is a soft requirement
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			"",
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "catalog_rewrite",
+	}, nil
+}
+
+// sharing returns the dependencies whose version comes from the same
+// [versions] key.
+func sharing(deps []engine.Dependency, constraint string) []engine.Dependency {
+	var members []engine.Dependency
+	for _, dep := range deps {
+		if dep.Constraint == constraint {
+			members = append(members, dep)
+		}
+	}
+	return members
+}
+
+// boms returns the BOMs among dependencies.
+func boms(deps []engine.Dependency) []engine.Dependency {
+	var result []engine.Dependency
+	for _, dep := range deps {
+		if dep.Type == depTypeBOM {
+			result = append(result, dep)
+		}
+	}
+	return result
+}
+
+// sharedVersions returns the versions published for every member that can be
+// queried, in the order the first of them lists them.
+func (i *Integration) sharedVersions(ctx context.Context, members []engine.Dependency) []string {
+	var (
+		shared  []string
+		queried int
+	)
+	for _, dep := range members {
+		versions, err := i.ds.GetVersions(ctx, artifact(dep))
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, artifact(dep))
+			if latestErr != nil {
+				continue
+			}
+			versions = []string{latest}
+		}
+
+		if queried == 0 {
+			shared = versions
+		} else {
+			published := make(map[string]bool, len(versions))
+			for _, v := range versions {
+				published[v] = true
+			}
+			kept := shared[:0:0]
+			for _, v := range shared {
+				if published[v] {
+					kept = append(kept, v)
+				}
+			}
+			shared = kept
+		}
+		queried++
+	}
+	return shared
+}
+
+// Apply executes the update by rewriting versions in the catalog.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read version catalog: %w", err)
+	}
+
+	newContent, applied, errs := rewriteCatalog(plan, oldContent)
+
+	if !bytes.Equal(newContent, oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+			return nil, fmt.Errorf("write version catalog: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(filepath.Base(plan.Manifest.Path), string(oldContent), string(newContent))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		ManifestDiff:   diff,
+		Errors:         errs,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to catalog content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewriteCatalog(plan, content)
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewriteCatalog writes each update's target where its version is defined:
+// the [versions] entry for a version.ref, otherwise the library or plugin
+// entry itself. An update whose version an earlier update already set to the
+// same target counts as applied. It returns the names of the applied updates'
+// dependencies.
+func rewriteCatalog(plan *engine.UpdatePlan, content []byte) (newContent []byte, applied, errs []string) {
+	newContent = content
+
+	for _, update := range plan.Updates {
+		dep := update.Dependency
+		if err := resolve.ValidateConstraint(integrationName, update.TargetVersion); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dep.Name, err))
+			continue
+		}
+
+		parsed, err := parseCatalog(newContent)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: parse version catalog: %v", dep.Name, err))
+			continue
+		}
+
+		var (
+			keys     []string
+			settled  bool
+			conflict string
+			seen     = make(map[string]bool)
+		)
+		for _, e := range parsed.entries {
+			if e.coordinate != dep.Name || (e.table == "plugins") != (dep.Type == depTypePlugin) {
+				continue
+			}
+			if ref := strings.TrimPrefix(dep.Constraint, refPrefix); ref != e.ref {
+				continue
+			}
+			version, key, ok := parsed.resolve(e)
+			if !ok {
+				continue
+			}
+			switch version {
+			case dep.CurrentVersion:
+				if !seen[key] {
+					seen[key] = true
+					keys = append(keys, key)
+				}
+			case update.TargetVersion:
+				settled = true
+			default:
+				if e.ref != "" {
+					conflict = fmt.Sprintf("version %s is %s", e.ref, version)
+				}
+			}
+		}
+
+		if len(keys) == 0 {
+			switch {
+			case settled:
+				applied = append(applied, dep.Name)
+			case conflict != "":
+				errs = append(errs, fmt.Sprintf("%s: %s, not %s", dep.Name, conflict, dep.CurrentVersion))
+			default:
+				errs = append(errs, fmt.Sprintf("%s: version %s not found in version catalog", dep.Name, dep.CurrentVersion))
+			}
+			continue
+		}
+
+		replaced := true
+		for _, key := range keys {
+			var ok bool
+			newContent, ok = replaceVersion(newContent, parsed.lines[key], dep.CurrentVersion, update.TargetVersion)
+			replaced = replaced && ok
+		}
+		if !replaced {
+			errs = append(errs, fmt.Sprintf("%s: could not rewrite version %s", dep.Name, dep.CurrentVersion))
+			continue
+		}
+		applied = append(applied, dep.Name)
+	}
+
+	return newContent, applied, errs
+}
+
+// replaceVersion rewrites the first quoted oldVersion in the block starting at
+// the 1-based line, which ends at the next table header. It matches a plain
+// value (kotlin = "1.9.0"), a version key (version = "1.9.0") and the end of a
+// coordinate string ("group:artifact:1.9.0"), leaving every other byte of the
+// file untouched.
+func replaceVersion(content []byte, line int, oldVersion, newVersion string) ([]byte, bool) {
+	if line < 1 {
+		return content, false
+	}
+
+	quoted := `(` + regexp.QuoteMeta(oldVersion) + `)["']`
+	patterns := []*regexp.Regexp{
+		regexp.MustCompile(`^(\s*["']?[A-Za-z0-9_-]+["']?\s*=\s*["'])` + quoted),
+		regexp.MustCompile(`(\bversion\s*=\s*["'])` + quoted),
+		regexp.MustCompile(`(["'][^"']*:)` + quoted),
+	}
+
+	lines := strings.SplitAfter(string(content), "\n")
+	for idx := line - 1; idx < len(lines); idx++ {
+		body := strings.TrimRight(lines[idx], "\r\n")
+		eol := lines[idx][len(body):]
+		if idx > line-1 && headerPattern.MatchString(body) {
+			break
+		}
+
+		for _, re := range patterns {
+			if loc := re.FindStringSubmatchIndex(body); loc != nil {
+				lines[idx] = body[:loc[4]] + newVersion + body[loc[5]:] + eol
+				return []byte(strings.Join(lines, "")), true
+			}
+		}
+	}
+	return content, false
+}
+
+// Validate checks that the manifest is valid TOML.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	if _, err := parseCatalog(manifest.Content); err != nil {
+		return fmt.Errorf("invalid version catalog %s: %w", manifest.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package gradle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testCatalog = `# Shared versions
+[versions]
+kotlin = "1.9.0"   # keep in sync with the plugin
+okhttp = "4.11.0"
+strict = { strictly = "[1.0, 2.0[" }
+
+[libraries]
+kotlin-stdlib = { module = "org.jetbrains.kotlin:kotlin-stdlib", version.ref = "kotlin" }
+kotlin-reflect = { group = "org.jetbrains.kotlin", name = "kotlin-reflect", version.ref = "kotlin" }
+okhttp = { module = "com.squareup.okhttp3:okhttp", version = { ref = "okhttp" } }
+gson = "com.google.code.gson:gson:2.10"
+junit = { module = "org.junit.jupiter:junit-jupiter", version = "5.9.0" }
+okhttp-bom-managed = { module = "com.squareup.okhttp3:logging-interceptor" }
+pinned = { module = "com.example:pinned", version.ref = "strict" }
+
+[plugins]
+kotlin-jvm = { id = "org.jetbrains.kotlin.jvm", version.ref = "kotlin" }
+versions = "com.github.ben-manes.versions:0.47.0"
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func writeCatalog(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, sub := range []string{"gradle", filepath.Join("build", "gradle")} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, sub, "libs.versions.toml"), []byte(testCatalog), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A catalog outside a gradle directory is not detected
+	if err := os.WriteFile(filepath.Join(dir, "libs.versions.toml"), []byte(testCatalog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeCatalog(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != filepath.Join("gradle", "libs.versions.toml") {
+		t.Fatalf("Detect() = %+v, want only gradle/libs.versions.toml", manifests)
+	}
+
+	// Rich and BOM-managed versions are skipped; referenced versions are
+	// reported at their [versions] line
+	want := []engine.Dependency{
+		{Name: "org.jetbrains.kotlin:kotlin-stdlib", CurrentVersion: "1.9.0", Constraint: "versions.kotlin", Type: depTypeLibrary, Line: 3},
+		{Name: "org.jetbrains.kotlin:kotlin-reflect", CurrentVersion: "1.9.0", Constraint: "versions.kotlin", Type: depTypeLibrary, Line: 3},
+		{Name: "com.squareup.okhttp3:okhttp", CurrentVersion: "4.11.0", Constraint: "versions.okhttp", Type: depTypeLibrary, Line: 4},
+		{Name: "com.google.code.gson:gson", CurrentVersion: "2.10", Type: depTypeLibrary, Line: 11},
+		{Name: "org.junit.jupiter:junit-jupiter", CurrentVersion: "5.9.0", Type: depTypeLibrary, Line: 12},
+		{Name: "org.jetbrains.kotlin.jvm", CurrentVersion: "1.9.0", Constraint: "versions.kotlin", Type: depTypePlugin, Line: 3},
+		{Name: "com.github.ben-manes.versions", CurrentVersion: "0.47.0", Type: depTypePlugin, Line: 18},
+	}
+	deps := manifests[0].Dependencies
+	if len(deps) != len(want) {
+		t.Fatalf("Dependencies = %+v, want %+v", deps, want)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Errorf("Dependencies[%d] = %+v, want %+v", i, deps[i], want[i])
+		}
+	}
+}
+
+func TestDetect_Platforms(t *testing.T) {
+	dir := t.TempDir()
+	catalog := `[versions]
+okhttp = "4.11.0"
+
+[libraries]
+okhttp = { module = "com.squareup.okhttp3:okhttp", version.ref = "okhttp" }
+okhttp-bom = { module = "com.squareup.okhttp3:okhttp-bom", version.ref = "okhttp" }
+spring_boot_bom = "org.springframework.boot:spring-boot-dependencies:3.1.0"
+jackson-bom = "com.fasterxml.jackson:jackson-bom:2.15.0"
+`
+	files := map[string]string{
+		filepath.Join("gradle", "libs.versions.toml"): catalog,
+		filepath.Join("app", "build.gradle.kts"):      "dependencies {\n    implementation(platform(libs.okhttp.bom))\n}\n",
+		"build.gradle":                                "dependencies {\n    implementation enforcedPlatform(libs.spring.boot.bom)\n    implementation libs.jackson.bom\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() = %+v, want one catalog", manifests)
+	}
+
+	// Only libraries imported with platform() are BOMs, whatever their name
+	want := map[string]string{
+		"com.squareup.okhttp3:okhttp":                       depTypeLibrary,
+		"com.squareup.okhttp3:okhttp-bom":                   depTypeBOM,
+		"org.springframework.boot:spring-boot-dependencies": depTypeBOM,
+		"com.fasterxml.jackson:jackson-bom":                 depTypeLibrary,
+	}
+	for _, dep := range manifests[0].Dependencies {
+		if want[dep.Name] != dep.Type {
+			t.Errorf("%s type = %q, want %q", dep.Name, dep.Type, want[dep.Name])
+		}
+	}
+}
+
+func TestPlan_SharedVersionRef(t *testing.T) {
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"org.jetbrains.kotlin:kotlin-stdlib":  {"2.0.0", "1.9.20", "1.9.0"},
+		"org.jetbrains.kotlin:kotlin-reflect": {"1.9.20", "1.9.0"},
+		// The plugin marker is not published to this datasource
+	}}}
+
+	parsed, err := parseCatalog([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("parseCatalog() error = %v", err)
+	}
+	manifest := &engine.Manifest{Path: "libs.versions.toml", Type: integrationName, Dependencies: parsed.dependencies(nil)}
+
+	plan, err := integ.Plan(context.Background(), manifest, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// One update for the kotlin ref, to the newest version both libraries publish
+	if len(plan.Updates) != 1 {
+		t.Fatalf("Plan() updates = %+v, want one", plan.Updates)
+	}
+	update := plan.Updates[0]
+	if update.Dependency.Constraint != "versions.kotlin" || update.TargetVersion != "1.9.20" {
+		t.Errorf("update = %+v, want versions.kotlin to 1.9.20", update)
+	}
+}
+
+func TestPlan_BOM(t *testing.T) {
+	content := []byte(`[versions]
+okhttp = "4.11.0"
+
+[libraries]
+okhttp = { module = "com.squareup.okhttp3:okhttp", version.ref = "okhttp" }
+okhttp-bom = { module = "com.squareup.okhttp3:okhttp-bom", version.ref = "okhttp" }
+logging-interceptor = { module = "com.squareup.okhttp3:logging-interceptor" }
+spring-boot-bom = "org.springframework.boot:spring-boot-dependencies:3.1.0"
+`)
+	parsed, err := parseCatalog(content)
+	if err != nil {
+		t.Fatalf("parseCatalog() error = %v", err)
+	}
+	manifest := &engine.Manifest{Path: "libs.versions.toml", Type: integrationName, Dependencies: parsed.dependencies(map[string]bool{"okhttp.bom": true, "spring.boot.bom": true})}
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		// okhttp alone has a newer release than its BOM
+		"com.squareup.okhttp3:okhttp":                       {"4.12.1", "4.12.0", "4.11.0"},
+		"com.squareup.okhttp3:okhttp-bom":                   {"4.12.0", "4.11.0"},
+		"org.springframework.boot:spring-boot-dependencies": {"3.3.0", "3.1.0"},
+	}}}
+	plan, err := integ.Plan(context.Background(), manifest, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// Only the BOMs are bumped; okhttp follows the BOM through the shared version
+	want := map[string]string{
+		"com.squareup.okhttp3:okhttp-bom":                   "4.12.0",
+		"org.springframework.boot:spring-boot-dependencies": "3.3.0",
+	}
+	if len(plan.Updates) != len(want) {
+		t.Fatalf("Plan() updates = %+v, want %v", plan.Updates, want)
+	}
+	for _, u := range plan.Updates {
+		if u.Dependency.Type != depTypeBOM || want[u.Dependency.Name] != u.TargetVersion {
+			t.Errorf("update = %+v, want a BOM update from %v", u, want)
+		}
+	}
+
+	result, err := integ.Rewrite(context.Background(), plan, content)
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	wantContent := strings.NewReplacer(
+		`okhttp = "4.11.0"`, `okhttp = "4.12.0"`,
+		`dependencies:3.1.0"`, `dependencies:3.3.0"`,
+	).Replace(string(content))
+	if result.Applied != 2 || string(result.Content) != wantContent {
+		t.Errorf("Rewrite() = %d applied, content\n%s\nwant\n%s", result.Applied, result.Content, wantContent)
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeCatalog(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"org.jetbrains.kotlin:kotlin-stdlib":                                        {"1.9.22", "1.9.0"},
+		"org.jetbrains.kotlin:kotlin-reflect":                                       {"1.9.22", "1.9.0"},
+		"org.jetbrains.kotlin.jvm:org.jetbrains.kotlin.jvm.gradle.plugin":           {"1.9.22", "1.9.0"},
+		"com.google.code.gson:gson":                                                 {"2.10.1", "2.10"},
+		"org.junit.jupiter:junit-jupiter":                                           {"5.10.1", "5.9.0"},
+		"com.github.ben-manes.versions:com.github.ben-manes.versions.gradle.plugin": {"0.51.0", "0.47.0"},
+	}}}
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 4 {
+		t.Fatalf("Plan() updates = %+v, want 4", plan.Updates)
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 4 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 4 applied and no errors", result)
+	}
+
+	content, _ := os.ReadFile(filepath.Join("gradle", "libs.versions.toml"))
+
+	// The shared version is updated once; comments and ordering are kept
+	want := strings.NewReplacer(
+		`kotlin = "1.9.0"`, `kotlin = "1.9.22"`,
+		`gson:2.10"`, `gson:2.10.1"`,
+		`version = "5.9.0"`, `version = "5.10.1"`,
+		`versions:0.47.0"`, `versions:0.51.0"`,
+	).Replace(testCatalog)
+	if string(content) != want {
+		t.Errorf("rewritten catalog =\n%s\nwant\n%s", content, want)
+	}
+}
+
+func TestRewrite_SharedVersionConflict(t *testing.T) {
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "libs.versions.toml", Type: integrationName},
+		Updates: []engine.Update{
+			{Dependency: engine.Dependency{Name: "org.jetbrains.kotlin:kotlin-stdlib", CurrentVersion: "1.9.0", Constraint: "versions.kotlin", Type: depTypeLibrary}, TargetVersion: "1.9.22"},
+			{Dependency: engine.Dependency{Name: "org.jetbrains.kotlin:kotlin-reflect", CurrentVersion: "1.9.0", Constraint: "versions.kotlin", Type: depTypeLibrary}, TargetVersion: "1.9.22"},
+			{Dependency: engine.Dependency{Name: "org.jetbrains.kotlin.jvm", CurrentVersion: "1.9.0", Constraint: "versions.kotlin", Type: depTypePlugin}, TargetVersion: "2.0.0"},
+		},
+	}
+
+	result, err := New().Rewrite(context.Background(), plan, []byte(testCatalog))
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Applied != 2 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "version kotlin is 1.9.22") {
+		t.Errorf("Rewrite() = %+v, want the conflicting update of the shared version reported", result)
+	}
+	if strings.Count(string(result.Content), "1.9.22") != 1 {
+		t.Errorf("shared version written more than once:\n%s", result.Content)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: "libs.versions.toml", Content: []byte(testCatalog)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: "libs.versions.toml", Content: []byte("[versions\nkotlin = ")}); err == nil {
+		t.Error("Validate() expected error for malformed TOML")
+	}
+}
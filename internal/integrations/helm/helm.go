@@ -25,8 +25,10 @@ package helm
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 
@@ -35,7 +37,9 @@ import (
 	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
 	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
 )
 
 func init() {
@@ -46,9 +50,23 @@ func init() {
 
 const integrationName = "helm"
 
+// lockfileName is the lockfile Helm writes next to Chart.yaml.
+const lockfileName = "Chart.lock"
+
+// runner executes a command in dir and returns its combined output.
+type runner func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+
 // Integration implements helm chart updates.
 type Integration struct {
-	ds datasource.Datasource
+	ds       datasource.Datasource
+	verifier indexVerifier
+	run      runner
+}
+
+// indexVerifier is implemented by datasources that can check repository
+// indexes against their published checksums.
+type indexVerifier interface {
+	EnableChecksumVerification(repository string)
 }
 
 // New creates a new helm integration.
@@ -58,8 +76,11 @@ func New() *Integration {
 		// Fallback to creating a new instance if not registered
 		ds = datasource.NewHelmDatasource()
 	}
+	verifier, _ := ds.(indexVerifier)
 	return &Integration{
-		ds: ds,
+		ds:       datasource.Cached(ds),
+		verifier: verifier,
+		run:      runCommand,
 	}
 }
 
@@ -68,6 +89,11 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of Helm chart manifests.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"Chart.yaml"}
+}
+
 // Chart represents the structure of Chart.yaml.
 type Chart struct {
 	Raw          map[string]any `yaml:",inline"`
@@ -82,13 +108,13 @@ type Chart struct {
 
 // Dependency represents a chart dependency.
 type Dependency struct {
-	Name       string `yaml:"name"`
-	Version    string `yaml:"version"`
-	Repository string `yaml:"repository"`
-	Condition  string `yaml:"condition,omitempty"`
-	Tags       string `yaml:"tags,omitempty"`
-	Alias      string `yaml:"alias,omitempty"`
-	Enabled    bool   `yaml:"enabled,omitempty"`
+	Name       string   `yaml:"name"`
+	Version    string   `yaml:"version"`
+	Repository string   `yaml:"repository"`
+	Condition  string   `yaml:"condition,omitempty"`
+	Alias      string   `yaml:"alias,omitempty"`
+	Tags       []string `yaml:"tags,omitempty"`
+	Enabled    bool     `yaml:"enabled,omitempty"`
 }
 
 // Detect finds Chart.yaml files in the repository.
@@ -140,6 +166,9 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 					"deps_count":    len(chart.Dependencies),
 				},
 			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), lockfileName)); err == nil {
+				manifest.Metadata["lockfile"] = filepath.Join(filepath.Dir(relPath), lockfileName)
+			}
 
 			manifests = append(manifests, manifest)
 		}
@@ -155,13 +184,8 @@ func (i *Integration) extractDependencies(chart *Chart) []engine.Dependency {
 	deps := make([]engine.Dependency, 0, len(chart.Dependencies))
 
 	for _, dep := range chart.Dependencies {
-		// Skip OCI repositories for now
-		if strings.HasPrefix(dep.Repository, "oci://") {
-			continue
-		}
-
-		// Skip local dependencies
-		if strings.HasPrefix(dep.Repository, "file://") {
+		// Skip local dependencies and subcharts vendored in charts/
+		if dep.Repository == "" || strings.HasPrefix(dep.Repository, "file://") {
 			continue
 		}
 
@@ -185,12 +209,23 @@ func (i *Integration) extractDependencies(chart *Chart) []engine.Dependency {
 func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
 	updates := make([]engine.Update, 0, len(manifest.Dependencies))
 
+	if i.verifier != nil && planCtx != nil && planCtx.Policy != nil {
+		for _, repo := range planCtx.Policy.Repositories {
+			if repo.VerifyChecksum {
+				i.verifier.EnableChecksumVerification(repo.URL)
+			}
+		}
+	}
+
 	for _, dep := range manifest.Dependencies {
 		// Get all versions from chart repository
 		// Datasource expects format: "repository_url|chart_name"
 		pkg := fmt.Sprintf("%s|%s", dep.Registry, dep.Name)
 
 		availableVersions, err := i.ds.GetVersions(ctx, pkg)
+		if errors.Is(err, registry.ErrIndexChecksum) {
+			return nil, fmt.Errorf("chart %s: %w", dep.Name, err)
+		}
 		if err != nil {
 			// Fallback: try to get just the latest version
 			latest, latestErr := i.ds.GetLatestVersion(ctx, pkg)
@@ -227,9 +262,13 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 	}, nil
 }
 
-// Apply executes the update by rewriting Chart.yaml.
+// Apply executes the update by rewriting the version of each updated
+// dependency in Chart.yaml. Chart.lock records a digest of Chart.yaml's
+// dependencies, so it cannot be edited in place; with FixLockfile set it is
+// regenerated by running 'helm dependency update', otherwise it is left for
+// the user to refresh.
 func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
-	if len(plan.Updates) == 0 {
+	if len(plan.Updates) == 0 || !plan.WritesManifest() {
 		return &engine.ApplyResult{
 			Manifest: plan.Manifest,
 			Applied:  0,
@@ -248,50 +287,170 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("read Chart.yaml: %w", err)
 	}
 
-	// Parse Chart.yaml
-	var chart Chart
-	err = yaml.Unmarshal(oldContent, &chart)
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
 	if err != nil {
+		return nil, err
+	}
+	if rewritten.Applied == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   rewritten.Failed,
+			Errors:   rewritten.Errors,
+		}, nil
+	}
+	newContent := rewritten.Content
+	applied := rewritten.Applied
+	errs := rewritten.Errors
+
+	// Write updated content
+	if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+		return nil, fmt.Errorf("write Chart.yaml: %w", err)
+	}
+
+	// Generate diff
+	diff := generateDiff(string(oldContent), string(newContent))
+
+	result := &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        applied,
+		ManifestDiff:   diff,
+	}
+
+	if lockPath, ok := plan.Manifest.Metadata["lockfile"].(string); ok && plan.FixLockfile && plan.WritesLockfile() {
+		lockDiff, err := i.updateLockfile(ctx, plan.Manifest.Path, lockPath)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+		result.LockfileDiff = lockDiff
+	}
+
+	result.Failed = len(errs)
+	result.Errors = errs
+	return result, nil
+}
+
+// Rewrite applies the plan's updates to Chart.yaml content in memory. Only
+// the version values of matching dependencies change, so comments, ordering,
+// and quoting are preserved.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
 		return nil, fmt.Errorf("parse Chart.yaml: %w", err)
 	}
 
-	// Create update map for quick lookup
-	updateMap := make(map[string]string)
-	for i := range plan.Updates {
-		update := &plan.Updates[i]
-		updateMap[update.Dependency.Name] = update.TargetVersion
+	var entries []*yaml.Node
+	if len(doc.Content) > 0 {
+		if deps := mappingValue(doc.Content[0], "dependencies"); deps != nil && deps.Kind == yaml.SequenceNode {
+			entries = deps.Content
+		}
 	}
 
-	applied := 0
+	lines := strings.Split(string(content), "\n")
+	var applied, errs []string
 
-	// Update dependency versions
-	for i := range chart.Dependencies {
-		if newVersion, ok := updateMap[chart.Dependencies[i].Name]; ok {
-			chart.Dependencies[i].Version = newVersion
-			applied++
+	for j := range plan.Updates {
+		update := &plan.Updates[j]
+		// Drop versions Helm cannot parse
+		if err := resolve.ValidateConstraint(integrationName, update.TargetVersion); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
+
+		found := false
+		for _, entry := range entries {
+			if !matchesDependency(entry, &update.Dependency) {
+				continue
+			}
+			version := mappingValue(entry, "version")
+			if version == nil || version.Line < 1 || version.Line > len(lines) {
+				continue
+			}
+			line := lines[version.Line-1]
+			col := version.Column - 1
+			if col < 0 || col > len(line) {
+				continue
+			}
+			lines[version.Line-1] = line[:col] + strings.Replace(line[col:], version.Value, update.TargetVersion, 1)
+			found = true
 		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: dependency not found in Chart.yaml", update.Dependency.Name))
+			continue
+		}
+		applied = append(applied, update.Dependency.Name)
+	}
+
+	return &engine.RewriteResult{
+		Content:        []byte(strings.Join(lines, "\n")),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// matchesDependency reports whether a dependencies entry of Chart.yaml is the
+// dependency dep, matching its name and, when recorded, its repository.
+func matchesDependency(entry *yaml.Node, dep *engine.Dependency) bool {
+	name := mappingValue(entry, "name")
+	if name == nil || name.Value != dep.Name {
+		return false
+	}
+	if dep.Registry == "" {
+		return true
 	}
+	repository := mappingValue(entry, "repository")
+	return repository != nil && strings.TrimSuffix(repository.Value, "/") == strings.TrimSuffix(dep.Registry, "/")
+}
 
-	// Marshal updated chart
-	newContent, err := yaml.Marshal(&chart)
+// mappingValue returns the value node for key in a mapping node.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for idx := 0; idx+1 < len(node.Content); idx += 2 {
+		if node.Content[idx].Value == key {
+			return node.Content[idx+1]
+		}
+	}
+	return nil
+}
+
+// updateLockfile regenerates Chart.lock by running 'helm dependency update'
+// in the chart's directory and returns the lockfile's diff. Helm also
+// refreshes the chart archives under charts/.
+func (i *Integration) updateLockfile(ctx context.Context, chartPath, lockPath string) (string, error) {
+	if err := integrations.ValidateFilePath(lockPath); err != nil {
+		return "", fmt.Errorf("invalid lockfile path: %w", err)
+	}
+
+	oldLock, err := os.ReadFile(lockPath) // #nosec G304 - path is validated above
 	if err != nil {
-		return nil, fmt.Errorf("marshal Chart.yaml: %w", err)
+		return "", fmt.Errorf("read %s: %w", lockfileName, err)
 	}
 
-	// Write updated content
-	if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
-		return nil, fmt.Errorf("write Chart.yaml: %w", err)
+	if output, err := i.run(ctx, filepath.Dir(chartPath), "helm", "dependency", "update", "."); err != nil {
+		return "", fmt.Errorf("%s: helm dependency update failed: %v\n%s", lockfileName, err, output)
 	}
 
-	// Generate diff
-	diff := generateDiff(string(oldContent), string(newContent))
+	newLock, err := os.ReadFile(lockPath) // #nosec G304 - path is validated above
+	if err != nil {
+		return "", fmt.Errorf("read updated %s: %w", lockfileName, err)
+	}
 
-	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       0,
-		ManifestDiff: diff,
-	}, nil
+	return rewrite.GenerateUnifiedDiff(lockfileName, string(oldLock), string(newLock))
+}
+
+// runCommand runs a command in dir and returns its combined output.
+func runCommand(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s command not found", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 - fixed helm arguments, not a shell
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
 }
 
 // Validate checks if the Chart.yaml is valid.
@@ -22,6 +22,9 @@ package helm
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,6 +34,7 @@ import (
 
 	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
 )
 
 func TestNew(t *testing.T) {
@@ -233,7 +237,7 @@ func TestExtractDependencies(t *testing.T) {
 		}
 	})
 
-	t.Run("skips OCI repositories", func(t *testing.T) {
+	t.Run("includes OCI repositories", func(t *testing.T) {
 		chart := &Chart{
 			Dependencies: []Dependency{
 				{Name: "oci-chart", Version: "1.0.0", Repository: "oci://registry.example.com/charts"},
@@ -243,11 +247,26 @@ func TestExtractDependencies(t *testing.T) {
 
 		deps := integ.extractDependencies(chart)
 
-		if len(deps) != 1 {
-			t.Fatalf("extractDependencies() count = %d, want 1 (OCI should be skipped)", len(deps))
+		if len(deps) != 2 {
+			t.Fatalf("extractDependencies() count = %d, want 2", len(deps))
 		}
-		if deps[0].Name != "normal-chart" {
-			t.Errorf("extractDependencies() name = %q, want %q", deps[0].Name, "normal-chart")
+		if deps[0].Registry != "oci://registry.example.com/charts" {
+			t.Errorf("extractDependencies() registry = %q, want the OCI repository", deps[0].Registry)
+		}
+	})
+
+	t.Run("skips vendored subcharts", func(t *testing.T) {
+		chart := &Chart{
+			Dependencies: []Dependency{
+				{Name: "vendored", Version: "1.0.0"},
+				{Name: "remote-chart", Version: "1.0.0", Repository: "https://charts.example.com"},
+			},
+		}
+
+		deps := integ.extractDependencies(chart)
+
+		if len(deps) != 1 || deps[0].Name != "remote-chart" {
+			t.Errorf("extractDependencies() = %+v, want only remote-chart", deps)
 		}
 	})
 
@@ -713,3 +732,179 @@ func TestPlan_WithMockDatasource(t *testing.T) {
 		}
 	})
 }
+
+func TestPlan_IndexVerification(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			_, _ = w.Write([]byte("apiVersion: v1\nentries:\n  nginx:\n    - name: nginx\n      version: 2.0.0\n"))
+		case "/index.yaml.sha256":
+			_, _ = w.Write([]byte(strings.Repeat("0", 64) + "  index.yaml\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ds := datasource.NewHelmDatasource()
+	integ := &Integration{ds: ds, verifier: ds}
+
+	manifest := &engine.Manifest{
+		Path: "Chart.yaml",
+		Type: "helm",
+		Dependencies: []engine.Dependency{
+			{Name: "nginx", CurrentVersion: "1.0.0", Registry: server.URL, Type: "chart"},
+		},
+	}
+
+	planCtx := &engine.PlanContext{
+		Policy: &engine.IntegrationPolicy{
+			Update:       "major",
+			Repositories: []engine.RepositoryConfig{{URL: server.URL, VerifyChecksum: true}},
+		},
+	}
+
+	_, err := integ.Plan(context.Background(), manifest, planCtx)
+	if !errors.Is(err, registry.ErrIndexChecksum) {
+		t.Fatalf("Plan() error = %v, want ErrIndexChecksum", err)
+	}
+}
+
+func TestApply_InvalidVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	chartPath := filepath.Join(tmpDir, "Chart.yaml")
+
+	original := "apiVersion: v2\nname: myapp\nversion: 1.0.0\ndependencies:\n  - name: nginx\n    version: 1.0.0\n    repository: https://charts.bitnami.com/bitnami\n"
+	if err := os.WriteFile(chartPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: chartPath},
+		Updates: []engine.Update{
+			{
+				Dependency: engine.Dependency{
+					Name:           "nginx",
+					CurrentVersion: "1.0.0",
+					Type:           "chart",
+				},
+				TargetVersion: ">=>2.0",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("Apply() applied/failed = %d/%d, want 0/1", result.Applied, result.Failed)
+	}
+
+	content, err := os.ReadFile(chartPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != original {
+		t.Errorf("Apply() wrote Chart.yaml despite invalid version:\n%s", content)
+	}
+}
+
+func TestChartDependencies(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	const chartYAML = `apiVersion: v2
+name: myapp # the application chart
+version: 1.0.0
+dependencies:
+  # Database, from the classic repository
+  - name: postgresql
+    version: "12.1.0" # pinned for the migration
+    repository: https://charts.bitnami.com/bitnami
+    condition: postgresql.enabled
+  - name: valkey
+    repository: oci://registry-1.docker.io/bitnamicharts
+    version: 2.0.0
+    tags: [cache]
+`
+	if err := os.WriteFile("Chart.yaml", []byte(chartYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockfileName, []byte("digest: sha256:old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var ran []string
+	integ := &Integration{
+		ds: &mockDatasource{versions: map[string][]string{
+			"https://charts.bitnami.com/bitnami|postgresql":   {"12.1.0", "12.5.6", "13.0.0"},
+			"oci://registry-1.docker.io/bitnamicharts|valkey": {"1.9.0", "2.0.0", "2.3.1"},
+		}},
+		run: func(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+			ran = append(ran, dir+": "+name+" "+strings.Join(args, " "))
+			return nil, os.WriteFile(filepath.Join(dir, lockfileName), []byte("digest: sha256:new\n"), 0o644)
+		},
+	}
+
+	manifests, err := integ.Detect(ctx, dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || len(manifests[0].Dependencies) != 2 {
+		t.Fatalf("Detect() = %+v, want one manifest with two dependencies", manifests)
+	}
+	if got := manifests[0].Metadata["lockfile"]; got != lockfileName {
+		t.Errorf("Metadata[lockfile] = %v, want %s", got, lockfileName)
+	}
+
+	plan, err := integ.Plan(ctx, manifests[0], &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "minor"}})
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	targets := map[string]string{}
+	for _, u := range plan.Updates {
+		targets[u.Dependency.Name] = u.TargetVersion
+	}
+	if targets["postgresql"] != "12.5.6" || targets["valkey"] != "2.3.1" {
+		t.Fatalf("Plan() targets = %v, want postgresql 12.5.6 and valkey 2.3.1", targets)
+	}
+
+	t.Run("preserves comments and ordering", func(t *testing.T) {
+		result, err := integ.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 2 || result.Failed != 0 {
+			t.Errorf("Apply() applied/failed = %d/%d, want 2/0 (%v)", result.Applied, result.Failed, result.Errors)
+		}
+
+		want := strings.Replace(strings.Replace(chartYAML, `"12.1.0"`, `"12.5.6"`, 1), "version: 2.0.0", "version: 2.3.1", 1)
+		content, _ := os.ReadFile("Chart.yaml")
+		if string(content) != want {
+			t.Errorf("Chart.yaml =\n%s\nwant\n%s", content, want)
+		}
+		if len(ran) != 0 || result.LockfileDiff != "" {
+			t.Errorf("Chart.lock regenerated without --fix-lockfile: %v", ran)
+		}
+	})
+
+	t.Run("fix-lockfile runs helm dependency update", func(t *testing.T) {
+		if err := os.WriteFile("Chart.yaml", []byte(chartYAML), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		plan.FixLockfile = true
+
+		result, err := integ.Apply(ctx, plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if want := []string{".: helm dependency update ."}; strings.Join(ran, "\n") != strings.Join(want, "\n") {
+			t.Errorf("commands = %v, want %v", ran, want)
+		}
+		if !strings.Contains(result.LockfileDiff, "+digest: sha256:new") {
+			t.Errorf("LockfileDiff = %q, want the regenerated digest", result.LockfileDiff)
+		}
+	})
+}
@@ -0,0 +1,533 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package maven implements the Maven integration for pom.xml dependencies.
+// It detects pom.xml files, reads <dependencies>, <dependencyManagement> and
+// <parent>, resolves ${property} versions from <properties>, queries Maven
+// Central for newer releases, and rewrites either the literal <version> or the
+// property element that sets it, leaving the rest of the file untouched.
+// A BOM is a dependencyManagement entry with <type>pom</type> and
+// <scope>import</scope>. Dependencies of the BOM's group, or of a group under
+// it, whose version property it shares are managed by it and follow the BOM's
+// update instead of being bumped on their own.
+package maven
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+)
+
+func init() {
+	integrations.Register("maven", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "maven"
+	manifestName    = "pom.xml"
+)
+
+// Dependency types reported by Detect.
+const (
+	depTypeDirect  = "direct"
+	depTypeManaged = "managed"
+	depTypeBOM     = "bom"
+	depTypeParent  = "parent"
+)
+
+var (
+	// propertyPattern matches a version that is a single property reference.
+	propertyPattern = regexp.MustCompile(`^\$\{([^}]+)\}$`)
+
+	// versionPattern matches a plain Maven version; ranges such as "[1.0,2.0)" do not match.
+	versionPattern = regexp.MustCompile(`^[0-9][0-9A-Za-z._-]*$`)
+)
+
+// text is element text with its byte offsets in the POM.
+type text struct {
+	value string
+	start int
+	end   int
+}
+
+// artifact is a <dependency> or <parent> read from a POM.
+type artifact struct {
+	groupID    string
+	artifactID string
+	version    text
+	scope      string
+	typ        string
+	depType    string
+}
+
+// pom holds the parts of a POM the integration reads and rewrites.
+type pom struct {
+	properties map[string]text
+	artifacts  []artifact
+}
+
+// Integration implements Maven pom.xml updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new Maven integration.
+func New() *Integration {
+	ds, err := datasource.Get("maven")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewMavenDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Maven POMs.
+func (i *Integration) SupportedFiles() []string {
+	return []string{manifestName}
+}
+
+// Detect finds pom.xml files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Build output holds copies of POMs
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && info.Name() == "target") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Name() != manifestName || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		parsed, err := parsePOM(content)
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", relPath, err)
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: parsed.dependencies(content),
+			Content:      content,
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parsePOM reads the project's properties, parent, dependencies and managed
+// dependencies. Profiles, plugins and nested modules are not read.
+func parsePOM(content []byte) (*pom, error) {
+	result := &pom{properties: make(map[string]text)}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	// Offsets index the raw bytes, so other declared encodings are read as-is
+	decoder.CharsetReader = func(_ string, r io.Reader) (io.Reader, error) { return r, nil }
+
+	var (
+		stack   []string
+		current *artifact
+	)
+	for {
+		start := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			switch path := strings.Join(stack, "/"); path {
+			case "project/parent":
+				current = &artifact{depType: depTypeParent}
+			case "project/dependencies/dependency":
+				current = &artifact{depType: depTypeDirect}
+			case "project/dependencyManagement/dependencies/dependency":
+				current = &artifact{depType: depTypeManaged}
+			}
+
+		case xml.EndElement:
+			if current != nil && isArtifactPath(stack) {
+				// A managed POM imported with scope "import" is a BOM
+				if current.depType == depTypeManaged && current.scope == "import" && current.typ == "pom" {
+					current.depType = depTypeBOM
+				}
+				if current.groupID != "" && current.artifactID != "" {
+					result.artifacts = append(result.artifacts, *current)
+				}
+				current = nil
+			}
+			stack = stack[:len(stack)-1]
+
+		case xml.CharData:
+			value := trimmedText(string(t), start)
+			if len(stack) == 3 && stack[0] == "project" && stack[1] == "properties" {
+				if _, seen := result.properties[stack[2]]; !seen {
+					result.properties[stack[2]] = value
+				}
+				continue
+			}
+			if current == nil || len(stack) < 2 || !isArtifactPath(stack[:len(stack)-1]) {
+				continue
+			}
+			switch stack[len(stack)-1] {
+			case "groupId":
+				current.groupID = value.value
+			case "artifactId":
+				current.artifactID = value.value
+			case "version":
+				current.version = value
+			case "scope":
+				current.scope = value.value
+			case "type":
+				current.typ = value.value
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// isArtifactPath reports whether an element path is a parent or dependency.
+func isArtifactPath(stack []string) bool {
+	switch strings.Join(stack, "/") {
+	case "project/parent", "project/dependencies/dependency", "project/dependencyManagement/dependencies/dependency":
+		return true
+	}
+	return false
+}
+
+// trimmedText returns raw element text starting at offset without its
+// surrounding whitespace.
+func trimmedText(raw string, offset int) text {
+	trimmed := strings.TrimLeft(raw, " \t\r\n")
+	offset += len(raw) - len(trimmed)
+	trimmed = strings.TrimRight(trimmed, " \t\r\n")
+	return text{value: trimmed, start: offset, end: offset + len(trimmed)}
+}
+
+// resolveVersion returns the version of an artifact and where it is written:
+// the property element for a ${property} version, otherwise the <version>
+// element itself. Ranges, expressions and undefined properties do not resolve.
+func (p *pom) resolveVersion(a artifact) (version text, property string, ok bool) {
+	version = a.version
+	if m := propertyPattern.FindStringSubmatch(version.value); m != nil {
+		property = m[1]
+		if version, ok = p.properties[property]; !ok {
+			return text{}, "", false
+		}
+	}
+	if !versionPattern.MatchString(version.value) {
+		return text{}, "", false
+	}
+	return version, property, true
+}
+
+// dependencies returns the artifacts with a resolvable version. The
+// constraint of a property-driven version is its ${property} reference.
+func (p *pom) dependencies(content []byte) []engine.Dependency {
+	var deps []engine.Dependency
+	seen := make(map[string]bool)
+
+	for _, a := range p.artifacts {
+		version, property, ok := p.resolveVersion(a)
+		if !ok {
+			continue
+		}
+		name := a.groupID + ":" + a.artifactID
+		if seen[name+"@"+version.value] {
+			continue
+		}
+		seen[name+"@"+version.value] = true
+
+		dep := engine.Dependency{
+			Name:           name,
+			CurrentVersion: version.value,
+			Type:           a.depType,
+			Line:           bytes.Count(content[:a.version.start], []byte("\n")) + 1,
+		}
+		if property != "" {
+			dep.Constraint = a.version.value
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// Plan determines available updates for POM dependencies. Dependencies of an
+// imported BOM's group, or of a group under it, whose version property the BOM
+// also uses are left to the BOM's update.
+// It applies policy precedence: CLI flags > uptool.yaml > constraints.
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+	var boms []engine.Dependency
+	for _, dep := range manifest.Dependencies {
+		if dep.Type == depTypeBOM && dep.Constraint != "" {
+			boms = append(boms, dep)
+		}
+	}
+
+	for _, dep := range manifest.Dependencies {
+		if dep.Type != depTypeBOM && managedByBOM(dep, boms) {
+			continue
+		}
+
+		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, dep.Name)
+			if latestErr != nil {
+				// Skip artifacts we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		// Use policy-aware version selection; a POM version is a soft requirement
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			"",
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "pom_rewrite",
+	}, nil
+}
+
+// managedByBOM reports whether one of boms shares dep's version property and
+// has dep's group, or a group dep's group is under (com.fasterxml.jackson for
+// com.fasterxml.jackson.core).
+func managedByBOM(dep engine.Dependency, boms []engine.Dependency) bool {
+	group := groupID(dep.Name)
+	for _, bom := range boms {
+		bomGroup := groupID(bom.Name)
+		if bom.Constraint == dep.Constraint && (group == bomGroup || strings.HasPrefix(group, bomGroup+".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// groupID returns the group of a "group:artifact" dependency name.
+func groupID(name string) string {
+	group, _, _ := strings.Cut(name, ":")
+	return group
+}
+
+// Apply executes the update by rewriting versions in pom.xml.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read pom.xml: %w", err)
+	}
+
+	newContent, applied, errs := rewritePOM(plan, oldContent)
+
+	if !bytes.Equal(newContent, oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+			return nil, fmt.Errorf("write pom.xml: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(manifestName, string(oldContent), string(newContent))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		ManifestDiff:   diff,
+		Errors:         errs,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to pom.xml content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewritePOM(plan, content)
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewritePOM writes each update's target where its version is defined: the
+// property element for a ${property} version, otherwise the <version>
+// element. An update whose shared property an earlier update already set to
+// the same target counts as applied. It returns the names of the applied
+// updates' dependencies.
+func rewritePOM(plan *engine.UpdatePlan, content []byte) (newContent []byte, applied, errs []string) {
+	newContent = content
+
+	for _, update := range plan.Updates {
+		name := update.Dependency.Name
+		if err := resolve.ValidateConstraint(integrationName, update.TargetVersion); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		parsed, err := parsePOM(newContent)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: parse pom.xml: %v", name, err))
+			continue
+		}
+
+		var (
+			targets  []text
+			settled  bool
+			offsets  = make(map[int]bool)
+			conflict string
+		)
+		for _, a := range parsed.artifacts {
+			if a.groupID+":"+a.artifactID != name {
+				continue
+			}
+			version, property, ok := parsed.resolveVersion(a)
+			if !ok {
+				continue
+			}
+			switch version.value {
+			case update.Dependency.CurrentVersion:
+				if !offsets[version.start] {
+					offsets[version.start] = true
+					targets = append(targets, version)
+				}
+			case update.TargetVersion:
+				settled = true
+			default:
+				if property != "" {
+					conflict = fmt.Sprintf("property %s is %s", property, version.value)
+				}
+			}
+		}
+
+		if len(targets) == 0 {
+			switch {
+			case settled:
+				applied = append(applied, name)
+			case conflict != "":
+				errs = append(errs, fmt.Sprintf("%s: %s, not %s", name, conflict, update.Dependency.CurrentVersion))
+			default:
+				errs = append(errs, fmt.Sprintf("%s: version %s not found in pom.xml", name, update.Dependency.CurrentVersion))
+			}
+			continue
+		}
+
+		// Replace from the end so earlier offsets stay valid
+		sort.Slice(targets, func(a, b int) bool { return targets[a].start > targets[b].start })
+		for _, t := range targets {
+			newContent = append(append(append([]byte{}, newContent[:t.start]...), update.TargetVersion...), newContent[t.end:]...)
+		}
+		applied = append(applied, name)
+	}
+
+	return newContent, applied, errs
+}
+
+// Validate checks that the manifest is a well-formed POM.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	if _, err := parsePOM(manifest.Content); err != nil {
+		return fmt.Errorf("invalid pom.xml %s: %w", manifest.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,415 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package maven
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testPOM = `<?xml version="1.0" encoding="UTF-8"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <modelVersion>4.0.0</modelVersion>
+
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.1.0</version>
+  </parent>
+
+  <artifactId>app</artifactId>
+  <version>1.0.0-SNAPSHOT</version>
+
+  <properties>
+    <java.version>17</java.version>
+    <jackson.version>2.15.0</jackson.version>
+  </properties>
+
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.fasterxml.jackson</groupId>
+        <artifactId>jackson-bom</artifactId>
+        <version>${jackson.version}</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+
+  <dependencies>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.apache.commons</groupId>
+      <artifactId>commons-lang3</artifactId>
+      <version>3.12.0</version>
+      <exclusions>
+        <exclusion>
+          <groupId>org.example</groupId>
+          <artifactId>excluded</artifactId>
+        </exclusion>
+      </exclusions>
+    </dependency>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>ranged</artifactId>
+      <version>[1.0,2.0)</version>
+    </dependency>
+    <!--
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>commented</artifactId>
+      <version>1.0.0</version>
+    </dependency>
+    -->
+  </dependencies>
+</project>
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func writePOM(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "pom.xml"), []byte(testPOM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "target"), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "target", "pom.xml"), []byte(testPOM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writePOM(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != "pom.xml" {
+		t.Fatalf("Detect() = %+v, want only pom.xml (target/ skipped)", manifests)
+	}
+
+	// Versionless, ranged and commented-out dependencies are skipped
+	want := []engine.Dependency{
+		{Name: "org.springframework.boot:spring-boot-starter-parent", CurrentVersion: "3.1.0", Type: depTypeParent, Line: 8},
+		{Name: "com.fasterxml.jackson:jackson-bom", CurrentVersion: "2.15.0", Constraint: "${jackson.version}", Type: depTypeBOM, Line: 24},
+		{Name: "com.fasterxml.jackson.core:jackson-databind", CurrentVersion: "2.15.0", Constraint: "${jackson.version}", Type: depTypeDirect, Line: 35},
+		{Name: "org.apache.commons:commons-lang3", CurrentVersion: "3.12.0", Type: depTypeDirect, Line: 40},
+	}
+	deps := manifests[0].Dependencies
+	if len(deps) != len(want) {
+		t.Fatalf("Dependencies = %+v, want %+v", deps, want)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Errorf("Dependencies[%d] = %+v, want %+v", i, deps[i], want[i])
+		}
+	}
+}
+
+func TestApply_PropertyVersion(t *testing.T) {
+	dir := writePOM(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"com.fasterxml.jackson:jackson-bom":                   {"2.17.1", "2.16.0", "2.15.0"},
+		"com.fasterxml.jackson.core:jackson-databind":         {"2.17.1", "2.16.0", "2.15.0"},
+		"org.apache.commons:commons-lang3":                    {"3.14.0", "3.12.0"},
+		"org.springframework.boot:spring-boot-starter-parent": {"3.3.0", "3.1.0"},
+	}}}
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	// jackson-databind shares the BOM's property and follows its update
+	if len(plan.Updates) != 3 {
+		t.Fatalf("Plan() updates = %+v, want 3", plan.Updates)
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 3 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 3 applied and no errors", result)
+	}
+
+	content, _ := os.ReadFile("pom.xml")
+	got := string(content)
+
+	// The property is updated and both references keep pointing at it
+	if !strings.Contains(got, "<jackson.version>2.17.1</jackson.version>") {
+		t.Errorf("jackson.version property not updated:\n%s", got)
+	}
+	if strings.Count(got, "<version>${jackson.version}</version>") != 2 || strings.Contains(got, "<version>2.17.1</version>") {
+		t.Errorf("property references replaced by a literal:\n%s", got)
+	}
+
+	want := strings.NewReplacer(
+		"<jackson.version>2.15.0</jackson.version>", "<jackson.version>2.17.1</jackson.version>",
+		"<version>3.12.0</version>", "<version>3.14.0</version>",
+		"<version>3.1.0</version>", "<version>3.3.0</version>",
+	).Replace(testPOM)
+	if got != want {
+		t.Errorf("rewritten pom.xml =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDependencies_BOMKinds(t *testing.T) {
+	content := []byte(`<project>
+  <properties>
+    <netty.version>4.1.100</netty.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>io.netty</groupId>
+        <artifactId>netty-bom</artifactId>
+        <version>${netty.version}</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+      <dependency>
+        <groupId>com.example</groupId>
+        <artifactId>not-a-bom</artifactId>
+        <version>1.0.0</version>
+        <scope>import</scope>
+      </dependency>
+      <dependency>
+        <groupId>com.example</groupId>
+        <artifactId>platform-bom</artifactId>
+        <version>2.0.0</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>io.netty</groupId>
+      <artifactId>netty-handler</artifactId>
+      <version>${netty.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>org.example</groupId>
+      <artifactId>netty-addon</artifactId>
+      <version>${netty.version}</version>
+    </dependency>
+  </dependencies>
+</project>
+`)
+
+	parsed, err := parsePOM(content)
+	if err != nil {
+		t.Fatalf("parsePOM() error = %v", err)
+	}
+	deps := parsed.dependencies(content)
+
+	// Only a pom imported with scope import is a BOM, whatever its name
+	types := make(map[string]string)
+	for _, dep := range deps {
+		types[dep.Name] = dep.Type
+	}
+	wantTypes := map[string]string{
+		"io.netty:netty-bom":       depTypeBOM,
+		"com.example:not-a-bom":    depTypeManaged,
+		"com.example:platform-bom": depTypeManaged,
+		"io.netty:netty-handler":   depTypeDirect,
+		"org.example:netty-addon":  depTypeDirect,
+	}
+	for name, want := range wantTypes {
+		if types[name] != want {
+			t.Errorf("%s type = %q, want %q", name, types[name], want)
+		}
+	}
+
+	// The BOM manages netty-handler, but not an artifact of another group
+	// that happens to reuse its property
+	var boms []engine.Dependency
+	for _, dep := range deps {
+		if dep.Type == depTypeBOM {
+			boms = append(boms, dep)
+		}
+	}
+	for _, dep := range deps {
+		want := dep.Name == "io.netty:netty-handler"
+		if got := managedByBOM(dep, boms); dep.Type != depTypeBOM && got != want {
+			t.Errorf("managedByBOM(%s) = %v, want %v", dep.Name, got, want)
+		}
+	}
+}
+
+func TestPlan_BOMImport(t *testing.T) {
+	content := []byte(`<project>
+  <properties>
+    <jackson.version>2.15.0</jackson.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>org.springframework.boot</groupId>
+        <artifactId>spring-boot-dependencies</artifactId>
+        <version>3.1.0</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+      <dependency>
+        <groupId>com.fasterxml.jackson</groupId>
+        <artifactId>jackson-bom</artifactId>
+        <version>${jackson.version}</version>
+        <type>pom</type>
+        <scope>import</scope>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+  <dependencies>
+    <dependency>
+      <groupId>org.springframework.boot</groupId>
+      <artifactId>spring-boot-starter-web</artifactId>
+    </dependency>
+    <dependency>
+      <groupId>com.fasterxml.jackson.core</groupId>
+      <artifactId>jackson-databind</artifactId>
+      <version>${jackson.version}</version>
+    </dependency>
+  </dependencies>
+</project>
+`)
+
+	parsed, err := parsePOM(content)
+	if err != nil {
+		t.Fatalf("parsePOM() error = %v", err)
+	}
+	manifest := &engine.Manifest{Path: "pom.xml", Type: integrationName, Dependencies: parsed.dependencies(content), Content: content}
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"org.springframework.boot:spring-boot-dependencies": {"3.3.0", "3.1.0"},
+		"org.springframework.boot:spring-boot-starter-web":  {"3.3.0", "3.1.0"},
+		"com.fasterxml.jackson:jackson-bom":                 {"2.17.1", "2.15.0"},
+		"com.fasterxml.jackson.core:jackson-databind":       {"2.17.2", "2.17.1", "2.15.0"},
+	}}}
+	plan, err := integ.Plan(context.Background(), manifest, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// Only the BOMs are bumped; the managed dependencies inherit their versions
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	want := map[string]string{
+		"org.springframework.boot:spring-boot-dependencies": "3.3.0",
+		"com.fasterxml.jackson:jackson-bom":                 "2.17.1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan() updates = %v, want %v", got, want)
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("update of %s = %q, want %q", name, got[name], target)
+		}
+	}
+
+	result, err := integ.Rewrite(context.Background(), plan, content)
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	wantContent := strings.NewReplacer(
+		"<jackson.version>2.15.0</jackson.version>", "<jackson.version>2.17.1</jackson.version>",
+		"<version>3.1.0</version>", "<version>3.3.0</version>",
+	).Replace(string(content))
+	if result.Applied != 2 || string(result.Content) != wantContent {
+		t.Errorf("Rewrite() = %d applied, content\n%s\nwant\n%s", result.Applied, result.Content, wantContent)
+	}
+}
+
+func TestRewrite_PropertyConflict(t *testing.T) {
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "pom.xml", Type: integrationName},
+		Updates: []engine.Update{
+			{Dependency: engine.Dependency{Name: "com.fasterxml.jackson:jackson-bom", CurrentVersion: "2.15.0"}, TargetVersion: "2.17.1"},
+			{Dependency: engine.Dependency{Name: "com.fasterxml.jackson.core:jackson-databind", CurrentVersion: "2.15.0"}, TargetVersion: "2.16.0"},
+		},
+	}
+
+	result, err := New().Rewrite(context.Background(), plan, []byte(testPOM))
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "property jackson.version is 2.17.1") {
+		t.Errorf("Rewrite() = %+v, want the second update of the shared property reported", result)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: "pom.xml", Content: []byte(testPOM)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: "pom.xml", Content: []byte("<project><dependencies></project>")}); err == nil {
+		t.Error("Validate() expected error for malformed XML")
+	}
+}
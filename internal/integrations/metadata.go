@@ -25,6 +25,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -63,7 +65,42 @@ type RegistryMetadata struct {
 	Version      string                        `yaml:"version"`
 }
 
-var cachedMetadata *RegistryMetadata
+var (
+	metadataMu     sync.Mutex
+	cachedMetadata *RegistryMetadata
+	// cachedStamp identifies the file version cachedMetadata was read from.
+	// It is zero when the cache was not loaded from a file.
+	cachedStamp fileStamp
+)
+
+// fileStamp records the path, modification time and size of a loaded file.
+type fileStamp struct {
+	modTime time.Time
+	path    string
+	size    int64
+}
+
+// current reports whether the file at s.path still has the recorded
+// modification time and size. A stamp without a path is always current.
+func (s fileStamp) current() bool {
+	if s.path == "" {
+		return true
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false
+	}
+	return info.ModTime().Equal(s.modTime) && info.Size() == s.size
+}
+
+// ResetMetadataCache discards the cached integrations.yaml so the next
+// LoadMetadata reads it again.
+func ResetMetadataCache() {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+	cachedMetadata = nil
+	cachedStamp = fileStamp{}
+}
 
 // findRegistryFile searches for integrations.yaml in the current directory and parent directories.
 func findRegistryFile() (string, error) {
@@ -104,8 +141,14 @@ func findRegistryFile() (string, error) {
 }
 
 // LoadMetadata loads integration metadata from the integrations.yaml file.
+// The result is cached and reloaded when the file's modification time or size
+// changes, so a long-running process picks up edits. It is safe for
+// concurrent use.
 func LoadMetadata() (*RegistryMetadata, error) {
-	if cachedMetadata != nil {
+	metadataMu.Lock()
+	defer metadataMu.Unlock()
+
+	if cachedMetadata != nil && cachedStamp.current() {
 		return cachedMetadata, nil
 	}
 
@@ -120,6 +163,16 @@ func LoadMetadata() (*RegistryMetadata, error) {
 		return nil, fmt.Errorf("invalid registry path: %w", err)
 	}
 
+	// Stat before reading so a write racing the read triggers another reload
+	registryPath, err = filepath.Abs(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolve integrations.yaml path: %w", err)
+	}
+	info, err := os.Stat(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading integrations.yaml: %w", err)
+	}
+
 	data, err := os.ReadFile(registryPath) // #nosec G304 - path is validated above
 	if err != nil {
 		return nil, fmt.Errorf("reading integrations.yaml: %w", err)
@@ -131,6 +184,7 @@ func LoadMetadata() (*RegistryMetadata, error) {
 	}
 
 	cachedMetadata = &metadata
+	cachedStamp = fileStamp{path: registryPath, modTime: info.ModTime(), size: info.Size()}
 	return &metadata, nil
 }
 
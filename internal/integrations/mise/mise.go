@@ -59,6 +59,11 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of mise configuration files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"mise.toml", ".mise.toml"}
+}
+
 // Detect scans for mise.toml and .mise.toml files.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
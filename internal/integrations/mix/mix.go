@@ -0,0 +1,672 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package mix implements the Mix integration for Elixir dependencies.
+// It detects mix.exs files, reads the {:app, "requirement"} tuples of the
+// deps function, queries hex.pm for the newest version each requirement
+// allows, and rewrites the requirement string in place, keeping its operator
+// and precision and the rest of the Elixir source. Dependencies fetched with
+// :git, :github or :path are skipped. When a mix.lock is present, the entries
+// of updated packages are refreshed alongside mix.exs.
+package mix
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("mix", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "mix"
+	manifestName    = "mix.exs"
+	lockfileName    = "mix.lock"
+)
+
+var (
+	// depsStartPattern matches the head of the deps function, capturing its
+	// indentation so the matching "end" can be found.
+	depsStartPattern = regexp.MustCompile(`(?m)^([ \t]*)defp?\s+deps(?:\(\))?\s+do[ \t]*$`)
+
+	// tuplePattern matches a dependency tuple with a requirement, such as
+	// {:phoenix, "~> 1.7"} or {:credo, "~> 1.7", only: [:dev, :test]},
+	// capturing the app, the requirement and the options.
+	tuplePattern = regexp.MustCompile(`\{\s*:([a-z_][a-zA-Z0-9_]*)\s*,\s*"([^"]*)"\s*(,[^{}]*)?\}`)
+
+	// externalSourcePattern matches options that fetch a dependency from
+	// somewhere other than the public hex.pm repository.
+	externalSourcePattern = regexp.MustCompile(`\b(?:git|github|path|in_umbrella|organization|repo)\s*:`)
+
+	// hexAliasPattern matches the hex option naming the package of an app.
+	hexAliasPattern = regexp.MustCompile(`\bhex\s*:\s*:"?([a-z_][a-zA-Z0-9_]*)`)
+
+	// onlyPattern matches the only option restricting a dependency to environments.
+	onlyPattern = regexp.MustCompile(`\bonly\s*:\s*(\[[^\]]*\]|:\w+)`)
+
+	// clausePattern splits a single-clause requirement such as "~> 1.7" into
+	// operator and version.
+	clausePattern = regexp.MustCompile(`^(~>|==|>=|<=|>|<)?\s*(\d+\.\d+(?:\.\d+)?(?:-[0-9A-Za-z.-]+)?)$`)
+
+	// rangePattern matches a requirement with a lower and an upper bound,
+	// such as ">= 1.0.0 and < 2.0.0".
+	rangePattern = regexp.MustCompile(`^(>=?\s*(\d+\.\d+\.\d+))(\s+and\s+)(<=?\s*\d+\.\d+\.\d+)$`)
+
+	// lockEntryPattern matches a hex entry of mix.lock, capturing the app,
+	// package and locked version.
+	lockEntryPattern = regexp.MustCompile(`(?m)^\s*"([a-z_][a-zA-Z0-9_]*)":\s*\{:hex,\s*:"?([a-z_][a-zA-Z0-9_]*)"?,\s*"([^"]+)"`)
+)
+
+// requirement is a dependency tuple read from mix.exs, with the byte offsets
+// of its requirement string (without quotes).
+type requirement struct {
+	app         string
+	pkg         string
+	requirement string
+	depType     string
+	line        int
+	start       int
+	end         int
+}
+
+// lockedPackage is a hex entry of mix.lock.
+type lockedPackage struct {
+	pkg     string
+	version string
+}
+
+// releaseSource fetches the checksums and requirements of a release. It is
+// implemented by the hex datasource and used to refresh mix.lock entries.
+type releaseSource interface {
+	GetRegistryRelease(ctx context.Context, pkg, version string) (*registry.HexRegistryRelease, error)
+}
+
+// Integration implements mix.exs updates.
+type Integration struct {
+	ds       datasource.Datasource
+	releases releaseSource
+}
+
+// New creates a new Mix integration.
+func New() *Integration {
+	ds, err := datasource.Get("hex")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewHexDatasource()
+	}
+	releases, _ := ds.(releaseSource) //nolint:errcheck // optional capability
+	return &Integration{
+		ds:       datasource.Cached(ds),
+		releases: releases,
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Mix projects.
+func (i *Integration) SupportedFiles() []string {
+	return []string{manifestName}
+}
+
+// Detect finds mix.exs files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// deps/ holds fetched dependencies and _build/ compiled ones
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && (info.Name() == "deps" || info.Name() == "_build")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if info.Name() != manifestName || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		metadata := map[string]any{}
+		var locked map[string]lockedPackage
+		lockPath := filepath.Join(filepath.Dir(path), lockfileName)
+		if lockContent, err := os.ReadFile(lockPath); err == nil { // #nosec G304 - sibling of a validated path
+			locked = parseLockfile(string(lockContent))
+			metadata["lockfile"] = filepath.Join(filepath.Dir(relPath), lockfileName)
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: dependencies(parseDeps(content), locked),
+			Content:      content,
+			Metadata:     metadata,
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parseDeps reads the hex dependency tuples of the deps function in file
+// order. Tuples in comments, tuples without a requirement string and
+// dependencies fetched from another source are skipped.
+func parseDeps(content []byte) []requirement {
+	start := depsStartPattern.FindSubmatchIndex(content)
+	if start == nil {
+		return nil
+	}
+	indent := string(content[start[2]:start[3]])
+	bodyStart := start[1]
+	bodyEnd := len(content)
+	endPattern := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(indent) + `end\b`)
+	if loc := endPattern.FindIndex(content[bodyStart:]); loc != nil {
+		bodyEnd = bodyStart + loc[0]
+	}
+
+	var reqs []requirement
+	for _, m := range tuplePattern.FindAllSubmatchIndex(content[bodyStart:bodyEnd], -1) {
+		for j := range m {
+			if m[j] >= 0 {
+				m[j] += bodyStart
+			}
+		}
+
+		lineStart := bytes.LastIndexByte(content[:m[0]], '\n') + 1
+		if bytes.IndexByte(content[lineStart:m[0]], '#') >= 0 {
+			continue
+		}
+
+		var opts string
+		if m[6] >= 0 {
+			opts = string(content[m[6]:m[7]])
+		}
+		if externalSourcePattern.MatchString(opts) {
+			continue
+		}
+
+		app := string(content[m[2]:m[3]])
+		pkg := app
+		if alias := hexAliasPattern.FindStringSubmatch(opts); alias != nil {
+			pkg = alias[1]
+		}
+		depType := "direct"
+		if only := onlyPattern.FindStringSubmatch(opts); only != nil && !strings.Contains(only[1], ":prod") {
+			depType = "dev"
+		}
+
+		reqs = append(reqs, requirement{
+			app:         app,
+			pkg:         pkg,
+			requirement: string(content[m[4]:m[5]]),
+			depType:     depType,
+			line:        bytes.Count(content[:m[0]], []byte("\n")) + 1,
+			start:       m[4],
+			end:         m[5],
+		})
+	}
+	return reqs
+}
+
+// parseLockfile returns the hex entries of mix.lock by app.
+func parseLockfile(content string) map[string]lockedPackage {
+	locked := make(map[string]lockedPackage)
+	for _, m := range lockEntryPattern.FindAllStringSubmatch(content, -1) {
+		locked[m[1]] = lockedPackage{pkg: m[2], version: m[3]}
+	}
+	return locked
+}
+
+// dependencies returns the dependencies of reqs. The current version is the
+// locked version when mix.lock has one, otherwise the version in the
+// requirement.
+func dependencies(reqs []requirement, locked map[string]lockedPackage) []engine.Dependency {
+	deps := make([]engine.Dependency, 0, len(reqs))
+	for _, req := range reqs {
+		current := currentVersion(req.requirement)
+		if lock, ok := locked[req.app]; ok {
+			current = lock.version
+		}
+		deps = append(deps, engine.Dependency{
+			Name:           req.app,
+			CurrentVersion: current,
+			Constraint:     req.requirement,
+			Type:           req.depType,
+			Registry:       "hex",
+			Line:           req.line,
+		})
+	}
+	return deps
+}
+
+// currentVersion returns the lowest version a requirement names: the version
+// of a single clause or the lower bound of a range. Other requirements are
+// returned unchanged.
+func currentVersion(req string) string {
+	req = strings.TrimSpace(req)
+	if m := clausePattern.FindStringSubmatch(req); m != nil {
+		return m[2]
+	}
+	if m := rangePattern.FindStringSubmatch(req); m != nil {
+		return m[2]
+	}
+	return req
+}
+
+// constraintFor returns the semver constraint a Mix requirement expresses.
+// "~>" has the same meaning in Elixir as in uptool's constraints, "==" and a
+// bare version are exact, and "and" joins clauses. ok is false for "or" and
+// other requirements uptool does not rewrite.
+func constraintFor(req string) (string, bool) {
+	req = strings.TrimSpace(req)
+	if m := clausePattern.FindStringSubmatch(req); m != nil {
+		switch m[1] {
+		case "", "==":
+			return m[2], true
+		case "~>":
+			return "~> " + m[2], true
+		}
+		return m[1] + " " + m[2], true
+	}
+	if m := rangePattern.FindStringSubmatch(req); m != nil {
+		return m[1] + ", " + m[4], true
+	}
+	return "", false
+}
+
+// newRequirement rewrites a requirement to target, keeping its operator,
+// spacing and precision, so "~> 1.7" becomes "~> 1.8" rather than a pinned
+// "1.8.2". A range moves its lower bound and keeps the upper one.
+func newRequirement(req, target string) string {
+	req = strings.TrimSpace(req)
+	target = version.Normalize(integrationName, target)
+
+	if m := clausePattern.FindStringSubmatch(req); m != nil {
+		return strings.TrimSuffix(req, m[2]) + truncate(target, m[2])
+	}
+	if m := rangePattern.FindStringSubmatch(req); m != nil {
+		return strings.TrimSuffix(m[1], m[2]) + target + m[3] + m[4]
+	}
+	return target
+}
+
+// truncate cuts target to the number of components in like, so "1.8.2" with
+// "1.7" becomes "1.8". Prereleases are kept whole.
+func truncate(target, like string) string {
+	parts := strings.Split(target, ".")
+	if n := len(strings.Split(like, ".")); n < len(parts) && !strings.Contains(target, "-") {
+		return strings.Join(parts[:n], ".")
+	}
+	return target
+}
+
+// requirementAllows reports whether a Mix requirement allows version.
+func requirementAllows(req, version string) bool {
+	constraint, ok := constraintFor(req)
+	return ok && resolve.ParseConstraint(constraint).Allows(version)
+}
+
+// HasLockfile reports whether a mix.exs has a mix.lock next to it.
+func (i *Integration) HasLockfile(manifest *engine.Manifest) bool {
+	_, ok := manifest.Metadata["lockfile"].(string)
+	return ok
+}
+
+// Plan determines available updates for Mix dependencies.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	packages := make(map[string]string)
+	for _, req := range parseDeps(manifest.Content) {
+		packages[req.app] = req.pkg
+	}
+
+	for _, dep := range manifest.Dependencies {
+		// "or" requirements can't be rewritten to a version
+		constraint, ok := constraintFor(dep.Constraint)
+		if !ok {
+			continue
+		}
+
+		pkg := dep.Name
+		if p, ok := packages[dep.Name]; ok {
+			pkg = p
+		}
+
+		availableVersions, err := i.ds.GetVersions(ctx, pkg)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, pkg)
+			if latestErr != nil {
+				// Skip packages we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			constraint,
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+		targetVersion = version.Normalize(integrationName, targetVersion)
+
+		// Without a lockfile to bump, a target the requirement already covers
+		// at its precision changes nothing
+		if newRequirement(dep.Constraint, targetVersion) == strings.TrimSpace(dep.Constraint) && !i.HasLockfile(manifest) {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			ChangelogURL:  fmt.Sprintf("https://hex.pm/packages/%s", pkg),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "mix_requirement_rewrite",
+	}, nil
+}
+
+// Apply executes the update plan by rewriting requirements in mix.exs and,
+// when present, the entries of updated packages in mix.lock. In a
+// lockfile-only run mix.exs is left untouched and only updates its
+// requirements already allow are written to mix.lock; with no-lockfile
+// mix.lock is left untouched.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 || (!plan.WritesManifest() && !i.HasLockfile(plan.Manifest)) {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read mix.exs: %w", err)
+	}
+
+	newContent, applied, errs := rewriteMix(plan, oldContent)
+
+	if !bytes.Equal(newContent, oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+			return nil, fmt.Errorf("write mix.exs: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(manifestName, string(oldContent), string(newContent))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	result := &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: integrations.DependencyNames(applied),
+		Applied:        len(applied),
+		ManifestDiff:   diff,
+	}
+
+	if lockPath, ok := plan.Manifest.Metadata["lockfile"].(string); ok && len(applied) > 0 && plan.WritesLockfile() {
+		lockDiff, lockErrs, err := i.updateLockfile(ctx, lockPath, applied)
+		if err != nil {
+			return nil, err
+		}
+		result.LockfileDiff = lockDiff
+		errs = append(errs, lockErrs...)
+	}
+
+	result.Failed = len(errs)
+	result.Errors = errs
+	return result, nil
+}
+
+// Rewrite applies the plan's updates to mix.exs content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewriteMix(plan, content)
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: integrations.DependencyNames(applied),
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewriteMix rewrites the requirements of the plan's updates in content and
+// returns the updates that apply. Only the requirement strings change. With
+// lockfile-only, content is returned unchanged and only updates its
+// requirements already allow apply.
+func rewriteMix(plan *engine.UpdatePlan, content []byte) (newContent []byte, applied []*engine.Update, errs []string) {
+	reqs := parseDeps(content)
+
+	type edit struct {
+		start, end int
+		value      string
+	}
+	var edits []edit
+
+	for j := range plan.Updates {
+		update := plan.Updates[j]
+		dep := update.Dependency
+		update.TargetVersion = version.Normalize(integrationName, update.TargetVersion)
+
+		if !plan.WritesManifest() {
+			if !requirementAllows(dep.Constraint, update.TargetVersion) {
+				errs = append(errs, fmt.Sprintf("%s: requirement %q does not allow %s; lockfile-only leaves mix.exs unchanged",
+					dep.Name, dep.Constraint, update.TargetVersion))
+				continue
+			}
+			applied = append(applied, &update)
+			continue
+		}
+
+		req := newRequirement(dep.Constraint, update.TargetVersion)
+		if err := resolve.ValidateConstraint(integrationName, req); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dep.Name, err))
+			continue
+		}
+
+		found := false
+		for _, r := range reqs {
+			if r.app == dep.Name && r.requirement == dep.Constraint {
+				edits = append(edits, edit{start: r.start, end: r.end, value: req})
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: requirement %q not found in mix.exs", dep.Name, dep.Constraint))
+			continue
+		}
+		applied = append(applied, &update)
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+	newContent = content
+	for _, e := range edits {
+		newContent = append(append(append([]byte{}, newContent[:e.start]...), e.value...), newContent[e.end:]...)
+	}
+
+	return newContent, applied, errs
+}
+
+// updateLockfile rewrites the mix.lock entries of the applied updates: the
+// version, both checksums and the package's own requirements, as published
+// in the hex.pm repository. Entries that cannot be refreshed are left as they
+// are and reported so the user knows to run `mix deps.update`.
+func (i *Integration) updateLockfile(ctx context.Context, lockPath string, updates []*engine.Update) (string, []string, error) {
+	if err := integrations.ValidateFilePath(lockPath); err != nil {
+		return "", nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(lockPath) // #nosec G304 - path is validated above
+	if err != nil {
+		return "", nil, fmt.Errorf("read mix.lock: %w", err)
+	}
+
+	content := string(oldContent)
+	locked := parseLockfile(content)
+	var errs []string
+
+	for _, update := range updates {
+		app := update.Dependency.Name
+		// Dependencies not locked yet are added by the next mix deps.get
+		lock, ok := locked[app]
+		if !ok {
+			continue
+		}
+
+		entry := regexp.MustCompile(`(?m)^(\s*"` + regexp.QuoteMeta(app) + `":\s*\{:hex,\s*:"?` + regexp.QuoteMeta(lock.pkg) +
+			`"?,\s*)"[^"]*",(\s*)"[0-9a-f]*",(\s*\[[^\]]*\],\s*)\[.*\](,\s*"[^"]+",\s*)"[0-9a-f]*"(\},?)$`)
+		m := entry.FindStringSubmatchIndex(content)
+		if m == nil {
+			errs = append(errs, fmt.Sprintf("%s: mix.lock entry not recognized; run mix deps.update %s", app, app))
+			continue
+		}
+
+		release, err := i.registryRelease(ctx, lock.pkg, update.TargetVersion)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: mix.lock not refreshed (%v); run mix deps.update %s", app, err, app))
+			continue
+		}
+
+		line := content[m[2]:m[3]] + `"` + release.Version + `",` + content[m[4]:m[5]] + `"` + release.InnerChecksum + `",` +
+			content[m[6]:m[7]] + lockDependencies(release.Dependencies) + content[m[8]:m[9]] + `"` + release.OuterChecksum + `"` + content[m[10]:m[11]]
+		content = content[:m[0]] + line + content[m[1]:]
+	}
+
+	if content == string(oldContent) {
+		return "", errs, nil
+	}
+
+	if err := os.WriteFile(lockPath, []byte(content), 0o600); err != nil {
+		return "", nil, fmt.Errorf("write mix.lock: %w", err)
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(lockfileName, string(oldContent), content)
+	if err != nil {
+		return "", nil, fmt.Errorf("generate diff: %w", err)
+	}
+	return diff, errs, nil
+}
+
+// registryRelease fetches a release with its checksums from the package repository.
+func (i *Integration) registryRelease(ctx context.Context, pkg, version string) (*registry.HexRegistryRelease, error) {
+	if i.releases == nil {
+		return nil, fmt.Errorf("hex repository unavailable")
+	}
+	release, err := i.releases.GetRegistryRelease(ctx, pkg, version)
+	if err != nil {
+		return nil, err
+	}
+	if release.InnerChecksum == "" || release.OuterChecksum == "" {
+		return nil, fmt.Errorf("release %s has no checksums", version)
+	}
+	return release, nil
+}
+
+// lockDependencies renders the requirements of a release as mix.lock lists
+// them, ordered by app, e.g.
+// [{:jason, "~> 1.0", [hex: :jason, repo: "hexpm", optional: true]}].
+func lockDependencies(deps []registry.HexDependency) string {
+	items := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		app := dep.App
+		if app == "" {
+			app = dep.Package
+		}
+		repo := dep.Repository
+		if repo == "" {
+			repo = "hexpm"
+		}
+		items = append(items, fmt.Sprintf(`{:%s, %q, [hex: :%s, repo: %q, optional: %t]}`,
+			app, dep.Requirement, dep.Package, repo, dep.Optional))
+	}
+	sort.Strings(items)
+	return "[" + strings.Join(items, ", ") + "]"
+}
+
+// Validate checks that every requirement in the deps function is well formed.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	for _, req := range parseDeps(manifest.Content) {
+		if err := resolve.ValidateConstraint(integrationName, req.requirement); err != nil {
+			return fmt.Errorf("dependency %s: %w", req.app, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,324 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package mix
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+const testMixExs = `defmodule MyApp.MixProject do
+  use Mix.Project
+
+  def project do
+    [
+      app: :my_app,
+      version: "0.1.0",
+      elixir: "~> 1.14",
+      deps: deps()
+    ]
+  end
+
+  defp deps do
+    [
+      {:phoenix, "~> 1.7.10"},
+      {:jason, "~> 1.4"},
+      {:plug_cowboy, github: "elixir-plug/plug_cowboy"},
+      {:ecto_sql, "~> 3.10", git: "https://github.com/elixir-ecto/ecto_sql.git"},
+      {:local_lib, "~> 0.1", path: "../local_lib"},
+      {:credo, "~> 1.6",
+       only: [:dev, :test], runtime: false},
+      {:tesla_fork, ">= 1.4.0 and < 2.0.0", hex: :tesla},
+      # {:commented, "~> 1.0"},
+      {:ecto, "~> 3.0 or ~> 4.0"}
+    ]
+  end
+end
+`
+
+const testMixLock = `%{
+  "credo": {:hex, :credo, "1.6.7", "aaaa", [:mix], [{:bunt, "~> 0.2.1", [hex: :bunt, repo: "hexpm", optional: false]}], "hexpm", "bbbb"},
+  "ecto_sql": {:git, "https://github.com/elixir-ecto/ecto_sql.git", "0123abc", []},
+  "jason": {:hex, :jason, "1.4.1", "cccc", [:mix], [{:decimal, "~> 1.0 or ~> 2.0", [hex: :decimal, repo: "hexpm", optional: true]}], "hexpm", "dddd"},
+  "phoenix": {:hex, :phoenix, "1.7.10", "eeee", [:mix], [{:jason, "~> 1.0", [hex: :jason, repo: "hexpm", optional: true]}, {:plug, "~> 1.14", [hex: :plug, repo: "hexpm", optional: false]}], "hexpm", "ffff"},
+}
+`
+
+// mockDatasource implements datasource.Datasource and releaseSource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+	releases map[string]*registry.HexRegistryRelease
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func (m *mockDatasource) GetRegistryRelease(ctx context.Context, pkg, version string) (*registry.HexRegistryRelease, error) {
+	if release, ok := m.releases[pkg+"@"+version]; ok {
+		return release, nil
+	}
+	return nil, context.Canceled
+}
+
+// hexpm mirrors what hex.pm publishes for the test packages.
+func hexpm() *mockDatasource {
+	return &mockDatasource{
+		versions: map[string][]string{
+			"phoenix": {"1.8.0", "1.7.12", "1.7.10"},
+			"jason":   {"1.4.4", "1.4.1"},
+			"credo":   {"1.7.5", "1.6.7"},
+			"tesla":   {"2.0.0", "1.9.0", "1.4.0"},
+		},
+		releases: map[string]*registry.HexRegistryRelease{
+			"phoenix@1.7.12": {Version: "1.7.12", InnerChecksum: "1111", OuterChecksum: "2222", Dependencies: []registry.HexDependency{
+				{Package: "plug", Requirement: "~> 1.14"},
+				{Package: "jason", Requirement: "~> 1.0", Optional: true},
+			}},
+			"jason@1.4.4": {Version: "1.4.4", InnerChecksum: "3333", OuterChecksum: "4444", Dependencies: []registry.HexDependency{
+				{Package: "decimal", Requirement: "~> 1.0 or ~> 2.0", Optional: true},
+			}},
+		},
+	}
+}
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestName), []byte(testMixExs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, lockfileName), []byte(testMixLock), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fetched := filepath.Join(dir, "deps", "phoenix")
+	if err := os.MkdirAll(fetched, 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(fetched, manifestName), []byte(testMixExs), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeProject(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != manifestName {
+		t.Fatalf("Detect() = %+v, want only mix.exs (deps/ skipped)", manifests)
+	}
+	if lock, _ := manifests[0].Metadata["lockfile"].(string); lock != lockfileName {
+		t.Errorf("Metadata[lockfile] = %q, want %q", lock, lockfileName)
+	}
+
+	// git, github and path dependencies and comments are skipped; locked
+	// versions are current
+	want := []engine.Dependency{
+		{Name: "phoenix", CurrentVersion: "1.7.10", Constraint: "~> 1.7.10", Type: "direct", Registry: "hex", Line: 15},
+		{Name: "jason", CurrentVersion: "1.4.1", Constraint: "~> 1.4", Type: "direct", Registry: "hex", Line: 16},
+		{Name: "credo", CurrentVersion: "1.6.7", Constraint: "~> 1.6", Type: "dev", Registry: "hex", Line: 20},
+		{Name: "tesla_fork", CurrentVersion: "1.4.0", Constraint: ">= 1.4.0 and < 2.0.0", Type: "direct", Registry: "hex", Line: 22},
+		{Name: "ecto", CurrentVersion: "~> 3.0 or ~> 4.0", Constraint: "~> 3.0 or ~> 4.0", Type: "direct", Registry: "hex", Line: 24},
+	}
+	deps := manifests[0].Dependencies
+	if len(deps) != len(want) {
+		t.Fatalf("Dependencies = %+v, want %+v", deps, want)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Errorf("Dependencies[%d] = %+v, want %+v", i, deps[i], want[i])
+		}
+	}
+}
+
+func TestNewRequirement(t *testing.T) {
+	tests := []struct {
+		req    string
+		target string
+		want   string
+	}{
+		{req: "~> 1.7", target: "1.8.2", want: "~> 1.8"},
+		{req: "~> 1.7.10", target: "1.7.12", want: "~> 1.7.12"},
+		{req: "~>1.7", target: "1.8.2", want: "~>1.8"},
+		{req: ">= 0.0.0", target: "1.8.2", want: ">= 1.8.2"},
+		{req: "== 1.2.3", target: "1.2.4", want: "== 1.2.4"},
+		{req: "1.2.3", target: "1.2.4", want: "1.2.4"},
+		{req: ">= 1.4.0 and < 2.0.0", target: "1.9.0", want: ">= 1.9.0 and < 2.0.0"},
+		{req: "~> 1.7", target: "2.0.0-rc.1", want: "~> 2.0.0-rc.1"},
+	}
+
+	for _, tt := range tests {
+		if got := newRequirement(tt.req, tt.target); got != tt.want {
+			t.Errorf("newRequirement(%q, %q) = %q, want %q", tt.req, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeProject(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: hexpm()}
+	integ.releases = integ.ds.(releaseSource)
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// The newest version each requirement allows; "or" is skipped
+	want := map[string]string{
+		"phoenix":    "1.7.12",
+		"jason":      "1.4.4",
+		"credo":      "1.7.5",
+		"tesla_fork": "1.9.0",
+	}
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan() updates = %v, want %v", got, want)
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("Plan() target for %s = %q, want %q", name, got[name], target)
+		}
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 4 {
+		t.Errorf("Applied = %d, want 4", result.Applied)
+	}
+	// credo 1.7.5 is not in the mocked repository
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "credo: mix.lock not refreshed") {
+		t.Errorf("Errors = %v, want credo reported as not refreshed", result.Errors)
+	}
+
+	// Requirements keep their operator and precision; nothing else changes
+	content, _ := os.ReadFile(manifestName)
+	wantMix := strings.NewReplacer(
+		`"~> 1.7.10"`, `"~> 1.7.12"`,
+		`{:credo, "~> 1.6"`, `{:credo, "~> 1.7"`,
+		`">= 1.4.0 and < 2.0.0"`, `">= 1.9.0 and < 2.0.0"`,
+	).Replace(testMixExs)
+	if string(content) != wantMix {
+		t.Errorf("rewritten mix.exs =\n%s\nwant\n%s", content, wantMix)
+	}
+
+	lock, _ := os.ReadFile(lockfileName)
+	wantLock := strings.NewReplacer(
+		`"phoenix": {:hex, :phoenix, "1.7.10", "eeee", [:mix], [{:jason, "~> 1.0", [hex: :jason, repo: "hexpm", optional: true]}, {:plug, "~> 1.14", [hex: :plug, repo: "hexpm", optional: false]}], "hexpm", "ffff"},`,
+		`"phoenix": {:hex, :phoenix, "1.7.12", "1111", [:mix], [{:jason, "~> 1.0", [hex: :jason, repo: "hexpm", optional: true]}, {:plug, "~> 1.14", [hex: :plug, repo: "hexpm", optional: false]}], "hexpm", "2222"},`,
+		`"jason": {:hex, :jason, "1.4.1", "cccc",`, `"jason": {:hex, :jason, "1.4.4", "3333",`,
+		`"hexpm", "dddd"}`, `"hexpm", "4444"}`,
+	).Replace(testMixLock)
+	if string(lock) != wantLock {
+		t.Errorf("rewritten mix.lock =\n%s\nwant\n%s", lock, wantLock)
+	}
+	if result.LockfileDiff == "" {
+		t.Error("LockfileDiff is empty")
+	}
+}
+
+func TestApply_LockfileOnly(t *testing.T) {
+	dir := writeProject(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: hexpm()}
+	integ.releases = integ.ds.(releaseSource)
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	plan := &engine.UpdatePlan{
+		Manifest: manifests[0],
+		Lockfile: engine.LockfileOnly,
+		Updates: []engine.Update{
+			{Dependency: manifests[0].Dependencies[1], TargetVersion: "1.4.4"},
+			{Dependency: manifests[0].Dependencies[0], TargetVersion: "1.8.0"},
+		},
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], `phoenix: requirement "~> 1.7.10" does not allow 1.8.0`) {
+		t.Errorf("Apply() = %+v, want jason applied and phoenix rejected", result)
+	}
+
+	content, _ := os.ReadFile(manifestName)
+	if string(content) != testMixExs {
+		t.Error("lockfile-only run rewrote mix.exs")
+	}
+	lock, _ := os.ReadFile(lockfileName)
+	if !strings.Contains(string(lock), `"jason": {:hex, :jason, "1.4.4", "3333",`) {
+		t.Errorf("mix.lock not updated for jason:\n%s", lock)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: manifestName, Content: []byte(testMixExs)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	bad := strings.Replace(testMixExs, `"~> 1.4"`, `">= 1.4"`, 1)
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: manifestName, Content: []byte(bad)}); err == nil {
+		t.Error("Validate() expected error for a requirement without a patch version")
+	}
+}
@@ -0,0 +1,527 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package nix implements the Nix flakes integration.
+// It detects flake.lock files, resolves the latest commit on the ref tracked
+// by each GitHub and GitLab input, and bumps the locked rev, either directly
+// or by running 'nix flake lock --update-input' when asked to fix the lockfile.
+package nix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/rewrite"
+)
+
+func init() {
+	integrations.Register("nix", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "nix"
+	lockfileName    = "flake.lock"
+	flakeName       = "flake.nix"
+)
+
+// commitResolver resolves a ref of a repository ("owner/repo") to the commit
+// SHA it points at. "HEAD" resolves the default branch.
+type commitResolver interface {
+	GetCommitSHA(ctx context.Context, pkg, ref string) (string, error)
+}
+
+// runner executes a command in dir and returns its combined output.
+type runner func(ctx context.Context, dir, name string, args ...string) ([]byte, error)
+
+// Integration implements Nix flake input updates.
+type Integration struct {
+	github commitResolver
+	gitlab func(host string) commitResolver
+	run    runner
+}
+
+// New creates a new Nix flakes integration.
+func New() *Integration {
+	ds, err := datasource.Get("github-releases")
+	if err != nil {
+		ds = datasource.NewGitHubDatasource()
+	}
+	github, _ := ds.(commitResolver)
+	return &Integration{
+		github: github,
+		gitlab: func(host string) commitResolver {
+			return registry.NewGitLabClient(host, os.Getenv("GITLAB_TOKEN"))
+		},
+		run: runCommand,
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of flake lockfiles.
+func (i *Integration) SupportedFiles() []string {
+	return []string{lockfileName}
+}
+
+// FlakeLock represents the structure of flake.lock.
+type FlakeLock struct {
+	Nodes   map[string]FlakeNode `json:"nodes"`
+	Root    string               `json:"root"`
+	Version int                  `json:"version"`
+}
+
+// FlakeNode is one input in the lock graph. Inputs map an input name to a
+// node key, or to a path of input names when the input follows another.
+type FlakeNode struct {
+	Inputs   map[string]json.RawMessage `json:"inputs,omitempty"`
+	Locked   *FlakeRef                  `json:"locked,omitempty"`
+	Original *FlakeRef                  `json:"original,omitempty"`
+}
+
+// FlakeRef is a locked or original flake reference.
+type FlakeRef struct {
+	Type    string `json:"type"`
+	Owner   string `json:"owner,omitempty"`
+	Repo    string `json:"repo,omitempty"`
+	Host    string `json:"host,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Rev     string `json:"rev,omitempty"`
+	NarHash string `json:"narHash,omitempty"`
+}
+
+// project returns the "owner/repo" path of a GitHub or GitLab reference.
+// GitLab subgroups are escaped in flake references (group%2Fsub).
+func (r *FlakeRef) project() string {
+	owner, err := url.PathUnescape(r.Owner)
+	if err != nil {
+		owner = r.Owner
+	}
+	return owner + "/" + r.Repo
+}
+
+// parseLockfile decodes flake.lock content.
+func parseLockfile(content []byte) (*FlakeLock, error) {
+	var lock FlakeLock
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", lockfileName, err)
+	}
+	if lock.Root == "" {
+		lock.Root = "root"
+	}
+	if _, ok := lock.Nodes[lock.Root]; !ok {
+		return nil, fmt.Errorf("parse %s: missing root node %q", lockfileName, lock.Root)
+	}
+	return &lock, nil
+}
+
+// inputNode returns the node key a direct input of the root flake is locked
+// as. Inputs that follow another input are not locked on their own.
+func (l *FlakeLock) inputNode(name string) (string, bool) {
+	raw, ok := l.Nodes[l.Root].Inputs[name]
+	if !ok {
+		return "", false
+	}
+	var key string
+	if err := json.Unmarshal(raw, &key); err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// Detect finds flake.lock files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip hidden directories and build results
+		if info.IsDir() && path != repoRoot && (strings.HasPrefix(info.Name(), ".") || info.Name() == "result") {
+			return filepath.SkipDir
+		}
+
+		if info.IsDir() || info.Name() != lockfileName {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		// Validate path for security
+		if err := integrations.ValidateFilePath(path); err != nil {
+			return err
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		lock, err := parseLockfile(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", relPath, err)
+		}
+
+		metadata := map[string]any{
+			"lock_version": lock.Version,
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(path), flakeName)); err == nil {
+			metadata["flake"] = filepath.Join(filepath.Dir(relPath), flakeName)
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: extractDependencies(lock),
+			Content:      content,
+			Metadata:     metadata,
+		})
+
+		return nil
+	})
+
+	return manifests, err
+}
+
+// extractDependencies returns the direct GitHub and GitLab inputs of the root
+// flake that are locked to a rev. Inputs pinned to a rev in flake.nix itself,
+// and non-git inputs (path, tarball, indirect, ...), are skipped.
+func extractDependencies(lock *FlakeLock) []engine.Dependency {
+	names := make([]string, 0, len(lock.Nodes[lock.Root].Inputs))
+	for name := range lock.Nodes[lock.Root].Inputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	deps := make([]engine.Dependency, 0, len(names))
+	for _, name := range names {
+		key, ok := lock.inputNode(name)
+		if !ok {
+			continue
+		}
+		node := lock.Nodes[key]
+		if node.Locked == nil || node.Locked.Rev == "" || !isGitHost(node.Locked.Type) {
+			continue
+		}
+		if node.Original != nil && node.Original.Rev != "" {
+			continue
+		}
+
+		var ref string
+		if node.Original != nil {
+			ref = node.Original.Ref
+		}
+
+		deps = append(deps, engine.Dependency{
+			Name:           name,
+			CurrentVersion: node.Locked.Rev,
+			Constraint:     ref,
+			Type:           "direct",
+			Registry:       node.Locked.Type,
+		})
+	}
+
+	return deps
+}
+
+func isGitHost(refType string) bool {
+	return refType == "github" || refType == "gitlab"
+}
+
+// Plan resolves the latest commit on the ref each input tracks (the default
+// branch when it tracks none) and proposes a rev bump when it moved.
+//
+// A commit bump carries no semantic version, so it is reported as a minor
+// update: patch-only policies leave flake inputs alone, and update: none
+// disables them.
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	plan := &engine.UpdatePlan{
+		Manifest: manifest,
+		Strategy: "lockfile_rewrite",
+	}
+
+	if level := planCtx.EffectiveUpdateLevel(); level == "none" || level == string(engine.ImpactPatch) {
+		return plan, nil
+	}
+
+	lock, err := parseLockfile(manifest.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dep := range manifest.Dependencies {
+		key, ok := lock.inputNode(dep.Name)
+		if !ok {
+			continue
+		}
+		locked := lock.Nodes[key].Locked
+
+		resolver := i.resolver(locked)
+		if resolver == nil {
+			continue
+		}
+
+		ref := dep.Constraint
+		if ref == "" {
+			ref = "HEAD"
+		}
+
+		sha, err := resolver.GetCommitSHA(ctx, locked.project(), ref)
+		if err != nil || sha == "" || strings.EqualFold(sha, dep.CurrentVersion) {
+			continue
+		}
+
+		plan.Updates = append(plan.Updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: sha,
+			Impact:        string(engine.ImpactMinor),
+			ChangelogURL:  compareURL(locked, dep.CurrentVersion, sha),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return plan, nil
+}
+
+// resolver returns the commit resolver for a locked reference's host.
+func (i *Integration) resolver(locked *FlakeRef) commitResolver {
+	switch locked.Type {
+	case "github":
+		return i.github
+	case "gitlab":
+		if i.gitlab == nil {
+			return nil
+		}
+		return i.gitlab(locked.Host)
+	default:
+		return nil
+	}
+}
+
+// compareURL links the commits between two revs of a GitHub or GitLab input.
+func compareURL(locked *FlakeRef, from, to string) string {
+	switch locked.Type {
+	case "github":
+		return fmt.Sprintf("https://github.com/%s/compare/%s...%s", locked.project(), from, to)
+	case "gitlab":
+		host := locked.Host
+		if host == "" {
+			host = "gitlab.com"
+		}
+		return fmt.Sprintf("https://%s/%s/-/compare/%s...%s", host, locked.project(), from, to)
+	default:
+		return ""
+	}
+}
+
+// HasLockfile reports that flake.lock, the manifest itself, is always a
+// lockfile, so flake inputs are updated in lockfile-only runs too.
+func (i *Integration) HasLockfile(manifest *engine.Manifest) bool {
+	return true
+}
+
+// Apply bumps the locked rev of each updated input. With FixLockfile set it
+// runs 'nix flake lock --update-input' so nix records the new rev together
+// with its narHash and lastModified; otherwise the rev is rewritten directly
+// and the now stale narHash and lastModified are dropped, to be filled in by
+// the next 'nix flake lock'.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 || !plan.WritesLockfile() {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", lockfileName, err)
+	}
+
+	var applied, errs []string
+	if plan.FixLockfile {
+		applied, errs = i.updateInputs(ctx, plan)
+	} else {
+		rewritten, err := i.Rewrite(ctx, plan, oldContent)
+		if err != nil {
+			return nil, err
+		}
+		applied, errs = rewritten.AppliedUpdates, rewritten.Errors
+		if len(applied) > 0 {
+			if err := os.WriteFile(plan.Manifest.Path, rewritten.Content, 0o600); err != nil {
+				return nil, fmt.Errorf("write %s: %w", lockfileName, err)
+			}
+		}
+	}
+
+	newContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read updated %s: %w", lockfileName, err)
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(lockfileName, string(oldContent), string(newContent))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite bumps the locked revs of the plan's inputs in flake.lock content in
+// memory. The fix-lockfile path through 'nix flake lock' cannot be previewed.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs, err := bumpRevs(content, plan.Updates)
+	if err != nil {
+		return nil, err
+	}
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// updateInputs runs 'nix flake lock --update-input' for every updated input.
+// Nix locks the input to the newest commit on its ref at the time it runs.
+// It returns the names of the inputs it updated.
+func (i *Integration) updateInputs(ctx context.Context, plan *engine.UpdatePlan) (applied, errs []string) {
+	dir := filepath.Dir(plan.Manifest.Path)
+	for _, update := range plan.Updates {
+		output, err := i.run(ctx, dir, "nix", "flake", "lock", "--update-input", update.Dependency.Name)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: nix flake lock failed: %v\n%s", update.Dependency.Name, err, output))
+			continue
+		}
+		applied = append(applied, update.Dependency.Name)
+	}
+	return applied, errs
+}
+
+// runCommand runs a command in dir and returns its combined output.
+func runCommand(ctx context.Context, dir, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, fmt.Errorf("%s command not found", name)
+	}
+	cmd := exec.CommandContext(ctx, name, args...) // #nosec G204 - arguments are input names from flake.lock, not a shell
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+// bumpRevs sets the locked rev of each updated input and drops the hashes
+// that described the old rev. Nix writes flake.lock as sorted, two-space
+// indented JSON, which encoding/json reproduces. It returns the names of the
+// inputs it updated.
+func bumpRevs(content []byte, updates []engine.Update) ([]byte, []string, []string, error) {
+	lock, err := parseLockfile(content)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	var doc map[string]any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("parse %s: %w", lockfileName, err)
+	}
+	nodes, _ := doc["nodes"].(map[string]any)
+
+	var applied, errs []string
+	for _, update := range updates {
+		key, ok := lock.inputNode(update.Dependency.Name)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: input not found in %s", update.Dependency.Name, lockfileName))
+			continue
+		}
+		node, _ := nodes[key].(map[string]any)
+		locked, _ := node["locked"].(map[string]any)
+		if locked == nil || locked["rev"] != update.Dependency.CurrentVersion {
+			errs = append(errs, fmt.Sprintf("%s: locked rev is no longer %s", update.Dependency.Name, update.Dependency.CurrentVersion))
+			continue
+		}
+
+		locked["rev"] = update.TargetVersion
+		delete(locked, "narHash")
+		delete(locked, "lastModified")
+		applied = append(applied, update.Dependency.Name)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return nil, nil, nil, fmt.Errorf("encode %s: %w", lockfileName, err)
+	}
+
+	return buf.Bytes(), applied, errs, nil
+}
+
+// Validate checks that flake.lock is well-formed.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	content := manifest.Content
+	if len(content) == 0 {
+		if err := integrations.ValidateFilePath(manifest.Path); err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		data, err := os.ReadFile(manifest.Path) // #nosec G304 - path is validated above
+		if err != nil {
+			return fmt.Errorf("read %s: %w", lockfileName, err)
+		}
+		content = data
+	}
+
+	_, err := parseLockfile(content)
+	return err
+}
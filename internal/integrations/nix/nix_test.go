@@ -0,0 +1,389 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package nix
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const (
+	oldRev = "a3a3dda3bacf61e8a39258a0ed9c924eeca8e293"
+	newRev = "5e4fbfb6b3de1aa2872b76d49fafc942626e2add"
+	utils  = "11707dc2f618dd54ca8739b309ec4fc024de578b"
+)
+
+const testLockfile = `{
+  "nodes": {
+    "flake-utils": {
+      "inputs": {
+        "systems": "systems"
+      },
+      "locked": {
+        "lastModified": 1710146030,
+        "narHash": "sha256-SZ5L6eA7HJ/nmkzGG7/ISclqe6oZdOZTNoesiInkXPQ=",
+        "owner": "numtide",
+        "repo": "flake-utils",
+        "rev": "` + utils + `",
+        "type": "github"
+      },
+      "original": {
+        "owner": "numtide",
+        "repo": "flake-utils",
+        "rev": "` + utils + `",
+        "type": "github"
+      }
+    },
+    "home-manager": {
+      "inputs": {
+        "nixpkgs": [
+          "nixpkgs"
+        ]
+      },
+      "locked": {
+        "lastModified": 1720000000,
+        "narHash": "sha256-AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+        "owner": "group%2Fsub",
+        "repo": "home-manager",
+        "rev": "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+        "type": "gitlab"
+      },
+      "original": {
+        "owner": "group%2Fsub",
+        "repo": "home-manager",
+        "type": "gitlab"
+      }
+    },
+    "local": {
+      "locked": {
+        "lastModified": 1,
+        "narHash": "sha256-BBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBBB=",
+        "path": "./local",
+        "type": "path"
+      },
+      "original": {
+        "path": "./local",
+        "type": "path"
+      }
+    },
+    "nixpkgs": {
+      "locked": {
+        "lastModified": 1720535198,
+        "narHash": "sha256-zwVvxrdIzralnSbcpghA92tWu2DV2lwv89xZc8MTrbg=",
+        "owner": "NixOS",
+        "repo": "nixpkgs",
+        "rev": "` + oldRev + `",
+        "type": "github"
+      },
+      "original": {
+        "owner": "NixOS",
+        "ref": "nixos-unstable",
+        "repo": "nixpkgs",
+        "type": "github"
+      }
+    },
+    "root": {
+      "inputs": {
+        "flake-utils": "flake-utils",
+        "home-manager": "home-manager",
+        "local": "local",
+        "nixpkgs": "nixpkgs",
+        "pkgs-follow": [
+          "home-manager",
+          "nixpkgs"
+        ]
+      }
+    },
+    "systems": {
+      "locked": {
+        "lastModified": 1681028828,
+        "narHash": "sha256-Vy1rq5AaRuLzOxct8nz4T6wlgyUR7zLU309k9mcEqrk=",
+        "owner": "nix-systems",
+        "repo": "default",
+        "rev": "da67096a3b9bf56a91d16901293e51ba5b49a27e",
+        "type": "github"
+      },
+      "original": {
+        "owner": "nix-systems",
+        "repo": "default",
+        "type": "github"
+      }
+    }
+  },
+  "root": "root",
+  "version": 7
+}
+`
+
+// mockCommits is a test double for commitResolver keyed by "owner/repo@ref".
+type mockCommits map[string]string
+
+func (m mockCommits) GetCommitSHA(ctx context.Context, pkg, ref string) (string, error) {
+	if sha, ok := m[pkg+"@"+ref]; ok {
+		return sha, nil
+	}
+	return "", context.Canceled
+}
+
+func writeFlake(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "flake.nix"), []byte("{ outputs = _: { }; }\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, lockfileName), []byte(testLockfile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeFlake(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+
+	m := manifests[0]
+	if m.Path != lockfileName || m.Type != integrationName {
+		t.Errorf("manifest = %s (%s), want %s (%s)", m.Path, m.Type, lockfileName, integrationName)
+	}
+	if m.Metadata["flake"] != "flake.nix" {
+		t.Errorf("Metadata[flake] = %v, want flake.nix", m.Metadata["flake"])
+	}
+
+	// flake-utils is pinned to a rev in flake.nix, local is a path input,
+	// pkgs-follow follows another input and systems is transitive
+	want := []engine.Dependency{
+		{Name: "home-manager", CurrentVersion: "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c", Type: "direct", Registry: "gitlab"},
+		{Name: "nixpkgs", CurrentVersion: oldRev, Constraint: "nixos-unstable", Type: "direct", Registry: "github"},
+	}
+	if !reflect.DeepEqual(m.Dependencies, want) {
+		t.Errorf("Dependencies = %+v, want %+v", m.Dependencies, want)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	dir := writeFlake(t)
+
+	integ := &Integration{
+		github: mockCommits{"NixOS/nixpkgs@nixos-unstable": newRev},
+		gitlab: func(host string) commitResolver {
+			// home-manager tracks the default branch and has not moved
+			return mockCommits{"group/sub/home-manager@HEAD": "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c"}
+		},
+	}
+
+	manifests, err := integ.Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 1 {
+		t.Fatalf("Plan() returned %d updates, want 1: %+v", len(plan.Updates), plan.Updates)
+	}
+
+	update := plan.Updates[0]
+	if update.Dependency.Name != "nixpkgs" || update.TargetVersion != newRev {
+		t.Errorf("update = %s -> %s, want nixpkgs -> %s", update.Dependency.Name, update.TargetVersion, newRev)
+	}
+	if update.Impact != string(engine.ImpactMinor) {
+		t.Errorf("Impact = %q, want minor", update.Impact)
+	}
+	if want := "https://github.com/NixOS/nixpkgs/compare/" + oldRev + "..." + newRev; update.ChangelogURL != want {
+		t.Errorf("ChangelogURL = %q, want %q", update.ChangelogURL, want)
+	}
+
+	patchOnly := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "patch"}}
+	plan, err = integ.Plan(context.Background(), manifests[0], patchOnly)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 0 {
+		t.Errorf("Plan() with update: patch returned %d updates, want 0", len(plan.Updates))
+	}
+}
+
+func nixpkgsUpdate() engine.Update {
+	return engine.Update{
+		Dependency: engine.Dependency{
+			Name:           "nixpkgs",
+			CurrentVersion: oldRev,
+			Constraint:     "nixos-unstable",
+			Type:           "direct",
+			Registry:       "github",
+		},
+		TargetVersion: newRev,
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeFlake(t)
+	lockPath := filepath.Join(dir, lockfileName)
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: lockPath, Type: integrationName},
+		Updates:  []engine.Update{nixpkgsUpdate()},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || result.Failed != 0 {
+		t.Errorf("Apply() applied %d, failed %d (%v); want 1, 0", result.Applied, result.Failed, result.Errors)
+	}
+
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lock map[string]map[string]map[string]any
+	if err := json.Unmarshal(content, &struct {
+		Nodes *map[string]map[string]map[string]any `json:"nodes"`
+	}{Nodes: &lock}); err != nil {
+		t.Fatalf("updated flake.lock is not valid JSON: %v", err)
+	}
+
+	locked := lock["nixpkgs"]["locked"]
+	if locked["rev"] != newRev {
+		t.Errorf("nixpkgs rev = %v, want %s", locked["rev"], newRev)
+	}
+	if _, ok := locked["narHash"]; ok {
+		t.Error("stale narHash should be dropped")
+	}
+	if _, ok := locked["lastModified"]; ok {
+		t.Error("stale lastModified should be dropped")
+	}
+
+	// Everything else is written back exactly as nix formats it
+	want := strings.Replace(testLockfile, `"lastModified": 1720535198,
+        "narHash": "sha256-zwVvxrdIzralnSbcpghA92tWu2DV2lwv89xZc8MTrbg=",
+        `, "", 1)
+	want = strings.Replace(want, oldRev, newRev, 1)
+	if string(content) != want {
+		t.Errorf("flake.lock =\n%s\nwant\n%s", content, want)
+	}
+	if !strings.Contains(result.ManifestDiff, "+        \"rev\": \""+newRev+"\",") {
+		t.Errorf("ManifestDiff missing rev bump:\n%s", result.ManifestDiff)
+	}
+
+	// A second apply finds the rev has already moved
+	result, err = New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("re-Apply() applied %d, failed %d; want 0, 1", result.Applied, result.Failed)
+	}
+}
+
+func TestApply_FixLockfile(t *testing.T) {
+	dir := writeFlake(t)
+	lockPath := filepath.Join(dir, lockfileName)
+
+	var calls [][]string
+	integ := &Integration{
+		run: func(ctx context.Context, runDir, name string, args ...string) ([]byte, error) {
+			if runDir != dir {
+				t.Errorf("nix ran in %s, want %s", runDir, dir)
+			}
+			calls = append(calls, append([]string{name}, args...))
+			return nil, os.WriteFile(lockPath, []byte(strings.Replace(testLockfile, oldRev, newRev, 1)), 0o600)
+		},
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest:    &engine.Manifest{Path: lockPath, Type: integrationName},
+		Updates:     []engine.Update{nixpkgsUpdate()},
+		FixLockfile: true,
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 {
+		t.Errorf("Apply() applied %d, want 1 (%v)", result.Applied, result.Errors)
+	}
+
+	want := [][]string{{"nix", "flake", "lock", "--update-input", "nixpkgs"}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("commands = %v, want %v", calls, want)
+	}
+	if !strings.Contains(result.ManifestDiff, newRev) {
+		t.Errorf("ManifestDiff missing new rev:\n%s", result.ManifestDiff)
+	}
+}
+
+func TestApply_NoLockfile(t *testing.T) {
+	dir := writeFlake(t)
+	lockPath := filepath.Join(dir, lockfileName)
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: lockPath, Type: integrationName},
+		Updates:  []engine.Update{nixpkgsUpdate()},
+		Lockfile: engine.LockfileSkip,
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 {
+		t.Errorf("Apply() with --no-lockfile applied %d, want 0", result.Applied)
+	}
+
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testLockfile {
+		t.Error("flake.lock was modified with --no-lockfile")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+
+	if err := integ.Validate(context.Background(), &engine.Manifest{Content: []byte(testLockfile)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := integ.Validate(context.Background(), &engine.Manifest{Content: []byte(`{"nodes": {}}`)}); err == nil {
+		t.Error("Validate() without root node should return error")
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+const lockfileName = "package-lock.json"
+
+// lockfileOnlyNote explains why an update cannot be applied without changing
+// package.json, or returns "" when its range already allows the target.
+func lockfileOnlyNote(update *engine.Update) string {
+	dep := update.Dependency
+	if dep.Type == depTypePackageManager {
+		return fmt.Sprintf("%s: packageManager is pinned in package.json; lockfile-only leaves it unchanged", dep.Name)
+	}
+
+	target := version.Normalize("npm", update.TargetVersion)
+	constraint, err := semver.NewConstraint(dep.Constraint)
+	if err != nil {
+		return fmt.Sprintf("%s: range %q cannot be checked against %s; lockfile-only leaves package.json unchanged",
+			dep.Name, dep.Constraint, target)
+	}
+	v, err := semver.NewVersion(target)
+	if err != nil || !constraint.Check(v) {
+		return fmt.Sprintf("%s: range %q does not allow %s; lockfile-only leaves package.json unchanged",
+			dep.Name, dep.Constraint, target)
+	}
+	return ""
+}
+
+// updateLockfile rewrites the package-lock.json entries of the applied
+// updates: the version, resolved tarball URL and integrity hash of
+// "packages" → "node_modules/<name>" (lockfile v2 and v3) and of the legacy
+// "dependencies" → "<name>" (v1 and v2). When package.json was rewritten, the
+// root package's copy of the range is updated to match. Entries are edited in
+// place so the rest of the file keeps npm's formatting. Entries that cannot be
+// refreshed are left as they are and reported, with the names of their
+// dependencies in stale, so the user knows to run `npm install`.
+func (i *Integration) updateLockfile(ctx context.Context, lockPath string, updates []*engine.Update, writesManifest bool) (diff string, stale, errs []string, err error) {
+	if err := integrations.ValidateFilePath(lockPath); err != nil {
+		return "", nil, nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(lockPath) // #nosec G304 - path is validated above
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("read package-lock.json: %w", err)
+	}
+
+	content := oldContent
+
+	for _, update := range updates {
+		dep := update.Dependency
+		if dep.Type == depTypePackageManager {
+			continue
+		}
+		target := version.Normalize("npm", update.TargetVersion)
+
+		var locked [][]string
+		for _, entry := range [][]string{{"packages", "node_modules/" + dep.Name}, {"dependencies", dep.Name}} {
+			if _, _, ok := jsonValueSpan(content, append(entry, "version")...); ok {
+				locked = append(locked, entry)
+			}
+		}
+		// Dependencies not locked yet are added by the next npm install
+		if len(locked) == 0 {
+			continue
+		}
+
+		dist, err := i.dist(ctx, dep.Name, target)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: package-lock.json not refreshed (%v); run npm install %s@%s",
+				dep.Name, err, dep.Name, target))
+			stale = append(stale, dep.Name)
+			continue
+		}
+
+		for _, entry := range locked {
+			content = setJSONString(content, target, append(entry, "version")...)
+			content = setJSONString(content, dist.Tarball, append(entry, "resolved")...)
+			content = setJSONString(content, dist.Integrity, append(entry, "integrity")...)
+		}
+		if section, ok := dependencySections[dep.Type]; ok && writesManifest {
+			content = setJSONString(content, versionWithPrefix(update), "packages", "", section, dep.Name)
+		}
+	}
+
+	if bytes.Equal(content, oldContent) {
+		return "", stale, errs, nil
+	}
+
+	if err := os.WriteFile(lockPath, content, 0o600); err != nil {
+		return "", nil, nil, fmt.Errorf("write package-lock.json: %w", err)
+	}
+
+	diff, err = rewrite.GenerateUnifiedDiff(lockfileName, string(oldContent), string(content))
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("generate diff: %w", err)
+	}
+	return diff, stale, errs, nil
+}
+
+// dist fetches the tarball URL and integrity string of a published version.
+func (i *Integration) dist(ctx context.Context, pkg, version string) (*registry.NPMDist, error) {
+	if i.dists == nil {
+		return nil, fmt.Errorf("npm registry unavailable")
+	}
+	dist, err := i.dists.GetDist(ctx, pkg, version)
+	if err != nil {
+		return nil, err
+	}
+	if dist.Integrity == "" {
+		return nil, fmt.Errorf("version %s has no integrity hash", version)
+	}
+	return dist, nil
+}
+
+// setJSONString replaces the value at path in JSON content with a string,
+// leaving every other byte as it is. Content without a value at path is
+// returned unchanged.
+func setJSONString(content []byte, value string, path ...string) []byte {
+	start, end, ok := jsonValueSpan(content, path...)
+	if !ok {
+		return content
+	}
+
+	return splice(content, start, end, encodeJSONString(value))
+}
+
+// jsonValueSpan returns the byte offsets of the value reached by following
+// object keys along path from the top-level object of JSON content.
+func jsonValueSpan(content []byte, path ...string) (start, end int, ok bool) {
+	dec := json.NewDecoder(bytes.NewReader(content))
+	for depth, key := range path {
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return 0, 0, false
+		}
+
+		found := false
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return 0, 0, false
+			}
+			if name, _ := tok.(string); name == key { //nolint:errcheck // object keys are always strings
+				found = true
+				break
+			}
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return 0, 0, false
+			}
+		}
+		if !found {
+			return 0, 0, false
+		}
+
+		if depth == len(path)-1 {
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return 0, 0, false
+			}
+			end = int(dec.InputOffset())
+			return end - len(bytes.TrimSpace(value)), end, true
+		}
+	}
+	return 0, 0, false
+}
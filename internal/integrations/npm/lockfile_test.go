@@ -0,0 +1,252 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+const (
+	oldIntegrity = "sha512-WFN4ZRE6Yd8gP7WzT6eOS2Ehl92aBOkYgTmbE3Dm2Yl/ffnD1BtD2Swg0h9uB3RHGBhhDVUFULmIMSJvgtoMjA=="
+	newIntegrity = "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg=="
+	newTarball   = "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz"
+)
+
+// lockfileFixture is a lockfile v2 with both the "packages" and legacy
+// "dependencies" entries for lodash.
+const lockfileFixture = `{
+  "name": "test-app",
+  "version": "1.0.0",
+  "lockfileVersion": 2,
+  "requires": true,
+  "packages": {
+    "": {
+      "name": "test-app",
+      "version": "1.0.0",
+      "dependencies": {
+        "lodash": "^4.17.0"
+      }
+    },
+    "node_modules/lodash": {
+      "version": "4.17.20",
+      "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz",
+      "integrity": "` + oldIntegrity + `"
+    }
+  },
+  "dependencies": {
+    "lodash": {
+      "version": "4.17.20",
+      "resolved": "https://registry.npmjs.org/lodash/-/lodash-4.17.20.tgz",
+      "integrity": "` + oldIntegrity + `"
+    }
+  }
+}
+`
+
+// lockfileTestDir writes package.json and package-lock.json into a temporary
+// directory and returns their paths.
+func lockfileTestDir(t *testing.T, constraint string) (pkgPath, lockPath string) {
+	t.Helper()
+	dir := t.TempDir()
+	pkgPath = filepath.Join(dir, "package.json")
+	lockPath = filepath.Join(dir, lockfileName)
+	pkg := "{\n  \"name\": \"test-app\",\n  \"dependencies\": {\n    \"lodash\": \"" + constraint + "\"\n  }\n}\n"
+	if err := os.WriteFile(pkgPath, []byte(pkg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(lockPath, []byte(lockfileFixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return pkgPath, lockPath
+}
+
+func lockfilePlan(pkgPath, lockPath, constraint, target string, mode engine.LockfileMode) *engine.UpdatePlan {
+	return &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:     pkgPath,
+			Metadata: map[string]interface{}{"lockfile": lockPath},
+		},
+		Updates: []engine.Update{{
+			Dependency:    engine.Dependency{Name: "lodash", CurrentVersion: constraint, Constraint: constraint, Type: "direct"},
+			TargetVersion: target,
+		}},
+		Lockfile: mode,
+	}
+}
+
+func readLockfile(t *testing.T, lockPath string) map[string]interface{} {
+	t.Helper()
+	content, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lock map[string]interface{}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		t.Fatalf("package-lock.json is not valid JSON: %v\n%s", err, content)
+	}
+	return lock
+}
+
+func lockEntry(lock map[string]interface{}, path ...string) map[string]interface{} {
+	entry := lock
+	for _, key := range path {
+		entry, _ = entry[key].(map[string]interface{})
+	}
+	return entry
+}
+
+func TestApply_LockfileOnly(t *testing.T) {
+	pkgPath, lockPath := lockfileTestDir(t, "^4.17.0")
+	originalPkg, _ := os.ReadFile(pkgPath)
+
+	mock := &mockDatasource{dists: map[string]*registry.NPMDist{
+		"lodash@4.17.21": {Tarball: newTarball, Integrity: newIntegrity},
+	}}
+	integ := &Integration{ds: mock, dists: mock}
+
+	result, err := integ.Apply(context.Background(), lockfilePlan(pkgPath, lockPath, "^4.17.0", "4.17.21", engine.LockfileOnly))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || result.Failed != 0 {
+		t.Errorf("Apply() applied/failed = %d/%d, want 1/0 (errors: %v)", result.Applied, result.Failed, result.Errors)
+	}
+	if result.ManifestDiff != "" {
+		t.Errorf("Apply() ManifestDiff = %q, want none in lockfile-only mode", result.ManifestDiff)
+	}
+	if !strings.Contains(result.LockfileDiff, "+      \"integrity\": \""+newIntegrity+"\"") {
+		t.Errorf("Apply() LockfileDiff missing refreshed integrity:\n%s", result.LockfileDiff)
+	}
+
+	if pkg, _ := os.ReadFile(pkgPath); string(pkg) != string(originalPkg) {
+		t.Errorf("package.json changed in lockfile-only mode:\n%s", pkg)
+	}
+
+	lock := readLockfile(t, lockPath)
+	for _, path := range [][]string{{"packages", "node_modules/lodash"}, {"dependencies", "lodash"}} {
+		entry := lockEntry(lock, path...)
+		if entry["version"] != "4.17.21" || entry["resolved"] != newTarball || entry["integrity"] != newIntegrity {
+			t.Errorf("%v = %v, want version, resolved and integrity of 4.17.21", path, entry)
+		}
+	}
+	if got := lockEntry(lock, "packages", "", "dependencies")["lodash"]; got != "^4.17.0" {
+		t.Errorf("root range = %v, want ^4.17.0 unchanged", got)
+	}
+}
+
+func TestApply_LockfileOnly_RangeExcludesTarget(t *testing.T) {
+	pkgPath, lockPath := lockfileTestDir(t, "~4.16.0")
+
+	mock := &mockDatasource{dists: map[string]*registry.NPMDist{
+		"lodash@4.17.21": {Tarball: newTarball, Integrity: newIntegrity},
+	}}
+	integ := &Integration{ds: mock, dists: mock}
+
+	result, err := integ.Apply(context.Background(), lockfilePlan(pkgPath, lockPath, "~4.16.0", "4.17.21", engine.LockfileOnly))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "does not allow 4.17.21") {
+		t.Errorf("Apply() = applied %d, errors %v; want the update refused", result.Applied, result.Errors)
+	}
+	if lock, _ := os.ReadFile(lockPath); string(lock) != lockfileFixture {
+		t.Errorf("package-lock.json changed:\n%s", lock)
+	}
+}
+
+func TestApply_LockfileOnly_DistUnavailable(t *testing.T) {
+	pkgPath, lockPath := lockfileTestDir(t, "^4.17.0")
+	mock := &mockDatasource{}
+	integ := &Integration{ds: mock, dists: mock}
+
+	result, err := integ.Apply(context.Background(), lockfilePlan(pkgPath, lockPath, "^4.17.0", "4.17.21", engine.LockfileOnly))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 || len(result.Errors) != 1 {
+		t.Errorf("Apply() applied/failed = %d/%d, errors %v; want the update failed", result.Applied, result.Failed, result.Errors)
+	}
+	if lock, _ := os.ReadFile(lockPath); string(lock) != lockfileFixture {
+		t.Errorf("package-lock.json changed without registry data:\n%s", lock)
+	}
+}
+
+func TestApply_ManifestAndLockfile(t *testing.T) {
+	pkgPath, lockPath := lockfileTestDir(t, "^4.17.0")
+	mock := &mockDatasource{dists: map[string]*registry.NPMDist{
+		"lodash@5.0.0": {Tarball: "https://registry.npmjs.org/lodash/-/lodash-5.0.0.tgz", Integrity: newIntegrity},
+	}}
+	integ := &Integration{ds: mock, dists: mock}
+
+	result, err := integ.Apply(context.Background(), lockfilePlan(pkgPath, lockPath, "^4.17.0", "5.0.0", engine.LockfileDefault))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 || result.ManifestDiff == "" || result.LockfileDiff == "" {
+		t.Errorf("Apply() = %+v, want package.json and package-lock.json rewritten", result)
+	}
+
+	lock := readLockfile(t, lockPath)
+	if got := lockEntry(lock, "packages", "", "dependencies")["lodash"]; got != "^5.0.0" {
+		t.Errorf("root range = %v, want ^5.0.0", got)
+	}
+	if entry := lockEntry(lock, "packages", "node_modules/lodash"); entry["integrity"] != newIntegrity {
+		t.Errorf("integrity = %v, want %v", entry["integrity"], newIntegrity)
+	}
+
+	// With no-lockfile only package.json changes
+	pkgPath, lockPath = lockfileTestDir(t, "^4.17.0")
+	result, err = integ.Apply(context.Background(), lockfilePlan(pkgPath, lockPath, "^4.17.0", "5.0.0", engine.LockfileSkip))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.ManifestDiff == "" || result.LockfileDiff != "" {
+		t.Errorf("Apply() with no-lockfile = %+v, want only package.json rewritten", result)
+	}
+}
+
+func TestSetJSONString(t *testing.T) {
+	content := []byte("{\n  \"a\": {\"b\": \"old\", \"c\": [1, {\"b\": 2}]},\n  \"b\" :  \"x\"\n}\n")
+
+	got := setJSONString(content, "new/&value", "a", "b")
+	want := "{\n  \"a\": {\"b\": \"new/&value\", \"c\": [1, {\"b\": 2}]},\n  \"b\" :  \"x\"\n}\n"
+	if string(got) != want {
+		t.Errorf("setJSONString(a.b) = %q, want %q", got, want)
+	}
+
+	got = setJSONString(content, "y", "b")
+	want = "{\n  \"a\": {\"b\": \"old\", \"c\": [1, {\"b\": 2}]},\n  \"b\" :  \"y\"\n}\n"
+	if string(got) != want {
+		t.Errorf("setJSONString(b) = %q, want %q", got, want)
+	}
+
+	if got := setJSONString(content, "z", "a", "missing"); string(got) != string(content) {
+		t.Errorf("setJSONString(a.missing) = %q, want content unchanged", got)
+	}
+}
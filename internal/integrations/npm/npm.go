@@ -21,14 +21,22 @@
 // Package npm implements the npm integration for updating package.json dependencies.
 // It detects package.json files, queries the npm registry for version updates,
 // and rewrites dependency versions while preserving constraint prefixes (^, ~, >=).
+// A package-lock.json next to package.json is kept in step with it.
 package npm
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1" // #nosec G505 - Corepack accepts sha1 pins; not used for security decisions here
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Masterminds/semver/v3"
@@ -36,7 +44,9 @@ import (
 	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
 	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/version"
 )
 
 func init() {
@@ -45,9 +55,28 @@ func init() {
 	})
 }
 
+// depTypePackageManager marks the package manager pinned by the Corepack
+// "packageManager" field (e.g. "pnpm@8.15.0+sha512.<hex>").
+const depTypePackageManager = "packageManager"
+
+// tarballSource downloads published package tarballs. It is implemented by
+// the npm datasource and used to recompute Corepack packageManager hashes.
+type tarballSource interface {
+	GetTarball(ctx context.Context, pkg, version string) ([]byte, error)
+}
+
+// distSource looks up where a published package version's tarball is and
+// its integrity string. It is implemented by the npm datasource and used to
+// refresh package-lock.json entries.
+type distSource interface {
+	GetDist(ctx context.Context, pkg, version string) (*registry.NPMDist, error)
+}
+
 // Integration implements npm package.json updates.
 type Integration struct {
-	ds datasource.Datasource
+	ds       datasource.Datasource
+	tarballs tarballSource
+	dists    distSource
 }
 
 // New creates a new npm integration.
@@ -57,8 +86,12 @@ func New() *Integration {
 		// Fallback to creating a new instance if not registered
 		ds = datasource.NewNPMDatasource()
 	}
+	tarballs, _ := ds.(tarballSource) //nolint:errcheck // optional capability
+	dists, _ := ds.(distSource)       //nolint:errcheck // optional capability
 	return &Integration{
-		ds: ds,
+		ds:       datasource.Cached(ds),
+		tarballs: tarballs,
+		dists:    dists,
 	}
 }
 
@@ -67,19 +100,30 @@ func (i *Integration) Name() string {
 	return "npm"
 }
 
+// SupportedFiles returns the file patterns of package manifests.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"package.json"}
+}
+
 // PackageJSON represents the structure of package.json.
 type PackageJSON struct {
-	Dependencies         map[string]string `json:"dependencies,omitempty"`
-	DevDependencies      map[string]string `json:"devDependencies,omitempty"`
-	PeerDependencies     map[string]string `json:"peerDependencies,omitempty"`
-	OptionalDependencies map[string]string `json:"optionalDependencies,omitempty"`
-	Name                 string            `json:"name,omitempty"`
-	Version              string            `json:"version,omitempty"`
+	Dependencies         map[string]string      `json:"dependencies,omitempty"`
+	DevDependencies      map[string]string      `json:"devDependencies,omitempty"`
+	PeerDependencies     map[string]string      `json:"peerDependencies,omitempty"`
+	OptionalDependencies map[string]string      `json:"optionalDependencies,omitempty"`
+	Overrides            map[string]interface{} `json:"overrides,omitempty"`
+	Name                 string                 `json:"name,omitempty"`
+	Version              string                 `json:"version,omitempty"`
+	PackageManager       string                 `json:"packageManager,omitempty"`
+	Workspaces           json.RawMessage        `json:"workspaces,omitempty"`
 }
 
 // Detect finds package.json files in the repository.
+// Workspace roots (a "workspaces" field) and their members are tagged with a
+// "workspace" metadata entry naming the root package.json.
 func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
 	var manifests []*engine.Manifest
+	roots := make(map[string][]string)
 
 	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -120,15 +164,24 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 			}
 
 			deps := i.extractDependencies(&pkg)
+			setDependencyLines(deps, content)
+
+			metadata := map[string]interface{}{
+				"package_name": pkg.Name,
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), lockfileName)); err == nil {
+				metadata["lockfile"] = filepath.Join(filepath.Dir(relPath), lockfileName)
+			}
+			if patterns := workspacePatterns(pkg.Workspaces); len(patterns) > 0 {
+				roots[relPath] = patterns
+			}
 
 			manifest := &engine.Manifest{
 				Path:         relPath,
 				Type:         "npm",
 				Dependencies: deps,
 				Content:      content,
-				Metadata: map[string]interface{}{
-					"package_name": pkg.Name,
-				},
+				Metadata:     metadata,
 			}
 
 			manifests = append(manifests, manifest)
@@ -136,8 +189,12 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 
 		return nil
 	})
+	if err != nil {
+		return manifests, err
+	}
 
-	return manifests, err
+	groupWorkspaces(manifests, roots)
+	return manifests, nil
 }
 
 // extractDependencies extracts all dependencies from package.json.
@@ -184,9 +241,134 @@ func (i *Integration) extractDependencies(pkg *PackageJSON) []engine.Dependency
 		})
 	}
 
+	if name, version, _, ok := parsePackageManager(pkg.PackageManager); ok {
+		deps = append(deps, engine.Dependency{
+			Name:           name,
+			CurrentVersion: version,
+			Type:           depTypePackageManager,
+			Registry:       "npm",
+		})
+	}
+
 	return deps
 }
 
+// dependencySections maps dependency types to the package.json field that
+// declares them.
+var dependencySections = map[string]string{
+	"direct":              "dependencies",
+	"dev":                 "devDependencies",
+	"peer":                "peerDependencies",
+	"optional":            "optionalDependencies",
+	depTypePackageManager: "packageManager",
+}
+
+// setDependencyLines records the line each dependency is declared on.
+func setDependencyLines(deps []engine.Dependency, content []byte) {
+	lines := dependencyLines(content)
+	for j := range deps {
+		section := dependencySections[deps[j].Type]
+		if deps[j].Type == depTypePackageManager {
+			deps[j].Line = lines[section]
+			continue
+		}
+		deps[j].Line = lines[section+"/"+deps[j].Name]
+	}
+}
+
+// dependencyLines maps "section/name" for every entry of the dependency
+// sections, and the bare "packageManager" key, to its 1-based line in
+// package.json content. Malformed content yields whatever was read so far.
+func dependencyLines(content []byte) map[string]int {
+	lines := make(map[string]int)
+	lineAt := func(offset int64) int {
+		return bytes.Count(content[:offset], []byte("\n")) + 1
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(content))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return lines
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return lines
+		}
+		key, _ := tok.(string) //nolint:errcheck // object keys are always strings
+		keyLine := lineAt(dec.InputOffset())
+
+		if key == "packageManager" {
+			lines[key] = keyLine
+		}
+		if !isDependencySection(key) {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return lines
+			}
+			continue
+		}
+
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+			return lines
+		}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return lines
+			}
+			name, _ := tok.(string) //nolint:errcheck // object keys are always strings
+			lines[key+"/"+name] = lineAt(dec.InputOffset())
+
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return lines
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return lines
+		}
+	}
+
+	return lines
+}
+
+func isDependencySection(key string) bool {
+	switch key {
+	case "dependencies", "devDependencies", "peerDependencies", "optionalDependencies":
+		return true
+	}
+	return false
+}
+
+// parsePackageManager splits a Corepack "packageManager" value of the form
+// name@version[+algorithm.hex]. Values pinning a URL or a non-semver version
+// are not recognized.
+func parsePackageManager(value string) (name, version, hash string, ok bool) {
+	name, rest, found := strings.Cut(value, "@")
+	if !found || name == "" || strings.Contains(rest, "://") {
+		return "", "", "", false
+	}
+	version, hash, _ = strings.Cut(rest, "+")
+	if _, err := semver.StrictNewVersion(version); err != nil {
+		return "", "", "", false
+	}
+	return name, version, hash, true
+}
+
+// packageManagerPackage returns the npm package that publishes a package
+// manager release. Yarn 2+ ships as @yarnpkg/cli-dist rather than yarn,
+// which only carries the 1.x line.
+func packageManagerPackage(name, current string) string {
+	if name != "yarn" {
+		return name
+	}
+	if v, err := semver.NewVersion(current); err == nil && v.Major() >= 2 {
+		return "@yarnpkg/cli-dist"
+	}
+	return name
+}
+
 // Plan determines available updates for npm dependencies.
 // It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
 //
@@ -201,27 +383,42 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 			continue
 		}
 
+		// Skip packages of the same workspace; they are linked, not installed
+		if workspaceDependency(manifest, &dep) {
+			continue
+		}
+
 		// Skip git URLs
 		if strings.Contains(dep.Constraint, "git") || strings.HasPrefix(dep.Constraint, "http") {
 			continue
 		}
 
+		// The packageManager field is an exact pin, so only policy limits
+		// how far it moves
+		pkgName, constraint := dep.Name, dep.Constraint
+		if dep.Type == depTypePackageManager {
+			pkgName, constraint = packageManagerPackage(dep.Name, dep.CurrentVersion), ""
+		}
+
 		// Get all available versions
-		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		availableVersions, err := i.ds.GetVersions(ctx, pkgName)
 		if err != nil {
 			// Fallback: try to get just the latest version
-			latest, latestErr := i.ds.GetLatestVersion(ctx, dep.Name)
+			latest, latestErr := i.ds.GetLatestVersion(ctx, pkgName)
 			if latestErr != nil {
 				// Skip packages that can't be resolved
 				continue
 			}
 			availableVersions = []string{latest}
 		}
+		if !planCtx.EffectiveAllowDeprecated() {
+			availableVersions = datasource.WithoutExcluded(ctx, i.ds, pkgName, availableVersions)
+		}
 
 		// Use policy-aware version selection
 		targetVersion, impact, err := resolve.SelectVersionWithContext(
 			dep.CurrentVersion,
-			dep.Constraint,
+			constraint,
 			availableVersions,
 			planCtx,
 		)
@@ -233,7 +430,7 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 			Dependency:    dep,
 			TargetVersion: targetVersion,
 			Impact:        string(impact),
-			ChangelogURL:  fmt.Sprintf("https://www.npmjs.com/package/%s", dep.Name),
+			ChangelogURL:  fmt.Sprintf("https://www.npmjs.com/package/%s", pkgName),
 			PolicySource:  planCtx.GetPolicySource(),
 		})
 	}
@@ -284,9 +481,19 @@ func (i *Integration) determineImpact(current, target string) string {
 	return "patch"
 }
 
-// Apply executes the update plan by rewriting package.json.
+// HasLockfile reports whether a package.json has a package-lock.json next to it.
+func (i *Integration) HasLockfile(manifest *engine.Manifest) bool {
+	_, ok := manifest.Metadata["lockfile"].(string)
+	return ok
+}
+
+// Apply executes the update plan by rewriting package.json and, when present,
+// the entries of updated packages in package-lock.json. In a lockfile-only
+// run package.json is left untouched and only updates its ranges already
+// allow are written to package-lock.json; with no-lockfile package-lock.json
+// is left untouched.
 func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
-	if len(plan.Updates) == 0 {
+	if len(plan.Updates) == 0 || (!plan.WritesManifest() && !i.HasLockfile(plan.Manifest)) {
 		return &engine.ApplyResult{
 			Manifest: plan.Manifest,
 			Applied:  0,
@@ -306,65 +513,140 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("read package.json: %w", err)
 	}
 
-	var pkg PackageJSON
-	err = json.Unmarshal(content, &pkg)
+	rewritten, applied, err := i.rewrite(ctx, plan, content)
 	if err != nil {
-		return nil, fmt.Errorf("parse package.json: %w", err)
+		return nil, err
+	}
+	if rewritten.Applied == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   rewritten.Failed,
+			Errors:   rewritten.Errors,
+		}, nil
 	}
 
-	oldContent := string(content)
-	applied := 0
+	result := &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		Errors:         rewritten.Errors,
+	}
+
+	if plan.WritesManifest() {
+		if err := os.WriteFile(fullPath, rewritten.Content, 0o600); err != nil {
+			return nil, fmt.Errorf("write package.json: %w", err)
+		}
+		result.ManifestDiff = generateDiff(string(content), string(rewritten.Content))
+	}
+
+	if lockPath, ok := plan.Manifest.Metadata["lockfile"].(string); ok && plan.WritesLockfile() {
+		lockDiff, stale, lockErrs, err := i.updateLockfile(ctx, lockPath, applied, plan.WritesManifest())
+		if err != nil {
+			return nil, err
+		}
+		result.LockfileDiff = lockDiff
+		result.Errors = append(result.Errors, lockErrs...)
+		// Without package.json changes, an update whose lockfile entry was
+		// not refreshed did not apply at all
+		if !plan.WritesManifest() {
+			result.AppliedUpdates = slices.DeleteFunc(result.AppliedUpdates, func(name string) bool {
+				return slices.Contains(stale, name)
+			})
+			result.Applied -= len(stale)
+			result.Failed += len(stale)
+		}
+	}
+
+	return result, nil
+}
+
+// Rewrite applies the plan's updates to package.json content in memory.
+// With lockfile-only, content is returned unchanged and only updates its
+// ranges already allow apply.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	rewritten, _, err := i.rewrite(ctx, plan, content)
+	return rewritten, err
+}
+
+// rewrite implements Rewrite and also returns the updates that apply.
+func (i *Integration) rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, []*engine.Update, error) {
+	var pkg PackageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return nil, nil, fmt.Errorf("parse package.json: %w", err)
+	}
+
+	var applied []*engine.Update
+	var errs []string
 
 	// Apply updates
 	for idx := range plan.Updates {
 		update := &plan.Updates[idx]
+
+		if !plan.WritesManifest() {
+			if note := lockfileOnlyNote(update); note != "" {
+				errs = append(errs, note)
+				continue
+			}
+			applied = append(applied, update)
+			continue
+		}
+
+		// Never write a constraint npm cannot parse
+		if err := resolve.ValidateConstraint(i.Name(), versionWithPrefix(update)); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
+
+		if update.Dependency.Type == depTypePackageManager {
+			ok, note := i.updatePackageManager(ctx, &pkg, update)
+			if note != "" {
+				errs = append(errs, note)
+			}
+			if ok {
+				applied = append(applied, update)
+			}
+			continue
+		}
+
 		if i.updateDependency(&pkg, update) {
-			applied++
+			applied = append(applied, update)
 		}
 	}
 
-	// Write back to package.json with formatting
+	if len(applied) == 0 || !plan.WritesManifest() {
+		return &engine.RewriteResult{
+			Content:        content,
+			AppliedUpdates: integrations.DependencyNames(applied),
+			Applied:        len(applied),
+			Failed:         len(plan.Updates) - len(applied),
+			Errors:         errs,
+		}, applied, nil
+	}
+
+	// Re-encode package.json with formatting
 	newContent, err := json.MarshalIndent(pkg, "", "  ")
 	if err != nil {
-		return nil, fmt.Errorf("marshal package.json: %w", err)
+		return nil, nil, fmt.Errorf("marshal package.json: %w", err)
 	}
 
 	// Add trailing newline
 	newContent = append(newContent, '\n')
 
-	if err := os.WriteFile(fullPath, newContent, 0o600); err != nil {
-		return nil, fmt.Errorf("write package.json: %w", err)
-	}
-
-	// Generate diff
-	diff := generateDiff(oldContent, string(newContent))
-
-	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       len(plan.Updates) - applied,
-		ManifestDiff: diff,
-	}, nil
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: integrations.DependencyNames(applied),
+		Applied:        len(applied),
+		Failed:         len(plan.Updates) - len(applied),
+		Errors:         errs,
+	}, applied, nil
 }
 
 // updateDependency updates a dependency in the package.json structure.
 func (i *Integration) updateDependency(pkg *PackageJSON, update *engine.Update) bool {
 	name := update.Dependency.Name
-	newVersion := update.TargetVersion
-
-	// Preserve constraint prefix (^, ~, >=)
-	prefix := ""
-	oldVersion := update.Dependency.CurrentVersion
-	switch {
-	case strings.HasPrefix(oldVersion, "^"):
-		prefix = "^"
-	case strings.HasPrefix(oldVersion, "~"):
-		prefix = "~"
-	case strings.HasPrefix(oldVersion, ">="):
-		prefix = ">="
-	}
-
-	newVersionWithPrefix := prefix + newVersion
+	newVersionWithPrefix := versionWithPrefix(update)
 
 	// Update in the appropriate section
 	switch update.Dependency.Type {
@@ -401,6 +683,126 @@ func (i *Integration) updateDependency(pkg *PackageJSON, update *engine.Update)
 	return false
 }
 
+// updatePackageManager rewrites the Corepack packageManager pin to the target
+// version. A "+algorithm.hex" hash on the old pin is recomputed from the new
+// release tarball; when that is not possible the hash is dropped and a note
+// is returned, since a stale hash would make Corepack refuse the download.
+func (i *Integration) updatePackageManager(ctx context.Context, pkg *PackageJSON, update *engine.Update) (applied bool, note string) {
+	name, _, oldHash, ok := parsePackageManager(pkg.PackageManager)
+	if !ok || name != update.Dependency.Name {
+		return false, ""
+	}
+
+	target := version.Normalize("npm", update.TargetVersion)
+	pkg.PackageManager = name + "@" + target
+	if oldHash == "" {
+		return true, ""
+	}
+
+	algorithm, _, _ := strings.Cut(oldHash, ".")
+	digest, err := i.packageManagerHash(ctx, name, target, algorithm)
+	if err != nil {
+		return true, fmt.Sprintf("%s: packageManager hash removed (%v); run `corepack use %s@%s` to pin it again",
+			name, err, name, target)
+	}
+	pkg.PackageManager += "+" + algorithm + "." + digest
+	return true, ""
+}
+
+// packageManagerHash returns the hex digest Corepack expects for a package
+// manager release. Corepack hashes the npm tarball for npm, pnpm and yarn 1.x;
+// yarn 2+ is hashed from a bundle served by repo.yarnpkg.com, which is not
+// fetched here.
+func (i *Integration) packageManagerHash(ctx context.Context, name, target, algorithm string) (string, error) {
+	var h hash.Hash
+	switch algorithm {
+	case "sha1":
+		h = sha1.New() // #nosec G401 - matches the algorithm of the existing pin
+	case "sha224":
+		h = sha256.New224()
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported hash algorithm %q", algorithm)
+	}
+
+	pkgName := packageManagerPackage(name, target)
+	if pkgName != name {
+		return "", fmt.Errorf("%s %s is not hashed from the npm tarball", name, target)
+	}
+	if i.tarballs == nil {
+		return "", fmt.Errorf("tarball download not supported")
+	}
+
+	data, err := i.tarballs.GetTarball(ctx, pkgName, target)
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// versionWithPrefix returns the target version with the current constraint prefix (^, ~, >=) preserved.
+// OR'd ranges keep every branch; see orRangeWithTarget.
+func versionWithPrefix(update *engine.Update) string {
+	oldVersion := update.Dependency.CurrentVersion
+	target := version.Normalize("npm", update.TargetVersion)
+	if strings.Contains(oldVersion, "||") {
+		return orRangeWithTarget(oldVersion, target)
+	}
+
+	return rangePrefix(oldVersion) + target
+}
+
+// rangePrefix returns the constraint operator (^, ~, >=) a range starts with.
+func rangePrefix(r string) string {
+	switch {
+	case strings.HasPrefix(r, "^"):
+		return "^"
+	case strings.HasPrefix(r, "~"):
+		return "~"
+	case strings.HasPrefix(r, ">="):
+		return ">="
+	}
+	return ""
+}
+
+// orRangeWithTarget moves the branches of an OR'd range such as
+// "^1.0.0 || ^2.0.0" that allow target to it, keeping their prefix and the
+// other branches. Branches that are not a single version (e.g., ">=1.2 <2")
+// are kept as they are. When no branch allows target, a "^target" branch is
+// added, as the widen versioning strategy does.
+func orRangeWithTarget(constraint, target string) string {
+	v, err := semver.NewVersion(target)
+	if err != nil {
+		return constraint + " || ^" + target
+	}
+
+	branches := strings.Split(constraint, "||")
+	allowed := false
+	for idx, raw := range branches {
+		branch := strings.TrimSpace(raw)
+		c, err := semver.NewConstraint(branch)
+		if err != nil || !c.Check(v) {
+			continue
+		}
+		allowed = true
+
+		prefix := rangePrefix(branch)
+		if rest := strings.TrimPrefix(branch, prefix); strings.ContainsAny(rest, " <>=") {
+			continue
+		}
+		branches[idx] = strings.Replace(raw, branch, prefix+target, 1)
+	}
+	if !allowed {
+		return constraint + " || ^" + target
+	}
+
+	return strings.Join(branches, "||")
+}
+
 // Validate runs npm validation (optional).
 func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
 	// Could run `npm install --package-lock-only` to validate
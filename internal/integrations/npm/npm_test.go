@@ -22,13 +22,17 @@ package npm
 
 import (
 	"context"
+	"crypto/sha512"
+	"encoding/hex"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/registry"
 )
 
 const (
@@ -118,6 +122,31 @@ func TestDetect(t *testing.T) {
 		}
 	})
 
+	t.Run("records package-lock.json", func(t *testing.T) {
+		tmpDir, _, _ := setupTestDir(t, filepath.Join("packages", "app"), `{"name":"root"}`, `{"name":"app"}`)
+		if err := os.WriteFile(filepath.Join(tmpDir, lockfileName), []byte(`{"lockfileVersion":3}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		integ := New()
+		manifests, err := integ.Detect(ctx, tmpDir)
+		if err != nil {
+			t.Fatalf("Detect() error = %v", err)
+		}
+		for _, m := range manifests {
+			want := ""
+			if m.Path == packageJSONName {
+				want = lockfileName
+			}
+			if got, _ := m.Metadata["lockfile"].(string); got != want {
+				t.Errorf("Detect() %s lockfile = %q, want %q", m.Path, got, want)
+			}
+			if got := integ.HasLockfile(m); got != (want != "") {
+				t.Errorf("HasLockfile(%s) = %v, want %v", m.Path, got, want != "")
+			}
+		}
+	})
+
 	t.Run("finds multiple package.json files", func(t *testing.T) {
 		tmpDir, _, _ := setupTestDir(t, filepath.Join("packages", "app"), `{"name":"root"}`, `{"name":"app"}`)
 
@@ -251,6 +280,40 @@ func TestExtractDependencies(t *testing.T) {
 	})
 }
 
+func TestDependencyLines(t *testing.T) {
+	content := []byte(`{
+  "name": "app",
+  "scripts": {"dependencies": "not a section"},
+  "dependencies": {
+    "express": "5.1.0",
+    "@types/node": "^20.0.0"
+  },
+  "devDependencies": {"jest": "~29.0.0"},
+  "packageManager": "pnpm@9.1.0"
+}
+`)
+
+	deps := []engine.Dependency{
+		{Name: "express", Type: "direct"},
+		{Name: "@types/node", Type: "direct"},
+		{Name: "jest", Type: "dev"},
+		{Name: "pnpm", Type: depTypePackageManager},
+		{Name: "missing", Type: "peer"},
+	}
+	setDependencyLines(deps, content)
+
+	want := map[string]int{"express": 5, "@types/node": 6, "jest": 8, "pnpm": 9, "missing": 0}
+	for _, dep := range deps {
+		if dep.Line != want[dep.Name] {
+			t.Errorf("%s line = %d, want %d", dep.Name, dep.Line, want[dep.Name])
+		}
+	}
+
+	if lines := dependencyLines([]byte(`{"dependencies": {"a": "1"`)); lines["dependencies/a"] != 1 {
+		t.Errorf("truncated content lines = %v, want dependencies/a on line 1", lines)
+	}
+}
+
 func TestPlan(t *testing.T) {
 	ctx := context.Background()
 	integ := New()
@@ -722,3 +785,369 @@ func TestGenerateDiff(t *testing.T) {
 		}
 	})
 }
+
+func TestApply_InvalidConstraint(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkgPath := filepath.Join(tmpDir, "package.json")
+
+	original := "{\n  \"name\": \"test-app\",\n  \"dependencies\": {\n    \"react\": \"^17.0.0\"\n  }\n}\n"
+	if err := os.WriteFile(pkgPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: pkgPath},
+		Updates: []engine.Update{
+			{
+				Dependency: engine.Dependency{
+					Name:           "react",
+					CurrentVersion: "^17.0.0",
+					Type:           "direct",
+				},
+				TargetVersion: "18.0.0 <<broken>>",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("Apply() applied/failed = %d/%d, want 0/1", result.Applied, result.Failed)
+	}
+	if len(result.Errors) != 1 {
+		t.Errorf("Apply() errors = %v, want one validation error", result.Errors)
+	}
+
+	content, err := os.ReadFile(pkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != original {
+		t.Errorf("Apply() wrote package.json despite invalid constraint:\n%s", content)
+	}
+}
+
+func TestApply_PrefixedVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	pkgPath := filepath.Join(tmpDir, "package.json")
+
+	original := "{\n  \"name\": \"test-app\",\n  \"dependencies\": {\n    \"react\": \"^17.0.0\"\n  }\n}\n"
+	if err := os.WriteFile(pkgPath, []byte(original), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Versions taken from v-prefixed tags are written back in npm's bare form
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: pkgPath},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "react", CurrentVersion: "^17.0.0", Type: "direct"},
+				TargetVersion: "v18.2.0",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 1 {
+		t.Errorf("Apply() applied = %d, want 1: %v", result.Applied, result.Errors)
+	}
+
+	content, err := os.ReadFile(pkgPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"react": "^18.2.0"`) {
+		t.Errorf("package.json missing bare react version:\n%s", content)
+	}
+}
+
+func TestPlanRewrite_OrRange(t *testing.T) {
+	mock := &mockDatasource{versions: map[string][]string{
+		"foo": {"1.9.0", "2.0.0", "2.4.0", "3.0.0"},
+	}}
+	integ := &Integration{ds: mock}
+
+	tests := []struct {
+		name   string
+		update string
+		want   string
+	}{
+		{name: "target inside a branch moves only that branch", update: "minor", want: `"foo": "^1.0.0 || ^2.4.0"`},
+		{name: "target outside every branch adds one", update: "major", want: `"foo": "^1.0.0 || ^2.0.0 || ^3.0.0"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content := []byte("{\n  \"dependencies\": {\n    \"foo\": \"^1.0.0 || ^2.0.0\"\n  }\n}\n")
+			var pkg PackageJSON
+			if err := json.Unmarshal(content, &pkg); err != nil {
+				t.Fatal(err)
+			}
+			manifest := &engine.Manifest{Path: "package.json", Dependencies: integ.extractDependencies(&pkg)}
+
+			planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: tt.update}}
+			plan, err := integ.Plan(context.Background(), manifest, planCtx)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			if len(plan.Updates) != 1 {
+				t.Fatalf("Plan() updates = %+v, want 1", plan.Updates)
+			}
+
+			result, err := integ.Rewrite(context.Background(), plan, content)
+			if err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+			if !strings.Contains(string(result.Content), tt.want) {
+				t.Errorf("Rewrite() = %s, want %s (target %s)", result.Content, tt.want, plan.Updates[0].TargetVersion)
+			}
+		})
+	}
+}
+
+func TestOrRangeWithTarget(t *testing.T) {
+	tests := []struct {
+		constraint string
+		target     string
+		want       string
+	}{
+		{"^1.0.0 || ^2.0.0", "1.9.0", "^1.9.0 || ^2.0.0"},
+		{"~1.2.0||~1.3.0", "1.3.4", "~1.2.0||~1.3.4"},
+		{">=1.2.0 <2.0.0 || ^3.0.0", "1.5.0", ">=1.2.0 <2.0.0 || ^3.0.0"},
+		{"^1.0.0 || ^2.0.0", "4.0.0", "^1.0.0 || ^2.0.0 || ^4.0.0"},
+	}
+	for _, tt := range tests {
+		if got := orRangeWithTarget(tt.constraint, tt.target); got != tt.want {
+			t.Errorf("orRangeWithTarget(%q, %q) = %q, want %q", tt.constraint, tt.target, got, tt.want)
+		}
+	}
+}
+
+// mockDatasource implements datasource.Datasource, tarballSource and
+// distSource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+	tarballs map[string]string
+	dists    map[string]*registry.NPMDist
+	infos    map[string]*datasource.PackageInfo
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return m.infos[pkg], nil
+}
+
+func (m *mockDatasource) GetTarball(ctx context.Context, pkg, version string) ([]byte, error) {
+	if data, ok := m.tarballs[pkg+"@"+version]; ok {
+		return []byte(data), nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetDist(ctx context.Context, pkg, version string) (*registry.NPMDist, error) {
+	if dist, ok := m.dists[pkg+"@"+version]; ok {
+		return dist, nil
+	}
+	return nil, context.Canceled
+}
+
+func TestParsePackageManager(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantName    string
+		wantVersion string
+		wantHash    string
+		wantOK      bool
+	}{
+		{value: "pnpm@8.15.0", wantName: "pnpm", wantVersion: "8.15.0", wantOK: true},
+		{value: "yarn@4.1.0+sha224.abc", wantName: "yarn", wantVersion: "4.1.0", wantHash: "sha224.abc", wantOK: true},
+		{value: "npm@10", wantOK: false},
+		{value: "yarn@https://example.com/yarn.js", wantOK: false},
+		{value: "pnpm", wantOK: false},
+		{value: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			name, version, hash, ok := parsePackageManager(tt.value)
+			if ok != tt.wantOK || name != tt.wantName || version != tt.wantVersion || hash != tt.wantHash {
+				t.Errorf("parsePackageManager(%q) = %q, %q, %q, %v; want %q, %q, %q, %v", tt.value,
+					name, version, hash, ok, tt.wantName, tt.wantVersion, tt.wantHash, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestPlan_PackageManager(t *testing.T) {
+	mock := &mockDatasource{versions: map[string][]string{
+		"pnpm":              {"8.15.0", "8.15.4", "9.0.0"},
+		"yarn":              {"1.22.19", "1.22.22"},
+		"@yarnpkg/cli-dist": {"4.0.0", "4.1.0"},
+	}}
+	integ := &Integration{ds: mock}
+
+	manifest := &engine.Manifest{
+		Path: "package.json",
+		Dependencies: []engine.Dependency{
+			{Name: "pnpm", CurrentVersion: "8.15.0", Type: depTypePackageManager},
+			{Name: "yarn", CurrentVersion: "4.0.0", Type: depTypePackageManager},
+		},
+	}
+
+	plan, err := integ.Plan(context.Background(), manifest, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	if got["pnpm"] != "9.0.0" {
+		t.Errorf("pnpm target = %q, want %q", got["pnpm"], "9.0.0")
+	}
+	if got["yarn"] != "4.1.0" {
+		t.Errorf("yarn target = %q, want %q (from @yarnpkg/cli-dist)", got["yarn"], "4.1.0")
+	}
+}
+
+func TestPlan_Deprecated(t *testing.T) {
+	mock := &mockDatasource{
+		versions: map[string][]string{"request": {"2.87.0", "2.88.0", "2.88.2"}},
+		infos: map[string]*datasource.PackageInfo{"request": {Versions: []datasource.VersionInfo{
+			{Version: "2.87.0"},
+			{Version: "2.88.0"},
+			{Version: "2.88.2", Deprecated: true},
+			{Version: "2.89.0", Yanked: true},
+		}}},
+	}
+	integ := &Integration{ds: mock}
+	manifest := &engine.Manifest{
+		Path: "package.json",
+		Dependencies: []engine.Dependency{
+			{Name: "request", CurrentVersion: "^2.87.0", Constraint: "^2.87.0", Type: "dependencies"},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		want            string
+		allowDeprecated bool
+	}{
+		{name: "deprecated newest skipped", want: "2.88.0"},
+		{name: "allow_deprecated proposes deprecated", allowDeprecated: true, want: "2.88.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "major", AllowDeprecated: tt.allowDeprecated}}
+			plan, err := integ.Plan(context.Background(), manifest, planCtx)
+			if err != nil {
+				t.Fatalf("Plan() error = %v", err)
+			}
+			if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != tt.want {
+				t.Errorf("Plan() updates = %+v, want target %s", plan.Updates, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply_PackageManager(t *testing.T) {
+	tarball := "pnpm-9.0.0.tgz contents"
+	sum := sha512.Sum512([]byte(tarball))
+	recomputed := "pnpm@9.0.0+sha512." + hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name      string
+		current   string
+		tarballs  map[string]string
+		want      string
+		wantNotes int
+	}{
+		{
+			name:    "without hash",
+			current: "pnpm@8.15.0",
+			want:    "pnpm@9.0.0",
+		},
+		{
+			name:     "recomputes hash",
+			current:  "pnpm@8.15.0+sha512.0123abcd",
+			tarballs: map[string]string{"pnpm@9.0.0": tarball},
+			want:     recomputed,
+		},
+		{
+			name:      "clears hash when tarball is unavailable",
+			current:   "pnpm@8.15.0+sha512.0123abcd",
+			want:      "pnpm@9.0.0",
+			wantNotes: 1,
+		},
+		{
+			name:      "clears hash with unsupported algorithm",
+			current:   "pnpm@8.15.0+md5.0123abcd",
+			tarballs:  map[string]string{"pnpm@9.0.0": tarball},
+			want:      "pnpm@9.0.0",
+			wantNotes: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pkgPath := filepath.Join(t.TempDir(), "package.json")
+			original := "{\n  \"name\": \"test-app\",\n  \"packageManager\": \"" + tt.current + "\"\n}\n"
+			if err := os.WriteFile(pkgPath, []byte(original), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			mock := &mockDatasource{tarballs: tt.tarballs}
+			integ := &Integration{ds: mock, tarballs: mock}
+
+			plan := &engine.UpdatePlan{
+				Manifest: &engine.Manifest{Path: pkgPath},
+				Updates: []engine.Update{{
+					Dependency:    engine.Dependency{Name: "pnpm", CurrentVersion: "8.15.0", Type: depTypePackageManager},
+					TargetVersion: "9.0.0",
+				}},
+			}
+
+			result, err := integ.Apply(context.Background(), plan)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if result.Applied != 1 {
+				t.Errorf("Apply() applied = %d, want 1", result.Applied)
+			}
+			if len(result.Errors) != tt.wantNotes {
+				t.Errorf("Apply() errors = %v, want %d", result.Errors, tt.wantNotes)
+			}
+
+			content, err := os.ReadFile(pkgPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var pkg PackageJSON
+			if err := json.Unmarshal(content, &pkg); err != nil {
+				t.Fatal(err)
+			}
+			if pkg.PackageManager != tt.want {
+				t.Errorf("packageManager = %q, want %q", pkg.PackageManager, tt.want)
+			}
+		})
+	}
+}
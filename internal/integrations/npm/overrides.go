@@ -0,0 +1,275 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package npm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/integrations"
+)
+
+// Vulnerability describes a vulnerable package reported for an npm project,
+// such as an OSV advisory, along with the first version that fixes it.
+type Vulnerability struct {
+	ID           string
+	Package      string
+	Version      string
+	FixedVersion string
+}
+
+// OverrideSuggestion is an npm "overrides" entry that forces a vulnerable
+// transitive dependency to its fixed version.
+type OverrideSuggestion struct {
+	Package       string `json:"package"`
+	Version       string `json:"version"`
+	Vulnerability string `json:"vulnerability,omitempty"`
+}
+
+// SuggestOverrides returns overrides for vulnerable transitive dependencies of pkg.
+// Direct dependencies are skipped since they can be bumped in place, as are
+// vulnerabilities without a fix and packages already overridden to a fixed version.
+// When several vulnerabilities affect the same package, the highest fixed version wins.
+func SuggestOverrides(pkg *PackageJSON, vulns []Vulnerability) []OverrideSuggestion {
+	best := make(map[string]OverrideSuggestion)
+	for _, v := range vulns {
+		if v.FixedVersion == "" || isDirectDependency(pkg, v.Package) {
+			continue
+		}
+		fixed, err := semver.NewVersion(v.FixedVersion)
+		if err != nil {
+			continue
+		}
+		if existing, ok := pkg.Overrides[v.Package].(string); ok && overrideSatisfies(existing, fixed) {
+			continue
+		}
+		if prev, ok := best[v.Package]; ok {
+			if prevVersion, err := semver.NewVersion(prev.Version); err == nil && !fixed.GreaterThan(prevVersion) {
+				continue
+			}
+		}
+		best[v.Package] = OverrideSuggestion{
+			Package:       v.Package,
+			Version:       v.FixedVersion,
+			Vulnerability: v.ID,
+		}
+	}
+
+	suggestions := make([]OverrideSuggestion, 0, len(best))
+	for _, s := range best {
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Package < suggestions[j].Package
+	})
+	return suggestions
+}
+
+// ApplyOverrides writes suggestions into the "overrides" field of the package.json
+// at path. The field is edited in place, so every other byte of the file, including
+// key order and formatting, is kept.
+func ApplyOverrides(path string, suggestions []OverrideSuggestion) error {
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	if err := integrations.ValidateFilePath(path); err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+	if err != nil {
+		return fmt.Errorf("read package.json: %w", err)
+	}
+
+	newContent, err := setOverrides(content, suggestions)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, newContent, 0o600); err != nil {
+		return fmt.Errorf("write package.json: %w", err)
+	}
+	return nil
+}
+
+// setOverrides sets each suggestion in the "overrides" object of package.json
+// content, adding the object when it is missing. A package whose override is a
+// nested object gets its own version under the "." key.
+func setOverrides(content []byte, suggestions []OverrideSuggestion) ([]byte, error) {
+	if !json.Valid(content) {
+		return nil, fmt.Errorf("parse package.json: invalid JSON")
+	}
+
+	if start, _, ok := jsonValueSpan(content, "overrides"); !ok {
+		content = insertJSONMember(content, "overrides", []byte("{}"))
+	} else if content[start] != '{' {
+		return nil, fmt.Errorf("parse overrides: not an object")
+	}
+
+	for _, s := range suggestions {
+		start, _, ok := jsonValueSpan(content, "overrides", s.Package)
+		switch {
+		case !ok:
+			content = insertJSONMember(content, s.Package, encodeJSONString(s.Version), "overrides")
+		case content[start] != '{':
+			content = setJSONString(content, s.Version, "overrides", s.Package)
+		default:
+			if _, _, ok := jsonValueSpan(content, "overrides", s.Package, "."); ok {
+				content = setJSONString(content, s.Version, "overrides", s.Package, ".")
+			} else {
+				content = insertJSONMember(content, ".", encodeJSONString(s.Version), "overrides", s.Package)
+			}
+		}
+	}
+	return content, nil
+}
+
+// insertJSONMember appends a key/value member to the object at path in JSON
+// content (the top-level object when path is empty), following the indentation
+// of the surrounding lines. Content without an object at path is returned unchanged.
+func insertJSONMember(content []byte, key string, value []byte, path ...string) []byte {
+	start, end, ok := jsonObjectSpan(content, path...)
+	if !ok {
+		return content
+	}
+
+	closing := end - 1
+	last := start + len(bytes.TrimRight(content[start:closing], " \t\r\n")) - 1
+	member := append(append(encodeJSONString(key), ": "...), value...)
+
+	var insert []byte
+	switch {
+	case last == start && bytes.ContainsRune(content, '\n'):
+		indent := lineIndent(content, start)
+		insert = append(append([]byte("\n"+indent+jsonIndentUnit(content)), member...), "\n"+indent...)
+		return splice(content, start+1, closing, insert)
+	case last == start:
+		return splice(content, start+1, closing, member)
+	case bytes.ContainsRune(content[start:closing], '\n'):
+		insert = append([]byte(",\n"+lineIndent(content, last)), member...)
+	default:
+		insert = append([]byte(", "), member...)
+	}
+	return splice(content, last+1, last+1, insert)
+}
+
+// jsonObjectSpan returns the byte offsets of the object at path in JSON content,
+// or of the top-level object when path is empty.
+func jsonObjectSpan(content []byte, path ...string) (start, end int, ok bool) {
+	if len(path) == 0 {
+		start = bytes.IndexByte(content, '{')
+		end = bytes.LastIndexByte(content, '}') + 1
+		return start, end, start >= 0 && end > start
+	}
+	start, end, ok = jsonValueSpan(content, path...)
+	return start, end, ok && content[start] == '{'
+}
+
+// encodeJSONString returns value as a JSON string without HTML escaping.
+func encodeJSONString(value string) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(value) //nolint:errcheck // encoding a string cannot fail
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
+
+// lineIndent returns the leading whitespace of the line containing offset pos.
+func lineIndent(content []byte, pos int) string {
+	lineStart := bytes.LastIndexByte(content[:pos], '\n') + 1
+	line := content[lineStart:pos]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// jsonIndentUnit returns the indentation of the first indented line in content,
+// defaulting to two spaces.
+func jsonIndentUnit(content []byte) string {
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if indent := line[:len(line)-len(bytes.TrimLeft(line, " \t"))]; len(indent) > 0 {
+			return string(indent)
+		}
+	}
+	return "  "
+}
+
+// splice returns content with the bytes between start and end replaced by insert.
+func splice(content []byte, start, end int, insert []byte) []byte {
+	return append(append(append([]byte{}, content[:start]...), insert...), content[end:]...)
+}
+
+// LockedPackages returns the versions of every package installed by a
+// package-lock.json (lockfileVersion 2 or later), keyed by package name.
+// A package installed at several places in the tree lists each distinct version.
+func LockedPackages(content []byte) (map[string][]string, error) {
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("parse package-lock.json: %w", err)
+	}
+
+	locked := make(map[string][]string)
+	for key, entry := range lock.Packages {
+		idx := strings.LastIndex(key, "node_modules/")
+		if idx < 0 || entry.Version == "" {
+			continue
+		}
+		name := key[idx+len("node_modules/"):]
+		if !slices.Contains(locked[name], entry.Version) {
+			locked[name] = append(locked[name], entry.Version)
+		}
+	}
+	for name := range locked {
+		sort.Strings(locked[name])
+	}
+	return locked, nil
+}
+
+// isDirectDependency reports whether name is declared in any dependency section of pkg.
+func isDirectDependency(pkg *PackageJSON, name string) bool {
+	for _, section := range []map[string]string{
+		pkg.Dependencies, pkg.DevDependencies, pkg.PeerDependencies, pkg.OptionalDependencies,
+	} {
+		if _, ok := section[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideSatisfies reports whether an existing override already pins at or above fixed.
+func overrideSatisfies(existing string, fixed *semver.Version) bool {
+	v, err := semver.NewVersion(strings.TrimLeft(existing, "^~>= "))
+	if err != nil {
+		return false
+	}
+	return !v.LessThan(fixed)
+}
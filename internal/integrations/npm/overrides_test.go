@@ -0,0 +1,217 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package npm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSuggestOverrides(t *testing.T) {
+	pkg := &PackageJSON{
+		Dependencies: map[string]string{"express": "^4.18.0"},
+		Overrides:    map[string]interface{}{"minimist": "1.2.6"},
+	}
+	vulns := []Vulnerability{
+		{ID: "GHSA-aaaa", Package: "express", Version: "4.18.0", FixedVersion: "4.19.2"},       // direct: bump in place
+		{ID: "GHSA-bbbb", Package: "qs", Version: "6.10.3", FixedVersion: "6.10.4"},            // transitive
+		{ID: "GHSA-cccc", Package: "qs", Version: "6.10.3", FixedVersion: "6.11.0"},            // higher fix wins
+		{ID: "GHSA-dddd", Package: "minimist", Version: "1.2.5", FixedVersion: "1.2.6"},        // already overridden
+		{ID: "GHSA-eeee", Package: "path-to-regexp", Version: "0.1.7", FixedVersion: "0.1.10"}, // transitive
+		{ID: "GHSA-ffff", Package: "debug", Version: "2.6.8"},                                  // no fix available
+	}
+
+	got := SuggestOverrides(pkg, vulns)
+	want := []OverrideSuggestion{
+		{Package: "path-to-regexp", Version: "0.1.10", Vulnerability: "GHSA-eeee"},
+		{Package: "qs", Version: "6.11.0", Vulnerability: "GHSA-cccc"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("SuggestOverrides() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SuggestOverrides()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, packageJSONName)
+	content := `{
+  "name": "app",
+  "scripts": {"test": "jest"},
+  "dependencies": {"express": "^4.18.0"},
+  "overrides": {"minimist": "1.2.6"}
+}
+`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ApplyOverrides(path, []OverrideSuggestion{{Package: "qs", Version: "6.11.0", Vulnerability: "GHSA-cccc"}})
+	if err != nil {
+		t.Fatalf("ApplyOverrides() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := string(data)
+	for _, want := range []string{`"qs": "6.11.0"`, `"minimist": "1.2.6"`, `"test": "jest"`, `"express": "^4.18.0"`} {
+		if !strings.Contains(result, want) {
+			t.Errorf("ApplyOverrides() result missing %s:\n%s", want, result)
+		}
+	}
+}
+
+func TestApplyOverrides_KeepsLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name: "adds overrides after the last field",
+			content: `{
+  "name": "app",
+  "dependencies": {
+    "express": "^4.18.0"
+  },
+  "scripts": {
+    "test": "jest"
+  }
+}
+`,
+			want: `{
+  "name": "app",
+  "dependencies": {
+    "express": "^4.18.0"
+  },
+  "scripts": {
+    "test": "jest"
+  },
+  "overrides": {
+    "qs": "6.11.0"
+  }
+}
+`,
+		},
+		{
+			name: "edits the existing overrides in place",
+			content: `{
+  "overrides": {
+    "minimist": "1.2.6",
+    "qs": "6.10.3",
+    "body-parser": {
+      "debug": "2.6.9"
+    }
+  },
+  "name": "app"
+}
+`,
+			want: `{
+  "overrides": {
+    "minimist": "1.2.6",
+    "qs": "6.11.0",
+    "body-parser": {
+      "debug": "2.6.9",
+      ".": "1.20.3"
+    }
+  },
+  "name": "app"
+}
+`,
+		},
+		{
+			name:    "single-line overrides",
+			content: "{\"name\": \"app\", \"overrides\": {\"minimist\": \"1.2.6\"}}\n",
+			want:    "{\"name\": \"app\", \"overrides\": {\"minimist\": \"1.2.6\", \"qs\": \"6.11.0\"}}\n",
+		},
+		{
+			name:    "empty overrides with tab indentation",
+			content: "{\n\t\"name\": \"app\",\n\t\"overrides\": {}\n}\n",
+			want:    "{\n\t\"name\": \"app\",\n\t\"overrides\": {\n\t\t\"qs\": \"6.11.0\"\n\t}\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), packageJSONName)
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			suggestions := []OverrideSuggestion{{Package: "qs", Version: "6.11.0"}}
+			if strings.Contains(tt.content, "body-parser") {
+				suggestions = append(suggestions, OverrideSuggestion{Package: "body-parser", Version: "1.20.3"})
+			}
+			if err := ApplyOverrides(path, suggestions); err != nil {
+				t.Fatalf("ApplyOverrides() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("ApplyOverrides() =\n%s\nwant\n%s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestLockedPackages(t *testing.T) {
+	content := []byte(`{
+  "lockfileVersion": 3,
+  "packages": {
+    "": {"name": "app", "dependencies": {"express": "^4.18.0"}},
+    "node_modules/express": {"version": "4.18.2"},
+    "node_modules/qs": {"version": "6.11.0"},
+    "node_modules/body-parser/node_modules/qs": {"version": "6.10.3"},
+    "node_modules/@types/node": {"version": "20.1.0"},
+    "packages/lib": {"version": "1.0.0"}
+  }
+}`)
+
+	got, err := LockedPackages(content)
+	if err != nil {
+		t.Fatalf("LockedPackages() error = %v", err)
+	}
+	want := map[string][]string{
+		"express":     {"4.18.2"},
+		"qs":          {"6.10.3", "6.11.0"},
+		"@types/node": {"20.1.0"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("LockedPackages() = %v, want %v", got, want)
+	}
+	for name, versions := range want {
+		if strings.Join(got[name], ",") != strings.Join(versions, ",") {
+			t.Errorf("LockedPackages()[%s] = %v, want %v", name, got[name], versions)
+		}
+	}
+}
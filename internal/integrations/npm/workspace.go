@@ -0,0 +1,179 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package npm
+
+import (
+	"encoding/json"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// workspaceProtocol prefixes dependencies that npm, Yarn and pnpm resolve to
+// a package of the same workspace (e.g. "workspace:^", "workspace:*").
+const workspaceProtocol = "workspace:"
+
+// workspacePatterns returns the member globs of a package.json "workspaces"
+// field: either an array, or Yarn's object form with a "packages" array.
+func workspacePatterns(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var patterns []string
+	if err := json.Unmarshal(raw, &patterns); err == nil {
+		return patterns
+	}
+
+	var object struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil {
+		return object.Packages
+	}
+	return nil
+}
+
+// matchWorkspace reports whether dir, relative to the workspace root and
+// slash-separated, is selected by the member globs. A trailing "/**" matches
+// every directory below its prefix, and "!" patterns exclude directories
+// matched earlier.
+func matchWorkspace(dir string, patterns []string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = path.Clean(strings.TrimPrefix(pattern, "!"))
+
+		ok := false
+		if prefix, found := strings.CutSuffix(pattern, "/**"); found {
+			ok = strings.HasPrefix(dir, prefix+"/")
+		} else {
+			ok, _ = path.Match(pattern, dir) //nolint:errcheck // malformed patterns match nothing
+		}
+		if ok {
+			matched = !negate
+		}
+	}
+	return matched
+}
+
+// groupWorkspaces tags workspace roots and their members with a "workspace"
+// metadata entry naming the root package.json, and a "workspace_packages"
+// entry with the package names of the whole workspace. roots maps each root
+// manifest path to its member globs. A member of several workspaces belongs
+// to the nearest one.
+func groupWorkspaces(manifests []*engine.Manifest, roots map[string][]string) {
+	if len(roots) == 0 {
+		return
+	}
+
+	rootPaths := make([]string, 0, len(roots))
+	for root := range roots {
+		rootPaths = append(rootPaths, root)
+	}
+	// Deepest roots first, so nested workspaces win over enclosing ones
+	sort.Slice(rootPaths, func(i, j int) bool {
+		if len(rootPaths[i]) != len(rootPaths[j]) {
+			return len(rootPaths[i]) > len(rootPaths[j])
+		}
+		return rootPaths[i] < rootPaths[j]
+	})
+
+	members := make(map[string][]*engine.Manifest)
+	for _, m := range manifests {
+		for _, root := range rootPaths {
+			if m.Path == root {
+				members[root] = append(members[root], m)
+				break
+			}
+			rel, err := filepath.Rel(filepath.Dir(root), filepath.Dir(m.Path))
+			if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+				continue
+			}
+			if matchWorkspace(filepath.ToSlash(rel), roots[root]) {
+				members[root] = append(members[root], m)
+				break
+			}
+		}
+	}
+
+	for root, group := range members {
+		names := make(map[string]bool)
+		for _, m := range group {
+			if name, _ := m.Metadata["package_name"].(string); name != "" { //nolint:errcheck // absent names are skipped
+				names[name] = true
+			}
+		}
+		for _, m := range group {
+			m.Metadata["workspace"] = root
+			m.Metadata["workspace_packages"] = names
+		}
+	}
+}
+
+// workspaceDependency reports whether a dependency refers to a package of
+// the manifest's own workspace, which is linked locally rather than
+// installed from the registry.
+func workspaceDependency(manifest *engine.Manifest, dep *engine.Dependency) bool {
+	if strings.HasPrefix(dep.Constraint, workspaceProtocol) {
+		return true
+	}
+	names, _ := manifest.Metadata["workspace_packages"].(map[string]bool) //nolint:errcheck // absent outside workspaces
+	return names[dep.Name]
+}
+
+// ReconcilePlans reports the plans of each npm workspace together: the root
+// package.json first, then its members by path, with their updates grouped
+// under the workspace. Target versions are not changed.
+func (i *Integration) ReconcilePlans(plans []*engine.UpdatePlan, _ *engine.PlanContext) []*engine.UpdatePlan {
+	groups := make(map[string][]*engine.UpdatePlan)
+	var order []string
+	for _, p := range plans {
+		key := p.Manifest.Path
+		if root, ok := p.Manifest.Metadata["workspace"].(string); ok && root != "" {
+			key = root
+			for idx := range p.Updates {
+				if p.Updates[idx].Group == "" {
+					p.Updates[idx].Group = "npm-workspace:" + root
+				}
+			}
+		}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], p)
+	}
+
+	result := make([]*engine.UpdatePlan, 0, len(plans))
+	for _, key := range order {
+		group := groups[key]
+		sort.SliceStable(group, func(i, j int) bool {
+			if ri, rj := group[i].Manifest.Path == key, group[j].Manifest.Path == key; ri != rj {
+				return ri
+			}
+			return group[i].Manifest.Path < group[j].Manifest.Path
+		})
+		result = append(result, group...)
+	}
+	return result
+}
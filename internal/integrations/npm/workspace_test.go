@@ -0,0 +1,187 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package npm
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func writeWorkspace(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestWorkspace_SiblingNotUpdated(t *testing.T) {
+	dir := writeWorkspace(t, map[string]string{
+		"package.json":              `{"name": "acme", "private": true, "workspaces": ["packages/*"], "devDependencies": {"typescript": "^5.3.0"}}`,
+		"packages/ui/package.json":  `{"name": "@acme/ui", "version": "1.0.0"}`,
+		"packages/web/package.json": `{"name": "@acme/web", "dependencies": {"@acme/ui": "workspace:^", "lodash": "^4.17.0"}}`,
+		"tools/package.json":        `{"name": "tools", "dependencies": {"@acme/ui": "^0.1.0"}}`,
+	})
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		// A public package of the same name must not be proposed
+		"@acme/ui":   {"0.1.0", "1.0.0", "2.0.0"},
+		"lodash":     {"4.17.0", "4.17.21"},
+		"typescript": {"5.3.0", "5.4.5"},
+	}}}
+
+	manifests, err := integ.Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	byPath := make(map[string]*engine.Manifest)
+	for _, m := range manifests {
+		byPath[m.Path] = m
+	}
+	for _, path := range []string{"package.json", "packages/ui/package.json", "packages/web/package.json"} {
+		if ws := byPath[path].Metadata["workspace"]; ws != "package.json" {
+			t.Errorf("%s workspace = %v, want package.json", path, ws)
+		}
+	}
+	if ws, ok := byPath["tools/package.json"].Metadata["workspace"]; ok {
+		t.Errorf("tools/package.json workspace = %v, want none outside the workspace globs", ws)
+	}
+
+	planCtx := &engine.PlanContext{Policy: &engine.IntegrationPolicy{Update: "major"}}
+	targets := func(path string) map[string]string {
+		t.Helper()
+		plan, err := integ.Plan(context.Background(), byPath[path], planCtx)
+		if err != nil {
+			t.Fatalf("Plan(%s) error = %v", path, err)
+		}
+		got := make(map[string]string)
+		for _, u := range plan.Updates {
+			got[u.Dependency.Name] = u.TargetVersion
+		}
+		return got
+	}
+
+	web := targets("packages/web/package.json")
+	if target, ok := web["@acme/ui"]; ok {
+		t.Errorf("@acme/ui target = %q, want workspace sibling not proposed", target)
+	}
+	if web["lodash"] != "4.17.21" {
+		t.Errorf("lodash target = %q, want 4.17.21", web["lodash"])
+	}
+	if got := targets("package.json"); got["typescript"] != "5.4.5" {
+		t.Errorf("root typescript target = %q, want 5.4.5", got["typescript"])
+	}
+	if got := targets("tools/package.json"); got["@acme/ui"] != "2.0.0" {
+		t.Errorf("tools @acme/ui target = %q, want 2.0.0 outside the workspace", got["@acme/ui"])
+	}
+}
+
+func TestWorkspacePatterns(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []string
+	}{
+		{name: "array", raw: `["packages/*", "apps/web"]`, want: []string{"packages/*", "apps/web"}},
+		{name: "yarn object", raw: `{"packages": ["packages/*"], "nohoist": ["**/react"]}`, want: []string{"packages/*"}},
+		{name: "absent", raw: ``, want: nil},
+		{name: "invalid", raw: `"packages/*"`, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workspacePatterns(json.RawMessage(tt.raw)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("workspacePatterns() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchWorkspace(t *testing.T) {
+	patterns := []string{"packages/*", "./apps/web", "libs/**", "!packages/legacy"}
+	tests := map[string]bool{
+		"packages/ui":     true,
+		"packages/legacy": false,
+		"packages/ui/sub": false,
+		"apps/web":        true,
+		"apps/admin":      false,
+		"libs/a/b":        true,
+		"tools":           false,
+	}
+
+	for dir, want := range tests {
+		if got := matchWorkspace(dir, patterns); got != want {
+			t.Errorf("matchWorkspace(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestReconcilePlans_Workspace(t *testing.T) {
+	plan := func(path, workspace string) *engine.UpdatePlan {
+		metadata := map[string]interface{}{}
+		if workspace != "" {
+			metadata["workspace"] = workspace
+		}
+		return &engine.UpdatePlan{
+			Manifest: &engine.Manifest{Path: path, Type: "npm", Metadata: metadata},
+			Updates:  []engine.Update{{Dependency: engine.Dependency{Name: "lodash"}, TargetVersion: "4.17.21"}},
+		}
+	}
+	plans := []*engine.UpdatePlan{
+		plan("packages/web/package.json", "package.json"),
+		plan("tools/package.json", ""),
+		plan("package.json", "package.json"),
+		plan("packages/api/package.json", "package.json"),
+	}
+
+	got := (&Integration{}).ReconcilePlans(plans, nil)
+
+	var order []string
+	for _, p := range got {
+		order = append(order, p.Manifest.Path)
+	}
+	want := []string{"package.json", "packages/api/package.json", "packages/web/package.json", "tools/package.json"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("ReconcilePlans() order = %v, want %v", order, want)
+	}
+	for _, p := range got {
+		wantGroup := ""
+		if p.Manifest.Metadata["workspace"] != nil {
+			wantGroup = "npm-workspace:package.json"
+		}
+		if p.Updates[0].Group != wantGroup {
+			t.Errorf("%s group = %q, want %q", p.Manifest.Path, p.Updates[0].Group, wantGroup)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package nuget implements the NuGet integration for .NET package references.
+// It detects *.csproj project files, Directory.Packages.props (central package
+// management) and packages.config, queries the nuget.org flat container API for
+// version updates, and rewrites Version attributes in place, leaving every other
+// attribute, their order and the surrounding whitespace untouched.
+package nuget
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("nuget", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "nuget"
+
+	centralPackagesFile = "Directory.Packages.props"
+	packagesConfigFile  = "packages.config"
+)
+
+var (
+	// elementPattern matches the start tag of an element that can declare a
+	// package version; group 2 holds its attributes.
+	elementPattern = regexp.MustCompile(`<(PackageReference|PackageVersion|package)(\s[^>]*)>`)
+
+	// attributePattern matches one attribute; group 3 or 4 holds its value.
+	attributePattern = regexp.MustCompile(`([A-Za-z_][\w.-]*)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+	// commentPattern matches an XML comment.
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+
+	// exactPattern matches a plain NuGet version (e.g., "13.0.3", "1.0.0.1", "2.0.0-rc.1").
+	exactPattern = regexp.MustCompile(`^\d+(\.\d+){0,3}(-[0-9A-Za-z.-]+)?$`)
+
+	// floatingPattern matches a floating version (e.g., "1.*", "1.2.*") and
+	// captures its fixed prefix.
+	floatingPattern = regexp.MustCompile(`^(\d+(?:\.\d+){0,2})\.\*$`)
+)
+
+// reference is a package version declared in a project file, with the byte
+// offsets of the Version attribute's value.
+type reference struct {
+	name    string
+	version string
+	start   int
+	end     int
+}
+
+// Integration implements NuGet package reference updates.
+type Integration struct {
+	ds datasource.Datasource
+}
+
+// New creates a new NuGet integration.
+func New() *Integration {
+	ds, err := datasource.Get("nuget")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewNuGetDatasource()
+	}
+	return &Integration{
+		ds: datasource.Cached(ds),
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of project and package files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"*.csproj", centralPackagesFile, packagesConfigFile}
+}
+
+// isManifestFile reports whether a file name is a NuGet manifest.
+func isManifestFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".csproj") ||
+		strings.EqualFold(name, centralPackagesFile) ||
+		strings.EqualFold(name, packagesConfigFile)
+}
+
+// Detect finds project files declaring package versions in the repository.
+// Project files whose references carry no version, as under central package
+// management, are not reported.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Build output holds restored copies of project files
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && (info.Name() == "bin" || info.Name() == "obj")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !isManifestFile(info.Name()) || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		deps := parseDependencies(string(content))
+		if len(deps) == 0 {
+			return nil
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: deps,
+			Content:      content,
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parseReferences returns the package references in content that carry a
+// Version attribute: PackageReference (Include or Update) in project files,
+// PackageVersion in Directory.Packages.props and package in packages.config.
+// References inside XML comments are ignored.
+func parseReferences(content string) []reference {
+	comments := commentPattern.FindAllStringIndex(content, -1)
+	inComment := func(offset int) bool {
+		for _, c := range comments {
+			if offset >= c[0] && offset < c[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var refs []reference
+	for _, m := range elementPattern.FindAllStringSubmatchIndex(content, -1) {
+		if inComment(m[0]) {
+			continue
+		}
+		element := content[m[2]:m[3]]
+		attrStart := m[4]
+
+		ref := reference{start: -1}
+		for _, a := range attributePattern.FindAllStringSubmatchIndex(content[attrStart:m[5]], -1) {
+			name := content[attrStart+a[2] : attrStart+a[3]]
+			valueStart, valueEnd := a[4], a[5]
+			if valueStart < 0 {
+				valueStart, valueEnd = a[6], a[7]
+			}
+			value := content[attrStart+valueStart : attrStart+valueEnd]
+
+			switch {
+			case element == "package" && strings.EqualFold(name, "id"),
+				element != "package" && (strings.EqualFold(name, "Include") || strings.EqualFold(name, "Update")):
+				ref.name = strings.TrimSpace(value)
+			case strings.EqualFold(name, "Version"):
+				ref.version = value
+				ref.start, ref.end = attrStart+valueStart, attrStart+valueEnd
+			}
+		}
+
+		if ref.name != "" && ref.start >= 0 {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// parseDependencies extracts the references with an exact or floating version.
+// Version ranges ("[1.0,2.0)"), MSBuild properties ("$(JsonVersion)") and
+// prerelease floats are skipped. A package declared twice with the same
+// version, as in per-framework item groups, is reported once.
+func parseDependencies(content string) []engine.Dependency {
+	var deps []engine.Dependency
+	seen := make(map[string]bool)
+
+	for _, ref := range parseReferences(content) {
+		current, ok := floor(strings.TrimSpace(ref.version))
+		if !ok {
+			continue
+		}
+		key := strings.ToLower(ref.name) + "@" + ref.version
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		deps = append(deps, engine.Dependency{
+			Name:           ref.name,
+			CurrentVersion: current,
+			Constraint:     ref.version,
+			Type:           "package",
+		})
+	}
+	return deps
+}
+
+// floor returns the lowest version a Version attribute allows: the version
+// itself when exact, or the fixed prefix padded with zeros when floating
+// ("1.2.*" -> "1.2.0").
+func floor(v string) (string, bool) {
+	if exactPattern.MatchString(v) {
+		return v, true
+	}
+	m := floatingPattern.FindStringSubmatch(v)
+	if m == nil {
+		return "", false
+	}
+	parts := strings.Split(m[1], ".")
+	for len(parts) < 3 {
+		parts = append(parts, "0")
+	}
+	return strings.Join(parts, "."), true
+}
+
+// isFloating reports whether a Version attribute is a floating version.
+func isFloating(v string) bool {
+	return floatingPattern.MatchString(strings.TrimSpace(v))
+}
+
+// floatingAllows reports whether the floating version float resolves to v.
+func floatingAllows(float, v string) bool {
+	m := floatingPattern.FindStringSubmatch(strings.TrimSpace(float))
+	return m != nil && strings.HasPrefix(v, m[1]+".") && !strings.Contains(v, "-")
+}
+
+// newFloating rewrites a floating version to cover target, keeping its
+// precision: "1.2.*" becomes "1.4.*" for 1.4.3.
+func newFloating(float, target string) string {
+	m := floatingPattern.FindStringSubmatch(strings.TrimSpace(float))
+	if m == nil {
+		return target
+	}
+	n := len(strings.Split(m[1], "."))
+	parts := strings.Split(strings.SplitN(target, "-", 2)[0], ".")
+	if len(parts) < n {
+		return target
+	}
+	return strings.Join(parts[:n], ".") + ".*"
+}
+
+// resolvedFloating returns the highest stable version a floating version
+// resolves to, which is what a restore would pick.
+func resolvedFloating(float string, versions []string) (string, bool) {
+	var best string
+	for _, v := range versions {
+		if !floatingAllows(float, v) {
+			continue
+		}
+		if best == "" {
+			best = v
+			continue
+		}
+		if cmp, err := resolve.CompareVersions(v, best); err == nil && cmp > 0 {
+			best = v
+		}
+	}
+	return best, best != ""
+}
+
+// Plan determines available updates for package references.
+// It applies policy precedence: CLI flags > uptool.yaml > constraints.
+//
+// An exact Version is a minimum in NuGet, so it does not limit updates. A
+// floating version is compared from the version it currently resolves to and
+// is only updated when the target falls outside it, keeping its precision.
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
+		if err != nil {
+			// Fallback: try to get just the latest version
+			latest, latestErr := i.ds.GetLatestVersion(ctx, dep.Name)
+			if latestErr != nil {
+				// Skip packages we can't query
+				continue
+			}
+			availableVersions = []string{latest}
+		}
+
+		floating := isFloating(dep.Constraint)
+		if floating {
+			if resolved, ok := resolvedFloating(dep.Constraint, availableVersions); ok {
+				dep.CurrentVersion = resolved
+			}
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			"",
+			availableVersions,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" {
+			continue
+		}
+		if floating && newFloating(dep.Constraint, targetVersion) == strings.TrimSpace(dep.Constraint) {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "xml_attribute_rewrite",
+	}, nil
+}
+
+// Apply executes the update by rewriting Version attributes in the project file.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", filepath.Base(plan.Manifest.Path), err)
+	}
+
+	newContent, applied, errs := rewriteReferences(plan, string(oldContent))
+
+	if newContent != string(oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, []byte(newContent), 0o600); err != nil {
+			return nil, fmt.Errorf("write %s: %w", filepath.Base(plan.Manifest.Path), err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(plan.Manifest.Path, string(oldContent), newContent)
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		ManifestDiff:   diff,
+		Errors:         errs,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to project file content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewriteReferences(plan, string(content))
+	return &engine.RewriteResult{
+		Content:        []byte(newContent),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewriteReferences replaces the Version attribute value of every reference
+// matching an update, leaving the rest of each element byte for byte. It
+// returns the names of the dependencies it updated.
+func rewriteReferences(plan *engine.UpdatePlan, content string) (newContent string, applied, errs []string) {
+	newContent = content
+
+	for _, update := range plan.Updates {
+		oldVersion := update.Dependency.Constraint
+		target := version.Normalize(integrationName, update.TargetVersion)
+		if isFloating(oldVersion) {
+			target = newFloating(oldVersion, target)
+		}
+		if err := resolve.ValidateConstraint(integrationName, target); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
+
+		var matches []reference
+		for _, ref := range parseReferences(newContent) {
+			if strings.EqualFold(ref.name, update.Dependency.Name) && ref.version == oldVersion {
+				matches = append(matches, ref)
+			}
+		}
+		if len(matches) == 0 {
+			errs = append(errs, fmt.Sprintf("%s: version %q not found", update.Dependency.Name, oldVersion))
+			continue
+		}
+
+		// Replace from the end so earlier offsets stay valid
+		sort.Slice(matches, func(a, b int) bool { return matches[a].start > matches[b].start })
+		for _, ref := range matches {
+			newContent = newContent[:ref.start] + target + newContent[ref.end:]
+		}
+		applied = append(applied, update.Dependency.Name)
+	}
+
+	return newContent, applied, errs
+}
+
+// Validate checks that the manifest is well-formed XML.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	decoder := xml.NewDecoder(bytes.NewReader(manifest.Content))
+	for {
+		_, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid XML in %s: %w", manifest.Path, err)
+		}
+	}
+}
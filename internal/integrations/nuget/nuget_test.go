@@ -0,0 +1,291 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package nuget
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testProject = `<Project Sdk="Microsoft.NET.Sdk">
+
+  <PropertyGroup>
+    <TargetFramework>net8.0</TargetFramework>
+  </PropertyGroup>
+
+  <ItemGroup>
+    <PackageReference Include="Newtonsoft.Json" Version="13.0.1" />
+    <PackageReference Version="6.0.0"   Include="Serilog"
+                      PrivateAssets="all" IncludeAssets="runtime; build" />
+    <PackageReference Include="xunit" Version="2.4.*" />
+    <PackageReference Include="Polly" Version="[7.0,8.0)" />
+    <PackageReference Include="Dapper" Version="$(DapperVersion)" />
+    <PackageReference Include="Microsoft.Extensions.Http" />
+    <!-- <PackageReference Include="Old.Package" Version="1.0.0" /> -->
+  </ItemGroup>
+
+</Project>
+`
+
+const testCentralPackages = `<Project>
+  <PropertyGroup>
+    <ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally>
+  </PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Microsoft.Extensions.Http" Version="8.0.0" />
+  </ItemGroup>
+</Project>
+`
+
+const testPackagesConfig = `<?xml version="1.0" encoding="utf-8"?>
+<packages>
+  <package id="NUnit" version="3.13.0" targetFramework="net48" />
+</packages>
+`
+
+// mockDatasource implements datasource.Datasource for testing.
+type mockDatasource struct {
+	versions map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if versions, ok := m.versions[pkg]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func writeRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"src/App/App.csproj":                  testProject,
+		"src/App/obj/App.csproj":              testProject,
+		"Directory.Packages.props":            testCentralPackages,
+		"legacy/Legacy/packages.config":       testPackagesConfig,
+		"src/Central/Central.csproj":          `<Project><ItemGroup><PackageReference Include="Serilog" /></ItemGroup></Project>`,
+		"src/App/Properties/launchSettings.x": "{}",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeRepo(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	got := make(map[string]*engine.Manifest)
+	for _, m := range manifests {
+		got[filepath.ToSlash(m.Path)] = m
+	}
+	if len(got) != 3 || got["src/App/App.csproj"] == nil || got["Directory.Packages.props"] == nil || got["legacy/Legacy/packages.config"] == nil {
+		t.Fatalf("Detect() paths = %v, want App.csproj, Directory.Packages.props and packages.config", got)
+	}
+
+	// Ranges, properties, versionless and commented-out references are skipped
+	deps := got["src/App/App.csproj"].Dependencies
+	want := []engine.Dependency{
+		{Name: "Newtonsoft.Json", CurrentVersion: "13.0.1", Constraint: "13.0.1", Type: "package"},
+		{Name: "Serilog", CurrentVersion: "6.0.0", Constraint: "6.0.0", Type: "package"},
+		{Name: "xunit", CurrentVersion: "2.4.0", Constraint: "2.4.*", Type: "package"},
+	}
+	if len(deps) != len(want) {
+		t.Fatalf("Dependencies = %+v, want %+v", deps, want)
+	}
+	for i := range want {
+		if deps[i] != want[i] {
+			t.Errorf("Dependencies[%d] = %+v, want %+v", i, deps[i], want[i])
+		}
+	}
+
+	if d := got["Directory.Packages.props"].Dependencies; len(d) != 1 || d[0].Name != "Microsoft.Extensions.Http" || d[0].CurrentVersion != "8.0.0" {
+		t.Errorf("Directory.Packages.props dependencies = %+v, want Microsoft.Extensions.Http 8.0.0", d)
+	}
+	if d := got["legacy/Legacy/packages.config"].Dependencies; len(d) != 1 || d[0].Name != "NUnit" || d[0].CurrentVersion != "3.13.0" {
+		t.Errorf("packages.config dependencies = %+v, want NUnit 3.13.0", d)
+	}
+}
+
+func TestNewFloating(t *testing.T) {
+	tests := []struct {
+		float, target, want string
+	}{
+		{"2.4.*", "2.9.0", "2.9.*"},
+		{"2.*", "3.1.4", "3.*"},
+		{"1.2.3.*", "1.2.5", "1.2.5.*"},
+		{"2.4.*", "3.0.0-rc.1", "3.0.*"},
+	}
+
+	for _, tt := range tests {
+		if got := newFloating(tt.float, tt.target); got != tt.want {
+			t.Errorf("newFloating(%q, %q) = %q, want %q", tt.float, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestPlan_FloatingVersion(t *testing.T) {
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"xunit": {"2.4.0", "2.4.2", "2.5.0-pre.1"},
+		"nunit": {"3.13.0", "3.14.0", "4.1.0"},
+	}}}
+
+	manifest := &engine.Manifest{
+		Path: "Tests.csproj",
+		Type: integrationName,
+		Dependencies: []engine.Dependency{
+			{Name: "xunit", CurrentVersion: "2.4.0", Constraint: "2.4.*"},
+			{Name: "nunit", CurrentVersion: "3.0.0", Constraint: "3.*"},
+		},
+	}
+
+	plan, err := integ.Plan(context.Background(), manifest, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// 2.4.* already resolves to the newest stable 2.4.x; 3.* moves to 4.*
+	if len(plan.Updates) != 1 {
+		t.Fatalf("Plan() updates = %+v, want only nunit", plan.Updates)
+	}
+	u := plan.Updates[0]
+	if u.Dependency.Name != "nunit" || u.Dependency.CurrentVersion != "3.14.0" || u.TargetVersion != "4.1.0" || u.Impact != "major" {
+		t.Errorf("update = %+v, want nunit 3.14.0 -> 4.1.0 (major)", u)
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeRepo(t)
+	t.Chdir(dir)
+
+	integ := &Integration{ds: &mockDatasource{versions: map[string][]string{
+		"Newtonsoft.Json": {"13.0.1", "13.0.3"},
+		"Serilog":         {"6.0.0", "7.0.0", "8.0.0-dev-1"},
+		"xunit":           {"2.4.2", "2.9.0"},
+	}}}
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	var project *engine.Manifest
+	for _, m := range manifests {
+		if filepath.Base(m.Path) == "App.csproj" {
+			project = m
+		}
+	}
+	if project == nil {
+		t.Fatal("App.csproj not detected")
+	}
+
+	plan, err := integ.Plan(context.Background(), project, nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	if len(plan.Updates) != 3 {
+		t.Fatalf("Plan() updates = %+v, want 3", plan.Updates)
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 3 || len(result.Errors) != 0 {
+		t.Fatalf("Apply() = %+v, want 3 applied and no errors", result)
+	}
+
+	content, _ := os.ReadFile(project.Path)
+	want := strings.NewReplacer(
+		`Include="Newtonsoft.Json" Version="13.0.1"`, `Include="Newtonsoft.Json" Version="13.0.3"`,
+		`Version="6.0.0"   Include="Serilog"`, `Version="7.0.0"   Include="Serilog"`,
+		`Version="2.4.*"`, `Version="2.9.*"`,
+	).Replace(testProject)
+	if string(content) != want {
+		t.Errorf("rewritten project =\n%s\nwant\n%s", content, want)
+	}
+	if !strings.Contains(string(content), "PrivateAssets=\"all\" IncludeAssets=\"runtime; build\" />") {
+		t.Error("PrivateAssets/IncludeAssets attributes not preserved")
+	}
+	if result.ManifestDiff == "" {
+		t.Error("Apply() ManifestDiff is empty")
+	}
+}
+
+func TestApply_PackagesConfig(t *testing.T) {
+	content := []byte(testPackagesConfig)
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "packages.config", Type: integrationName},
+		Updates: []engine.Update{{
+			Dependency:    engine.Dependency{Name: "nunit", CurrentVersion: "3.13.0", Constraint: "3.13.0"},
+			TargetVersion: "3.14.0",
+		}},
+	}
+
+	result, err := New().Rewrite(context.Background(), plan, content)
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if result.Applied != 1 {
+		t.Fatalf("Rewrite() = %+v, want 1 applied", result)
+	}
+	if !strings.Contains(string(result.Content), `<package id="NUnit" version="3.14.0" targetFramework="net48" />`) {
+		t.Errorf("packages.config not rewritten:\n%s", result.Content)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: "App.csproj", Content: []byte(testProject)}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := integ.Validate(context.Background(), &engine.Manifest{Path: "App.csproj", Content: []byte("<Project><ItemGroup></Project>")}); err == nil {
+		t.Error("Validate() expected error for malformed XML")
+	}
+}
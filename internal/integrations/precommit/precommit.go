@@ -61,6 +61,11 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of pre-commit configuration files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{".pre-commit-config.yaml"}
+}
+
 // Config represents the structure of .pre-commit-config.yaml.
 type Config struct {
 	Repos []Repo `yaml:"repos"`
@@ -325,14 +330,18 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 	// Generate diff
 	diff := generateDiff(string(oldContent), string(newContent))
 
-	// Count actual updates from output
-	applied := len(i.parseAutoupdateOutput(string(output), nil))
+	// Report the repositories autoupdate actually bumped
+	var applied []string
+	for _, update := range i.parseAutoupdateOutput(string(output), nil) {
+		applied = append(applied, update.Dependency.Name)
+	}
 
 	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       0,
-		ManifestDiff: diff,
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         0,
+		ManifestDiff:   diff,
 	}, nil
 }
 
@@ -30,6 +30,7 @@ import (
 	"path/filepath"
 	"plugin"
 	"sort"
+	"strings"
 	"sync"
 
 	"github.com/santosr2/uptool/internal/engine"
@@ -40,6 +41,8 @@ var (
 	registry = make(map[string]func() engine.Integration)
 	// instances holds cached integration instances for lazy loading
 	instances = make(map[string]engine.Integration)
+	// fromPlugin records the integrations registered by plugins
+	fromPlugin = make(map[string]bool)
 	// mu protects registry and instances during access
 	mu sync.RWMutex
 	// pluginsLoaded tracks whether plugins have been discovered
@@ -65,6 +68,37 @@ func Register(name string, constructor func() engine.Integration) {
 	registry[name] = constructor
 }
 
+// RegisterOverride adds an integration constructor, replacing any constructor
+// already registered under name instead of panicking. Use it to deliberately
+// supersede a built-in integration, such as with a company-internal npm
+// integration. A warning is printed when an existing registration is replaced
+// and its cached instance is discarded.
+func RegisterOverride(name string, constructor func() engine.Integration) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		fmt.Fprintf(os.Stderr, "Warning: integration %q overridden by a new registration\n", name)
+	}
+
+	registry[name] = constructor
+	delete(instances, name)
+}
+
+// registerIfAbsent adds an integration constructor unless name is already
+// registered, and reports whether it did.
+func registerIfAbsent(name string, constructor func() engine.Integration) bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, exists := registry[name]; exists {
+		return false
+	}
+
+	registry[name] = constructor
+	return true
+}
+
 // Get returns a single integration by name, creating it lazily if needed.
 // This is more efficient than GetAll() when you only need specific integrations.
 func Get(name string) (engine.Integration, error) {
@@ -156,6 +190,15 @@ func List() []string {
 	return names
 }
 
+// IsPlugin reports whether an integration was registered by a plugin rather
+// than compiled into the binary.
+func IsPlugin(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	return fromPlugin[name]
+}
+
 // Count returns the number of registered integrations.
 func Count() int {
 	mu.RLock()
@@ -255,23 +298,102 @@ func loadPlugin(path string) error {
 		return fmt.Errorf("opening plugin: %w", err)
 	}
 
-	// Look for the Register function
-	// Plugin must export a function: func Register(func(string, func() engine.Integration))
-	registerSymbol, err := p.Lookup("RegisterWith")
+	return registerPlugin(p.Lookup)
+}
+
+// registerPlugin registers the integrations of a plugin through its exported
+// RegisterWith and RegisterOverridesWith functions. lookup is typically
+// (*plugin.Plugin).Lookup.
+//
+// Integrations passed to RegisterWith must not be registered yet; duplicates
+// are skipped and reported instead of panicking. Integrations passed to
+// RegisterOverridesWith replace any existing registration, such as a
+// built-in one.
+func registerPlugin(lookup func(string) (plugin.Symbol, error)) error {
+	// Check the plugin was built against a compatible Integration interface
+	// before calling into it, so mismatches fail with a clear message.
+	if _, err := negotiateInterfaceVersion(lookup); err != nil {
+		return err
+	}
+
+	// Plugins export func RegisterWith(func(string, func() engine.Integration)),
+	// RegisterOverridesWith with the same signature, or both
+	registerSymbol, registerErr := lookup("RegisterWith")
+	overrideSymbol, overrideErr := lookup("RegisterOverridesWith")
+	if registerErr != nil && overrideErr != nil {
+		return fmt.Errorf("plugin missing RegisterWith function: %w", registerErr)
+	}
+
+	var duplicates []string
+
+	if registerErr == nil {
+		registerFunc, ok := registerSymbol.(func(func(string, func() engine.Integration)))
+		if !ok {
+			return fmt.Errorf("plugin RegisterWith has wrong signature")
+		}
+
+		// Plugin will call our callback to register its integrations
+		registerFunc(func(name string, constructor func() engine.Integration) {
+			if !registerIfAbsent(name, constructor) {
+				duplicates = append(duplicates, name)
+				return
+			}
+			markPlugin(name)
+		})
+	}
+
+	if overrideErr == nil {
+		overrideFunc, ok := overrideSymbol.(func(func(string, func() engine.Integration)))
+		if !ok {
+			return fmt.Errorf("plugin RegisterOverridesWith has wrong signature")
+		}
+
+		overrideFunc(func(name string, constructor func() engine.Integration) {
+			RegisterOverride(name, constructor)
+			markPlugin(name)
+		})
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("integrations already registered: %s (register them with RegisterOverridesWith to replace the existing ones)",
+			strings.Join(duplicates, ", "))
+	}
+
+	return nil
+}
+
+// markPlugin records that an integration was registered by a plugin.
+func markPlugin(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	fromPlugin[name] = true
+}
+
+// negotiateInterfaceVersion returns the Integration interface version a plugin
+// declares through its exported InterfaceVersion function. Plugins that predate
+// version negotiation don't export it and are treated as MinInterfaceVersion.
+// lookup is typically (*plugin.Plugin).Lookup.
+func negotiateInterfaceVersion(lookup func(string) (plugin.Symbol, error)) (int, error) {
+	symbol, err := lookup("InterfaceVersion")
 	if err != nil {
-		return fmt.Errorf("plugin missing RegisterWith function: %w", err)
+		return engine.MinInterfaceVersion, nil
 	}
 
-	// Call the plugin's RegisterWith function, passing our Register function
-	registerFunc, ok := registerSymbol.(func(func(string, func() engine.Integration)))
+	versionFunc, ok := symbol.(func() int)
 	if !ok {
-		return fmt.Errorf("plugin RegisterWith has wrong signature")
+		return 0, fmt.Errorf("plugin InterfaceVersion has wrong signature (want func() int)")
 	}
 
-	// Plugin will call our Register function to register its integrations
-	registerFunc(Register)
+	version := versionFunc()
+	switch {
+	case version > engine.InterfaceVersion:
+		return 0, fmt.Errorf("plugin requires integration interface v%d, but this uptool supports up to v%d; upgrade uptool", version, engine.InterfaceVersion)
+	case version < engine.MinInterfaceVersion:
+		return 0, fmt.Errorf("plugin was built for integration interface v%d, but this uptool requires at least v%d; rebuild the plugin against a newer uptool", version, engine.MinInterfaceVersion)
+	}
 
-	return nil
+	return version, nil
 }
 
 // ClearCache clears all cached instances, forcing reinitialization on next access.
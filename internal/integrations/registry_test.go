@@ -23,9 +23,15 @@ package integrations
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"plugin"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/santosr2/uptool/internal/engine"
 )
@@ -134,6 +140,88 @@ func TestRegisterPanic(t *testing.T) {
 	})
 }
 
+// isolateRegistry gives a test an empty registry and restores the original one
+// when the test ends.
+func isolateRegistry(t *testing.T) {
+	t.Helper()
+
+	mu.Lock()
+	originalRegistry, originalInstances, originalFromPlugin := registry, instances, fromPlugin
+	registry = make(map[string]func() engine.Integration)
+	instances = make(map[string]engine.Integration)
+	fromPlugin = make(map[string]bool)
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		mu.Lock()
+		registry, instances, fromPlugin = originalRegistry, originalInstances, originalFromPlugin
+		mu.Unlock()
+	})
+}
+
+// captureStderr returns what fn writes to os.Stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = orig }()
+
+	fn()
+	_ = w.Close()
+
+	var buf strings.Builder
+	_, _ = io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestRegisterOverride(t *testing.T) {
+	isolateRegistry(t)
+
+	Register("npm", func() engine.Integration {
+		return &mockIntegration{name: "builtin-npm"}
+	})
+
+	// Instantiate the built-in so the override must drop the cached instance
+	if got, err := Get("npm"); err != nil || got.(*mockIntegration).name != "builtin-npm" {
+		t.Fatalf("Get() before override = %v, %v", got, err)
+	}
+
+	stderr := captureStderr(t, func() {
+		RegisterOverride("npm", func() engine.Integration {
+			return &mockIntegration{name: "internal-npm"}
+		})
+	})
+	if !strings.Contains(stderr, `integration "npm" overridden`) {
+		t.Errorf("RegisterOverride() warning = %q, want override warning", stderr)
+	}
+
+	got, err := Get("npm")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if name := got.(*mockIntegration).name; name != "internal-npm" {
+		t.Errorf("Get() after override = %q, want internal-npm", name)
+	}
+	if Count() != 1 {
+		t.Errorf("Count() = %d, want 1", Count())
+	}
+
+	// Overriding a name that isn't registered just adds it, silently
+	stderr = captureStderr(t, func() {
+		RegisterOverride("fresh", func() engine.Integration {
+			return &mockIntegration{name: "fresh"}
+		})
+	})
+	if stderr != "" {
+		t.Errorf("RegisterOverride() of a new name warned: %q", stderr)
+	}
+}
+
 func TestGet(t *testing.T) {
 	// Save original registry state
 	mu.Lock()
@@ -557,6 +645,144 @@ func TestLoadPluginsFromDir(t *testing.T) {
 	}
 }
 
+func TestNegotiateInterfaceVersion(t *testing.T) {
+	symbols := func(syms map[string]plugin.Symbol) func(string) (plugin.Symbol, error) {
+		return func(name string) (plugin.Symbol, error) {
+			if sym, ok := syms[name]; ok {
+				return sym, nil
+			}
+			return nil, fmt.Errorf("symbol %s not found", name)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		symbols     map[string]plugin.Symbol
+		wantVersion int
+		wantErr     string
+	}{
+		{
+			name:        "legacy plugin without InterfaceVersion",
+			symbols:     map[string]plugin.Symbol{},
+			wantVersion: engine.MinInterfaceVersion,
+		},
+		{
+			name:        "current version",
+			symbols:     map[string]plugin.Symbol{"InterfaceVersion": func() int { return engine.InterfaceVersion }},
+			wantVersion: engine.InterfaceVersion,
+		},
+		{
+			name:    "newer than supported",
+			symbols: map[string]plugin.Symbol{"InterfaceVersion": func() int { return engine.InterfaceVersion + 1 }},
+			wantErr: "upgrade uptool",
+		},
+		{
+			name:    "older than supported",
+			symbols: map[string]plugin.Symbol{"InterfaceVersion": func() int { return engine.MinInterfaceVersion - 1 }},
+			wantErr: "rebuild the plugin",
+		},
+		{
+			name:    "wrong signature",
+			symbols: map[string]plugin.Symbol{"InterfaceVersion": func() string { return "1" }},
+			wantErr: "wrong signature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := negotiateInterfaceVersion(symbols(tt.symbols))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("negotiateInterfaceVersion() error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("negotiateInterfaceVersion() error = %v", err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("negotiateInterfaceVersion() = %d, want %d", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestRegisterPlugin(t *testing.T) {
+	symbols := func(syms map[string]plugin.Symbol) func(string) (plugin.Symbol, error) {
+		return func(name string) (plugin.Symbol, error) {
+			if sym, ok := syms[name]; ok {
+				return sym, nil
+			}
+			return nil, fmt.Errorf("symbol %s not found", name)
+		}
+	}
+	builtin := func() engine.Integration { return &mockIntegration{name: "builtin-npm"} }
+	internal := func() engine.Integration { return &mockIntegration{name: "internal-npm"} }
+
+	t.Run("duplicate via RegisterWith is reported and keeps the built-in", func(t *testing.T) {
+		isolateRegistry(t)
+		Register("npm", builtin)
+
+		err := registerPlugin(symbols(map[string]plugin.Symbol{
+			"RegisterWith": func(register func(string, func() engine.Integration)) {
+				register("npm", internal)
+				register("python", internal)
+			},
+		}))
+		if err == nil || !strings.Contains(err.Error(), "already registered: npm") || !strings.Contains(err.Error(), "RegisterOverridesWith") {
+			t.Fatalf("registerPlugin() error = %v, want duplicate npm reported", err)
+		}
+
+		got, _ := Get("npm")
+		if got.(*mockIntegration).name != "builtin-npm" || IsPlugin("npm") {
+			t.Error("duplicate registration replaced the built-in npm integration")
+		}
+		if !IsPlugin("python") {
+			t.Error("non-duplicate integration of the same plugin was not registered")
+		}
+	})
+
+	t.Run("RegisterOverridesWith replaces the built-in", func(t *testing.T) {
+		isolateRegistry(t)
+		Register("npm", builtin)
+
+		var err error
+		captureStderr(t, func() {
+			err = registerPlugin(symbols(map[string]plugin.Symbol{
+				"RegisterOverridesWith": func(register func(string, func() engine.Integration)) {
+					register("npm", internal)
+				},
+			}))
+		})
+		if err != nil {
+			t.Fatalf("registerPlugin() error = %v", err)
+		}
+
+		got, _ := Get("npm")
+		if got.(*mockIntegration).name != "internal-npm" || !IsPlugin("npm") {
+			t.Error("RegisterOverridesWith did not replace the built-in npm integration")
+		}
+	})
+
+	t.Run("no registration function", func(t *testing.T) {
+		isolateRegistry(t)
+
+		err := registerPlugin(symbols(map[string]plugin.Symbol{}))
+		if err == nil || !strings.Contains(err.Error(), "missing RegisterWith") {
+			t.Errorf("registerPlugin() error = %v, want missing RegisterWith", err)
+		}
+	})
+
+	t.Run("wrong override signature", func(t *testing.T) {
+		isolateRegistry(t)
+
+		err := registerPlugin(symbols(map[string]plugin.Symbol{"RegisterOverridesWith": func() {}}))
+		if err == nil || !strings.Contains(err.Error(), "wrong signature") {
+			t.Errorf("registerPlugin() error = %v, want wrong signature", err)
+		}
+	})
+}
+
 func TestEnsurePluginsLoaded(t *testing.T) {
 	// Save original state
 	mu.Lock()
@@ -654,8 +880,8 @@ func TestValidateFilePath(t *testing.T) {
 
 func TestLoadMetadata(t *testing.T) {
 	// Clear cached metadata for clean test
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	t.Run("loads metadata from integrations.yaml", func(t *testing.T) {
 		// This test will work if integrations.yaml exists in the repo root
@@ -675,7 +901,7 @@ func TestLoadMetadata(t *testing.T) {
 	})
 
 	t.Run("caches metadata", func(t *testing.T) {
-		cachedMetadata = nil
+		ResetMetadataCache()
 		metadata1, err1 := LoadMetadata()
 		if err1 != nil {
 			t.Skip("Skipping cache test - integrations.yaml not available")
@@ -693,8 +919,8 @@ func TestLoadMetadata(t *testing.T) {
 }
 
 func TestGetMetadata(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	t.Run("returns metadata for existing integration", func(t *testing.T) {
 		// Pre-populate cache with test data
@@ -731,8 +957,8 @@ func TestGetMetadata(t *testing.T) {
 }
 
 func TestListIntegrations(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	t.Run("returns all integrations", func(t *testing.T) {
 		cachedMetadata = &RegistryMetadata{
@@ -761,8 +987,8 @@ func TestListIntegrations(t *testing.T) {
 }
 
 func TestListByCategory(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	t.Run("filters by category", func(t *testing.T) {
 		cachedMetadata = &RegistryMetadata{
@@ -806,8 +1032,8 @@ func TestListByCategory(t *testing.T) {
 }
 
 func TestIsDisabled(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	cachedMetadata = &RegistryMetadata{
 		Integrations: map[string]Metadata{
@@ -836,8 +1062,8 @@ func TestIsDisabled(t *testing.T) {
 }
 
 func TestIsExperimental(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	cachedMetadata = &RegistryMetadata{
 		Integrations: map[string]Metadata{
@@ -921,8 +1147,8 @@ func TestFindRegistryFile(t *testing.T) {
 }
 
 func TestLoadMetadata_InvalidYAML(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	// Save current working directory
 	originalWd, err := os.Getwd()
@@ -953,8 +1179,8 @@ func TestLoadMetadata_InvalidYAML(t *testing.T) {
 }
 
 func TestLoadMetadata_ValidYAML(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	// Save current working directory
 	originalWd, err := os.Getwd()
@@ -1000,8 +1226,8 @@ integrations:
 }
 
 func TestListIntegrations_Error(t *testing.T) {
-	cachedMetadata = nil
-	defer func() { cachedMetadata = nil }()
+	ResetMetadataCache()
+	defer ResetMetadataCache()
 
 	// Save current working directory
 	originalWd, err := os.Getwd()
@@ -1029,3 +1255,85 @@ func TestListIntegrations_Error(t *testing.T) {
 	// Error is expected since integrations.yaml doesn't exist
 	_ = err
 }
+
+func TestLoadMetadata_ReloadsChangedFile(t *testing.T) {
+	ResetMetadataCache()
+	defer ResetMetadataCache()
+
+	t.Chdir(t.TempDir())
+	write := func(displayName string, modTime time.Time) {
+		t.Helper()
+		content := "version: \"1.0\"\nintegrations:\n  npm:\n    displayName: \"" + displayName + "\"\n"
+		if err := os.WriteFile("integrations.yaml", []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes("integrations.yaml", modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now().Add(-time.Hour)
+	write("NPM", start)
+	if meta, err := GetMetadata("npm"); err != nil || meta.DisplayName != "NPM" {
+		t.Fatalf("GetMetadata() = %+v, %v; want NPM", meta, err)
+	}
+
+	// An unchanged file is served from the cache
+	first, _ := LoadMetadata()
+	if second, _ := LoadMetadata(); first != second {
+		t.Error("LoadMetadata() reloaded an unchanged file")
+	}
+
+	write("npm (edited)", start.Add(time.Minute))
+	meta, err := GetMetadata("npm")
+	if err != nil {
+		t.Fatalf("GetMetadata() error = %v", err)
+	}
+	if meta.DisplayName != "npm (edited)" {
+		t.Errorf("DisplayName = %q after the file changed, want %q", meta.DisplayName, "npm (edited)")
+	}
+}
+
+func TestResetMetadataCache(t *testing.T) {
+	ResetMetadataCache()
+	defer ResetMetadataCache()
+
+	t.Chdir(t.TempDir())
+	content := "version: \"1.0\"\nintegrations:\n  npm:\n    displayName: \"NPM\"\n"
+	if err := os.WriteFile("integrations.yaml", []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cachedMetadata = &RegistryMetadata{Integrations: map[string]Metadata{"npm": {DisplayName: "seeded"}}}
+	if meta, _ := GetMetadata("npm"); meta == nil || meta.DisplayName != "seeded" {
+		t.Fatalf("GetMetadata() = %+v, want the seeded cache", meta)
+	}
+
+	ResetMetadataCache()
+	if meta, _ := GetMetadata("npm"); meta == nil || meta.DisplayName != "NPM" {
+		t.Errorf("GetMetadata() = %+v after reset, want NPM from integrations.yaml", meta)
+	}
+}
+
+func TestLoadMetadata_Concurrent(t *testing.T) {
+	ResetMetadataCache()
+	defer ResetMetadataCache()
+
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("integrations.yaml", []byte("version: \"1.0\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := LoadMetadata(); err != nil {
+				t.Errorf("LoadMetadata() error = %v", err)
+			}
+			ResetMetadataCache()
+		}()
+	}
+	wg.Wait()
+}
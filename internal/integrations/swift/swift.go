@@ -0,0 +1,455 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package swift implements the Swift Package Manager integration for
+// Package.swift manifests. It reads .package(url:) declarations pinned with
+// from:, .upToNextMajor(from:), .upToNextMinor(from:) or an exact version,
+// resolves the newest matching tag of GitHub-hosted packages, and rewrites the
+// version literal in place. Package.swift is Swift code rather than data, so
+// declarations are matched line by line and everything around the version
+// literal is left untouched. Packages pinned to a branch or revision, local
+// packages and version ranges are skipped.
+package swift
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/rewrite"
+	"github.com/santosr2/uptool/internal/version"
+)
+
+func init() {
+	integrations.Register("swift", func() engine.Integration {
+		return New()
+	})
+}
+
+const (
+	integrationName = "swift"
+	manifestName    = "Package.swift"
+)
+
+// Requirement kinds of a package declaration.
+const (
+	kindFrom  = "from"
+	kindMinor = "upToNextMinor"
+	kindExact = "exact"
+)
+
+var (
+	// manifestPattern matches Package.swift and its version-specific
+	// variants, such as Package@swift-5.9.swift.
+	manifestPattern = regexp.MustCompile(`^Package(@swift-[0-9.]+)?\.swift$`)
+
+	// packagePattern matches a remote package declaration, capturing its URL
+	// and the arguments that follow it.
+	packagePattern = regexp.MustCompile(`\.package\(\s*(?:name:\s*"[^"]*"\s*,\s*)?url:\s*"([^"]+)"\s*,\s*(.*)$`)
+
+	// requirementPattern matches the version requirement that follows the
+	// URL, capturing its form and the version literal.
+	requirementPattern = regexp.MustCompile(`^(from:|\.upToNextMajor\(\s*from:|\.upToNextMinor\(\s*from:|exact:|\.exact\()\s*"([^"]+)"`)
+)
+
+// declaration is a package read from Package.swift, with the byte offsets of
+// its version literal (without quotes).
+type declaration struct {
+	url     string
+	kind    string
+	version string
+	line    int
+	start   int
+	end     int
+}
+
+// tagLister lists the tags of a GitHub repository. It is implemented by the
+// GitHub datasource; without it, releases are used instead.
+type tagLister interface {
+	GetTags(ctx context.Context, repo string) ([]string, error)
+}
+
+// Integration implements Package.swift updates.
+type Integration struct {
+	ds   datasource.Datasource
+	tags tagLister
+}
+
+// New creates a new Swift Package Manager integration.
+func New() *Integration {
+	ds, err := datasource.Get("github-releases")
+	if err != nil {
+		// Fallback to creating a new instance if not registered
+		ds = datasource.NewGitHubDatasource()
+	}
+	tags, _ := ds.(tagLister) //nolint:errcheck // optional capability
+	return &Integration{
+		ds:   datasource.Cached(ds),
+		tags: tags,
+	}
+}
+
+// Name returns the integration identifier.
+func (i *Integration) Name() string {
+	return integrationName
+}
+
+// SupportedFiles returns the file patterns of Swift package manifests.
+func (i *Integration) SupportedFiles() []string {
+	return []string{manifestName, "Package@swift-*.swift"}
+}
+
+// Detect finds Package.swift files in the repository.
+func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Manifest, error) {
+	var manifests []*engine.Manifest
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
+	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// .build/ holds checked-out dependencies and .swiftpm/ Xcode state
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) || (path != repoRoot && (info.Name() == ".build" || info.Name() == ".swiftpm")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !manifestPattern.MatchString(info.Name()) || filter.ShouldSkipFile(path) {
+			return nil
+		}
+
+		if pathErr := integrations.ValidateFilePath(path); pathErr != nil {
+			return pathErr
+		}
+
+		content, err := os.ReadFile(path) // #nosec G304 - path is validated above
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(repoRoot, path)
+		if err != nil {
+			return err
+		}
+
+		manifests = append(manifests, &engine.Manifest{
+			Path:         relPath,
+			Type:         integrationName,
+			Dependencies: dependencies(parsePackages(content)),
+			Content:      content,
+			Metadata:     map[string]any{},
+		})
+		return nil
+	})
+
+	return manifests, err
+}
+
+// parsePackages reads the remote package declarations of a Package.swift in
+// file order. Declarations pinned to a branch or revision, version ranges and
+// commented-out lines are skipped.
+func parsePackages(content []byte) []declaration {
+	var decls []declaration
+
+	offset, line := 0, 0
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		text := scanner.Text()
+		line++
+		lineStart := offset
+		offset += len(text) + 1
+
+		if strings.HasPrefix(strings.TrimSpace(text), "//") {
+			continue
+		}
+
+		m := packagePattern.FindStringSubmatchIndex(text)
+		if m == nil {
+			continue
+		}
+		args := text[m[4]:m[5]]
+		req := requirementPattern.FindStringSubmatchIndex(args)
+		if req == nil {
+			continue
+		}
+
+		kind := kindFrom
+		switch form := args[req[2]:req[3]]; {
+		case strings.HasPrefix(form, ".upToNextMinor"):
+			kind = kindMinor
+		case strings.Contains(form, "exact"):
+			kind = kindExact
+		}
+
+		decls = append(decls, declaration{
+			url:     text[m[2]:m[3]],
+			kind:    kind,
+			version: args[req[4]:req[5]],
+			line:    line,
+			start:   lineStart + m[4] + req[4],
+			end:     lineStart + m[4] + req[5],
+		})
+	}
+
+	return decls
+}
+
+// dependencies returns the dependencies of decls, named by repository URL
+// without scheme or .git suffix (e.g., github.com/apple/swift-log).
+func dependencies(decls []declaration) []engine.Dependency {
+	deps := make([]engine.Dependency, 0, len(decls))
+	for _, d := range decls {
+		deps = append(deps, engine.Dependency{
+			Name:           packageName(d.url),
+			CurrentVersion: d.version,
+			Constraint:     constraintFor(d.kind, d.version),
+			Type:           "direct",
+			Registry:       "github",
+			Line:           d.line,
+		})
+	}
+	return deps
+}
+
+// packageName returns a package URL without scheme, user or .git suffix.
+func packageName(url string) string {
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		url = rest
+	}
+	if _, rest, ok := strings.Cut(url, "@"); ok {
+		url = strings.Replace(rest, ":", "/", 1)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
+}
+
+// githubRepository returns the owner/repo of a package hosted on GitHub.
+func githubRepository(name string) (string, bool) {
+	repo, ok := strings.CutPrefix(name, "github.com/")
+	if !ok || strings.Count(repo, "/") != 1 {
+		return "", false
+	}
+	return repo, true
+}
+
+// constraintFor returns the semver constraint a requirement expresses:
+// from: and .upToNextMajor allow the next major version, .upToNextMinor the
+// next minor, and an exact version nothing else.
+func constraintFor(kind, v string) string {
+	switch kind {
+	case kindFrom:
+		return "^" + v
+	case kindMinor:
+		return "~" + v
+	}
+	return v
+}
+
+// Plan determines available updates for GitHub-hosted packages.
+// It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
+//
+// The planCtx parameter provides the policy context. If nil, default behavior
+// is used (respect constraints only).
+func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planCtx *engine.PlanContext) (*engine.UpdatePlan, error) {
+	updates := make([]engine.Update, 0, len(manifest.Dependencies))
+
+	for _, dep := range manifest.Dependencies {
+		repo, ok := githubRepository(dep.Name)
+		if !ok {
+			continue
+		}
+
+		tags, err := i.versions(ctx, repo)
+		if err != nil || len(tags) == 0 {
+			// Skip packages we can't query
+			continue
+		}
+
+		// An exact version is a pin, so only policy limits how far it moves
+		constraint := dep.Constraint
+		if constraint == dep.CurrentVersion {
+			constraint = ""
+		}
+
+		// Use policy-aware version selection
+		targetVersion, impact, err := resolve.SelectVersionWithContext(
+			dep.CurrentVersion,
+			constraint,
+			tags,
+			planCtx,
+		)
+		if err != nil || targetVersion == "" || targetVersion == dep.CurrentVersion {
+			continue
+		}
+
+		updates = append(updates, engine.Update{
+			Dependency:    dep,
+			TargetVersion: targetVersion,
+			Impact:        string(impact),
+			ChangelogURL:  fmt.Sprintf("https://%s/releases", dep.Name),
+			PolicySource:  planCtx.GetPolicySource(),
+		})
+	}
+
+	return &engine.UpdatePlan{
+		Manifest: manifest,
+		Updates:  updates,
+		Strategy: "package_swift_rewrite",
+	}, nil
+}
+
+// versions returns the versions tagged in a GitHub repository, falling back
+// to its releases when tags cannot be listed.
+func (i *Integration) versions(ctx context.Context, repo string) ([]string, error) {
+	if i.tags != nil {
+		if tags, err := i.tags.GetTags(ctx, repo); err == nil && len(tags) > 0 {
+			versions := make([]string, 0, len(tags))
+			for _, tag := range tags {
+				versions = append(versions, version.Bare(tag))
+			}
+			return versions, nil
+		}
+	}
+	return i.ds.GetVersions(ctx, repo)
+}
+
+// Apply executes the update plan by rewriting version literals in Package.swift.
+func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engine.ApplyResult, error) {
+	if len(plan.Updates) == 0 {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   0,
+		}, nil
+	}
+
+	// Validate path for security
+	if err := integrations.ValidateFilePath(plan.Manifest.Path); err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+
+	oldContent, err := os.ReadFile(plan.Manifest.Path) // #nosec G304 - path is validated above
+	if err != nil {
+		return nil, fmt.Errorf("read Package.swift: %w", err)
+	}
+
+	newContent, applied, errs := rewritePackages(plan, oldContent)
+
+	if !bytes.Equal(newContent, oldContent) {
+		if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+			return nil, fmt.Errorf("write Package.swift: %w", err)
+		}
+	}
+
+	diff, err := rewrite.GenerateUnifiedDiff(filepath.Base(plan.Manifest.Path), string(oldContent), string(newContent))
+	if err != nil {
+		return nil, fmt.Errorf("generate diff: %w", err)
+	}
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to Package.swift content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
+	newContent, applied, errs := rewritePackages(plan, content)
+	return &engine.RewriteResult{
+		Content:        newContent,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+	}, nil
+}
+
+// rewritePackages replaces the version literal of each planned package in
+// content and returns the names of the packages it updated. Only the text
+// inside the quotes changes.
+func rewritePackages(plan *engine.UpdatePlan, content []byte) (newContent []byte, applied, errs []string) {
+	decls := parsePackages(content)
+
+	type edit struct {
+		start, end int
+		value      string
+	}
+	var edits []edit
+
+	for _, update := range plan.Updates {
+		dep := update.Dependency
+		target := version.Bare(update.TargetVersion)
+
+		if err := resolve.ValidateConstraint(integrationName, target); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", dep.Name, err))
+			continue
+		}
+
+		found := false
+		for _, d := range decls {
+			if packageName(d.url) == dep.Name && d.version == dep.CurrentVersion {
+				edits = append(edits, edit{start: d.start, end: d.end, value: target})
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: version %q not found in Package.swift", dep.Name, dep.CurrentVersion))
+			continue
+		}
+		applied = append(applied, dep.Name)
+	}
+
+	// Replace from the end so earlier offsets stay valid
+	sort.Slice(edits, func(a, b int) bool { return edits[a].start > edits[b].start })
+	newContent = content
+	for _, e := range edits {
+		newContent = append(append(append([]byte{}, newContent[:e.start]...), e.value...), newContent[e.end:]...)
+	}
+
+	return newContent, applied, errs
+}
+
+// Validate checks that every package version in Package.swift is a full
+// semantic version, as SwiftPM requires.
+func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
+	for _, d := range parsePackages(manifest.Content) {
+		if err := resolve.ValidateConstraint(integrationName, d.version); err != nil {
+			return fmt.Errorf("package %s: %w", packageName(d.url), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package swift
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+const testPackageSwift = `// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "MyApp",
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-argument-parser", from: "1.2.3"),
+        .package(url: "https://github.com/apple/swift-log.git", .upToNextMajor(from: "1.5.0")),
+        .package(url: "https://github.com/apple/swift-nio.git", .upToNextMinor(from: "2.60.0")),
+        .package(url: "https://github.com/pointfreeco/swift-snapshot-testing", exact: "1.15.0"),
+        .package(name: "Alamofire", url: "git@github.com:Alamofire/Alamofire.git", .exact("5.8.0")),
+        .package(url: "https://github.com/vapor/vapor.git", branch: "main"),
+        .package(url: "https://github.com/groue/GRDB.swift", revision: "0123456789abcdef0123456789abcdef01234567"),
+        .package(url: "https://github.com/onevcat/Kingfisher", "7.0.0"..<"8.0.0"),
+        .package(url: "https://gitlab.com/acme/swift-utils.git", from: "0.3.0"),
+        .package(path: "../LocalKit"),
+        // .package(url: "https://github.com/commented/out", from: "1.0.0"),
+    ],
+    targets: [
+        .executableTarget(name: "MyApp", dependencies: [
+            .product(name: "ArgumentParser", package: "swift-argument-parser"),
+        ]),
+    ]
+)
+`
+
+// mockDatasource implements datasource.Datasource and tagLister for testing.
+// Only swift-log is listed through releases.
+type mockDatasource struct {
+	tags     map[string][]string
+	releases map[string][]string
+}
+
+func (m *mockDatasource) Name() string {
+	return "mock"
+}
+
+func (m *mockDatasource) GetVersions(ctx context.Context, repo string) ([]string, error) {
+	if versions, ok := m.releases[repo]; ok {
+		return versions, nil
+	}
+	return nil, context.Canceled
+}
+
+func (m *mockDatasource) GetLatestVersion(ctx context.Context, repo string) (string, error) {
+	return "", context.Canceled
+}
+
+func (m *mockDatasource) GetPackageInfo(ctx context.Context, repo string) (*datasource.PackageInfo, error) {
+	return nil, nil
+}
+
+func (m *mockDatasource) GetTags(ctx context.Context, repo string) ([]string, error) {
+	if tags, ok := m.tags[repo]; ok {
+		return tags, nil
+	}
+	return nil, context.Canceled
+}
+
+// github mirrors the tags and releases of the test packages.
+func github() *Integration {
+	ds := &mockDatasource{
+		tags: map[string][]string{
+			"apple/swift-argument-parser":        {"2.0.0", "1.3.1", "1.3.0", "1.2.3"},
+			"apple/swift-nio":                    {"2.62.0", "2.61.1", "2.60.0"},
+			"pointfreeco/swift-snapshot-testing": {"1.16.0", "1.15.0"},
+			"Alamofire/Alamofire":                {"5.9.0", "5.8.0"},
+			"vapor/vapor":                        {"4.92.0"},
+			"onevcat/Kingfisher":                 {"7.10.0"},
+		},
+		releases: map[string][]string{
+			"apple/swift-log": {"1.6.1", "1.5.0"},
+		},
+	}
+	return &Integration{ds: ds, tags: ds}
+}
+
+func writeProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		manifestName: testPackageSwift,
+		filepath.Join(".build", "checkouts", "swift-log", manifestName): testPackageSwift,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDetect(t *testing.T) {
+	dir := writeProject(t)
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1 (.build/ skipped)", len(manifests))
+	}
+
+	want := []engine.Dependency{
+		{Name: "github.com/apple/swift-argument-parser", CurrentVersion: "1.2.3", Constraint: "^1.2.3"},
+		{Name: "github.com/apple/swift-log", CurrentVersion: "1.5.0", Constraint: "^1.5.0"},
+		{Name: "github.com/apple/swift-nio", CurrentVersion: "2.60.0", Constraint: "~2.60.0"},
+		{Name: "github.com/pointfreeco/swift-snapshot-testing", CurrentVersion: "1.15.0", Constraint: "1.15.0"},
+		{Name: "github.com/Alamofire/Alamofire", CurrentVersion: "5.8.0", Constraint: "5.8.0"},
+		{Name: "gitlab.com/acme/swift-utils", CurrentVersion: "0.3.0", Constraint: "^0.3.0"},
+	}
+	deps := manifests[0].Dependencies
+	if len(deps) != len(want) {
+		t.Fatalf("Detect() found %d dependencies, want %d (branch, revision, range, path and comments skipped): %+v", len(deps), len(want), deps)
+	}
+	for j, w := range want {
+		if deps[j].Name != w.Name || deps[j].CurrentVersion != w.CurrentVersion || deps[j].Constraint != w.Constraint {
+			t.Errorf("dependency %d = {%q, %q, %q}, want {%q, %q, %q}", j,
+				deps[j].Name, deps[j].CurrentVersion, deps[j].Constraint, w.Name, w.CurrentVersion, w.Constraint)
+		}
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/apple/swift-log.git": "github.com/apple/swift-log",
+		"https://github.com/apple/swift-log":     "github.com/apple/swift-log",
+		"git@github.com:Alamofire/Alamofire.git": "github.com/Alamofire/Alamofire",
+	}
+	for url, want := range tests {
+		if got := packageName(url); got != want {
+			t.Errorf("packageName(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := writeProject(t)
+	t.Chdir(dir)
+
+	integ := github()
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+
+	plan, err := integ.Plan(context.Background(), manifests[0], nil)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	// from: stays within the major version and exact pins move to the newest
+	// tag; swift-nio's newer tags are beyond .upToNextMinor(from: "2.60.0")
+	want := map[string]string{
+		"github.com/apple/swift-argument-parser":        "1.3.1",
+		"github.com/apple/swift-log":                    "1.6.1",
+		"github.com/pointfreeco/swift-snapshot-testing": "1.16.0",
+		"github.com/Alamofire/Alamofire":                "5.9.0",
+	}
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Plan() updates = %v, want %v", got, want)
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("Plan() target for %s = %q, want %q", name, got[name], target)
+		}
+	}
+
+	result, err := integ.Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 4 || result.Failed != 0 {
+		t.Errorf("Apply() = %d applied, %d failed (%v), want 4 applied", result.Applied, result.Failed, result.Errors)
+	}
+
+	// Only version literals change; the branch pin and the rest of the code are untouched
+	content, _ := os.ReadFile(manifestName)
+	wantContent := `// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "MyApp",
+    dependencies: [
+        .package(url: "https://github.com/apple/swift-argument-parser", from: "1.3.1"),
+        .package(url: "https://github.com/apple/swift-log.git", .upToNextMajor(from: "1.6.1")),
+        .package(url: "https://github.com/apple/swift-nio.git", .upToNextMinor(from: "2.60.0")),
+        .package(url: "https://github.com/pointfreeco/swift-snapshot-testing", exact: "1.16.0"),
+        .package(name: "Alamofire", url: "git@github.com:Alamofire/Alamofire.git", .exact("5.9.0")),
+        .package(url: "https://github.com/vapor/vapor.git", branch: "main"),
+        .package(url: "https://github.com/groue/GRDB.swift", revision: "0123456789abcdef0123456789abcdef01234567"),
+        .package(url: "https://github.com/onevcat/Kingfisher", "7.0.0"..<"8.0.0"),
+        .package(url: "https://gitlab.com/acme/swift-utils.git", from: "0.3.0"),
+        .package(path: "../LocalKit"),
+        // .package(url: "https://github.com/commented/out", from: "1.0.0"),
+    ],
+    targets: [
+        .executableTarget(name: "MyApp", dependencies: [
+            .product(name: "ArgumentParser", package: "swift-argument-parser"),
+        ]),
+    ]
+)
+`
+	if string(content) != wantContent {
+		t.Errorf("Package.swift after Apply:\n%s\nwant:\n%s", content, wantContent)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	integ := New()
+
+	valid := &engine.Manifest{Path: manifestName, Content: []byte(testPackageSwift)}
+	if err := integ.Validate(context.Background(), valid); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+
+	invalid := &engine.Manifest{Path: manifestName, Content: []byte(`.package(url: "https://github.com/a/b", from: "1.2"),`)}
+	if err := integ.Validate(context.Background(), invalid); err == nil {
+		t.Error("Validate() should reject a version without a patch component")
+	}
+}
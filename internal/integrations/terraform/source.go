@@ -0,0 +1,207 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package terraform
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// sourceKind classifies where a module source is hosted, and so where its
+// versions are looked up. It is stored as the dependency's Registry.
+type sourceKind string
+
+const (
+	// sourceRegistry modules are versioned by the version argument.
+	sourceRegistry sourceKind = "terraform"
+	// sourceGitHub, sourceGitLab and sourceGit modules are pinned with a
+	// ?ref= query parameter naming a tag of the repository.
+	sourceGitHub sourceKind = "github"
+	sourceGitLab sourceKind = "gitlab"
+	sourceGit    sourceKind = "git"
+)
+
+// moduleSource is a parsed module source address.
+type moduleSource struct {
+	kind sourceKind
+	// address is the source without its query string. It names VCS modules,
+	// since their ref changes with each update.
+	address string
+	// repo is "owner/name" on GitHub, the project path on GitLab, or the clone
+	// URL of other git repositories. It is empty for registry modules.
+	repo string
+	// ref is the ?ref= query parameter of VCS sources.
+	ref string
+}
+
+// parseModuleSource classifies a module source address. Local paths and
+// sources Terraform fetches by other means (archives over HTTP, S3, GCS,
+// Mercurial, ...) are not versioned and return false.
+//
+// Supported forms:
+//
+//	terraform-aws-modules/vpc/aws                      registry
+//	github.com/org/repo//modules/vpc?ref=v1.2.0        GitHub
+//	git::https://github.com/org/repo.git?ref=v1.2.0    GitHub
+//	git@gitlab.com:group/sub/repo.git?ref=v1.2.0       GitLab
+//	git::https://git.example.com/repo.git?ref=v1.2.0   generic git
+func parseModuleSource(source string) (moduleSource, bool) {
+	if source == "" || strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") {
+		return moduleSource{}, false
+	}
+
+	address, query, _ := strings.Cut(source, "?")
+	ref := ""
+	if query != "" {
+		values, err := url.ParseQuery(query)
+		if err != nil {
+			return moduleSource{}, false
+		}
+		ref = values.Get("ref")
+	}
+
+	getter, rest, forced := strings.Cut(address, "::")
+	if !forced {
+		rest = address
+	} else if getter != "git" {
+		return moduleSource{}, false
+	}
+
+	scpLike := strings.HasPrefix(rest, "git@")
+	_, _, hasScheme := strings.Cut(rest, "://")
+
+	host, path := splitRepository(rest)
+	switch {
+	case host == "github.com":
+		parts := strings.Split(path, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return moduleSource{}, false
+		}
+		return moduleSource{kind: sourceGitHub, address: address, repo: path, ref: ref}, true
+	case host == "gitlab.com":
+		if !strings.Contains(path, "/") {
+			return moduleSource{}, false
+		}
+		return moduleSource{kind: sourceGitLab, address: address, repo: path, ref: ref}, true
+	case forced || scpLike:
+		return moduleSource{kind: sourceGit, address: address, repo: cloneURL(rest), ref: ref}, true
+	case hasScheme || strings.HasPrefix(rest, "bitbucket.org/"):
+		// Archives over HTTP(S) and Bitbucket are not resolved
+		return moduleSource{}, false
+	}
+
+	return moduleSource{kind: sourceRegistry, address: address}, true
+}
+
+// splitRepository returns the host and repository path of a git location in
+// shorthand (github.com/org/repo), SCP (git@github.com:org/repo.git) or URL
+// (https://github.com/org/repo.git) form. A "//subdir" suffix and the .git
+// extension are dropped from the path.
+func splitRepository(location string) (host, path string) {
+	location = cloneURL(location)
+
+	switch {
+	case strings.HasPrefix(location, "git@"):
+		host, path, _ = strings.Cut(strings.TrimPrefix(location, "git@"), ":")
+	case strings.Contains(location, "://"):
+		u, err := url.Parse(location)
+		if err != nil {
+			return "", ""
+		}
+		host, path = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+	default:
+		host, path, _ = strings.Cut(location, "/")
+	}
+
+	return host, strings.TrimSuffix(path, ".git")
+}
+
+// cloneURL strips a "//subdir" suffix from a git location.
+func cloneURL(location string) string {
+	scheme, rest, hasScheme := strings.Cut(location, "://")
+	if !hasScheme {
+		scheme, rest = "", location
+	}
+	if before, _, ok := strings.Cut(rest, "//"); ok {
+		rest = before
+	}
+	if hasScheme {
+		return scheme + "://" + rest
+	}
+	return rest
+}
+
+// replaceRef returns source with its ref query parameter changed from oldRef
+// to newRef, leaving other parameters in place.
+func replaceRef(source, oldRef, newRef string) (string, bool) {
+	address, query, ok := strings.Cut(source, "?")
+	if !ok {
+		return "", false
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		if param == "ref="+oldRef || param == "ref="+url.QueryEscape(oldRef) {
+			params[i] = "ref=" + newRef
+			return address + "?" + strings.Join(params, "&"), true
+		}
+	}
+	return "", false
+}
+
+// tagLister lists the tags of a repository.
+type tagLister interface {
+	GetTags(ctx context.Context, repo string) ([]string, error)
+}
+
+// gitTags lists the tags of any git repository with git ls-remote.
+type gitTags struct{}
+
+// GetTags returns the tag names of the repository at cloneURL.
+func (gitTags) GetTags(ctx context.Context, cloneURL string) ([]string, error) {
+	// A URL starting with "-" would be read as an option
+	if strings.HasPrefix(cloneURL, "-") {
+		return nil, fmt.Errorf("invalid repository URL: %s", cloneURL)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--tags", "--refs", cloneURL) // #nosec G204 - URL is validated above and passed as an argument, not to a shell
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-remote %s: %w: %s", cloneURL, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var tags []string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		_, ref, ok := strings.Cut(scanner.Text(), "\t")
+		if tag, isTag := strings.CutPrefix(ref, "refs/tags/"); ok && isTag {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, scanner.Err()
+}
@@ -0,0 +1,280 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package terraform
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/uptool/internal/datasource"
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestParseModuleSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   moduleSource
+		ok     bool
+	}{
+		{
+			source: "terraform-aws-modules/vpc/aws",
+			want:   moduleSource{kind: sourceRegistry, address: "terraform-aws-modules/vpc/aws"},
+			ok:     true,
+		},
+		{
+			source: "app.terraform.io/example/vpc/aws",
+			want:   moduleSource{kind: sourceRegistry, address: "app.terraform.io/example/vpc/aws"},
+			ok:     true,
+		},
+		{
+			source: "github.com/org/modules//vpc?ref=v1.2.0",
+			want:   moduleSource{kind: sourceGitHub, address: "github.com/org/modules//vpc", repo: "org/modules", ref: "v1.2.0"},
+			ok:     true,
+		},
+		{
+			source: "git::https://github.com/org/modules.git?ref=1.2.0&depth=1",
+			want:   moduleSource{kind: sourceGitHub, address: "git::https://github.com/org/modules.git", repo: "org/modules", ref: "1.2.0"},
+			ok:     true,
+		},
+		{
+			source: "git@github.com:org/modules.git?ref=v2",
+			want:   moduleSource{kind: sourceGitHub, address: "git@github.com:org/modules.git", repo: "org/modules", ref: "v2"},
+			ok:     true,
+		},
+		{
+			source: "git::https://gitlab.com/group/sub/modules.git//network?ref=v0.3.0",
+			want:   moduleSource{kind: sourceGitLab, address: "git::https://gitlab.com/group/sub/modules.git//network", repo: "group/sub/modules", ref: "v0.3.0"},
+			ok:     true,
+		},
+		{
+			source: "git::https://git.example.com/infra/modules.git//vpc?ref=v1.0.0",
+			want:   moduleSource{kind: sourceGit, address: "git::https://git.example.com/infra/modules.git//vpc", repo: "https://git.example.com/infra/modules.git", ref: "v1.0.0"},
+			ok:     true,
+		},
+		{
+			source: "git::https://example.com/repo.git",
+			want:   moduleSource{kind: sourceGit, address: "git::https://example.com/repo.git", repo: "https://example.com/repo.git"},
+			ok:     true,
+		},
+		{source: "./modules/vpc"},
+		{source: "../shared"},
+		{source: "https://example.com/vpc-module.zip"},
+		{source: "s3::https://s3-eu-west-1.amazonaws.com/bucket/vpc.zip"},
+		{source: "hg::http://example.com/vpc.hg"},
+		{source: "bitbucket.org/org/modules"},
+	}
+
+	for _, tt := range tests {
+		got, ok := parseModuleSource(tt.source)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseModuleSource(%q) = %+v, %v; want %+v, %v", tt.source, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReplaceRef(t *testing.T) {
+	got, ok := replaceRef("git::https://github.com/org/m.git//vpc?depth=1&ref=v1.2.0", "v1.2.0", "v1.3.0")
+	if !ok || got != "git::https://github.com/org/m.git//vpc?depth=1&ref=v1.3.0" {
+		t.Errorf("replaceRef() = %q, %v", got, ok)
+	}
+	if _, ok := replaceRef("github.com/org/m?ref=v1.0.0", "v1.2.0", "v1.3.0"); ok {
+		t.Error("replaceRef() replaced a different ref")
+	}
+}
+
+const vcsConfig = `module "registry" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+
+module "github" {
+  source = "github.com/org/modules//vpc?ref=v1.2.0"
+}
+
+module "gitlab" {
+  source = "git::https://gitlab.com/group/modules.git?ref=0.3.0"
+}
+
+module "untracked" {
+  source = "github.com/org/modules//dns"
+}
+`
+
+func TestDetect_VCSModules(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(vcsConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := New().Detect(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() found %d manifests, want 1", len(manifests))
+	}
+
+	want := map[string]engine.Dependency{
+		"terraform-aws-modules/vpc/aws": {Name: "terraform-aws-modules/vpc/aws", CurrentVersion: "5.0.0", Constraint: "5.0.0", Type: "module", Registry: "terraform"},
+		"github.com/org/modules//vpc":   {Name: "github.com/org/modules//vpc", CurrentVersion: "v1.2.0", Type: "module", Registry: "github"},
+		"git::https://gitlab.com/group/modules.git": {
+			Name: "git::https://gitlab.com/group/modules.git", CurrentVersion: "0.3.0", Type: "module", Registry: "gitlab",
+		},
+	}
+	deps := manifests[0].Dependencies
+	if len(deps) != len(want) {
+		t.Fatalf("Detect() found %d dependencies, want %d: %+v", len(deps), len(want), deps)
+	}
+	for _, dep := range deps {
+		if dep != want[dep.Name] {
+			t.Errorf("dependency %q = %+v, want %+v", dep.Name, dep, want[dep.Name])
+		}
+	}
+}
+
+func TestPlan_RoutesBySourceType(t *testing.T) {
+	registryDS := &versionsDatasource{versions: map[string][]string{
+		"terraform-aws-modules/vpc/aws": {"5.0.0", "5.1.0"},
+	}}
+	githubDS := &versionsDatasource{versions: map[string][]string{
+		"org/modules": {"1.2.0", "1.4.0"},
+	}}
+	gitlab := tagsByRepo{"group/modules": {"0.3.0", "v0.4.0", "not-a-version"}}
+
+	integ := &Integration{ds: registryDS, github: githubDS, gitlab: gitlab, git: tagsByRepo{}}
+
+	manifest := &engine.Manifest{
+		Path: ".",
+		Type: integrationName,
+		Dependencies: []engine.Dependency{
+			{Name: "terraform-aws-modules/vpc/aws", CurrentVersion: "5.0.0", Constraint: "5.0.0", Type: "module", Registry: "terraform"},
+			{Name: "github.com/org/modules//vpc", CurrentVersion: "v1.2.0", Type: "module", Registry: "github"},
+			{Name: "git::https://gitlab.com/group/modules.git", CurrentVersion: "0.3.0", Type: "module", Registry: "gitlab"},
+		},
+	}
+
+	planCtx := engine.NewPlanContext()
+	planCtx.Policy = &engine.IntegrationPolicy{Update: "major"}
+	plan, err := integ.Plan(context.Background(), manifest, planCtx)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+
+	got := make(map[string]string)
+	for _, u := range plan.Updates {
+		got[u.Dependency.Name] = u.TargetVersion
+	}
+	want := map[string]string{
+		"terraform-aws-modules/vpc/aws":             "5.1.0",
+		"github.com/org/modules//vpc":               "v1.4.0",
+		"git::https://gitlab.com/group/modules.git": "0.4.0",
+	}
+	for name, target := range want {
+		if got[name] != target {
+			t.Errorf("Plan() target for %s = %q, want %q", name, got[name], target)
+		}
+	}
+
+	if registryDS.lookups["org/modules"] != 0 || githubDS.lookups["terraform-aws-modules/vpc/aws"] != 0 {
+		t.Errorf("Plan() queried the wrong datasource: registry %v, github %v", registryDS.lookups, githubDS.lookups)
+	}
+}
+
+func TestApply_RewritesRefs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.tf")
+	if err := os.WriteFile(path, []byte(vcsConfig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: dir, Type: integrationName, Metadata: map[string]any{"files": []string{"main.tf"}}},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "github.com/org/modules//vpc", CurrentVersion: "v1.2.0", Type: "module", Registry: "github"},
+				TargetVersion: "v1.4.0",
+			},
+			{
+				Dependency:    engine.Dependency{Name: "terraform-aws-modules/vpc/aws", CurrentVersion: "5.0.0", Type: "module", Registry: "terraform"},
+				TargetVersion: "5.1.0",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 2 || result.Failed != 0 {
+		t.Fatalf("Apply() applied = %d, failed = %d (%v); want 2, 0", result.Applied, result.Failed, result.Errors)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`source = "github.com/org/modules//vpc?ref=v1.4.0"`,
+		`version = "5.1.0"`,
+		`source = "github.com/org/modules//dns"`,
+		`source = "git::https://gitlab.com/group/modules.git?ref=0.3.0"`,
+	} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("Apply() content missing %q:\n%s", want, content)
+		}
+	}
+}
+
+// versionsDatasource returns fixed versions per package and counts lookups.
+type versionsDatasource struct {
+	versions map[string][]string
+	lookups  map[string]int
+}
+
+func (v *versionsDatasource) Name() string {
+	return "versions"
+}
+
+func (v *versionsDatasource) GetLatestVersion(ctx context.Context, pkg string) (string, error) {
+	return "", nil
+}
+
+func (v *versionsDatasource) GetVersions(ctx context.Context, pkg string) ([]string, error) {
+	if v.lookups == nil {
+		v.lookups = make(map[string]int)
+	}
+	v.lookups[pkg]++
+	return v.versions[pkg], nil
+}
+
+func (v *versionsDatasource) GetPackageInfo(ctx context.Context, pkg string) (*datasource.PackageInfo, error) {
+	return &datasource.PackageInfo{Name: pkg}, nil
+}
+
+// tagsByRepo lists fixed tags per repository.
+type tagsByRepo map[string][]string
+
+func (t tagsByRepo) GetTags(ctx context.Context, repo string) ([]string, error) {
+	return t[repo], nil
+}
@@ -21,6 +21,8 @@
 // Package terraform implements the Terraform integration for updating module versions in .tf files.
 // It detects Terraform configuration files, parses HCL to extract module and provider versions,
 // queries the Terraform Registry for updates, and rewrites versions while preserving HCL formatting.
+// Modules sourced from GitHub, GitLab or other git repositories are pinned by their ?ref= tag,
+// which is resolved against the repository's tags instead of the registry.
 package terraform
 
 import (
@@ -29,6 +31,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/hcl/v2"
@@ -39,7 +42,9 @@ import (
 	"github.com/santosr2/uptool/internal/datasource"
 	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/integrations"
+	"github.com/santosr2/uptool/internal/registry"
 	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/version"
 )
 
 func init() {
@@ -49,24 +54,56 @@ func init() {
 }
 
 const (
-	integrationName = "terraform"
-	blockTypeModule = "module"
+	integrationName   = "terraform"
+	blockTypeModule   = "module"
+	blockTypeVariable = "variable"
 )
 
+// variableVersionsSetting is the policy setting that opts in to updating
+// module versions set through a variable's default.
+const variableVersionsSetting = "variable_versions"
+
+// metadataVariableModules is the manifest metadata key listing the modules
+// whose version is a variable's default.
+const metadataVariableModules = "variable_modules"
+
+// variableRefPattern matches a version expression that is a single variable
+// reference, e.g. var.vpc_version.
+var variableRefPattern = regexp.MustCompile(`^var\.([A-Za-z_][A-Za-z0-9_-]*)$`)
+
+// variableModule is a registry module whose version is the default of a
+// variable declared in the same directory.
+type variableModule struct {
+	Source   string `json:"source"`
+	Variable string `json:"variable"`
+	Default  string `json:"default"`
+}
+
 // Integration implements terraform configuration updates.
 type Integration struct {
-	ds datasource.Datasource
+	ds     datasource.Datasource
+	github datasource.Datasource
+	gitlab tagLister
+	git    tagLister
 }
 
-// New creates a new terraform integration.
+// New creates a new terraform integration. Modules on GitLab use
+// GITLAB_TOKEN for private projects.
 func New() *Integration {
 	ds, err := datasource.Get("terraform")
 	if err != nil {
 		// Fallback to creating a new instance if not registered
 		ds = datasource.NewTerraformDatasource()
 	}
+	gh, err := datasource.Get("github-releases")
+	if err != nil {
+		gh = datasource.NewGitHubDatasource()
+	}
 	return &Integration{
-		ds: ds,
+		ds:     datasource.Cached(ds),
+		github: datasource.Cached(gh),
+		gitlab: registry.NewGitLabClient("gitlab.com", os.Getenv("GITLAB_TOKEN")),
+		git:    gitTags{},
 	}
 }
 
@@ -75,6 +112,11 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of Terraform configuration files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{"*.tf"}
+}
+
 // Config represents terraform configuration structure.
 type Config struct {
 	Remain    hcl.Body        `hcl:",remain"`
@@ -114,17 +156,25 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 	var manifests []*engine.Manifest
 	manifestMap := make(map[string]*engine.Manifest)
 
+	// Module versions set through variables, and variable defaults, by directory
+	variableRefs := make(map[string]map[string]string)
+	variableDefaults := make(map[string]map[string]string)
+
+	filter := engine.NewWalkFilter(ctx, repoRoot)
 	err := filepath.Walk(repoRoot, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip hidden directories
-		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != repoRoot {
-			return filepath.SkipDir
+		// Skips hidden directories such as .terraform without descending into them
+		if info.IsDir() {
+			if filter.ShouldSkipDir(path) {
+				return filepath.SkipDir
+			}
+			return nil
 		}
 
-		if !info.IsDir() && strings.HasSuffix(info.Name(), ".tf") {
+		if strings.HasSuffix(info.Name(), ".tf") && !filter.ShouldSkipFile(path) {
 			dir := filepath.Dir(path)
 			relDir, err := filepath.Rel(repoRoot, dir)
 			if err != nil {
@@ -165,6 +215,15 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 
 			// Extract module dependencies
 			for _, block := range file.Body().Blocks() {
+				if block.Type() == blockTypeVariable {
+					if name, value, ok := variableDefault(block); ok {
+						if variableDefaults[relDir] == nil {
+							variableDefaults[relDir] = make(map[string]string)
+						}
+						variableDefaults[relDir][name] = value
+					}
+					continue
+				}
 				if block.Type() != blockTypeModule {
 					continue
 				}
@@ -176,24 +235,44 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 
 				sourceAttr := block.Body().GetAttribute("source")
 				versionAttr := block.Body().GetAttribute("version")
+				if sourceAttr == nil {
+					continue
+				}
 
-				if sourceAttr != nil && versionAttr != nil {
-					sourceTokens := sourceAttr.Expr().BuildTokens(nil)
-					source := strings.Trim(string(sourceTokens.Bytes()), ` "`)
+				sourceTokens := sourceAttr.Expr().BuildTokens(nil)
+				source := strings.Trim(string(sourceTokens.Bytes()), ` "`)
 
+				src, ok := parseModuleSource(source)
+				switch {
+				case !ok:
+					continue
+				case src.kind == sourceRegistry && versionAttr != nil:
 					versionTokens := versionAttr.Expr().BuildTokens(nil)
 					version := strings.Trim(string(versionTokens.Bytes()), ` "`)
 
-					// Only track registry modules
-					if !strings.HasPrefix(source, ".") && !strings.Contains(source, "git::") {
-						manifest.Dependencies = append(manifest.Dependencies, engine.Dependency{
-							Name:           source,
-							CurrentVersion: version,
-							Constraint:     version, // Store original constraint (e.g., "~> 5.0")
-							Type:           "module",
-							Registry:       "terraform",
-						})
+					if m := variableRefPattern.FindStringSubmatch(version); m != nil {
+						if variableRefs[relDir] == nil {
+							variableRefs[relDir] = make(map[string]string)
+						}
+						variableRefs[relDir][source] = m[1]
+						continue
 					}
+
+					manifest.Dependencies = append(manifest.Dependencies, engine.Dependency{
+						Name:           source,
+						CurrentVersion: version,
+						Constraint:     version, // Store original constraint (e.g., "~> 5.0")
+						Type:           "module",
+						Registry:       string(sourceRegistry),
+					})
+				case src.kind != sourceRegistry && src.ref != "":
+					// VCS modules without a ref follow the default branch
+					manifest.Dependencies = append(manifest.Dependencies, engine.Dependency{
+						Name:           src.address,
+						CurrentVersion: src.ref,
+						Type:           "module",
+						Registry:       string(src.kind),
+					})
 				}
 			}
 
@@ -207,8 +286,11 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 	})
 
 	// Convert map to slice
-	for _, manifest := range manifestMap {
-		if len(manifest.Dependencies) > 0 {
+	for relDir, manifest := range manifestMap {
+		if modules := resolveVariableModules(variableRefs[relDir], variableDefaults[relDir]); len(modules) > 0 {
+			manifest.Metadata[metadataVariableModules] = modules
+		}
+		if len(manifest.Dependencies) > 0 || manifest.Metadata[metadataVariableModules] != nil {
 			manifests = append(manifests, manifest)
 		}
 	}
@@ -216,6 +298,53 @@ func (i *Integration) Detect(ctx context.Context, repoRoot string) ([]*engine.Ma
 	return manifests, err
 }
 
+// variableDefault returns the name and default of a variable block whose
+// default is a plain string.
+func variableDefault(block *hclwrite.Block) (name, value string, ok bool) {
+	labels := block.Labels()
+	defaultAttr := block.Body().GetAttribute("default")
+	if len(labels) == 0 || defaultAttr == nil {
+		return "", "", false
+	}
+
+	raw := strings.TrimSpace(string(defaultAttr.Expr().BuildTokens(nil).Bytes()))
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' || strings.Contains(raw, "${") {
+		return "", "", false
+	}
+	return labels[0], raw[1 : len(raw)-1], true
+}
+
+// resolveVariableModules pairs modules versioned by a variable with the
+// variable's default, sorted by source. Variables without a default are
+// skipped.
+func resolveVariableModules(refs, defaults map[string]string) []variableModule {
+	var modules []variableModule
+	for source, variable := range refs {
+		if value, ok := defaults[variable]; ok && value != "" {
+			modules = append(modules, variableModule{Source: source, Variable: variable, Default: value})
+		}
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Source < modules[j].Source })
+	return modules
+}
+
+// variableModules returns the variable-versioned modules Detect recorded.
+func variableModules(manifest *engine.Manifest) []variableModule {
+	modules, _ := manifest.Metadata[metadataVariableModules].([]variableModule) //nolint:errcheck // metadata set by us
+	return modules
+}
+
+// variableVersionsEnabled reports whether the policy opts in to updating
+// module versions set through variable defaults. The detection is a
+// heuristic, so it is off unless variable_versions is true.
+func variableVersionsEnabled(planCtx *engine.PlanContext) bool {
+	if planCtx == nil || planCtx.Policy == nil {
+		return false
+	}
+	enabled, _ := planCtx.Policy.Custom[variableVersionsSetting].(bool) //nolint:errcheck // non-bool values disable it
+	return enabled
+}
+
 // processDependencyUpdate fetches and compares versions for a dependency.
 // It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
 func (i *Integration) processDependencyUpdate(
@@ -223,6 +352,10 @@ func (i *Integration) processDependencyUpdate(
 	dep *engine.Dependency,
 	planCtx *engine.PlanContext,
 ) (engine.Update, bool) {
+	if isVCS(dep) {
+		return i.processRefUpdate(ctx, dep, planCtx)
+	}
+
 	// Get all available versions from the datasource
 	availableVersions, err := i.ds.GetVersions(ctx, dep.Name)
 	if err != nil {
@@ -254,6 +387,65 @@ func (i *Integration) processDependencyUpdate(
 	}, true
 }
 
+// isVCS reports whether dep is a module pinned by a git ref.
+func isVCS(dep *engine.Dependency) bool {
+	switch sourceKind(dep.Registry) {
+	case sourceGitHub, sourceGitLab, sourceGit:
+		return true
+	}
+	return false
+}
+
+// processRefUpdate resolves a newer tag for a module pinned by a git ref. The
+// tag keeps the "v" prefix of the current ref, if any.
+func (i *Integration) processRefUpdate(
+	ctx context.Context,
+	dep *engine.Dependency,
+	planCtx *engine.PlanContext,
+) (engine.Update, bool) {
+	src, ok := parseModuleSource(dep.Name + "?ref=" + dep.CurrentVersion)
+	if !ok {
+		return engine.Update{}, false
+	}
+
+	var (
+		tags []string
+		err  error
+	)
+	switch src.kind {
+	case sourceGitHub:
+		tags, err = i.github.GetVersions(ctx, src.repo)
+	case sourceGitLab:
+		tags, err = i.gitlab.GetTags(ctx, src.repo)
+	default:
+		tags, err = i.git.GetTags(ctx, src.repo)
+	}
+	if err != nil || len(tags) == 0 {
+		return engine.Update{}, false
+	}
+
+	available := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		available = append(available, version.Bare(tag))
+	}
+
+	current := version.Bare(dep.CurrentVersion)
+	targetVersion, impact, err := resolve.SelectVersionWithContext(current, "", available, planCtx)
+	if err != nil || targetVersion == "" || targetVersion == current {
+		return engine.Update{}, false
+	}
+	if current != dep.CurrentVersion {
+		targetVersion = dep.CurrentVersion[:1] + targetVersion
+	}
+
+	return engine.Update{
+		Dependency:    *dep,
+		TargetVersion: targetVersion,
+		Impact:        string(impact),
+		PolicySource:  planCtx.GetPolicySource(),
+	}, true
+}
+
 // Plan determines available updates for terraform providers and modules.
 // It applies policy precedence: CLI flags > uptool.yaml > manifest constraints.
 //
@@ -270,6 +462,22 @@ func (i *Integration) Plan(ctx context.Context, manifest *engine.Manifest, planC
 		}
 	}
 
+	// Modules versioned by a variable are updated at the variable's default
+	if variableVersionsEnabled(planCtx) {
+		for _, vm := range variableModules(manifest) {
+			dep := engine.Dependency{
+				Name:           vm.Source,
+				CurrentVersion: vm.Default,
+				Constraint:     vm.Default,
+				Type:           blockTypeModule,
+				Registry:       string(sourceRegistry),
+			}
+			if update, ok := i.processDependencyUpdate(ctx, &dep, planCtx); ok {
+				updates = append(updates, update)
+			}
+		}
+	}
+
 	return &engine.UpdatePlan{
 		Manifest: manifest,
 		Updates:  updates,
@@ -287,21 +495,53 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		}, nil
 	}
 
-	// Create update maps for quick lookup
+	// Create update maps for quick lookup, dropping constraints Terraform cannot parse
 	providerUpdates := make(map[string]string)
 	moduleUpdates := make(map[string]string)
+	refUpdates := make(map[string]*engine.Update)
+	var errs []string
 
 	for i := range plan.Updates {
 		update := &plan.Updates[i]
+		// Refs are tags, written exactly as the repository names them
+		if isVCS(&update.Dependency) {
+			refUpdates[update.Dependency.Name] = update
+			continue
+		}
+		target := version.Normalize(integrationName, update.TargetVersion)
+		if err := resolve.ValidateConstraint(integrationName, target); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
 		switch update.Dependency.Type {
 		case "provider":
-			providerUpdates[update.Dependency.Name] = update.TargetVersion
+			providerUpdates[update.Dependency.Name] = target
 		case blockTypeModule:
-			moduleUpdates[update.Dependency.Name] = update.TargetVersion
+			moduleUpdates[update.Dependency.Name] = target
+		}
+	}
+
+	// Variables whose default sets the version of an updated module, and the
+	// modules each one versions
+	variableUpdates := make(map[string]string)
+	variableSources := make(map[string][]string)
+	for _, vm := range variableModules(plan.Manifest) {
+		target, ok := moduleUpdates[vm.Source]
+		if !ok {
+			continue
+		}
+		if existing, shared := variableUpdates[vm.Variable]; shared && existing != target {
+			errs = append(errs, fmt.Sprintf("%s: variable %s is shared with a module updated to %s", vm.Source, vm.Variable, existing))
+			delete(moduleUpdates, vm.Source)
+			continue
 		}
+		variableUpdates[vm.Variable] = target
+		variableSources[vm.Variable] = append(variableSources[vm.Variable], vm.Source)
 	}
 
-	applied := 0
+	// A directory is one manifest but each provider or module is declared in a single
+	// file, so every file is checked and only the declarations it contains are rewritten.
+	appliedUpdates := make(map[string]bool)
 	var allDiffs strings.Builder
 
 	// Get list of files to update
@@ -327,29 +567,32 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 			continue
 		}
 
-		fileUpdated := false
+		// Providers declared in this file's required_providers blocks,
+		// and the updates rewritten in this file
+		var fileProviders, fileApplied []string
 
 		// Update terraform blocks (providers)
 		for _, block := range file.Body().Blocks() {
 			if block.Type() == "terraform" {
 				for _, innerBlock := range block.Body().Blocks() {
-					if innerBlock.Type() == "required_providers" {
-						// Update each provider in required_providers
-						for name := range providerUpdates {
-							// Extract provider name from source (e.g., "hashicorp/aws" -> "aws")
-							providerName := name
-							if strings.Contains(name, "/") {
-								parts := strings.Split(name, "/")
-								providerName = parts[len(parts)-1]
-							}
-
-							providerAttr := innerBlock.Body().GetAttribute(providerName)
-							if providerAttr != nil {
-								// This is a complex attribute, need to update the version within it
-								// For now, we'll use string replacement as HCL doesn't provide easy nested updates
-								fileUpdated = true
-								applied++
-							}
+					if innerBlock.Type() != "required_providers" {
+						continue
+					}
+					for name := range providerUpdates {
+						if innerBlock.Body().GetAttribute(providerLocalName(name)) != nil {
+							fileProviders = append(fileProviders, name)
+						}
+					}
+				}
+			}
+
+			// Update variable defaults that set module versions
+			if block.Type() == blockTypeVariable {
+				if name, _, ok := variableDefault(block); ok {
+					if newVersion, updated := variableUpdates[name]; updated {
+						block.Body().SetAttributeValue("default", cty.StringVal(newVersion))
+						for _, source := range variableSources[name] {
+							fileApplied = append(fileApplied, blockTypeModule+"/"+source)
 						}
 					}
 				}
@@ -373,50 +616,106 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 
 				if newVersion, ok := moduleUpdates[source]; ok {
 					versionAttr := block.Body().GetAttribute("version")
-					if versionAttr != nil {
+					if versionAttr != nil && !isVariableRef(versionAttr) {
 						block.Body().SetAttributeValue("version", cty.StringVal(newVersion))
-						fileUpdated = true
-						applied++
+						fileApplied = append(fileApplied, blockTypeModule+"/"+source)
 					}
 				}
-			}
-		}
-
-		if fileUpdated {
-			// Write updated content
-			newContent := file.Bytes()
 
-			// For provider versions, use regex replacement since HCL doesn't support nested updates easily
-			for providerSource, newVersion := range providerUpdates {
-				providerName := providerSource
-				if strings.Contains(providerSource, "/") {
-					parts := strings.Split(providerSource, "/")
-					providerName = parts[len(parts)-1]
+				address, _, _ := strings.Cut(source, "?")
+				if update, ok := refUpdates[address]; ok {
+					if newSource, replaced := replaceRef(source, update.Dependency.CurrentVersion, update.TargetVersion); replaced {
+						block.Body().SetAttributeValue("source", cty.StringVal(newSource))
+						fileApplied = append(fileApplied, blockTypeModule+"/"+address)
+					}
 				}
-
-				// Match: provider_name = { ... version = "old_version" ... }
-				re := regexp.MustCompile(fmt.Sprintf(`(%s\s*=\s*\{[^}]*version\s*=\s*)"([^"]*)"`, providerName))
-				newContent = re.ReplaceAll(newContent, []byte(fmt.Sprintf(`${1}%q`, newVersion)))
 			}
+		}
+
+		newContent := file.Bytes()
 
-			if err := os.WriteFile(filePath, newContent, 0o600); err != nil {
+		// For provider versions, use regex replacement since HCL doesn't support nested updates easily
+		for _, providerSource := range fileProviders {
+			// Match: provider_name = { ... version = "old_version" ... }
+			re := regexp.MustCompile(fmt.Sprintf(`(?m)(^\s*%s\s*=\s*\{[^}]*version\s*=\s*)"([^"]*)"`,
+				regexp.QuoteMeta(providerLocalName(providerSource))))
+			if !re.Match(newContent) {
 				continue
 			}
+			newContent = re.ReplaceAll(newContent, []byte(fmt.Sprintf(`${1}%q`, providerUpdates[providerSource])))
+			fileApplied = append(fileApplied, "provider/"+providerSource)
+		}
+
+		if string(newContent) == string(oldContent) {
+			continue
+		}
+
+		// Write updated content
+		if err := os.WriteFile(filePath, newContent, 0o600); err != nil {
+			errs = append(errs, fmt.Sprintf("write %s: %v", filename, err))
+			continue
+		}
+
+		for _, key := range fileApplied {
+			appliedUpdates[key] = true
+		}
 
-			// Generate diff for this file
-			diff := generateDiff(filename, string(oldContent), string(newContent))
-			allDiffs.WriteString(diff)
+		// Generate diff for this file
+		diff := generateDiff(filename, string(oldContent), string(newContent))
+		allDiffs.WriteString(diff)
+	}
+
+	// Report updates that no file in the directory declares
+	for name := range providerUpdates {
+		if !appliedUpdates["provider/"+name] {
+			errs = append(errs, fmt.Sprintf("%s: provider not declared in any file of %s", name, plan.Manifest.Path))
+		}
+	}
+	for name := range moduleUpdates {
+		if !appliedUpdates[blockTypeModule+"/"+name] {
+			errs = append(errs, fmt.Sprintf("%s: module not declared in any file of %s", name, plan.Manifest.Path))
+		}
+	}
+	for name, update := range refUpdates {
+		if !appliedUpdates[blockTypeModule+"/"+name] {
+			errs = append(errs, fmt.Sprintf("%s: module with ref %s not declared in any file of %s", name, update.Dependency.CurrentVersion, plan.Manifest.Path))
+		}
+	}
+	sort.Strings(errs)
+
+	var applied []string
+	for i := range plan.Updates {
+		dep := &plan.Updates[i].Dependency
+		if appliedUpdates[dep.Type+"/"+dep.Name] {
+			applied = append(applied, dep.Name)
 		}
 	}
 
 	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       0,
-		ManifestDiff: allDiffs.String(),
+		Manifest:       plan.Manifest,
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
+		ManifestDiff:   allDiffs.String(),
 	}, nil
 }
 
+// isVariableRef reports whether an attribute's value is a single variable reference.
+func isVariableRef(attr *hclwrite.Attribute) bool {
+	expr := strings.TrimSpace(string(attr.Expr().BuildTokens(nil).Bytes()))
+	return variableRefPattern.MatchString(expr)
+}
+
+// providerLocalName returns the local name used in required_providers for a provider source.
+// For example, "hashicorp/aws" returns "aws".
+func providerLocalName(source string) string {
+	if idx := strings.LastIndex(source, "/"); idx >= 0 {
+		return source[idx+1:]
+	}
+	return source
+}
+
 // Validate checks if the terraform configuration is valid.
 func (i *Integration) Validate(ctx context.Context, manifest *engine.Manifest) error {
 	// Basic HCL validation
@@ -577,6 +577,143 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApply_DistributesUpdatesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	providers := `terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "4.0.0"
+    }
+  }
+}
+`
+	vpc := `module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "3.0.0"
+
+  providers = {
+    aws = aws
+  }
+}
+`
+	outputs := `output "vpc_id" {
+  value = module.vpc.vpc_id
+}
+`
+	for name, content := range map[string]string{
+		"providers.tf": providers,
+		"vpc.tf":       vpc,
+		"outputs.tf":   outputs,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path: dir,
+			Type: "terraform",
+			Metadata: map[string]any{
+				"files": []string{"outputs.tf", "providers.tf", "vpc.tf"},
+			},
+		},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "hashicorp/aws", CurrentVersion: "4.0.0", Type: "provider"},
+				TargetVersion: "5.0.0",
+			},
+			{
+				Dependency:    engine.Dependency{Name: "terraform-aws-modules/vpc/aws", CurrentVersion: "3.0.0", Type: "module"},
+				TargetVersion: "5.1.0",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 2 || result.Failed != 0 {
+		t.Errorf("Apply() applied = %d, failed = %d (%v); want 2, 0", result.Applied, result.Failed, result.Errors)
+	}
+
+	read := func(name string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	gotProviders := read("providers.tf")
+	if !strings.Contains(gotProviders, `version = "5.0.0"`) {
+		t.Errorf("providers.tf not updated:\n%s", gotProviders)
+	}
+	if strings.Contains(gotProviders, "5.1.0") {
+		t.Errorf("providers.tf received the module update:\n%s", gotProviders)
+	}
+
+	gotVPC := read("vpc.tf")
+	if !strings.Contains(gotVPC, `version = "5.1.0"`) {
+		t.Errorf("vpc.tf not updated:\n%s", gotVPC)
+	}
+	if strings.Contains(gotVPC, "5.0.0") {
+		t.Errorf("vpc.tf received the provider update:\n%s", gotVPC)
+	}
+
+	if got := read("outputs.tf"); got != outputs {
+		t.Errorf("outputs.tf was modified:\n%s", got)
+	}
+
+	if !strings.Contains(result.ManifestDiff, "providers.tf") || !strings.Contains(result.ManifestDiff, "vpc.tf") {
+		t.Errorf("ManifestDiff should cover both edited files:\n%s", result.ManifestDiff)
+	}
+	if strings.Contains(result.ManifestDiff, "outputs.tf") {
+		t.Errorf("ManifestDiff should not include unchanged files:\n%s", result.ManifestDiff)
+	}
+}
+
+func TestApply_ReportsUndeclaredUpdates(t *testing.T) {
+	dir := t.TempDir()
+	content := `module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "3.0.0"
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "vpc.tf"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &engine.UpdatePlan{
+		Manifest: &engine.Manifest{
+			Path:     dir,
+			Type:     "terraform",
+			Metadata: map[string]any{"files": []string{"vpc.tf"}},
+		},
+		Updates: []engine.Update{
+			{
+				Dependency:    engine.Dependency{Name: "hashicorp/aws", CurrentVersion: "4.0.0", Type: "provider"},
+				TargetVersion: "5.0.0",
+			},
+		},
+	}
+
+	result, err := New().Apply(context.Background(), plan)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if result.Applied != 0 || result.Failed != 1 {
+		t.Errorf("Apply() applied = %d, failed = %d; want 0, 1", result.Applied, result.Failed)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "hashicorp/aws") {
+		t.Errorf("Apply() errors = %v, want one error naming hashicorp/aws", result.Errors)
+	}
+}
+
 func TestGenerateDiff(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -827,3 +964,130 @@ func TestPlan_WithPlanContext(t *testing.T) {
 		t.Fatal("Plan() returned nil")
 	}
 }
+
+func TestDetect_Gitignored(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := []byte(`module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+`)
+	for _, dir := range []string{".", "scratch", ".terraform/modules/vpc"} {
+		if err := os.MkdirAll(filepath.Join(tmpDir, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, dir, "main.tf"), content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, ".gitignore"), []byte("scratch/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifests, err := New().Detect(context.Background(), tmpDir)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Path != "." {
+		paths := make([]string, 0, len(manifests))
+		for _, m := range manifests {
+			paths = append(paths, m.Path)
+		}
+		t.Errorf("Detect() paths = %v, want only the root module (gitignored and .terraform skipped)", paths)
+	}
+}
+
+func TestVariableVersions(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	main := `module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = var.vpc_version
+}
+
+module "eks" {
+  source  = "terraform-aws-modules/eks/aws"
+  version = "19.0.0"
+}
+`
+	variables := `variable "vpc_version" {
+  description = "Version of the VPC module"
+  type        = string
+  default     = "5.0.0"
+}
+`
+	for name, content := range map[string]string{"main.tf": main, "variables.tf": variables} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	integ := &Integration{ds: &versionsDatasource{versions: map[string][]string{
+		"terraform-aws-modules/vpc/aws": {"5.0.0", "5.1.0"},
+		"terraform-aws-modules/eks/aws": {"19.0.0"},
+	}}}
+
+	manifests, err := integ.Detect(context.Background(), ".")
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if len(manifests) != 1 {
+		t.Fatalf("Detect() = %d manifests, want 1", len(manifests))
+	}
+	manifest := manifests[0]
+	manifest.Path = dir
+
+	// The variable-driven module is recorded, not reported as a dependency
+	for _, dep := range manifest.Dependencies {
+		if dep.Name == "terraform-aws-modules/vpc/aws" {
+			t.Errorf("variable-driven module reported as dependency: %+v", dep)
+		}
+	}
+	wantModules := []variableModule{{Source: "terraform-aws-modules/vpc/aws", Variable: "vpc_version", Default: "5.0.0"}}
+	if got := variableModules(manifest); len(got) != 1 || got[0] != wantModules[0] {
+		t.Fatalf("variable modules = %+v, want %+v", got, wantModules)
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		planCtx := engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Update: "major"})
+		plan, err := integ.Plan(context.Background(), manifest, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 0 {
+			t.Errorf("Plan() updates = %+v, want none without %s", plan.Updates, variableVersionsSetting)
+		}
+	})
+
+	t.Run("updates the variable default", func(t *testing.T) {
+		planCtx := engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+			Update: "major",
+			Custom: map[string]interface{}{variableVersionsSetting: true},
+		})
+		plan, err := integ.Plan(context.Background(), manifest, planCtx)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		if len(plan.Updates) != 1 || plan.Updates[0].TargetVersion != "5.1.0" {
+			t.Fatalf("Plan() updates = %+v, want vpc to 5.1.0", plan.Updates)
+		}
+
+		result, err := integ.Apply(context.Background(), plan)
+		if err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		if result.Applied != 1 || len(result.Errors) != 0 {
+			t.Fatalf("Apply() = %+v, want 1 applied and no errors", result)
+		}
+
+		gotVariables, _ := os.ReadFile("variables.tf")
+		if want := strings.Replace(variables, `"5.0.0"`, `"5.1.0"`, 1); string(gotVariables) != want {
+			t.Errorf("variables.tf =\n%s\nwant\n%s", gotVariables, want)
+		}
+		gotMain, _ := os.ReadFile("main.tf")
+		if string(gotMain) != main {
+			t.Errorf("main.tf changed, want the variable reference kept:\n%s", gotMain)
+		}
+	})
+}
@@ -40,6 +40,7 @@ import (
 	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/integrations"
 	"github.com/santosr2/uptool/internal/resolve"
+	"github.com/santosr2/uptool/internal/version"
 )
 
 func init() {
@@ -63,7 +64,7 @@ func New() *Integration {
 		ds = datasource.NewGitHubDatasource()
 	}
 	return &Integration{
-		ds: ds,
+		ds: datasource.Cached(ds),
 	}
 }
 
@@ -72,6 +73,11 @@ func (i *Integration) Name() string {
 	return integrationName
 }
 
+// SupportedFiles returns the file patterns of tflint configuration files.
+func (i *Integration) SupportedFiles() []string {
+	return []string{".tflint.hcl"}
+}
+
 // Config represents .tflint.hcl structure.
 type Config struct {
 	Remain  hcl.Body `hcl:",remain"`
@@ -269,24 +275,73 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 		return nil, fmt.Errorf("read config: %w", err)
 	}
 
+	rewritten, err := i.Rewrite(ctx, plan, oldContent)
+	if err != nil {
+		return nil, err
+	}
+	// Leave the file alone when no target version could be written
+	if rewritten.Failed == len(plan.Updates) {
+		return &engine.ApplyResult{
+			Manifest: plan.Manifest,
+			Applied:  0,
+			Failed:   rewritten.Failed,
+			Errors:   rewritten.Errors,
+		}, nil
+	}
+
+	// Write updated content
+	newContent := rewritten.Content
+	if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
+		return nil, fmt.Errorf("write config: %w", err)
+	}
+
+	// Generate diff
+	diff := generateDiff(string(oldContent), string(newContent))
+
+	return &engine.ApplyResult{
+		Manifest:       plan.Manifest,
+		AppliedUpdates: rewritten.AppliedUpdates,
+		Applied:        rewritten.Applied,
+		Failed:         rewritten.Failed,
+		Errors:         rewritten.Errors,
+		ManifestDiff:   diff,
+	}, nil
+}
+
+// Rewrite applies the plan's updates to .tflint.hcl content in memory.
+func (i *Integration) Rewrite(ctx context.Context, plan *engine.UpdatePlan, content []byte) (*engine.RewriteResult, error) {
 	// Parse HCL for writing
-	file, diags := hclwrite.ParseConfig(oldContent, plan.Manifest.Path, hcl.Pos{Line: 1, Column: 1})
+	file, diags := hclwrite.ParseConfig(content, plan.Manifest.Path, hcl.Pos{Line: 1, Column: 1})
 	if diags.HasErrors() {
 		return nil, fmt.Errorf("parse HCL for writing: %s", diags.Error())
 	}
 
-	// Create update map for quick lookup
+	// Create update map for quick lookup, dropping versions tflint cannot parse
 	updateMap := make(map[string]string)
+	var errs []string
 	for i := range plan.Updates {
 		update := &plan.Updates[i]
-		updateMap[update.Dependency.Name] = update.TargetVersion
+		target := version.Normalize(integrationName, update.TargetVersion)
+		if err := resolve.ValidateConstraint(integrationName, target); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", update.Dependency.Name, err))
+			continue
+		}
+		updateMap[update.Dependency.Name] = target
+	}
+
+	if len(updateMap) == 0 {
+		return &engine.RewriteResult{
+			Content: content,
+			Failed:  len(plan.Updates),
+			Errors:  errs,
+		}, nil
 	}
 
-	applied := 0
+	var applied []string
 
 	// Parse config to get source values
 	var config Config
-	if err := hclsimple.Decode(plan.Manifest.Path, oldContent, nil, &config); err != nil {
+	if err := hclsimple.Decode(plan.Manifest.Path, content, nil, &config); err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
 
@@ -330,25 +385,17 @@ func (i *Integration) Apply(ctx context.Context, plan *engine.UpdatePlan) (*engi
 			if versionAttr != nil {
 				// Update version
 				block.Body().SetAttributeValue("version", cty.StringVal(newVersion))
-				applied++
+				applied = append(applied, source)
 			}
 		}
 	}
 
-	// Write updated content
-	newContent := file.Bytes()
-	if err := os.WriteFile(plan.Manifest.Path, newContent, 0o600); err != nil {
-		return nil, fmt.Errorf("write config: %w", err)
-	}
-
-	// Generate diff
-	diff := generateDiff(string(oldContent), string(newContent))
-
-	return &engine.ApplyResult{
-		Manifest:     plan.Manifest,
-		Applied:      applied,
-		Failed:       0,
-		ManifestDiff: diff,
+	return &engine.RewriteResult{
+		Content:        file.Bytes(),
+		AppliedUpdates: applied,
+		Applied:        len(applied),
+		Failed:         len(errs),
+		Errors:         errs,
 	}, nil
 }
 
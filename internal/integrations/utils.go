@@ -25,6 +25,8 @@ import (
 	"fmt"
 	"path/filepath"
 	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
 )
 
 // ValidateFilePath validates that a file path is safe to read/write.
@@ -40,3 +42,13 @@ func ValidateFilePath(path string) error {
 
 	return nil
 }
+
+// DependencyNames returns the dependency name of each update, as reported in
+// ApplyResult.AppliedUpdates.
+func DependencyNames(updates []*engine.Update) []string {
+	names := make([]string, 0, len(updates))
+	for _, update := range updates {
+		names = append(names, update.Dependency.Name)
+	}
+	return names
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package jsonschema generates JSON Schemas from Go types using their json or
+// yaml struct tags, so the schemas published for uptool's output and
+// configuration follow the structs as they evolve.
+package jsonschema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema document or subschema. Only the keywords needed to
+// describe Go types are modelled; fields are ordered as they are printed.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Tag selects the struct tag that names properties.
+type Tag string
+
+// Supported struct tags.
+const (
+	// JSON describes documents written with encoding/json. Fields without
+	// omitempty are always present, so they are listed as required, and
+	// pointers without omitempty may be null.
+	JSON Tag = "json"
+
+	// YAML describes documents read with yaml.v3, where every field is
+	// optional and ",inline" maps accept arbitrary extra keys.
+	YAML Tag = "yaml"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generate returns the schema for the type of v. Named struct types are
+// placed in $defs and referenced, which also handles recursive types.
+func Generate(v any, tag Tag, title string) *Schema {
+	g := &generator{tag: tag, defs: make(map[string]*Schema), refs: make(map[string]int)}
+	root := g.schemaFor(reflect.TypeOf(v))
+
+	// Inline the root definition so the document describes v directly. It
+	// stays in $defs only when the type refers to itself.
+	if root.Ref != "" {
+		name := strings.TrimPrefix(root.Ref, "#/$defs/")
+		def := *g.defs[name]
+		if g.refs[name] == 1 {
+			delete(g.defs, name)
+		}
+		root = &def
+	}
+
+	root.Schema = Draft
+	root.Title = title
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+type generator struct {
+	defs map[string]*Schema
+	refs map[string]int
+	tag  Tag
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		// Interfaces and anything else accept any value
+		return &Schema{}
+	}
+}
+
+// structRef returns a reference to the definition of a named struct,
+// generating it on first use. Anonymous structs are described inline.
+func (g *generator) structRef(t reflect.Type) *Schema {
+	if t.Name() == "" {
+		return g.structSchema(t)
+	}
+
+	name := t.Name()
+	g.refs[name]++
+	if _, ok := g.defs[name]; !ok {
+		// Reserve the name first so recursive references terminate
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *g.structSchema(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the properties of struct t to s, flattening embedded
+// structs and inline fields the way the encoders do.
+func (g *generator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		name, opts, tagged := parseTag(field.Tag.Get(string(g.tag)))
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		inline := opts.has("inline") || (field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct)
+		if inline {
+			switch fieldType.Kind() {
+			case reflect.Struct:
+				g.addFields(s, fieldType)
+			case reflect.Map:
+				s.AdditionalProperties = g.schemaFor(fieldType.Elem())
+			}
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if !tagged || name == "" {
+			name = defaultName(field.Name, g.tag)
+		}
+
+		prop := g.schemaFor(field.Type)
+		omitempty := opts.has("omitempty")
+		if g.tag == JSON && !omitempty {
+			s.Required = append(s.Required, name)
+			if field.Type.Kind() == reflect.Pointer {
+				prop = &Schema{AnyOf: []*Schema{prop, {Type: "null"}}}
+			}
+		}
+		s.Properties[name] = prop
+	}
+}
+
+// defaultName is the property name an encoder uses for an untagged field.
+func defaultName(field string, tag Tag) string {
+	if tag == YAML {
+		return strings.ToLower(field)
+	}
+	return field
+}
+
+type tagOptions string
+
+func (o tagOptions) has(option string) bool {
+	for _, opt := range strings.Split(string(o), ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTag splits a struct tag value into its name and options. tagged is
+// false when the field has no tag for the generator's encoder.
+func parseTag(tag string) (name string, opts tagOptions, tagged bool) {
+	if tag == "" {
+		return "", "", false
+	}
+	name, rest, _ := strings.Cut(tag, ",")
+	return name, tagOptions(rest), true
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package jsonschema
+
+import (
+	"testing"
+	"time"
+)
+
+type node struct {
+	Parent   *node          `json:"parent"`
+	Created  time.Time      `json:"created"`
+	Labels   map[string]int `json:"labels,omitempty"`
+	Name     string         `json:"name"`
+	Children []node         `json:"children,omitempty"`
+	Raw      []byte         `json:"raw,omitempty"`
+	Ignored  string         `json:"-"`
+	hidden   string
+}
+
+func TestGenerate_JSON(t *testing.T) {
+	s := Generate(node{}, JSON, "node")
+
+	if s.Schema != Draft || s.Title != "node" || s.Type != "object" {
+		t.Fatalf("root = %+v", s)
+	}
+	if _, ok := s.Defs["node"]; !ok {
+		t.Error("recursive type has no definition to reference")
+	}
+
+	tests := map[string]func(*Schema) bool{
+		"name":     func(p *Schema) bool { return p.Type == "string" },
+		"created":  func(p *Schema) bool { return p.Type == "string" && p.Format == "date-time" },
+		"labels":   func(p *Schema) bool { return p.Type == "object" && p.AdditionalProperties.Type == "integer" },
+		"children": func(p *Schema) bool { return p.Type == "array" && p.Items.Ref == "#/$defs/node" },
+		"raw":      func(p *Schema) bool { return p.Type == "string" && p.Format == "byte" },
+		"parent": func(p *Schema) bool {
+			return len(p.AnyOf) == 2 && p.AnyOf[0].Ref == "#/$defs/node" && p.AnyOf[1].Type == "null"
+		},
+	}
+	for name, ok := range tests {
+		prop, found := s.Properties[name]
+		if !found {
+			t.Errorf("property %q missing", name)
+			continue
+		}
+		if !ok(prop) {
+			t.Errorf("property %q = %+v", name, prop)
+		}
+	}
+
+	for _, name := range []string{"Ignored", "-", "hidden"} {
+		if _, found := s.Properties[name]; found {
+			t.Errorf("property %q should not be present", name)
+		}
+	}
+
+	want := []string{"parent", "created", "name"}
+	if len(s.Required) != len(want) {
+		t.Fatalf("required = %v, want %v", s.Required, want)
+	}
+	for i := range want {
+		if s.Required[i] != want[i] {
+			t.Errorf("required = %v, want %v", s.Required, want)
+		}
+	}
+}
+
+type base struct {
+	ID string `yaml:"id"`
+}
+
+type config struct {
+	base    `yaml:",inline"`
+	Extra   map[string]any `yaml:",inline"`
+	Enabled *bool          `yaml:"enabled"`
+	Timeout int
+}
+
+func TestGenerate_YAML(t *testing.T) {
+	s := Generate(config{}, YAML, "config")
+
+	for name, wantType := range map[string]string{"id": "string", "enabled": "boolean", "timeout": "integer"} {
+		prop, ok := s.Properties[name]
+		if !ok || prop.Type != wantType {
+			t.Errorf("property %q = %+v, want type %s", name, prop, wantType)
+		}
+	}
+	if s.AdditionalProperties == nil {
+		t.Error("inline map should allow additional properties")
+	}
+	if len(s.Required) != 0 {
+		t.Errorf("yaml properties are optional, got required %v", s.Required)
+	}
+}
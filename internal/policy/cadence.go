@@ -28,6 +28,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/santosr2/uptool/internal/engine"
 	"github.com/santosr2/uptool/internal/secureio"
 )
 
@@ -47,17 +48,51 @@ func (cs *CadenceState) ShouldCheckForUpdates(manifestPath, cadence string) bool
 		return true // Never checked before
 	}
 
-	now := time.Now()
-	switch cadence {
-	case "daily":
-		return now.Sub(lastCheck) >= 24*time.Hour
-	case "weekly":
-		return now.Sub(lastCheck) >= 7*24*time.Hour
-	case "monthly":
-		return now.Sub(lastCheck) >= 30*24*time.Hour
-	default:
+	interval, ok := cadenceIntervals[cadence]
+	if !ok {
 		return true // Unknown cadence, allow check
 	}
+	return time.Since(lastCheck) >= interval
+}
+
+// cadenceIntervals is the minimum time between checks for each cadence.
+var cadenceIntervals = map[string]time.Duration{
+	"daily":   24 * time.Hour,
+	"weekly":  7 * 24 * time.Hour,
+	"monthly": 30 * 24 * time.Hour,
+}
+
+// IsDue reports whether key is due for a run at now under policy p. A key
+// never checked before is always due. A schedule is due once its next run
+// after the last check has passed; otherwise the cadence interval must have
+// elapsed since the last check. Without either, every run is due.
+func (cs *CadenceState) IsDue(key string, p *engine.IntegrationPolicy, now time.Time) (bool, error) {
+	lastCheck, exists := cs.LastChecked[key]
+	if !exists || p == nil {
+		return true, nil
+	}
+
+	if p.Schedule != nil && p.Schedule.Interval != "" {
+		next, err := p.Schedule.NextRun(lastCheck)
+		if err != nil {
+			return false, err
+		}
+		return !now.Before(next), nil
+	}
+
+	interval, ok := cadenceIntervals[p.Cadence]
+	if !ok {
+		return true, nil
+	}
+	return now.Sub(lastCheck) >= interval, nil
+}
+
+// MarkCheckedAt records that key was checked at t.
+func (cs *CadenceState) MarkCheckedAt(key string, t time.Time) {
+	if cs.LastChecked == nil {
+		cs.LastChecked = make(map[string]time.Time)
+	}
+	cs.LastChecked[key] = t
 }
 
 // MarkChecked records that a manifest was checked at the current time.
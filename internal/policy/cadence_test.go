@@ -25,6 +25,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
 )
 
 func TestCadenceState_ShouldCheckForUpdates(t *testing.T) {
@@ -289,3 +291,53 @@ func TestCadenceState_ShouldCheckForUpdates_ZeroTime(t *testing.T) {
 		t.Error("ShouldCheckForUpdates() should return true for zero time (never checked)")
 	}
 }
+
+func TestCadenceState_IsDue(t *testing.T) {
+	now := time.Date(2026, 3, 11, 12, 0, 0, 0, time.UTC) // a Wednesday
+	weekly := &engine.IntegrationPolicy{Cadence: "weekly"}
+	scheduled := &engine.IntegrationPolicy{Schedule: &engine.Schedule{Interval: "daily", Time: "09:00", Timezone: "UTC"}}
+
+	tests := []struct {
+		policy      *engine.IntegrationPolicy
+		lastChecked *time.Time
+		name        string
+		want        bool
+	}{
+		{name: "never checked", policy: weekly, want: true},
+		{name: "weekly checked 2 days ago", policy: weekly, lastChecked: ptrTime(now.Add(-48 * time.Hour)), want: false},
+		{name: "weekly checked 8 days ago", policy: weekly, lastChecked: ptrTime(now.Add(-8 * 24 * time.Hour)), want: true},
+		{name: "no policy", lastChecked: ptrTime(now.Add(-time.Minute)), want: true},
+		{name: "no cadence", policy: &engine.IntegrationPolicy{}, lastChecked: ptrTime(now.Add(-time.Minute)), want: true},
+		{name: "schedule run passed", policy: scheduled, lastChecked: ptrTime(now.Add(-4 * time.Hour)), want: true},
+		{name: "schedule run pending", policy: scheduled, lastChecked: ptrTime(now.Add(-2 * time.Hour)), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cs := &CadenceState{LastChecked: make(map[string]time.Time)}
+			if tt.lastChecked != nil {
+				cs.MarkCheckedAt("npm", *tt.lastChecked)
+			}
+			got, err := cs.IsDue("npm", tt.policy, now)
+			if err != nil {
+				t.Fatalf("IsDue() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCadenceState_IsDue_InvalidSchedule(t *testing.T) {
+	cs := &CadenceState{}
+	cs.MarkCheckedAt("npm", time.Now().Add(-time.Hour))
+	p := &engine.IntegrationPolicy{Schedule: &engine.Schedule{Interval: "daily", Timezone: "Mars/Olympus"}}
+	if _, err := cs.IsDue("npm", p, time.Now()); err == nil {
+		t.Error("IsDue() expected error for invalid timezone")
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
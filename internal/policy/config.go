@@ -69,6 +69,7 @@ package policy
 
 import (
 	"fmt"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
@@ -98,6 +99,10 @@ type Config struct {
 	// This field is optional - if omitted, no org-level policies are enforced.
 	OrgPolicy *OrgPolicy `yaml:"org_policy,omitempty"`
 
+	// RegistriesFromDependabot is the path of a dependabot.yml whose private
+	// registry definitions are imported into the registry clients.
+	RegistriesFromDependabot string `yaml:"registries_from_dependabot,omitempty"`
+
 	// Integrations contains per-integration configuration (update policies, file patterns).
 	// Each integration can be individually enabled/disabled and configured with its own policy.
 	Integrations []IntegrationConfig `yaml:"integrations"`
@@ -249,6 +254,15 @@ func ValidateIntegrationPolicy(p *engine.IntegrationPolicy) error {
 		return fmt.Errorf("invalid update strategy %q (must be: none, patch, minor, major)", p.Update)
 	}
 
+	validSegments := map[string]bool{
+		"":      true,
+		"patch": true,
+		"minor": true,
+	}
+	if !validSegments[p.Segment] {
+		return fmt.Errorf("invalid segment %q (must be: patch, minor)", p.Segment)
+	}
+
 	validCadences := map[string]bool{
 		"":        true,
 		"daily":   true,
@@ -273,6 +287,13 @@ func ValidateIntegrationPolicy(p *engine.IntegrationPolicy) error {
 		}
 	}
 
+	// Validate prerelease channels
+	for _, channel := range p.PrereleaseChannels {
+		if !isPrereleaseChannel(channel) {
+			return fmt.Errorf("invalid prerelease channel %q (must be letters only, e.g. rc, beta)", channel)
+		}
+	}
+
 	// Validate open pull requests limit
 	if p.OpenPullRequestsLimit < 0 || p.OpenPullRequestsLimit > 10 {
 		if p.OpenPullRequestsLimit != 0 {
@@ -294,6 +315,13 @@ func ValidateIntegrationPolicy(p *engine.IntegrationPolicy) error {
 		}
 	}
 
+	// Validate repositories
+	for i, repo := range p.Repositories {
+		if repo.URL == "" {
+			return fmt.Errorf("repositories[%d]: url is required", i)
+		}
+	}
+
 	// Validate commit message
 	if p.CommitMessage != nil {
 		if err := validateCommitMessage(p.CommitMessage); err != nil {
@@ -324,13 +352,39 @@ func validateSchedule(s *engine.Schedule) error {
 		}
 	}
 
-	if s.Interval == "cron" && s.Cron == "" {
-		return fmt.Errorf("cron expression is required when interval is 'cron'")
+	if s.Interval == "cron" {
+		if s.Cron == "" {
+			return fmt.Errorf("cron expression is required when interval is 'cron'")
+		}
+		if _, err := engine.ParseCron(s.Cron); err != nil {
+			return err
+		}
+	} else if s.Cron != "" {
+		return fmt.Errorf("cron expression %q is only used when interval is 'cron'", s.Cron)
+	}
+
+	if s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+		}
 	}
 
 	return nil
 }
 
+// isPrereleaseChannel reports whether channel is a valid prerelease channel name.
+func isPrereleaseChannel(channel string) bool {
+	if channel == "" {
+		return false
+	}
+	for _, r := range channel {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
 // validateVersioningStrategy validates a versioning strategy.
 func validateVersioningStrategy(strategy string) error {
 	valid := map[string]bool{
@@ -105,6 +105,22 @@ func TestValidateIntegrationPolicy(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "patch segment",
+			policy: engine.IntegrationPolicy{
+				Update:  "major",
+				Segment: "patch",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid segment",
+			policy: engine.IntegrationPolicy{
+				Update:  "minor",
+				Segment: "major",
+			},
+			wantErr: true,
+		},
 		{
 			name: "all valid update strategies",
 			policy: engine.IntegrationPolicy{
@@ -112,6 +128,22 @@ func TestValidateIntegrationPolicy(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid prerelease channels",
+			policy: engine.IntegrationPolicy{
+				Update:             "minor",
+				PrereleaseChannels: []string{"rc", "beta"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid prerelease channel",
+			policy: engine.IntegrationPolicy{
+				Update:             "minor",
+				PrereleaseChannels: []string{"rc.1"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -611,6 +643,47 @@ func TestValidateIntegrationPolicy_Schedule(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "malformed cron expression",
+			schedule: &engine.Schedule{
+				Interval: "cron",
+				Cron:     "0 9 * *",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cron field out of range",
+			schedule: &engine.Schedule{
+				Interval: "cron",
+				Cron:     "0 25 * * 1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "cron expression without cron interval",
+			schedule: &engine.Schedule{
+				Interval: "weekly",
+				Cron:     "0 9 * * 1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid timezone",
+			schedule: &engine.Schedule{
+				Interval: "daily",
+				Time:     "09:00",
+				Timezone: "Europe/Lisbon",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid timezone",
+			schedule: &engine.Schedule{
+				Interval: "daily",
+				Timezone: "Mars/Olympus_Mons",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -797,6 +870,24 @@ func TestValidateIntegrationPolicy_Cooldown(t *testing.T) {
 	}
 }
 
+func TestValidateIntegrationPolicy_Repositories(t *testing.T) {
+	valid := &engine.IntegrationPolicy{
+		Update:       "minor",
+		Repositories: []engine.RepositoryConfig{{URL: "https://charts.example.com", VerifyChecksum: true}},
+	}
+	if err := ValidateIntegrationPolicy(valid); err != nil {
+		t.Errorf("ValidateIntegrationPolicy() error = %v, want nil", err)
+	}
+
+	missing := &engine.IntegrationPolicy{
+		Update:       "minor",
+		Repositories: []engine.RepositoryConfig{{VerifyChecksum: true}},
+	}
+	if err := ValidateIntegrationPolicy(missing); err == nil {
+		t.Error("ValidateIntegrationPolicy() expected error for repository without url")
+	}
+}
+
 func TestValidateIntegrationPolicy_CommitMessage(t *testing.T) {
 	tests := []struct {
 		commitMessage *engine.CommitMessageConfig
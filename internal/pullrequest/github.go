@@ -0,0 +1,174 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pullrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/registry"
+)
+
+const githubAPIURL = "https://api.github.com"
+
+// Publisher opens pull requests and returns their URLs.
+type Publisher interface {
+	Publish(ctx context.Context, req Request) (string, error)
+}
+
+// gitRunner runs a git command and returns its combined output.
+type gitRunner func(ctx context.Context, args ...string) (string, error)
+
+// GitHub commits each request to its branch with git, pushes it to origin and
+// opens the pull request through the GitHub REST API.
+type GitHub struct {
+	client  *http.Client
+	git     gitRunner
+	baseURL string
+	repo    string
+	token   string
+}
+
+// NewGitHub creates a GitHub publisher for the repository in the working
+// directory. It reads the repository ("owner/name") from GITHUB_REPOSITORY,
+// the token from GITHUB_TOKEN and, for GitHub Enterprise, the API URL from
+// GITHUB_API_URL.
+func NewGitHub() (*GitHub, error) {
+	repo := os.Getenv("GITHUB_REPOSITORY")
+	if strings.Count(repo, "/") != 1 {
+		return nil, fmt.Errorf("GITHUB_REPOSITORY must be set to owner/name")
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN must be set to create pull requests")
+	}
+	baseURL := os.Getenv("GITHUB_API_URL")
+	if baseURL == "" {
+		baseURL = githubAPIURL
+	}
+
+	return &GitHub{
+		client:  registry.NewHTTPClient("github"),
+		git:     runGit,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		repo:    repo,
+		token:   token,
+	}, nil
+}
+
+// runGit runs git in the working directory.
+func runGit(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...) // #nosec G204 - arguments are built by uptool, not a shell
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w\n%s", args[0], err, output)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Publish commits req.Files on req.Branch, starting from the current branch,
+// pushes the branch and opens a pull request against the current branch.
+// Files outside req.Files stay uncommitted in the working tree, so several
+// requests can be published from one set of applied updates.
+func (g *GitHub) Publish(ctx context.Context, req Request) (string, error) {
+	base, err := g.git(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := g.git(ctx, "checkout", "-B", req.Branch); err != nil {
+		return "", err
+	}
+
+	commitErr := g.commit(ctx, req)
+	if _, err := g.git(ctx, "checkout", base); err != nil && commitErr == nil {
+		commitErr = err
+	}
+	if commitErr != nil {
+		return "", commitErr
+	}
+
+	return g.open(ctx, req, base)
+}
+
+// commit stages and commits the request's files, then pushes the branch.
+func (g *GitHub) commit(ctx context.Context, req Request) error {
+	if _, err := g.git(ctx, append([]string{"add", "--"}, req.Files...)...); err != nil {
+		return err
+	}
+	if _, err := g.git(ctx, "commit", "-m", req.Title); err != nil {
+		return err
+	}
+	_, err := g.git(ctx, "push", "--force", "origin", req.Branch)
+	return err
+}
+
+// open creates the pull request and returns its URL.
+func (g *GitHub) open(ctx context.Context, req Request, base string) (string, error) {
+	payload, err := json.Marshal(map[string]string{
+		"title": req.Title,
+		"head":  req.Branch,
+		"base":  base,
+		"body":  req.Body,
+	})
+	if err != nil {
+		return "", fmt.Errorf("encode pull request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/pulls", g.baseURL, g.repo)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/vnd.github+json")
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+g.token)
+
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		return "", fmt.Errorf("create pull request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("create pull request: unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(body, &created); err != nil {
+		return "", fmt.Errorf("parse response: %w", err)
+	}
+	return created.HTMLURL, nil
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+// Package pullrequest builds and opens pull requests for applied updates.
+// Updates are either split into one pull request per group (the update group,
+// or the manifest when ungrouped) or combined into a single batch pull request
+// whose body has one section per manifest.
+package pullrequest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+// DefaultBranch is the branch prefix used when none is configured.
+const DefaultBranch = "uptool/dependency-updates"
+
+// DefaultTitle is the pull request title used when none is configured.
+const DefaultTitle = "chore: update dependencies"
+
+// Request describes a pull request to open.
+type Request struct {
+	Branch string
+	Title  string
+	Body   string
+	// Files are the paths, relative to the repository root, committed on Branch.
+	Files []string
+	// Plans are the applied plans included in the pull request.
+	Plans []*engine.UpdatePlan
+}
+
+// Batch combines every applied plan into a single pull request.
+func Batch(plans []*engine.UpdatePlan, branch, title string) Request {
+	sorted := sortedPlans(plans)
+	return Request{
+		Branch: branch,
+		Title:  title,
+		Body:   Body(sorted),
+		Files:  planFiles(sorted),
+		Plans:  sorted,
+	}
+}
+
+// PerGroup opens one pull request per group. Manifests whose updates all share
+// an update group are combined under that group; other manifests get a pull
+// request of their own. Branches are named "<branch>/<group or manifest>".
+func PerGroup(plans []*engine.UpdatePlan, branch, title string) []Request {
+	groups := make(map[string][]*engine.UpdatePlan)
+	for _, p := range plans {
+		key := planGroup(p)
+		groups[key] = append(groups[key], p)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	requests := make([]Request, 0, len(keys))
+	for _, key := range keys {
+		req := Batch(groups[key], branch+"/"+branchSuffix(key), fmt.Sprintf("%s (%s)", title, key))
+		requests = append(requests, req)
+	}
+	return requests
+}
+
+// Body renders the markdown body of a pull request, with one section per
+// manifest listing its updates. An Age column is added when any update
+// records release times (--fetch-info).
+func Body(plans []*engine.UpdatePlan) string {
+	var b strings.Builder
+	b.WriteString("## 📦 Dependency Updates\n\n")
+	b.WriteString("This PR updates dependencies to their latest versions.\n\n")
+
+	withAge := hasAge(plans)
+	total, major, minor, patch := 0, 0, 0, 0
+	for _, p := range plans {
+		fmt.Fprintf(&b, "### %s\n\n", strings.TrimPrefix(p.Manifest.Path, "./"))
+		if withAge {
+			b.WriteString("| Package | Update | Type | Age | Links |\n")
+			b.WriteString("|---------|--------|------|-----|-------|\n")
+		} else {
+			b.WriteString("| Package | Update | Type | Links |\n")
+			b.WriteString("|---------|--------|------|-------|\n")
+		}
+		for i := range p.Updates {
+			u := &p.Updates[i]
+			link := "N/A"
+			if u.ChangelogURL != "" {
+				link = "[Changelog](" + u.ChangelogURL + ")"
+			}
+			fmt.Fprintf(&b, "| **%s** | `%s` → `%s` | %s |",
+				u.Dependency.Name, u.Dependency.CurrentVersion, u.TargetVersion, ImpactLabel(u.Impact))
+			if withAge {
+				fmt.Fprintf(&b, " %s |", AgeLabel(u.Age))
+			}
+			fmt.Fprintf(&b, " %s |\n", link)
+
+			total++
+			switch u.Impact {
+			case string(engine.ImpactMajor):
+				major++
+			case string(engine.ImpactMinor):
+				minor++
+			case string(engine.ImpactPatch):
+				patch++
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("---\n\n")
+	b.WriteString("### 📊 Update Summary\n\n")
+	fmt.Fprintf(&b, "- **Total updates:** %d\n", total)
+	fmt.Fprintf(&b, "- **Manifests affected:** %d\n", len(plans))
+	fmt.Fprintf(&b, "- **Update types:** 🔴 %d major · 🟡 %d minor · 🟢 %d patch\n", major, minor, patch)
+
+	return b.String()
+}
+
+// AgeLabel describes how old the current version of an update is and when its
+// target was released, e.g. "412 days old → released 2024-05-01". Unknown
+// parts are left out; with no release times at all it is "N/A".
+func AgeLabel(age *engine.UpdateAge) string {
+	if age == nil {
+		return "N/A"
+	}
+
+	var parts []string
+	if !age.CurrentReleasedAt.IsZero() {
+		unit := "days"
+		if age.CurrentAgeDays == 1 {
+			unit = "day"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s old", age.CurrentAgeDays, unit))
+	}
+	if !age.TargetReleasedAt.IsZero() {
+		parts = append(parts, "released "+age.TargetReleasedAt.Format("2006-01-02"))
+	}
+	if len(parts) == 0 {
+		return "N/A"
+	}
+	return strings.Join(parts, " → ")
+}
+
+// hasAge reports whether any update of plans records release times.
+func hasAge(plans []*engine.UpdatePlan) bool {
+	for _, p := range plans {
+		for i := range p.Updates {
+			if p.Updates[i].Age != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ImpactLabel matches the impact labels used by the GitHub Action.
+func ImpactLabel(impact string) string {
+	switch impact {
+	case string(engine.ImpactMajor):
+		return "🔴 Major"
+	case string(engine.ImpactMinor):
+		return "🟡 Minor"
+	case string(engine.ImpactPatch):
+		return "🟢 Patch"
+	default:
+		return "⚪ " + impact
+	}
+}
+
+// planGroup returns the update group shared by all of a plan's updates, or the
+// manifest path when they are ungrouped or in different groups.
+func planGroup(p *engine.UpdatePlan) string {
+	group := ""
+	for i := range p.Updates {
+		g := p.Updates[i].Group
+		if g == "" || (group != "" && g != group) {
+			return p.Manifest.Path
+		}
+		group = g
+	}
+	if group == "" {
+		return p.Manifest.Path
+	}
+	return group
+}
+
+// branchSuffix turns a group or manifest path into a branch name component.
+func branchSuffix(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == '/':
+			return r
+		default:
+			return '-'
+		}
+	}, strings.TrimPrefix(key, "./"))
+}
+
+// planFiles returns the manifests and lockfiles written for plans.
+func planFiles(plans []*engine.UpdatePlan) []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, p := range plans {
+		add(p.Manifest.Path)
+		if lockfile, ok := p.Manifest.Metadata["lockfile"].(string); ok {
+			add(lockfile)
+		}
+	}
+	return files
+}
+
+// sortedPlans returns plans ordered by manifest path.
+func sortedPlans(plans []*engine.UpdatePlan) []*engine.UpdatePlan {
+	sorted := append([]*engine.UpdatePlan(nil), plans...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Manifest.Path < sorted[j].Manifest.Path
+	})
+	return sorted
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package pullrequest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func testPlans() []*engine.UpdatePlan {
+	return []*engine.UpdatePlan{
+		{
+			Manifest: &engine.Manifest{Path: "package.json", Type: "npm"},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "express", CurrentVersion: "4.18.0"}, TargetVersion: "4.19.2", Impact: "minor"},
+				{Dependency: engine.Dependency{Name: "react", CurrentVersion: "17.0.2"}, TargetVersion: "18.2.0", Impact: "major",
+					ChangelogURL: "https://github.com/facebook/react/releases"},
+			},
+		},
+		{
+			Manifest: &engine.Manifest{Path: "ios/Podfile", Type: "cocoapods", Metadata: map[string]interface{}{"lockfile": "ios/Podfile.lock"}},
+			Updates: []engine.Update{
+				{Dependency: engine.Dependency{Name: "Alamofire", CurrentVersion: "5.6.4"}, TargetVersion: "5.6.5", Impact: "patch"},
+			},
+		},
+	}
+}
+
+func TestBatch(t *testing.T) {
+	req := Batch(testPlans(), DefaultBranch, DefaultTitle)
+
+	if req.Branch != DefaultBranch || req.Title != DefaultTitle {
+		t.Errorf("Batch() branch/title = %q/%q", req.Branch, req.Title)
+	}
+
+	wantFiles := []string{"ios/Podfile", "ios/Podfile.lock", "package.json"}
+	if !reflect.DeepEqual(req.Files, wantFiles) {
+		t.Errorf("Batch() files = %v, want %v", req.Files, wantFiles)
+	}
+
+	want := "## 📦 Dependency Updates\n\n" +
+		"This PR updates dependencies to their latest versions.\n\n" +
+		"### ios/Podfile\n\n" +
+		"| Package | Update | Type | Links |\n" +
+		"|---------|--------|------|-------|\n" +
+		"| **Alamofire** | `5.6.4` → `5.6.5` | 🟢 Patch | N/A |\n\n" +
+		"### package.json\n\n" +
+		"| Package | Update | Type | Links |\n" +
+		"|---------|--------|------|-------|\n" +
+		"| **express** | `4.18.0` → `4.19.2` | 🟡 Minor | N/A |\n" +
+		"| **react** | `17.0.2` → `18.2.0` | 🔴 Major | [Changelog](https://github.com/facebook/react/releases) |\n\n" +
+		"---\n\n" +
+		"### 📊 Update Summary\n\n" +
+		"- **Total updates:** 3\n" +
+		"- **Manifests affected:** 2\n" +
+		"- **Update types:** 🔴 1 major · 🟡 1 minor · 🟢 1 patch\n"
+	if req.Body != want {
+		t.Errorf("Batch() body =\n%s\nwant\n%s", req.Body, want)
+	}
+}
+
+func TestBody_Age(t *testing.T) {
+	plans := testPlans()[:1]
+	plans[0].Updates[0].Age = &engine.UpdateAge{
+		CurrentReleasedAt: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+		TargetReleasedAt:  time.Date(2024, 3, 25, 0, 0, 0, 0, time.UTC),
+		CurrentAgeDays:    412,
+	}
+
+	body := Body(plans)
+	for _, want := range []string{
+		"| Package | Update | Type | Age | Links |\n",
+		"| **express** | `4.18.0` → `4.19.2` | 🟡 Minor | 412 days old → released 2024-03-25 | N/A |\n",
+		"| **react** | `17.0.2` → `18.2.0` | 🔴 Major | N/A | [Changelog](https://github.com/facebook/react/releases) |\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Body() missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestAgeLabel(t *testing.T) {
+	released := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		age  *engine.UpdateAge
+		want string
+	}{
+		{age: nil, want: "N/A"},
+		{age: &engine.UpdateAge{}, want: "N/A"},
+		{age: &engine.UpdateAge{TargetReleasedAt: released}, want: "released 2024-05-01"},
+		{age: &engine.UpdateAge{CurrentReleasedAt: released, CurrentAgeDays: 1}, want: "1 day old"},
+		{age: &engine.UpdateAge{CurrentReleasedAt: released, CurrentAgeDays: 0, TargetReleasedAt: released}, want: "0 days old → released 2024-05-01"},
+	}
+
+	for _, tt := range tests {
+		if got := AgeLabel(tt.age); got != tt.want {
+			t.Errorf("AgeLabel(%+v) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestPerGroup(t *testing.T) {
+	plans := testPlans()
+	plans = append(plans, &engine.UpdatePlan{
+		Manifest: &engine.Manifest{Path: "web/package.json", Type: "npm"},
+		Updates: []engine.Update{
+			{Dependency: engine.Dependency{Name: "lodash"}, TargetVersion: "4.17.21", Group: "frontend"},
+		},
+	})
+	plans[0].Updates[0].Group = "frontend"
+	plans[0].Updates[1].Group = "frontend"
+
+	requests := PerGroup(plans, "deps", "chore: update")
+	if len(requests) != 2 {
+		t.Fatalf("PerGroup() returned %d requests, want 2", len(requests))
+	}
+
+	if requests[0].Branch != "deps/frontend" || !reflect.DeepEqual(requests[0].Files, []string{"package.json", "web/package.json"}) {
+		t.Errorf("PerGroup()[0] = %q %v, want deps/frontend with both package.json files", requests[0].Branch, requests[0].Files)
+	}
+	if requests[1].Branch != "deps/ios/Podfile" || requests[1].Title != "chore: update (ios/Podfile)" {
+		t.Errorf("PerGroup()[1] = %q %q", requests[1].Branch, requests[1].Title)
+	}
+}
+
+func TestGitHub_Publish(t *testing.T) {
+	var payload map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/repos/owner/repo/pulls" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url":"https://github.com/owner/repo/pull/7"}`))
+	}))
+	defer server.Close()
+
+	var commands []string
+	g := &GitHub{
+		client:  server.Client(),
+		baseURL: server.URL,
+		repo:    "owner/repo",
+		token:   "token",
+		git: func(ctx context.Context, args ...string) (string, error) {
+			commands = append(commands, strings.Join(args, " "))
+			if args[0] == "rev-parse" {
+				return "main", nil
+			}
+			return "", nil
+		},
+	}
+
+	req := Batch(testPlans(), DefaultBranch, DefaultTitle)
+	url, err := g.Publish(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if url != "https://github.com/owner/repo/pull/7" {
+		t.Errorf("Publish() url = %q", url)
+	}
+
+	wantCommands := []string{
+		"rev-parse --abbrev-ref HEAD",
+		"checkout -B uptool/dependency-updates",
+		"add -- ios/Podfile ios/Podfile.lock package.json",
+		"commit -m chore: update dependencies",
+		"push --force origin uptool/dependency-updates",
+		"checkout main",
+	}
+	if !reflect.DeepEqual(commands, wantCommands) {
+		t.Errorf("git commands = %q, want %q", commands, wantCommands)
+	}
+
+	if payload["head"] != DefaultBranch || payload["base"] != "main" || payload["body"] != req.Body {
+		t.Errorf("pull request payload = %v", payload)
+	}
+}
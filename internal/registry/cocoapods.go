@@ -0,0 +1,157 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5" // #nosec G501 - CocoaPods shards its CDN by MD5 of the pod name, not used for security
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const cocoaPodsCDNURL = "https://cdn.cocoapods.org"
+
+// CocoaPodsClient queries the CocoaPods trunk CDN.
+type CocoaPodsClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewCocoaPodsClient creates a new CocoaPods CDN client.
+func NewCocoaPodsClient() *CocoaPodsClient {
+	return &CocoaPodsClient{
+		client:  NewHTTPClient("cocoapods"),
+		baseURL: cocoaPodsCDNURL,
+	}
+}
+
+// podShard returns the CDN shard prefix for a pod: the first three hex
+// characters of the MD5 of its name (e.g., "Alamofire" -> ["d", "a", "2"]).
+func podShard(pod string) []string {
+	sum := md5.Sum([]byte(pod)) // #nosec G401 - CDN sharding scheme, not used for security
+	hexSum := hex.EncodeToString(sum[:])
+	return []string{hexSum[0:1], hexSum[1:2], hexSum[2:3]}
+}
+
+// rootPodName strips a subspec from a pod name ("Firebase/Analytics" -> "Firebase").
+func rootPodName(pod string) string {
+	if idx := strings.Index(pod, "/"); idx > 0 {
+		return pod[:idx]
+	}
+	return pod
+}
+
+// GetVersions fetches all published versions of a pod.
+// Subspecs resolve to the versions of their root pod.
+func (c *CocoaPodsClient) GetVersions(ctx context.Context, pod string) ([]string, error) {
+	name := rootPodName(pod)
+	shard := podShard(name)
+
+	url := fmt.Sprintf("%s/all_pods_versions_%s.txt", c.baseURL, strings.Join(shard, "_"))
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch pod versions: %w", err)
+	}
+
+	// Each line is "PodName/1.0.0/1.1.0/..."
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "/")
+		if fields[0] == name {
+			return fields[1:], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read pod versions: %w", err)
+	}
+
+	return nil, fmt.Errorf("pod not found: %s", name)
+}
+
+// GetLatestVersion returns the highest stable version of a pod.
+func (c *CocoaPodsClient) GetLatestVersion(ctx context.Context, pod string) (string, error) {
+	versions, err := c.GetVersions(ctx, pod)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *semver.Version
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found for pod: %s", pod)
+	}
+
+	return latest.Original(), nil
+}
+
+// GetPodspec fetches the published podspec JSON for a pod version.
+func (c *CocoaPodsClient) GetPodspec(ctx context.Context, pod, version string) ([]byte, error) {
+	name := rootPodName(pod)
+	shard := podShard(name)
+
+	url := fmt.Sprintf("%s/Specs/%s/%s/%s/%s.podspec.json",
+		c.baseURL, strings.Join(shard, "/"), name, version, name)
+	body, err := c.get(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch podspec: %w", err)
+	}
+	return body, nil
+}
+
+// get performs a GET request and returns the body of a 200 response.
+func (c *CocoaPodsClient) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return body, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	cratesIOURL = "https://crates.io/api/v1"
+
+	// cratesUserAgent identifies uptool, as the crates.io crawler policy requires.
+	cratesUserAgent = "uptool (https://github.com/santosr2/uptool)"
+)
+
+// CratesClient queries the crates.io API for Rust crate information.
+type CratesClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewCratesClient creates a new crates.io client.
+func NewCratesClient() *CratesClient {
+	return &CratesClient{
+		client:  NewHTTPClient("crates"),
+		baseURL: cratesIOURL,
+	}
+}
+
+// CrateInfo is the crates.io response for a single crate.
+type CrateInfo struct {
+	Crate    CrateSummary   `json:"crate"`
+	Versions []CrateVersion `json:"versions"`
+}
+
+// CrateSummary holds the crate-level fields of a crates.io response.
+type CrateSummary struct {
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Homepage         string `json:"homepage"`
+	Repository       string `json:"repository"`
+	MaxStableVersion string `json:"max_stable_version"`
+	MaxVersion       string `json:"max_version"`
+}
+
+// CrateVersion is one published version of a crate.
+type CrateVersion struct {
+	Num       string `json:"num"`
+	CreatedAt string `json:"created_at"`
+	Yanked    bool   `json:"yanked"`
+}
+
+// GetCrateInfo fetches metadata and published versions for a crate.
+func (c *CratesClient) GetCrateInfo(ctx context.Context, name string) (*CrateInfo, error) {
+	reqURL := fmt.Sprintf("%s/crates/%s", c.baseURL, url.PathEscape(name))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", cratesUserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch crate info: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("crate not found: %s", name)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var info CrateInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("parse crate info: %w", err)
+	}
+
+	return &info, nil
+}
+
+// GetVersions returns every version of a crate that has not been yanked.
+func (c *CratesClient) GetVersions(ctx context.Context, name string) ([]string, error) {
+	info, err := c.GetCrateInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(info.Versions))
+	for _, v := range info.Versions {
+		if !v.Yanked {
+			versions = append(versions, v.Num)
+		}
+	}
+
+	return versions, nil
+}
+
+// GetLatestVersion returns the highest stable, non-yanked version of a crate.
+func (c *CratesClient) GetLatestVersion(ctx context.Context, name string) (string, error) {
+	info, err := c.GetCrateInfo(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Crate.MaxStableVersion != "" {
+		return info.Crate.MaxStableVersion, nil
+	}
+
+	var latest *semver.Version
+	for _, v := range info.Versions {
+		parsed, err := semver.NewVersion(v.Num)
+		if err != nil || v.Yanked || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found for crate: %s", name)
+	}
+
+	return latest.Original(), nil
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	galaxyURL = "https://galaxy.ansible.com"
+
+	// galaxyMaxPages bounds pagination so a misbehaving server cannot loop forever.
+	galaxyMaxPages = 20
+)
+
+// GalaxyClient queries the Ansible Galaxy API.
+type GalaxyClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewGalaxyClient creates a new Ansible Galaxy client.
+func NewGalaxyClient() *GalaxyClient {
+	return &GalaxyClient{
+		client:  NewHTTPClient("ansible-galaxy"),
+		baseURL: galaxyURL,
+	}
+}
+
+// GalaxyCollectionVersions represents a page of the v3 collection versions endpoint.
+type GalaxyCollectionVersions struct {
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+	Data []GalaxyCollectionVersion `json:"data"`
+}
+
+// GalaxyCollectionVersion represents a single published collection version.
+type GalaxyCollectionVersion struct {
+	Version   string `json:"version"`
+	CreatedAt string `json:"created_at"`
+}
+
+// GalaxyRoleSearch represents the response from /api/v1/roles/.
+type GalaxyRoleSearch struct {
+	Results []struct {
+		ID int `json:"id"`
+	} `json:"results"`
+}
+
+// GalaxyRoleVersions represents a page of /api/v1/roles/{id}/versions/.
+type GalaxyRoleVersions struct {
+	Next    string              `json:"next"`
+	Results []GalaxyRoleVersion `json:"results"`
+}
+
+// GalaxyRoleVersion represents a single role version (a git tag imported by Galaxy).
+type GalaxyRoleVersion struct {
+	Name    string `json:"name"`
+	Created string `json:"created"`
+}
+
+// GetCollectionVersions returns all published versions of a collection.
+// name format: "namespace.collection" (e.g., "community.general")
+func (c *GalaxyClient) GetCollectionVersions(ctx context.Context, name string) ([]GalaxyCollectionVersion, error) {
+	namespace, collection, err := splitGalaxyName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	next := fmt.Sprintf("%s/api/v3/plugin/ansible/content/published/collections/index/%s/%s/versions/?limit=100",
+		c.baseURL, url.PathEscape(namespace), url.PathEscape(collection))
+
+	var versions []GalaxyCollectionVersion
+	for page := 0; next != "" && page < galaxyMaxPages; page++ {
+		var resp GalaxyCollectionVersions
+		if err := c.getJSON(ctx, next, &resp); err != nil {
+			return nil, fmt.Errorf("fetch collection versions for %s: %w", name, err)
+		}
+		versions = append(versions, resp.Data...)
+		next = c.resolveNext(resp.Links.Next)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions found for collection: %s", name)
+	}
+
+	return versions, nil
+}
+
+// GetRoleVersions returns all imported versions of a standalone role.
+// Roles are only served by the v1 API. name format: "namespace.role" (e.g., "geerlingguy.docker")
+func (c *GalaxyClient) GetRoleVersions(ctx context.Context, name string) ([]GalaxyRoleVersion, error) {
+	namespace, role, err := splitGalaxyName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	searchURL := fmt.Sprintf("%s/api/v1/roles/?github_user=%s&name=%s",
+		c.baseURL, url.QueryEscape(namespace), url.QueryEscape(role))
+
+	var search GalaxyRoleSearch
+	if err := c.getJSON(ctx, searchURL, &search); err != nil {
+		return nil, fmt.Errorf("search role %s: %w", name, err)
+	}
+	if len(search.Results) == 0 {
+		return nil, fmt.Errorf("role not found: %s", name)
+	}
+
+	next := fmt.Sprintf("%s/api/v1/roles/%d/versions/?page_size=100", c.baseURL, search.Results[0].ID)
+
+	var versions []GalaxyRoleVersion
+	for page := 0; next != "" && page < galaxyMaxPages; page++ {
+		var resp GalaxyRoleVersions
+		if err := c.getJSON(ctx, next, &resp); err != nil {
+			return nil, fmt.Errorf("fetch role versions for %s: %w", name, err)
+		}
+		versions = append(versions, resp.Results...)
+		next = c.resolveNext(resp.Next)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no versions found for role: %s", name)
+	}
+
+	return versions, nil
+}
+
+// LatestStableVersion returns the highest non-prerelease semver version from versions.
+func LatestStableVersion(versions []string) (string, error) {
+	var latest *semver.Version
+	for _, raw := range versions {
+		v, err := semver.NewVersion(raw)
+		if err != nil {
+			continue
+		}
+
+		// Skip prereleases
+		if v.Prerelease() != "" {
+			continue
+		}
+
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found")
+	}
+
+	return latest.Original(), nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into v.
+func (c *GalaxyClient) getJSON(ctx context.Context, reqURL string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("not found")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+
+	return nil
+}
+
+// resolveNext turns a pagination link into an absolute URL.
+// Galaxy returns relative links for v3 and absolute links for v1.
+func (c *GalaxyClient) resolveNext(next string) string {
+	if next == "" || strings.HasPrefix(next, "http://") || strings.HasPrefix(next, "https://") {
+		return next
+	}
+	return c.baseURL + next
+}
+
+// splitGalaxyName splits "namespace.name" into its parts.
+func splitGalaxyName(name string) (namespace, rest string, err error) {
+	parts := strings.SplitN(name, ".", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid galaxy name format: %s", name)
+	}
+	return parts[0], parts[1], nil
+}
@@ -23,34 +23,63 @@ package registry
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+
+	"github.com/santosr2/uptool/internal/version"
 )
 
 const githubAPIURL = "https://api.github.com"
 
+// Default retry settings for GitHub API requests.
+const (
+	DefaultGitHubMaxRetries = 3
+	DefaultGitHubMaxWait    = time.Minute
+)
+
+// githubBackoffBase is the first delay used when retrying a 5xx response.
+// Later attempts double it.
+const githubBackoffBase = time.Second
+
+// ErrRateLimited is returned when GitHub keeps rejecting requests for rate
+// limiting after all retries, or when the reset lies beyond the allowed wait.
+var ErrRateLimited = errors.New("github rate limit exceeded")
+
+// commitSHAPattern matches a full 40-character commit SHA.
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
 // GitHubClient queries GitHub API for release information.
 type GitHubClient struct {
-	client  *http.Client
-	baseURL string
-	token   string
+	client     *http.Client
+	baseURL    string
+	token      string
+	maxRetries int
+	maxWait    time.Duration
+	backoff    time.Duration
 }
 
 // NewGitHubClient creates a new GitHub API client.
 // Token is optional but recommended to avoid rate limiting.
-func NewGitHubClient(token string) *GitHubClient {
+//
+// Rate-limited (403/429) and transient 5xx responses are retried up to
+// maxRetries times. A rate-limit wait longer than maxWait is not attempted
+// and fails with ErrRateLimited instead.
+func NewGitHubClient(token string, maxRetries int, maxWait time.Duration) *GitHubClient {
 	return &GitHubClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: githubAPIURL,
-		token:   token,
+		client:     NewHTTPClient("github"),
+		baseURL:    githubAPIURL,
+		token:      token,
+		maxRetries: maxRetries,
+		maxWait:    maxWait,
+		backoff:    githubBackoffBase,
 	}
 }
 
@@ -58,6 +87,8 @@ func NewGitHubClient(token string) *GitHubClient {
 type Release struct {
 	TagName     string `json:"tag_name"`
 	Name        string `json:"name"`
+	Body        string `json:"body"`
+	HTMLURL     string `json:"html_url"`
 	CreatedAt   string `json:"created_at"`
 	PublishedAt string `json:"published_at"`
 	Draft       bool   `json:"draft"`
@@ -78,7 +109,7 @@ func (c *GitHubClient) GetLatestRelease(ctx context.Context, owner, repo string)
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("fetch release: %w", err)
 	}
@@ -102,9 +133,7 @@ func (c *GitHubClient) GetLatestRelease(ctx context.Context, owner, repo string)
 		return "", fmt.Errorf("parse response: %w", err)
 	}
 
-	// Strip 'v' prefix if present
-	version := strings.TrimPrefix(release.TagName, "v")
-	return version, nil
+	return version.Bare(release.TagName), nil
 }
 
 // GetAllReleases fetches all releases for a repository.
@@ -121,7 +150,7 @@ func (c *GitHubClient) GetAllReleases(ctx context.Context, owner, repo string) (
 		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 
-	resp, err := c.client.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, fmt.Errorf("fetch releases: %w", err)
 	}
@@ -148,6 +177,190 @@ func (c *GitHubClient) GetAllReleases(ctx context.Context, owner, repo string) (
 	return releases, nil
 }
 
+// Tag represents a git tag of a GitHub repository.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// GetTags fetches the most recent tags of a repository, including tags
+// without a GitHub release.
+func (c *GitHubClient) GetTags(ctx context.Context, owner, repo string) ([]string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/tags?per_page=100", c.baseURL, owner, repo)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch tags: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("repository not found: %s/%s", owner, repo)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var tags []Tag
+	if err := json.Unmarshal(body, &tags); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names, nil
+}
+
+// GetCommitSHA resolves a ref (tag, branch, or commit) to its full commit SHA.
+// Annotated tags are peeled to the commit they point at.
+func (c *GitHubClient) GetCommitSHA(ctx context.Context, owner, repo, ref string) (string, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/commits/%s", c.baseURL, owner, repo, ref)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+
+	// The sha media type returns the commit SHA as plain text
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch commit: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusUnprocessableEntity {
+		return "", fmt.Errorf("ref not found: %s/%s@%s", owner, repo, ref)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("read response: %w", err)
+	}
+
+	sha := strings.TrimSpace(string(body))
+	if !commitSHAPattern.MatchString(sha) {
+		return "", fmt.Errorf("parse response: unexpected commit SHA %q", sha)
+	}
+
+	return sha, nil
+}
+
+// ErrNotFound is returned when a release or ref does not exist.
+var ErrNotFound = errors.New("not found")
+
+// GetReleaseByTag fetches the release published for tag.
+func (c *GitHubClient) GetReleaseByTag(ctx context.Context, owner, repo, tag string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/tags/%s", c.baseURL, owner, repo, tag)
+
+	body, err := c.getJSON(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch release %s/%s@%s: %w", owner, repo, tag, err)
+	}
+
+	var release Release
+	if err := json.Unmarshal(body, &release); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	return &release, nil
+}
+
+// Commit is a commit listed by the compare API.
+type Commit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// CompareCommits lists the commits reachable from head but not from base,
+// oldest first. At most limit commits are fetched.
+func (c *GitHubClient) CompareCommits(ctx context.Context, owner, repo, base, head string, limit int) ([]Commit, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s?per_page=%d", c.baseURL, owner, repo, base, head, limit)
+
+	body, err := c.getJSON(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("compare %s/%s %s...%s: %w", owner, repo, base, head, err)
+	}
+
+	var comparison struct {
+		Commits []Commit `json:"commits"`
+	}
+	if err := json.Unmarshal(body, &comparison); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if len(comparison.Commits) > limit {
+		comparison.Commits = comparison.Commits[:limit]
+	}
+	return comparison.Commits, nil
+}
+
+// getJSON sends an authenticated GET request for a JSON document and returns
+// its body. A 404 fails with ErrNotFound.
+func (c *GitHubClient) getJSON(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return body, nil
+}
+
 // FindBestRelease finds the best release matching a constraint.
 func (c *GitHubClient) FindBestRelease(ctx context.Context, owner, repo, constraint string, allowPrerelease bool) (string, error) {
 	releases, err := c.GetAllReleases(ctx, owner, repo)
@@ -173,8 +386,7 @@ func (c *GitHubClient) FindBestRelease(ctx context.Context, owner, repo, constra
 			continue
 		}
 
-		// Strip 'v' prefix
-		versionStr := strings.TrimPrefix(rel.TagName, "v")
+		versionStr := version.Bare(rel.TagName)
 
 		parsed, err := semver.NewVersion(versionStr)
 		if err != nil {
@@ -201,6 +413,89 @@ func (c *GitHubClient) FindBestRelease(ctx context.Context, owner, repo, constra
 	return best.Original(), nil
 }
 
+// do sends req, retrying rate-limited and transient server error responses.
+// Waits never extend past the request context deadline.
+func (c *GitHubClient) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 0; ; attempt++ {
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, rateLimited, retryable := c.retryDelay(resp, attempt)
+		if !retryable {
+			return resp, nil
+		}
+
+		giveUp := attempt >= c.maxRetries
+		if rateLimited && c.maxWait > 0 && wait > c.maxWait {
+			giveUp = true
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			giveUp = true
+		}
+
+		if giveUp {
+			if !rateLimited {
+				return resp, nil
+			}
+			_ = resp.Body.Close() //nolint:errcheck // HTTP cleanup best effort
+			return nil, fmt.Errorf("%w: retry after %s", ErrRateLimited, wait.Round(time.Second))
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body) //nolint:errcheck // drain for connection reuse
+		_ = resp.Body.Close()                 //nolint:errcheck // HTTP cleanup best effort
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		// Requests with a body, such as GraphQL queries, resend it
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// retryDelay reports how long to wait before retrying resp. rateLimited is
+// set for 403/429 responses carrying rate-limit headers, whose wait comes
+// from Retry-After or X-RateLimit-Reset; 5xx responses back off
+// exponentially from the client's base delay.
+func (c *GitHubClient) retryDelay(resp *http.Response, attempt int) (wait time.Duration, rateLimited, retryable bool) {
+	switch {
+	case resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		if secs, err := strconv.Atoi(strings.TrimSpace(resp.Header.Get("Retry-After"))); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				return max(time.Until(time.Unix(reset, 0)), 0), true, true
+			}
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return c.backoff << attempt, true, true
+		}
+		// A plain 403 is a permission problem, not a rate limit
+		return 0, false, false
+	case resp.StatusCode == http.StatusInternalServerError,
+		resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return c.backoff << attempt, false, true
+	default:
+		return 0, false, false
+	}
+}
+
 // ParseGitHubURL extracts owner and repo from a GitHub URL.
 // Supports:
 // - https://github.com/owner/repo
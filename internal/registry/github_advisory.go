@@ -0,0 +1,169 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrTokenRequired is returned by GitHub APIs that cannot be used anonymously.
+var ErrTokenRequired = errors.New("a GitHub token is required")
+
+// maxAdvisoryPages bounds how many pages of vulnerabilities are read for a
+// single package.
+const maxAdvisoryPages = 10
+
+// SecurityVulnerability is a version range of a package affected by a GitHub
+// security advisory.
+type SecurityVulnerability struct {
+	GHSAID                 string
+	Severity               string
+	Summary                string
+	VulnerableVersionRange string // e.g. ">= 1.0.0, < 1.0.5"
+	FirstPatchedVersion    string // empty when no fix is published
+}
+
+const securityVulnerabilitiesQuery = `query($ecosystem: SecurityAdvisoryEcosystem!, $package: String!, $after: String) {
+  securityVulnerabilities(first: 100, ecosystem: $ecosystem, package: $package, after: $after) {
+    nodes {
+      advisory { ghsaId severity summary withdrawnAt }
+      vulnerableVersionRange
+      firstPatchedVersion { identifier }
+    }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+
+type securityVulnerabilitiesResponse struct {
+	Data struct {
+		SecurityVulnerabilities struct {
+			Nodes []struct {
+				Advisory struct {
+					WithdrawnAt *string `json:"withdrawnAt"`
+					GHSAID      string  `json:"ghsaId"`
+					Severity    string  `json:"severity"`
+					Summary     string  `json:"summary"`
+				} `json:"advisory"`
+				FirstPatchedVersion *struct {
+					Identifier string `json:"identifier"`
+				} `json:"firstPatchedVersion"`
+				VulnerableVersionRange string `json:"vulnerableVersionRange"`
+			} `json:"nodes"`
+			PageInfo struct {
+				EndCursor   string `json:"endCursor"`
+				HasNextPage bool   `json:"hasNextPage"`
+			} `json:"pageInfo"`
+		} `json:"securityVulnerabilities"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GetSecurityVulnerabilities queries the GitHub Advisory Database for the
+// vulnerable version ranges of a package. ecosystem is a GraphQL
+// SecurityAdvisoryEcosystem value such as NPM, GO, or RUST. Withdrawn
+// advisories are left out. The GraphQL API needs a token.
+func (c *GitHubClient) GetSecurityVulnerabilities(ctx context.Context, ecosystem, pkg string) ([]SecurityVulnerability, error) {
+	if c.token == "" {
+		return nil, fmt.Errorf("query security advisories: %w", ErrTokenRequired)
+	}
+
+	var (
+		vulns []SecurityVulnerability
+		after *string
+	)
+	for page := 0; page < maxAdvisoryPages; page++ {
+		result, err := c.querySecurityVulnerabilities(ctx, map[string]any{
+			"ecosystem": ecosystem,
+			"package":   pkg,
+			"after":     after,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("query security advisories for %s: %w", pkg, err)
+		}
+
+		conn := result.Data.SecurityVulnerabilities
+		for _, node := range conn.Nodes {
+			if node.Advisory.WithdrawnAt != nil {
+				continue
+			}
+			v := SecurityVulnerability{
+				GHSAID:                 node.Advisory.GHSAID,
+				Severity:               node.Advisory.Severity,
+				Summary:                node.Advisory.Summary,
+				VulnerableVersionRange: node.VulnerableVersionRange,
+			}
+			if node.FirstPatchedVersion != nil {
+				v.FirstPatchedVersion = node.FirstPatchedVersion.Identifier
+			}
+			vulns = append(vulns, v)
+		}
+
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		cursor := conn.PageInfo.EndCursor
+		after = &cursor
+	}
+
+	return vulns, nil
+}
+
+// querySecurityVulnerabilities posts one page of the securityVulnerabilities
+// query to the GraphQL endpoint and decodes the response.
+func (c *GitHubClient) querySecurityVulnerabilities(ctx context.Context, variables map[string]any) (*securityVulnerabilitiesResponse, error) {
+	payload, err := json.Marshal(map[string]any{"query": securityVulnerabilitiesQuery, "variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("encode query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/graphql", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	var result securityVulnerabilitiesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("graphql: %s", result.Errors[0].Message)
+	}
+	return &result, nil
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+const gitlabAPIURL = "https://gitlab.com/api/v4"
+
+// GitLabClient queries the GitLab API for repository refs.
+type GitLabClient struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// NewGitLabClient creates a GitLab API client for host, or gitlab.com when
+// host is empty. Token is optional and only needed for private projects.
+func NewGitLabClient(host, token string) *GitLabClient {
+	baseURL := gitlabAPIURL
+	if host != "" && host != "gitlab.com" {
+		baseURL = "https://" + host + "/api/v4"
+	}
+	return &GitLabClient{
+		client:  NewHTTPClient("gitlab"),
+		baseURL: baseURL,
+		token:   token,
+	}
+}
+
+// GetCommitSHA resolves ref (a branch, tag or commit) of project
+// ("group/name") to a full commit SHA. An empty ref or "HEAD" resolves the
+// project's default branch.
+func (c *GitLabClient) GetCommitSHA(ctx context.Context, project, ref string) (string, error) {
+	if ref == "" || ref == "HEAD" {
+		branch, err := c.defaultBranch(ctx, project)
+		if err != nil {
+			return "", err
+		}
+		ref = branch
+	}
+
+	var commit struct {
+		ID string `json:"id"`
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/repository/commits/%s", c.baseURL, url.PathEscape(project), url.PathEscape(ref))
+	if err := c.getJSON(ctx, endpoint, &commit); err != nil {
+		return "", fmt.Errorf("fetch commit: %w", err)
+	}
+
+	if !commitSHAPattern.MatchString(commit.ID) {
+		return "", fmt.Errorf("parse response: unexpected commit SHA %q", commit.ID)
+	}
+
+	return commit.ID, nil
+}
+
+// GetTags returns the tag names of project ("group/name"), newest first as
+// ordered by the API. Only the first page of 100 tags is read.
+func (c *GitLabClient) GetTags(ctx context.Context, project string) ([]string, error) {
+	var tags []struct {
+		Name string `json:"name"`
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/repository/tags?per_page=100", c.baseURL, url.PathEscape(project))
+	if err := c.getJSON(ctx, endpoint, &tags); err != nil {
+		return nil, fmt.Errorf("fetch tags: %w", err)
+	}
+
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names, nil
+}
+
+// defaultBranch returns the default branch of project.
+func (c *GitLabClient) defaultBranch(ctx context.Context, project string) (string, error) {
+	var info struct {
+		DefaultBranch string `json:"default_branch"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/projects/%s", c.baseURL, url.PathEscape(project)), &info); err != nil {
+		return "", fmt.Errorf("fetch project: %w", err)
+	}
+	if info.DefaultBranch == "" {
+		return "", fmt.Errorf("project %s has no default branch", project)
+	}
+	return info.DefaultBranch, nil
+}
+
+// getJSON performs a GET request and decodes the JSON response into v.
+func (c *GitLabClient) getJSON(ctx context.Context, endpoint string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("not found: %s", endpoint)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+
+	return nil
+}
@@ -21,24 +21,49 @@
 package registry
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"golang.org/x/mod/modfile"
+	gosemver "golang.org/x/mod/semver"
+	"golang.org/x/mod/sumdb/dirhash"
 )
 
 const goProxyURL = "https://proxy.golang.org"
 
+// errGoProxyNotFound reports a module or version every proxy answered with
+// 404 Not Found or 410 Gone.
+var errGoProxyNotFound = errors.New("not found")
+
 // GoClient queries the Go module proxy for version information.
+// Like the go command, it honors GOPROXY: a list of proxy URLs tried in
+// order, where a proxy followed by "," is skipped only when it does not have
+// the module (404 or 410) and one followed by "|" is skipped on any error.
+// "off" disallows lookups; "direct" (fetching from version control) is not
+// supported, so it ends the list.
 type GoClient struct {
 	client  *http.Client
 	baseURL string
+	// proxies overrides baseURL with the GOPROXY list when set.
+	proxies []goProxy
+}
+
+// goProxy is one GOPROXY entry.
+type goProxy struct {
+	url string
+	// fallbackOnError is set when the entry is followed by "|".
+	fallbackOnError bool
 }
 
 // GoModuleInfo represents the JSON response from the Go module proxy @latest endpoint.
@@ -47,45 +72,128 @@ type GoModuleInfo struct {
 	Version string    `json:"Version"`
 }
 
-// NewGoClient creates a new Go module proxy client.
+// GoRetraction is a version interval a module author retracted with a
+// "retract" directive in go.mod. Low equals High for a single version.
+type GoRetraction struct {
+	Low       string
+	High      string
+	Rationale string
+}
+
+// NewGoClient creates a new Go module proxy client that uses the proxies
+// listed in GOPROXY, or proxy.golang.org when it is unset.
 func NewGoClient() *GoClient {
 	return &GoClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  NewHTTPClient("go-proxy"),
 		baseURL: goProxyURL,
+		proxies: parseGoProxy(os.Getenv("GOPROXY")),
 	}
 }
 
-// GetLatestVersion fetches the latest version for a Go module.
-// It queries the @latest endpoint which returns the highest semver version.
-func (c *GoClient) GetLatestVersion(ctx context.Context, modulePath string) (string, error) {
-	// URL encode the module path (required for modules with slashes)
-	encodedPath := escapeModulePath(modulePath)
-	reqURL := fmt.Sprintf("%s/%s/@latest", c.baseURL, encodedPath)
+// parseGoProxy splits a GOPROXY value into its entries. An empty value
+// selects the go command's default, "https://proxy.golang.org,direct".
+func parseGoProxy(value string) []goProxy {
+	if strings.TrimSpace(value) == "" {
+		value = goProxyURL + ",direct"
+	}
+
+	var proxies []goProxy
+	for value != "" {
+		end := strings.IndexAny(value, ",|")
+		entry, fallbackOnError := value, false
+		if end >= 0 {
+			entry, fallbackOnError = value[:end], value[end] == '|'
+			value = value[end+1:]
+		} else {
+			value = ""
+		}
+		if entry = strings.TrimSpace(entry); entry != "" {
+			proxies = append(proxies, goProxy{url: strings.TrimSuffix(entry, "/"), fallbackOnError: fallbackOnError})
+		}
+	}
+	return proxies
+}
+
+// fetch downloads path (e.g. "@v/list") of a module from the first proxy
+// that has it. It returns an error wrapping errGoProxyNotFound when no proxy
+// has the module.
+func (c *GoClient) fetch(ctx context.Context, modulePath, path string) ([]byte, error) {
+	proxies := c.proxies
+	if len(proxies) == 0 {
+		proxies = []goProxy{{url: c.baseURL}}
+	}
+
+	notFound := fmt.Errorf("%s: %w", modulePath, errGoProxyNotFound)
+	for j, proxy := range proxies {
+		switch proxy.url {
+		case "off":
+			return nil, fmt.Errorf("%s: module lookup disabled by GOPROXY=off", modulePath)
+		case "direct":
+			// Only report the missing VCS support when no proxy was asked
+			if j == 0 {
+				return nil, fmt.Errorf("%s: GOPROXY=direct is not supported; set GOPROXY to a module proxy", modulePath)
+			}
+			return nil, notFound
+		}
+
+		body, err := c.fetchFrom(ctx, proxy.url, modulePath, path)
+		switch {
+		case err == nil:
+			return body, nil
+		case errors.Is(err, errGoProxyNotFound):
+			notFound = err
+		case proxy.fallbackOnError:
+			continue
+		default:
+			return nil, err
+		}
+	}
+	return nil, notFound
+}
+
+// fetchFrom downloads path of a module from one proxy.
+func (c *GoClient) fetchFrom(ctx context.Context, proxyURL, modulePath, path string) ([]byte, error) {
+	reqURL := fmt.Sprintf("%s/%s/%s", proxyURL, escapeModulePath(modulePath), path)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, http.NoBody)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("fetch module info: %w", err)
+		return nil, fmt.Errorf("fetch %s: %w", path, err)
 	}
 	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
 
 	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
-		return "", fmt.Errorf("module not found: %s", modulePath)
+		return nil, fmt.Errorf("%s/%s: %w", modulePath, path, errGoProxyNotFound)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxModuleZipSize+1))
 	if err != nil {
-		return "", fmt.Errorf("read response: %w", err)
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if len(body) > maxModuleZipSize {
+		return nil, fmt.Errorf("%s/%s exceeds %d bytes", modulePath, path, maxModuleZipSize)
+	}
+
+	return body, nil
+}
+
+// GetLatestVersion fetches the latest version for a Go module.
+// It queries the @latest endpoint which returns the highest semver version.
+func (c *GoClient) GetLatestVersion(ctx context.Context, modulePath string) (string, error) {
+	body, err := c.fetch(ctx, modulePath, "@latest")
+	if errors.Is(err, errGoProxyNotFound) {
+		return "", fmt.Errorf("module not found: %s", modulePath)
+	}
+	if err != nil {
+		return "", err
 	}
 
 	var info GoModuleInfo
@@ -99,81 +207,157 @@ func (c *GoClient) GetLatestVersion(ctx context.Context, modulePath string) (str
 // GetVersions returns all available versions for a Go module.
 // It queries the @v/list endpoint which returns newline-separated versions.
 func (c *GoClient) GetVersions(ctx context.Context, modulePath string) ([]string, error) {
-	encodedPath := escapeModulePath(modulePath)
-	reqURL := fmt.Sprintf("%s/%s/@v/list", c.baseURL, encodedPath)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, http.NoBody)
+	body, err := c.fetch(ctx, modulePath, "@v/list")
+	if errors.Is(err, errGoProxyNotFound) {
+		return nil, fmt.Errorf("module not found: %s", modulePath)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
 	}
 
-	resp, err := c.client.Do(req)
+	return parseVersionList(body), nil
+}
+
+// parseVersionList parses an @v/list response: one version per line, in no
+// particular order, with blank lines and anything after the version ignored.
+func parseVersionList(body []byte) []string {
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+
+	versions := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			versions = append(versions, fields[0])
+		}
+	}
+	return versions
+}
+
+// GetModuleInfo fetches detailed information about a specific version of a module.
+func (c *GoClient) GetModuleInfo(ctx context.Context, modulePath, version string) (*GoModuleInfo, error) {
+	body, err := c.fetch(ctx, modulePath, "@v/"+escapeModulePath(version)+".info")
+	if errors.Is(err, errGoProxyNotFound) {
+		return nil, fmt.Errorf("version not found: %s@%s", modulePath, version)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("fetch version list: %w", err)
+		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
 
-	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
-		return nil, fmt.Errorf("module not found: %s", modulePath)
+	var info GoModuleInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	return &info, nil
+}
+
+// GetRetractions returns the versions the module's author retracted. The go
+// command reads them from the retract directives in the go.mod of the
+// module's latest version, so that is where they are looked up.
+func (c *GoClient) GetRetractions(ctx context.Context, modulePath string) ([]GoRetraction, error) {
+	latest, err := c.GetLatestVersion(ctx, modulePath)
+	if err != nil {
+		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	mod, err := c.getProxyFile(ctx, modulePath, latest, "mod")
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return nil, err
+	}
+	file, err := modfile.ParseLax("go.mod", mod, nil)
+	if err != nil {
+		return nil, fmt.Errorf("parse go.mod of %s@%s: %w", modulePath, latest, err)
 	}
 
-	// Response is newline-separated versions
-	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	retractions := make([]GoRetraction, 0, len(file.Retract))
+	for _, r := range file.Retract {
+		retractions = append(retractions, GoRetraction{Low: r.Low, High: r.High, Rationale: r.Rationale})
+	}
+	return retractions, nil
+}
 
-	versions := make([]string, 0, len(lines))
-	for _, line := range lines {
-		if v := strings.TrimSpace(line); v != "" {
-			versions = append(versions, v)
+// Retracted reports whether a version falls in one of the retracted intervals.
+func Retracted(version string, retractions []GoRetraction) bool {
+	for _, r := range retractions {
+		if gosemver.Compare(r.Low, version) <= 0 && gosemver.Compare(version, r.High) <= 0 {
+			return true
 		}
 	}
-
-	return versions, nil
+	return false
 }
 
-// GetModuleInfo fetches detailed information about a specific version of a module.
-func (c *GoClient) GetModuleInfo(ctx context.Context, modulePath, version string) (*GoModuleInfo, error) {
-	encodedPath := escapeModulePath(modulePath)
-	reqURL := fmt.Sprintf("%s/%s/@v/%s.info", c.baseURL, encodedPath, version)
+// maxModuleZipSize is the largest module zip the go command accepts.
+const maxModuleZipSize = 500 << 20
 
-	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, http.NoBody)
+// GoModuleSums holds the go.sum hashes of a module version: the "h1:" hash
+// of its zip file and of its go.mod file.
+type GoModuleSums struct {
+	Version string
+	Zip     string
+	GoMod   string
+}
+
+// GetModuleSums downloads a module version's go.mod and zip from the proxy
+// and computes the hashes go.sum records for them. The version is resolved
+// through the .info endpoint first so the hashes are recorded under its
+// canonical form.
+func (c *GoClient) GetModuleSums(ctx context.Context, modulePath, version string) (*GoModuleSums, error) {
+	info, err := c.GetModuleInfo(ctx, modulePath, version)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return nil, err
+	}
+	if info.Version != "" {
+		version = info.Version
 	}
 
-	resp, err := c.client.Do(req)
+	mod, err := c.getProxyFile(ctx, modulePath, version, "mod")
 	if err != nil {
-		return nil, fmt.Errorf("fetch module info: %w", err)
+		return nil, err
 	}
-	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
-
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("version not found: %s@%s", modulePath, version)
+	modHash, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(mod)), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hash go.mod: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	zipData, err := c.getProxyFile(ctx, modulePath, version, "zip")
+	if err != nil {
+		return nil, err
 	}
+	zipHash, err := hashModuleZip(zipData)
+	if err != nil {
+		return nil, fmt.Errorf("hash module zip: %w", err)
+	}
+
+	return &GoModuleSums{Version: version, Zip: zipHash, GoMod: modHash}, nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// hashModuleZip computes the "h1:" hash of a module zip's file contents, as
+// dirhash.HashZip does for a zip on disk.
+func hashModuleZip(data []byte) (string, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
+		return "", err
 	}
 
-	var info GoModuleInfo
-	if err := json.Unmarshal(body, &info); err != nil {
-		return nil, fmt.Errorf("parse response: %w", err)
+	files := make([]string, 0, len(z.File))
+	zfiles := make(map[string]*zip.File, len(z.File))
+	for _, file := range z.File {
+		files = append(files, file.Name)
+		zfiles[file.Name] = file
 	}
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return zfiles[name].Open()
+	})
+}
 
-	return &info, nil
+// getProxyFile downloads the .mod or .zip file of a module version.
+func (c *GoClient) getProxyFile(ctx context.Context, modulePath, version, ext string) ([]byte, error) {
+	body, err := c.fetch(ctx, modulePath, "@v/"+escapeModulePath(version)+"."+ext)
+	if errors.Is(err, errGoProxyNotFound) {
+		return nil, fmt.Errorf("version not found: %s@%s", modulePath, version)
+	}
+	return body, err
 }
 
 // FindBestVersion finds the best version matching criteria.
@@ -21,10 +21,14 @@
 package registry
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -264,6 +268,62 @@ func TestGoClient_GetModuleInfo(t *testing.T) {
 	})
 }
 
+func TestGoClient_GetModuleSums(t *testing.T) {
+	const modFile = "module example.com/mod\n\ngo 1.21\n"
+
+	var zipData bytes.Buffer
+	zw := zip.NewWriter(&zipData)
+	for name, content := range map[string]string{
+		"example.com/mod@v1.1.0/go.mod": modFile,
+		"example.com/mod@v1.1.0/mod.go": "package mod\n",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mod/@v/v1.1.0.info":
+			_ = json.NewEncoder(w).Encode(GoModuleInfo{Version: "v1.1.0"})
+		case "/example.com/mod/@v/v1.1.0.mod":
+			_, _ = w.Write([]byte(modFile))
+		case "/example.com/mod/@v/v1.1.0.zip":
+			_, _ = w.Write(zipData.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &GoClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	sums, err := client.GetModuleSums(context.Background(), "example.com/mod", "v1.1.0")
+	if err != nil {
+		t.Fatalf("GetModuleSums() error = %v", err)
+	}
+	want := GoModuleSums{
+		Version: "v1.1.0",
+		Zip:     "h1:VWxuYaKd+zuP9waRTkjOJQb25eACkSmy01fNbjMNukM=",
+		GoMod:   "h1:6Olo59fmoKf9DlNu5tKoc/bk5pj1sXqF+kzl7w/es4E=",
+	}
+	if *sums != want {
+		t.Errorf("GetModuleSums() = %+v, want %+v", *sums, want)
+	}
+
+	if _, err := client.GetModuleSums(context.Background(), "example.com/mod", "v9.9.9"); err == nil {
+		t.Error("GetModuleSums() expected error for unknown version")
+	}
+}
+
 func TestGoClient_FindBestVersion(t *testing.T) {
 	// Helper to test FindBestVersion with different version lists
 	testFindBest := func(t *testing.T, versionList string, allowPrerelease bool, wantVersion, errMsg string) {
@@ -354,3 +414,128 @@ func TestEscapeModulePath(t *testing.T) {
 		})
 	}
 }
+
+func TestParseGoProxy(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []goProxy
+	}{
+		{"", []goProxy{{url: goProxyURL}, {url: "direct"}}},
+		{"https://goproxy.io/", []goProxy{{url: "https://goproxy.io"}}},
+		{"https://a.example,https://b.example|direct", []goProxy{
+			{url: "https://a.example"},
+			{url: "https://b.example", fallbackOnError: true},
+			{url: "direct"},
+		}},
+		{"off", []goProxy{{url: "off"}}},
+	}
+
+	for _, tt := range tests {
+		if got := parseGoProxy(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGoProxy(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersionList(t *testing.T) {
+	body := []byte("v1.0.0\nv1.1.0\n\nv2.0.0+incompatible\nv1.2.0 2023-01-01T00:00:00Z\n")
+	want := []string{"v1.0.0", "v1.1.0", "v2.0.0+incompatible", "v1.2.0"}
+	if got := parseVersionList(body); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseVersionList() = %v, want %v", got, want)
+	}
+}
+
+func TestGoClient_GOPROXY(t *testing.T) {
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer missing.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer broken.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gopkg.in/yaml.v3/@v/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("v3.0.0\nv3.0.1\n"))
+	}))
+	defer mirror.Close()
+
+	tests := []struct {
+		name    string
+		goproxy string
+		wantErr string
+	}{
+		{name: "falls back when the module is missing", goproxy: missing.URL + "," + mirror.URL},
+		{name: "falls back on any error after a pipe", goproxy: broken.URL + "|" + mirror.URL},
+		{name: "stops on other errors after a comma", goproxy: broken.URL + "," + mirror.URL, wantErr: "unexpected status: 502"},
+		{name: "not found anywhere", goproxy: missing.URL + ",direct", wantErr: "module not found"},
+		{name: "off", goproxy: "off", wantErr: "GOPROXY=off"},
+		{name: "direct only", goproxy: "direct", wantErr: "GOPROXY=direct is not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &GoClient{client: &http.Client{Timeout: 5 * time.Second}, proxies: parseGoProxy(tt.goproxy)}
+			versions, err := client.GetVersions(context.Background(), "gopkg.in/yaml.v3")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("GetVersions() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetVersions() error = %v", err)
+			}
+			if want := []string{"v3.0.0", "v3.0.1"}; !reflect.DeepEqual(versions, want) {
+				t.Errorf("GetVersions() = %v, want %v", versions, want)
+			}
+		})
+	}
+}
+
+func TestGoClient_GetRetractions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/example.com/mod/@latest":
+			_ = json.NewEncoder(w).Encode(GoModuleInfo{Version: "v1.3.0"})
+		case "/example.com/mod/@v/v1.3.0.mod":
+			_, _ = w.Write([]byte(`module example.com/mod
+
+go 1.21
+
+retract (
+	v1.2.1 // Published with a data race.
+	[v1.0.0, v1.0.5]
+)
+`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &GoClient{client: server.Client(), baseURL: server.URL}
+	retractions, err := client.GetRetractions(context.Background(), "example.com/mod")
+	if err != nil {
+		t.Fatalf("GetRetractions() error = %v", err)
+	}
+	want := []GoRetraction{
+		{Low: "v1.2.1", High: "v1.2.1", Rationale: "Published with a data race."},
+		{Low: "v1.0.0", High: "v1.0.5"},
+	}
+	if !reflect.DeepEqual(retractions, want) {
+		t.Errorf("GetRetractions() = %+v, want %+v", retractions, want)
+	}
+
+	for version, retracted := range map[string]bool{
+		"v1.0.0": true, "v1.0.3": true, "v1.0.5": true, "v1.0.6": false,
+		"v1.2.0": false, "v1.2.1": true, "v1.3.0": false,
+	} {
+		if got := Retracted(version, retractions); got != retracted {
+			t.Errorf("Retracted(%s) = %v, want %v", version, got, retracted)
+		}
+	}
+}
@@ -0,0 +1,305 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	hexAPIURL  = "https://hex.pm/api"
+	hexRepoURL = "https://repo.hex.pm"
+)
+
+// HexClient queries the hex.pm API and the hex.pm package repository.
+type HexClient struct {
+	client  *http.Client
+	baseURL string
+	repoURL string
+}
+
+// NewHexClient creates a new hex.pm client.
+func NewHexClient() *HexClient {
+	return &HexClient{
+		client:  NewHTTPClient("hex"),
+		baseURL: hexAPIURL,
+		repoURL: hexRepoURL,
+	}
+}
+
+// HexRelease is a published release of a Hex package.
+type HexRelease struct {
+	Version    string `json:"version"`
+	InsertedAt string `json:"inserted_at"`
+}
+
+// hexPackage is the response of /api/packages/{name}.
+type hexPackage struct {
+	Name     string       `json:"name"`
+	Releases []HexRelease `json:"releases"`
+}
+
+// HexRegistryRelease is a release as recorded in the package repository,
+// with the checksums and requirements mix.lock stores for it.
+type HexRegistryRelease struct {
+	Version string
+	// InnerChecksum and OuterChecksum are lowercase hex SHA-256 digests of
+	// the tarball contents and of the tarball itself.
+	InnerChecksum string
+	OuterChecksum string
+	Dependencies  []HexDependency
+}
+
+// HexDependency is a requirement of a Hex release.
+type HexDependency struct {
+	Package     string
+	Requirement string
+	App         string // defaults to Package when empty
+	Repository  string // defaults to "hexpm" when empty
+	Optional    bool
+}
+
+// GetReleases fetches the releases of a package, newest first.
+func (c *HexClient) GetReleases(ctx context.Context, name string) ([]HexRelease, error) {
+	body, err := c.get(ctx, fmt.Sprintf("%s/packages/%s", c.baseURL, url.PathEscape(name)), "application/json")
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg hexPackage
+	if err := json.Unmarshal(body, &pkg); err != nil {
+		return nil, fmt.Errorf("parse package: %w", err)
+	}
+	return pkg.Releases, nil
+}
+
+// GetVersions fetches all published versions of a package.
+func (c *HexClient) GetVersions(ctx context.Context, name string) ([]string, error) {
+	releases, err := c.GetReleases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(releases))
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	return versions, nil
+}
+
+// GetLatestVersion returns the highest stable version of a package.
+func (c *HexClient) GetLatestVersion(ctx context.Context, name string) (string, error) {
+	versions, err := c.GetVersions(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *semver.Version
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found for package: %s", name)
+	}
+
+	return latest.Original(), nil
+}
+
+// GetRegistryRelease fetches a release from the package repository
+// (repo.hex.pm/packages/{name}), which unlike the API records the inner
+// checksum. The registry signature is not verified.
+func (c *HexClient) GetRegistryRelease(ctx context.Context, name, version string) (*HexRegistryRelease, error) {
+	body, err := c.get(ctx, fmt.Sprintf("%s/packages/%s", c.repoURL, url.PathEscape(name)), "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("decompress registry: %w", err)
+	}
+	signed, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("decompress registry: %w", err)
+	}
+
+	releases, err := decodeHexRegistry(signed)
+	if err != nil {
+		return nil, fmt.Errorf("parse registry: %w", err)
+	}
+	for i := range releases {
+		if releases[i].Version == version {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release not found: %s %s", name, version)
+}
+
+// get performs a GET request and returns the body of a 200 response.
+func (c *HexClient) get(ctx context.Context, endpoint, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("package not found: %s", endpoint)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return body, nil
+}
+
+// decodeHexRegistry decodes the releases of a package from a signed registry
+// resource. The resource is a protobuf Signed message whose payload (field 1)
+// is a Package, whose releases (field 1) are Release messages:
+//
+//	Release    { 1: version, 2: inner_checksum, 3: dependencies, 5: outer_checksum }
+//	Dependency { 1: package, 2: requirement, 3: optional, 4: app, 5: repository }
+func decodeHexRegistry(signed []byte) ([]HexRegistryRelease, error) {
+	var payload []byte
+	err := walkProtobuf(signed, func(field int, value []byte, _ uint64) {
+		if field == 1 {
+			payload = value
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []HexRegistryRelease
+	var relErr error
+	err = walkProtobuf(payload, func(field int, value []byte, _ uint64) {
+		if field != 1 || relErr != nil {
+			return
+		}
+		var rel HexRegistryRelease
+		relErr = walkProtobuf(value, func(field int, value []byte, _ uint64) {
+			switch field {
+			case 1:
+				rel.Version = string(value)
+			case 2:
+				rel.InnerChecksum = hex.EncodeToString(value)
+			case 3:
+				var dep HexDependency
+				relErr = errors.Join(relErr, walkProtobuf(value, func(field int, value []byte, n uint64) {
+					switch field {
+					case 1:
+						dep.Package = string(value)
+					case 2:
+						dep.Requirement = string(value)
+					case 3:
+						dep.Optional = n != 0
+					case 4:
+						dep.App = string(value)
+					case 5:
+						dep.Repository = string(value)
+					}
+				}))
+				rel.Dependencies = append(rel.Dependencies, dep)
+			case 5:
+				rel.OuterChecksum = hex.EncodeToString(value)
+			}
+		})
+		releases = append(releases, rel)
+	})
+	if err == nil {
+		err = relErr
+	}
+	return releases, err
+}
+
+// walkProtobuf calls fn for each field of a protobuf message, with the bytes
+// of length-delimited fields or the value of varint fields. Fixed-width
+// fields are skipped.
+func walkProtobuf(msg []byte, fn func(field int, value []byte, n uint64)) error {
+	for len(msg) > 0 {
+		key, size := binary.Uvarint(msg)
+		if size <= 0 {
+			return errors.New("malformed protobuf key")
+		}
+		msg = msg[size:]
+		field := int(key >> 3) // #nosec G115 - protobuf field numbers fit in an int
+
+		switch key & 7 {
+		case 0: // varint
+			n, size := binary.Uvarint(msg)
+			if size <= 0 {
+				return errors.New("malformed protobuf varint")
+			}
+			msg = msg[size:]
+			fn(field, nil, n)
+		case 1: // 64-bit
+			if len(msg) < 8 {
+				return errors.New("truncated protobuf field")
+			}
+			msg = msg[8:]
+		case 2: // length-delimited
+			length, size := binary.Uvarint(msg)
+			if size <= 0 || uint64(len(msg)-size) < length {
+				return errors.New("truncated protobuf field")
+			}
+			msg = msg[size:]
+			fn(field, msg[:length], 0)
+			msg = msg[length:]
+		case 5: // 32-bit
+			if len(msg) < 4 {
+				return errors.New("truncated protobuf field")
+			}
+			msg = msg[4:]
+		default:
+			return fmt.Errorf("unsupported protobuf wire type %d", key&7)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santosr2/uptool/internal/secureio"
+)
+
+// DefaultCacheTTL is how long a cached response is reused when the registry
+// sends no Cache-Control max-age of its own.
+const DefaultCacheTTL = time.Hour
+
+// CacheEntry is a stored registry response.
+type CacheEntry struct {
+	Expires      time.Time   `json:"expires"`
+	Header       http.Header `json:"header"`
+	ETag         string      `json:"etag,omitempty"`
+	LastModified string      `json:"last_modified,omitempty"`
+	Body         []byte      `json:"body"`
+}
+
+// Cache stores registry responses keyed by request. Implementations must be
+// safe for concurrent use.
+type Cache interface {
+	Get(key string) (*CacheEntry, bool)
+	Set(key string, entry *CacheEntry)
+}
+
+var (
+	httpCache    Cache
+	httpCacheTTL time.Duration
+	httpCacheMu  sync.RWMutex
+)
+
+// SetHTTPCache makes every client created by NewHTTPClient serve successful
+// GET responses from cache. Entries expire after the response's
+// Cache-Control max-age, or ttl when it has none, and are then revalidated
+// with their ETag or Last-Modified. A nil cache disables caching.
+func SetHTTPCache(cache Cache, ttl time.Duration) {
+	httpCacheMu.Lock()
+	defer httpCacheMu.Unlock()
+	httpCache, httpCacheTTL = cache, ttl
+}
+
+func currentHTTPCache() (Cache, time.Duration) {
+	httpCacheMu.RLock()
+	defer httpCacheMu.RUnlock()
+	return httpCache, httpCacheTTL
+}
+
+// DefaultCacheDir returns the directory the HTTP cache lives in:
+// $XDG_CACHE_HOME/uptool/http, or the platform's user cache directory.
+func DefaultCacheDir() (string, error) {
+	base := os.Getenv("XDG_CACHE_HOME")
+	if base == "" {
+		var err error
+		if base, err = os.UserCacheDir(); err != nil {
+			return "", fmt.Errorf("locate cache directory: %w", err)
+		}
+	}
+	return filepath.Join(base, "uptool", "http"), nil
+}
+
+// DiskCache is a Cache storing one JSON file per entry in a directory.
+// Unreadable or corrupt entries are treated as misses.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a cache stored in dir, which is created on first write.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve cache directory: %w", err)
+	}
+	return &DiskCache{dir: abs}, nil
+}
+
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get returns the entry stored under key.
+func (c *DiskCache) Get(key string) (*CacheEntry, bool) {
+	data, err := secureio.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// Set stores entry under key. Write failures are ignored; the response is
+// simply fetched again next time.
+func (c *DiskCache) Set(key string, entry *CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return
+	}
+
+	// Write then rename so concurrent runs never read a partial entry
+	path := c.path(key)
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := secureio.WriteFile(tmp, data, 0o600); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp) //nolint:errcheck // best-effort cleanup
+	}
+}
+
+// cachingTransport serves GET requests from the configured HTTP cache,
+// falling through to base on a miss or when caching is disabled. Requests
+// sent with "Cache-Control: no-store" bypass the cache.
+type cachingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip answers from a fresh cache entry, revalidates a stale one, and
+// stores successful responses the registry allows to be stored.
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	cache, ttl := currentHTTPCache()
+	if cache == nil || req.Method != http.MethodGet || hasDirective(req.Header, "no-store") ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	now := time.Now()
+	entry, cached := cache.Get(key)
+	if cached && now.Before(entry.Expires) {
+		return entry.response(req), nil
+	}
+
+	outgoing := req
+	if cached && (entry.ETag != "" || entry.LastModified != "") {
+		outgoing = req.Clone(req.Context())
+		if entry.ETag != "" {
+			outgoing.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			outgoing.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close() //nolint:errcheck // HTTP cleanup best effort
+		entry.Expires = expiresAt(resp.Header, now, ttl)
+		cache.Set(key, entry)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || hasDirective(resp.Header, "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close() //nolint:errcheck // HTTP cleanup best effort
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	cache.Set(key, &CacheEntry{
+		Expires:      expiresAt(resp.Header, now, ttl),
+		Header:       resp.Header.Clone(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body,
+	})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// credentialHeaders are the request headers registries authenticate with.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "PRIVATE-TOKEN", "Job-Token", "Cookie"}
+
+// cacheKey identifies a request by URL, Accept header and credentials, so
+// different tokens never share an entry. Credentials are only stored hashed.
+func cacheKey(req *http.Request) string {
+	key := req.URL.String() + "\n" + req.Header.Get("Accept")
+
+	hash := sha256.New()
+	authenticated := false
+	for _, name := range credentialHeaders {
+		for _, value := range req.Header.Values(name) {
+			authenticated = true
+			_, _ = fmt.Fprintf(hash, "%s: %s\n", name, value) //nolint:errcheck // hash writes never fail
+		}
+	}
+	if authenticated {
+		key += "\n" + hex.EncodeToString(hash.Sum(nil))
+	}
+	return key
+}
+
+// response rebuilds an HTTP response from a cache entry.
+func (e *CacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// expiresAt returns when a response stored at now goes stale: immediately for
+// no-cache, after max-age when given, otherwise after ttl.
+func expiresAt(header http.Header, now time.Time, ttl time.Duration) time.Time {
+	if hasDirective(header, "no-cache") {
+		return now
+	}
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(directive), "=")
+		if !ok || !strings.EqualFold(name, "max-age") {
+			continue
+		}
+		if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+			return now.Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	return now.Add(ttl)
+}
+
+// hasDirective reports whether the Cache-Control header carries directive.
+func hasDirective(header http.Header, directive string) bool {
+	for _, d := range strings.Split(header.Get("Cache-Control"), ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(d), "=")
+		if strings.EqualFold(name, directive) {
+			return true
+		}
+	}
+	return false
+}
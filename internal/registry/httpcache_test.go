@@ -0,0 +1,229 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// useDiskCache enables a disk cache in a temporary directory for the test.
+func useDiskCache(t *testing.T, ttl time.Duration) {
+	t.Helper()
+	cache, err := NewDiskCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetHTTPCache(cache, ttl)
+	t.Cleanup(func() { SetHTTPCache(nil, 0) })
+}
+
+// countingServer serves a fixed npm packument, setting headers on each
+// response, and counts the requests it receives.
+func countingServer(t *testing.T, headers map[string]string, handle func(w http.ResponseWriter, r *http.Request) bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var count atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+		for k, v := range headers {
+			w.Header().Set(k, v)
+		}
+		if handle != nil && handle(w, r) {
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"lodash","dist-tags":{"latest":"4.17.21"},"versions":{"4.17.21":{}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &count
+}
+
+func TestHTTPCache_ServesRepeatLookups(t *testing.T) {
+	useDiskCache(t, time.Hour)
+	server, count := countingServer(t, nil, nil)
+
+	ctx := context.Background()
+	client := NewNPMClient(NPMConfig{Registry: server.URL})
+	for range 2 {
+		versions, err := client.GetVersions(ctx, "lodash")
+		if err != nil {
+			t.Fatalf("GetVersions() error = %v", err)
+		}
+		if len(versions) != 1 || versions[0] != "4.17.21" {
+			t.Errorf("GetVersions() = %v, want [4.17.21]", versions)
+		}
+	}
+
+	// A new client shares the same cache
+	if _, err := NewNPMClient(NPMConfig{Registry: server.URL}).GetLatestVersion(ctx, "lodash"); err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+
+	if got := count.Load(); got != 1 {
+		t.Errorf("server received %d requests, want 1", got)
+	}
+}
+
+func TestHTTPCache_RevalidatesWithETag(t *testing.T) {
+	useDiskCache(t, time.Hour)
+	var revalidated atomic.Int32
+	server, count := countingServer(t, map[string]string{
+		"ETag":          `"v1"`,
+		"Cache-Control": "max-age=0",
+	}, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+		return false
+	})
+
+	client := NewNPMClient(NPMConfig{Registry: server.URL})
+	for range 2 {
+		versions, err := client.GetVersions(context.Background(), "lodash")
+		if err != nil {
+			t.Fatalf("GetVersions() error = %v", err)
+		}
+		if len(versions) != 1 {
+			t.Errorf("GetVersions() = %v, want one version", versions)
+		}
+	}
+
+	if got := count.Load(); got != 2 {
+		t.Errorf("server received %d requests, want 2", got)
+	}
+	if got := revalidated.Load(); got != 1 {
+		t.Errorf("conditional requests = %d, want 1", got)
+	}
+}
+
+func TestHTTPCache_Bypassed(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers map[string]string
+		enable  bool
+	}{
+		{name: "no-store response", headers: map[string]string{"Cache-Control": "private, no-store"}, enable: true},
+		{name: "cache disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.enable {
+				useDiskCache(t, time.Hour)
+			}
+			server, count := countingServer(t, tt.headers, nil)
+
+			client := NewNPMClient(NPMConfig{Registry: server.URL})
+			for range 2 {
+				if _, err := client.GetVersions(context.Background(), "lodash"); err != nil {
+					t.Fatalf("GetVersions() error = %v", err)
+				}
+			}
+			if got := count.Load(); got != 2 {
+				t.Errorf("server received %d requests, want 2", got)
+			}
+		})
+	}
+}
+
+func TestHTTPCache_ErrorsNotCached(t *testing.T) {
+	useDiskCache(t, time.Hour)
+	server, count := countingServer(t, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		w.WriteHeader(http.StatusInternalServerError)
+		return true
+	})
+
+	client := NewHelmClient()
+	for range 2 {
+		if _, err := client.GetChartVersions(context.Background(), server.URL, "nginx"); err == nil {
+			t.Fatal("GetChartVersions() expected error")
+		}
+	}
+	if got := count.Load(); got != 2 {
+		t.Errorf("server received %d requests, want 2", got)
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		cacheControl string
+		want         time.Time
+	}{
+		{"", now.Add(time.Hour)},
+		{"public, max-age=300", now.Add(5 * time.Minute)},
+		{"no-cache", now},
+		{"max-age=bogus", now.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.cacheControl != "" {
+			header.Set("Cache-Control", tt.cacheControl)
+		}
+		if got := expiresAt(header, now, time.Hour); !got.Equal(tt.want) {
+			t.Errorf("expiresAt(%q) = %v, want %v", tt.cacheControl, got, tt.want)
+		}
+	}
+}
+
+func TestCacheKey_Credentials(t *testing.T) {
+	newRequest := func(headers map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "https://gitlab.example.com/api/v4/projects/1/repository/tags", http.NoBody)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return req
+	}
+
+	anonymous := cacheKey(newRequest(nil))
+	keys := map[string]string{"anonymous": anonymous}
+	for name, headers := range map[string]map[string]string{
+		"authorization": {"Authorization": "Bearer token-a"},
+		"private token": {"PRIVATE-TOKEN": "token-a"},
+		"other token":   {"PRIVATE-TOKEN": "token-b"},
+		"job token":     {"Job-Token": "token-a"},
+	} {
+		key := cacheKey(newRequest(headers))
+		for other, otherKey := range keys {
+			if key == otherKey {
+				t.Errorf("cacheKey(%s) = cacheKey(%s), want distinct entries", name, other)
+			}
+		}
+		keys[name] = key
+
+		for _, v := range headers {
+			if strings.Contains(key, v) {
+				t.Errorf("cacheKey(%s) = %q contains the raw credential", name, key)
+			}
+		}
+	}
+
+	if got := cacheKey(newRequest(map[string]string{"PRIVATE-TOKEN": "token-a"})); got != keys["private token"] {
+		t.Errorf("cacheKey() is not stable for the same credentials")
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	mavenSearchURL = "https://search.maven.org"
+
+	// mavenSearchRows caps how many versions one search returns.
+	mavenSearchRows = 200
+)
+
+// MavenClient queries the Maven Central search API.
+type MavenClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewMavenClient creates a new Maven Central client.
+func NewMavenClient() *MavenClient {
+	return &MavenClient{
+		client:  NewHTTPClient("maven"),
+		baseURL: mavenSearchURL,
+	}
+}
+
+// mavenSearchResponse is the response of the solrsearch/select endpoint.
+type mavenSearchResponse struct {
+	Response struct {
+		Docs []struct {
+			Version       string `json:"v"`
+			LatestVersion string `json:"latestVersion"`
+		} `json:"docs"`
+		NumFound int `json:"numFound"`
+	} `json:"response"`
+}
+
+// splitCoordinate splits "group:artifact" into its parts.
+func splitCoordinate(coordinate string) (group, artifact string, err error) {
+	group, artifact, ok := strings.Cut(coordinate, ":")
+	if !ok || group == "" || artifact == "" {
+		return "", "", fmt.Errorf("invalid Maven coordinate %q: expected group:artifact", coordinate)
+	}
+	return group, artifact, nil
+}
+
+// GetLatestVersion returns the latest release Maven Central records for an
+// artifact, given as "group:artifact".
+func (c *MavenClient) GetLatestVersion(ctx context.Context, coordinate string) (string, error) {
+	resp, err := c.search(ctx, coordinate, false)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Response.Docs) == 0 || resp.Response.Docs[0].LatestVersion == "" {
+		return "", fmt.Errorf("artifact not found: %s", coordinate)
+	}
+	return resp.Response.Docs[0].LatestVersion, nil
+}
+
+// GetVersions returns the published versions of an artifact, newest first.
+func (c *MavenClient) GetVersions(ctx context.Context, coordinate string) ([]string, error) {
+	resp, err := c.search(ctx, coordinate, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Response.Docs) == 0 {
+		return nil, fmt.Errorf("artifact not found: %s", coordinate)
+	}
+
+	versions := make([]string, 0, len(resp.Response.Docs))
+	for _, doc := range resp.Response.Docs {
+		if doc.Version != "" {
+			versions = append(versions, doc.Version)
+		}
+	}
+	return versions, nil
+}
+
+// search queries solrsearch/select for an artifact. With allVersions it
+// searches the gav core, which returns one document per version.
+func (c *MavenClient) search(ctx context.Context, coordinate string, allVersions bool) (*mavenSearchResponse, error) {
+	group, artifact, err := splitCoordinate(coordinate)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("q", fmt.Sprintf(`g:"%s" AND a:"%s"`, group, artifact))
+	query.Set("wt", "json")
+	if allVersions {
+		query.Set("core", "gav")
+		query.Set("rows", fmt.Sprint(mavenSearchRows))
+	}
+	endpoint := c.baseURL + "/solrsearch/select?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("search Maven Central: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var result mavenSearchResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("parse search response: %w", err)
+	}
+	return &result, nil
+}
@@ -24,12 +24,17 @@
 package registry
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"time"
+	"os"
+	"strings"
 
 	"github.com/Masterminds/semver/v3"
 )
@@ -37,27 +42,234 @@ import (
 const npmRegistryURL = "https://registry.npmjs.org"
 
 // NPMClient queries the npm registry for package information.
+// Scoped packages can be routed to their own registry, and requests carry the
+// auth token configured for the registry they are sent to.
 type NPMClient struct {
 	client  *http.Client
+	scopes  map[string]string
+	tokens  map[string]string
+	auths   map[string]string
 	baseURL string
 }
 
-// NewNPMClient creates a new npm registry client.
-func NewNPMClient() *NPMClient {
-	return &NPMClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+// NPMConfig configures the registries an NPMClient queries, mirroring the
+// settings npm reads from .npmrc.
+type NPMConfig struct {
+	// Scopes maps a package scope (e.g., "@mycorp") to its registry URL.
+	Scopes map[string]string
+	// Tokens maps a registry in .npmrc form, without the protocol
+	// (e.g., "//npm.example.com/"), to its auth token.
+	Tokens map[string]string
+	// Auths maps a registry, keyed like Tokens, to base64 "user:password"
+	// credentials sent with basic auth (npm's _auth setting).
+	Auths map[string]string
+	// Registry replaces the public npm registry for unscoped packages.
+	Registry string
+}
+
+// NewNPMClient creates a new npm registry client. An optional config routes
+// scoped packages to private registries and authenticates against them.
+func NewNPMClient(config ...NPMConfig) *NPMClient {
+	c := &NPMClient{
+		client:  NewHTTPClient("npm"),
 		baseURL: npmRegistryURL,
+		scopes:  make(map[string]string),
+		tokens:  make(map[string]string),
+		auths:   make(map[string]string),
+	}
+	c.Configure(config...)
+	return c
+}
+
+// Configure merges registry settings into the client. Later settings take
+// precedence over earlier ones and over those the client was created with.
+func (c *NPMClient) Configure(config ...NPMConfig) {
+	for _, cfg := range config {
+		if cfg.Registry != "" {
+			c.baseURL = strings.TrimSuffix(cfg.Registry, "/")
+		}
+		for scope, url := range cfg.Scopes {
+			c.scopes[scope] = strings.TrimSuffix(url, "/")
+		}
+		for registry, token := range cfg.Tokens {
+			c.tokens[registry] = token
+		}
+		for registry, auth := range cfg.Auths {
+			c.auths[registry] = auth
+		}
+	}
+}
+
+// ParseNPMRC reads registry settings from .npmrc content:
+//
+//	registry=https://registry.example.com/
+//	@mycorp:registry=https://npm.mycorp.com/
+//	//npm.mycorp.com/:_authToken=${NPM_TOKEN}
+//	//npm.other.com/:_auth=${NPM_BASIC_AUTH}
+//
+// Environment variable references are expanded. Other settings are ignored.
+func ParseNPMRC(content []byte) NPMConfig {
+	cfg := NPMConfig{
+		Scopes: make(map[string]string),
+		Tokens: make(map[string]string),
+		Auths:  make(map[string]string),
 	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = os.ExpandEnv(strings.Trim(strings.TrimSpace(value), `"'`))
+
+		switch {
+		case key == "registry":
+			cfg.Registry = value
+		case strings.HasPrefix(key, "@") && strings.HasSuffix(key, ":registry"):
+			cfg.Scopes[strings.TrimSuffix(key, ":registry")] = value
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_authToken"):
+			cfg.Tokens[strings.TrimSuffix(key, ":_authToken")] = value
+		case strings.HasPrefix(key, "//") && strings.HasSuffix(key, ":_auth"):
+			cfg.Auths[strings.TrimSuffix(key, ":_auth")] = value
+		}
+	}
+
+	return cfg
+}
+
+// registryFor returns the registry URL that serves a package: its scope's
+// registry when one is configured, otherwise the default registry.
+func (c *NPMClient) registryFor(packageName string) string {
+	if strings.HasPrefix(packageName, "@") {
+		scope, _, _ := strings.Cut(packageName, "/")
+		if url, ok := c.scopes[scope]; ok {
+			return url
+		}
+	}
+	return c.baseURL
+}
+
+// RegistryFor returns the registry URL that serves a package.
+func (c *NPMClient) RegistryFor(packageName string) string {
+	return c.registryFor(packageName)
+}
+
+// tokenFor returns the auth token of the most specific registry entry that
+// url falls under, matching npm's "//host/path/:_authToken" keys.
+func (c *NPMClient) tokenFor(url string) string {
+	return mostSpecificRegistry(c.tokens, url)
+}
+
+// authorization returns the Authorization header for a request to url: a
+// bearer token when one is configured, otherwise basic auth credentials.
+func (c *NPMClient) authorization(url string) string {
+	if token := c.tokenFor(url); token != "" {
+		return "Bearer " + token
+	}
+	if auth := mostSpecificRegistry(c.auths, url); auth != "" {
+		return "Basic " + auth
+	}
+	return ""
+}
+
+// mostSpecificRegistry returns the value of the longest "//host/path/" key
+// in settings that url falls under.
+func mostSpecificRegistry(settings map[string]string, url string) string {
+	_, rest, ok := strings.Cut(url, "://")
+	if !ok {
+		return ""
+	}
+	rest = "//" + rest
+
+	var value string
+	best := 0
+	for registry, v := range settings {
+		prefix := strings.TrimSuffix(registry, "/") + "/"
+		if strings.HasPrefix(rest, prefix) && len(prefix) > best {
+			value, best = v, len(prefix)
+		}
+	}
+	return value
+}
+
+// escapePackageName encodes the slash of a scoped package name the way the npm
+// CLI does, which private registries require.
+func escapePackageName(packageName string) string {
+	if strings.HasPrefix(packageName, "@") {
+		return strings.Replace(packageName, "/", "%2f", 1)
+	}
+	return packageName
 }
 
 // PackageInfo contains npm package metadata.
 type PackageInfo struct {
-	Versions map[string]map[string]interface{} `json:"versions"`
-	DistTags map[string]string                 `json:"dist-tags"`
-	Time     map[string]string                 `json:"time"`
-	Name     string                            `json:"name"`
+	Versions   map[string]map[string]interface{} `json:"versions"`
+	DistTags   map[string]string                 `json:"dist-tags"`
+	Time       map[string]string                 `json:"time"`
+	Name       string                            `json:"name"`
+	Repository PackageRepository                 `json:"repository"`
+}
+
+// PackageRepository is the "repository" field of package.json, which is
+// either an object with a url or a shorthand string such as
+// "github:owner/repo" or "owner/repo".
+type PackageRepository struct {
+	Type      string `json:"type"`
+	URL       string `json:"url"`
+	Directory string `json:"directory"`
+}
+
+// UnmarshalJSON accepts both the object and the shorthand string form.
+func (r *PackageRepository) UnmarshalJSON(data []byte) error {
+	var shorthand string
+	if err := json.Unmarshal(data, &shorthand); err == nil {
+		*r = PackageRepository{URL: shorthand}
+		return nil
+	}
+
+	type plain PackageRepository
+	var repo plain
+	if err := json.Unmarshal(data, &repo); err != nil {
+		return err
+	}
+	*r = PackageRepository(repo)
+	return nil
+}
+
+// WebURL returns the repository's browsable https URL, normalizing git
+// transports ("git+https://", "git://", "git@host:") and the "github:",
+// "gitlab:", and bare "owner/repo" shorthands. It returns "" when there is
+// no repository.
+func (r PackageRepository) WebURL() string {
+	url := strings.TrimSpace(r.URL)
+	if url == "" {
+		return ""
+	}
+
+	for _, shorthand := range [][2]string{{"github:", "github.com/"}, {"gitlab:", "gitlab.com/"}, {"bitbucket:", "bitbucket.org/"}} {
+		if rest, ok := strings.CutPrefix(url, shorthand[0]); ok {
+			return "https://" + shorthand[1] + rest
+		}
+	}
+
+	url = strings.TrimPrefix(url, "git+")
+	switch {
+	case strings.HasPrefix(url, "git@"):
+		url = "https://" + strings.Replace(strings.TrimPrefix(url, "git@"), ":", "/", 1)
+	case strings.HasPrefix(url, "git://"), strings.HasPrefix(url, "ssh://"):
+		_, rest, _ := strings.Cut(url, "://")
+		url = "https://" + strings.TrimPrefix(rest, "git@")
+	case !strings.Contains(url, "://"):
+		url = "https://github.com/" + url
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git")
 }
 
 // GetLatestVersion fetches the latest version for a package.
@@ -77,7 +289,7 @@ func (c *NPMClient) GetLatestVersion(ctx context.Context, packageName string) (s
 
 // GetPackageInfo fetches full package information from npm registry.
 func (c *NPMClient) GetPackageInfo(ctx context.Context, packageName string) (*PackageInfo, error) {
-	url := fmt.Sprintf("%s/%s", c.baseURL, packageName)
+	url := fmt.Sprintf("%s/%s", c.registryFor(packageName), escapePackageName(packageName))
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
 	if err != nil {
@@ -85,6 +297,9 @@ func (c *NPMClient) GetPackageInfo(ctx context.Context, packageName string) (*Pa
 	}
 
 	req.Header.Set("Accept", "application/json")
+	if auth := c.authorization(url); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -174,3 +389,72 @@ func (c *NPMClient) GetVersions(ctx context.Context, packageName string) ([]stri
 
 	return versions, nil
 }
+
+// NPMDist is the dist metadata of a published package version: where its
+// tarball is and the Subresource Integrity string package-lock.json records
+// for it (e.g., "sha512-<base64>").
+type NPMDist struct {
+	Tarball   string
+	Integrity string
+}
+
+// GetDist returns the dist metadata of a package version. Versions published
+// before npm recorded integrity strings get one derived from their sha1
+// shasum, as npm itself does.
+func (c *NPMClient) GetDist(ctx context.Context, packageName, version string) (*NPMDist, error) {
+	info, err := c.GetPackageInfo(ctx, packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	dist, _ := info.Versions[version]["dist"].(map[string]interface{}) //nolint:errcheck // absent dist handled below
+	tarball, _ := dist["tarball"].(string)                             //nolint:errcheck // absent tarball handled below
+	if tarball == "" {
+		return nil, fmt.Errorf("no tarball for %s@%s", packageName, version)
+	}
+
+	integrity, _ := dist["integrity"].(string) //nolint:errcheck // absent integrity handled below
+	if integrity == "" {
+		shasum, _ := dist["shasum"].(string) //nolint:errcheck // absent shasum handled below
+		if sum, err := hex.DecodeString(shasum); err == nil && len(sum) > 0 {
+			integrity = "sha1-" + base64.StdEncoding.EncodeToString(sum)
+		}
+	}
+
+	return &NPMDist{Tarball: tarball, Integrity: integrity}, nil
+}
+
+// GetTarball downloads the published tarball of a package version, as listed
+// in the version's dist metadata.
+func (c *NPMClient) GetTarball(ctx context.Context, packageName, version string) ([]byte, error) {
+	dist, err := c.GetDist(ctx, packageName, version)
+	if err != nil {
+		return nil, err
+	}
+	url := dist.Tarball
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if auth := c.authorization(url); auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch tarball: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const nugetFlatContainerURL = "https://api.nuget.org/v3-flatcontainer"
+
+// NuGetClient queries the NuGet v3 flat container (package base address) API.
+type NuGetClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewNuGetClient creates a new NuGet client for nuget.org.
+func NewNuGetClient() *NuGetClient {
+	return &NuGetClient{
+		client:  NewHTTPClient("nuget"),
+		baseURL: nugetFlatContainerURL,
+	}
+}
+
+// nugetVersionIndex is the response of {base}/{id}/index.json.
+type nugetVersionIndex struct {
+	Versions []string `json:"versions"`
+}
+
+// GetVersions fetches all published, listed versions of a package. Package
+// IDs are case-insensitive; the flat container expects them lowercased.
+func (c *NuGetClient) GetVersions(ctx context.Context, id string) ([]string, error) {
+	endpoint := fmt.Sprintf("%s/%s/index.json", c.baseURL, url.PathEscape(strings.ToLower(id)))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package versions: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("package not found: %s", id)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var index nugetVersionIndex
+	if err := json.Unmarshal(body, &index); err != nil {
+		return nil, fmt.Errorf("parse version index: %w", err)
+	}
+	return index.Versions, nil
+}
+
+// GetLatestVersion returns the highest stable version of a package.
+func (c *NuGetClient) GetLatestVersion(ctx context.Context, id string) (string, error) {
+	versions, err := c.GetVersions(ctx, id)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *semver.Version
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found for package: %s", id)
+	}
+
+	return latest.Original(), nil
+}
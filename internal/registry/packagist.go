@@ -0,0 +1,152 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const packagistURL = "https://repo.packagist.org"
+
+// PackagistClient queries the Packagist Composer v2 metadata API.
+type PackagistClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewPackagistClient creates a new Packagist client.
+func NewPackagistClient() *PackagistClient {
+	return &PackagistClient{
+		client:  NewHTTPClient("packagist"),
+		baseURL: packagistURL,
+	}
+}
+
+// PackagistVersion is a tagged release of a Composer package.
+type PackagistVersion struct {
+	Version string `json:"version"`
+	Time    string `json:"time"`
+}
+
+// packagistMetadata is the response of /p2/{vendor}/{package}.json. Each
+// release only lists the fields that changed since the previous one, but
+// version is always present.
+type packagistMetadata struct {
+	Packages map[string][]PackagistVersion `json:"packages"`
+}
+
+// GetReleases fetches the tagged releases of a package ("vendor/package"),
+// newest first. Development branches are served separately and not included.
+func (c *PackagistClient) GetReleases(ctx context.Context, name string) ([]PackagistVersion, error) {
+	name = strings.ToLower(name)
+	if strings.Count(name, "/") != 1 {
+		return nil, fmt.Errorf("invalid package name %q: want vendor/package", name)
+	}
+
+	endpoint := fmt.Sprintf("%s/p2/%s.json", c.baseURL, name)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch package metadata: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("package not found: %s", name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var metadata packagistMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("parse package metadata: %w", err)
+	}
+
+	releases, ok := metadata.Packages[name]
+	if !ok {
+		return nil, fmt.Errorf("package not found: %s", name)
+	}
+
+	// Fields omitted from a minified entry are inherited from the one before
+	for i := 1; i < len(releases); i++ {
+		if releases[i].Time == "" {
+			releases[i].Time = releases[i-1].Time
+		}
+	}
+	return releases, nil
+}
+
+// GetVersions fetches all tagged versions of a package.
+func (c *PackagistClient) GetVersions(ctx context.Context, name string) ([]string, error) {
+	releases, err := c.GetReleases(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(releases))
+	for _, r := range releases {
+		versions = append(versions, r.Version)
+	}
+	return versions, nil
+}
+
+// GetLatestVersion returns the highest stable version of a package.
+func (c *PackagistClient) GetLatestVersion(ctx context.Context, name string) (string, error) {
+	versions, err := c.GetVersions(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *semver.Version
+	for _, v := range versions {
+		parsed, err := semver.NewVersion(v)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found for package: %s", name)
+	}
+
+	return latest.Original(), nil
+}
@@ -22,10 +22,18 @@
 package registry
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -354,6 +362,100 @@ func TestNPMClient_GetVersions(t *testing.T) {
 	}
 }
 
+func TestNPMClient_GetTarball(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/pnpm":
+			_ = json.NewEncoder(w).Encode(PackageInfo{
+				Name: "pnpm",
+				Versions: map[string]map[string]interface{}{
+					"9.0.0": {"dist": map[string]interface{}{"tarball": server.URL + "/pnpm/-/pnpm-9.0.0.tgz"}},
+					"9.1.0": {},
+				},
+			})
+		case "/pnpm/-/pnpm-9.0.0.tgz":
+			_, _ = w.Write([]byte("tarball"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &NPMClient{
+		client:  &http.Client{Timeout: 5 * time.Second},
+		baseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	data, err := client.GetTarball(ctx, "pnpm", "9.0.0")
+	if err != nil {
+		t.Fatalf("GetTarball() error = %v", err)
+	}
+	if string(data) != "tarball" {
+		t.Errorf("GetTarball() = %q, want %q", data, "tarball")
+	}
+
+	if _, err := client.GetTarball(ctx, "pnpm", "9.1.0"); err == nil {
+		t.Error("GetTarball() expected error for version without dist")
+	}
+}
+
+func TestNPMClient_GetDist(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lodash" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(PackageInfo{
+			Name: "lodash",
+			Versions: map[string]map[string]interface{}{
+				"4.17.21": {"dist": map[string]interface{}{
+					"tarball":   "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+					"integrity": "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==",
+					"shasum":    "679591c564c3bffaae8454cf0b3df370c3d6911c",
+				}},
+				"0.1.0": {"dist": map[string]interface{}{
+					"tarball": "https://registry.npmjs.org/lodash/-/lodash-0.1.0.tgz",
+					"shasum":  "679591c564c3bffaae8454cf0b3df370c3d6911c",
+				}},
+				"0.0.1": {},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := &NPMClient{
+		client:  &http.Client{Timeout: 5 * time.Second},
+		baseURL: server.URL,
+	}
+	ctx := context.Background()
+
+	dist, err := client.GetDist(ctx, "lodash", "4.17.21")
+	if err != nil {
+		t.Fatalf("GetDist() error = %v", err)
+	}
+	want := NPMDist{
+		Tarball:   "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz",
+		Integrity: "sha512-v2kDEe57lecTulaDIuNTPy3Ry4gLGJ6Z1O3vE1krgXZNrsQ+LFTGHVxVjcXPs17LhbZVGedAJv8XZ1tvj5FvSg==",
+	}
+	if *dist != want {
+		t.Errorf("GetDist() = %+v, want %+v", *dist, want)
+	}
+
+	dist, err = client.GetDist(ctx, "lodash", "0.1.0")
+	if err != nil {
+		t.Fatalf("GetDist() error = %v", err)
+	}
+	if wantIntegrity := "sha1-Z5WRxWTDv/quhFTPCz3zcMPWkRw="; dist.Integrity != wantIntegrity {
+		t.Errorf("GetDist() integrity from shasum = %q, want %q", dist.Integrity, wantIntegrity)
+	}
+
+	if _, err := client.GetDist(ctx, "lodash", "0.0.1"); err == nil {
+		t.Error("GetDist() expected error for version without dist")
+	}
+}
+
 func TestNewNPMClient(t *testing.T) {
 	client := NewNPMClient()
 
@@ -370,6 +472,96 @@ func TestNewNPMClient(t *testing.T) {
 	}
 }
 
+func TestNPMClient_ScopedRegistry(t *testing.T) {
+	response := PackageInfo{
+		Name:     "pkg",
+		DistTags: map[string]string{"latest": "2.0.0"},
+		Versions: map[string]map[string]interface{}{"2.0.0": {}},
+	}
+
+	var scopedPath, scopedAuth string
+	scoped := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scopedPath = r.URL.EscapedPath()
+		scopedAuth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer scoped.Close()
+
+	var defaultPath, defaultAuth string
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defaultPath = r.URL.Path
+		defaultAuth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer public.Close()
+
+	client := NewNPMClient(NPMConfig{
+		Registry: public.URL,
+		Scopes:   map[string]string{"@mycorp": scoped.URL + "/npm/"},
+		Tokens:   map[string]string{strings.TrimPrefix(scoped.URL, "http:") + "/npm/": "secret"},
+	})
+
+	ctx := context.Background()
+	if _, err := client.GetLatestVersion(ctx, "@mycorp/lib"); err != nil {
+		t.Fatalf("GetLatestVersion(@mycorp/lib) error = %v", err)
+	}
+	if scopedPath != "/npm/@mycorp%2flib" {
+		t.Errorf("scoped registry path = %q, want %q", scopedPath, "/npm/@mycorp%2flib")
+	}
+	if scopedAuth != "Bearer secret" {
+		t.Errorf("scoped registry Authorization = %q, want %q", scopedAuth, "Bearer secret")
+	}
+
+	if _, err := client.FindBestVersion(ctx, "lodash", "^2.0.0", false); err != nil {
+		t.Fatalf("FindBestVersion(lodash) error = %v", err)
+	}
+	if defaultPath != "/lodash" {
+		t.Errorf("default registry path = %q, want %q", defaultPath, "/lodash")
+	}
+	if defaultAuth != "" {
+		t.Errorf("default registry Authorization = %q, want none", defaultAuth)
+	}
+
+	defaultPath = ""
+	if _, err := client.GetPackageInfo(ctx, "@other/lib"); err != nil {
+		t.Fatalf("GetPackageInfo(@other/lib) error = %v", err)
+	}
+	if defaultPath != "/@other/lib" {
+		t.Errorf("unconfigured scope path = %q, want default registry", defaultPath)
+	}
+}
+
+func TestParseNPMRC(t *testing.T) {
+	t.Setenv("NPM_TOKEN", "from-env")
+
+	content := []byte(`# user settings
+registry=https://registry.example.com/
+@mycorp:registry=https://npm.mycorp.com/
+//npm.mycorp.com/:_authToken=${NPM_TOKEN}
+//npm.other.com/:_auth=dXNlcjpwYXNz
+; unrelated settings are ignored
+save-exact=true
+`)
+
+	cfg := ParseNPMRC(content)
+
+	if cfg.Registry != "https://registry.example.com/" {
+		t.Errorf("Registry = %q, want %q", cfg.Registry, "https://registry.example.com/")
+	}
+	if got := cfg.Scopes["@mycorp"]; got != "https://npm.mycorp.com/" {
+		t.Errorf("Scopes[@mycorp] = %q, want %q", got, "https://npm.mycorp.com/")
+	}
+	if got := cfg.Tokens["//npm.mycorp.com/"]; got != "from-env" {
+		t.Errorf("Tokens[//npm.mycorp.com/] = %q, want %q", got, "from-env")
+	}
+	if got := cfg.Auths["//npm.other.com/"]; got != "dXNlcjpwYXNz" {
+		t.Errorf("Auths[//npm.other.com/] = %q, want %q", got, "dXNlcjpwYXNz")
+	}
+	if len(cfg.Scopes) != 1 || len(cfg.Tokens) != 1 || len(cfg.Auths) != 1 {
+		t.Errorf("ParseNPMRC() = %+v, want one scope, one token and one auth", cfg)
+	}
+}
+
 // =============================================================================
 // GitHub Client Tests
 // =============================================================================
@@ -478,6 +670,35 @@ func TestGitHubClient_GetAllReleases(t *testing.T) {
 	}
 }
 
+func TestGitHubClient_GetTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/tags" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"name":"1.3.0","commit":{"sha":"abc"}},{"name":"1.2.3","commit":{"sha":"def"}}]`))
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{
+		client:  &http.Client{Timeout: 5 * time.Second},
+		baseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	tags, err := client.GetTags(ctx, "owner", "repo")
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "1.3.0" || tags[1] != "1.2.3" {
+		t.Errorf("GetTags() = %v, want [1.3.0 1.2.3]", tags)
+	}
+
+	if _, err := client.GetTags(ctx, "owner", "missing"); err == nil || !strings.Contains(err.Error(), "repository not found") {
+		t.Errorf("GetTags() for unknown repository error = %v, want repository not found", err)
+	}
+}
+
 func TestGitHubClient_FindBestRelease(t *testing.T) {
 	releases := []Release{
 		{TagName: "v2.0.0", Prerelease: false, Draft: false},
@@ -569,8 +790,202 @@ func TestGitHubClient_WithToken(t *testing.T) {
 	}
 }
 
+func TestGitHubClient_GetCommitSHA(t *testing.T) {
+	const sha = "11bd71901bbe5b1630ceea73d27597364c9af683"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "application/vnd.github.sha" {
+			t.Errorf("Accept header = %q, want application/vnd.github.sha", r.Header.Get("Accept"))
+		}
+		if r.URL.Path != "/repos/actions/checkout/commits/v4.2.2" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		_, _ = w.Write([]byte(sha))
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	got, err := client.GetCommitSHA(context.Background(), "actions", "checkout", "v4.2.2")
+	if err != nil {
+		t.Fatalf("GetCommitSHA() error = %v", err)
+	}
+	if got != sha {
+		t.Errorf("GetCommitSHA() = %q, want %q", got, sha)
+	}
+
+	if _, err := client.GetCommitSHA(context.Background(), "actions", "checkout", "v0.0.0"); err == nil {
+		t.Error("GetCommitSHA() for unknown ref should return error")
+	}
+}
+
+func TestGitHubClient_ReleaseNotesAndCompare(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/expressjs/express/releases/tags/v4.19.0":
+			_, _ = w.Write([]byte(`{"tag_name":"v4.19.0","body":"* Fix routing","html_url":"https://github.com/expressjs/express/releases/tag/v4.19.0"}`))
+		case "/repos/expressjs/express/compare/v4.18.0...v4.19.0":
+			if got := r.URL.Query().Get("per_page"); got != "2" {
+				t.Errorf("per_page = %q, want 2", got)
+			}
+			_, _ = w.Write([]byte(`{"commits":[
+				{"sha":"a1","html_url":"https://github.com/expressjs/express/commit/a1","commit":{"message":"Fix routing\n\nDetails","author":{"name":"Jane"}},"author":{"login":"jane"}},
+				{"sha":"b2","commit":{"message":"Bump deps","author":{"name":"Bot"}},"author":null},
+				{"sha":"c3","commit":{"message":"Release 4.19.0"}}
+			]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{client: server.Client(), baseURL: server.URL}
+	ctx := context.Background()
+
+	release, err := client.GetReleaseByTag(ctx, "expressjs", "express", "v4.19.0")
+	if err != nil {
+		t.Fatalf("GetReleaseByTag() error = %v", err)
+	}
+	if release.Body != "* Fix routing" || release.HTMLURL != "https://github.com/expressjs/express/releases/tag/v4.19.0" {
+		t.Errorf("GetReleaseByTag() = %+v", release)
+	}
+	if _, err := client.GetReleaseByTag(ctx, "expressjs", "express", "4.19.0"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetReleaseByTag() for unknown tag error = %v, want ErrNotFound", err)
+	}
+
+	commits, err := client.CompareCommits(ctx, "expressjs", "express", "v4.18.0", "v4.19.0", 2)
+	if err != nil {
+		t.Fatalf("CompareCommits() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("CompareCommits() returned %d commits, want at most 2", len(commits))
+	}
+	if commits[0].SHA != "a1" || commits[0].Author == nil || commits[0].Author.Login != "jane" || commits[1].Author != nil {
+		t.Errorf("CompareCommits() = %+v", commits)
+	}
+}
+
+func TestPackageRepository(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{"object", `{"type":"git","url":"git+https://github.com/expressjs/express.git"}`, "https://github.com/expressjs/express"},
+		{"git protocol", `{"url":"git://github.com/lodash/lodash.git"}`, "https://github.com/lodash/lodash"},
+		{"ssh", `{"url":"git@github.com:facebook/react.git"}`, "https://github.com/facebook/react"},
+		{"github shorthand", `"github:sindresorhus/got"`, "https://github.com/sindresorhus/got"},
+		{"bare shorthand", `"npm/cli"`, "https://github.com/npm/cli"},
+		{"gitlab shorthand", `"gitlab:group/project"`, "https://gitlab.com/group/project"},
+		{"missing", `{}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var info PackageInfo
+			if err := json.Unmarshal([]byte(`{"name":"pkg","repository":`+tt.json+`}`), &info); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if got := info.Repository.WebURL(); got != tt.want {
+				t.Errorf("WebURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubClient_GetSecurityVulnerabilities(t *testing.T) {
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			t.Errorf("request = %s %s, want POST /graphql", r.Method, r.URL.Path)
+		}
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		if body.Variables["ecosystem"] != "NPM" || body.Variables["package"] != "lodash" {
+			t.Errorf("variables = %v, want ecosystem NPM and package lodash", body.Variables)
+		}
+		pages++
+
+		w.Header().Set("Content-Type", "application/json")
+		if body.Variables["after"] == nil {
+			_, _ = w.Write([]byte(`{"data":{"securityVulnerabilities":{
+				"nodes":[
+					{"advisory":{"ghsaId":"GHSA-p6mc-m468-83gw","severity":"HIGH","summary":"Prototype pollution"},
+					 "vulnerableVersionRange":"< 4.17.20","firstPatchedVersion":{"identifier":"4.17.20"}},
+					{"advisory":{"ghsaId":"GHSA-withdrawn","severity":"LOW","summary":"Withdrawn","withdrawnAt":"2024-01-01T00:00:00Z"},
+					 "vulnerableVersionRange":"< 5.0.0","firstPatchedVersion":null}
+				],
+				"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}`))
+			return
+		}
+		if body.Variables["after"] != "c1" {
+			t.Errorf("after = %v, want c1", body.Variables["after"])
+		}
+		_, _ = w.Write([]byte(`{"data":{"securityVulnerabilities":{
+			"nodes":[
+				{"advisory":{"ghsaId":"GHSA-35jh-r3h4-6jhm","severity":"CRITICAL","summary":"Command injection"},
+				 "vulnerableVersionRange":"< 4.17.21","firstPatchedVersion":{"identifier":"4.17.21"}}
+			],
+			"pageInfo":{"hasNextPage":false,"endCursor":"c2"}}}}`))
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+		token:   "test-token",
+	}
+
+	vulns, err := client.GetSecurityVulnerabilities(context.Background(), "NPM", "lodash")
+	if err != nil {
+		t.Fatalf("GetSecurityVulnerabilities() error = %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("pages requested = %d, want 2", pages)
+	}
+
+	want := []SecurityVulnerability{
+		{GHSAID: "GHSA-p6mc-m468-83gw", Severity: "HIGH", Summary: "Prototype pollution", VulnerableVersionRange: "< 4.17.20", FirstPatchedVersion: "4.17.20"},
+		{GHSAID: "GHSA-35jh-r3h4-6jhm", Severity: "CRITICAL", Summary: "Command injection", VulnerableVersionRange: "< 4.17.21", FirstPatchedVersion: "4.17.21"},
+	}
+	if len(vulns) != len(want) {
+		t.Fatalf("GetSecurityVulnerabilities() = %+v, want %+v", vulns, want)
+	}
+	for i := range want {
+		if vulns[i] != want[i] {
+			t.Errorf("vulnerability %d = %+v, want %+v", i, vulns[i], want[i])
+		}
+	}
+}
+
+func TestGitHubClient_GetSecurityVulnerabilities_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Bad credentials"}]}`))
+	}))
+	defer server.Close()
+
+	anonymous := &GitHubClient{client: server.Client(), baseURL: server.URL}
+	if _, err := anonymous.GetSecurityVulnerabilities(context.Background(), "NPM", "lodash"); !errors.Is(err, ErrTokenRequired) {
+		t.Errorf("GetSecurityVulnerabilities() without token error = %v, want ErrTokenRequired", err)
+	}
+
+	client := &GitHubClient{client: server.Client(), baseURL: server.URL, token: "bad"}
+	_, err := client.GetSecurityVulnerabilities(context.Background(), "NPM", "lodash")
+	if err == nil || !strings.Contains(err.Error(), "Bad credentials") {
+		t.Errorf("GetSecurityVulnerabilities() error = %v, want GraphQL error", err)
+	}
+}
+
 func TestNewGitHubClient(t *testing.T) {
-	client := NewGitHubClient("test-token")
+	client := NewGitHubClient("test-token", 2, 30*time.Second)
 
 	if client == nil {
 		t.Fatal("NewGitHubClient() returned nil")
@@ -583,6 +998,132 @@ func TestNewGitHubClient(t *testing.T) {
 	if client.baseURL != githubAPIURL {
 		t.Errorf("NewGitHubClient() baseURL = %v, want %v", client.baseURL, githubAPIURL)
 	}
+
+	if client.maxRetries != 2 || client.maxWait != 30*time.Second {
+		t.Errorf("NewGitHubClient() retries = %d, maxWait = %v, want 2, 30s", client.maxRetries, client.maxWait)
+	}
+}
+
+func TestGitHubClient_RetriesRateLimit(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Release{TagName: "v1.2.3"})
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("", 2, 5*time.Second)
+	client.baseURL = server.URL
+
+	start := time.Now()
+	got, err := client.GetLatestRelease(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("GetLatestRelease() error = %v", err)
+	}
+	if got != "1.2.3" {
+		t.Errorf("GetLatestRelease() = %q, want %q", got, "1.2.3")
+	}
+	if calls != 2 {
+		t.Errorf("server called %d times, want 2", calls)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retry waited %v, want at least the Retry-After of 1s", elapsed)
+	}
+}
+
+func TestGitHubClient_RateLimitExhausted(t *testing.T) {
+	tests := []struct {
+		name    string
+		retries int
+		maxWait time.Duration
+		header  string
+		value   string
+	}{
+		{name: "no retries left", retries: 0, maxWait: time.Minute, header: "Retry-After", value: "0"},
+		{name: "reset beyond max wait", retries: 3, maxWait: time.Second, header: "Retry-After", value: "3600"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(tt.header, tt.value)
+				w.WriteHeader(http.StatusForbidden)
+			}))
+			defer server.Close()
+
+			client := NewGitHubClient("", tt.retries, tt.maxWait)
+			client.baseURL = server.URL
+
+			_, err := client.GetLatestRelease(context.Background(), "owner", "repo")
+			if !errors.Is(err, ErrRateLimited) {
+				t.Errorf("GetLatestRelease() error = %v, want ErrRateLimited", err)
+			}
+		})
+	}
+}
+
+func TestGitHubClient_RespectsContextDeadline(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("", 3, time.Minute)
+	client.baseURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetLatestRelease(ctx, "owner", "repo")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("GetLatestRelease() error = %v, want ErrRateLimited", err)
+	}
+	if calls != 1 {
+		t.Errorf("server called %d times, want 1", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetLatestRelease() took %v, should give up without sleeping past the deadline", elapsed)
+	}
+}
+
+func TestGitHubClient_BacksOffOnServerError(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]Release{{TagName: "v2.0.0"}})
+	}))
+	defer server.Close()
+
+	client := NewGitHubClient("", 3, time.Minute)
+	client.baseURL = server.URL
+	client.backoff = time.Millisecond
+
+	releases, err := client.GetAllReleases(context.Background(), "owner", "repo")
+	if err != nil {
+		t.Fatalf("GetAllReleases() error = %v", err)
+	}
+	if len(releases) != 1 || calls != 3 {
+		t.Errorf("GetAllReleases() = %d releases after %d calls, want 1 after 3", len(releases), calls)
+	}
+
+	calls = -10
+	if _, err := client.GetAllReleases(context.Background(), "owner", "repo"); err == nil {
+		t.Error("GetAllReleases() should fail once retries are exhausted")
+	}
 }
 
 func TestParseGitHubURL(t *testing.T) {
@@ -909,6 +1450,81 @@ func TestIsOCIRepository(t *testing.T) {
 	}
 }
 
+// newOCIChartServer simulates a registry that requires an anonymous bearer
+// token for charts/nginx and serves its tags across two pages.
+func newOCIChartServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if got := r.URL.Query().Get("scope"); got != "repository:charts/nginx:pull" {
+				t.Errorf("token scope = %q, want repository:charts/nginx:pull", got)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"token": "pull-token"})
+		case "/v2/charts/nginx/tags/list":
+			if r.Header.Get("Authorization") != "Bearer pull-token" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="registry.test",scope="repository:charts/nginx:pull"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			tags := []string{"1.2.0", "1.2.1_build.5", "latest", "sha256-0a1b.sig"}
+			if r.URL.Query().Get("last") == "" {
+				tags = []string{"1.0.0", "1.9.0", "2.0.0-rc.1", "1.10.0"}
+				w.Header().Set("Link", `</v2/charts/nginx/tags/list?n=4&last=1.10.0>; rel="next"`)
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "charts/nginx", "tags": tags})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestHelmClient_OCI(t *testing.T) {
+	server := newOCIChartServer(t)
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	client := NewHelmClient()
+	client.client = server.Client()
+	ctx := context.Background()
+
+	for _, repository := range []string{"oci://" + host + "/charts", "oci://" + host + "/charts/nginx/"} {
+		t.Run(repository, func(t *testing.T) {
+			latest, err := client.GetLatestChartVersion(ctx, repository, "nginx")
+			if err != nil {
+				t.Fatalf("GetLatestChartVersion() error = %v", err)
+			}
+			if latest != "1.10.0" {
+				t.Errorf("GetLatestChartVersion() = %q, want 1.10.0", latest)
+			}
+
+			best, err := client.FindBestChartVersion(ctx, repository, "nginx", "~1.2")
+			if err != nil {
+				t.Fatalf("FindBestChartVersion() error = %v", err)
+			}
+			if best != "1.2.1+build.5" {
+				t.Errorf("FindBestChartVersion() = %q, want 1.2.1+build.5", best)
+			}
+
+			versions, err := client.GetChartVersions(ctx, repository, "nginx")
+			if err != nil {
+				t.Fatalf("GetChartVersions() error = %v", err)
+			}
+			want := []string{"1.0.0", "1.9.0", "2.0.0-rc.1", "1.10.0", "1.2.0", "1.2.1+build.5"}
+			if strings.Join(versions, ",") != strings.Join(want, ",") {
+				t.Errorf("GetChartVersions() = %v, want %v", versions, want)
+			}
+		})
+	}
+
+	if _, err := client.GetLatestChartVersion(ctx, "oci://"+host+"/charts", "redis"); err == nil {
+		t.Error("GetLatestChartVersion() for a missing chart should fail")
+	}
+}
+
 func TestNewHelmClient(t *testing.T) {
 	client := NewHelmClient()
 
@@ -921,21 +1537,108 @@ func TestNewHelmClient(t *testing.T) {
 	}
 }
 
-// =============================================================================
-// Terraform Client Tests
-// =============================================================================
+func TestHelmClient_Verification(t *testing.T) {
+	index := []byte("apiVersion: v1\nentries:\n  nginx:\n    - name: nginx\n      version: 15.0.0\n")
+	sum := sha256.Sum256(index)
+	digest := hex.EncodeToString(sum[:])
+	tampered := strings.Repeat("0", 64)
 
-func TestTerraformClient_GetLatestProviderVersion(t *testing.T) {
 	tests := []struct {
-		name        string
-		source      string
-		response    ProviderVersions
-		statusCode  int
-		wantVersion string
-		wantErr     bool
+		name    string
+		files   map[string]string
+		verify  bool
+		wantErr bool
 	}{
 		{
-			name:   "successful latest version",
+			name:   "verification disabled ignores digest",
+			files:  map[string]string{"/index.yaml.sha256": tampered},
+			verify: false,
+		},
+		{
+			name:   "matching sha256 file",
+			files:  map[string]string{"/index.yaml.sha256": digest + "  index.yaml\n"},
+			verify: true,
+		},
+		{
+			name:    "mismatched sha256 file",
+			files:   map[string]string{"/index.yaml.sha256": tampered + "  index.yaml\n"},
+			verify:  true,
+			wantErr: true,
+		},
+		{
+			name: "matching provenance",
+			files: map[string]string{"/index.yaml.prov": "-----BEGIN PGP SIGNED MESSAGE-----\n" +
+				"Hash: SHA512\n\nfiles:\n  index.yaml: sha256:" + digest + "\n...\n"},
+			verify: true,
+		},
+		{
+			name: "mismatched provenance",
+			files: map[string]string{"/index.yaml.prov": "-----BEGIN PGP SIGNED MESSAGE-----\n" +
+				"Hash: SHA512\n\nfiles:\n  index.yaml: sha256:" + tampered + "\n...\n"},
+			verify:  true,
+			wantErr: true,
+		},
+		{
+			name:    "no published digest",
+			files:   map[string]string{},
+			verify:  true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/index.yaml" {
+					_, _ = w.Write(index)
+					return
+				}
+				content, ok := tt.files[r.URL.Path]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				_, _ = w.Write([]byte(content))
+			}))
+			defer server.Close()
+
+			client := NewHelmClient()
+			if tt.verify {
+				client.EnableChecksumVerification(server.URL + "/")
+			}
+
+			version, err := client.GetLatestChartVersion(context.Background(), server.URL, "nginx")
+			if tt.wantErr {
+				if !errors.Is(err, ErrIndexChecksum) {
+					t.Fatalf("GetLatestChartVersion() error = %v, want ErrIndexChecksum", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetLatestChartVersion() error = %v", err)
+			}
+			if version != "15.0.0" {
+				t.Errorf("GetLatestChartVersion() = %v, want 15.0.0", version)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Terraform Client Tests
+// =============================================================================
+
+func TestTerraformClient_GetLatestProviderVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      string
+		response    ProviderVersions
+		statusCode  int
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:   "successful latest version",
 			source: "hashicorp/aws",
 			response: ProviderVersions{
 				Versions: []ProviderVersion{
@@ -1271,3 +1974,717 @@ func TestTerraformClient_InvalidJSON(t *testing.T) {
 		t.Error("Expected error for invalid JSON")
 	}
 }
+
+// =============================================================================
+// Galaxy Client Tests
+// =============================================================================
+
+func TestGalaxyClient_GetCollectionVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wantPath := "/api/v3/plugin/ansible/content/published/collections/index/community/general/versions/"
+		if r.URL.Path != wantPath {
+			t.Errorf("request path = %q, want %q", r.URL.Path, wantPath)
+		}
+
+		w.WriteHeader(http.StatusOK)
+		if r.URL.Query().Get("offset") == "" {
+			_, _ = w.Write([]byte(`{"links":{"next":"` + wantPath + `?limit=100&offset=2"},"data":[{"version":"8.1.0"},{"version":"8.0.0"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"links":{"next":null},"data":[{"version":"7.5.0"}]}`))
+	}))
+	defer server.Close()
+
+	client := &GalaxyClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	versions, err := client.GetCollectionVersions(context.Background(), "community.general")
+	if err != nil {
+		t.Fatalf("GetCollectionVersions() error = %v", err)
+	}
+
+	if len(versions) != 3 {
+		t.Errorf("GetCollectionVersions() returned %d versions, want 3", len(versions))
+	}
+
+	if _, err := client.GetCollectionVersions(context.Background(), "invalid"); err == nil {
+		t.Error("GetCollectionVersions() with invalid name should return error")
+	}
+}
+
+func TestGalaxyClient_GetRoleVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/roles/":
+			if r.URL.Query().Get("github_user") != "geerlingguy" || r.URL.Query().Get("name") != "docker" {
+				_, _ = w.Write([]byte(`{"results":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"results":[{"id":42}]}`))
+		case "/api/v1/roles/42/versions/":
+			_, _ = w.Write([]byte(`{"next":null,"results":[{"name":"6.1.0"},{"name":"7.0.0"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &GalaxyClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	versions, err := client.GetRoleVersions(context.Background(), "geerlingguy.docker")
+	if err != nil {
+		t.Fatalf("GetRoleVersions() error = %v", err)
+	}
+
+	if len(versions) != 2 || versions[1].Name != "7.0.0" {
+		t.Errorf("GetRoleVersions() = %v, want [6.1.0 7.0.0]", versions)
+	}
+
+	if _, err := client.GetRoleVersions(context.Background(), "unknown.role"); err == nil {
+		t.Error("GetRoleVersions() for unknown role should return error")
+	}
+}
+
+func TestLatestStableVersion(t *testing.T) {
+	got, err := LatestStableVersion([]string{"1.0.0", "2.1.0", "3.0.0-beta.1", "not-a-version", "2.0.5"})
+	if err != nil {
+		t.Fatalf("LatestStableVersion() error = %v", err)
+	}
+	if got != "2.1.0" {
+		t.Errorf("LatestStableVersion() = %q, want %q", got, "2.1.0")
+	}
+
+	if _, err := LatestStableVersion([]string{"1.0.0-rc.1"}); err == nil {
+		t.Error("LatestStableVersion() with only prereleases should return error")
+	}
+}
+
+// =============================================================================
+// CocoaPods Client Tests
+// =============================================================================
+
+func TestCocoaPodsClient_GetVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/all_pods_versions_d_a_2.txt":
+			_, _ = w.Write([]byte("AlamofireImage/4.0.0\nAlamofire/5.6.0/5.9.1/5.10.0-beta.1\n"))
+		case "/Specs/d/a/2/Alamofire/5.9.1/Alamofire.podspec.json":
+			_, _ = w.Write([]byte(`{"name":"Alamofire","version":"5.9.1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &CocoaPodsClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	versions, err := client.GetVersions(context.Background(), "Alamofire/Core")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if len(versions) != 3 || versions[1] != "5.9.1" {
+		t.Errorf("GetVersions() = %v, want [5.6.0 5.9.1 5.10.0-beta.1]", versions)
+	}
+
+	latest, err := client.GetLatestVersion(context.Background(), "Alamofire")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "5.9.1" {
+		t.Errorf("GetLatestVersion() = %q, want %q", latest, "5.9.1")
+	}
+
+	spec, err := client.GetPodspec(context.Background(), "Alamofire", "5.9.1")
+	if err != nil {
+		t.Fatalf("GetPodspec() error = %v", err)
+	}
+	if !strings.Contains(string(spec), `"version":"5.9.1"`) {
+		t.Errorf("GetPodspec() = %s", spec)
+	}
+
+	if _, err := client.GetVersions(context.Background(), "NoSuchPod"); err == nil {
+		t.Error("GetVersions() for unknown pod should return error")
+	}
+}
+
+// =============================================================================
+// GitLab Client Tests
+// =============================================================================
+
+func TestGitLabClient_GetCommitSHA(t *testing.T) {
+	const sha = "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/projects/group%2Fproject":
+			_, _ = w.Write([]byte(`{"default_branch":"main"}`))
+		case "/projects/group%2Fproject/repository/commits/main":
+			_, _ = w.Write([]byte(`{"id":"` + sha + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &GitLabClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	for _, ref := range []string{"main", "", "HEAD"} {
+		got, err := client.GetCommitSHA(context.Background(), "group/project", ref)
+		if err != nil {
+			t.Fatalf("GetCommitSHA(%q) error = %v", ref, err)
+		}
+		if got != sha {
+			t.Errorf("GetCommitSHA(%q) = %q, want %q", ref, got, sha)
+		}
+	}
+
+	if _, err := client.GetCommitSHA(context.Background(), "group/project", "missing"); err == nil {
+		t.Error("GetCommitSHA() for unknown ref should return error")
+	}
+}
+
+func TestNewGitLabClient(t *testing.T) {
+	if got := NewGitLabClient("", "").baseURL; got != gitlabAPIURL {
+		t.Errorf("NewGitLabClient() baseURL = %q, want %q", got, gitlabAPIURL)
+	}
+	if got := NewGitLabClient("gitlab.example.com", "").baseURL; got != "https://gitlab.example.com/api/v4" {
+		t.Errorf("NewGitLabClient(self-hosted) baseURL = %q", got)
+	}
+}
+
+func TestGitLabClient_GetTags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/projects/group%2Fsub%2Fci-templates/repository/tags" {
+			t.Errorf("request path = %q", r.URL.EscapedPath())
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			t.Errorf("PRIVATE-TOKEN = %q, want secret", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		_, _ = w.Write([]byte(`[{"name": "v1.3.0"}, {"name": "v1.2.0"}]`))
+	}))
+	defer server.Close()
+
+	client := &GitLabClient{client: server.Client(), baseURL: server.URL, token: "secret"}
+	tags, err := client.GetTags(context.Background(), "group/sub/ci-templates")
+	if err != nil {
+		t.Fatalf("GetTags() error = %v", err)
+	}
+	if strings.Join(tags, ",") != "v1.3.0,v1.2.0" {
+		t.Errorf("GetTags() = %v, want [v1.3.0 v1.2.0]", tags)
+	}
+}
+
+// =============================================================================
+// Crates Client Tests
+// =============================================================================
+
+func TestCratesClient_GetLatestVersion_Mock(t *testing.T) {
+	tests := []struct {
+		name        string
+		crate       string
+		response    CrateInfo
+		statusCode  int
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name:  "max stable version",
+			crate: "serde",
+			response: CrateInfo{
+				Crate:    CrateSummary{Name: "serde", MaxStableVersion: "1.0.210", MaxVersion: "1.0.211-beta.1"},
+				Versions: []CrateVersion{{Num: "1.0.211-beta.1"}, {Num: "1.0.210"}},
+			},
+			statusCode:  http.StatusOK,
+			wantVersion: "1.0.210",
+		},
+		{
+			name:  "highest non-yanked version without max_stable_version",
+			crate: "tokio",
+			response: CrateInfo{
+				Crate: CrateSummary{Name: "tokio"},
+				Versions: []CrateVersion{
+					{Num: "1.41.0", Yanked: true},
+					{Num: "1.40.0"},
+					{Num: "1.42.0-rc.1"},
+				},
+			},
+			statusCode:  http.StatusOK,
+			wantVersion: "1.40.0",
+		},
+		{
+			name:       "crate not found",
+			crate:      "nonexistent-crate-xyz",
+			statusCode: http.StatusNotFound,
+			wantErr:    true,
+		},
+		{
+			name:  "only yanked versions",
+			crate: "yanked",
+			response: CrateInfo{
+				Crate:    CrateSummary{Name: "yanked"},
+				Versions: []CrateVersion{{Num: "0.1.0", Yanked: true}},
+			},
+			statusCode: http.StatusOK,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/crates/"+tt.crate {
+					t.Errorf("request path = %q, want /crates/%s", r.URL.Path, tt.crate)
+				}
+				if r.Header.Get("User-Agent") != cratesUserAgent {
+					t.Errorf("User-Agent = %q, want %q", r.Header.Get("User-Agent"), cratesUserAgent)
+				}
+				w.WriteHeader(tt.statusCode)
+				if tt.statusCode == http.StatusOK {
+					_ = json.NewEncoder(w).Encode(tt.response)
+				}
+			}))
+			defer server.Close()
+
+			client := &CratesClient{
+				client:  &http.Client{Timeout: 5 * time.Second},
+				baseURL: server.URL,
+			}
+
+			ctx := context.Background()
+			version, err := client.GetLatestVersion(ctx, tt.crate)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetLatestVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !tt.wantErr && version != tt.wantVersion {
+				t.Errorf("GetLatestVersion() = %v, want %v", version, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestCratesClient_GetVersions_Mock(t *testing.T) {
+	response := CrateInfo{
+		Crate: CrateSummary{Name: "serde"},
+		Versions: []CrateVersion{
+			{Num: "1.0.210"},
+			{Num: "1.0.209", Yanked: true},
+			{Num: "1.0.208"},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := &CratesClient{
+		client:  &http.Client{Timeout: 5 * time.Second},
+		baseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	versions, err := client.GetVersions(ctx, "serde")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+
+	if len(versions) != 2 || versions[0] != "1.0.210" || versions[1] != "1.0.208" {
+		t.Errorf("GetVersions() = %v, want [1.0.210 1.0.208] (yanked excluded)", versions)
+	}
+}
+
+func TestCratesClient_GetCrateInfo_InvalidJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("not json"))
+	}))
+	defer server.Close()
+
+	client := &CratesClient{
+		client:  &http.Client{Timeout: 5 * time.Second},
+		baseURL: server.URL,
+	}
+
+	if _, err := client.GetCrateInfo(context.Background(), "serde"); err == nil {
+		t.Error("GetCrateInfo() expected error for invalid JSON")
+	}
+}
+
+func TestNewCratesClient(t *testing.T) {
+	client := NewCratesClient()
+
+	if client.baseURL != cratesIOURL {
+		t.Errorf("NewCratesClient() baseURL = %v, want %v", client.baseURL, cratesIOURL)
+	}
+
+	if client.client == nil {
+		t.Error("NewCratesClient() http client is nil")
+	}
+}
+
+// =============================================================================
+// NuGet Client Tests
+// =============================================================================
+
+func TestNuGetClient_GetVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/newtonsoft.json/index.json":
+			_, _ = w.Write([]byte(`{"versions":["12.0.3","13.0.1","13.0.3","14.0.1-beta1"]}`))
+		case "/broken/index.json":
+			_, _ = w.Write([]byte(`{"versions":`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &NuGetClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	// Package IDs are lowercased for the flat container
+	versions, err := client.GetVersions(context.Background(), "Newtonsoft.Json")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if len(versions) != 4 || versions[2] != "13.0.3" {
+		t.Errorf("GetVersions() = %v, want [12.0.3 13.0.1 13.0.3 14.0.1-beta1]", versions)
+	}
+
+	latest, err := client.GetLatestVersion(context.Background(), "Newtonsoft.Json")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "13.0.3" {
+		t.Errorf("GetLatestVersion() = %q, want %q", latest, "13.0.3")
+	}
+
+	if _, err := client.GetVersions(context.Background(), "No.Such.Package"); err == nil || !strings.Contains(err.Error(), "package not found") {
+		t.Errorf("GetVersions() for unknown package error = %v, want package not found", err)
+	}
+	if _, err := client.GetVersions(context.Background(), "broken"); err == nil {
+		t.Error("GetVersions() with malformed index should return error")
+	}
+}
+
+// =============================================================================
+// Maven Client Tests
+// =============================================================================
+
+func TestMavenClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/solrsearch/select" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("q") != `g:"org.apache.commons" AND a:"commons-lang3"` {
+			_, _ = w.Write([]byte(`{"response":{"numFound":0,"docs":[]}}`))
+			return
+		}
+		if q.Get("core") == "gav" {
+			_, _ = w.Write([]byte(`{"response":{"numFound":2,"docs":[{"v":"3.14.0"},{"v":"3.12.0"}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"response":{"numFound":1,"docs":[{"latestVersion":"3.14.0"}]}}`))
+	}))
+	defer server.Close()
+
+	client := &MavenClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	latest, err := client.GetLatestVersion(context.Background(), "org.apache.commons:commons-lang3")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "3.14.0" {
+		t.Errorf("GetLatestVersion() = %q, want %q", latest, "3.14.0")
+	}
+
+	versions, err := client.GetVersions(context.Background(), "org.apache.commons:commons-lang3")
+	if err != nil {
+		t.Fatalf("GetVersions() error = %v", err)
+	}
+	if len(versions) != 2 || versions[0] != "3.14.0" {
+		t.Errorf("GetVersions() = %v, want [3.14.0 3.12.0]", versions)
+	}
+
+	if _, err := client.GetLatestVersion(context.Background(), "org.example:missing"); err == nil {
+		t.Error("GetLatestVersion() for unknown artifact should return error")
+	}
+	if _, err := client.GetVersions(context.Background(), "commons-lang3"); err == nil {
+		t.Error("GetVersions() without a group should return error")
+	}
+}
+
+// =============================================================================
+// Packagist Client Tests
+// =============================================================================
+
+func TestPackagistClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/p2/monolog/monolog.json":
+			_, _ = w.Write([]byte(`{"packages":{"monolog/monolog":[
+				{"version":"3.6.0","version_normalized":"3.6.0.0","time":"2024-04-12T21:02:21+00:00","require":{"php":">=8.1"}},
+				{"version":"3.5.0","version_normalized":"3.5.0.0","time":"2023-10-27T15:32:31+00:00"},
+				{"version":"3.0.0-RC1","version_normalized":"3.0.0.0-RC1"},
+				{"version":"2.9.1","version_normalized":"2.9.1.0","time":"2023-02-06T13:44:46+00:00"}
+			]},"minified":"composer/2.0"}`))
+		case "/p2/broken/broken.json":
+			_, _ = w.Write([]byte(`{"packages":`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &PackagistClient{
+		client:  server.Client(),
+		baseURL: server.URL,
+	}
+
+	// Package names are lowercased
+	releases, err := client.GetReleases(context.Background(), "Monolog/Monolog")
+	if err != nil {
+		t.Fatalf("GetReleases() error = %v", err)
+	}
+	if len(releases) != 4 || releases[0].Version != "3.6.0" {
+		t.Fatalf("GetReleases() = %+v, want 4 releases, newest 3.6.0", releases)
+	}
+	// Minified entries inherit omitted fields from the previous release
+	if releases[2].Time != "2023-10-27T15:32:31+00:00" {
+		t.Errorf("GetReleases()[2].Time = %q, want it inherited from 3.5.0", releases[2].Time)
+	}
+
+	latest, err := client.GetLatestVersion(context.Background(), "monolog/monolog")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "3.6.0" {
+		t.Errorf("GetLatestVersion() = %q, want %q", latest, "3.6.0")
+	}
+
+	if _, err := client.GetVersions(context.Background(), "acme/missing"); err == nil || !strings.Contains(err.Error(), "package not found") {
+		t.Errorf("GetVersions() for unknown package error = %v, want package not found", err)
+	}
+	if _, err := client.GetVersions(context.Background(), "broken/broken"); err == nil {
+		t.Error("GetVersions() with malformed metadata should return error")
+	}
+	if _, err := client.GetVersions(context.Background(), "no-vendor"); err == nil {
+		t.Error("GetVersions() without a vendor should return error")
+	}
+}
+
+// =============================================================================
+// Hex Client Tests
+// =============================================================================
+
+// protoField encodes a length-delimited protobuf field.
+func protoField(field int, value []byte) []byte {
+	b := binary.AppendUvarint(nil, uint64(field<<3|2))
+	b = binary.AppendUvarint(b, uint64(len(value)))
+	return append(b, value...)
+}
+
+// protoVarint encodes a varint protobuf field.
+func protoVarint(field int, n uint64) []byte {
+	return binary.AppendUvarint(binary.AppendUvarint(nil, uint64(field<<3)), n)
+}
+
+func TestHexClient(t *testing.T) {
+	inner, _ := hex.DecodeString("a1b2")
+	outer, _ := hex.DecodeString("c3d4")
+	dependency := append(append(protoField(1, []byte("plug")), protoField(2, []byte("~> 1.14"))...), protoVarint(3, 1)...)
+	release := bytes.Join([][]byte{
+		protoField(1, []byte("1.7.12")),
+		protoField(2, inner),
+		protoField(3, dependency),
+		protoField(5, outer),
+	}, nil)
+	pkg := append(append(protoField(1, protoField(1, []byte("1.7.10"))), protoField(1, release)...), protoField(2, []byte("phoenix"))...)
+	signed := append(protoField(1, pkg), protoField(2, []byte("signature"))...)
+
+	var registryBody bytes.Buffer
+	gz := gzip.NewWriter(&registryBody)
+	_, _ = gz.Write(signed)
+	_ = gz.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/packages/phoenix":
+			_, _ = w.Write([]byte(`{"name":"phoenix","releases":[
+				{"version":"1.8.0-rc.0","inserted_at":"2024-05-01T10:00:00.000000Z"},
+				{"version":"1.7.12","inserted_at":"2024-04-22T11:20:55.000000Z"},
+				{"version":"1.7.10","inserted_at":"2023-11-03T18:59:44.000000Z"}
+			]}`))
+		case "/repo/packages/phoenix":
+			_, _ = w.Write(registryBody.Bytes())
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &HexClient{
+		client:  server.Client(),
+		baseURL: server.URL + "/api",
+		repoURL: server.URL + "/repo",
+	}
+	ctx := context.Background()
+
+	releases, err := client.GetReleases(ctx, "phoenix")
+	if err != nil {
+		t.Fatalf("GetReleases() error = %v", err)
+	}
+	if len(releases) != 3 || releases[1].InsertedAt != "2024-04-22T11:20:55.000000Z" {
+		t.Errorf("GetReleases() = %+v, want 3 releases with insertion times", releases)
+	}
+
+	latest, err := client.GetLatestVersion(ctx, "phoenix")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "1.7.12" {
+		t.Errorf("GetLatestVersion() = %q, want %q", latest, "1.7.12")
+	}
+
+	rel, err := client.GetRegistryRelease(ctx, "phoenix", "1.7.12")
+	if err != nil {
+		t.Fatalf("GetRegistryRelease() error = %v", err)
+	}
+	if rel.InnerChecksum != "a1b2" || rel.OuterChecksum != "c3d4" {
+		t.Errorf("GetRegistryRelease() checksums = %q/%q, want a1b2/c3d4", rel.InnerChecksum, rel.OuterChecksum)
+	}
+	if len(rel.Dependencies) != 1 || rel.Dependencies[0] != (HexDependency{Package: "plug", Requirement: "~> 1.14", Optional: true}) {
+		t.Errorf("GetRegistryRelease() dependencies = %+v", rel.Dependencies)
+	}
+
+	if _, err := client.GetRegistryRelease(ctx, "phoenix", "9.9.9"); err == nil || !strings.Contains(err.Error(), "release not found") {
+		t.Errorf("GetRegistryRelease() for unknown version error = %v, want release not found", err)
+	}
+	if _, err := client.GetVersions(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "package not found") {
+		t.Errorf("GetVersions() for unknown package error = %v, want package not found", err)
+	}
+	if _, err := decodeHexRegistry(protoField(1, []byte{0x0a, 0x05, 'x'})); err == nil {
+		t.Error("decodeHexRegistry() with a truncated release should return error")
+	}
+}
+
+// =============================================================================
+// RubyGems Client Tests
+// =============================================================================
+
+func TestRubyGemsClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/versions/nokogiri.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"number":"1.17.0.rc1","platform":"ruby","prerelease":true,"created_at":"2024-11-20T10:00:00.000Z"},
+			{"number":"1.16.0","platform":"x86_64-linux","prerelease":false,"created_at":"2023-12-27T18:00:01.000Z"},
+			{"number":"1.16.0","platform":"ruby","prerelease":false,"created_at":"2023-12-27T18:00:00.000Z"},
+			{"number":"1.15.5","platform":"ruby","prerelease":false,"created_at":"2023-11-17T16:00:00.000Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := &RubyGemsClient{client: server.Client(), baseURL: server.URL}
+	ctx := context.Background()
+
+	releases, err := client.GetReleases(ctx, "nokogiri")
+	if err != nil {
+		t.Fatalf("GetReleases() error = %v", err)
+	}
+	// Platform builds of a version are listed once
+	if len(releases) != 3 || releases[1].CreatedAt != "2023-12-27T18:00:01.000Z" {
+		t.Errorf("GetReleases() = %+v, want 3 releases with creation times", releases)
+	}
+
+	latest, err := client.GetLatestVersion(ctx, "nokogiri")
+	if err != nil {
+		t.Fatalf("GetLatestVersion() error = %v", err)
+	}
+	if latest != "1.16.0" {
+		t.Errorf("GetLatestVersion() = %q, want %q", latest, "1.16.0")
+	}
+
+	if _, err := client.GetVersions(ctx, "missing"); err == nil || !strings.Contains(err.Error(), "gem not found") {
+		t.Errorf("GetVersions() for unknown gem error = %v, want gem not found", err)
+	}
+}
+
+// =============================================================================
+// Network Stats Tests
+// =============================================================================
+
+func TestNetworkStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{ //nolint:errcheck // test server
+			"name":      "lodash",
+			"dist-tags": map[string]string{"latest": "4.17.21"},
+			"versions":  map[string]interface{}{"4.17.21": map[string]interface{}{}},
+		})
+	}))
+	defer server.Close()
+
+	ResetNetworkStats()
+	defer ResetNetworkStats()
+
+	client := &NPMClient{client: NewHTTPClient("npm-stats"), baseURL: server.URL}
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := client.GetLatestVersion(ctx, "lodash"); err != nil {
+			t.Fatalf("GetLatestVersion() error = %v", err)
+		}
+	}
+
+	stats := NetworkStats()["npm-stats"]
+	if stats.Requests != 3 {
+		t.Errorf("Requests = %d, want 3", stats.Requests)
+	}
+	if stats.Errors != 0 {
+		t.Errorf("Errors = %d, want 0", stats.Errors)
+	}
+	if stats.Duration <= 0 {
+		t.Errorf("Duration = %v, want > 0", stats.Duration)
+	}
+
+	// Transport failures are counted as errors
+	server.Close()
+	if _, err := client.GetLatestVersion(ctx, "lodash"); err == nil {
+		t.Fatal("GetLatestVersion() expected error after server close")
+	}
+
+	stats = NetworkStats()["npm-stats"]
+	if stats.Requests != 4 || stats.Errors != 1 {
+		t.Errorf("after failure: Requests = %d, Errors = %d; want 4, 1", stats.Requests, stats.Errors)
+	}
+
+	ResetNetworkStats()
+	if len(NetworkStats()) != 0 {
+		t.Error("ResetNetworkStats() did not clear counters")
+	}
+}
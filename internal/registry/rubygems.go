@@ -0,0 +1,143 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const rubyGemsURL = "https://rubygems.org"
+
+// RubyGemsClient queries the RubyGems.org API.
+type RubyGemsClient struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewRubyGemsClient creates a new RubyGems.org client.
+func NewRubyGemsClient() *RubyGemsClient {
+	return &RubyGemsClient{
+		client:  NewHTTPClient("rubygems"),
+		baseURL: rubyGemsURL,
+	}
+}
+
+// GemVersion is a published version of a gem.
+type GemVersion struct {
+	Number     string `json:"number"`
+	Platform   string `json:"platform"`
+	CreatedAt  string `json:"created_at"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// GetReleases fetches the versions of a gem, newest first. A version built
+// for several platforms is listed once.
+func (c *RubyGemsClient) GetReleases(ctx context.Context, gem string) ([]GemVersion, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/versions/%s.json", c.baseURL, url.PathEscape(gem))
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch gem versions: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }() //nolint:errcheck // HTTP cleanup best effort
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("gem not found: %s", gem)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	var all []GemVersion
+	if err := json.Unmarshal(body, &all); err != nil {
+		return nil, fmt.Errorf("parse gem versions: %w", err)
+	}
+
+	seen := make(map[string]bool, len(all))
+	releases := make([]GemVersion, 0, len(all))
+	for _, v := range all {
+		if seen[v.Number] {
+			continue
+		}
+		seen[v.Number] = true
+		releases = append(releases, v)
+	}
+	return releases, nil
+}
+
+// GetVersions fetches all published versions of a gem.
+func (c *RubyGemsClient) GetVersions(ctx context.Context, gem string) ([]string, error) {
+	releases, err := c.GetReleases(ctx, gem)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(releases))
+	for _, r := range releases {
+		versions = append(versions, r.Number)
+	}
+	return versions, nil
+}
+
+// GetLatestVersion returns the highest stable version of a gem.
+func (c *RubyGemsClient) GetLatestVersion(ctx context.Context, gem string) (string, error) {
+	releases, err := c.GetReleases(ctx, gem)
+	if err != nil {
+		return "", err
+	}
+
+	var latest *semver.Version
+	for _, r := range releases {
+		if r.Prerelease {
+			continue
+		}
+		parsed, err := semver.NewVersion(r.Number)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest = parsed
+		}
+	}
+
+	if latest == nil {
+		return "", fmt.Errorf("no stable versions found for gem: %s", gem)
+	}
+
+	return latest.Original(), nil
+}
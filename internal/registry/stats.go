@@ -0,0 +1,104 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package registry
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultTimeout bounds every registry request.
+const defaultTimeout = 30 * time.Second
+
+// ClientStats summarizes the HTTP traffic of one registry client.
+type ClientStats struct {
+	Requests int           `json:"requests"`
+	Errors   int           `json:"errors"`
+	Duration time.Duration `json:"duration"`
+}
+
+var (
+	networkStats   = make(map[string]*ClientStats)
+	networkStatsMu sync.Mutex
+)
+
+// countingTransport records request counts and latency per client name.
+type countingTransport struct {
+	base http.RoundTripper
+	name string
+}
+
+// RoundTrip performs the request with the base transport and records its outcome.
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	networkStatsMu.Lock()
+	defer networkStatsMu.Unlock()
+
+	stats, ok := networkStats[t.name]
+	if !ok {
+		stats = &ClientStats{}
+		networkStats[t.name] = stats
+	}
+	stats.Requests++
+	stats.Duration += elapsed
+	if err != nil {
+		stats.Errors++
+	}
+
+	return resp, err
+}
+
+// NewHTTPClient returns an HTTP client whose requests are counted under name
+// in NetworkStats. Registry clients and datasources calling HTTP APIs directly
+// share it so per-run network statistics cover all traffic. Responses served
+// from the HTTP cache (see SetHTTPCache) are not counted as requests.
+func NewHTTPClient(name string) *http.Client {
+	return &http.Client{
+		Timeout: defaultTimeout,
+		Transport: &cachingTransport{
+			base: &countingTransport{base: http.DefaultTransport, name: name},
+		},
+	}
+}
+
+// NetworkStats returns a snapshot of the HTTP requests made by each registry
+// client since the process started or the last ResetNetworkStats.
+func NetworkStats() map[string]ClientStats {
+	networkStatsMu.Lock()
+	defer networkStatsMu.Unlock()
+
+	snapshot := make(map[string]ClientStats, len(networkStats))
+	for name, stats := range networkStats {
+		snapshot[name] = *stats
+	}
+	return snapshot
+}
+
+// ResetNetworkStats clears all recorded request counters.
+func ResetNetworkStats() {
+	networkStatsMu.Lock()
+	defer networkStatsMu.Unlock()
+	networkStats = make(map[string]*ClientStats)
+}
@@ -27,7 +27,6 @@ import (
 	"io"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/Masterminds/semver/v3"
 )
@@ -43,9 +42,7 @@ type TerraformClient struct {
 // NewTerraformClient creates a new Terraform Registry client.
 func NewTerraformClient() *TerraformClient {
 	return &TerraformClient{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		client:  NewHTTPClient("terraform"),
 		baseURL: terraformRegistryURL,
 	}
 }
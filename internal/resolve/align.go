@@ -0,0 +1,108 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package resolve
+
+import "github.com/santosr2/uptool/internal/engine"
+
+// AlignTargets moves plans that share dependencies to a common version: for
+// every dependency declared in at least two of members, each member is
+// proposed the highest target planned for it in any member. A raised target
+// must still be selectable for the member under planCtx (manifest
+// constraint, update level, pre-release settings), otherwise the member keeps
+// the target it planned itself. It runs before policy filters, so ignore
+// rules and cooldown also apply to aligned targets.
+//
+// skip, when non-nil, reports dependencies a member never updates. group is
+// set on aligned updates that have no group yet. Updates added for a member
+// take their policy source from planCtx and carry no changelog URL, since
+// the update they were aligned to may come from another source.
+func AlignTargets(members []*engine.UpdatePlan, planCtx *engine.PlanContext, group string, skip func(*engine.Manifest, *engine.Dependency) bool) {
+	skipped := func(m *engine.Manifest, dep *engine.Dependency) bool {
+		return skip != nil && skip(m, dep)
+	}
+
+	// Highest planned update per dependency across members
+	highest := make(map[string]engine.Update)
+	for _, p := range members {
+		for idx := range p.Updates {
+			u := p.Updates[idx]
+			best, ok := highest[u.Dependency.Name]
+			if !ok {
+				highest[u.Dependency.Name] = u
+				continue
+			}
+			if cmp, err := CompareVersions(u.TargetVersion, best.TargetVersion); err == nil && cmp > 0 {
+				highest[u.Dependency.Name] = u
+			}
+		}
+	}
+
+	occurrences := make(map[string]int)
+	for _, p := range members {
+		for idx := range p.Manifest.Dependencies {
+			if dep := &p.Manifest.Dependencies[idx]; !skipped(p.Manifest, dep) {
+				occurrences[dep.Name]++
+			}
+		}
+	}
+
+	for _, p := range members {
+		planned := make(map[string]int, len(p.Updates))
+		for idx := range p.Updates {
+			planned[p.Updates[idx].Dependency.Name] = idx
+		}
+
+		for _, dep := range p.Manifest.Dependencies {
+			best, ok := highest[dep.Name]
+			if !ok || occurrences[dep.Name] < 2 || skipped(p.Manifest, &dep) {
+				continue
+			}
+			// A digest pin can only move with the digest of the new target
+			if dep.Digest != "" && best.TargetDigest == "" {
+				continue
+			}
+
+			target, impact, err := SelectVersionWithContext(dep.CurrentVersion, dep.Constraint, []string{best.TargetVersion}, planCtx)
+			if err != nil || target != best.TargetVersion || impact == engine.ImpactNone {
+				continue
+			}
+
+			if idx, ok := planned[dep.Name]; ok {
+				u := &p.Updates[idx]
+				u.TargetVersion = best.TargetVersion
+				u.TargetDigest = best.TargetDigest
+				u.Impact = string(impact)
+				if u.Group == "" {
+					u.Group = group
+				}
+				continue
+			}
+
+			p.Updates = append(p.Updates, engine.Update{
+				Dependency:    dep,
+				TargetVersion: best.TargetVersion,
+				TargetDigest:  best.TargetDigest,
+				Impact:        string(impact),
+				PolicySource:  planCtx.GetPolicySource(),
+				Group:         group,
+			})
+		}
+	}
+}
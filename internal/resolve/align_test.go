@@ -0,0 +1,106 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package resolve
+
+import (
+	"testing"
+
+	"github.com/santosr2/uptool/internal/engine"
+)
+
+func TestAlignTargets(t *testing.T) {
+	member := func(path, current, target, group string) *engine.UpdatePlan {
+		dep := engine.Dependency{Name: "lib", CurrentVersion: current}
+		plan := &engine.UpdatePlan{Manifest: &engine.Manifest{Path: path, Dependencies: []engine.Dependency{dep}}}
+		if target != "" {
+			plan.Updates = []engine.Update{{
+				Dependency:    dep,
+				TargetVersion: target,
+				Group:         group,
+				ChangelogURL:  "https://example.com/" + path,
+				PolicySource:  engine.PolicySourceUptoolYAML,
+			}}
+		}
+		return plan
+	}
+
+	tests := []struct {
+		planCtx *engine.PlanContext
+		want    map[string]string
+		name    string
+	}{
+		{
+			name: "raises every member to the highest target",
+			want: map[string]string{"a": "1.3.0", "b": "1.3.0", "c": "1.3.0"},
+		},
+		{
+			name:    "keeps members within the update level",
+			planCtx: engine.NewPlanContext().WithCLIFlags(&engine.CLIFlags{UpdateLevel: "patch"}),
+			want:    map[string]string{"a": "1.3.0", "b": "1.2.1", "c": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := []*engine.UpdatePlan{
+				member("a", "1.2.0", "1.3.0", ""),
+				member("b", "1.2.0", "1.2.1", "user-group"),
+				member("c", "1.0.0", "", ""),
+			}
+
+			AlignTargets(members, tt.planCtx, "family", nil)
+
+			for _, p := range members {
+				got := ""
+				if len(p.Updates) > 0 {
+					got = p.Updates[0].TargetVersion
+				}
+				if got != tt.want[p.Manifest.Path] {
+					t.Errorf("%s target = %q, want %q", p.Manifest.Path, got, tt.want[p.Manifest.Path])
+				}
+			}
+			if g := members[1].Updates[0].Group; g != "user-group" {
+				t.Errorf("b group = %q, want the existing group kept", g)
+			}
+			if len(members[2].Updates) > 0 {
+				added := members[2].Updates[0]
+				if added.PolicySource != tt.planCtx.GetPolicySource() || added.ChangelogURL != "" {
+					t.Errorf("c update = %+v, want its own policy source and no changelog URL", added)
+				}
+			}
+		})
+	}
+}
+
+func TestAlignTargets_DigestPin(t *testing.T) {
+	pinned := engine.Dependency{Name: "nginx", CurrentVersion: "1.25.0", Digest: "sha256:old"}
+	tagged := engine.Dependency{Name: "nginx", CurrentVersion: "1.25.0"}
+	members := []*engine.UpdatePlan{
+		{Manifest: &engine.Manifest{Path: "base", Dependencies: []engine.Dependency{tagged}},
+			Updates: []engine.Update{{Dependency: tagged, TargetVersion: "1.27.0"}}},
+		{Manifest: &engine.Manifest{Path: "overlay", Dependencies: []engine.Dependency{pinned}}},
+	}
+
+	AlignTargets(members, nil, "", nil)
+
+	if len(members[1].Updates) != 0 {
+		t.Errorf("digest-pinned member updates = %+v, want none without a target digest", members[1].Updates)
+	}
+}
@@ -65,6 +65,9 @@ const (
 	// ConstraintRange allows versions within a range (e.g., ">= 1.0, < 2.0").
 	ConstraintRange ConstraintType = "range"
 
+	// ConstraintUnion allows versions matching any of several OR'd ranges (e.g., "^1.0.0 || ^2.0.0").
+	ConstraintUnion ConstraintType = "union"
+
 	// updateLevelMajor is the default update level that allows all updates.
 	updateLevelMajor = "major"
 )
@@ -101,6 +104,10 @@ func ParseConstraint(constraint string) *ParsedConstraint {
 		}
 	}
 
+	if strings.Contains(constraint, "||") {
+		return parseUnionConstraint(constraint)
+	}
+
 	result := &ParsedConstraint{
 		Original: constraint,
 	}
@@ -160,6 +167,38 @@ func ParseConstraint(constraint string) *ParsedConstraint {
 	return result
 }
 
+// parseUnionConstraint parses OR'd ranges such as "^1.0.0 || ^2.0.0".
+// A version is allowed if any branch allows it. The base version is the highest
+// branch base and the max impact is the widest of all branches.
+func parseUnionConstraint(constraint string) *ParsedConstraint {
+	result := &ParsedConstraint{
+		Original:         constraint,
+		Type:             ConstraintUnion,
+		MaxAllowedImpact: engine.ImpactNone,
+	}
+
+	var highest *semver.Version
+	for _, branch := range strings.Split(constraint, "||") {
+		parsed := ParseConstraint(branch)
+
+		if !result.AllowsImpact(parsed.MaxAllowedImpact) {
+			result.MaxAllowedImpact = parsed.MaxAllowedImpact
+		}
+
+		base, err := normalizeAndParse(parsed.BaseVersion)
+		if err != nil {
+			continue
+		}
+		if highest == nil || base.GreaterThan(highest) {
+			highest = base
+			result.BaseVersion = parsed.BaseVersion
+		}
+	}
+
+	result.Constraint, _ = semver.NewConstraint(constraint) //nolint:errcheck // nil constraint is handled
+	return result
+}
+
 // computePessimisticImpact determines the max impact for Terraform's ~> constraint.
 // ~> 5.0 (2 parts) allows minor updates (5.x)
 // ~> 5.0.0 (3 parts) allows only patch updates (5.0.x)
@@ -294,9 +333,11 @@ func SelectVersionWithContext(
 	// Get effective policy settings
 	updateLevel := updateLevelMajor // Default: allow all
 	allowPrerelease := false
+	var prereleaseChannels []string
 	if planCtx != nil {
 		updateLevel = planCtx.EffectiveUpdateLevel()
 		allowPrerelease = planCtx.EffectiveAllowPrerelease()
+		prereleaseChannels = planCtx.EffectivePrereleaseChannels()
 	}
 
 	// Parse and filter available versions (pre-allocate for performance)
@@ -307,8 +348,8 @@ func SelectVersionWithContext(
 			continue // skip invalid versions
 		}
 
-		// Filter out prereleases if not allowed
-		if parsed.Prerelease() != "" && !allowPrerelease {
+		// Filter out prereleases if not allowed (or not in an allowed channel)
+		if !engine.PrereleaseAllowed(parsed.Prerelease(), allowPrerelease, prereleaseChannels) {
 			continue
 		}
 
@@ -352,8 +393,12 @@ func SelectVersionWithContext(
 }
 
 // stripConstraintPrefix removes constraint prefixes like ~>, ^, >=, etc.
+// For OR'd ranges (e.g., "^1.0.0 || ^2.0.0") the highest branch base is returned.
 func stripConstraintPrefix(version string) string {
 	version = strings.TrimSpace(version)
+	if strings.Contains(version, "||") {
+		return ParseConstraint(version).BaseVersion
+	}
 	version = strings.TrimPrefix(version, "~>")
 	version = strings.TrimPrefix(version, ">=")
 	version = strings.TrimPrefix(version, "<=")
@@ -508,3 +553,23 @@ func CompareVersions(v1, v2 string) (int, error) {
 
 	return ver1.Compare(ver2), nil
 }
+
+// DetermineImpact returns the semver impact of moving from current to target.
+// Constraint prefixes on current (e.g., ^, ~>) are ignored.
+func DetermineImpact(current, target string) (engine.Impact, error) {
+	cur, err := normalizeAndParse(stripConstraintPrefix(current))
+	if err != nil {
+		return engine.ImpactNone, fmt.Errorf("parse current %q: %w", current, err)
+	}
+
+	tgt, err := normalizeAndParse(target)
+	if err != nil {
+		return engine.ImpactNone, fmt.Errorf("parse target %q: %w", target, err)
+	}
+
+	if !tgt.GreaterThan(cur) {
+		return engine.ImpactNone, nil
+	}
+
+	return determineImpact(cur, tgt), nil
+}
@@ -268,6 +268,36 @@ func TestParseConstraint(t *testing.T) {
 			shouldAllowVer:   true,
 			disallowsVersion: "1.2.4",
 		},
+		{
+			name:             "npm union ^1.0.0 || ^2.0.0 allows second branch",
+			constraint:       "^1.0.0 || ^2.0.0",
+			wantType:         ConstraintUnion,
+			wantBaseVersion:  "2.0.0",
+			wantMaxImpact:    engine.ImpactMinor,
+			allowsVersion:    "2.5.0",
+			shouldAllowVer:   true,
+			disallowsVersion: "3.0.0",
+		},
+		{
+			name:             "npm union ^1.0.0 || ^2.0.0 allows first branch",
+			constraint:       "^1.0.0 || ^2.0.0",
+			wantType:         ConstraintUnion,
+			wantBaseVersion:  "2.0.0",
+			wantMaxImpact:    engine.ImpactMinor,
+			allowsVersion:    "1.9.0",
+			shouldAllowVer:   true,
+			disallowsVersion: "0.9.0",
+		},
+		{
+			name:             "npm union with exact and tilde",
+			constraint:       "1.2.3 || ~1.4.0",
+			wantType:         ConstraintUnion,
+			wantBaseVersion:  "1.4.0",
+			wantMaxImpact:    engine.ImpactPatch,
+			allowsVersion:    "1.4.2",
+			shouldAllowVer:   true,
+			disallowsVersion: "1.3.0",
+		},
 	}
 
 	for _, tt := range tests {
@@ -528,3 +558,156 @@ func TestSelectVersionWithContext_Pin(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectVersionWithContext_UnionConstraint(t *testing.T) {
+	const constraint = "^1.0.0 || ^2.0.0"
+	available := []string{"1.9.0", "2.0.0", "2.4.0", "3.0.0"}
+
+	t.Run("stays within the union", func(t *testing.T) {
+		got, impact, err := SelectVersionWithContext(constraint, constraint, available, nil)
+		if err != nil {
+			t.Fatalf("SelectVersionWithContext() error = %v", err)
+		}
+		if got != "2.4.0" {
+			t.Errorf("SelectVersionWithContext() version = %q, want %q", got, "2.4.0")
+		}
+		if impact != engine.ImpactMinor {
+			t.Errorf("SelectVersionWithContext() impact = %q, want %q", impact, engine.ImpactMinor)
+		}
+	})
+
+	t.Run("policy overrides the union", func(t *testing.T) {
+		planCtx := engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Update: "major"})
+		got, _, err := SelectVersionWithContext(constraint, constraint, available, planCtx)
+		if err != nil {
+			t.Fatalf("SelectVersionWithContext() error = %v", err)
+		}
+		if got != "3.0.0" {
+			t.Errorf("SelectVersionWithContext() version = %q, want %q", got, "3.0.0")
+		}
+	})
+}
+
+func TestSelectVersionWithContext_PrereleaseChannels(t *testing.T) {
+	available := []string{"1.0.0", "1.1.0", "2.0.0-alpha.1", "2.0.0-beta.2", "2.0.0-rc.1", "2.1.0-beta.1"}
+
+	tests := []struct {
+		name        string
+		planCtx     *engine.PlanContext
+		wantVersion string
+	}{
+		{
+			name:        "prereleases excluded by default",
+			planCtx:     engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Update: "major"}),
+			wantVersion: "1.1.0",
+		},
+		{
+			name: "rc channel allowed, beta rejected",
+			planCtx: engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+				Update:             "major",
+				PrereleaseChannels: []string{"rc"},
+			}),
+			wantVersion: "2.0.0-rc.1",
+		},
+		{
+			name: "channels restrict allow_prerelease",
+			planCtx: engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+				Update:             "major",
+				AllowPrerelease:    true,
+				PrereleaseChannels: []string{"RC"},
+			}),
+			wantVersion: "2.0.0-rc.1",
+		},
+		{
+			name: "allow_prerelease without channels takes any prerelease",
+			planCtx: engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+				Update:          "major",
+				AllowPrerelease: true,
+			}),
+			wantVersion: "2.1.0-beta.1",
+		},
+		{
+			name: "CLI channels override policy channels",
+			planCtx: engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+				Update:             "major",
+				PrereleaseChannels: []string{"rc"},
+			}).WithCLIFlags(&engine.CLIFlags{PrereleaseChannels: []string{"beta"}}),
+			wantVersion: "2.1.0-beta.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := SelectVersionWithContext("1.0.0", "", available, tt.planCtx)
+			if err != nil {
+				t.Fatalf("SelectVersionWithContext() error = %v", err)
+			}
+			if got != tt.wantVersion {
+				t.Errorf("SelectVersionWithContext() version = %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
+
+func TestSelectVersionWithContext_Segment(t *testing.T) {
+	available := []string{"1.2.3", "1.2.9", "1.3.0", "2.0.0"}
+
+	tests := []struct {
+		name        string
+		constraint  string
+		planCtx     *engine.PlanContext
+		wantVersion string
+	}{
+		{
+			name:        "patch segment stays within the current minor",
+			planCtx:     engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Segment: "patch"}),
+			wantVersion: "1.2.9",
+		},
+		{
+			name: "patch segment caps a minor policy",
+			planCtx: engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+				Update:  "minor",
+				Segment: "patch",
+			}),
+			wantVersion: "1.2.9",
+		},
+		{
+			name: "patch segment caps the CLI update level",
+			planCtx: engine.NewPlanContext().
+				WithPolicy(&engine.IntegrationPolicy{Segment: "patch"}).
+				WithCLIFlags(&engine.CLIFlags{UpdateLevel: "major"}),
+			wantVersion: "1.2.9",
+		},
+		{
+			name:        "patch segment keeps an exact manifest constraint",
+			constraint:  "=1.2.3",
+			planCtx:     engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Segment: "patch"}),
+			wantVersion: "",
+		},
+		{
+			name:        "minor segment stays within the current major",
+			planCtx:     engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{Segment: "minor"}),
+			wantVersion: "1.3.0",
+		},
+		{
+			name: "segment does not widen a stricter update level",
+			planCtx: engine.NewPlanContext().WithPolicy(&engine.IntegrationPolicy{
+				Update:  "none",
+				Segment: "minor",
+			}),
+			wantVersion: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := SelectVersionWithContext("1.2.3", tt.constraint, available, tt.planCtx)
+			if err != nil {
+				t.Fatalf("SelectVersionWithContext() error = %v", err)
+			}
+			if got != tt.wantVersion {
+				t.Errorf("SelectVersionWithContext() version = %q, want %q", got, tt.wantVersion)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package resolve
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+var (
+	// terraformClause matches one clause of a Terraform version constraint (e.g., "~> 5.0", ">= 1.2.3").
+	terraformClause = regexp.MustCompile(`^(=|!=|>|>=|<|<=|~>)?\s*\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+
+	// cocoapodsClause matches one clause of a RubyGems version requirement, as used by
+	// CocoaPods and Bundler (e.g., "~> 5.6", ">= 1.2.3").
+	cocoapodsClause = regexp.MustCompile(`^(=|!=|>|>=|<|<=|~>)?\s*\d+(\.[0-9A-Za-z]+)*(-[0-9A-Za-z.-]+)?$`)
+
+	// cargoClause matches one clause of a Cargo version requirement (e.g., "1.0", "^1.2.3", ">=1.2, <2").
+	cargoClause = regexp.MustCompile(`^(=|>|>=|<|<=|~|\^)?\s*\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+
+	// composerClause matches one clause of a Composer version constraint (e.g., "^8.1", "~2.0", ">=1.0", "2.1.*").
+	composerClause = regexp.MustCompile(`^((==|=|!=|>|>=|<|<=|~|\^)?\d+(\.\d+){0,2}(-[0-9A-Za-z.]+)?|\d+(\.\d+){0,2}\.\*)$`)
+
+	// composerOperatorSpace matches the whitespace Composer allows between an operator and its version.
+	composerOperatorSpace = regexp.MustCompile(`([<>=!~^])\s+`)
+
+	// mixClause matches one clause of a Mix version requirement (e.g., "~> 1.7", ">= 1.0.0").
+	// Only "~>" accepts a version without a patch component.
+	mixClause = regexp.MustCompile(`^(~>\s*\d+\.\d+(\.\d+)?|(==|!=|>|>=|<|<=)?\s*\d+\.\d+\.\d+)(-[0-9A-Za-z.-]+)?$`)
+
+	// mixConnective matches the "and" and "or" joining Mix requirement clauses.
+	mixConnective = regexp.MustCompile(`\s+(?:and|or)\s+`)
+
+	// nugetVersion matches a NuGet version written by uptool: exact (e.g., "13.0.3", "1.0.0.1")
+	// or floating (e.g., "1.2.*").
+	nugetVersion = regexp.MustCompile(`^(\d+(\.\d+){0,3}(-[0-9A-Za-z.-]+)?|\d+(\.\d+){0,2}\.\*)$`)
+
+	// mavenVersion matches a plain Maven version (e.g., "3.2.1", "33.0.0-jre", "6.4.4.Final").
+	mavenVersion = regexp.MustCompile(`^[0-9][0-9A-Za-z._-]*$`)
+
+	// galaxyClause matches one clause of an Ansible Galaxy version range (e.g., ">=1.0.0", "!=2.1.0").
+	galaxyClause = regexp.MustCompile(`^(==|=|!=|>|>=|<|<=)?\s*v?\d+(\.\d+){0,2}(-[0-9A-Za-z.-]+)?$`)
+
+	// pep440Clause matches one clause of a PEP 440 version specifier (e.g., ">=2.0", "~=1.4.2",
+	// "==3.1.*").
+	pep440Clause = regexp.MustCompile(`(?i)^(` + pep440Specifier + `)$`)
+
+	// poetryClause matches one clause of a Poetry version constraint: a PEP 440 clause, or a
+	// caret, tilde, bare or wildcard version (e.g., "^1.2", "~1.2.3", "1.2.*").
+	poetryClause = regexp.MustCompile(`(?i)^(` + pep440Specifier + `|(\^|~|=)?\s*` + pep440Version + `|\d+(\.\d+)*\.\*)$`)
+)
+
+const (
+	// pep440Version matches a PEP 440 version (e.g., "2.31.0", "1!2.0", "3.0rc1", "1.0.post2.dev1").
+	pep440Version = `v?(\d+!)?\d+(\.\d+)*([-_.]?(a|alpha|b|beta|c|rc|pre|preview)[-_.]?\d*)?` +
+		`([-_.]?(post|rev|r)[-_.]?\d*)?([-_.]?dev[-_.]?\d*)?(\+[a-z0-9.]+)?`
+
+	// pep440Specifier matches one PEP 440 clause. Only == and != accept a trailing wildcard,
+	// and === compares an arbitrary string.
+	pep440Specifier = `(~=|<=|>=|<|>)\s*` + pep440Version + `|(==|!=)\s*(` + pep440Version + `|\d+(\.\d+)*\.\*)|===\s*[0-9A-Za-z.*+!_-]+`
+)
+
+// ValidateConstraint checks that s is a well-formed version or constraint for the
+// given ecosystem (integration name). Integrations call it on every generated
+// string before writing a manifest so a bad target never produces a corrupt file.
+//
+// Ecosystems whose references are free-form (e.g., docker tags, action refs)
+// are not validated and always return nil.
+func ValidateConstraint(ecosystem, s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("empty %s constraint", ecosystem)
+	}
+
+	var err error
+	switch ecosystem {
+	case "npm", "helm":
+		// npm and Helm both use node-semver style ranges
+		_, err = semver.NewConstraint(s)
+	case "gomod":
+		err = validateGoVersion(s)
+	case "terraform":
+		err = validateClauses(s, terraformClause)
+	case "tflint":
+		// tflint plugin versions must be exact
+		_, err = semver.StrictNewVersion(s)
+	case "swift":
+		// SwiftPM versions are full semantic versions
+		_, err = semver.StrictNewVersion(s)
+	case "ansible":
+		err = validateClauses(s, galaxyClause)
+	case "cocoapods", "bundler":
+		err = validateClauses(s, cocoapodsClause)
+	case "cargo":
+		err = validateClauses(s, cargoClause)
+	case "python":
+		// PEP 440 specifiers, as written in requirements files and PEP 621 metadata
+		err = validateClauses(s, pep440Clause)
+	case "poetry":
+		err = validateClauses(s, poetryClause)
+	case "maven", "gradle":
+		if !mavenVersion.MatchString(s) {
+			err = fmt.Errorf("malformed version")
+		}
+	case "composer":
+		err = validateComposer(s)
+	case "mix":
+		err = validateMix(s)
+	case "nuget":
+		if !nugetVersion.MatchString(s) {
+			err = fmt.Errorf("malformed version")
+		}
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s constraint %q: %w", ecosystem, s, err)
+	}
+	return nil
+}
+
+// validateGoVersion checks for a canonical Go module version ("v" + semver).
+func validateGoVersion(s string) error {
+	if !strings.HasPrefix(s, "v") {
+		return fmt.Errorf("missing v prefix")
+	}
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(s, "v"))
+	return err
+}
+
+// validateComposer checks every clause of a Composer constraint. Clauses are
+// separated by commas or whitespace (">=1.0 <2.0"); unions with || are not
+// written by uptool and are rejected.
+func validateComposer(s string) error {
+	if strings.TrimSpace(s) == "" {
+		return fmt.Errorf("empty constraint")
+	}
+	for _, part := range strings.FieldsFunc(composerOperatorSpace.ReplaceAllString(s, "$1"), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	}) {
+		if !composerClause.MatchString(part) {
+			return fmt.Errorf("malformed clause %q", part)
+		}
+	}
+	return nil
+}
+
+// validateMix checks every clause of a Mix requirement. Clauses are joined
+// by "and" or "or" (">= 1.0.0 and < 2.0.0").
+func validateMix(s string) error {
+	for _, part := range mixConnective.Split(strings.TrimSpace(s), -1) {
+		if !mixClause.MatchString(part) {
+			return fmt.Errorf("malformed clause %q", part)
+		}
+	}
+	return nil
+}
+
+// validateClauses checks every comma-separated clause of s against clause.
+func validateClauses(s string, clause *regexp.Regexp) error {
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "*" {
+			continue
+		}
+		if !clause.MatchString(part) {
+			return fmt.Errorf("malformed clause %q", part)
+		}
+	}
+	return nil
+}
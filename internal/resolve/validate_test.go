@@ -0,0 +1,142 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package resolve
+
+import "testing"
+
+func TestValidateConstraint(t *testing.T) {
+	tests := []struct {
+		ecosystem  string
+		constraint string
+		wantErr    bool
+	}{
+		// npm / Helm ranges
+		{ecosystem: "npm", constraint: "^18.2.0"},
+		{ecosystem: "npm", constraint: ">=1.0.0 <2.0.0 || 3.x"},
+		{ecosystem: "npm", constraint: "18.0.0 <<broken>>", wantErr: true},
+		{ecosystem: "helm", constraint: "12.1.0"},
+		{ecosystem: "helm", constraint: ">=>2.0", wantErr: true},
+
+		// Go modules require canonical versions
+		{ecosystem: "gomod", constraint: "v1.9.3"},
+		{ecosystem: "gomod", constraint: "v0.0.0-20240101000000-abcdef123456"},
+		{ecosystem: "gomod", constraint: "1.9.3", wantErr: true},
+		{ecosystem: "gomod", constraint: "v1.9", wantErr: true},
+
+		// Terraform constraints
+		{ecosystem: "terraform", constraint: "~> 5.0"},
+		{ecosystem: "terraform", constraint: ">= 1.2.0, < 2.0.0"},
+		{ecosystem: "terraform", constraint: "5.31.0"},
+		{ecosystem: "terraform", constraint: "^5.0", wantErr: true},
+
+		// tflint plugins are exact
+		{ecosystem: "tflint", constraint: "0.30.0"},
+		{ecosystem: "tflint", constraint: "~> 0.30", wantErr: true},
+
+		// Ansible Galaxy ranges
+		{ecosystem: "ansible", constraint: ">=8.2.0"},
+		{ecosystem: "ansible", constraint: ">=1.0.0,!=1.2.0"},
+		{ecosystem: "ansible", constraint: "*"},
+		{ecosystem: "ansible", constraint: "~1.0", wantErr: true},
+
+		// CocoaPods requirements
+		{ecosystem: "cocoapods", constraint: "~> 5.6"},
+		{ecosystem: "cocoapods", constraint: ">= 1.0, < 2.0"},
+		{ecosystem: "cocoapods", constraint: "~> 5.6.1.2"},
+		{ecosystem: "cocoapods", constraint: "^5.6", wantErr: true},
+
+		// SwiftPM versions
+		{ecosystem: "swift", constraint: "1.2.3"},
+		{ecosystem: "swift", constraint: "1.0.0-beta.1"},
+		{ecosystem: "swift", constraint: "1.2", wantErr: true},
+		{ecosystem: "swift", constraint: "v1.2.3", wantErr: true},
+
+		// Bundler requirements
+		{ecosystem: "bundler", constraint: "~> 7.1"},
+		{ecosystem: "bundler", constraint: "~> 1.15, >= 1.15.2"},
+		{ecosystem: "bundler", constraint: "!= 2.0.1"},
+		{ecosystem: "bundler", constraint: "^7.1", wantErr: true},
+
+		// Cargo requirements
+		{ecosystem: "cargo", constraint: "1.0"},
+		{ecosystem: "cargo", constraint: "^1.2.3"},
+		{ecosystem: "cargo", constraint: ">=1.2, <2"},
+		{ecosystem: "cargo", constraint: "~> 1.2", wantErr: true},
+
+		// NuGet versions
+		{ecosystem: "nuget", constraint: "13.0.3"},
+		{ecosystem: "nuget", constraint: "1.0.0.1"},
+		{ecosystem: "nuget", constraint: "1.2.*"},
+		{ecosystem: "nuget", constraint: "[1.0,2.0)", wantErr: true},
+		{ecosystem: "nuget", constraint: "1.2.x", wantErr: true},
+
+		// Maven versions
+		{ecosystem: "maven", constraint: "33.0.0-jre"},
+		{ecosystem: "maven", constraint: "6.4.4.Final"},
+		{ecosystem: "maven", constraint: "[1.0,2.0)", wantErr: true},
+		{ecosystem: "maven", constraint: "1.0</version>", wantErr: true},
+		{ecosystem: "gradle", constraint: "1.9.22"},
+		{ecosystem: "composer", constraint: "^8.2"},
+		{ecosystem: "composer", constraint: "~2.0"},
+		{ecosystem: "composer", constraint: ">=1.5 <2.0"},
+		{ecosystem: "composer", constraint: ">= 1.5, < 2.0"},
+		{ecosystem: "composer", constraint: "2.1.*"},
+		{ecosystem: "composer", constraint: "^1.0 || ^2.0", wantErr: true},
+		{ecosystem: "composer", constraint: "^8.2\", \"evil/pkg\": \"*", wantErr: true},
+		{ecosystem: "gradle", constraint: "1.9.22\" # injected", wantErr: true},
+		{ecosystem: "mix", constraint: "~> 1.7"},
+		{ecosystem: "mix", constraint: "~> 1.7.10"},
+		{ecosystem: "mix", constraint: ">= 1.0.0 and < 2.0.0"},
+		{ecosystem: "mix", constraint: "~> 1.0 or ~> 2.0"},
+		{ecosystem: "mix", constraint: ">= 1.0", wantErr: true},
+		{ecosystem: "mix", constraint: "~> 1.7\"}, {:evil, \"1.0.0", wantErr: true},
+
+		// PEP 440 specifiers, and Poetry constraints on top of them
+		{ecosystem: "python", constraint: "==2.31.0"},
+		{ecosystem: "python", constraint: ">=2.31,<3"},
+		{ecosystem: "python", constraint: "~=1.4.2"},
+		{ecosystem: "python", constraint: "==3.1.*"},
+		{ecosystem: "python", constraint: ">=1.0rc1, !=1.0.post2"},
+		{ecosystem: "python", constraint: ">=1.0,<", wantErr: true},
+		{ecosystem: "python", constraint: ">=1.0,", wantErr: true},
+		{ecosystem: "python", constraint: ">=1.*", wantErr: true},
+		{ecosystem: "python", constraint: "^1.2", wantErr: true},
+		{ecosystem: "python", constraint: "==2.0; os_name == \"nt\"", wantErr: true},
+		{ecosystem: "poetry", constraint: "^1.9"},
+		{ecosystem: "poetry", constraint: "~1.2.3"},
+		{ecosystem: "poetry", constraint: "1.2.*"},
+		{ecosystem: "poetry", constraint: ">=1.2,<2.0"},
+		{ecosystem: "poetry", constraint: "^1.9 || ^2.0", wantErr: true},
+
+		// Free-form ecosystems are not validated, but empty values never are valid
+		{ecosystem: "docker", constraint: "1.25-alpine"},
+		{ecosystem: "npm", constraint: "  ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ecosystem+" "+tt.constraint, func(t *testing.T) {
+			err := ValidateConstraint(tt.ecosystem, tt.constraint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConstraint(%q, %q) error = %v, wantErr %v", tt.ecosystem, tt.constraint, err, tt.wantErr)
+			}
+		})
+	}
+}
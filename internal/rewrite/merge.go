@@ -0,0 +1,158 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package rewrite
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ErrMergeConflict is returned by Merge3 when both sides change the same region differently.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// hunk is a change to base lines [start, end) replaced by lines.
+type hunk struct {
+	lines []string
+	start int
+	end   int
+}
+
+// HasConflictMarkers reports whether content contains unresolved git conflict markers.
+func HasConflictMarkers(content string) bool {
+	var sawStart, sawSeparator bool
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.HasPrefix(line, "<<<<<<<"):
+			sawStart = true
+		case sawStart && line == "=======":
+			sawSeparator = true
+		case sawSeparator && strings.HasPrefix(line, ">>>>>>>"):
+			return true
+		}
+	}
+	return false
+}
+
+// Merge3 performs a line-based three-way merge of ours and theirs against their
+// common ancestor base. Changes that touch disjoint regions of base are combined;
+// identical changes are taken once. Overlapping, differing changes return
+// ErrMergeConflict.
+func Merge3(base, ours, theirs string) (string, error) {
+	baseLines := splitKeepEOL(base)
+	ourHunks := diffHunks(baseLines, splitKeepEOL(ours))
+	theirHunks := diffHunks(baseLines, splitKeepEOL(theirs))
+
+	var out strings.Builder
+	pos := 0
+	i, j := 0, 0
+	for i < len(ourHunks) || j < len(theirHunks) {
+		var h hunk
+		switch {
+		case j >= len(theirHunks):
+			h = ourHunks[i]
+			i++
+		case i >= len(ourHunks):
+			h = theirHunks[j]
+			j++
+		case overlaps(ourHunks[i], theirHunks[j]):
+			if !sameHunk(ourHunks[i], theirHunks[j]) {
+				return "", ErrMergeConflict
+			}
+			h = ourHunks[i]
+			i++
+			j++
+		case ourHunks[i].start < theirHunks[j].start ||
+			(ourHunks[i].start == theirHunks[j].start && ourHunks[i].end <= theirHunks[j].end):
+			h = ourHunks[i]
+			i++
+		default:
+			h = theirHunks[j]
+			j++
+		}
+
+		for _, line := range baseLines[pos:h.start] {
+			out.WriteString(line)
+		}
+		for _, line := range h.lines {
+			out.WriteString(line)
+		}
+		pos = h.end
+	}
+
+	for _, line := range baseLines[pos:] {
+		out.WriteString(line)
+	}
+
+	return out.String(), nil
+}
+
+// splitKeepEOL splits s into lines, keeping line terminators so joining
+// the result reproduces s exactly.
+func splitKeepEOL(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffHunks returns the changes that turn a into b, expressed against a.
+func diffHunks(a, b []string) []hunk {
+	matcher := difflib.NewMatcher(a, b)
+
+	var hunks []hunk
+	for _, op := range matcher.GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		hunks = append(hunks, hunk{
+			start: op.I1,
+			end:   op.I2,
+			lines: b[op.J1:op.J2],
+		})
+	}
+	return hunks
+}
+
+// overlaps reports whether two hunks touch the same base region.
+// Two insertions at the same position also overlap, since their order is ambiguous.
+func overlaps(a, b hunk) bool {
+	if a.start == b.start {
+		return true
+	}
+	return a.start < b.end && b.start < a.end
+}
+
+// sameHunk reports whether two hunks make the identical change.
+func sameHunk(a, b hunk) bool {
+	if a.start != b.start || a.end != b.end || len(a.lines) != len(b.lines) {
+		return false
+	}
+	for k := range a.lines {
+		if a.lines[k] != b.lines[k] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package rewrite
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "a\nb\nc\nd\ne\n"
+
+	tests := []struct {
+		name    string
+		ours    string
+		theirs  string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "no changes",
+			ours:   base,
+			theirs: base,
+			want:   base,
+		},
+		{
+			name:   "only ours changed",
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: base,
+			want:   "a\nB\nc\nd\ne\n",
+		},
+		{
+			name:   "only theirs changed",
+			ours:   base,
+			theirs: "a\nb\nc\nD\ne\n",
+			want:   "a\nb\nc\nD\ne\n",
+		},
+		{
+			name:   "disjoint changes combined",
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: "a\nb\nc\nD\ne\nf\n",
+			want:   "a\nB\nc\nD\ne\nf\n",
+		},
+		{
+			name:   "identical changes taken once",
+			ours:   "a\nB\nc\nd\ne\n",
+			theirs: "a\nB\nc\nd\ne\n",
+			want:   "a\nB\nc\nd\ne\n",
+		},
+		{
+			name:    "conflicting changes",
+			ours:    "a\nB\nc\nd\ne\n",
+			theirs:  "a\nX\nc\nd\ne\n",
+			wantErr: true,
+		},
+		{
+			name:    "insertions at same position conflict",
+			ours:    "a\nb\nours\nc\nd\ne\n",
+			theirs:  "a\nb\ntheirs\nc\nd\ne\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Merge3(base, tt.ours, tt.theirs)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMergeConflict) {
+					t.Fatalf("Merge3() error = %v, want ErrMergeConflict", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Merge3() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Merge3() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasConflictMarkers(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{
+			name:    "clean file",
+			content: "lockfileVersion: 9\npackages:\n  foo: 1.0.0\n",
+			want:    false,
+		},
+		{
+			name:    "conflict block",
+			content: "packages:\n<<<<<<< HEAD\n  foo: 1.0.0\n=======\n  foo: 1.1.0\n>>>>>>> feature\n",
+			want:    true,
+		},
+		{
+			name:    "separator alone is not a conflict",
+			content: "Title\n=======\n",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HasConflictMarkers(tt.content); got != tt.want {
+				t.Errorf("HasConflictMarkers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
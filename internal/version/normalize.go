@@ -0,0 +1,82 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package version
+
+import "strings"
+
+// Forms an ecosystem writes release versions in.
+const (
+	formPrefixed = "prefixed" // v1.2.3
+	formBare     = "bare"     // 1.2.3
+)
+
+// ecosystemForms maps ecosystems, by integration or datasource name, to the
+// version form their manifests use. Ecosystems not listed, such as GitHub
+// Actions, Docker and pre-commit, use tags verbatim and are left unchanged.
+var ecosystemForms = map[string]string{
+	"gomod":     formPrefixed,
+	"go":        formPrefixed,
+	"npm":       formBare,
+	"cargo":     formBare,
+	"crates":    formBare,
+	"terraform": formBare,
+	"tflint":    formBare,
+	"cocoapods": formBare,
+	"nuget":     formBare,
+	"composer":  formBare,
+	"packagist": formBare,
+	"mix":       formBare,
+	"hex":       formBare,
+	"bundler":   formBare,
+	"rubygems":  formBare,
+	"swift":     formBare,
+	"python":    formBare,
+	"pypi":      formBare,
+}
+
+// Normalize returns raw in the form ecosystem writes versions in: Go modules
+// keep a leading "v" (v1.2.3) while npm, Cargo, Terraform and the like drop
+// it (1.2.3). Strings that are not versions, and versions of ecosystems whose
+// tags vary, are returned unchanged.
+func Normalize(ecosystem, raw string) string {
+	switch ecosystemForms[ecosystem] {
+	case formPrefixed:
+		if startsWithDigit(raw) {
+			return "v" + raw
+		}
+	case formBare:
+		return Bare(raw)
+	}
+	return raw
+}
+
+// Bare strips the "v" from a v-prefixed version such as v1.2.3. Other strings,
+// including names that merely start with a v, are returned unchanged.
+func Bare(raw string) string {
+	if (strings.HasPrefix(raw, "v") || strings.HasPrefix(raw, "V")) && startsWithDigit(raw[1:]) {
+		return raw[1:]
+	}
+	return raw
+}
+
+func startsWithDigit(s string) bool {
+	return s != "" && s[0] >= '0' && s[0] <= '9'
+}
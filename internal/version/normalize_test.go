@@ -0,0 +1,88 @@
+// Copyright (c) 2024 santosr2
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package version
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		ecosystem string
+		raw       string
+		want      string
+	}{
+		// Go modules always carry the prefix
+		{"gomod", "1.2.3", "v1.2.3"},
+		{"gomod", "v1.2.3", "v1.2.3"},
+		{"gomod", "v0.0.0-20231201123456-abcdef123456", "v0.0.0-20231201123456-abcdef123456"},
+		{"go", "2.0.0+incompatible", "v2.0.0+incompatible"},
+
+		// Registries that publish bare versions
+		{"npm", "v4.19.2", "4.19.2"},
+		{"npm", "4.19.2", "4.19.2"},
+		{"npm", "latest", "latest"},
+		{"cargo", "V1.0.0", "1.0.0"},
+		{"terraform", "v5.31.0", "5.31.0"},
+		{"tflint", "v0.27.0", "0.27.0"},
+		{"cocoapods", "v5.6.4", "5.6.4"},
+		{"python", "v2.31.0", "2.31.0"},
+
+		// Tags are written as published
+		{"actions", "v4", "v4"},
+		{"actions", "4.1.0", "4.1.0"},
+		{"docker", "v1.25", "v1.25"},
+		{"unknown", "v1.0.0", "v1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ecosystem+"/"+tt.raw, func(t *testing.T) {
+			if got := Normalize(tt.ecosystem, tt.raw); got != tt.want {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.ecosystem, tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize_RoundTrip(t *testing.T) {
+	// Normalizing an already-normalized version is a no-op in every ecosystem
+	for ecosystem := range ecosystemForms {
+		for _, raw := range []string{"1.2.3", "v1.2.3"} {
+			once := Normalize(ecosystem, raw)
+			if twice := Normalize(ecosystem, once); twice != once {
+				t.Errorf("Normalize(%q) is not idempotent: %q -> %q -> %q", ecosystem, raw, once, twice)
+			}
+		}
+	}
+}
+
+func TestBare(t *testing.T) {
+	tests := map[string]string{
+		"v1.2.3": "1.2.3",
+		"1.2.3":  "1.2.3",
+		"v":      "v",
+		"vue":    "vue",
+		"":       "",
+	}
+	for raw, want := range tests {
+		if got := Bare(raw); got != want {
+			t.Errorf("Bare(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}